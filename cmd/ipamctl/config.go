@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the ipamctl configuration file. Like a kubeconfig it holds
+// named contexts, one per IPAM server, and the one in use.
+type Config struct {
+	CurrentContext string    `json:"current-context,omitempty"`
+	Contexts       []Context `json:"contexts,omitempty"`
+}
+
+// Context describes one IPAM server and how to authenticate to it.
+type Context struct {
+	Name   string `json:"name"`
+	Server string `json:"server"`
+	// Token is a static bearer token, set by `login --token`.
+	Token string      `json:"token,omitempty"`
+	OIDC  *OIDCConfig `json:"oidc,omitempty"`
+	// Credentials are the tokens stored by the device flow.
+	Credentials *Credentials `json:"credentials,omitempty"`
+}
+
+// OIDCConfig selects a public client for the device authorization grant.
+type OIDCConfig struct {
+	Issuer   string   `json:"issuer"`
+	ClientID string   `json:"client-id"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+type Credentials struct {
+	AccessToken  string    `json:"access-token"`
+	RefreshToken string    `json:"refresh-token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// defaultConfigPath honours IPAMCTL_CONFIG and otherwise uses the user's
+// configuration directory.
+func defaultConfigPath(getenv func(string) string) (string, error) {
+	if path := getenv("IPAMCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "ipamctl", "config.yaml"), nil
+}
+
+// loadConfig returns an empty configuration when the file does not exist.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// saveConfig writes the file readable only by the user, since it holds
+// tokens.
+func saveConfig(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+func (c *Config) context(name string) (*Context, bool) {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i], true
+		}
+	}
+	return nil, false
+}
+
+// upsertContext returns the named context, adding it when missing.
+func (c *Config) upsertContext(name string) *Context {
+	if existing, ok := c.context(name); ok {
+		return existing
+	}
+	c.Contexts = append(c.Contexts, Context{Name: name})
+	return &c.Contexts[len(c.Contexts)-1]
+}
+
+func (c *Config) deleteContext(name string) bool {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			if c.CurrentContext == name {
+				c.CurrentContext = ""
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+func (a *app) runConfig(args []string) error {
+	name, args, err := subcommand("config", args)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "get-contexts":
+		return a.getContexts(args)
+	case "current-context":
+		return a.showCurrentContext(args)
+	case "use-context":
+		return a.useContext(args)
+	case "set-context":
+		return a.setContext(args)
+	case "delete-context":
+		return a.deleteContext(args)
+	default:
+		return usageError("unknown config subcommand %q", name)
+	}
+}
+
+func (a *app) getContexts(args []string) error {
+	positional, err := a.parse("config get-contexts", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"CURRENT", "NAME", "SERVER", "AUTH"}}
+	for _, context := range cfg.Contexts {
+		current := ""
+		if context.Name == cfg.CurrentContext {
+			current = "*"
+		}
+		t.add(current, context.Name, context.Server, authKind(context))
+	}
+	return a.print(cfg.Contexts, t)
+}
+
+// authKind summarises how a context authenticates without printing secrets.
+func authKind(context Context) string {
+	switch {
+	case context.Token != "":
+		return "token"
+	case context.Credentials != nil:
+		return "oidc"
+	case context.OIDC != nil:
+		return "oidc (logged out)"
+	default:
+		return "none"
+	}
+}
+
+func (a *app) showCurrentContext(args []string) error {
+	positional, err := a.parse("config current-context", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.CurrentContext == "" {
+		return fmt.Errorf("current-context is not set")
+	}
+	fmt.Fprintln(a.stdout, cfg.CurrentContext)
+	return nil
+}
+
+func (a *app) useContext(args []string) error {
+	positional, err := a.parse("config use-context", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<name>"); err != nil {
+		return err
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.context(positional[0]); !ok {
+		return fmt.Errorf("context %q not found", positional[0])
+	}
+	cfg.CurrentContext = positional[0]
+	if err := a.saveConfig(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Switched to context %q.\n", positional[0])
+	return nil
+}
+
+// setContext creates or updates a context from the global --server flag and
+// the OIDC flags. The first context created becomes the current one.
+func (a *app) setContext(args []string) error {
+	var issuer, clientID, scopes string
+	positional, err := a.parse("config set-context", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&issuer, "issuer", "", "OIDC issuer URL for `login`")
+		fs.StringVar(&clientID, "client-id", "", "OIDC public client id for `login`")
+		fs.StringVar(&scopes, "scopes", "", "comma-separated OIDC scopes (default openid,offline_access)")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<name>"); err != nil {
+		return err
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	context := cfg.upsertContext(positional[0])
+	if a.opts.server != "" {
+		context.Server = a.opts.server
+	}
+	if context.Server == "" {
+		return usageError("--server is required for a new context")
+	}
+	if issuer != "" || clientID != "" || scopes != "" {
+		if context.OIDC == nil {
+			context.OIDC = &OIDCConfig{}
+		}
+		if issuer != "" {
+			context.OIDC.Issuer = issuer
+		}
+		if clientID != "" {
+			context.OIDC.ClientID = clientID
+		}
+		if scopes != "" {
+			context.OIDC.Scopes = strings.Split(scopes, ",")
+		}
+		if context.OIDC.Issuer == "" || context.OIDC.ClientID == "" {
+			return usageError("--issuer and --client-id are both required for OIDC login")
+		}
+	}
+	if cfg.CurrentContext == "" {
+		cfg.CurrentContext = context.Name
+	}
+	if err := a.saveConfig(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Context %q saved.\n", context.Name)
+	return nil
+}
+
+func (a *app) deleteContext(args []string) error {
+	positional, err := a.parse("config delete-context", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<name>"); err != nil {
+		return err
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	if !cfg.deleteContext(positional[0]) {
+		return fmt.Errorf("context %q not found", positional[0])
+	}
+	if err := a.saveConfig(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deleted context %q.\n", positional[0])
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+func (a *app) runImport(ctx context.Context, args []string) error {
+	name, args, err := subcommand("import", args)
+	if err != nil {
+		return err
+	}
+	if name != "csv" {
+		return usageError("unknown import format %q", name)
+	}
+	positional, err := a.parse("import csv", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<file>"); err != nil {
+		return err
+	}
+	file, err := os.Open(positional[0])
+	if err != nil {
+		return fmt.Errorf("open csv: %w", err)
+	}
+	defer file.Close()
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := api.ImportCSV(ctx, positional[0], file)
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"ROW", "ERROR"}}
+	for _, rowError := range result.Errors {
+		t.add(strconv.Itoa(rowError.Row), rowError.Message)
+	}
+	if a.opts.output == "table" || a.opts.output == "" {
+		fmt.Fprintf(a.stdout, "Processed %d rows: %d created, %d updated, %d failed.\n",
+			result.Processed, result.Created, result.Updated, result.Failed)
+		if len(result.Errors) == 0 {
+			return nil
+		}
+	}
+	return a.print(result, t)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+)
+
+func (a *app) runIPs(ctx context.Context, args []string) error {
+	name, args, err := subcommand("ips", args)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "list", "ls":
+		return a.listIPs(ctx, args)
+	case "add":
+		return a.addIP(ctx, args)
+	case "rm", "delete":
+		return a.removeIP(ctx, args)
+	default:
+		return usageError("unknown ips subcommand %q", name)
+	}
+}
+
+func (a *app) listIPs(ctx context.Context, args []string) error {
+	positional, err := a.parse("ips list", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<subnet-id>"); err != nil {
+		return err
+	}
+	subnetID, err := parseSubnetID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	ips, err := api.ListIPs(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	return a.print(ips, ipTable(ips...))
+}
+
+func (a *app) addIP(ctx context.Context, args []string) error {
+	var hostname string
+	positional, err := a.parse("ips add", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&hostname, "hostname", "", "hostname for the address")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<subnet-id>", "<ip>"); err != nil {
+		return err
+	}
+	subnetID, err := parseSubnetID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	ip, err := api.CreateIP(ctx, subnetID, client.CreateIPRequest{IP: positional[1], Hostname: hostname})
+	if err != nil {
+		return err
+	}
+	return a.print(ip, ipTable(ip))
+}
+
+// removeIP accepts either the address or its id.
+func (a *app) removeIP(ctx context.Context, args []string) error {
+	positional, err := a.parse("ips rm", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<subnet-id>", "<ip|id>"); err != nil {
+		return err
+	}
+	subnetID, err := parseSubnetID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	id := positional[1]
+	if addr, err := netip.ParseAddr(id); err == nil {
+		ip, err := findIP(ctx, api, subnetID, addr)
+		if err != nil {
+			return err
+		}
+		id = ip.ID
+	}
+	if err := api.DeleteIP(ctx, subnetID, id); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deleted %s from subnet %d.\n", positional[1], subnetID)
+	return nil
+}
+
+// findIP returns the record for addr in a subnet.
+func findIP(ctx context.Context, api *client.Client, subnetID int64, addr netip.Addr) (client.IPAddress, error) {
+	ips, err := api.ListIPs(ctx, subnetID)
+	if err != nil {
+		return client.IPAddress{}, err
+	}
+	for _, ip := range ips {
+		if recorded, err := netip.ParseAddr(ip.IP); err == nil && recorded == addr {
+			return ip, nil
+		}
+	}
+	return client.IPAddress{}, fmt.Errorf("%s is not recorded in subnet %d", addr, subnetID)
+}
+
+func ipTable(ips ...client.IPAddress) table {
+	t := table{header: []string{"ID", "IP", "HOSTNAME", "SUBNET", "KUBERNETES SERVICES"}}
+	for _, ip := range ips {
+		services := make([]string, 0, len(ip.KubernetesServices))
+		for _, service := range ip.KubernetesServices {
+			services = append(services, service.Namespace+"/"+service.Name)
+		}
+		t.add(ip.ID, ip.IP, ip.Hostname, strconv.FormatInt(ip.SubnetID, 10), strings.Join(services, ","))
+	}
+	return t
+}
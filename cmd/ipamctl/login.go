@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+	"golang.org/x/oauth2"
+)
+
+// runLogin stores a static token given with --token, or runs the OIDC device
+// authorization grant against the context's issuer.
+func (a *app) runLogin(ctx context.Context, args []string) error {
+	positional, err := a.parse("login", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	current, err := a.currentContext()
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("login needs a context; run `ipamctl config set-context` first")
+	}
+	if a.opts.token != "" {
+		current.Token, current.Credentials = a.opts.token, nil
+		if err := a.saveConfig(); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "Stored token for context %q.\n", current.Name)
+		return nil
+	}
+	if current.OIDC == nil {
+		return fmt.Errorf("context %q has no OIDC issuer; pass --token or set --issuer and --client-id", current.Name)
+	}
+
+	oauthConfig, err := a.oauthConfig(ctx, current.OIDC)
+	if err != nil {
+		return err
+	}
+	if oauthConfig.Endpoint.DeviceAuthURL == "" {
+		return fmt.Errorf("issuer %s does not support the device authorization grant", current.OIDC.Issuer)
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+	authorization, err := oauthConfig.DeviceAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("start device login: %w", err)
+	}
+	verification := authorization.VerificationURIComplete
+	if verification == "" {
+		verification = authorization.VerificationURI
+	}
+	fmt.Fprintf(a.stderr, "Open %s and enter code %s to log in.\n", verification, authorization.UserCode)
+	token, err := oauthConfig.DeviceAccessToken(ctx, authorization)
+	if err != nil {
+		return fmt.Errorf("complete device login: %w", err)
+	}
+	current.Token = ""
+	current.Credentials = &Credentials{AccessToken: token.AccessToken, RefreshToken: token.RefreshToken, Expiry: token.Expiry}
+	if err := a.saveConfig(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Logged in to context %q.\n", current.Name)
+	return nil
+}
+
+func (a *app) runLogout(args []string) error {
+	positional, err := a.parse("logout", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	current, err := a.currentContext()
+	if err != nil || current == nil {
+		return err
+	}
+	current.Token, current.Credentials = "", nil
+	return a.saveConfig()
+}
+
+func (a *app) oauthConfig(ctx context.Context, oidc *OIDCConfig) (*oauth2.Config, error) {
+	endpoints, err := client.DiscoverOIDC(ctx, a.httpClient, oidc.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	scopes := oidc.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "offline_access"}
+	}
+	return &oauth2.Config{
+		ClientID: oidc.ClientID,
+		Scopes:   scopes,
+		Endpoint: oauth2.Endpoint{
+			TokenURL:      endpoints.TokenURL,
+			DeviceAuthURL: endpoints.DeviceAuthorizationURL,
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}, nil
+}
+
+// deviceTokenSource refreshes the stored device-flow token when it expires.
+func (a *app) deviceTokenSource(ctx context.Context, current *Context) (oauth2.TokenSource, error) {
+	stored := &oauth2.Token{
+		AccessToken:  current.Credentials.AccessToken,
+		RefreshToken: current.Credentials.RefreshToken,
+		Expiry:       current.Credentials.Expiry,
+		TokenType:    "Bearer",
+	}
+	if stored.Valid() {
+		return oauth2.StaticTokenSource(stored), nil
+	}
+	if stored.RefreshToken == "" {
+		return nil, fmt.Errorf("token for context %q expired; run `ipamctl login`", current.Name)
+	}
+	oauthConfig, err := a.oauthConfig(ctx, current.OIDC)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+	return oauthConfig.TokenSource(ctx, stored), nil
+}
+
+// persistTokens writes back a token refreshed during the command.
+func (a *app) persistTokens() error {
+	if a.tokens == nil {
+		return nil
+	}
+	current, err := a.currentContext()
+	if err != nil || current == nil || current.Credentials == nil {
+		return err
+	}
+	token, err := a.tokens.Token()
+	if err != nil {
+		return nil
+	}
+	if token.AccessToken == current.Credentials.AccessToken {
+		return nil
+	}
+	current.Credentials = &Credentials{AccessToken: token.AccessToken, RefreshToken: token.RefreshToken, Expiry: token.Expiry}
+	return a.saveConfig()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+)
+
+// lookupResult is what `lookup` prints: the most specific subnet containing
+// the address and its record there, if any.
+type lookupResult struct {
+	IP      string            `json:"ip"`
+	Subnet  client.Subnet     `json:"subnet"`
+	Address *client.IPAddress `json:"address,omitempty"`
+}
+
+func (a *app) runLookup(ctx context.Context, args []string) error {
+	positional, err := a.parse("lookup", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<ip>"); err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(positional[0])
+	if err != nil {
+		return fmt.Errorf("invalid ip %q", positional[0])
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	subnets, err := api.ListSubnets(ctx)
+	if err != nil {
+		return err
+	}
+	subnet, ok := longestMatch(subnets, addr)
+	if !ok {
+		return fmt.Errorf("%s is not in any subnet", addr)
+	}
+	result := lookupResult{IP: addr.String(), Subnet: subnet}
+	ips, err := api.ListIPs(ctx, subnet.ID)
+	if err != nil {
+		return err
+	}
+	for i := range ips {
+		if recorded, err := netip.ParseAddr(ips[i].IP); err == nil && recorded == addr {
+			result.Address = &ips[i]
+			break
+		}
+	}
+
+	t := table{header: []string{"IP", "SUBNET", "CIDR", "STATUS", "HOSTNAME", "ID"}}
+	status, hostname, id := "free", "", ""
+	if result.Address != nil {
+		status, hostname, id = "allocated", result.Address.Hostname, result.Address.ID
+	}
+	t.add(result.IP, strconv.FormatInt(subnet.ID, 10), subnet.CIDR, status, hostname, id)
+	return a.print(result, t)
+}
+
+// longestMatch returns the most specific subnet containing addr.
+func longestMatch(subnets []client.Subnet, addr netip.Addr) (client.Subnet, bool) {
+	var (
+		best     client.Subnet
+		bestBits = -1
+	)
+	for _, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(subnet.CIDR)
+		if err != nil || !prefix.Contains(addr) {
+			continue
+		}
+		if prefix.Bits() > bestBits {
+			best, bestBits = subnet, prefix.Bits()
+		}
+	}
+	return best, bestBits >= 0
+}
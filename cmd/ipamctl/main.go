@@ -0,0 +1,272 @@
+// Command ipamctl is a command-line client for the IPAM API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+	"golang.org/x/oauth2"
+)
+
+const usage = `ipamctl manages subnets, IP addresses and sites through the IPAM API.
+
+Usage:
+  ipamctl [flags] <command> [arguments]
+
+Commands:
+  subnets list | get <id> | create <cidr> --site <id> | allocate <subnet-id>
+  ips list <subnet-id> | add <subnet-id> <ip> | rm <subnet-id> <ip|id>
+  sites list | get <id> | create <name> | update <id> <name> | rm <id> | stats
+  import csv <file>
+  lookup <ip>
+  login [--token <token>]
+  logout
+  config get-contexts | current-context | use-context <name>
+         | set-context <name> --server <url> [--issuer <url> --client-id <id>]
+         | delete-context <name>
+
+Flags (accepted before or after the command):
+`
+
+// errUsage reports a malformed command line; main prints usage for it.
+var errUsage = errors.New("invalid usage")
+
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+type globalOptions struct {
+	configPath string
+	context    string
+	server     string
+	token      string
+	output     string
+}
+
+type app struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	getenv     func(string) string
+	httpClient *http.Client
+	opts       globalOptions
+
+	config *Config
+	// tokens is set when the context uses device-flow credentials, so that a
+	// refreshed token can be written back after the command.
+	tokens oauth2.TokenSource
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(newApp(os.Stdout, os.Stderr, os.Getenv, http.DefaultClient).run(ctx, os.Args[1:]))
+}
+
+func newApp(stdout, stderr io.Writer, getenv func(string) string, httpClient *http.Client) *app {
+	return &app{
+		stdout: stdout, stderr: stderr, getenv: getenv, httpClient: httpClient,
+		opts: globalOptions{output: "table"},
+	}
+}
+
+func (a *app) run(ctx context.Context, args []string) int {
+	fs := a.flagSet("ipamctl")
+	err := fs.Parse(args)
+	if err == nil {
+		err = a.dispatch(ctx, fs.Args())
+	}
+	if err == nil {
+		err = a.persistTokens()
+	}
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(a.stderr, "error:", err)
+		fs.Usage()
+		return 2
+	default:
+		fmt.Fprintln(a.stderr, "error:", err)
+		return 1
+	}
+}
+
+// flagSet registers the global flags. Their defaults are the values already
+// parsed, so a command's own flag set keeps flags given before the command.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.opts.configPath, "config", a.opts.configPath, "config file (default $IPAMCTL_CONFIG or the user config dir)")
+	fs.StringVar(&a.opts.context, "context", a.opts.context, "context to use instead of current-context")
+	fs.StringVar(&a.opts.server, "server", a.opts.server, "API server URL, overriding the context")
+	fs.StringVar(&a.opts.token, "token", a.opts.token, "bearer token, overriding the context and $IPAMCTL_TOKEN")
+	fs.StringVar(&a.opts.output, "o", a.opts.output, "output format: table, json or yaml")
+	fs.Usage = func() {
+		fmt.Fprint(a.stderr, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses a command's arguments. Global flags and the command's own,
+// added by register, may appear anywhere among the positional arguments.
+func (a *app) parse(name string, args []string, register func(*flag.FlagSet)) ([]string, error) {
+	fs := a.flagSet("ipamctl " + name)
+	if register != nil {
+		register(fs)
+	}
+	return parseInterspersed(fs, args)
+}
+
+// parseInterspersed parses flags anywhere in args and returns the remaining
+// positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func (a *app) dispatch(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usageError("a command is required")
+	}
+	command, rest := args[0], args[1:]
+	switch command {
+	case "subnets", "subnet":
+		return a.runSubnets(ctx, rest)
+	case "ips", "ip":
+		return a.runIPs(ctx, rest)
+	case "sites", "site":
+		return a.runSites(ctx, rest)
+	case "import":
+		return a.runImport(ctx, rest)
+	case "lookup":
+		return a.runLookup(ctx, rest)
+	case "login":
+		return a.runLogin(ctx, rest)
+	case "logout":
+		return a.runLogout(rest)
+	case "config":
+		return a.runConfig(rest)
+	default:
+		return usageError("unknown command %q", command)
+	}
+}
+
+// subcommand splits args into a subcommand name and its arguments.
+func subcommand(resource string, args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usageError("%s needs a subcommand", resource)
+	}
+	return args[0], args[1:], nil
+}
+
+// wantArgs checks the positional argument count.
+func wantArgs(args []string, names ...string) error {
+	if len(args) != len(names) {
+		return usageError("expected arguments: %s", strings.Join(names, " "))
+	}
+	return nil
+}
+
+func (a *app) configPath() (string, error) {
+	if a.opts.configPath != "" {
+		return a.opts.configPath, nil
+	}
+	return defaultConfigPath(a.getenv)
+}
+
+func (a *app) loadConfig() (*Config, error) {
+	if a.config != nil {
+		return a.config, nil
+	}
+	path, err := a.configPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	a.config = cfg
+	return cfg, nil
+}
+
+func (a *app) saveConfig() error {
+	path, err := a.configPath()
+	if err != nil {
+		return err
+	}
+	return saveConfig(path, a.config)
+}
+
+// currentContext returns the selected context. It may be nil when --server
+// is given and no context is configured.
+func (a *app) currentContext() (*Context, error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := a.opts.context
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+	if name == "" {
+		if a.opts.server != "" {
+			return nil, nil
+		}
+		return nil, errors.New("no context selected; run `ipamctl config set-context` or pass --server")
+	}
+	current, ok := cfg.context(name)
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	return current, nil
+}
+
+// client builds an API client from the flags, environment and context, in
+// that order of precedence.
+func (a *app) client(ctx context.Context) (*client.Client, error) {
+	current, err := a.currentContext()
+	if err != nil {
+		return nil, err
+	}
+	cfg := client.Config{BaseURL: a.opts.server, HTTPClient: a.httpClient, UserAgent: "ipamctl"}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = current.Server
+	}
+	switch {
+	case a.opts.token != "":
+		cfg.Token = a.opts.token
+	case a.getenv("IPAMCTL_TOKEN") != "":
+		cfg.Token = a.getenv("IPAMCTL_TOKEN")
+	case current == nil:
+	case current.Token != "":
+		cfg.Token = current.Token
+	case current.Credentials != nil && current.OIDC != nil:
+		tokens, err := a.deviceTokenSource(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		a.tokens = tokens
+		cfg.TokenSource = tokens
+	}
+	return client.New(cfg)
+}
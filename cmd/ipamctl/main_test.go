@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+)
+
+func TestNextFreeAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		used   []string
+		want   string
+	}{
+		{name: "skips network address", prefix: "10.0.0.0/24", want: "10.0.0.1"},
+		{name: "skips used", prefix: "10.0.0.0/24", used: []string{"10.0.0.1", "10.0.0.2"}, want: "10.0.0.3"},
+		{name: "skips broadcast", prefix: "10.0.0.0/30", used: []string{"10.0.0.1", "10.0.0.2"}, want: ""},
+		{name: "point to point", prefix: "10.0.0.0/31", used: []string{"10.0.0.0"}, want: "10.0.0.1"},
+		{name: "host route", prefix: "10.0.0.7/32", want: "10.0.0.7"},
+		{name: "ipv6 uses first address", prefix: "2001:db8::/126", used: []string{"2001:db8::"}, want: "2001:db8::1"},
+		{name: "unmasked prefix", prefix: "10.0.0.9/29", want: "10.0.0.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := map[netip.Addr]bool{}
+			for _, ip := range tt.used {
+				used[netip.MustParseAddr(ip)] = true
+			}
+			got, ok := nextFreeAddress(netip.MustParsePrefix(tt.prefix), used)
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("nextFreeAddress() = %s, want none", got)
+				}
+				return
+			}
+			if !ok || got.String() != tt.want {
+				t.Fatalf("nextFreeAddress() = %s, %v, want %s", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLongestMatch(t *testing.T) {
+	subnets := []client.Subnet{
+		{ID: 1, CIDR: "10.0.0.0/8"},
+		{ID: 2, CIDR: "10.1.0.0/16"},
+		{ID: 3, CIDR: "192.168.0.0/24"},
+	}
+	if got, ok := longestMatch(subnets, netip.MustParseAddr("10.1.2.3")); !ok || got.ID != 2 {
+		t.Fatalf("longestMatch() = %d, %v, want 2", got.ID, ok)
+	}
+	if _, ok := longestMatch(subnets, netip.MustParseAddr("172.16.0.1")); ok {
+		t.Fatal("longestMatch() matched an address outside every subnet")
+	}
+}
+
+// fakeAPI serves the few endpoints the commands under test use.
+type fakeAPI struct {
+	mu        sync.Mutex
+	token     string
+	ips       []client.IPAddress
+	conflicts int
+	created   []client.CreateIPRequest
+}
+
+func (f *fakeAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/subnets", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []client.Subnet{{ID: 1, CIDR: "10.0.0.0/8"}, {ID: 7, CIDR: "10.0.0.0/29"}})
+	})
+	mux.HandleFunc("GET /api/v1/subnets/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, client.Subnet{ID: 7, CIDR: "10.0.0.0/29"})
+	})
+	mux.HandleFunc("GET /api/v1/subnets/7/ips", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		writeJSON(w, http.StatusOK, f.ips)
+	})
+	mux.HandleFunc("POST /api/v1/subnets/7/ips", func(w http.ResponseWriter, r *http.Request) {
+		var input client.CreateIPRequest
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.created = append(f.created, input)
+		ip := client.IPAddress{ID: "id-" + input.IP, IP: input.IP, Hostname: input.Hostname, SubnetID: 7}
+		f.ips = append(f.ips, ip)
+		if f.conflicts > 0 {
+			// Another client took the address between list and create.
+			f.conflicts--
+			w.Header().Set("Content-Type", "application/problem+json")
+			writeJSON(w, http.StatusConflict, map[string]any{
+				"type":   "https://github.com/Flarenzy/simple-k8s-app/blob/main/docs/problems.md#conflict",
+				"title":  "Conflict",
+				"status": http.StatusConflict,
+			})
+			return
+		}
+		writeJSON(w, http.StatusCreated, ip)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+f.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type result struct {
+	code           int
+	stdout, stderr string
+}
+
+func runCommand(t *testing.T, configPath string, args ...string) result {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string {
+		if key == "IPAMCTL_CONFIG" {
+			return configPath
+		}
+		return ""
+	}
+	code := newApp(&stdout, &stderr, getenv, http.DefaultClient).run(context.Background(), args)
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func TestContextsRoundTrip(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	if res := runCommand(t, configPath, "config", "set-context", "lab", "--server", "https://lab.example.com"); res.code != 0 {
+		t.Fatalf("set-context lab: %d %s", res.code, res.stderr)
+	}
+	if res := runCommand(t, configPath, "--server", "https://prod.example.com", "config", "set-context", "prod",
+		"--issuer", "https://sso.example.com", "--client-id", "ipamctl"); res.code != 0 {
+		t.Fatalf("set-context prod: %d %s", res.code, res.stderr)
+	}
+	if res := runCommand(t, configPath, "login", "--token", "secret"); res.code != 0 {
+		t.Fatalf("login --token: %d %s", res.code, res.stderr)
+	}
+	if res := runCommand(t, configPath, "config", "use-context", "prod"); res.code != 0 {
+		t.Fatalf("use-context: %d %s", res.code, res.stderr)
+	}
+
+	res := runCommand(t, configPath, "config", "current-context")
+	if res.code != 0 || strings.TrimSpace(res.stdout) != "prod" {
+		t.Fatalf("current-context = %d %q, want prod", res.code, res.stdout)
+	}
+	res = runCommand(t, configPath, "config", "get-contexts")
+	if !strings.Contains(res.stdout, "lab   https://lab.example.com   token") ||
+		!strings.Contains(res.stdout, "*        prod") {
+		t.Fatalf("get-contexts table:\n%s", res.stdout)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	lab, _ := cfg.context("lab")
+	prod, _ := cfg.context("prod")
+	if lab.Token != "secret" || prod.OIDC == nil || prod.OIDC.ClientID != "ipamctl" {
+		t.Fatalf("saved contexts = %+v", cfg.Contexts)
+	}
+	info, err := os.Stat(configPath)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("config mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+
+	if res := runCommand(t, configPath, "--context", "lab", "logout"); res.code != 0 {
+		t.Fatalf("logout: %d %s", res.code, res.stderr)
+	}
+	if res := runCommand(t, configPath, "config", "delete-context", "prod"); res.code != 0 {
+		t.Fatalf("delete-context: %d %s", res.code, res.stderr)
+	}
+	cfg, _ = loadConfig(configPath)
+	if len(cfg.Contexts) != 1 || cfg.Contexts[0].Token != "" || cfg.CurrentContext != "" {
+		t.Fatalf("config after logout and delete = %+v", cfg)
+	}
+}
+
+func TestAllocateRetriesAfterConflict(t *testing.T) {
+	api := &fakeAPI{token: "secret", ips: []client.IPAddress{{ID: "a", IP: "10.0.0.1", SubnetID: 7}}, conflicts: 1}
+	server := httptest.NewServer(api.handler())
+	t.Cleanup(server.Close)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	res := runCommand(t, configPath, "--server", server.URL, "--token", "secret",
+		"subnets", "allocate", "7", "--hostname", "web-1", "-o", "json")
+	if res.code != 0 {
+		t.Fatalf("allocate: %d %s", res.code, res.stderr)
+	}
+	var ip client.IPAddress
+	if err := json.Unmarshal([]byte(res.stdout), &ip); err != nil {
+		t.Fatalf("decode output %q: %v", res.stdout, err)
+	}
+	if ip.IP != "10.0.0.3" || ip.Hostname != "web-1" {
+		t.Fatalf("allocated %+v, want 10.0.0.3 after losing 10.0.0.2", ip)
+	}
+	if len(api.created) != 2 || api.created[0].IP != "10.0.0.2" {
+		t.Fatalf("create requests = %+v", api.created)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	api := &fakeAPI{token: "secret", ips: []client.IPAddress{{ID: "a", IP: "10.0.0.2", Hostname: "db", SubnetID: 7}}}
+	server := httptest.NewServer(api.handler())
+	t.Cleanup(server.Close)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if res := runCommand(t, configPath, "--server", server.URL, "config", "set-context", "test"); res.code != 0 {
+		t.Fatalf("set-context: %d %s", res.code, res.stderr)
+	}
+	if res := runCommand(t, configPath, "login", "--token", "secret"); res.code != 0 {
+		t.Fatalf("login: %d %s", res.code, res.stderr)
+	}
+
+	res := runCommand(t, configPath, "lookup", "10.0.0.2", "-o", "yaml")
+	if res.code != 0 {
+		t.Fatalf("lookup: %d %s", res.code, res.stderr)
+	}
+	for _, want := range []string{"ip: 10.0.0.2", "cidr: 10.0.0.0/29", "hostname: db"} {
+		if !strings.Contains(res.stdout, want) {
+			t.Fatalf("lookup output missing %q:\n%s", want, res.stdout)
+		}
+	}
+
+	res = runCommand(t, configPath, "lookup", "10.0.0.5")
+	if res.code != 0 || !strings.Contains(res.stdout, "free") {
+		t.Fatalf("lookup free address = %d %q %s", res.code, res.stdout, res.stderr)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for _, args := range [][]string{
+		nil,
+		{"frobnicate"},
+		{"subnets", "get"},
+		{"--server", "http://localhost", "subnets", "create", "10.0.0.0/24"},
+		{"-o", "xml", "--server", "http://localhost", "config", "get-contexts"},
+	} {
+		if res := runCommand(t, configPath, args...); res.code != 2 || !strings.Contains(res.stderr, "Usage:") {
+			t.Errorf("run(%q) = %d %q, want usage and exit code 2", args, res.code, res.stderr)
+		}
+	}
+	res := runCommand(t, configPath, "subnets", "list")
+	if res.code != 1 || !strings.Contains(res.stderr, "no context selected") {
+		t.Fatalf("run without context = %d %q", res.code, res.stderr)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+	"github.com/google/uuid"
+)
+
+func (a *app) runSites(ctx context.Context, args []string) error {
+	name, args, err := subcommand("sites", args)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "list", "ls":
+		return a.listSites(ctx, args)
+	case "get":
+		return a.getSite(ctx, args)
+	case "create":
+		return a.createSite(ctx, args)
+	case "update":
+		return a.updateSite(ctx, args)
+	case "rm", "delete":
+		return a.removeSite(ctx, args)
+	case "stats":
+		return a.siteStatistics(ctx, args)
+	default:
+		return usageError("unknown sites subcommand %q", name)
+	}
+}
+
+func (a *app) listSites(ctx context.Context, args []string) error {
+	positional, err := a.parse("sites list", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	sites, err := api.ListSites(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(sites, siteTable(sites...))
+}
+
+func (a *app) getSite(ctx context.Context, args []string) error {
+	positional, err := a.parse("sites get", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<id>"); err != nil {
+		return err
+	}
+	id, err := parseSiteID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	site, err := api.GetSite(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.print(site, siteTable(site))
+}
+
+func (a *app) createSite(ctx context.Context, args []string) error {
+	var description string
+	positional, err := a.parse("sites create", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&description, "description", "", "site description")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<name>"); err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	site, err := api.CreateSite(ctx, client.SiteRequest{Name: positional[0], Description: description})
+	if err != nil {
+		return err
+	}
+	return a.print(site, siteTable(site))
+}
+
+// updateSite replaces the name and keeps the description unless
+// --description is given.
+func (a *app) updateSite(ctx context.Context, args []string) error {
+	var description string
+	descriptionSet := false
+	positional, err := a.parse("sites update", args, func(fs *flag.FlagSet) {
+		fs.Func("description", "site description", func(value string) error {
+			description, descriptionSet = value, true
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<id>", "<name>"); err != nil {
+		return err
+	}
+	id, err := parseSiteID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	if !descriptionSet {
+		existing, err := api.GetSite(ctx, id)
+		if err != nil {
+			return err
+		}
+		description = existing.Description
+	}
+	site, err := api.UpdateSite(ctx, id, client.SiteRequest{Name: positional[1], Description: description})
+	if err != nil {
+		return err
+	}
+	return a.print(site, siteTable(site))
+}
+
+func (a *app) removeSite(ctx context.Context, args []string) error {
+	positional, err := a.parse("sites rm", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<id>"); err != nil {
+		return err
+	}
+	id, err := parseSiteID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	if err := api.DeleteSite(ctx, id); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deleted site %s.\n", id)
+	return nil
+}
+
+func (a *app) siteStatistics(ctx context.Context, args []string) error {
+	positional, err := a.parse("sites stats", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	statistics, err := api.SiteStatistics(ctx)
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"ID", "NAME", "SUBNETS", "USED", "TOTAL", "FREE"}}
+	for _, site := range statistics {
+		t.add(site.ID.String(), site.Name, strconv.FormatInt(site.SubnetCount, 10),
+			strconv.FormatInt(site.UsedIPCount, 10), strconv.FormatInt(site.TotalIPCount, 10),
+			strconv.FormatInt(site.FreeIPCount, 10))
+	}
+	return a.print(statistics, t)
+}
+
+func parseSiteID(value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid site id %q", value)
+	}
+	return id, nil
+}
+
+func siteTable(sites ...client.Site) table {
+	t := table{header: []string{"ID", "NAME", "SUBNETS", "USED", "TOTAL", "DESCRIPTION"}}
+	for _, site := range sites {
+		t.add(site.ID.String(), site.Name, strconv.FormatInt(site.SubnetCount, 10),
+			strconv.FormatInt(site.UsedIPs, 10), strconv.FormatInt(site.TotalIPs, 10), site.Description)
+	}
+	return t
+}
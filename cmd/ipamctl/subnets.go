@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+
+	"github.com/Flarenzy/simple-k8s-app/pkg/client"
+	"github.com/google/uuid"
+)
+
+// maxAllocateAttempts bounds how often allocate retries after another client
+// took the address it picked.
+const maxAllocateAttempts = 5
+
+func (a *app) runSubnets(ctx context.Context, args []string) error {
+	name, args, err := subcommand("subnets", args)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "list", "ls":
+		return a.listSubnets(ctx, args)
+	case "get":
+		return a.getSubnet(ctx, args)
+	case "create":
+		return a.createSubnet(ctx, args)
+	case "allocate":
+		return a.allocateIP(ctx, args)
+	default:
+		return usageError("unknown subnets subcommand %q", name)
+	}
+}
+
+func (a *app) listSubnets(ctx context.Context, args []string) error {
+	positional, err := a.parse("subnets list", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional); err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	subnets, err := api.ListSubnets(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(subnets, subnetTable(subnets...))
+}
+
+func (a *app) getSubnet(ctx context.Context, args []string) error {
+	positional, err := a.parse("subnets get", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<id>"); err != nil {
+		return err
+	}
+	id, err := parseSubnetID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	subnet, err := api.GetSubnet(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.print(subnet, subnetTable(subnet))
+}
+
+func (a *app) createSubnet(ctx context.Context, args []string) error {
+	var site, description string
+	positional, err := a.parse("subnets create", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&site, "site", "", "site id (required)")
+		fs.StringVar(&description, "description", "", "subnet description")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<cidr>"); err != nil {
+		return err
+	}
+	if site == "" {
+		return usageError("--site is required")
+	}
+	siteID, err := uuid.Parse(site)
+	if err != nil {
+		return fmt.Errorf("invalid site id %q", site)
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	subnet, err := api.CreateSubnet(ctx, client.SubnetRequest{CIDR: positional[0], SiteID: &siteID, Description: description})
+	if err != nil {
+		return err
+	}
+	return a.print(subnet, subnetTable(subnet))
+}
+
+// allocateIP records the first free address in a subnet. The API has no
+// allocation endpoint, so the address is chosen here and a conflict means
+// another client took it first; the next free address is tried.
+func (a *app) allocateIP(ctx context.Context, args []string) error {
+	var hostname string
+	positional, err := a.parse("subnets allocate", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&hostname, "hostname", "", "hostname for the allocated address")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs(positional, "<subnet-id>"); err != nil {
+		return err
+	}
+	id, err := parseSubnetID(positional[0])
+	if err != nil {
+		return err
+	}
+	api, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	subnet, err := api.GetSubnet(ctx, id)
+	if err != nil {
+		return err
+	}
+	prefix, err := netip.ParsePrefix(subnet.CIDR)
+	if err != nil {
+		return fmt.Errorf("subnet %d has invalid cidr %q: %w", id, subnet.CIDR, err)
+	}
+	for range maxAllocateAttempts {
+		ips, err := api.ListIPs(ctx, id)
+		if err != nil {
+			return err
+		}
+		used := make(map[netip.Addr]bool, len(ips))
+		for _, ip := range ips {
+			if addr, err := netip.ParseAddr(ip.IP); err == nil {
+				used[addr] = true
+			}
+		}
+		addr, ok := nextFreeAddress(prefix, used)
+		if !ok {
+			return fmt.Errorf("subnet %s has no free addresses", subnet.CIDR)
+		}
+		ip, err := api.CreateIP(ctx, id, client.CreateIPRequest{IP: addr.String(), Hostname: hostname})
+		if errors.Is(err, client.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return a.print(ip, ipTable(ip))
+	}
+	return fmt.Errorf("could not allocate an address in %s after %d attempts", subnet.CIDR, maxAllocateAttempts)
+}
+
+// nextFreeAddress returns the lowest address in prefix that is not in used.
+// IPv4 network and broadcast addresses are skipped except in /31 and /32.
+func nextFreeAddress(prefix netip.Prefix, used map[netip.Addr]bool) (netip.Addr, bool) {
+	prefix = prefix.Masked()
+	first := prefix.Addr()
+	last := lastAddress(prefix)
+	if first.Is4() && prefix.Bits() < 31 {
+		first, last = first.Next(), last.Prev()
+	}
+	for addr := first; addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+		if addr.Compare(last) > 0 {
+			break
+		}
+		if !used[addr] {
+			return addr, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+func lastAddress(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	value := new(big.Int).SetBytes(bytes)
+	hostBits := uint(len(bytes)*8 - prefix.Bits())
+	hostMask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), hostBits), big.NewInt(1))
+	value.Or(value, hostMask)
+	last, _ := netip.AddrFromSlice(value.FillBytes(make([]byte, len(bytes))))
+	return last
+}
+
+func parseSubnetID(value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid subnet id %q", value)
+	}
+	return id, nil
+}
+
+func subnetTable(subnets ...client.Subnet) table {
+	t := table{header: []string{"ID", "CIDR", "SITE", "USED", "TOTAL", "DESCRIPTION"}}
+	for _, subnet := range subnets {
+		site := ""
+		if subnet.SiteID != nil {
+			site = subnet.SiteID.String()
+		}
+		t.add(strconv.FormatInt(subnet.ID, 10), subnet.CIDR, site,
+			strconv.FormatInt(subnet.UsedIPs, 10), strconv.FormatInt(subnet.TotalIPs, 10), subnet.Description)
+	}
+	return t
+}
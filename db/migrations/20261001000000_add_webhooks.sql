@@ -0,0 +1,111 @@
+-- +goose Up
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    object_type TEXT NOT NULL CHECK (object_type IN ('subnet', 'ip', 'site')),
+    object_id TEXT NOT NULL,
+    site_id UUID,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_events_pending_idx
+    ON outbox_events (id)
+    WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_attempt_at TIMESTAMPTZ,
+    last_status_code INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE INDEX webhook_deliveries_dead_idx
+    ON webhook_deliveries (last_attempt_at DESC)
+    WHERE status = 'dead';
+
+-- The outbox is written by row triggers so every subnet, IP and site mutation
+-- (API, CSV import, cascades) records its change event in the same transaction.
+-- +goose StatementBegin
+CREATE FUNCTION record_outbox_event() RETURNS trigger AS $$
+DECLARE
+    event_object TEXT := TG_ARGV[0];
+    row_data JSONB;
+    event_site UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := to_jsonb(OLD);
+    ELSE
+        row_data := to_jsonb(NEW);
+    END IF;
+    IF TG_OP = 'UPDATE' AND to_jsonb(OLD) = row_data THEN
+        RETURN NULL;
+    END IF;
+
+    event_site := CASE event_object
+        WHEN 'site' THEN (row_data->>'id')::uuid
+        WHEN 'subnet' THEN (row_data->>'site_id')::uuid
+        WHEN 'ip' THEN (SELECT subnets.site_id FROM subnets WHERE subnets.id = (row_data->>'subnet_id')::bigint)
+    END;
+
+    INSERT INTO outbox_events (event_type, object_type, object_id, site_id, payload)
+    VALUES (
+        event_object || '.' || CASE TG_OP
+            WHEN 'INSERT' THEN 'created'
+            WHEN 'UPDATE' THEN 'updated'
+            ELSE 'deleted'
+        END,
+        event_object,
+        row_data->>'id',
+        event_site,
+        row_data
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER subnets_outbox
+    AFTER INSERT OR UPDATE OR DELETE ON subnets
+    FOR EACH ROW EXECUTE FUNCTION record_outbox_event('subnet');
+
+CREATE TRIGGER ip_addresses_outbox
+    AFTER INSERT OR UPDATE OR DELETE ON ip_addresses
+    FOR EACH ROW EXECUTE FUNCTION record_outbox_event('ip');
+
+CREATE TRIGGER sites_outbox
+    AFTER INSERT OR UPDATE OR DELETE ON sites
+    FOR EACH ROW EXECUTE FUNCTION record_outbox_event('site');
+
+-- +goose Down
+DROP TRIGGER sites_outbox ON sites;
+DROP TRIGGER ip_addresses_outbox ON ip_addresses;
+DROP TRIGGER subnets_outbox ON subnets;
+DROP FUNCTION record_outbox_event();
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_subscriptions;
+DROP TABLE outbox_events;
@@ -0,0 +1,103 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_outbox_event() RETURNS trigger AS $$
+DECLARE
+    event_object TEXT := TG_ARGV[0];
+    row_data JSONB;
+    event_site UUID;
+    event_subnet BIGINT;
+    event_id BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := to_jsonb(OLD);
+    ELSE
+        row_data := to_jsonb(NEW);
+    END IF;
+    IF TG_OP = 'UPDATE' AND to_jsonb(OLD) = row_data THEN
+        RETURN NULL;
+    END IF;
+
+    event_site := CASE event_object
+        WHEN 'site' THEN (row_data->>'id')::uuid
+        WHEN 'subnet' THEN (row_data->>'site_id')::uuid
+        WHEN 'ip' THEN (SELECT subnets.site_id FROM subnets WHERE subnets.id = (row_data->>'subnet_id')::bigint)
+    END;
+
+    event_subnet := CASE event_object
+        WHEN 'subnet' THEN (row_data->>'id')::bigint
+        WHEN 'ip' THEN (row_data->>'subnet_id')::bigint
+    END;
+
+    INSERT INTO outbox_events (event_type, object_type, object_id, site_id, payload)
+    VALUES (
+        event_object || '.' || CASE TG_OP
+            WHEN 'INSERT' THEN 'created'
+            WHEN 'UPDATE' THEN 'updated'
+            ELSE 'deleted'
+        END,
+        event_object,
+        row_data->>'id',
+        event_site,
+        row_data
+    )
+    RETURNING id INTO event_id;
+
+    -- Live listeners only receive identifiers; NOTIFY payloads are capped at
+    -- 8000 bytes and clients refetch the rows they display.
+    PERFORM pg_notify('ipam_events', json_build_object(
+        'id', event_id,
+        'type', event_object || '.' || CASE TG_OP
+            WHEN 'INSERT' THEN 'created'
+            WHEN 'UPDATE' THEN 'updated'
+            ELSE 'deleted'
+        END,
+        'object_type', event_object,
+        'object_id', row_data->>'id',
+        'site_id', event_site,
+        'subnet_id', event_subnet,
+        'occurred_at', now()
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_outbox_event() RETURNS trigger AS $$
+DECLARE
+    event_object TEXT := TG_ARGV[0];
+    row_data JSONB;
+    event_site UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := to_jsonb(OLD);
+    ELSE
+        row_data := to_jsonb(NEW);
+    END IF;
+    IF TG_OP = 'UPDATE' AND to_jsonb(OLD) = row_data THEN
+        RETURN NULL;
+    END IF;
+
+    event_site := CASE event_object
+        WHEN 'site' THEN (row_data->>'id')::uuid
+        WHEN 'subnet' THEN (row_data->>'site_id')::uuid
+        WHEN 'ip' THEN (SELECT subnets.site_id FROM subnets WHERE subnets.id = (row_data->>'subnet_id')::bigint)
+    END;
+
+    INSERT INTO outbox_events (event_type, object_type, object_id, site_id, payload)
+    VALUES (
+        event_object || '.' || CASE TG_OP
+            WHEN 'INSERT' THEN 'created'
+            WHEN 'UPDATE' THEN 'updated'
+            ELSE 'deleted'
+        END,
+        event_object,
+        row_data->>'id',
+        event_site,
+        row_data
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
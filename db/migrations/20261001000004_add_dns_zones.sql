@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE dns_zones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    site_id UUID REFERENCES sites(id) ON DELETE CASCADE,
+    subnet_id BIGINT REFERENCES subnets(id) ON DELETE CASCADE,
+    ttl INTEGER NOT NULL DEFAULT 3600 CHECK (ttl > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT dns_zones_single_attachment CHECK ((site_id IS NULL) <> (subnet_id IS NULL))
+);
+
+CREATE UNIQUE INDEX dns_zones_site_id_key ON dns_zones (site_id) WHERE site_id IS NOT NULL;
+CREATE UNIQUE INDEX dns_zones_subnet_id_key ON dns_zones (subnet_id) WHERE subnet_id IS NOT NULL;
+
+-- Serials are kept per zone name so that computed reverse zones, which have
+-- no dns_zones row, get stable serials too.
+CREATE TABLE dns_zone_serials (
+    zone TEXT PRIMARY KEY,
+    serial BIGINT NOT NULL,
+    content_hash TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dns_zone_serials;
+DROP TABLE IF EXISTS dns_zones;
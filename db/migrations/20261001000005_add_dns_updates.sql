@@ -0,0 +1,106 @@
+-- +goose Up
+-- One row per address whose DNS records may be out of date. Triggers
+-- re-enqueue an address by bumping its generation, so a worker that finishes
+-- an older generation leaves the newer request queued. Addresses are queued
+-- even while dynamic updates are disabled; keying by address bounds the table.
+CREATE TABLE dns_update_queue (
+    ip INET PRIMARY KEY,
+    generation BIGINT NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    enqueued_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX dns_update_queue_next_attempt_at_idx ON dns_update_queue (next_attempt_at);
+
+-- The records last pushed to the DNS server for each address, so a rename or
+-- delete knows which forward record to remove.
+CREATE TABLE dns_registrations (
+    ip INET PRIMARY KEY,
+    name TEXT NOT NULL,
+    zone TEXT NOT NULL,
+    reverse_zone TEXT NOT NULL,
+    ttl INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION enqueue_dns_updates(ips INET[]) RETURNS void AS $$
+    INSERT INTO dns_update_queue (ip)
+    SELECT DISTINCT unnest(ips)
+    ON CONFLICT (ip) DO UPDATE
+    SET generation = dns_update_queue.generation + 1,
+        attempts = 0,
+        next_attempt_at = now(),
+        enqueued_at = now();
+$$ LANGUAGE sql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION enqueue_ip_dns_update() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM enqueue_dns_updates(ARRAY[NEW.ip]);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM enqueue_dns_updates(ARRAY[OLD.ip]);
+    ELSIF (OLD.ip, OLD.hostname, OLD.subnet_id) IS DISTINCT FROM (NEW.ip, NEW.hostname, NEW.subnet_id) THEN
+        PERFORM enqueue_dns_updates(ARRAY[OLD.ip, NEW.ip]);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Attaching or removing a zone, or moving a subnet to another site, changes
+-- the names of every address in the affected subnets.
+-- +goose StatementBegin
+CREATE FUNCTION enqueue_zone_dns_updates() RETURNS trigger AS $$
+DECLARE
+    changed_site UUID;
+    changed_subnet BIGINT;
+BEGIN
+    IF TG_TABLE_NAME = 'subnets' THEN
+        IF OLD.site_id IS NOT DISTINCT FROM NEW.site_id THEN
+            RETURN NULL;
+        END IF;
+        changed_subnet := NEW.id;
+    ELSIF TG_OP = 'DELETE' THEN
+        changed_site := OLD.site_id;
+        changed_subnet := OLD.subnet_id;
+    ELSE
+        changed_site := NEW.site_id;
+        changed_subnet := NEW.subnet_id;
+    END IF;
+    PERFORM enqueue_dns_updates(ARRAY(
+        SELECT ip_addresses.ip
+        FROM ip_addresses
+        JOIN subnets ON subnets.id = ip_addresses.subnet_id
+        WHERE subnets.id = changed_subnet OR subnets.site_id = changed_site
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ip_addresses_dns_update
+    AFTER INSERT OR UPDATE OR DELETE ON ip_addresses
+    FOR EACH ROW EXECUTE FUNCTION enqueue_ip_dns_update();
+
+CREATE TRIGGER dns_zones_dns_update
+    AFTER INSERT OR DELETE ON dns_zones
+    FOR EACH ROW EXECUTE FUNCTION enqueue_zone_dns_updates();
+
+CREATE TRIGGER subnets_dns_update
+    AFTER UPDATE OF site_id ON subnets
+    FOR EACH ROW EXECUTE FUNCTION enqueue_zone_dns_updates();
+
+-- +goose Down
+DROP TRIGGER subnets_dns_update ON subnets;
+DROP TRIGGER dns_zones_dns_update ON dns_zones;
+DROP TRIGGER ip_addresses_dns_update ON ip_addresses;
+DROP FUNCTION enqueue_zone_dns_updates();
+DROP FUNCTION enqueue_ip_dns_update();
+DROP FUNCTION enqueue_dns_updates(INET[]);
+DROP TABLE IF EXISTS dns_registrations;
+DROP TABLE IF EXISTS dns_update_queue;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- DHCP settings rendered into Kea and dnsmasq configuration. Pools are
+-- inclusive address ranges stored as [{"start": "...", "end": "..."}].
+ALTER TABLE subnets
+    ADD COLUMN gateway INET,
+    ADD COLUMN dns_servers INET[] NOT NULL DEFAULT '{}',
+    ADD COLUMN dhcp_pools JSONB NOT NULL DEFAULT '[]';
+
+-- A MAC address turns the record into a DHCP host reservation, so it may
+-- appear once per subnet.
+ALTER TABLE ip_addresses ADD COLUMN mac_address MACADDR;
+
+CREATE UNIQUE INDEX ip_addresses_subnet_mac_key
+    ON ip_addresses (subnet_id, mac_address)
+    WHERE mac_address IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS ip_addresses_subnet_mac_key;
+ALTER TABLE ip_addresses DROP COLUMN IF EXISTS mac_address;
+ALTER TABLE subnets
+    DROP COLUMN IF EXISTS dhcp_pools,
+    DROP COLUMN IF EXISTS dns_servers,
+    DROP COLUMN IF EXISTS gateway;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- When a DHCP lease last showed the address in use. NULL means it was never
+-- seen in a lease file.
+ALTER TABLE ip_addresses ADD COLUMN last_seen_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE ip_addresses DROP COLUMN IF EXISTS last_seen_at;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Sources created through the API carry their own connection settings;
+-- sources from the environment or a sources file keep the defaults and
+-- managed = false.
+ALTER TABLE kubernetes_sources
+    ADD COLUMN managed BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN auth_mode TEXT NOT NULL DEFAULT 'in_cluster' CHECK (auth_mode IN ('in_cluster', 'kubeconfig')),
+    ADD COLUMN kubeconfig_ciphertext BYTEA,
+    ADD COLUMN kubeconfig_context TEXT NOT NULL DEFAULT '',
+    ADD COLUMN reconcile_interval_seconds INTEGER NOT NULL DEFAULT 300 CHECK (reconcile_interval_seconds > 0),
+    ADD COLUMN request_timeout_seconds INTEGER NOT NULL DEFAULT 15 CHECK (request_timeout_seconds > 0),
+    ADD COLUMN stale_retention_seconds INTEGER NOT NULL DEFAULT 604800 CHECK (stale_retention_seconds > 0);
+
+-- +goose Down
+ALTER TABLE kubernetes_sources
+    DROP COLUMN stale_retention_seconds,
+    DROP COLUMN request_timeout_seconds,
+    DROP COLUMN reconcile_interval_seconds,
+    DROP COLUMN kubeconfig_context,
+    DROP COLUMN kubeconfig_ciphertext,
+    DROP COLUMN auth_mode,
+    DROP COLUMN managed;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Services are always discovered; Nodes, Pods and EndpointSlices only for
+-- the kinds a source lists in object_kinds.
+ALTER TABLE kubernetes_sources
+    ADD COLUMN object_kinds TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN pod_selector TEXT NOT NULL DEFAULT '',
+    ADD COLUMN object_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE kubernetes_objects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_id UUID NOT NULL REFERENCES kubernetes_sources(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('node', 'pod', 'endpoint_slice')),
+    kubernetes_uid TEXT NOT NULL,
+    namespace TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    host_network BOOLEAN NOT NULL DEFAULT false,
+    resource_version TEXT NOT NULL,
+    observed_at TIMESTAMPTZ NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    stale_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT kubernetes_objects_source_uid_unique UNIQUE (source_id, kubernetes_uid)
+);
+
+-- node_name is the Node the address belongs to or runs on: the Node itself,
+-- a Pod's spec.nodeName, or an endpoint's nodeName.
+CREATE TABLE kubernetes_object_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    object_id UUID NOT NULL REFERENCES kubernetes_objects(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('internal_ip', 'external_ip', 'pod_ip', 'endpoint')),
+    address INET NOT NULL,
+    node_name TEXT NOT NULL DEFAULT '',
+    ip_address_id UUID REFERENCES ip_addresses(id) ON DELETE SET NULL,
+    match_status TEXT NOT NULL CHECK (match_status IN ('matched', 'unmatched', 'ambiguous')),
+    match_count INTEGER NOT NULL,
+    CONSTRAINT kubernetes_object_addresses_unique UNIQUE (object_id, kind, address)
+);
+
+CREATE INDEX kubernetes_object_addresses_ip_idx
+    ON kubernetes_object_addresses (ip_address_id)
+    WHERE ip_address_id IS NOT NULL;
+
+CREATE INDEX kubernetes_object_addresses_address_idx
+    ON kubernetes_object_addresses USING gist (address inet_ops);
+
+-- +goose Down
+DROP TABLE kubernetes_object_addresses;
+DROP TABLE kubernetes_objects;
+ALTER TABLE kubernetes_sources
+    DROP COLUMN object_count,
+    DROP COLUMN pod_selector,
+    DROP COLUMN object_kinds;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Ingresses, Gateways and HTTPRoutes are objects too. Their status addresses
+-- are load_balancer addresses; an HTTPRoute gets its parent Gateways'
+-- addresses as gateway addresses.
+ALTER TABLE kubernetes_objects
+    DROP CONSTRAINT kubernetes_objects_kind_check,
+    ADD CONSTRAINT kubernetes_objects_kind_check
+        CHECK (kind IN ('node', 'pod', 'endpoint_slice', 'ingress', 'gateway', 'http_route'));
+
+ALTER TABLE kubernetes_object_addresses
+    DROP CONSTRAINT kubernetes_object_addresses_kind_check,
+    ADD CONSTRAINT kubernetes_object_addresses_kind_check
+        CHECK (kind IN ('internal_ip', 'external_ip', 'pod_ip', 'endpoint', 'load_balancer', 'gateway'));
+
+CREATE TABLE kubernetes_object_hostnames (
+    id BIGSERIAL PRIMARY KEY,
+    object_id UUID NOT NULL REFERENCES kubernetes_objects(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('host', 'load_balancer')),
+    hostname TEXT NOT NULL,
+    CONSTRAINT kubernetes_object_hostnames_unique UNIQUE (object_id, kind, hostname)
+);
+
+-- +goose Down
+DROP TABLE kubernetes_object_hostnames;
+DELETE FROM kubernetes_objects WHERE kind IN ('ingress', 'gateway', 'http_route');
+ALTER TABLE kubernetes_object_addresses
+    DROP CONSTRAINT kubernetes_object_addresses_kind_check,
+    ADD CONSTRAINT kubernetes_object_addresses_kind_check
+        CHECK (kind IN ('internal_ip', 'external_ip', 'pod_ip', 'endpoint'));
+ALTER TABLE kubernetes_objects
+    DROP CONSTRAINT kubernetes_objects_kind_check,
+    ADD CONSTRAINT kubernetes_objects_kind_check
+        CHECK (kind IN ('node', 'pod', 'endpoint_slice'));
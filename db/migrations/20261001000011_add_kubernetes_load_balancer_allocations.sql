@@ -0,0 +1,15 @@
+-- +goose Up
+-- Addresses the LoadBalancer allocator gave to Services. The address row is
+-- the allocation: deleting it, by release or by hand, removes the owner.
+CREATE TABLE kubernetes_load_balancer_allocations (
+    ip_address_id UUID PRIMARY KEY REFERENCES ip_addresses(id) ON DELETE CASCADE,
+    controller_key TEXT NOT NULL,
+    service_uid TEXT NOT NULL,
+    namespace TEXT NOT NULL,
+    name TEXT NOT NULL,
+    allocated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT kubernetes_load_balancer_allocations_service_unique UNIQUE (controller_key, service_uid)
+);
+
+-- +goose Down
+DROP TABLE kubernetes_load_balancer_allocations;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- auto_register decides what a source does with addresses inside a subnet
+-- of its site that have no IP address record: off leaves them unmatched,
+-- register records every such address and register_lb_only only
+-- LoadBalancer addresses.
+ALTER TABLE kubernetes_sources
+    ADD COLUMN auto_register TEXT NOT NULL DEFAULT 'off'
+        CHECK (auto_register IN ('off', 'register', 'register_lb_only'));
+
+-- Address records discovery created. The address row is the registration:
+-- deleting it, by cleanup or by hand, removes the owner.
+CREATE TABLE kubernetes_registered_addresses (
+    ip_address_id UUID PRIMARY KEY REFERENCES ip_addresses(id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES kubernetes_sources(id) ON DELETE CASCADE,
+    service_uid TEXT NOT NULL,
+    namespace TEXT NOT NULL,
+    name TEXT NOT NULL,
+    registered_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX kubernetes_registered_addresses_service_idx
+    ON kubernetes_registered_addresses (source_id, service_uid);
+
+-- +goose Down
+DROP TABLE kubernetes_registered_addresses;
+ALTER TABLE kubernetes_sources DROP COLUMN auto_register;
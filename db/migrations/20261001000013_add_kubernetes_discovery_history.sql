@@ -0,0 +1,44 @@
+-- +goose Up
+-- One row per publication of a source's Services, complete or incremental,
+-- and per failed attempt. Runs older than the source's stale retention are
+-- deleted with their events.
+CREATE TABLE kubernetes_discovery_runs (
+    id BIGSERIAL PRIMARY KEY,
+    source_id UUID NOT NULL REFERENCES kubernetes_sources(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    service_count INTEGER NOT NULL DEFAULT 0,
+    matched_count INTEGER NOT NULL DEFAULT 0,
+    unmatched_count INTEGER NOT NULL DEFAULT 0,
+    ambiguous_count INTEGER NOT NULL DEFAULT 0,
+    no_usable_ip_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX kubernetes_discovery_runs_source_idx
+    ON kubernetes_discovery_runs (source_id, started_at DESC, id DESC);
+
+-- The Service changes a run made. Addresses are kept as they were, so the
+-- previous address of a Service survives its observation.
+CREATE TABLE kubernetes_discovery_events (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES kubernetes_discovery_runs(id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES kubernetes_sources(id) ON DELETE CASCADE,
+    service_uid TEXT NOT NULL,
+    namespace TEXT NOT NULL,
+    name TEXT NOT NULL,
+    change TEXT NOT NULL CHECK (change IN ('appeared', 'address_changed', 'stale', 'purged')),
+    previous_addresses INET[] NOT NULL DEFAULT '{}',
+    addresses INET[] NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX kubernetes_discovery_events_source_idx
+    ON kubernetes_discovery_events (source_id, occurred_at DESC, id DESC);
+
+CREATE INDEX kubernetes_discovery_events_run_idx
+    ON kubernetes_discovery_events (run_id);
+
+-- +goose Down
+DROP TABLE kubernetes_discovery_events;
+DROP TABLE kubernetes_discovery_runs;
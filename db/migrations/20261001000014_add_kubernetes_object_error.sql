@@ -0,0 +1,8 @@
+-- +goose Up
+-- The error of the latest object listing, kept apart from last_error: a
+-- source whose objects cannot be listed still publishes its Services.
+ALTER TABLE kubernetes_sources
+    ADD COLUMN object_error TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE kubernetes_sources DROP COLUMN object_error;
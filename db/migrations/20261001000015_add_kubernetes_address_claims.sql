@@ -0,0 +1,15 @@
+-- +goose Up
+-- Addresses the Cluster API IPAM provider recorded for IPAddressClaims. As
+-- with LoadBalancer allocations, the address row is the claim's: deleting
+-- it, by release or by hand, removes the link.
+CREATE TABLE kubernetes_address_claims (
+    ip_address_id UUID PRIMARY KEY REFERENCES ip_addresses(id) ON DELETE CASCADE,
+    claim_uid TEXT NOT NULL,
+    namespace TEXT NOT NULL,
+    name TEXT NOT NULL,
+    claimed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT kubernetes_address_claims_claim_unique UNIQUE (claim_uid)
+);
+
+-- +goose Down
+DROP TABLE kubernetes_address_claims;
@@ -0,0 +1,67 @@
+-- name: ListDNSZones :many
+SELECT id, name, site_id, subnet_id, ttl, created_at, updated_at
+FROM dns_zones
+ORDER BY name;
+
+-- name: GetDNSZoneByName :one
+SELECT id, name, site_id, subnet_id, ttl, created_at, updated_at
+FROM dns_zones
+WHERE name = $1;
+
+-- name: GetDNSZoneForSubnet :one
+-- A zone attached to the subnet itself takes precedence over its site's.
+SELECT z.id, z.name, z.site_id, z.subnet_id, z.ttl, z.created_at, z.updated_at
+FROM subnets s
+JOIN dns_zones z ON z.subnet_id = s.id OR (z.site_id IS NOT NULL AND z.site_id = s.site_id)
+WHERE s.id = $1
+ORDER BY z.subnet_id IS NULL
+LIMIT 1;
+
+-- name: CreateDNSZone :one
+INSERT INTO dns_zones (name, site_id, subnet_id, ttl)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, site_id, subnet_id, ttl, created_at, updated_at;
+
+-- name: DeleteDNSZoneByName :execrows
+DELETE FROM dns_zones
+WHERE name = $1;
+
+-- name: ListDNSHostRecords :many
+-- Every named address with the forward zone it belongs to, if any.
+SELECT ip.ip, ip.hostname, ip.subnet_id, s.cidr,
+       COALESCE(subnet_zone.name, site_zone.name, '')::text AS zone,
+       COALESCE(subnet_zone.ttl, site_zone.ttl, 0)::integer AS ttl
+FROM ip_addresses ip
+JOIN subnets s ON s.id = ip.subnet_id
+LEFT JOIN dns_zones subnet_zone ON subnet_zone.subnet_id = s.id
+LEFT JOIN dns_zones site_zone ON site_zone.site_id = s.site_id
+WHERE ip.hostname <> ''
+ORDER BY ip.ip, ip.created_at;
+
+-- name: ListDNSHostRecordsForIP :many
+SELECT ip.ip, ip.hostname, ip.subnet_id, s.cidr,
+       COALESCE(subnet_zone.name, site_zone.name, '')::text AS zone,
+       COALESCE(subnet_zone.ttl, site_zone.ttl, 0)::integer AS ttl
+FROM ip_addresses ip
+JOIN subnets s ON s.id = ip.subnet_id
+LEFT JOIN dns_zones subnet_zone ON subnet_zone.subnet_id = s.id
+LEFT JOIN dns_zones site_zone ON site_zone.site_id = s.site_id
+WHERE ip.hostname <> '' AND ip.ip = $1
+ORDER BY ip.created_at;
+
+-- name: BumpDNSZoneSerial :one
+-- The serial only moves when the zone content hash changes. It becomes the
+-- larger of the next serial and today's YYYYMMDD01 candidate.
+INSERT INTO dns_zone_serials AS current (zone, serial, content_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (zone) DO UPDATE
+SET serial = CASE
+        WHEN current.content_hash = EXCLUDED.content_hash THEN current.serial
+        ELSE GREATEST(current.serial + 1, EXCLUDED.serial)
+    END,
+    content_hash = EXCLUDED.content_hash,
+    updated_at = CASE
+        WHEN current.content_hash = EXCLUDED.content_hash THEN current.updated_at
+        ELSE now()
+    END
+RETURNING serial;
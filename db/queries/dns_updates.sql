@@ -0,0 +1,53 @@
+-- name: ClaimDueDNSUpdates :many
+WITH due AS (
+    SELECT ip
+    FROM dns_update_queue
+    WHERE next_attempt_at <= now()
+    ORDER BY next_attempt_at, ip
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE dns_update_queue
+SET attempts = dns_update_queue.attempts + 1,
+    next_attempt_at = $2::timestamptz
+FROM due
+WHERE dns_update_queue.ip = due.ip
+RETURNING dns_update_queue.ip, dns_update_queue.generation, dns_update_queue.attempts;
+
+-- name: CompleteDNSUpdate :exec
+-- A newer generation was enqueued while this one ran and stays queued.
+DELETE FROM dns_update_queue
+WHERE ip = $1 AND generation = $2;
+
+-- name: FailDNSUpdate :exec
+UPDATE dns_update_queue
+SET next_attempt_at = $3,
+    last_error = $4
+WHERE ip = $1 AND generation = $2;
+
+-- name: EnqueueDNSUpdates :exec
+SELECT enqueue_dns_updates(@ips::inet[]);
+
+-- name: GetDNSRegistration :one
+SELECT ip, name, zone, reverse_zone, ttl, updated_at
+FROM dns_registrations
+WHERE ip = $1;
+
+-- name: ListDNSRegistrations :many
+SELECT ip, name, zone, reverse_zone, ttl, updated_at
+FROM dns_registrations
+ORDER BY ip;
+
+-- name: UpsertDNSRegistration :exec
+INSERT INTO dns_registrations (ip, name, zone, reverse_zone, ttl)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (ip) DO UPDATE
+SET name = EXCLUDED.name,
+    zone = EXCLUDED.zone,
+    reverse_zone = EXCLUDED.reverse_zone,
+    ttl = EXCLUDED.ttl,
+    updated_at = now();
+
+-- name: DeleteDNSRegistration :exec
+DELETE FROM dns_registrations
+WHERE ip = $1;
@@ -0,0 +1,2 @@
+-- name: NotifyChangeEvent :exec
+SELECT pg_notify('ipam_events', $1::text);
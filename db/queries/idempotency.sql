@@ -0,0 +1,36 @@
+-- name: ClaimIdempotencyKey :one
+-- Inserts a pending key, or takes over one that has expired or whose
+-- request was abandoned before completing. Returns no row when the key is
+-- held by a live or completed request.
+INSERT INTO idempotency_keys (scope, idempotency_key, method, path, request_hash, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (scope, idempotency_key) DO UPDATE
+SET method = EXCLUDED.method,
+    path = EXCLUDED.path,
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    content_type = '',
+    response_body = NULL,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= $8::timestamptz)
+RETURNING scope, idempotency_key, method, path, request_hash, status_code, content_type, response_body, created_at, expires_at;
+
+-- name: GetIdempotencyKey :one
+SELECT scope, idempotency_key, method, path, request_hash, status_code, content_type, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE scope = $1 AND idempotency_key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE scope = $1 AND idempotency_key = $2;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE scope = $1 AND idempotency_key = $2 AND status_code IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1;
@@ -1,19 +1,19 @@
 -- name: ListIPsBySubnetID :many
-SELECT id, ip, hostname, created_at, updated_at, subnet_id
+SELECT id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at
 FROM ip_addresses
 WHERE subnet_id = $1
 ORDER by ip;
 
 -- name: CreateIPAddress :one
-INSERT INTO ip_addresses (ip, hostname, subnet_id)
-VALUES ($1, $2, $3)
-RETURNING id, ip, hostname, created_at, updated_at, subnet_id;
+INSERT INTO ip_addresses (ip, hostname, subnet_id, mac_address, last_seen_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at;
 
 -- name: UpdateIPByUUID :one
 UPDATE ip_addresses
-SET hostname = $1, updated_at = NOW()
+SET hostname = $1, mac_address = $3, last_seen_at = $4, updated_at = NOW()
 WHERE id = $2
-RETURNING id, ip, hostname, created_at, updated_at, subnet_id;
+RETURNING id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at;
 
 -- name: GetIPByUUIDandSubnetID :one
 SELECT * FROM ip_addresses
@@ -0,0 +1,18 @@
+-- name: GetKubernetesAddressClaim :one
+SELECT claim.ip_address_id, claim.claim_uid, claim.namespace, claim.name, claim.claimed_at,
+    ip.ip, ip.subnet_id
+FROM kubernetes_address_claims claim
+JOIN ip_addresses ip ON ip.id = claim.ip_address_id
+WHERE claim.claim_uid = $1;
+
+-- name: CreateKubernetesAddressClaim :one
+INSERT INTO kubernetes_address_claims (ip_address_id, claim_uid, namespace, name)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteKubernetesAddressClaimAddress :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT ip_address_id FROM kubernetes_address_claims
+    WHERE claim_uid = $1
+);
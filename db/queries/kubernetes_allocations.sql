@@ -0,0 +1,35 @@
+-- name: LockSubnetAllocations :exec
+SELECT pg_advisory_xact_lock(hashtextextended('ip_allocation:' || sqlc.arg(subnet_id)::bigint::text, 0));
+
+-- name: GetKubernetesAllocation :one
+SELECT alloc.ip_address_id, alloc.controller_key, alloc.service_uid, alloc.namespace, alloc.name, alloc.allocated_at,
+    ip.ip, ip.subnet_id
+FROM kubernetes_load_balancer_allocations alloc
+JOIN ip_addresses ip ON ip.id = alloc.ip_address_id
+WHERE alloc.controller_key = $1 AND alloc.service_uid = $2;
+
+-- name: CreateKubernetesAllocation :one
+INSERT INTO kubernetes_load_balancer_allocations (ip_address_id, controller_key, service_uid, namespace, name)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: DeleteKubernetesAllocationAddress :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT ip_address_id FROM kubernetes_load_balancer_allocations
+    WHERE controller_key = $1 AND service_uid = $2
+);
+
+-- name: DeleteKubernetesAllocationAddressesExcept :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT ip_address_id FROM kubernetes_load_balancer_allocations
+    WHERE controller_key = $1 AND NOT (service_uid = ANY(sqlc.arg(service_uids)::text[]))
+        AND allocated_at < sqlc.arg(listed_at)::timestamptz
+);
+
+-- name: ListKubernetesAllocationsBySubnet :many
+SELECT alloc.*
+FROM kubernetes_load_balancer_allocations alloc
+JOIN ip_addresses ip ON ip.id = alloc.ip_address_id
+WHERE ip.subnet_id = $1;
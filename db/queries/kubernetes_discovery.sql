@@ -3,20 +3,24 @@ SELECT pg_try_advisory_xact_lock(hashtextextended($1, 0));
 
 -- name: UpsertKubernetesSource :one
 INSERT INTO kubernetes_sources (
-    source_key, name, site_id, cluster_domain, namespace_scope, updated_at
-) VALUES ($1, $2, $3, $4, $5::text[], now())
+    source_key, name, site_id, cluster_domain, namespace_scope, object_kinds, pod_selector, auto_register, updated_at
+) VALUES ($1, $2, $3, $4, $5::text[], $6::text[], $7, $8, now())
 ON CONFLICT (source_key) DO UPDATE SET
     name = EXCLUDED.name,
     site_id = EXCLUDED.site_id,
     cluster_domain = EXCLUDED.cluster_domain,
     namespace_scope = EXCLUDED.namespace_scope,
+    object_kinds = EXCLUDED.object_kinds,
+    pod_selector = EXCLUDED.pod_selector,
+    auto_register = EXCLUDED.auto_register,
     updated_at = now()
+WHERE NOT kubernetes_sources.managed
 RETURNING *;
 
 -- name: EnsureKubernetesSource :exec
 INSERT INTO kubernetes_sources (
-    source_key, name, site_id, cluster_domain, namespace_scope
-) VALUES ($1, $2, $3, $4, $5::text[])
+    source_key, name, site_id, cluster_domain, namespace_scope, object_kinds, pod_selector, auto_register
+) VALUES ($1, $2, $3, $4, $5::text[], $6::text[], $7, $8)
 ON CONFLICT (source_key) DO NOTHING;
 
 -- name: GetKubernetesSourceByKey :one
@@ -66,20 +70,103 @@ INSERT INTO kubernetes_service_addresses (
 -- name: DeleteKubernetesServiceHostnames :exec
 DELETE FROM kubernetes_service_hostnames WHERE service_id = $1;
 
+-- name: FindKubernetesRegistrationSubnet :one
+-- The most specific subnet of the site that contains the address.
+SELECT id, cidr
+FROM subnets
+WHERE site_id = $1 AND cidr >>= sqlc.arg(address)::inet
+ORDER BY masklen(cidr) DESC, id
+LIMIT 1;
+
+-- name: CreateKubernetesRegisteredIP :one
+-- No row is returned when the address was recorded concurrently.
+INSERT INTO ip_addresses (ip, hostname, subnet_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (ip, subnet_id) DO NOTHING
+RETURNING id;
+
+-- name: CreateKubernetesRegisteredAddress :exec
+INSERT INTO kubernetes_registered_addresses (ip_address_id, source_id, service_uid, namespace, name)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: DeleteOrphanedKubernetesRegisteredAddresses :execrows
+-- Deletes the address records the source registered for Services that no
+-- longer report them, because the Service is gone or its address changed,
+-- unless an active Service still reports the address.
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT reg.ip_address_id
+    FROM kubernetes_registered_addresses reg
+    WHERE reg.source_id = $1
+      AND NOT EXISTS (
+          SELECT 1
+          FROM kubernetes_service_addresses a
+          JOIN kubernetes_services svc ON svc.id = a.service_id
+          WHERE a.ip_address_id = reg.ip_address_id
+            AND (svc.active OR (svc.source_id = reg.source_id AND svc.kubernetes_uid = reg.service_uid))
+      )
+);
+
+-- name: ListKubernetesRegisteredAddressesBySubnet :many
+SELECT reg.ip_address_id, reg.service_uid, reg.namespace, reg.name, reg.registered_at,
+       src.source_key, src.name AS source_name
+FROM kubernetes_registered_addresses reg
+JOIN ip_addresses ip ON ip.id = reg.ip_address_id
+JOIN kubernetes_sources src ON src.id = reg.source_id
+WHERE ip.subnet_id = $1;
+
 -- name: CreateKubernetesServiceHostname :exec
 INSERT INTO kubernetes_service_hostnames (service_id, kind, hostname)
 VALUES ($1, $2, $3);
 
--- name: MarkMissingKubernetesServicesInactive :exec
+-- name: GetKubernetesServiceHistoryState :one
+-- The state a Service is compared against before it is replaced.
+SELECT svc.active,
+       ARRAY(
+           SELECT DISTINCT a.address FROM kubernetes_service_addresses a
+           WHERE a.service_id = svc.id ORDER BY a.address
+       )::inet[] AS addresses
+FROM kubernetes_services svc
+WHERE svc.source_id = $1 AND svc.kubernetes_uid = $2;
+
+-- name: MarkMissingKubernetesServicesInactive :many
 UPDATE kubernetes_services
 SET active = false, stale_at = $3, updated_at = now()
 WHERE source_id = $1
   AND active = true
-  AND NOT (kubernetes_uid = ANY($2::text[]));
+  AND NOT (kubernetes_uid = ANY($2::text[]))
+RETURNING kubernetes_uid, namespace, name,
+    ARRAY(
+        SELECT DISTINCT a.address FROM kubernetes_service_addresses a
+        WHERE a.service_id = kubernetes_services.id ORDER BY a.address
+    )::inet[] AS addresses;
+
+-- name: MarkKubernetesServicesInactive :many
+UPDATE kubernetes_services
+SET active = false, stale_at = $3, updated_at = now()
+WHERE source_id = $1
+  AND active = true
+  AND kubernetes_uid = ANY($2::text[])
+RETURNING kubernetes_uid, namespace, name,
+    ARRAY(
+        SELECT DISTINCT a.address FROM kubernetes_service_addresses a
+        WHERE a.service_id = kubernetes_services.id ORDER BY a.address
+    )::inet[] AS addresses;
+
+-- name: CountKubernetesSourceObservations :one
+SELECT count(DISTINCT svc.id)::integer AS services,
+       count(a.service_id) FILTER (WHERE a.match_status = 'matched')::integer AS matched,
+       count(a.service_id) FILTER (WHERE a.match_status = 'unmatched')::integer AS unmatched,
+       count(a.service_id) FILTER (WHERE a.match_status = 'ambiguous')::integer AS ambiguous,
+       count(DISTINCT svc.id) FILTER (WHERE a.service_id IS NULL)::integer AS no_usable_ip
+FROM kubernetes_services svc
+LEFT JOIN kubernetes_service_addresses a ON a.service_id = svc.id
+WHERE svc.source_id = $1 AND svc.active = true;
 
--- name: DeleteStaleKubernetesServices :exec
+-- name: DeleteStaleKubernetesServices :many
 DELETE FROM kubernetes_services
-WHERE source_id = $1 AND active = false AND stale_at <= $2;
+WHERE source_id = $1 AND active = false AND stale_at <= $2
+RETURNING kubernetes_uid, namespace, name;
 
 -- name: RecordKubernetesSourceSuccess :exec
 UPDATE kubernetes_sources
@@ -100,12 +187,7 @@ SET last_attempt_at = $2, last_error = $3, updated_at = now()
 WHERE id = $1;
 
 -- name: ListKubernetesSourceStatuses :many
-SELECT source_key, name, site_id, cluster_domain, namespace_scope,
-       last_attempt_at, last_success_at, last_error,
-       service_count, matched_count, unmatched_count, ambiguous_count,
-       no_usable_ip_count
-FROM kubernetes_sources
-ORDER BY source_key;
+SELECT * FROM kubernetes_sources ORDER BY source_key;
 
 -- name: ListMatchedKubernetesServicesBySubnet :many
 SELECT a.ip_address_id,
@@ -209,3 +291,174 @@ JOIN kubernetes_services svc ON svc.source_id = src.id AND svc.active = true
 JOIN kubernetes_service_hostnames hostname ON hostname.service_id = svc.id
 WHERE subnet.id = $1
 ORDER BY svc.id, hostname.kind, hostname.hostname;
+
+-- name: UpsertKubernetesObject :one
+INSERT INTO kubernetes_objects (
+    source_id, kind, kubernetes_uid, namespace, name, host_network,
+    resource_version, observed_at, active, stale_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true, NULL)
+ON CONFLICT (source_id, kubernetes_uid) DO UPDATE SET
+    kind = EXCLUDED.kind,
+    namespace = EXCLUDED.namespace,
+    name = EXCLUDED.name,
+    host_network = EXCLUDED.host_network,
+    resource_version = EXCLUDED.resource_version,
+    observed_at = EXCLUDED.observed_at,
+    active = true,
+    stale_at = NULL,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteKubernetesObjectAddresses :exec
+DELETE FROM kubernetes_object_addresses WHERE object_id = $1;
+
+-- name: CreateKubernetesObjectAddress :exec
+INSERT INTO kubernetes_object_addresses (
+    object_id, kind, address, node_name, ip_address_id, match_status, match_count
+) VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: DeleteKubernetesObjectHostnames :exec
+DELETE FROM kubernetes_object_hostnames WHERE object_id = $1;
+
+-- name: CreateKubernetesObjectHostname :exec
+INSERT INTO kubernetes_object_hostnames (object_id, kind, hostname)
+VALUES ($1, $2, $3);
+
+-- name: MarkMissingKubernetesObjectsInactive :exec
+UPDATE kubernetes_objects
+SET active = false, stale_at = $3, updated_at = now()
+WHERE source_id = $1
+  AND active = true
+  AND NOT (kubernetes_uid = ANY($2::text[]));
+
+-- name: DeleteStaleKubernetesObjects :exec
+DELETE FROM kubernetes_objects
+WHERE source_id = $1 AND active = false AND stale_at <= $2;
+
+-- name: RecordKubernetesObjectCount :exec
+UPDATE kubernetes_sources
+SET object_count = $2, object_error = '', updated_at = now()
+WHERE id = $1;
+
+-- name: RecordKubernetesObjectFailure :exec
+UPDATE kubernetes_sources
+SET object_error = $2, updated_at = now()
+WHERE source_key = $1;
+
+-- name: ListMatchedKubernetesObjectsBySubnet :many
+SELECT a.ip_address_id,
+       obj.id AS object_id,
+       src.source_key,
+       src.name AS source_name,
+       obj.kind,
+       obj.kubernetes_uid,
+       obj.namespace,
+       obj.name,
+       obj.host_network,
+       obj.observed_at,
+       a.kind AS address_kind,
+       a.node_name
+FROM kubernetes_object_addresses a
+JOIN kubernetes_objects obj ON obj.id = a.object_id
+JOIN kubernetes_sources src ON src.id = obj.source_id
+JOIN ip_addresses ip ON ip.id = a.ip_address_id
+JOIN subnets subnet ON subnet.id = ip.subnet_id
+WHERE ip.subnet_id = $1
+  AND subnet.site_id = src.site_id
+  AND obj.active = true
+  AND a.match_status = 'matched'
+ORDER BY a.ip_address_id, src.source_key, obj.kind, obj.namespace, obj.name, obj.kubernetes_uid, a.kind;
+
+-- name: ListKubernetesObjectAddressesBySubnet :many
+-- Every observed address inside the subnet's CIDR, including the ones no IP
+-- address record matches. load_balancer_services names the Services that
+-- have the address as a load-balancer IP, such as an ingress controller's.
+SELECT obj.id AS object_id,
+       src.source_key,
+       src.name AS source_name,
+       obj.kind,
+       obj.kubernetes_uid,
+       obj.namespace,
+       obj.name,
+       obj.host_network,
+       obj.observed_at,
+       a.kind AS address_kind,
+       a.address,
+       a.node_name,
+       CASE
+           WHEN a.match_status = 'matched' AND matched_subnet.id IS NULL THEN 'unmatched'
+           ELSE a.match_status
+       END::text AS match_status,
+       CASE
+           WHEN a.match_status = 'matched' AND matched_subnet.id IS NULL THEN 0
+           ELSE a.match_count
+       END::integer AS match_count,
+       CASE
+           WHEN a.match_status = 'matched' AND matched_subnet.id IS NOT NULL THEN a.ip_address_id
+           ELSE NULL
+       END::uuid AS ip_address_id,
+       matched_subnet.id AS matched_subnet_id,
+       ARRAY(
+           SELECT DISTINCT svc.namespace || '/' || svc.name
+           FROM kubernetes_service_addresses lb
+           JOIN kubernetes_services svc ON svc.id = lb.service_id
+           WHERE svc.source_id = obj.source_id
+             AND svc.active = true
+             AND lb.kind = 'load_balancer'
+             AND lb.address = a.address
+           ORDER BY 1
+       )::text[] AS load_balancer_services
+FROM subnets subnet
+JOIN kubernetes_sources src ON src.site_id = subnet.site_id
+JOIN kubernetes_objects obj ON obj.source_id = src.id AND obj.active = true
+JOIN kubernetes_object_addresses a ON a.object_id = obj.id AND a.address <<= subnet.cidr
+LEFT JOIN ip_addresses matched_ip ON matched_ip.id = a.ip_address_id
+LEFT JOIN subnets matched_subnet ON matched_subnet.id = matched_ip.subnet_id
+                                AND matched_subnet.site_id = src.site_id
+WHERE subnet.id = $1
+ORDER BY src.source_key, obj.kind, obj.namespace, obj.name, obj.kubernetes_uid, a.kind, a.address;
+
+-- name: ListKubernetesObjectHostnamesBySubnet :many
+SELECT hostname.object_id,
+       hostname.kind,
+       hostname.hostname
+FROM subnets subnet
+JOIN kubernetes_sources src ON src.site_id = subnet.site_id
+JOIN kubernetes_objects obj ON obj.source_id = src.id AND obj.active = true
+JOIN kubernetes_object_hostnames hostname ON hostname.object_id = obj.id
+WHERE subnet.id = $1
+ORDER BY hostname.object_id, hostname.kind, hostname.hostname;
+
+-- name: CreateKubernetesDiscoveryRun :one
+INSERT INTO kubernetes_discovery_runs (
+    source_id, started_at, finished_at, service_count, matched_count,
+    unmatched_count, ambiguous_count, no_usable_ip_count, error
+) VALUES ($1, $2, clock_timestamp(), $3, $4, $5, $6, $7, $8)
+RETURNING id;
+
+-- name: CreateKubernetesDiscoveryEvent :exec
+INSERT INTO kubernetes_discovery_events (
+    run_id, source_id, service_uid, namespace, name, change,
+    previous_addresses, addresses, occurred_at
+) VALUES ($1, $2, $3, $4, $5, $6, sqlc.arg(previous_addresses)::inet[], sqlc.arg(addresses)::inet[], $7);
+
+-- name: DeleteExpiredKubernetesDiscoveryRuns :exec
+DELETE FROM kubernetes_discovery_runs
+WHERE source_id = $1 AND started_at < $2;
+
+-- name: ListKubernetesDiscoveryRuns :many
+SELECT r.*,
+       (SELECT count(*) FROM kubernetes_discovery_events e WHERE e.run_id = r.id)::integer AS change_count
+FROM kubernetes_discovery_runs r
+WHERE r.source_id = $1
+ORDER BY r.started_at DESC, r.id DESC
+LIMIT $2;
+
+-- name: ListKubernetesDiscoveryEvents :many
+-- A null run_id lists the changes of every run.
+SELECT *
+FROM kubernetes_discovery_events
+WHERE source_id = $1
+  AND (sqlc.narg(run_id)::bigint IS NULL OR run_id = sqlc.narg(run_id))
+ORDER BY occurred_at DESC, id DESC
+LIMIT $2;
@@ -0,0 +1,53 @@
+-- name: CreateManagedKubernetesSource :one
+-- A source first seen through configuration is taken over, keeping its
+-- observations; an existing managed source is left alone and no row is
+-- returned.
+INSERT INTO kubernetes_sources (
+    source_key, name, site_id, cluster_domain, namespace_scope, managed,
+    auth_mode, kubeconfig_ciphertext, kubeconfig_context,
+    reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds,
+    object_kinds, pod_selector, auto_register
+) VALUES ($1, $2, $3, $4, $5::text[], true, $6, $7, $8, $9, $10, $11, $12::text[], $13, $14)
+ON CONFLICT (source_key) DO UPDATE SET
+    name = EXCLUDED.name,
+    site_id = EXCLUDED.site_id,
+    cluster_domain = EXCLUDED.cluster_domain,
+    namespace_scope = EXCLUDED.namespace_scope,
+    managed = true,
+    auth_mode = EXCLUDED.auth_mode,
+    kubeconfig_ciphertext = EXCLUDED.kubeconfig_ciphertext,
+    kubeconfig_context = EXCLUDED.kubeconfig_context,
+    reconcile_interval_seconds = EXCLUDED.reconcile_interval_seconds,
+    request_timeout_seconds = EXCLUDED.request_timeout_seconds,
+    stale_retention_seconds = EXCLUDED.stale_retention_seconds,
+    object_kinds = EXCLUDED.object_kinds,
+    pod_selector = EXCLUDED.pod_selector,
+    auto_register = EXCLUDED.auto_register,
+    updated_at = now()
+WHERE NOT kubernetes_sources.managed
+RETURNING *;
+
+-- name: UpdateManagedKubernetesSource :one
+UPDATE kubernetes_sources
+SET name = $2,
+    site_id = $3,
+    cluster_domain = $4,
+    namespace_scope = $5::text[],
+    auth_mode = $6,
+    kubeconfig_ciphertext = $7,
+    kubeconfig_context = $8,
+    reconcile_interval_seconds = $9,
+    request_timeout_seconds = $10,
+    stale_retention_seconds = $11,
+    object_kinds = $12::text[],
+    pod_selector = $13,
+    auto_register = $14,
+    updated_at = now()
+WHERE source_key = $1 AND managed
+RETURNING *;
+
+-- name: DeleteManagedKubernetesSource :execrows
+DELETE FROM kubernetes_sources WHERE source_key = $1 AND managed;
+
+-- name: ListManagedKubernetesSources :many
+SELECT * FROM kubernetes_sources WHERE managed ORDER BY source_key;
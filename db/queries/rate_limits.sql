@@ -0,0 +1,10 @@
+-- name: IncrementRateLimitCounter :one
+INSERT INTO rate_limit_counters (bucket_key, window_start, hits, expires_at)
+VALUES ($1, $2, 1, $3)
+ON CONFLICT (bucket_key, window_start) DO UPDATE
+SET hits = rate_limit_counters.hits + 1
+RETURNING hits;
+
+-- name: DeleteExpiredRateLimitCounters :execrows
+DELETE FROM rate_limit_counters
+WHERE expires_at <= $1;
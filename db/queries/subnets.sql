@@ -1,5 +1,6 @@
 -- name: ListSubnets :many
 SELECT subnets.id, subnets.cidr, subnets.description, subnets.created_at, subnets.updated_at, subnets.site_id,
+       subnets.gateway, subnets.dns_servers, subnets.dhcp_pools,
        (SELECT COUNT(*) FROM ip_addresses WHERE subnet_id = subnets.id) AS used_ips
 FROM subnets
 ORDER BY subnets.id;
@@ -11,6 +12,7 @@ RETURNING id, cidr, description, created_at, updated_at, site_id;
 
 -- name: GetSubnetByID :one
 SELECT subnets.id, subnets.cidr, subnets.description, subnets.created_at, subnets.updated_at, subnets.site_id,
+       subnets.gateway, subnets.dns_servers, subnets.dhcp_pools,
        (SELECT COUNT(*) FROM ip_addresses WHERE subnet_id = subnets.id) AS used_ips
 FROM subnets
 WHERE subnets.id = $1;
@@ -34,3 +36,9 @@ WITH deleted_rows AS (
     RETURNING *
 )
 SELECT count(*) FROM deleted_rows;
+
+-- name: UpdateSubnetDHCP :one
+UPDATE subnets
+SET gateway = $2, dns_servers = $3, dhcp_pools = $4, updated_at = now() AT TIME ZONE 'UTC'
+WHERE id = $1
+RETURNING id;
@@ -0,0 +1,133 @@
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions ORDER BY created_at, id;
+
+-- name: GetWebhookSubscriptionByID :one
+SELECT * FROM webhook_subscriptions WHERE id = $1;
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, description, event_types, secret, active)
+VALUES ($1, $2, $3::text[], $4, $5)
+RETURNING *;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = $2,
+    description = $3,
+    event_types = $4::text[],
+    secret = $5,
+    active = $6,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookSubscriptionByID :execrows
+DELETE FROM webhook_subscriptions WHERE id = $1;
+
+-- name: QueueWebhookDeliveries :execrows
+WITH events AS (
+    SELECT id, event_type, object_type
+    FROM outbox_events
+    WHERE dispatched_at IS NULL
+    ORDER BY id
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+), queued AS (
+    INSERT INTO webhook_deliveries (subscription_id, event_id)
+    SELECT webhook_subscriptions.id, events.id
+    FROM events
+    JOIN webhook_subscriptions ON webhook_subscriptions.active
+        AND (
+            events.event_type = ANY(webhook_subscriptions.event_types)
+            OR events.object_type || '.*' = ANY(webhook_subscriptions.event_types)
+            OR '*' = ANY(webhook_subscriptions.event_types)
+        )
+    ON CONFLICT (subscription_id, event_id) DO NOTHING
+)
+UPDATE outbox_events
+SET dispatched_at = now()
+FROM events
+WHERE outbox_events.id = events.id;
+
+-- name: ClaimDueWebhookDeliveries :many
+WITH due AS (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at, id
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries
+SET attempts = webhook_deliveries.attempts + 1,
+    next_attempt_at = $2::timestamptz
+FROM due, webhook_subscriptions, outbox_events
+WHERE webhook_deliveries.id = due.id
+  AND webhook_subscriptions.id = webhook_deliveries.subscription_id
+  AND outbox_events.id = webhook_deliveries.event_id
+RETURNING webhook_deliveries.id,
+          webhook_deliveries.subscription_id,
+          webhook_deliveries.attempts,
+          webhook_subscriptions.url,
+          webhook_subscriptions.secret,
+          outbox_events.id AS event_id,
+          outbox_events.event_type,
+          outbox_events.object_type,
+          outbox_events.object_id,
+          outbox_events.site_id,
+          outbox_events.payload,
+          outbox_events.created_at AS occurred_at;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    last_attempt_at = now(),
+    last_status_code = $2,
+    last_error = '',
+    delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = CASE WHEN $2::boolean THEN 'dead' ELSE 'pending' END,
+    last_attempt_at = now(),
+    last_status_code = $3,
+    last_error = $4,
+    next_attempt_at = $5
+WHERE id = $1;
+
+-- name: ListDeadWebhookDeliveries :many
+SELECT webhook_deliveries.id,
+       webhook_deliveries.subscription_id,
+       webhook_subscriptions.url,
+       outbox_events.id AS event_id,
+       outbox_events.event_type,
+       outbox_events.object_type,
+       outbox_events.object_id,
+       webhook_deliveries.attempts,
+       webhook_deliveries.last_status_code,
+       webhook_deliveries.last_error,
+       webhook_deliveries.last_attempt_at,
+       webhook_deliveries.created_at
+FROM webhook_deliveries
+JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id
+JOIN outbox_events ON outbox_events.id = webhook_deliveries.event_id
+WHERE webhook_deliveries.status = 'dead'
+ORDER BY webhook_deliveries.last_attempt_at DESC, webhook_deliveries.id DESC
+LIMIT $1;
+
+-- name: RetryDeadWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    last_error = ''
+WHERE id = $1 AND status = 'dead';
+
+-- name: DeleteDispatchedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE dispatched_at < $1
+  AND NOT EXISTS (
+      SELECT 1 FROM webhook_deliveries
+      WHERE webhook_deliveries.event_id = outbox_events.id
+        AND webhook_deliveries.status <> 'delivered'
+  );
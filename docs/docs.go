@@ -24,122 +24,46 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/import/csv": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "import"
-                ],
-                "summary": "Import sites, subnets, and IP metadata",
-                "parameters": [
-                    {
-                        "type": "file",
-                        "description": "CSV file with site,cidr,ip,description columns",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/http.ImportResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/kubernetes/sources": {
+        "/api/v1/dns/zones": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Forward zones attached to sites or subnets, followed by the reverse zones computed from subnet prefixes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "kubernetes"
+                    "dns"
                 ],
-                "summary": "List Kubernetes discovery source status",
+                "summary": "List DNS zones",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/http.KubernetesDiscoveryStatusResponse"
+                                "$ref": "#/definitions/http.DNSZoneResponse"
                             }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/reporting/settings": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "reporting"
-                ],
-                "summary": "Get subnet usage reporting settings",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/http.ReportingSettingsResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Attaches a forward zone to a site or to a subnet; a subnet's own zone takes precedence over its site's.\nHostnames in the zone's subnets must then be valid DNS names relative to the zone.",
                 "consumes": [
                     "application/json"
                 ],
@@ -147,43 +71,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reporting"
+                    "dns"
                 ],
-                "summary": "Update subnet usage reporting settings",
+                "summary": "Create DNS zone",
                 "parameters": [
                     {
-                        "description": "Reporting settings",
-                        "name": "settings",
+                        "description": "DNS zone",
+                        "name": "zone",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.ReportingSettingsRequest"
+                            "$ref": "#/definitions/http.DNSZoneRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/http.ReportingSettingsResponse"
+                            "$ref": "#/definitions/http.DNSZoneResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/sites": {
+        "/api/v1/dns/zones/{zone}": {
             "get": {
                 "security": [
                     {
@@ -194,346 +124,407 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sites"
+                    "dns"
+                ],
+                "summary": "Get DNS zone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Zone name, such as lab.example.com or 0.10.in-addr.arpa",
+                        "name": "zone",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List sites",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/http.SiteResponse"
-                            }
+                            "$ref": "#/definitions/http.DNSZoneResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Only forward zones can be deleted; reverse zones follow the subnets.",
                 "tags": [
-                    "sites"
+                    "dns"
                 ],
-                "summary": "Create site",
+                "summary": "Delete DNS zone",
                 "parameters": [
                     {
-                        "description": "Site payload",
-                        "name": "site",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.SiteRequest"
-                        }
+                        "type": "string",
+                        "description": "Zone name",
+                        "name": "zone",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/http.SiteResponse"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/sites/statistics": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "sites"
-                ],
-                "summary": "Get site statistics",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/http.SiteStatisticsResponse"
-                            }
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/sites/{id}": {
+        "/api/v1/dns/zones/{zone}/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Renders the zone as an RFC 1035 master file with SOA, NS and A/AAAA or PTR records built from IP hostnames.\nThe SOA serial (YYYYMMDDnn) only increases when the zone content changed since the previous export.",
                 "produces": [
-                    "application/json"
+                    "text/dns"
                 ],
                 "tags": [
-                    "sites"
+                    "dns"
                 ],
-                "summary": "Get site by ID",
+                "summary": "Export DNS zone file",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Site ID",
-                        "name": "id",
+                        "description": "Zone name",
+                        "name": "zone",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/http.SiteResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "BIND zone file",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "type": "string"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/events/stream": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Server-sent events for subnet, IP, site, Kubernetes reconcile, reporting snapshot and DNS zone changes.\nEach frame carries the event type in the event field and a ChangeEventResponse as data.\nEvents without a site, such as reporting snapshots, are sent to every site filter.",
+                "produces": [
+                    "text/event-stream"
+                ],
                 "tags": [
-                    "sites"
+                    "events"
                 ],
-                "summary": "Delete site",
+                "summary": "Stream live changes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Site ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Comma-separated object types: subnet, ip, site, kubernetes, reporting, dns_zone",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events for this site",
+                        "name": "site_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ChangeEventResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/api/v1/import/csv": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "sites"
+                    "import"
                 ],
-                "summary": "Update site",
+                "summary": "Import sites, subnets, and IP metadata",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Site ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "CSV file with site,cidr,ip,description columns",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     },
                     {
-                        "description": "Site payload",
-                        "name": "site",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.SiteRequest"
-                        }
+                        "type": "string",
+                        "description": "Replays the stored response for a repeated key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.SiteResponse"
+                            "$ref": "#/definitions/http.ImportResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subnets": {
-            "get": {
+        "/api/v1/import/leases": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Matches every active lease to the most specific subnet containing its address and creates or updates the address with the lease's hostname, MAC address and last-seen time.\nOnly the last entry for an address counts. Existing hostnames are kept. With dry_run=true nothing is written.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "import"
+                ],
+                "summary": "Import addresses from a DHCP lease file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "ISC dhcpd.leases, Kea memfile CSV or dnsmasq lease file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "isc, kea or dnsmasq",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report the result without writing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a repeated key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
                 ],
-                "summary": "List subnets",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/http.SubnetResponse"
-                            }
+                            "$ref": "#/definitions/http.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/import/zone": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Names addresses after the A, AAAA and PTR records of a forward or reverse zone file. Each address is created in the most specific subnet containing it; existing hostnames are kept.\nRecords that match no subnet or cannot be read are reported by line. With dry_run=true nothing is written.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "import"
                 ],
-                "summary": "Create subnet",
+                "summary": "Import hostnames from a BIND zone file",
                 "parameters": [
                     {
-                        "description": "Subnet payload",
-                        "name": "subnet",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.CreateSubnetRequest"
-                        }
+                        "type": "file",
+                        "description": "BIND zone file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Zone name for relative names when the file has no $ORIGIN",
+                        "name": "origin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report the result without writing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a repeated key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.SubnetResponse"
+                            "$ref": "#/definitions/http.ImportResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subnets/{id}": {
+        "/api/v1/kubernetes/sources": {
             "get": {
                 "security": [
                     {
@@ -544,84 +535,124 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "kubernetes"
                 ],
-                "summary": "Get subnet by ID",
+                "summary": "List Kubernetes discovery source status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.KubernetesDiscoveryStatusResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Discovery starts without a restart. Creating a source with the key of one from the deployment's configuration takes it over.\nKubeconfigs must embed their credentials and are encrypted at rest; they are never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kubernetes"
+                ],
+                "summary": "Create Kubernetes discovery source",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Discovery source",
+                        "name": "source",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.KubernetesSourceRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/http.SubnetResponse"
+                            "$ref": "#/definitions/http.KubernetesDiscoveryStatusResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/kubernetes/sources/{key}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Stops discovery and removes the source with its observations. Only sources created through the API can be deleted.",
                 "tags": [
-                    "subnets"
+                    "kubernetes"
                 ],
-                "summary": "Delete subnet",
+                "summary": "Delete Kubernetes discovery source",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet ID of the subnet to delete.",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Source key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "No content"
+                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
@@ -632,6 +663,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "Only sources created through the API can be changed. The running discovery restarts with the new settings.",
                 "consumes": [
                     "application/json"
                 ],
@@ -639,24 +671,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "kubernetes"
                 ],
-                "summary": "Update subnet",
+                "summary": "Update Kubernetes discovery source",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Source key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Subnet payload",
-                        "name": "subnet",
+                        "description": "Fields to change",
+                        "name": "source",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.CreateSubnetRequest"
+                            "$ref": "#/definitions/http.UpdateKubernetesSourceRequest"
                         }
                     }
                 ],
@@ -664,51 +696,73 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.SubnetResponse"
+                            "$ref": "#/definitions/http.KubernetesDiscoveryStatusResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subnets/{id}/ips": {
+        "/api/v1/kubernetes/sources/{key}/events": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Newest first. A Service appears when it becomes active, changes address when its set of addresses differs, goes stale when it is no longer observed and is purged after the stale retention. Addresses are recorded as they were, so the previous addresses of a Service remain after it changes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "kubernetes"
                 ],
-                "summary": "Get ips by subnet ID",
+                "summary": "List Service changes made by Kubernetes discovery",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Source key",
+                        "name": "key",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the changes of this run",
+                        "name": "run_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum changes to return",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -717,187 +771,212 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/http.IPResponse"
+                                "$ref": "#/definitions/http.KubernetesDiscoveryEventResponse"
                             }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/kubernetes/sources/{key}/reconcile": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Runs a discovery cycle without waiting for the source's interval. When this replica has no runner for the source, the request is signalled to the other replicas and answered with the outcome of the cycle one of them runs.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "kubernetes"
                 ],
-                "summary": "Create ip under subnet",
+                "summary": "Reconcile Kubernetes discovery source now",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet id in which the ip is created.",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Source key",
+                        "name": "key",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "IP address to create.",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.CreateIPRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.IPResponse"
+                            "$ref": "#/definitions/http.KubernetesReconcileResultResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subnets/{id}/ips/{uuid}": {
-            "delete": {
+        "/api/v1/kubernetes/sources/{key}/runs": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Newest first. Each complete publication of the source's Services is a run, as is each incremental publication that changed a Service and each failed attempt. Runs are kept for the source's stale retention.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "kubernetes"
                 ],
-                "summary": "Delete ip under subnet",
+                "summary": "List Kubernetes discovery runs of a source",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet id in which the ip is deleted.",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Source key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "UUID of the ip to be deleted.",
-                        "name": "uuid",
-                        "in": "path",
-                        "required": true
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum runs to return",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.KubernetesDiscoveryRunResponse"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/api/v1/reporting/settings": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reporting"
+                ],
+                "summary": "Get subnet usage reporting settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ReportingSettingsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "reporting"
                 ],
-                "summary": "Update ip under subnet",
+                "summary": "Update subnet usage reporting settings",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet id in which the ip is updated.",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "UUID of the ip to be updated.",
-                        "name": "uuid",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "IP address to update",
-                        "name": "payload",
+                        "description": "Reporting settings",
+                        "name": "settings",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.UpdateIPRequest"
+                            "$ref": "#/definitions/http.ReportingSettingsRequest"
                         }
                     }
                 ],
@@ -905,31 +984,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.IPResponse"
+                            "$ref": "#/definitions/http.ReportingSettingsResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subnets/{id}/kubernetes-services": {
+        "/api/v1/sites": {
             "get": {
                 "security": [
                     {
@@ -940,51 +1013,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "kubernetes"
-                ],
-                "summary": "List discovered Kubernetes Services for a subnet site",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Subnet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "sites"
                 ],
+                "summary": "List sites",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/http.KubernetesServiceObservationResponse"
+                                "$ref": "#/definitions/http.SiteResponse"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/subnets/{id}/site": {
-            "patch": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -997,56 +1047,61 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subnets"
+                    "sites"
                 ],
-                "summary": "Assign subnet to site",
+                "summary": "Create site",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Site assignment",
+                        "description": "Site payload",
                         "name": "site",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.AssignSubnetSiteRequest"
+                            "$ref": "#/definitions/http.SiteRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a repeated key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/http.SubnetResponse"
+                            "$ref": "#/definitions/http.SiteResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subnets/{id}/usage-history": {
+        "/api/v1/sites/statistics": {
             "get": {
                 "security": [
                     {
@@ -1057,120 +1112,1549 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reporting"
+                    "sites"
                 ],
-                "summary": "Get periodic subnet usage snapshots",
+                "summary": "Get site statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.SiteStatisticsResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sites/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sites"
+                ],
+                "summary": "Get site by ID",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subnet ID",
+                        "type": "string",
+                        "description": "Site ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "enum": [
-                            "24h",
-                            "7d",
-                            "30d",
-                            "90d",
-                            "180d"
-                        ],
-                        "type": "string",
-                        "default": "7d",
-                        "description": "Bounded history range",
-                        "name": "range",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.SubnetUsageHistoryResponse"
+                            "$ref": "#/definitions/http.SiteResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/healthz": {
-            "get": {
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "tags": [
-                    "health"
+                    "sites"
                 ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "ok",
-                        "schema": {
-                            "type": "string"
-                        }
+                "summary": "Delete site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                }
-            }
-        },
-        "/readyz": {
-            "get": {
-                "tags": [
-                    "health"
                 ],
-                "summary": "Readiness check",
                 "responses": {
-                    "200": {
-                        "description": "ready",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "503": {
-                        "description": "db unavailable",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "http.AssignSubnetSiteRequest": {
-            "type": "object",
-            "properties": {
-                "site_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "http.CreateIPRequest": {
-            "type": "object",
-            "properties": {
-                "hostname": {
-                    "type": "string",
-                    "example": "printer-1"
-                },
-                "ip": {
-                    "type": "string",
-                    "example": "10.0.0.1"
-                }
-            }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sites"
+                ],
+                "summary": "Update site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Site payload",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SiteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SiteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sites/{id}/dhcp-config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renders every subnet of the site, ordered by subnet ID.",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dhcp"
+                ],
+                "summary": "Export site DHCP configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "kea (default) or dnsmasq",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kea JSON or dnsmasq configuration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "List subnets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.SubnetResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Create subnet",
+                "parameters": [
+                    {
+                        "description": "Subnet payload",
+                        "name": "subnet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateSubnetRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a repeated key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Get subnet by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Delete subnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID of the subnet to delete.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Update subnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subnet payload",
+                        "name": "subnet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateSubnetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/dhcp": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the gateway, DNS servers and address pools rendered into DHCP configuration.\nThe gateway and pools must lie inside the subnet, and pools may not overlap.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dhcp"
+                ],
+                "summary": "Update subnet DHCP settings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "DHCP settings",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetDHCPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/dhcp-config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renders the subnet, its pools, gateway and DNS options and a host reservation for every address with a MAC address.\nThe output is deterministic, so it can be committed and diffed.",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "dhcp"
+                ],
+                "summary": "Export subnet DHCP configuration",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "kea (default) or dnsmasq",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kea JSON or dnsmasq configuration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/ips": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Get ips by subnet ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.IPResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Create ip under subnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet id in which the ip is created.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IP address to create.",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateIPRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response for a repeated key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.IPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/ips/{uuid}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Delete ip under subnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet id in which the ip is deleted.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of the ip to be deleted.",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Update ip under subnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet id in which the ip is updated.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of the ip to be updated.",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IP address to update",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateIPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.IPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/kubernetes-objects": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Includes addresses that match no IP in IPAM, such as hostNetwork Pods.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kubernetes"
+                ],
+                "summary": "List discovered Kubernetes objects with addresses in a subnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.KubernetesObjectObservationResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/kubernetes-services": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kubernetes"
+                ],
+                "summary": "List discovered Kubernetes Services for a subnet site",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.KubernetesServiceObservationResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/site": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subnets"
+                ],
+                "summary": "Assign subnet to site",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Site assignment",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AssignSubnetSiteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subnets/{id}/usage-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reporting"
+                ],
+                "summary": "Get periodic subnet usage snapshots",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subnet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24h",
+                            "7d",
+                            "30d",
+                            "90d",
+                            "180d"
+                        ],
+                        "type": "string",
+                        "default": "7d",
+                        "description": "Bounded history range",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubnetUsageHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.WebhookSubscriptionResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Event types are concrete types such as ip.created, an object wildcard such as subnet.*, or *.\nThe signing secret is generated when omitted and is only returned by this endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Webhook subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.CreatedWebhookSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deliveries that exhausted their retries, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List dead-lettered webhook deliveries",
+                "parameters": [
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of deliveries",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.WebhookDeadLetterResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/deliveries/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Resets the attempt counter and queues the delivery for immediate redelivery.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retry a dead-lettered webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.WebhookSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateWebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.WebhookSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "ready",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "http.AssignSubnetSiteRequest": {
+            "type": "object",
+            "properties": {
+                "site_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.ChangeEventResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 340
+                },
+                "object_id": {
+                    "type": "string"
+                },
+                "object_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "subnet_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ip.created"
+                }
+            }
+        },
+        "http.CreateIPRequest": {
+            "type": "object",
+            "properties": {
+                "hostname": {
+                    "type": "string",
+                    "example": "printer-1"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
+                "mac_address": {
+                    "type": "string",
+                    "example": "52:54:00:12:34:56"
+                }
+            }
         },
         "http.CreateSubnetRequest": {
             "type": "object",
@@ -1192,12 +2676,119 @@ const docTemplate = `{
                 }
             }
         },
-        "http.ErrorResponse": {
+        "http.CreatedWebhookSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CMDB sync"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subnet.*",
+                        "ip.created"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cmdb.example.com/hooks/ipam"
+                }
+            }
+        },
+        "http.DHCPPool": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string",
+                    "example": "10.0.0.199"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "10.0.0.100"
+                }
+            }
+        },
+        "http.DNSZoneRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "lab.example.com"
+                },
+                "site_id": {
+                    "type": "string",
+                    "example": "50e8400-e29b-41d4-a716-446655440000"
+                },
+                "subnet_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "ttl": {
+                    "type": "integer",
+                    "example": 3600
+                }
+            }
+        },
+        "http.DNSZoneResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
                     "type": "string",
-                    "example": "subnet not found"
+                    "enum": [
+                        "forward",
+                        "reverse"
+                    ],
+                    "example": "forward"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "lab.example.com"
+                },
+                "network": {
+                    "type": "string",
+                    "example": "10.0.0.0/24"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "subnet_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "ttl": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
@@ -1220,12 +2811,42 @@ const docTemplate = `{
                     "type": "string",
                     "example": "10.0.0.1"
                 },
+                "kubernetes_allocation": {
+                    "description": "KubernetesAllocation names the LoadBalancer Service the allocator gave\nthe address to.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.KubernetesAllocationResponse"
+                        }
+                    ]
+                },
+                "kubernetes_objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.KubernetesObjectResponse"
+                    }
+                },
+                "kubernetes_registration": {
+                    "description": "KubernetesRegistration names the Service discovery created the record\nfor. The record is deleted once the Service is gone.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.KubernetesRegistrationResponse"
+                        }
+                    ]
+                },
                 "kubernetes_services": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/http.KubernetesServiceResponse"
                     }
                 },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "2026-10-14T10:00:00Z"
+                },
+                "mac_address": {
+                    "type": "string",
+                    "example": "52:54:00:12:34:56"
+                },
                 "subnet_id": {
                     "type": "integer",
                     "example": 4
@@ -1259,24 +2880,314 @@ const docTemplate = `{
                 }
             }
         },
-        "http.KubernetesAddressObservationResponse": {
+        "http.KubernetesAddressObservationResponse": {
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string",
+                    "example": "10.96.12.4"
+                },
+                "ip_mode": {
+                    "type": "string",
+                    "example": "VIP"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "cluster_ip"
+                },
+                "match_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "match_status": {
+                    "type": "string",
+                    "enum": [
+                        "matched",
+                        "unmatched",
+                        "ambiguous"
+                    ],
+                    "example": "matched"
+                },
+                "matched_ip_address_id": {
+                    "type": "string",
+                    "example": "50e8400-e29b-41d4-a716-446655440000"
+                },
+                "matched_subnet_id": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "http.KubernetesAllocationResponse": {
+            "type": "object",
+            "properties": {
+                "allocated_at": {
+                    "type": "string",
+                    "example": "2026-10-18T10:00:00Z"
+                },
+                "controller": {
+                    "type": "string",
+                    "example": "prod-cluster"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ingress-nginx-controller"
+                },
+                "namespace": {
+                    "type": "string",
+                    "example": "ingress-nginx"
+                },
+                "service_uid": {
+                    "type": "string",
+                    "example": "6f4d9f2a-5c1e-4d7b-9a43-1c0f3e2b8a10"
+                }
+            }
+        },
+        "http.KubernetesDiscoveryEventResponse": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.96.12.9"
+                    ]
+                },
+                "change": {
+                    "type": "string",
+                    "enum": [
+                        "appeared",
+                        "address_changed",
+                        "stale",
+                        "purged"
+                    ],
+                    "example": "address_changed"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 5120
+                },
+                "name": {
+                    "type": "string",
+                    "example": "orders"
+                },
+                "namespace": {
+                    "type": "string",
+                    "example": "commerce"
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:02Z"
+                },
+                "previous_addresses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.96.12.4"
+                    ]
+                },
+                "run_id": {
+                    "type": "integer",
+                    "example": 812
+                },
+                "service_uid": {
+                    "type": "string",
+                    "example": "1f6b2c58-5c2d-4b8f-9e79-0d8f7b6c1a10"
+                }
+            }
+        },
+        "http.KubernetesDiscoveryRunResponse": {
+            "type": "object",
+            "properties": {
+                "ambiguous": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "changes": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:02Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 812
+                },
+                "matched": {
+                    "type": "integer",
+                    "example": 38
+                },
+                "no_usable_ip": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "services": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:00Z"
+                },
+                "unmatched": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "http.KubernetesDiscoveryStatusResponse": {
+            "type": "object",
+            "properties": {
+                "ambiguous": {
+                    "type": "integer"
+                },
+                "auto_register": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "register",
+                        "register_lb_only"
+                    ],
+                    "example": "register_lb_only"
+                },
+                "cluster_domain": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_success_at": {
+                    "type": "string"
+                },
+                "managed": {
+                    "description": "Managed sources were created through the API and can be changed or\ndeleted there; the others come from the deployment's configuration.",
+                    "type": "boolean"
+                },
+                "matched": {
+                    "type": "integer"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "no_usable_ip": {
+                    "type": "integer"
+                },
+                "object_error": {
+                    "description": "ObjectError is why the latest listing of the source's object kinds\nfailed; its Services are still discovered.",
+                    "type": "string",
+                    "example": "list pods in namespace \"apps\": pods is forbidden"
+                },
+                "object_kinds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "node",
+                        "pod"
+                    ]
+                },
+                "objects": {
+                    "type": "integer"
+                },
+                "pod_selector": {
+                    "type": "string",
+                    "example": "app.kubernetes.io/part-of=edge"
+                },
+                "services": {
+                    "type": "integer"
+                },
+                "settings": {
+                    "$ref": "#/definitions/http.KubernetesSourceSettingsResponse"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/http.KubernetesSourceResponse"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "healthy"
+                },
+                "unmatched": {
+                    "type": "integer"
+                }
+            }
+        },
+        "http.KubernetesHostnameObservationResponse": {
+            "type": "object",
+            "properties": {
+                "hostname": {
+                    "type": "string",
+                    "example": "orders.example.com"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "load_balancer"
+                }
+            }
+        },
+        "http.KubernetesMatchedAddressResponse": {
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string",
+                    "example": "10.96.12.4"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "cluster_ip"
+                }
+            }
+        },
+        "http.KubernetesObjectAddressObservationResponse": {
             "type": "object",
             "properties": {
                 "ip": {
                     "type": "string",
-                    "example": "10.96.12.4"
-                },
-                "ip_mode": {
-                    "type": "string",
-                    "example": "VIP"
+                    "example": "10.0.0.23"
                 },
                 "kind": {
                     "type": "string",
-                    "example": "cluster_ip"
+                    "enum": [
+                        "internal_ip",
+                        "external_ip",
+                        "pod_ip",
+                        "endpoint",
+                        "load_balancer",
+                        "gateway"
+                    ],
+                    "example": "pod_ip"
+                },
+                "load_balancer_services": {
+                    "description": "LoadBalancerServices are the Services, as namespace/name, that have\nthis address as a load-balancer IP.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ingress-nginx/ingress-nginx-controller"
+                    ]
                 },
                 "match_count": {
                     "type": "integer",
-                    "example": 1
+                    "example": 0
                 },
                 "match_status": {
                     "type": "string",
@@ -1285,7 +3196,7 @@ const docTemplate = `{
                         "unmatched",
                         "ambiguous"
                     ],
-                    "example": "matched"
+                    "example": "unmatched"
                 },
                 "matched_ip_address_id": {
                     "type": "string",
@@ -1294,80 +3205,177 @@ const docTemplate = `{
                 "matched_subnet_id": {
                     "type": "integer",
                     "example": 4
+                },
+                "node_name": {
+                    "type": "string",
+                    "example": "worker-3"
                 }
             }
         },
-        "http.KubernetesDiscoveryStatusResponse": {
+        "http.KubernetesObjectObservationResponse": {
             "type": "object",
             "properties": {
-                "ambiguous": {
-                    "type": "integer"
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.KubernetesObjectAddressObservationResponse"
+                    }
                 },
-                "cluster_domain": {
-                    "type": "string"
+                "host_network": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "last_attempt_at": {
-                    "type": "string"
+                "hostnames": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.KubernetesHostnameObservationResponse"
+                    }
                 },
-                "last_error": {
-                    "type": "string"
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "node",
+                        "pod",
+                        "endpoint_slice",
+                        "ingress",
+                        "gateway",
+                        "http_route"
+                    ],
+                    "example": "ingress"
                 },
-                "last_success_at": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "example": "ipam"
                 },
-                "matched": {
-                    "type": "integer"
+                "namespace": {
+                    "type": "string",
+                    "example": "ipam"
                 },
-                "namespaces": {
+                "observed_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:00Z"
+                },
+                "source": {
+                    "$ref": "#/definitions/http.KubernetesSourceResponse"
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "9d4c1a2b-1234-5678-90ab-abcdefabcdef"
+                }
+            }
+        },
+        "http.KubernetesObjectResponse": {
+            "type": "object",
+            "properties": {
+                "address_kind": {
+                    "type": "string",
+                    "enum": [
+                        "internal_ip",
+                        "external_ip",
+                        "pod_ip",
+                        "endpoint",
+                        "load_balancer",
+                        "gateway"
+                    ],
+                    "example": "pod_ip"
+                },
+                "host_network": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "hostnames": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/http.KubernetesHostnameObservationResponse"
                     }
                 },
-                "no_usable_ip": {
-                    "type": "integer"
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "node",
+                        "pod",
+                        "endpoint_slice",
+                        "ingress",
+                        "gateway",
+                        "http_route"
+                    ],
+                    "example": "pod"
                 },
-                "services": {
-                    "type": "integer"
+                "name": {
+                    "type": "string",
+                    "example": "node-exporter-x7k2p"
                 },
-                "site_id": {
-                    "type": "string"
+                "namespace": {
+                    "type": "string",
+                    "example": "kube-system"
+                },
+                "node_name": {
+                    "type": "string",
+                    "example": "worker-3"
+                },
+                "observed_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:00Z"
                 },
                 "source": {
                     "$ref": "#/definitions/http.KubernetesSourceResponse"
                 },
-                "state": {
+                "uid": {
                     "type": "string",
-                    "example": "healthy"
-                },
-                "unmatched": {
-                    "type": "integer"
+                    "example": "9d4c1a2b-1234-5678-90ab-abcdefabcdef"
                 }
             }
         },
-        "http.KubernetesHostnameObservationResponse": {
+        "http.KubernetesReconcileResultResponse": {
             "type": "object",
             "properties": {
-                "hostname": {
-                    "type": "string",
-                    "example": "orders.example.com"
+                "ambiguous": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "kind": {
+                "matched": {
+                    "type": "integer",
+                    "example": 38
+                },
+                "no_usable_ip": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "services": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "source": {
                     "type": "string",
-                    "example": "load_balancer"
+                    "example": "prod-a"
+                },
+                "unmatched": {
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "http.KubernetesMatchedAddressResponse": {
+        "http.KubernetesRegistrationResponse": {
             "type": "object",
             "properties": {
-                "ip": {
+                "name": {
                     "type": "string",
-                    "example": "10.96.12.4"
+                    "example": "edge-gateway"
                 },
-                "kind": {
+                "namespace": {
                     "type": "string",
-                    "example": "cluster_ip"
+                    "example": "metallb-system"
+                },
+                "registered_at": {
+                    "type": "string",
+                    "example": "2026-10-18T10:00:00Z"
+                },
+                "service_uid": {
+                    "type": "string",
+                    "example": "6f4d9f2a-5c1e-4d7b-9a43-1c0f3e2b8a10"
+                },
+                "source": {
+                    "$ref": "#/definitions/http.KubernetesSourceResponse"
                 }
             }
         },
@@ -1410,114 +3418,271 @@ const docTemplate = `{
                 },
                 "namespace": {
                     "type": "string",
-                    "example": "commerce"
+                    "example": "commerce"
+                },
+                "observed_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:00Z"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.KubernetesServicePortResponse"
+                    }
+                },
+                "source": {
+                    "$ref": "#/definitions/http.KubernetesSourceResponse"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "LoadBalancer"
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "02e12c93-1234-5678-90ab-abcdefabcdef"
+                }
+            }
+        },
+        "http.KubernetesServicePortResponse": {
+            "type": "object",
+            "properties": {
+                "app_protocol": {
+                    "type": "string",
+                    "example": "https"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "https"
+                },
+                "node_port": {
+                    "type": "integer",
+                    "example": 30443
+                },
+                "port": {
+                    "type": "integer",
+                    "example": 443
+                },
+                "protocol": {
+                    "type": "string",
+                    "example": "TCP"
+                },
+                "target_port": {
+                    "type": "string",
+                    "example": "8443"
+                }
+            }
+        },
+        "http.KubernetesServiceResponse": {
+            "type": "object",
+            "properties": {
+                "dns_name": {
+                    "type": "string",
+                    "example": "orders.commerce.svc.cluster.local"
+                },
+                "matched_addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.KubernetesMatchedAddressResponse"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "orders"
+                },
+                "namespace": {
+                    "type": "string",
+                    "example": "commerce"
+                },
+                "observed_at": {
+                    "type": "string",
+                    "example": "2026-08-01T10:00:00Z"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.KubernetesServicePortResponse"
+                    }
+                },
+                "source": {
+                    "$ref": "#/definitions/http.KubernetesSourceResponse"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "LoadBalancer"
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "02e12c93-1234-5678-90ab-abcdefabcdef"
+                }
+            }
+        },
+        "http.KubernetesSourceRequest": {
+            "type": "object",
+            "properties": {
+                "auth_mode": {
+                    "type": "string",
+                    "enum": [
+                        "in_cluster",
+                        "kubeconfig"
+                    ],
+                    "example": "kubeconfig"
+                },
+                "auto_register": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "register",
+                        "register_lb_only"
+                    ],
+                    "example": "register_lb_only"
+                },
+                "cluster_domain": {
+                    "type": "string",
+                    "example": "cluster.local"
+                },
+                "interval_seconds": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "key": {
+                    "type": "string",
+                    "example": "prod-a"
+                },
+                "kubeconfig": {
+                    "type": "string"
+                },
+                "kubeconfig_context": {
+                    "type": "string",
+                    "example": "prod-a"
                 },
-                "observed_at": {
+                "name": {
                     "type": "string",
-                    "example": "2026-08-01T10:00:00Z"
+                    "example": "Production A"
                 },
-                "ports": {
+                "namespaces": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/http.KubernetesServicePortResponse"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "default",
+                        "apps"
+                    ]
                 },
-                "source": {
-                    "$ref": "#/definitions/http.KubernetesSourceResponse"
+                "object_kinds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "node",
+                        "pod"
+                    ]
                 },
-                "type": {
+                "pod_selector": {
                     "type": "string",
-                    "example": "LoadBalancer"
+                    "example": "app.kubernetes.io/part-of=edge"
                 },
-                "uid": {
-                    "type": "string",
-                    "example": "02e12c93-1234-5678-90ab-abcdefabcdef"
+                "request_timeout_seconds": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "stale_retention_seconds": {
+                    "type": "integer",
+                    "example": 604800
                 }
             }
         },
-        "http.KubernetesServicePortResponse": {
+        "http.KubernetesSourceResponse": {
             "type": "object",
             "properties": {
-                "app_protocol": {
+                "key": {
                     "type": "string",
-                    "example": "https"
+                    "example": "prod-cluster"
                 },
                 "name": {
                     "type": "string",
-                    "example": "https"
-                },
-                "node_port": {
-                    "type": "integer",
-                    "example": 30443
+                    "example": "Production"
+                }
+            }
+        },
+        "http.KubernetesSourceSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "auth_mode": {
+                    "type": "string",
+                    "enum": [
+                        "in_cluster",
+                        "kubeconfig"
+                    ],
+                    "example": "kubeconfig"
                 },
-                "port": {
+                "interval_seconds": {
                     "type": "integer",
-                    "example": 443
+                    "example": 300
                 },
-                "protocol": {
+                "kubeconfig_context": {
                     "type": "string",
-                    "example": "TCP"
+                    "example": "prod-a"
                 },
-                "target_port": {
-                    "type": "string",
-                    "example": "8443"
+                "request_timeout_seconds": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "stale_retention_seconds": {
+                    "type": "integer",
+                    "example": 604800
                 }
             }
         },
-        "http.KubernetesServiceResponse": {
+        "http.Problem": {
             "type": "object",
             "properties": {
-                "dns_name": {
+                "detail": {
                     "type": "string",
-                    "example": "orders.commerce.svc.cluster.local"
+                    "example": "subnet not found"
                 },
-                "matched_addresses": {
+                "errors": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/http.KubernetesMatchedAddressResponse"
+                        "$ref": "#/definitions/http.ProblemFieldError"
                     }
                 },
-                "name": {
-                    "type": "string",
-                    "example": "orders"
-                },
-                "namespace": {
+                "instance": {
                     "type": "string",
-                    "example": "commerce"
+                    "example": "/api/v1/subnets/42"
                 },
-                "observed_at": {
+                "request_id": {
                     "type": "string",
-                    "example": "2026-08-01T10:00:00Z"
+                    "example": "5f0c6c1e-4c1d-4df3-9d59-2a4a8f0f3b8e"
                 },
-                "ports": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/http.KubernetesServicePortResponse"
-                    }
-                },
-                "source": {
-                    "$ref": "#/definitions/http.KubernetesSourceResponse"
+                "status": {
+                    "type": "integer",
+                    "example": 404
                 },
-                "type": {
+                "title": {
                     "type": "string",
-                    "example": "LoadBalancer"
+                    "example": "Resource not found"
                 },
-                "uid": {
+                "type": {
                     "type": "string",
-                    "example": "02e12c93-1234-5678-90ab-abcdefabcdef"
+                    "example": "https://github.com/Flarenzy/simple-k8s-app/blob/main/docs/problems.md#not-found"
                 }
             }
         },
-        "http.KubernetesSourceResponse": {
+        "http.ProblemFieldError": {
             "type": "object",
             "properties": {
-                "key": {
+                "field": {
                     "type": "string",
-                    "example": "prod-cluster"
+                    "example": "cidr"
                 },
-                "name": {
+                "message": {
                     "type": "string",
-                    "example": "Production"
+                    "example": "invalid cidr"
                 }
             }
         },
@@ -1663,6 +3828,30 @@ const docTemplate = `{
                 }
             }
         },
+        "http.SubnetDHCPRequest": {
+            "type": "object",
+            "properties": {
+                "dns_servers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.53"
+                    ]
+                },
+                "gateway": {
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
+                "pools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.DHCPPool"
+                    }
+                }
+            }
+        },
         "http.SubnetResponse": {
             "type": "object",
             "properties": {
@@ -1678,6 +3867,25 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Office network"
                 },
+                "dhcp_pools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.DHCPPool"
+                    }
+                },
+                "dns_servers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.53"
+                    ]
+                },
+                "gateway": {
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -1752,6 +3960,223 @@ const docTemplate = `{
                 "hostname": {
                     "type": "string",
                     "example": "pc-1"
+                },
+                "mac_address": {
+                    "type": "string",
+                    "example": "52:54:00:12:34:56"
+                }
+            }
+        },
+        "http.UpdateKubernetesSourceRequest": {
+            "type": "object",
+            "properties": {
+                "auth_mode": {
+                    "type": "string",
+                    "enum": [
+                        "in_cluster",
+                        "kubeconfig"
+                    ],
+                    "example": "kubeconfig"
+                },
+                "auto_register": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "register",
+                        "register_lb_only"
+                    ],
+                    "example": "register"
+                },
+                "cluster_domain": {
+                    "type": "string",
+                    "example": "cluster.local"
+                },
+                "interval_seconds": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "kubeconfig": {
+                    "type": "string"
+                },
+                "kubeconfig_context": {
+                    "type": "string",
+                    "example": "prod-a"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Production A"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "*"
+                    ]
+                },
+                "object_kinds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "node"
+                    ]
+                },
+                "pod_selector": {
+                    "type": "string",
+                    "example": "app.kubernetes.io/part-of=edge"
+                },
+                "request_timeout_seconds": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "stale_retention_seconds": {
+                    "type": "integer",
+                    "example": 604800
+                }
+            }
+        },
+        "http.UpdateWebhookSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "*"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cmdb.example.com/hooks/ipam"
+                }
+            }
+        },
+        "http.WebhookDeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 340
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "ip.created"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "unexpected response status 503"
+                },
+                "last_status_code": {
+                    "type": "integer",
+                    "example": 503
+                },
+                "object_id": {
+                    "type": "string"
+                },
+                "object_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.WebhookSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CMDB sync"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subnet.*",
+                        "ip.created"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cmdb.example.com/hooks/ipam"
+                }
+            }
+        },
+        "http.WebhookSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CMDB sync"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subnet.*",
+                        "ip.created"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cmdb.example.com/hooks/ipam"
                 }
             }
         }
@@ -1,5 +1,6 @@
 import { useCallback, useEffect, useState } from "react";
 import { api, requestError, type Requester } from "./api";
+import type { ChangeEvent } from "./events.js";
 import { capabilitiesForRoles, localAdminRoles, roleLabel, rolesFromToken } from "./authz";
 import AppHeader from "./components/AppHeader";
 import keycloak, { initKeycloak, keycloakEnabled, roleClientId } from "./keycloak";
@@ -39,8 +40,9 @@ export default function App() {
 	const [error, setError] = useState<string | null>(null);
 	const [authReady, setAuthReady] = useState(!keycloakEnabled);
 	const [authError, setAuthError] = useState<string | null>(null);
+	const [liveEvent, setLiveEvent] = useState<ChangeEvent | null>(null);
 	const requester = useCallback<Requester>(async (input, init = {}) => { const client = keycloak; const token = client?.token; if (client && client.isTokenExpired(30)) { try { await client.updateToken(30); } catch { setAuthError("Your session has expired."); } } const headers = new Headers(init.headers); if (client?.token || token) headers.set("Authorization", `Bearer ${client?.token || token}`); return fetch(input, { ...init, headers }); }, []);
-	const refresh = useCallback(async () => { setLoading(true); setError(null); try { const [nextSubnets, nextSites] = await Promise.all([api.subnets(requester), api.siteStatistics(requester)]); setSubnets(nextSubnets); setSites(nextSites); setUsage(Object.fromEntries(nextSubnets.map((subnet) => [subnet.id, { used: subnet.used_ips, total: subnet.total_ips }]))); setServiceSummaries({}); } catch (err) { setError(err instanceof Error ? err.message : "Unable to load inventory"); } finally { setLoading(false); } }, [requester]);
+	const refresh = useCallback(async (quiet = false) => { if (!quiet) setLoading(true); setError(null); try { const [nextSubnets, nextSites] = await Promise.all([api.subnets(requester), api.siteStatistics(requester)]); setSubnets(nextSubnets); setSites(nextSites); setUsage(Object.fromEntries(nextSubnets.map((subnet) => [subnet.id, { used: subnet.used_ips, total: subnet.total_ips }]))); setServiceSummaries({}); } catch (err) { setError(err instanceof Error ? err.message : "Unable to load inventory"); } finally { if (!quiet) setLoading(false); } }, [requester]);
 	const loadServiceSummary = useCallback(async (subnetId: number) => {
 		setServiceSummaries((current) => ({ ...current, [subnetId]: { count: 0, statuses: {}, state: "loading" } }));
 		try {
@@ -51,6 +53,7 @@ export default function App() {
 		}
 	}, [requester]);
 	useEffect(() => { let cancelled = false; if (!keycloakEnabled) { void refresh(); return; } initKeycloak().then((authenticated) => { if (cancelled) return; if (!authenticated) { setAuthError("Not authenticated"); return; } setAuthReady(true); void refresh(); }).catch((err) => { if (!cancelled) setAuthError(err instanceof Error ? err.message : "Unable to sign in"); }); return () => { cancelled = true; }; }, [refresh]);
+	useEffect(() => { if (!authReady) return; const controller = new AbortController(); let timer: ReturnType<typeof setTimeout> | undefined; void api.eventStream(requester, (event) => { setLiveEvent(event); clearTimeout(timer); timer = setTimeout(() => void refresh(true), 500); }, controller.signal); return () => { controller.abort(); clearTimeout(timer); }; }, [authReady, refresh, requester]);
 	const username = keycloak?.tokenParsed?.preferred_username || keycloak?.tokenParsed?.name || "Account";
 	const roles = keycloakEnabled ? rolesFromToken(keycloak?.tokenParsed, roleClientId) : localAdminRoles;
 	const capabilities = capabilitiesForRoles(roles);
@@ -65,5 +68,5 @@ export default function App() {
 	const changePassword = () => { if (!keycloak) return; try { window.location.assign(keycloak.createAccountUrl({ redirectUri: window.location.href })); } catch { setAuthError("Keycloak account management is unavailable."); } };
 	if (!authReady) return <div className="page page--center"><div className="card"><h1 className="title">Signing you in…</h1><p className="muted">{authError || "Redirecting to Keycloak."}</p></div></div>;
 	const selectedSubnet = subnets.find((subnet) => subnet.id === selectedSubnetId);
-		return <div className="app"><AppHeader view={view} username={username} role={roleLabel(roles)} authenticated={keycloakEnabled && Boolean(keycloak)} canImport={capabilities.canCreate} onNavigate={navigate} onChangePassword={changePassword} onLogout={() => { if (keycloak) void keycloak.logout(); }} />{authError ? <div className="content"><div className="error" role="alert">{authError}</div></div> : null}{view === "dashboard" ? <DashboardView subnets={subnets} sites={sites} usage={usage} summaries={serviceSummaries} loading={loading} error={error} canCreate={capabilities.canCreate} onSelectSubnet={openSubnet} onAddSubnet={() => navigate("subnets")} /> : view === "subnets" ? <SubnetsView subnets={subnets} sites={sites} summaries={serviceSummaries} loading={loading} error={error} canCreate={capabilities.canCreate} canEdit={capabilities.canEdit} canDelete={capabilities.canDelete} onSelect={openSubnet} onSave={saveSubnet} onDelete={deleteSubnet} onLoadSummary={loadServiceSummary} /> : view === "sites" ? <SitesView sites={sites} loading={loading} error={error} canCreate={capabilities.canCreate} canEdit={capabilities.canEdit} canDelete={capabilities.canDelete} onSave={saveSite} onDelete={deleteSite} onImport={() => navigate("import")} /> : view === "import" && capabilities.canCreate ? <ImportView onImport={importCSV} /> : selectedSubnet ? <SubnetDetailView subnet={selectedSubnet} site={sites.find((site) => site.id === selectedSubnet.site_id)} requester={requester} canEdit={capabilities.canEdit} canDelete={capabilities.canDelete} onBack={() => navigate("subnets")} onRefreshUsage={refresh} /> : <main className="content"><section className="card empty-state"><h1 className="title">Subnet not found</h1><p className="muted">This subnet may have been deleted or is still loading.</p><button className="secondary" onClick={() => navigate("subnets")}>Back to subnets</button></section></main>}</div>;
+		return <div className="app"><AppHeader view={view} username={username} role={roleLabel(roles)} authenticated={keycloakEnabled && Boolean(keycloak)} canImport={capabilities.canCreate} onNavigate={navigate} onChangePassword={changePassword} onLogout={() => { if (keycloak) void keycloak.logout(); }} />{authError ? <div className="content"><div className="error" role="alert">{authError}</div></div> : null}{view === "dashboard" ? <DashboardView subnets={subnets} sites={sites} usage={usage} summaries={serviceSummaries} loading={loading} error={error} canCreate={capabilities.canCreate} onSelectSubnet={openSubnet} onAddSubnet={() => navigate("subnets")} /> : view === "subnets" ? <SubnetsView subnets={subnets} sites={sites} summaries={serviceSummaries} loading={loading} error={error} canCreate={capabilities.canCreate} canEdit={capabilities.canEdit} canDelete={capabilities.canDelete} onSelect={openSubnet} onSave={saveSubnet} onDelete={deleteSubnet} onLoadSummary={loadServiceSummary} /> : view === "sites" ? <SitesView sites={sites} loading={loading} error={error} canCreate={capabilities.canCreate} canEdit={capabilities.canEdit} canDelete={capabilities.canDelete} onSave={saveSite} onDelete={deleteSite} onImport={() => navigate("import")} /> : view === "import" && capabilities.canCreate ? <ImportView onImport={importCSV} /> : selectedSubnet ? <SubnetDetailView subnet={selectedSubnet} site={sites.find((site) => site.id === selectedSubnet.site_id)} requester={requester} canEdit={capabilities.canEdit} canDelete={capabilities.canDelete} onBack={() => navigate("subnets")} liveEvent={liveEvent} onRefreshUsage={() => void refresh()} /> : <main className="content"><section className="card empty-state"><h1 className="title">Subnet not found</h1><p className="muted">This subnet may have been deleted or is still loading.</p><button className="secondary" onClick={() => navigate("subnets")}>Back to subnets</button></section></main>}</div>;
 }
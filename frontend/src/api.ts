@@ -1,5 +1,6 @@
 import { getEnv } from "./env";
-import type { IPAddress, ImportResult, KubernetesServiceObservation, ReportingSettings, Site, SiteStatistics, Subnet, SubnetUsageHistory, UsageRange } from "./types";
+import { subscribeToEvents, type ChangeEvent } from "./events.js";
+import type { IPAddress, ImportResult, KubernetesObjectObservation, KubernetesServiceObservation, ReportingSettings, Site, SiteStatistics, Subnet, SubnetUsageHistory, UsageRange } from "./types";
 
 const API_BASE = getEnv("VITE_API_BASE", "/api/v1");
 
@@ -9,8 +10,9 @@ export async function requestError(response: Response) {
 	const text = await response.text();
 	if (!text) return `request failed: ${response.status}`;
 	try {
-		const parsed = JSON.parse(text) as { error?: string };
-		return parsed.error || text;
+		const parsed = JSON.parse(text) as { detail?: string; title?: string; errors?: { field: string; message: string }[] };
+		if (parsed.errors?.length) return parsed.errors.map((field) => `${field.field}: ${field.message}`).join("; ");
+		return parsed.detail || parsed.title || text;
 	} catch {
 		return text;
 	}
@@ -34,6 +36,7 @@ export const api = {
 	siteStatistics: (requester: Requester) => json<SiteStatistics[]>(requester, "/sites/statistics"),
 	ips: async (requester: Requester, subnetId: number) => (await json<Array<IPAddress & { kubernetes_services?: IPAddress["kubernetes_services"] }>>(requester, `/subnets/${subnetId}/ips`)).map((record) => mapIPAddress(record)),
 	kubernetesServices: (requester: Requester, subnetId: number) => json<KubernetesServiceObservation[]>(requester, `/subnets/${subnetId}/kubernetes-services`),
+	kubernetesObjects: (requester: Requester, subnetId: number) => json<KubernetesObjectObservation[]>(requester, `/subnets/${subnetId}/kubernetes-objects`),
 	reportingSettings: (requester: Requester) => json<ReportingSettings>(requester, "/reporting/settings"),
 	usageHistory: (requester: Requester, subnetId: number, range: UsageRange) => json<SubnetUsageHistory>(requester, `/subnets/${subnetId}/usage-history?range=${range}`),
 	updateReportingSettings: (requester: Requester, settings: Pick<ReportingSettings, "cadence" | "retention_days">) =>
@@ -63,6 +66,7 @@ export const api = {
 			body: JSON.stringify(existing ? { hostname: hostname.trim() } : { ip, hostname: hostname.trim() }),
 		}), existing?.kubernetes_services),
 	deleteIp: (requester: Requester, subnetId: number, id: string) => requester(`${API_BASE}/subnets/${subnetId}/ips/${id}`, { method: "DELETE" }),
+	eventStream: (requester: Requester, onEvent: (event: ChangeEvent) => void, signal: AbortSignal) => subscribeToEvents(requester, `${API_BASE}/events/stream`, onEvent, signal),
 	importCSV: (requester: Requester, file: File) => {
 		const form = new FormData();
 		form.append("file", file);
@@ -50,9 +50,9 @@ function UsageChart({ history }: { history: SubnetUsageHistory }) {
 	return <div className="usage-chart"><div className="usage-chart__summary"><div><span className="field-label">Latest snapshot</span><strong>{latest ? `${latest.used_ips.toLocaleString()} used` : "No snapshot"}</strong></div><div><span className="field-label">Capacity</span><strong>{latest?.total_ips.toLocaleString() ?? "—"}</strong></div><div><span className="field-label">Samples</span><strong>{history.points.length.toLocaleString()}</strong></div></div>{history.points.length ? <svg viewBox="0 0 720 220" role="img" aria-label={`Subnet usage history with ${history.points.length} recorded snapshots`}><line className="usage-chart__grid" x1="44" x2="684" y1="16" y2="16" /><line className="usage-chart__grid" x1="44" x2="684" y1="96" y2="96" /><line className="usage-chart__axis" x1="44" x2="684" y1="176" y2="176" /><text x="4" y="21">{capacity.toLocaleString()}</text><text x="26" y="181">0</text><text x="44" y="207">{new Date(history.from).toLocaleDateString()}</text><text x="684" y="207" textAnchor="end">{new Date(history.to).toLocaleDateString()}</text>{segments.map((segment, index) => segment.length > 1 ? <polyline className="usage-chart__line" key={index} points={segment.map((point) => `${point.x},${point.y}`).join(" ")} /> : null)}{segments.flat().map((point) => <circle className="usage-chart__point" key={point.capturedAt} cx={point.x} cy={point.y} r="4"><title>{new Date(point.capturedAt).toLocaleString()}: {point.used.toLocaleString()} used</title></circle>)}</svg> : <div className="usage-chart__empty"><strong>No snapshots in this range</strong><p className="muted">Reporting captures current inventory periodically; it does not backfill history from existing rows.</p></div>}<p className="usage-chart__note">Only recorded snapshots are plotted. Missed intervals remain unconnected.</p></div>;
 }
 
-type Props = { subnetId: number; cidr: string; requester: Requester; canEdit: boolean };
+type Props = { subnetId: number; cidr: string; requester: Requester; canEdit: boolean; refreshKey?: number };
 
-export default function UsageHistoryPanel({ subnetId, cidr, requester, canEdit }: Props) {
+export default function UsageHistoryPanel({ subnetId, cidr, requester, canEdit, refreshKey = 0 }: Props) {
 	const ipv4 = !cidr.includes(":");
 	const [settings, setSettings] = useState<ReportingSettings | null>(null);
 	const [history, setHistory] = useState<SubnetUsageHistory | null>(null);
@@ -79,6 +79,14 @@ export default function UsageHistoryPanel({ subnetId, cidr, requester, canEdit }
 		return () => { cancelled = true; };
 	}, [ipv4, range, requester, subnetId]);
 
+	// Snapshot events refresh the chart without resetting the panel.
+	useEffect(() => {
+		if (!refreshKey || !ipv4) return;
+		let cancelled = false;
+		void api.usageHistory(requester, subnetId, range).then((nextHistory) => { if (!cancelled) setHistory(nextHistory); }).catch(() => undefined);
+		return () => { cancelled = true; };
+	}, [refreshKey]);
+
 	const availableRanges = rangeOptions.filter((option) => option.days <= (settings?.retention_days ?? retention));
 	const saveSettings = async (event: FormEvent) => {
 		event.preventDefault();
@@ -0,0 +1,93 @@
+/**
+ * @typedef {{ id?: number; type: string; object_type: string; object_id: string; site_id?: string; subnet_id?: number; occurred_at: string }} ChangeEvent
+ */
+
+/**
+ * Incremental parser for text/event-stream bodies. Feed it decoded chunks;
+ * it returns the complete events seen so far and keeps partial lines.
+ * @returns {(chunk: string) => ChangeEvent[]}
+ */
+export function createEventStreamParser() {
+	let buffer = "";
+	/** @type {string[]} */
+	let data = [];
+	return (chunk) => {
+		buffer += chunk;
+		const lines = buffer.split(/\r\n|\r|\n/);
+		buffer = lines.pop() ?? "";
+		/** @type {ChangeEvent[]} */
+		const events = [];
+		for (const line of lines) {
+			if (line === "") {
+				if (data.length) {
+					try {
+						events.push(JSON.parse(data.join("\n")));
+					} catch {
+						// Ignore frames that are not change events.
+					}
+				}
+				data = [];
+				continue;
+			}
+			if (line.startsWith(":")) continue;
+			const separator = line.indexOf(":");
+			const field = separator === -1 ? line : line.slice(0, separator);
+			const value = separator === -1 ? "" : line.slice(separator + 1).replace(/^ /, "");
+			if (field === "data") data.push(value);
+		}
+		return events;
+	};
+}
+
+/**
+ * @param {ChangeEvent} event
+ * @param {{ subnetId: number; siteId?: string | null }} subnet
+ * @returns {boolean}
+ */
+export function affectsSubnet(event, subnet) {
+	if (event.object_type === "ip" || event.object_type === "subnet") return event.subnet_id === subnet.subnetId;
+	if (event.object_type === "kubernetes") return !event.site_id || event.site_id === subnet.siteId;
+	return false;
+}
+
+/**
+ * Streams change events through fetch so the request carries the same
+ * Authorization header as other API calls; EventSource cannot set headers.
+ * Reconnects with capped backoff until the signal aborts.
+ * @param {(input: string, init?: RequestInit) => Promise<Response>} requester
+ * @param {string} url
+ * @param {(event: ChangeEvent) => void} onEvent
+ * @param {AbortSignal} signal
+ * @param {{ minDelay?: number; maxDelay?: number }} [options]
+ * @returns {Promise<void>}
+ */
+export async function subscribeToEvents(requester, url, onEvent, signal, options = {}) {
+	const minDelay = options.minDelay ?? 1000;
+	const maxDelay = options.maxDelay ?? 30000;
+	let delay = minDelay;
+	while (!signal.aborted) {
+		try {
+			const response = await requester(url, { headers: { Accept: "text/event-stream" }, signal });
+			if (response.ok && response.body) {
+				delay = minDelay;
+				const parse = createEventStreamParser();
+				const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+				for (;;) {
+					const { value, done } = await reader.read();
+					if (done) break;
+					for (const event of parse(value)) onEvent(event);
+				}
+			}
+		} catch {
+			if (signal.aborted) return;
+		}
+		await new Promise((resolve) => {
+			const timer = setTimeout(resolve, delay);
+			signal.addEventListener("abort", () => {
+				clearTimeout(timer);
+				resolve(undefined);
+			}, { once: true });
+		});
+		delay = Math.min(delay * 2, maxDelay);
+	}
+}
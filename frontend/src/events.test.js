@@ -0,0 +1,53 @@
+import assert from "node:assert/strict";
+import { describe, it } from "node:test";
+import { affectsSubnet, createEventStreamParser, subscribeToEvents } from "./events.js";
+
+describe("event stream parser", () => {
+	it("parses frames split across chunks and skips comments", () => {
+		const parse = createEventStreamParser();
+		assert.deepEqual(parse("retry: 5000\n\n: ping\n\nid: 4"), []);
+		assert.deepEqual(parse("2\nevent: ip.created\ndata: {\"id\":42,\"type\":\"ip.created\","), []);
+		assert.deepEqual(parse("\"object_type\":\"ip\",\"subnet_id\":7}\n\n"), [{ id: 42, type: "ip.created", object_type: "ip", subnet_id: 7 }]);
+	});
+
+	it("emits events once the frame is terminated", () => {
+		const parse = createEventStreamParser();
+		const events = parse("event: subnet.updated\r\ndata: {\"type\":\"subnet.updated\",\"object_type\":\"subnet\",\"subnet_id\":3}\r\n\r\n");
+		assert.equal(events.length, 1);
+		assert.equal(events[0].type, "subnet.updated");
+		assert.equal(events[0].subnet_id, 3);
+	});
+});
+
+describe("subnet event matching", () => {
+	const subnet = { subnetId: 7, siteId: "site-a" };
+	for (const testCase of [
+		{ event: { object_type: "ip", subnet_id: 7 }, want: true },
+		{ event: { object_type: "ip", subnet_id: 8 }, want: false },
+		{ event: { object_type: "kubernetes", site_id: "site-a" }, want: true },
+		{ event: { object_type: "kubernetes", site_id: "site-b" }, want: false },
+		{ event: { object_type: "site", site_id: "site-a" }, want: false },
+	]) {
+		it(`${testCase.event.object_type} ${JSON.stringify(testCase.event)} -> ${testCase.want}`, () => {
+			assert.equal(affectsSubnet(/** @type {any} */ (testCase.event), subnet), testCase.want);
+		});
+	}
+});
+
+describe("event subscription", () => {
+	it("delivers events and reconnects after the stream ends", async () => {
+		const controller = new AbortController();
+		/** @type {any[]} */
+		const received = [];
+		let calls = 0;
+		const requester = async () => {
+			calls++;
+			return new Response(`data: {"type":"site.updated","object_type":"site","object_id":"${calls}"}\n\n`, { status: 200 });
+		};
+		await subscribeToEvents(requester, "/api/v1/events/stream", (event) => {
+			received.push(event.object_id);
+			if (received.length === 2) controller.abort();
+		}, controller.signal, { minDelay: 1, maxDelay: 2 });
+		assert.deepEqual(received, ["1", "2"]);
+	});
+});
@@ -19,6 +19,25 @@ export type IPAddress = {
 	created_at: string;
 	updated_at: string;
 	kubernetes_services: KubernetesService[];
+	kubernetes_objects?: KubernetesObject[];
+	kubernetes_allocation?: KubernetesAllocation;
+	kubernetes_registration?: KubernetesRegistration;
+};
+
+export type KubernetesAllocation = {
+	controller: string;
+	service_uid: string;
+	namespace: string;
+	name: string;
+	allocated_at: string;
+};
+
+export type KubernetesRegistration = {
+	source: { key: string; name: string };
+	service_uid: string;
+	namespace: string;
+	name: string;
+	registered_at: string;
 };
 
 export type KubernetesServiceStatus = "matched" | "unmatched" | "ambiguous" | "no_usable_ip";
@@ -64,6 +83,34 @@ export type KubernetesServiceObservation = Omit<KubernetesService, "matched_addr
 	hostnames?: { kind: string; hostname: string }[];
 };
 
+export type KubernetesObjectKind = "node" | "pod" | "endpoint_slice" | "ingress" | "gateway" | "http_route";
+
+export type KubernetesObject = {
+	source: { key: string; name: string };
+	kind: KubernetesObjectKind;
+	uid: string;
+	namespace?: string;
+	name: string;
+	host_network: boolean;
+	address_kind: string;
+	node_name?: string;
+	hostnames?: { kind: string; hostname: string }[];
+	observed_at: string;
+};
+
+export type KubernetesObjectObservation = Omit<KubernetesObject, "address_kind" | "node_name"> & {
+	addresses: {
+		ip: string;
+		kind: string;
+		node_name?: string;
+		match_status: Exclude<KubernetesServiceStatus, "no_usable_ip">;
+		match_count: number;
+		matched_ip_address_id?: string;
+		matched_subnet_id?: number;
+		load_balancer_services?: string[];
+	}[];
+};
+
 export type KubernetesServiceSummary = {
 	count: number;
 	statuses: Partial<Record<KubernetesServiceStatus, number>>;
@@ -1,8 +1,9 @@
 import { memo, useEffect, useMemo, useState } from "react";
 import { api, type Requester } from "../api";
 import UsageHistoryPanel from "../components/UsageHistoryPanel";
+import { affectsSubnet, type ChangeEvent } from "../events.js";
 import { formatIPv4, parseUsableIPv4Cidr } from "../subnet.js";
-import type { IPAddress, KubernetesService, KubernetesServiceObservation, KubernetesServiceStatus, SiteStatistics, Subnet } from "../types";
+import type { IPAddress, KubernetesObject, KubernetesObjectObservation, KubernetesService, KubernetesServiceObservation, KubernetesServiceStatus, SiteStatistics, Subnet } from "../types";
 
 const WINDOW = 256;
 const statusLabel: Record<KubernetesServiceStatus, string> = { matched: "Matched", unmatched: "Unmatched", ambiguous: "Ambiguous", no_usable_ip: "No usable IP" };
@@ -24,13 +25,18 @@ const ServiceCard = ({ service, observation = false }: { service: KubernetesServ
 	const addresses = "addresses" in service ? service.addresses : service.matched_addresses;
 	return <article className="kubernetes-service"><div className="kubernetes-service__heading"><strong title={`${service.namespace}/${service.name}`}>{service.namespace}/{service.name}</strong><span className="service-badge">{service.type}</span></div><div className="kubernetes-service__meta"><span>{service.source.name || service.source.key}</span>{service.dns_name ? <span className="mono" title={service.dns_name}>{service.dns_name}</span> : null}</div>{observation ? <div className="kubernetes-service__addresses">{addresses.length ? addresses.map((address, index) => <span className={`service-status service-status--${address.match_status || "unmatched"}`} key={`${address.kind}-${address.ip}-${index}`}>{address.kind}: {address.ip} · {statusLabel[address.match_status || "unmatched"]}</span>) : <span className="muted">No usable IP addresses</span>}</div> : null}<div className="kubernetes-service__ports">{service.ports.length ? service.ports.map((port, index) => <span className="port-badge" key={`${port.name || port.port}-${port.protocol}-${index}`}>{port.name ? `${port.name} ` : ""}{port.port}/{port.protocol}</span>) : <span className="muted">No declared ports</span>}</div><span className={`service-status service-status--${status}`} aria-label={`Service status: ${statusLabel[status]}`}>{statusLabel[status]}</span></article>;
 };
-const IpRow = memo(({ address, record, saving, onSave }: { address: string; record?: IPAddress; saving: boolean; onSave: (address: string, hostname: string) => void }) => { const [draft, setDraft] = useState(record?.hostname ?? ""); useEffect(() => setDraft(record?.hostname ?? ""), [record?.hostname]); return <article className="ip-card"><div className="ip-card__heading"><strong className="mono">{address}</strong><span className="muted">{record?.updated_at ? `Updated ${new Date(record.updated_at).toLocaleString()}` : "Untracked"}</span></div><label className="manual-hostname"><span>Manual hostname</span><input aria-label={`Manual hostname for ${address}`} value={draft} onChange={(event) => setDraft(event.target.value)} placeholder="(unset)" /></label><div><span className="field-label">Kubernetes Services</span><div className="kubernetes-services">{record?.kubernetes_services?.length ? record.kubernetes_services.map((service) => <ServiceCard key={`${service.source.key}:${service.uid}`} service={service} />) : <span className="muted">No discovered Services</span>}</div></div><button className="secondary ip-card__save" disabled={saving} onClick={() => onSave(address, draft)}>{saving ? "Saving…" : "Save hostname"}</button></article>; }, (a, b) => a.address === b.address && a.record?.id === b.record?.id && a.record?.hostname === b.record?.hostname && a.record?.updated_at === b.record?.updated_at && a.record?.kubernetes_services === b.record?.kubernetes_services && a.saving === b.saving);
+const objectKindLabel: Record<KubernetesObject["kind"], string> = { node: "Node", pod: "Pod", endpoint_slice: "EndpointSlice", ingress: "Ingress", gateway: "Gateway", http_route: "HTTPRoute" };
+const objectName = (object: { namespace?: string; name: string }) => object.namespace ? `${object.namespace}/${object.name}` : object.name;
+const ObjectCard = ({ object }: { object: KubernetesObjectObservation }) => <article className="kubernetes-service"><div className="kubernetes-service__heading"><strong title={objectName(object)}>{objectName(object)}</strong><span className="service-badge">{objectKindLabel[object.kind]}</span>{object.host_network ? <span className="service-badge">hostNetwork</span> : null}</div><div className="kubernetes-service__meta"><span>{object.source.name || object.source.key}</span></div><div className="kubernetes-service__addresses">{object.addresses.map((address) => <span className={`service-status service-status--${address.match_status}`} key={`${address.kind}-${address.ip}`}>{address.kind}: {address.ip}{address.node_name ? ` on ${address.node_name}` : ""}{address.load_balancer_services?.length ? ` via ${address.load_balancer_services.join(", ")}` : ""} · {statusLabel[address.match_status]}</span>)}</div>{object.hostnames?.length ? <div className="kubernetes-service__ports">{object.hostnames.map((hostname) => <span className="port-badge" key={`${hostname.kind}-${hostname.hostname}`}>{hostname.kind}: {hostname.hostname}</span>)}</div> : null}</article>;
+const IpRow = memo(({ address, record, saving, onSave }: { address: string; record?: IPAddress; saving: boolean; onSave: (address: string, hostname: string) => void }) => { const [draft, setDraft] = useState(record?.hostname ?? ""); useEffect(() => setDraft(record?.hostname ?? ""), [record?.hostname]); return <article className="ip-card"><div className="ip-card__heading"><strong className="mono">{address}</strong><span className="muted">{record?.updated_at ? `Updated ${new Date(record.updated_at).toLocaleString()}` : "Untracked"}</span>{record?.kubernetes_allocation ? <span className="port-badge" title={`Allocated to ${record.kubernetes_allocation.controller} on ${new Date(record.kubernetes_allocation.allocated_at).toLocaleString()}`}>LoadBalancer {record.kubernetes_allocation.namespace}/{record.kubernetes_allocation.name}</span> : null}{record?.kubernetes_registration ? <span className="port-badge" title={`Registered by ${record.kubernetes_registration.source.name || record.kubernetes_registration.source.key} on ${new Date(record.kubernetes_registration.registered_at).toLocaleString()}`}>Discovered {record.kubernetes_registration.namespace}/{record.kubernetes_registration.name}</span> : null}</div><label className="manual-hostname"><span>Manual hostname</span><input aria-label={`Manual hostname for ${address}`} value={draft} onChange={(event) => setDraft(event.target.value)} placeholder="(unset)" /></label><div><span className="field-label">Kubernetes Services</span><div className="kubernetes-services">{record?.kubernetes_services?.length ? record.kubernetes_services.map((service) => <ServiceCard key={`${service.source.key}:${service.uid}`} service={service} />) : <span className="muted">No discovered Services</span>}</div></div>{record?.kubernetes_objects?.length ? <div><span className="field-label">Kubernetes objects</span><div className="kubernetes-services">{record.kubernetes_objects.map((object) => <span className="port-badge" key={`${object.source.key}:${object.uid}:${object.address_kind}`} title={object.source.name || object.source.key}>{objectKindLabel[object.kind]} {objectName(object)}{object.node_name && object.kind !== "node" ? ` on ${object.node_name}` : ""}{object.host_network ? " (hostNetwork)" : ""}{object.hostnames?.length ? ` · ${object.hostnames.map((hostname) => hostname.hostname).join(", ")}` : ""}</span>)}</div></div> : null}<button className="secondary ip-card__save" disabled={saving} onClick={() => onSave(address, draft)}>{saving ? "Saving…" : "Save hostname"}</button></article>; }, (a, b) => a.address === b.address && a.record?.id === b.record?.id && a.record?.hostname === b.record?.hostname && a.record?.updated_at === b.record?.updated_at && a.record?.kubernetes_services === b.record?.kubernetes_services && a.record?.kubernetes_objects === b.record?.kubernetes_objects && a.record?.kubernetes_allocation?.service_uid === b.record?.kubernetes_allocation?.service_uid && a.record?.kubernetes_registration?.service_uid === b.record?.kubernetes_registration?.service_uid && a.saving === b.saving);
 
-type Props = { subnet: Subnet; site?: SiteStatistics; requester: Requester; canEdit: boolean; canDelete: boolean; onBack: () => void; onRefreshUsage: () => void };
-export default function SubnetDetailView({ subnet, site, requester, canEdit, canDelete, onBack, onRefreshUsage }: Props) {
-	const [records, setRecords] = useState<IPAddress[]>([]); const [services, setServices] = useState<KubernetesServiceObservation[]>([]); const [loading, setLoading] = useState(true); const [error, setError] = useState<string | null>(null); const [saving, setSaving] = useState<string | null>(null); const [windowStart, setWindowStart] = useState(0);
-	useEffect(() => { setLoading(true); setError(null); void Promise.all([api.ips(requester, subnet.id), api.kubernetesServices(requester, subnet.id)]).then(([nextRecords, nextServices]) => { setRecords(nextRecords); setServices(nextServices); }).catch((err) => setError(err instanceof Error ? err.message : "Unable to load subnet details")).finally(() => setLoading(false)); }, [requester, subnet.id]);
+type Props = { subnet: Subnet; site?: SiteStatistics; requester: Requester; canEdit: boolean; canDelete: boolean; liveEvent: ChangeEvent | null; onBack: () => void; onRefreshUsage: () => void };
+export default function SubnetDetailView({ subnet, site, requester, canEdit, canDelete, liveEvent, onBack, onRefreshUsage }: Props) {
+	const [records, setRecords] = useState<IPAddress[]>([]); const [services, setServices] = useState<KubernetesServiceObservation[]>([]); const [objects, setObjects] = useState<KubernetesObjectObservation[]>([]); const [loading, setLoading] = useState(true); const [error, setError] = useState<string | null>(null); const [saving, setSaving] = useState<string | null>(null); const [windowStart, setWindowStart] = useState(0);
+	useEffect(() => { setLoading(true); setError(null); void Promise.all([api.ips(requester, subnet.id), api.kubernetesServices(requester, subnet.id), api.kubernetesObjects(requester, subnet.id)]).then(([nextRecords, nextServices, nextObjects]) => { setRecords(nextRecords); setServices(nextServices); setObjects(nextObjects); }).catch((err) => setError(err instanceof Error ? err.message : "Unable to load subnet details")).finally(() => setLoading(false)); }, [requester, subnet.id]);
+	const [usageRefreshKey, setUsageRefreshKey] = useState(0);
+	useEffect(() => { if (!liveEvent) return; if (liveEvent.object_type === "reporting") { setUsageRefreshKey((key) => key + 1); return; } if (!affectsSubnet(liveEvent, { subnetId: subnet.id, siteId: subnet.site_id })) return; let cancelled = false; void Promise.all([api.ips(requester, subnet.id), api.kubernetesServices(requester, subnet.id), api.kubernetesObjects(requester, subnet.id)]).then(([nextRecords, nextServices, nextObjects]) => { if (cancelled) return; setRecords(nextRecords); setServices(nextServices); setObjects(nextObjects); }).catch(() => undefined); return () => { cancelled = true; }; }, [liveEvent, requester, subnet.id, subnet.site_id]);
 	const parsed = useMemo(() => parseUsableIPv4Cidr(subnet.cidr), [subnet.cidr]); const max = parsed && parsed.count > WINDOW ? Math.floor((parsed.count - 1) / WINDOW) * WINDOW : 0; const start = Math.min(windowStart, max); const end = parsed ? Math.min(start + WINDOW, parsed.count) : 0; const addresses = useMemo(() => parsed ? Array.from({ length: end - start }, (_, index) => formatIPv4((parsed.first + start + index) >>> 0)) : [], [parsed, start, end]); const map = useMemo(() => new Map(records.map((record) => [record.ip, record])), [records]);
 		const save = async (address: string, hostname: string) => { const existing = map.get(address); setSaving(address); try { if (existing && !hostname.trim()) { const response = await api.deleteIp(requester, subnet.id, existing.id); if (!response.ok) throw new Error("Unable to clear IP"); setRecords((current) => current.filter((record) => record.id !== existing.id)); } else { const saved = await api.saveIp(requester, subnet.id, existing, address, hostname); setRecords((current) => [saved, ...current.filter((record) => record.ip !== saved.ip)]); } onRefreshUsage(); } catch (err) { setError(err instanceof Error ? err.message : "Unable to save IP"); } finally { setSaving(null); } };
-	return <main className="content"><button className="back-link" onClick={onBack}>← Back to subnets</button><div className="page-heading"><div><p className="eyebrow">Subnet detail</p><h1 className="mono">{subnet.cidr}</h1><p className="muted">{site ? `Owned by ${site.name}` : "Unassigned site"} · {subnet.description || "No description"}</p></div><div className="detail-count"><strong>{parsed?.count.toLocaleString() ?? "—"}</strong><span className="muted">usable addresses</span></div></div>{error ? <div className="error" role="alert">{error}</div> : null}<UsageHistoryPanel subnetId={subnet.id} cidr={subnet.cidr} requester={requester} canEdit={canEdit} /><section className="card kubernetes-services-panel"><div className="section-heading"><div><p className="eyebrow">Discovery</p><h2>Kubernetes Services</h2><p className="muted">Observed in the subnet’s site context. Match details appear on each Service.</p></div><span className="pill">{services.length} observed</span></div>{loading ? <p className="muted" aria-live="polite">Loading Services…</p> : services.length ? <div className="kubernetes-service-list">{services.map((service) => <ServiceCard key={`${service.source.key}:${service.uid}`} service={service} observation />)}</div> : <p className="muted">No discovered Services for this subnet site.</p>}</section><section className="card"><div className="table-toolbar"><div><h2 className="panel__title">IP addresses</h2>{!loading && parsed ? <span className="muted">Showing {start + 1}–{end} of {parsed.count.toLocaleString()} usable</span> : null}</div>{max ? <div className="button-group"><button className="secondary" onClick={() => setWindowStart((value) => Math.max(value - WINDOW, 0))} disabled={!start}>Previous</button><button className="secondary" onClick={() => setWindowStart((value) => Math.min(value + WINDOW, max))} disabled={start >= max}>Next</button></div> : null}</div>{loading ? <p className="muted" aria-live="polite">Loading IPs…</p> : !parsed ? <div className="error">Cannot render IPs for this CIDR.</div> : <div className="ip-card-list">{addresses.map((address) => <IpRow key={address} address={address} record={map.get(address)} saving={saving === address} onSave={(value, hostname) => void save(value, hostname)} />)}</div>}</section></main>;
+	return <main className="content"><button className="back-link" onClick={onBack}>← Back to subnets</button><div className="page-heading"><div><p className="eyebrow">Subnet detail</p><h1 className="mono">{subnet.cidr}</h1><p className="muted">{site ? `Owned by ${site.name}` : "Unassigned site"} · {subnet.description || "No description"}</p></div><div className="detail-count"><strong>{parsed?.count.toLocaleString() ?? "—"}</strong><span className="muted">usable addresses</span></div></div>{error ? <div className="error" role="alert">{error}</div> : null}<UsageHistoryPanel subnetId={subnet.id} cidr={subnet.cidr} requester={requester} canEdit={canEdit} refreshKey={usageRefreshKey} /><section className="card kubernetes-services-panel"><div className="section-heading"><div><p className="eyebrow">Discovery</p><h2>Kubernetes Services</h2><p className="muted">Observed in the subnet’s site context. Match details appear on each Service.</p></div><span className="pill">{services.length} observed</span></div>{loading ? <p className="muted" aria-live="polite">Loading Services…</p> : services.length ? <div className="kubernetes-service-list">{services.map((service) => <ServiceCard key={`${service.source.key}:${service.uid}`} service={service} observation />)}</div> : <p className="muted">No discovered Services for this subnet site.</p>}</section>{objects.length ? <section className="card kubernetes-services-panel"><div className="section-heading"><div><p className="eyebrow">Discovery</p><h2>Kubernetes objects</h2><p className="muted">Node, Pod, EndpointSlice, Ingress and Gateway addresses inside this subnet, including hostNetwork Pods with no IPAM record.</p></div><span className="pill">{objects.length} observed</span></div><div className="kubernetes-service-list">{objects.map((object) => <ObjectCard key={`${object.source.key}:${object.uid}`} object={object} />)}</div></section> : null}<section className="card"><div className="table-toolbar"><div><h2 className="panel__title">IP addresses</h2>{!loading && parsed ? <span className="muted">Showing {start + 1}–{end} of {parsed.count.toLocaleString()} usable</span> : null}</div>{max ? <div className="button-group"><button className="secondary" onClick={() => setWindowStart((value) => Math.max(value - WINDOW, 0))} disabled={!start}>Previous</button><button className="secondary" onClick={() => setWindowStart((value) => Math.min(value + WINDOW, max))} disabled={start >= max}>Next</button></div> : null}</div>{loading ? <p className="muted" aria-live="polite">Loading IPs…</p> : !parsed ? <div className="error">Cannot render IPs for this CIDR.</div> : <div className="ip-card-list">{addresses.map((address) => <IpRow key={address} address={address} record={map.get(address)} saving={saving === address} onSave={(value, hostname) => void save(value, hostname)} />)}</div>}</section></main>;
 }
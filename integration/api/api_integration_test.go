@@ -3,6 +3,7 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -12,6 +13,7 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/netip"
 	"net/url"
 	"os"
@@ -19,7 +21,9 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -29,7 +33,9 @@ import (
 	containerruntime "github.com/Flarenzy/simple-k8s-app/integration/containerruntime"
 	app "github.com/Flarenzy/simple-k8s-app/internal/app"
 	appdb "github.com/Flarenzy/simple-k8s-app/internal/db"
+	sqlcdb "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/Flarenzy/simple-k8s-app/internal/webhooks"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/testcontainers/testcontainers-go"
@@ -110,6 +116,43 @@ type ipResponse struct {
 			TargetPort string `json:"target_port"`
 		} `json:"ports"`
 	} `json:"kubernetes_services"`
+	KubernetesObjects []struct {
+		Kind        string `json:"kind"`
+		UID         string `json:"uid"`
+		Name        string `json:"name"`
+		AddressKind string `json:"address_kind"`
+		NodeName    string `json:"node_name"`
+	} `json:"kubernetes_objects"`
+	KubernetesAllocation *struct {
+		Controller string `json:"controller"`
+		ServiceUID string `json:"service_uid"`
+		Namespace  string `json:"namespace"`
+		Name       string `json:"name"`
+	} `json:"kubernetes_allocation"`
+	KubernetesRegistration *struct {
+		ServiceUID string `json:"service_uid"`
+		Namespace  string `json:"namespace"`
+		Name       string `json:"name"`
+	} `json:"kubernetes_registration"`
+}
+
+type kubernetesObjectObservationResponse struct {
+	Kind        string `json:"kind"`
+	UID         string `json:"uid"`
+	Name        string `json:"name"`
+	HostNetwork bool   `json:"host_network"`
+	Addresses   []struct {
+		IP                   string   `json:"ip"`
+		Kind                 string   `json:"kind"`
+		NodeName             string   `json:"node_name"`
+		MatchStatus          string   `json:"match_status"`
+		MatchedIPAddressID   string   `json:"matched_ip_address_id"`
+		LoadBalancerServices []string `json:"load_balancer_services"`
+	} `json:"addresses"`
+	Hostnames []struct {
+		Kind     string `json:"kind"`
+		Hostname string `json:"hostname"`
+	} `json:"hostnames"`
 }
 
 type kubernetesStatusResponse struct {
@@ -119,6 +162,24 @@ type kubernetesStatusResponse struct {
 	State         string     `json:"state"`
 	LastSuccessAt *time.Time `json:"last_success_at"`
 	NoUsableIP    int        `json:"no_usable_ip"`
+	Managed       bool       `json:"managed"`
+	ObjectError   string     `json:"object_error"`
+	Namespaces    []string   `json:"namespaces"`
+}
+
+type kubernetesRunResponse struct {
+	ID        int64     `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+	Services  int       `json:"services"`
+	Error     string    `json:"error"`
+	Changes   int       `json:"changes"`
+}
+
+type kubernetesEventResponse struct {
+	ServiceUID        string   `json:"service_uid"`
+	Change            string   `json:"change"`
+	PreviousAddresses []string `json:"previous_addresses"`
+	Addresses         []string `json:"addresses"`
 }
 
 type kubernetesServiceObservationResponse struct {
@@ -166,8 +227,14 @@ type importResponse struct {
 	} `json:"errors"`
 }
 
-type errorResponse struct {
-	Error string `json:"error"`
+type problemResponse struct {
+	Type   string `json:"type"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"errors"`
 }
 
 type reportingSettingsResponse struct {
@@ -508,7 +575,7 @@ func TestKubernetesDiscoveryReconciliationAndEnrichment(t *testing.T) {
 			DNSName:   "payments.commerce.svc.cluster.test",
 			Addresses: []domain.KubernetesServiceAddress{{Kind: "cluster_ip", Address: netip.MustParseAddr("192.0.2.99")}},
 		},
-	}, observedAt)
+	}, observedAt, observedAt)
 	if err != nil {
 		t.Fatalf("reconcile: %v", err)
 	}
@@ -629,7 +696,7 @@ func TestKubernetesDiscoveryReconciliationAndEnrichment(t *testing.T) {
 	result, err = repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{{
 		UID: "service-uid-1", Namespace: "commerce", Name: "orders", Type: "ClusterIP", ResourceVersion: "2",
 		DNSName: "orders.commerce.svc.cluster.test", Addresses: []domain.KubernetesServiceAddress{{Kind: "cluster_ip", Address: netip.MustParseAddr("10.88.0.10")}},
-	}}, observedAt.Add(2*time.Minute))
+	}}, observedAt.Add(2*time.Minute), observedAt.Add(2*time.Minute))
 	if err != nil || result.Ambiguous != 1 || result.Matched != 0 {
 		t.Fatalf("expected ambiguous site-scoped match, result=%+v err=%v", result, err)
 	}
@@ -651,7 +718,7 @@ func TestKubernetesDiscoveryReconciliationAndEnrichment(t *testing.T) {
 		t.Fatalf("ambiguous Service contract is inaccurate: %+v", discoveredServices)
 	}
 
-	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{}, observedAt.Add(3*time.Minute)); err != nil {
+	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{}, observedAt.Add(3*time.Minute), observedAt.Add(3*time.Minute)); err != nil {
 		t.Fatalf("reconcile complete empty snapshot: %v", err)
 	}
 	var activeServices int
@@ -664,7 +731,7 @@ func TestKubernetesDiscoveryReconciliationAndEnrichment(t *testing.T) {
 	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{{
 		UID: "service-uid-2", Namespace: "commerce", Name: "orders", Type: "ExternalName", ResourceVersion: "1",
 		ExternalName: "orders.example.test", DNSName: "orders.commerce.svc.cluster.test",
-	}}, observedAt.Add(4*time.Minute)); err != nil {
+	}}, observedAt.Add(4*time.Minute), observedAt.Add(4*time.Minute)); err != nil {
 		t.Fatalf("reconcile recreated service: %v", err)
 	}
 	var oldActive, newActive bool
@@ -696,6 +763,539 @@ func TestKubernetesDiscoveryReconciliationAndEnrichment(t *testing.T) {
 			t.Fatalf("expected no_usable_ip source count, got %+v", status)
 		}
 	}
+
+	payments := domain.KubernetesServiceSnapshot{
+		UID: "service-uid-3", Namespace: "commerce", Name: "payments", Type: "ClusterIP", ResourceVersion: "1",
+		DNSName: "payments.commerce.svc.cluster.test", Addresses: []domain.KubernetesServiceAddress{{Kind: "cluster_ip", Address: netip.MustParseAddr("192.0.2.77")}},
+	}
+	result, err = repository.ApplyChanges(context.Background(), source, domain.KubernetesServiceChanges{
+		Upserted:    []domain.KubernetesServiceSnapshot{payments},
+		DeletedUIDs: []string{"service-uid-2"},
+	}, observedAt.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("apply incremental changes: %v", err)
+	}
+	if result.Services != 1 || result.Unmatched != 1 || result.NoUsableIP != 0 {
+		t.Fatalf("incremental result does not count the active services: %+v", result)
+	}
+	if err := pool.QueryRow(context.Background(), `
+		SELECT bool_or(active) FILTER (WHERE kubernetes_uid = 'service-uid-2'),
+		       bool_or(active) FILTER (WHERE kubernetes_uid = 'service-uid-3')
+		FROM kubernetes_services`).Scan(&oldActive, &newActive); err != nil {
+		t.Fatalf("read incrementally changed services: %v", err)
+	}
+	if oldActive || !newActive {
+		t.Fatalf("incremental changes were not applied: deleted active=%t added active=%t", oldActive, newActive)
+	}
+	// An update that changes none of the recorded Service changes, such as a
+	// label edit, is published without a run.
+	payments.ResourceVersion = "2"
+	if _, err := repository.ApplyChanges(context.Background(), source, domain.KubernetesServiceChanges{Upserted: []domain.KubernetesServiceSnapshot{payments}}, observedAt.Add(6*time.Minute)); err != nil {
+		t.Fatalf("apply an update without changes: %v", err)
+	}
+
+	runsResp, err := s.get(t, "/api/v1/kubernetes/sources/"+source.Key+"/runs", token)
+	if err != nil || runsResp.StatusCode != http.StatusOK {
+		t.Fatalf("list discovery runs: status=%v err=%v", runsResp.StatusCode, err)
+	}
+	var runs []kubernetesRunResponse
+	s.decodeJSON(t, runsResp, &runs)
+	if len(runs) != 6 || runs[4].Error != "forbidden" || runs[5].Services != 2 || runs[5].Changes != 2 || !runs[5].StartedAt.Equal(observedAt) {
+		t.Fatalf("discovery runs were not recorded: %+v", runs)
+	}
+	eventsResp, err := s.get(t, fmt.Sprintf("/api/v1/kubernetes/sources/%s/events?run_id=%d", source.Key, runs[3].ID), token)
+	if err != nil || eventsResp.StatusCode != http.StatusOK {
+		t.Fatalf("list discovery events: status=%v err=%v", eventsResp.StatusCode, err)
+	}
+	var events []kubernetesEventResponse
+	s.decodeJSON(t, eventsResp, &events)
+	changes := make(map[string]kubernetesEventResponse)
+	for _, event := range events {
+		changes[event.ServiceUID] = event
+	}
+	changed, stale := changes["service-uid-1"], changes["service-uid-unmatched"]
+	if len(events) != 2 || changed.Change != "address_changed" || !slices.Equal(changed.PreviousAddresses, []string{"10.88.0.10", "192.0.2.88"}) || !slices.Equal(changed.Addresses, []string{"10.88.0.10"}) {
+		t.Fatalf("address change was not recorded with the previous addresses: %+v", events)
+	}
+	if stale.Change != "stale" || !slices.Equal(stale.PreviousAddresses, []string{"192.0.2.99"}) || len(stale.Addresses) != 0 {
+		t.Fatalf("stale Service was not recorded: %+v", stale)
+	}
+}
+
+func TestKubernetesObjectDiscovery(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+
+	createSiteResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/sites", token, map[string]any{"name": "Kubernetes object site"})
+	if err != nil || createSiteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create site: status=%v err=%v", createSiteResp.StatusCode, err)
+	}
+	var site siteResponse
+	s.decodeJSON(t, createSiteResp, &site)
+
+	createSubnetResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{
+		"cidr": "10.89.0.0/24", "site_id": site.ID, "description": "node addresses",
+	})
+	if err != nil || createSubnetResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create subnet: status=%v err=%v", createSubnetResp.StatusCode, err)
+	}
+	var subnet subnetResponse
+	s.decodeJSON(t, createSubnetResp, &subnet)
+
+	createIPResp, err := s.jsonRequest(t, http.MethodPost, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token, map[string]any{
+		"ip": "10.89.0.5", "hostname": "worker-1",
+	})
+	if err != nil || createIPResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create ip: status=%v err=%v", createIPResp.StatusCode, err)
+	}
+	s.closeBody(t, createIPResp)
+
+	pool, err := appdb.NewPool(context.Background(), s.dsn)
+	if err != nil {
+		t.Fatalf("open discovery repository pool: %v", err)
+	}
+	defer pool.Close()
+	repository := appdb.NewKubernetesDiscoveryRepository(pool)
+	source := domain.KubernetesSourceConfig{
+		Key: "object-cluster", Name: "Object cluster", SiteID: uuid.MustParse(site.ID), ClusterDomain: "cluster.test",
+		Namespaces: []string{"kube-system"}, ObjectKinds: []string{domain.KubernetesObjectNode, domain.KubernetesObjectPod, domain.KubernetesObjectIngress}, StaleRetention: 7 * 24 * time.Hour,
+	}
+	observedAt := time.Now().UTC().Truncate(time.Microsecond)
+	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{{
+		UID: "ingress-controller-uid", Namespace: "kube-system", Name: "ingress-controller", Type: "LoadBalancer", ResourceVersion: "1",
+		DNSName:   "ingress-controller.kube-system.svc.cluster.test",
+		Addresses: []domain.KubernetesServiceAddress{{Kind: "load_balancer", Address: netip.MustParseAddr("10.89.0.20")}},
+	}}, observedAt, observedAt); err != nil {
+		t.Fatalf("reconcile ingress controller service: %v", err)
+	}
+	result, err := repository.ReconcileObjects(context.Background(), source, []domain.KubernetesObjectSnapshot{
+		{
+			UID: "node-uid-1", Kind: domain.KubernetesObjectNode, Name: "worker-1", ResourceVersion: "1",
+			Addresses: []domain.KubernetesObjectAddress{{Kind: "internal_ip", Address: netip.MustParseAddr("10.89.0.5"), NodeName: "worker-1"}},
+		},
+		{
+			UID: "pod-uid-1", Kind: domain.KubernetesObjectPod, Namespace: "kube-system", Name: "node-exporter", ResourceVersion: "1", HostNetwork: true,
+			Addresses: []domain.KubernetesObjectAddress{{Kind: "pod_ip", Address: netip.MustParseAddr("10.89.0.6"), NodeName: "worker-2"}},
+		},
+		{
+			UID: "ingress-uid-1", Kind: domain.KubernetesObjectIngress, Namespace: "kube-system", Name: "ipam", ResourceVersion: "1",
+			Addresses: []domain.KubernetesObjectAddress{{Kind: "load_balancer", Address: netip.MustParseAddr("10.89.0.20")}},
+			Hostnames: []domain.KubernetesObjectHostname{{Kind: "host", Hostname: "ipam.example.test"}},
+		},
+	}, observedAt)
+	if err != nil {
+		t.Fatalf("reconcile objects: %v", err)
+	}
+	if result.Objects != 3 || result.Matched != 1 || result.Unmatched != 2 {
+		t.Fatalf("unexpected object result: %+v", result)
+	}
+
+	listResp, err := s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token)
+	if err != nil || listResp.StatusCode != http.StatusOK {
+		t.Fatalf("list enriched ips: status=%v err=%v", listResp.StatusCode, err)
+	}
+	var ips []ipResponse
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 1 || len(ips[0].KubernetesObjects) != 1 {
+		t.Fatalf("unexpected enriched IP: %+v", ips)
+	}
+	if object := ips[0].KubernetesObjects[0]; object.UID != "node-uid-1" || object.AddressKind != "internal_ip" || object.NodeName != "worker-1" {
+		t.Fatalf("unexpected object enrichment: %+v", object)
+	}
+
+	objectsResp, err := s.get(t, fmt.Sprintf("/api/v1/subnets/%d/kubernetes-objects", subnet.ID), token)
+	if err != nil || objectsResp.StatusCode != http.StatusOK {
+		t.Fatalf("list discovered objects: status=%v err=%v", objectsResp.StatusCode, err)
+	}
+	var objects []kubernetesObjectObservationResponse
+	s.decodeJSON(t, objectsResp, &objects)
+	if len(objects) != 3 {
+		t.Fatalf("expected the node, the hostNetwork pod and the ingress, got %+v", objects)
+	}
+	for _, object := range objects {
+		switch object.UID {
+		case "node-uid-1":
+			if object.Addresses[0].MatchStatus != "matched" || object.Addresses[0].MatchedIPAddressID != ips[0].ID {
+				t.Fatalf("node address was not linked: %+v", object)
+			}
+		case "pod-uid-1":
+			if !object.HostNetwork || object.Addresses[0].MatchStatus != "unmatched" || object.Addresses[0].NodeName != "worker-2" {
+				t.Fatalf("unmatched hostNetwork pod was not listed: %+v", object)
+			}
+		case "ingress-uid-1":
+			if len(object.Hostnames) != 1 || object.Hostnames[0].Hostname != "ipam.example.test" {
+				t.Fatalf("ingress hostnames were not stored: %+v", object)
+			}
+			if services := object.Addresses[0].LoadBalancerServices; len(services) != 1 || services[0] != "kube-system/ingress-controller" {
+				t.Fatalf("ingress address was not linked to its LoadBalancer Service: %+v", object)
+			}
+		default:
+			t.Fatalf("unexpected object: %+v", object)
+		}
+	}
+
+	if err := repository.RecordObjectFailure(context.Background(), source, "list pods: pods is forbidden"); err != nil {
+		t.Fatalf("record object failure: %v", err)
+	}
+	if objectError := s.kubernetesObjectError(t, token, source.Key); objectError != "list pods: pods is forbidden" {
+		t.Fatalf("object failure was not recorded: %q", objectError)
+	}
+	if _, err := repository.ReconcileObjects(context.Background(), source, []domain.KubernetesObjectSnapshot{}, observedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("reconcile empty objects: %v", err)
+	}
+	if objectError := s.kubernetesObjectError(t, token, source.Key); objectError != "" {
+		t.Fatalf("published objects did not clear the object failure: %q", objectError)
+	}
+	listResp, err = s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token)
+	if err != nil {
+		t.Fatalf("list after objects disappeared: %v", err)
+	}
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips[0].KubernetesObjects) != 0 {
+		t.Fatalf("inactive objects still enrich the IP: %+v", ips[0])
+	}
+}
+
+func TestKubernetesLoadBalancerAllocation(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+
+	createSubnetResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{
+		"cidr": "10.90.0.0/30", "description": "load balancer pool",
+	})
+	if err != nil || createSubnetResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create subnet: status=%v err=%v", createSubnetResp.StatusCode, err)
+	}
+	var subnet subnetResponse
+	s.decodeJSON(t, createSubnetResp, &subnet)
+	createIPResp, err := s.jsonRequest(t, http.MethodPost, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token, map[string]any{
+		"ip": "10.90.0.1", "hostname": "router",
+	})
+	if err != nil || createIPResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create ip: status=%v err=%v", createIPResp.StatusCode, err)
+	}
+	s.closeBody(t, createIPResp)
+
+	pool, err := appdb.NewPool(context.Background(), s.dsn)
+	if err != nil {
+		t.Fatalf("open allocation repository pool: %v", err)
+	}
+	defer pool.Close()
+	service := domain.NewKubernetesAllocationService(appdb.NewKubernetesAllocationRepository(pool))
+	input := domain.AllocateLoadBalancerIPInput{Controller: "lb-cluster", SubnetID: subnet.ID, ServiceUID: "orders-uid", Namespace: "apps", Name: "orders"}
+
+	allocation, err := service.Allocate(context.Background(), input)
+	if err != nil || allocation.IP.String() != "10.90.0.2" {
+		t.Fatalf("expected the only free address, got %+v, %v", allocation, err)
+	}
+	if again, err := service.Allocate(context.Background(), input); err != nil || again.IP != allocation.IP || again.IPAddressID != allocation.IPAddressID {
+		t.Fatalf("repeated allocation should return the same address, got %+v, %v", again, err)
+	}
+	second := input
+	second.ServiceUID, second.Name = "billing-uid", "billing"
+	if _, err := service.Allocate(context.Background(), second); !errors.Is(err, domain.ErrSubnetFull) {
+		t.Fatalf("expected a full subnet, got %v", err)
+	}
+
+	listResp, err := s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token)
+	if err != nil || listResp.StatusCode != http.StatusOK {
+		t.Fatalf("list ips: status=%v err=%v", listResp.StatusCode, err)
+	}
+	var ips []ipResponse
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 2 || ips[0].KubernetesAllocation != nil || ips[1].KubernetesAllocation == nil || ips[1].KubernetesAllocation.Name != "orders" {
+		t.Fatalf("unexpected allocation in IP list: %+v", ips)
+	}
+
+	// Another replica's sync that listed the Services before the orders
+	// allocation was made must not release it.
+	if released, err := service.ReleaseExcept(context.Background(), "lb-cluster", []string{"billing-uid"}, allocation.AllocatedAt.Add(-time.Second)); err != nil || released != 0 {
+		t.Fatalf("expected an allocation made after the listing to survive, got %d, %v", released, err)
+	}
+	if released, err := service.ReleaseExcept(context.Background(), "lb-cluster", []string{"billing-uid"}, time.Now().UTC()); err != nil || released != 1 {
+		t.Fatalf("expected the orders allocation to be released, got %d, %v", released, err)
+	}
+	if allocation, err = service.Allocate(context.Background(), second); err != nil || allocation.IP.String() != "10.90.0.2" {
+		t.Fatalf("expected the released address to be reused, got %+v, %v", allocation, err)
+	}
+	if released, err := service.Release(context.Background(), "lb-cluster", "billing-uid"); err != nil || !released {
+		t.Fatalf("release: %v, %v", released, err)
+	}
+
+	createSubnetResp, err = s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{
+		"cidr": "10.90.1.0/28", "description": "load balancer pool next to DHCP",
+	})
+	if err != nil || createSubnetResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create dhcp subnet: status=%v err=%v", createSubnetResp.StatusCode, err)
+	}
+	var dhcpSubnet subnetResponse
+	s.decodeJSON(t, createSubnetResp, &dhcpSubnet)
+	dhcpResp, err := s.jsonRequest(t, http.MethodPatch, fmt.Sprintf("/api/v1/subnets/%d/dhcp", dhcpSubnet.ID), token, map[string]any{
+		"gateway": "10.90.1.1", "pools": []map[string]string{{"start": "10.90.1.2", "end": "10.90.1.9"}},
+	})
+	if err != nil || dhcpResp.StatusCode != http.StatusOK {
+		t.Fatalf("set dhcp settings: status=%v err=%v", dhcpResp.StatusCode, err)
+	}
+	s.closeBody(t, dhcpResp)
+	third := input
+	third.SubnetID, third.ServiceUID, third.Name = dhcpSubnet.ID, "edge-uid", "edge"
+	third.Preferred = netip.MustParseAddr("10.90.1.5")
+	if allocation, err = service.Allocate(context.Background(), third); err != nil || allocation.IP.String() != "10.90.1.10" {
+		t.Fatalf("expected the gateway and DHCP pool to be skipped, got %+v, %v", allocation, err)
+	}
+}
+
+func TestKubernetesAddressClaims(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+
+	createSubnetResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{
+		"cidr": "10.92.0.0/30", "description": "cluster api pool",
+	})
+	if err != nil || createSubnetResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create subnet: status=%v err=%v", createSubnetResp.StatusCode, err)
+	}
+	var subnet subnetResponse
+	s.decodeJSON(t, createSubnetResp, &subnet)
+
+	pool, err := appdb.NewPool(context.Background(), s.dsn)
+	if err != nil {
+		t.Fatalf("open claim repository pool: %v", err)
+	}
+	defer pool.Close()
+	service := domain.NewKubernetesAddressClaimService(appdb.NewKubernetesAddressClaimRepository(pool), appdb.NewDNSZoneRepository(sqlcdb.New(pool)))
+	input := domain.ClaimAddressInput{SubnetID: subnet.ID, ClaimUID: "worker-0-uid", Namespace: "capi", Name: "worker-0"}
+
+	claim, err := service.Claim(context.Background(), input)
+	if err != nil || claim.IP.String() != "10.92.0.1" {
+		t.Fatalf("expected the lowest free address, got %+v, %v", claim, err)
+	}
+	if again, err := service.Claim(context.Background(), input); err != nil || again.IPAddressID != claim.IPAddressID {
+		t.Fatalf("repeated claim should return the same address, got %+v, %v", again, err)
+	}
+	second := input
+	second.ClaimUID, second.Name = "worker-1-uid", "worker-1"
+	if _, err := service.Claim(context.Background(), second); err != nil {
+		t.Fatalf("claim second address: %v", err)
+	}
+	third := input
+	third.ClaimUID, third.Name = "worker-2-uid", "worker-2"
+	if _, err := service.Claim(context.Background(), third); !errors.Is(err, domain.ErrSubnetFull) {
+		t.Fatalf("expected a full subnet, got %v", err)
+	}
+
+	if released, err := service.Release(context.Background(), "worker-0-uid"); err != nil || !released {
+		t.Fatalf("release: %v, %v", released, err)
+	}
+	if released, err := service.Release(context.Background(), "worker-0-uid"); err != nil || released {
+		t.Fatalf("second release should find nothing, got %v, %v", released, err)
+	}
+	listResp, err := s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token)
+	if err != nil || listResp.StatusCode != http.StatusOK {
+		t.Fatalf("list ips: status=%v err=%v", listResp.StatusCode, err)
+	}
+	var ips []ipResponse
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 1 || ips[0].Hostname != "worker-1" {
+		t.Fatalf("expected only the second claim's address to remain, got %+v", ips)
+	}
+}
+
+func TestKubernetesAutoRegistration(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+
+	createSiteResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/sites", token, map[string]any{"name": "Kubernetes registration site"})
+	if err != nil || createSiteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create site: status=%v err=%v", createSiteResp.StatusCode, err)
+	}
+	var site siteResponse
+	s.decodeJSON(t, createSiteResp, &site)
+	var subnets []subnetResponse
+	for _, cidr := range []string{"10.91.0.0/16", "10.91.1.0/24"} {
+		createSubnetResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{
+			"cidr": cidr, "site_id": site.ID, "description": "metallb",
+		})
+		if err != nil || createSubnetResp.StatusCode != http.StatusCreated {
+			t.Fatalf("create subnet %s: status=%v err=%v", cidr, createSubnetResp.StatusCode, err)
+		}
+		var subnet subnetResponse
+		s.decodeJSON(t, createSubnetResp, &subnet)
+		subnets = append(subnets, subnet)
+	}
+	pool := subnets[1]
+
+	db, err := appdb.NewPool(context.Background(), s.dsn)
+	if err != nil {
+		t.Fatalf("open discovery repository pool: %v", err)
+	}
+	defer db.Close()
+	repository := appdb.NewKubernetesDiscoveryRepository(db)
+	source := domain.KubernetesSourceConfig{
+		Key: "registration-cluster", Name: "Registration cluster", SiteID: uuid.MustParse(site.ID), ClusterDomain: "cluster.test",
+		Namespaces: []string{"*"}, StaleRetention: time.Hour, AutoRegister: domain.KubernetesAutoRegisterLoadBalancerOnly,
+	}
+	edge := domain.KubernetesServiceSnapshot{
+		UID: "edge-uid", Namespace: "metallb-system", Name: "edge", Type: "LoadBalancer", ResourceVersion: "1",
+		DNSName: "edge.metallb-system.svc.cluster.test",
+		Addresses: []domain.KubernetesServiceAddress{
+			{Kind: "cluster_ip", Address: netip.MustParseAddr("10.91.1.11")},
+			{Kind: "load_balancer", Address: netip.MustParseAddr("10.91.1.10")},
+			{Kind: "load_balancer", Address: netip.MustParseAddr("10.91.1.255")},
+			{Kind: "load_balancer", Address: netip.MustParseAddr("192.0.2.10")},
+		},
+	}
+	observedAt := time.Now().UTC().Truncate(time.Microsecond)
+	for i := range 2 {
+		cycleAt := observedAt.Add(time.Duration(i) * time.Minute)
+		result, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{edge}, cycleAt, cycleAt)
+		if err != nil {
+			t.Fatalf("reconcile %d: %v", i, err)
+		}
+		if result.Matched != 1 || result.Unmatched != 3 {
+			t.Fatalf("reconcile %d: expected only the LoadBalancer address in the pool to be registered, got %+v", i, result)
+		}
+	}
+
+	listResp, err := s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", pool.ID), token)
+	if err != nil || listResp.StatusCode != http.StatusOK {
+		t.Fatalf("list ips: status=%v err=%v", listResp.StatusCode, err)
+	}
+	var ips []ipResponse
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 1 || ips[0].IP != "10.91.1.10" || ips[0].Hostname != "edge.metallb-system.svc.cluster.test" {
+		t.Fatalf("expected the registered LoadBalancer address in the most specific subnet, got %+v", ips)
+	}
+	if registration := ips[0].KubernetesRegistration; registration == nil || registration.ServiceUID != "edge-uid" || registration.Name != "edge" {
+		t.Fatalf("expected the registering Service, got %+v", registration)
+	}
+	if len(ips[0].KubernetesServices) != 1 {
+		t.Fatalf("registered address is not linked to its Service: %+v", ips[0])
+	}
+
+	// A Service whose address changes takes its registration along, in full
+	// and in incremental publications.
+	edge.ResourceVersion = "2"
+	edge.Addresses[1].Address = netip.MustParseAddr("10.91.1.12")
+	changedAt := observedAt.Add(2 * time.Minute)
+	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{edge}, changedAt, changedAt); err != nil {
+		t.Fatalf("reconcile with the changed address: %v", err)
+	}
+	listResp, err = s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", pool.ID), token)
+	if err != nil {
+		t.Fatalf("list ips after the address change: %v", err)
+	}
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 1 || ips[0].IP != "10.91.1.12" {
+		t.Fatalf("expected only the new address to stay registered, got %+v", ips)
+	}
+	edge.ResourceVersion = "3"
+	edge.Addresses[1].Address = netip.MustParseAddr("10.91.1.13")
+	changedAt = changedAt.Add(time.Minute)
+	if _, err := repository.ApplyChanges(context.Background(), source, domain.KubernetesServiceChanges{Upserted: []domain.KubernetesServiceSnapshot{edge}}, changedAt); err != nil {
+		t.Fatalf("apply the changed address: %v", err)
+	}
+	listResp, err = s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", pool.ID), token)
+	if err != nil {
+		t.Fatalf("list ips after the incremental address change: %v", err)
+	}
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 1 || ips[0].IP != "10.91.1.13" {
+		t.Fatalf("expected only the newest address to stay registered, got %+v", ips)
+	}
+
+	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{}, observedAt.Add(5*time.Minute), observedAt.Add(5*time.Minute)); err != nil {
+		t.Fatalf("reconcile without the Service: %v", err)
+	}
+	listResp, err = s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", pool.ID), token)
+	if err != nil {
+		t.Fatalf("list ips while stale: %v", err)
+	}
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 1 {
+		t.Fatalf("registered address removed before the stale retention: %+v", ips)
+	}
+
+	if _, err := repository.Reconcile(context.Background(), source, []domain.KubernetesServiceSnapshot{}, observedAt.Add(2*time.Hour), observedAt.Add(2*time.Hour)); err != nil {
+		t.Fatalf("reconcile past the stale retention: %v", err)
+	}
+	listResp, err = s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", pool.ID), token)
+	if err != nil {
+		t.Fatalf("list ips after cleanup: %v", err)
+	}
+	s.decodeJSON(t, listResp, &ips)
+	if len(ips) != 0 {
+		t.Fatalf("registered address outlived its Service: %+v", ips)
+	}
+}
+
+func TestKubernetesSourcesManagedThroughAPI(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+
+	createSiteResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/sites", token, map[string]any{"name": "Managed Kubernetes source site"})
+	if err != nil || createSiteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create site: status=%v err=%v", createSiteResp.StatusCode, err)
+	}
+	var site siteResponse
+	s.decodeJSON(t, createSiteResp, &site)
+
+	createResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/kubernetes/sources", token, map[string]any{
+		"key": "managed-cluster", "site_id": site.ID, "namespaces": []string{"apps"},
+	})
+	if err != nil || createResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create source: status=%v err=%v body=%s", createResp.StatusCode, err, s.readBody(t, createResp))
+	}
+	var created kubernetesStatusResponse
+	s.decodeJSON(t, createResp, &created)
+	if !created.Managed || created.State != "pending" {
+		t.Fatalf("unexpected created source: %+v", created)
+	}
+	duplicateResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/kubernetes/sources", token, map[string]any{
+		"key": "managed-cluster", "site_id": site.ID, "namespaces": []string{"apps"},
+	})
+	if err != nil || duplicateResp.StatusCode != http.StatusConflict {
+		t.Fatalf("duplicate source: status=%v err=%v", duplicateResp.StatusCode, err)
+	}
+	s.closeBody(t, duplicateResp)
+
+	// A deployment that still lists the key must not overwrite the managed row.
+	pool, err := appdb.NewPool(context.Background(), s.dsn)
+	if err != nil {
+		t.Fatalf("open discovery repository pool: %v", err)
+	}
+	defer pool.Close()
+	configured := domain.KubernetesSourceConfig{Key: "managed-cluster", Name: "managed-cluster", SiteID: uuid.MustParse(site.ID), ClusterDomain: "cluster.local", Namespaces: []string{"other"}, StaleRetention: time.Hour}
+	if _, err := appdb.NewKubernetesDiscoveryRepository(pool).Reconcile(context.Background(), configured, []domain.KubernetesServiceSnapshot{}, time.Now().UTC(), time.Now().UTC()); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected configured reconcile of a managed source to conflict, got %v", err)
+	}
+	if err := appdb.NewKubernetesDiscoveryRepository(pool).RequestReconcile(context.Background(), "managed-cluster", uuid.NewString(), time.Now().UTC()); err != nil {
+		t.Fatalf("request reconcile of managed source: %v", err)
+	}
+	if err := appdb.NewKubernetesDiscoveryRepository(pool).RequestReconcile(context.Background(), "missing-cluster", uuid.NewString(), time.Now().UTC()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected reconcile request for a missing source to be not found, got %v", err)
+	}
+
+	updateResp, err := s.jsonRequest(t, http.MethodPatch, "/api/v1/kubernetes/sources/managed-cluster", token, map[string]any{"namespaces": []string{"*"}})
+	if err != nil || updateResp.StatusCode != http.StatusOK {
+		t.Fatalf("update source: status=%v err=%v", updateResp.StatusCode, err)
+	}
+	var updated kubernetesStatusResponse
+	s.decodeJSON(t, updateResp, &updated)
+	if len(updated.Namespaces) != 1 || updated.Namespaces[0] != "*" {
+		t.Fatalf("unexpected updated source: %+v", updated)
+	}
+
+	deleteResp, err := s.request(t, http.MethodDelete, "/api/v1/kubernetes/sources/managed-cluster", token, nil)
+	if err != nil || deleteResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete source: status=%v err=%v", deleteResp.StatusCode, err)
+	}
+	s.closeBody(t, deleteResp)
+	deleteResp, err = s.request(t, http.MethodDelete, "/api/v1/kubernetes/sources/managed-cluster", token, nil)
+	if err != nil || deleteResp.StatusCode != http.StatusNotFound {
+		t.Fatalf("delete missing source: status=%v err=%v", deleteResp.StatusCode, err)
+	}
+	s.closeBody(t, deleteResp)
 }
 
 func TestSitesCRUDAndStatistics(t *testing.T) {
@@ -911,14 +1511,14 @@ func TestCustomerJourney(t *testing.T) {
 	if err != nil {
 		t.Fatalf("duplicate ip request: %v", err)
 	}
-	if duplicateIPResp.StatusCode != http.StatusBadRequest {
-		t.Fatalf("expected 400 for duplicate ip, got %d", duplicateIPResp.StatusCode)
+	if duplicateIPResp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate ip, got %d", duplicateIPResp.StatusCode)
 	}
 
-	var duplicateErr errorResponse
+	var duplicateErr problemResponse
 	s.decodeJSON(t, duplicateIPResp, &duplicateErr)
-	if duplicateErr.Error != "bad request, ip exists" {
-		t.Fatalf("unexpected duplicate ip error: %q", duplicateErr.Error)
+	if !strings.HasSuffix(duplicateErr.Type, "#conflict") {
+		t.Fatalf("unexpected duplicate ip problem type: %q", duplicateErr.Type)
 	}
 
 	outsideIPResp, err := s.jsonRequest(
@@ -938,10 +1538,10 @@ func TestCustomerJourney(t *testing.T) {
 		t.Fatalf("expected 400 for out-of-subnet ip, got %d", outsideIPResp.StatusCode)
 	}
 
-	var outsideErr errorResponse
+	var outsideErr problemResponse
 	s.decodeJSON(t, outsideIPResp, &outsideErr)
-	if outsideErr.Error != "bad request" {
-		t.Fatalf("unexpected outside ip error: %q", outsideErr.Error)
+	if !strings.HasSuffix(outsideErr.Type, "#validation-failed") || len(outsideErr.Errors) != 1 || outsideErr.Errors[0].Field != "ip" {
+		t.Fatalf("unexpected outside ip problem: %+v", outsideErr)
 	}
 
 	listIPResp, err := s.get(t, fmt.Sprintf("/api/v1/subnets/%d/ips", subnet.ID), token)
@@ -1104,6 +1704,185 @@ func TestCSVImportJourney(t *testing.T) {
 	}
 }
 
+func TestWebhookDeliveryFromOutbox(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+	received := make(chan webhooks.Envelope, 8)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		unix, _ := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+		if !webhooks.Verify("integration-webhook-secret", time.Unix(unix, 0), body, r.Header.Get(webhooks.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var envelope webhooks.Envelope
+		if err := json.Unmarshal(body, &envelope); err == nil {
+			received <- envelope
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	subscriptionResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/webhooks", token, map[string]any{
+		"url": receiver.URL, "event_types": []string{"subnet.created"}, "secret": "integration-webhook-secret",
+	})
+	if err != nil || subscriptionResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create webhook: status=%v err=%v", subscriptionResp.StatusCode, err)
+	}
+	var subscription struct {
+		ID string `json:"id"`
+	}
+	s.decodeJSON(t, subscriptionResp, &subscription)
+	t.Cleanup(func() {
+		response, cleanupErr := s.request(t, http.MethodDelete, "/api/v1/webhooks/"+subscription.ID, token, nil)
+		if cleanupErr != nil {
+			t.Errorf("delete webhook: %v", cleanupErr)
+			return
+		}
+		s.closeBody(t, response)
+	})
+
+	siteResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/sites", token, map[string]any{"name": "Webhook site"})
+	if err != nil || siteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create webhook site: status=%v err=%v", siteResp.StatusCode, err)
+	}
+	var site siteResponse
+	s.decodeJSON(t, siteResp, &site)
+	subnetResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{"cidr": "10.126.0.0/24", "site_id": site.ID})
+	if err != nil || subnetResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create webhook subnet: status=%v err=%v", subnetResp.StatusCode, err)
+	}
+	var subnet subnetResponse
+	s.decodeJSON(t, subnetResp, &subnet)
+
+	timeout := time.After(20 * time.Second)
+	for {
+		select {
+		case envelope := <-received:
+			if envelope.Type != "subnet.created" {
+				t.Fatalf("received unsubscribed event: %+v", envelope)
+			}
+			if envelope.ObjectID != strconv.FormatInt(subnet.ID, 10) {
+				continue
+			}
+			if envelope.SiteID == nil || envelope.SiteID.String() != site.ID {
+				t.Fatalf("expected site %s on webhook event, got %+v", site.ID, envelope)
+			}
+			return
+		case <-timeout:
+			t.Fatalf("webhook for subnet %d was not delivered", subnet.ID)
+		}
+	}
+}
+
+func TestEventStreamDeliversSiteScopedChanges(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+
+	siteResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/sites", token, map[string]any{"name": "Event stream site"})
+	if err != nil || siteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create event site: status=%v err=%v", siteResp.StatusCode, err)
+	}
+	var site siteResponse
+	s.decodeJSON(t, siteResp, &site)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/api/v1/events/stream?types=subnet&site_id="+site.ID, nil)
+	if err != nil {
+		t.Fatalf("build stream request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil || stream.StatusCode != http.StatusOK {
+		t.Fatalf("open event stream: status=%v err=%v", stream.StatusCode, err)
+	}
+	defer s.closeBody(t, stream)
+
+	// Frames are written only after the subscription is registered, so the
+	// retry preamble marks the point where changes are observed.
+	reader := bufio.NewReader(stream.Body)
+	if line, readErr := reader.ReadString('\n'); readErr != nil || !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("expected retry preamble, got %q err=%v", line, readErr)
+	}
+
+	subnetResp, err := s.jsonRequest(t, http.MethodPost, "/api/v1/subnets", token, map[string]any{"cidr": "10.127.0.0/24", "site_id": site.ID})
+	if err != nil || subnetResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create event subnet: status=%v err=%v", subnetResp.StatusCode, err)
+	}
+	var subnet subnetResponse
+	s.decodeJSON(t, subnetResp, &subnet)
+
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			t.Fatalf("subnet %d event was not streamed: %v", subnet.ID, readErr)
+		}
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		var event struct {
+			Type     string `json:"type"`
+			SiteID   string `json:"site_id"`
+			SubnetID int64  `json:"subnet_id"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("decode stream event %q: %v", data, err)
+		}
+		if event.SiteID != site.ID || event.Type != "subnet.created" {
+			t.Fatalf("received event outside the filter: %+v", event)
+		}
+		if event.SubnetID == subnet.ID {
+			return
+		}
+	}
+}
+
+func TestIdempotencyKeyPreventsDuplicateSites(t *testing.T) {
+	s := mustSuite(t)
+	token := s.mustToken(t)
+	key := "integration-" + uuid.NewString()
+	post := func(name string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, s.baseURL+"/api/v1/sites", strings.NewReader(`{"name":"`+name+`"}`))
+		if err != nil {
+			t.Fatalf("build request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("create site: %v", err)
+		}
+		return resp
+	}
+
+	first := post("Idempotent site")
+	if first.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", first.StatusCode, s.readBody(t, first))
+	}
+	var created siteResponse
+	s.decodeJSON(t, first, &created)
+
+	retry := post("Idempotent site")
+	if retry.StatusCode != http.StatusCreated || retry.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed 201, got %d: %s", retry.StatusCode, s.readBody(t, retry))
+	}
+	var replayed siteResponse
+	s.decodeJSON(t, retry, &replayed)
+	if replayed.ID != created.ID {
+		t.Fatalf("expected replay of site %s, got %s", created.ID, replayed.ID)
+	}
+
+	reused := post("Other site")
+	defer s.closeBody(t, reused)
+	if reused.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for reused key, got %d", reused.StatusCode)
+	}
+}
+
 func mustSuite(t *testing.T) *integrationSuite {
 	t.Helper()
 
@@ -1639,6 +2418,23 @@ func (s *integrationSuite) mustTokenFor(t *testing.T, username string) string {
 	return token.AccessToken
 }
 
+func (s *integrationSuite) kubernetesObjectError(t *testing.T, token, key string) string {
+	t.Helper()
+	resp, err := s.get(t, "/api/v1/kubernetes/sources", token)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("discovery status: status=%v err=%v", resp.StatusCode, err)
+	}
+	var statuses []kubernetesStatusResponse
+	s.decodeJSON(t, resp, &statuses)
+	for _, status := range statuses {
+		if status.Source.Key == key {
+			return status.ObjectError
+		}
+	}
+	t.Fatalf("source %q has no status", key)
+	return ""
+}
+
 func (s *integrationSuite) get(t *testing.T, path string, token string) (*http.Response, error) {
 	t.Helper()
 	return s.request(t, http.MethodGet, path, token, nil)
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	appdb "github.com/Flarenzy/simple-k8s-app/internal/db"
+	sqlcdb "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	apihttp "github.com/Flarenzy/simple-k8s-app/internal/http"
+)
+
+const (
+	RateLimitBackendOff      = "off"
+	RateLimitBackendMemory   = "memory"
+	RateLimitBackendPostgres = "postgres"
+)
+
+func parseRateLimitBackend(raw string) (string, error) {
+	switch raw {
+	case "":
+		return RateLimitBackendOff, nil
+	case RateLimitBackendOff, RateLimitBackendMemory, RateLimitBackendPostgres:
+		return raw, nil
+	default:
+		return "", fmt.Errorf("invalid RATE_LIMIT_BACKEND %q: must be off, memory or postgres", raw)
+	}
+}
+
+// parseRateLimits applies comma-separated role.class=requests/window
+// overrides, e.g. "read-only.read=300/1m,editor.csv=5/1h", on top of the
+// defaults. A requests value of 0 removes the limit.
+func parseRateLimits(raw string) (apihttp.RateLimits, error) {
+	limits := apihttp.DefaultRateLimits()
+	for _, entry := range parseCSV(raw) {
+		target, budget, ok := strings.Cut(entry, "=")
+		roleName, className, okTarget := strings.Cut(target, ".")
+		requestsRaw, windowRaw, okBudget := strings.Cut(budget, "/")
+		if !ok || !okTarget || !okBudget {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q: want role.class=requests/window", entry)
+		}
+		role := apiauth.Role(strings.TrimSpace(roleName))
+		if _, known := limits[role]; !known {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q: unknown role %q", entry, role)
+		}
+		class := apihttp.RateLimitClass(strings.TrimSpace(className))
+		switch class {
+		case apihttp.RateLimitRead, apihttp.RateLimitWrite, apihttp.RateLimitCSVImport:
+		default:
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q: class must be read, write or csv", entry)
+		}
+		requests, err := strconv.ParseInt(strings.TrimSpace(requestsRaw), 10, 64)
+		if err != nil || requests < 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q: requests must be a non-negative integer", entry)
+		}
+		window, err := time.ParseDuration(strings.TrimSpace(windowRaw))
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q: window must be a positive duration", entry)
+		}
+		limits[role][class] = domain.RateLimit{Requests: requests, Window: window}
+	}
+	return limits, nil
+}
+
+func newRateLimiter(backend string, queries *sqlcdb.Queries) domain.RateLimiter {
+	switch backend {
+	case RateLimitBackendMemory:
+		return domain.NewRateLimiter(domain.NewMemoryRateLimitCounters())
+	case RateLimitBackendPostgres:
+		return domain.NewRateLimiter(appdb.NewRateLimitRepository(queries))
+	default:
+		return nil
+	}
+}
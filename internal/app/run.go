@@ -8,29 +8,52 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
 	appdb "github.com/Flarenzy/simple-k8s-app/internal/db"
 	sqlcdb "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/dnsserver"
+	"github.com/Flarenzy/simple-k8s-app/internal/dnsupdate"
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/Flarenzy/simple-k8s-app/internal/events"
 	apihttp "github.com/Flarenzy/simple-k8s-app/internal/http"
+	"github.com/Flarenzy/simple-k8s-app/internal/idempotency"
 	kubediscovery "github.com/Flarenzy/simple-k8s-app/internal/kubernetes"
+	"github.com/Flarenzy/simple-k8s-app/internal/metrics"
+	"github.com/Flarenzy/simple-k8s-app/internal/ratelimit"
 	reportingrunner "github.com/Flarenzy/simple-k8s-app/internal/reporting"
+	"github.com/Flarenzy/simple-k8s-app/internal/secrets"
+	"github.com/Flarenzy/simple-k8s-app/internal/telemetry"
+	"github.com/Flarenzy/simple-k8s-app/internal/webhooks"
 )
 
 type Config struct {
-	Port                string
-	DSN                 string
-	ReadTimeout         time.Duration
-	WriteTimeout        time.Duration
-	AuthEnabled         bool
-	Issuer              string
-	Audience            string
-	JWKSURL             string
-	CORSAllowedOrigins  []string
-	KubernetesDiscovery kubediscovery.Config
+	Port               string
+	DSN                string
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	AuthEnabled        bool
+	Issuer             string
+	Audience           string
+	JWKSURL            string
+	CORSAllowedOrigins []string
+	IdempotencyWindow  time.Duration
+	RateLimitBackend   string
+	RateLimits         apihttp.RateLimits
+	Tracing            telemetry.Config
+	MetricsToken       string
+	KubernetesSources  []kubediscovery.Config
+	// KubernetesSourceKey encrypts the kubeconfigs of sources managed
+	// through the API. Without it only in-cluster sources can be created.
+	KubernetesSourceKey []byte
+	KubernetesAllocator kubediscovery.AllocatorConfig
+	IPAMProvider        kubediscovery.IPAMProviderConfig
+	DNS                 domain.DNSSettings
+	DNSListenAddr       string
+	DNSUpdate           dnsupdate.Config
 }
 
 func parseCSV(value string) []string {
@@ -49,10 +72,22 @@ var (
 )
 
 func LoadConfig() (Config, error) {
-	discoveryConfig, err := kubediscovery.ConfigFromEnv(os.Getenv)
+	discoverySources, err := kubediscovery.SourcesFromEnv(os.Getenv)
 	if err != nil {
 		return Config{}, fmt.Errorf("load kubernetes discovery config: %w", err)
 	}
+	allocatorConfig, err := kubediscovery.AllocatorConfigFromEnv(os.Getenv)
+	if err != nil {
+		return Config{}, fmt.Errorf("load kubernetes allocator config: %w", err)
+	}
+	ipamProviderConfig, err := kubediscovery.IPAMProviderConfigFromEnv(os.Getenv)
+	if err != nil {
+		return Config{}, fmt.Errorf("load kubernetes ipam provider config: %w", err)
+	}
+	dnsUpdateConfig, err := dnsupdate.ConfigFromEnv(os.Getenv)
+	if err != nil {
+		return Config{}, fmt.Errorf("load dns update config: %w", err)
+	}
 	cfg := Config{
 		DSN:                 os.Getenv("DB_CONN"),
 		Port:                os.Getenv("PORT"),
@@ -63,7 +98,43 @@ func LoadConfig() (Config, error) {
 		Audience:            os.Getenv("KEYCLOAK_AUDIENCE"),
 		JWKSURL:             os.Getenv("KEYCLOAK_JWKS_URL"),
 		CORSAllowedOrigins:  parseCSV(os.Getenv("CORS_ALLOWED_ORIGINS")),
-		KubernetesDiscovery: discoveryConfig,
+		IdempotencyWindow:   domain.DefaultIdempotencyWindow,
+		Tracing:             telemetry.ConfigFromEnv(os.Getenv),
+		MetricsToken:        os.Getenv("METRICS_TOKEN"),
+		KubernetesSources:   discoverySources,
+		KubernetesAllocator: allocatorConfig,
+		IPAMProvider:        ipamProviderConfig,
+		DNS: domain.DNSSettings{
+			PrimaryNS:  os.Getenv("DNS_PRIMARY_NS"),
+			Hostmaster: os.Getenv("DNS_HOSTMASTER"),
+		},
+		DNSUpdate: dnsUpdateConfig,
+	}
+	if raw := os.Getenv("IDEMPOTENCY_WINDOW"); raw != "" {
+		window, parseErr := time.ParseDuration(raw)
+		if parseErr != nil || window <= 0 {
+			return Config{}, fmt.Errorf("invalid IDEMPOTENCY_WINDOW %q: must be a positive duration", raw)
+		}
+		cfg.IdempotencyWindow = window
+	}
+	cfg.DNSListenAddr = os.Getenv("DNS_LISTEN_ADDR")
+	if raw := os.Getenv("DNS_DEFAULT_TTL"); raw != "" {
+		ttl, parseErr := strconv.ParseInt(raw, 10, 32)
+		if parseErr != nil || ttl <= 0 {
+			return Config{}, fmt.Errorf("invalid DNS_DEFAULT_TTL %q: must be a positive number of seconds", raw)
+		}
+		cfg.DNS.DefaultTTL = int32(ttl)
+	}
+	if raw := os.Getenv("KUBERNETES_SOURCE_ENCRYPTION_KEY"); raw != "" {
+		if cfg.KubernetesSourceKey, err = secrets.ParseKey(raw); err != nil {
+			return Config{}, fmt.Errorf("invalid KUBERNETES_SOURCE_ENCRYPTION_KEY: %w", err)
+		}
+	}
+	if cfg.RateLimitBackend, err = parseRateLimitBackend(os.Getenv("RATE_LIMIT_BACKEND")); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimits, err = parseRateLimits(os.Getenv("RATE_LIMITS")); err != nil {
+		return Config{}, err
 	}
 
 	if cfg.DSN == "" {
@@ -94,6 +165,18 @@ func Run(ctx context.Context, cfg Config) error {
 
 func Serve(ctx context.Context, cfg Config, listener net.Listener) error {
 	logger := slog.Default()
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("initialize tracing: %w", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+			logger.Error("flushing traces", "err", flushErr)
+		}
+	}()
+
 	pool, err := appdb.NewPool(ctx, cfg.DSN)
 	if err != nil {
 		return err
@@ -106,29 +189,112 @@ func Serve(ctx context.Context, cfg Config, listener net.Listener) error {
 	sitesRepo := appdb.NewSitesRepository(queries)
 	discoveryRepo := appdb.NewKubernetesDiscoveryRepository(pool)
 	reportingRepo := appdb.NewReportingRepository(queries)
-	networkService := domain.NewLoggingNetworkService(logger, domain.NewNetworkServiceWithDiscovery(subnetRepo, ipRepo, sitesRepo, discoveryRepo))
-	sitesService := domain.NewSitesService(sitesRepo)
-	discoveryService := domain.NewKubernetesDiscoveryService(discoveryRepo)
-	reportingService := domain.NewReportingService(reportingRepo, subnetRepo)
+	dnsZoneRepo := appdb.NewDNSZoneRepository(queries)
+	baseNetworkService := domain.NewNetworkServiceWithDiscovery(subnetRepo, ipRepo, sitesRepo, discoveryRepo, dnsZoneRepo)
+	networkService := domain.NewTracingNetworkService(domain.NewLoggingNetworkService(logger, baseNetworkService))
+	sitesService := domain.NewTracingSitesService(domain.NewSitesService(sitesRepo))
+	discoveryService := domain.NewTracingKubernetesDiscoveryService(domain.NewKubernetesDiscoveryService(discoveryRepo))
+	reportingService := domain.NewTracingReportingService(domain.NewReportingService(reportingRepo, subnetRepo))
+	webhookService := domain.NewWebhookService(appdb.NewWebhookRepository(queries))
+	idempotencyService := domain.NewIdempotencyService(appdb.NewIdempotencyRepository(queries), cfg.IdempotencyWindow)
 	authenticator, err := newAuthenticator(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("initialize authenticator: %w", err)
 	}
 
 	api := apihttp.NewAPIWithCORS(logger, pool, networkService, sitesService, authenticator, cfg.CORSAllowedOrigins)
-	api.ImportService = domain.NewCSVImportService(sitesService, networkService)
+	api.ImportService = domain.NewTracingImportService(domain.NewCSVImportService(sitesService, networkService))
+	api.LeaseImportService = domain.NewTracingLeaseImportService(domain.NewLeaseImportService(networkService))
+	api.ZoneImportService = domain.NewTracingZoneImportService(domain.NewZoneImportService(networkService))
 	api.DiscoveryService = discoveryService
+	var sourceCipher domain.SecretCipher
+	if cfg.KubernetesSourceKey != nil {
+		cipher, cipherErr := secrets.NewCipher(cfg.KubernetesSourceKey)
+		if cipherErr != nil {
+			return fmt.Errorf("initialize kubernetes source encryption: %w", cipherErr)
+		}
+		sourceCipher = cipher
+	}
+	sourceService := domain.NewTracingKubernetesSourceService(domain.NewKubernetesSourceService(appdb.NewKubernetesSourceRepository(queries), sitesRepo, sourceCipher, kubediscovery.CheckKubeconfig))
+	api.KubernetesSourceService = sourceService
 	api.ReportingService = reportingService
-	go reportingrunner.NewRunner(reportingService, logger).Run(ctx)
+	// The dispatcher polls every few seconds, so only API calls are traced.
+	api.WebhookService = domain.NewTracingWebhookService(webhookService)
+	api.IdempotencyService = idempotencyService
+	dnsService := domain.NewDNSService(dnsZoneRepo, subnetRepo, sitesRepo, cfg.DNS)
+	api.DNSService = domain.NewTracingDNSService(dnsService)
+	api.DHCPService = domain.NewTracingDHCPService(domain.NewDHCPService(subnetRepo, ipRepo, sitesRepo, dnsZoneRepo))
+	appMetrics := metrics.New(logger)
+	appMetrics.Register(metrics.NewPoolCollector(pool), metrics.NewSubnetUtilizationCollector(baseNetworkService, logger))
+	api.RequestObserver = appMetrics
+	api.MetricsHandler = appMetrics.Handler()
+	api.MetricsToken = cfg.MetricsToken
+	eventBroker := events.NewBroker(logger)
+	api.EventStream = eventBroker
+	go eventBroker.Run(ctx, appdb.NewEventListener(pool))
+	if cfg.DNSListenAddr != "" {
+		packetConn, dnsListener, listenErr := dnsserver.Listen(cfg.DNSListenAddr)
+		if listenErr != nil {
+			return fmt.Errorf("listen for dns on %s: %w", cfg.DNSListenAddr, listenErr)
+		}
+		// Zones are cached between changes, so lookups are not traced.
+		dnsServer := dnsserver.NewServer(dnsService, logger)
+		go dnsServer.WatchChanges(ctx, eventBroker)
+		go func() {
+			fmt.Printf("Serving DNS on %s\n", packetConn.LocalAddr())
+			if serveErr := dnsServer.Serve(ctx, packetConn, dnsListener); serveErr != nil {
+				logger.Error("dns server stopped", "err", serveErr)
+			}
+		}()
+	}
+	if cfg.DNSUpdate.Enabled() {
+		dnsUpdateService := domain.NewDNSUpdateService(appdb.NewDNSUpdateRepository(queries), dnsZoneRepo, dnsupdate.NewClient(cfg.DNSUpdate))
+		go dnsupdate.NewRunner(dnsUpdateService, cfg.DNSUpdate, logger).Run(ctx)
+	}
+	go reportingrunner.NewRunner(reportingService, logger).WithObserver(appMetrics).Run(ctx)
+	go webhooks.NewDispatcher(webhookService, logger).Run(ctx)
+	go idempotency.NewPruner(idempotencyService, logger).Run(ctx)
+	if rateLimiter := newRateLimiter(cfg.RateLimitBackend, queries); rateLimiter != nil {
+		api.RateLimiter = rateLimiter
+		api.RateLimits = cfg.RateLimits
+		go ratelimit.NewPruner(rateLimiter, logger).Run(ctx)
+	}
 
-	if cfg.KubernetesDiscovery.Enabled {
-		client, clientErr := kubediscovery.NewClient(cfg.KubernetesDiscovery)
+	// On-demand cycles reach the runners of this replica directly and those
+	// of the others through the event broker.
+	reconcileTriggers := kubediscovery.NewTriggers(discoveryService, eventBroker, logger)
+	api.KubernetesReconciler = reconcileTriggers
+	go reconcileTriggers.Run(ctx)
+	// Each source has its own client and runner, so a failing cluster backs
+	// off without delaying the others.
+	for _, source := range cfg.KubernetesSources {
+		client, clientErr := kubediscovery.NewClient(source)
 		if clientErr != nil {
-			return fmt.Errorf("initialize kubernetes discovery client: %w", clientErr)
+			return fmt.Errorf("initialize kubernetes discovery client for source %q: %w", source.Source.Key, clientErr)
 		}
-		runner := kubediscovery.NewRunner(cfg.KubernetesDiscovery, client, discoveryService, logger)
+		runner := kubediscovery.NewRunner(source, client, discoveryService, logger).WithObserver(appMetrics)
+		reconcileTriggers.Add(runner)
 		go runner.Run(ctx)
 	}
+	// Sources created through the API are started, replaced and stopped by
+	// the supervisor as they change.
+	go kubediscovery.NewSupervisor(sourceService, discoveryService, logger).WithObserver(appMetrics).WithTriggers(reconcileTriggers).Run(ctx, eventBroker)
+	if cfg.KubernetesAllocator.Enabled {
+		allocationService := domain.NewTracingKubernetesAllocationService(domain.NewKubernetesAllocationService(appdb.NewKubernetesAllocationRepository(pool)))
+		allocator, allocatorErr := kubediscovery.NewAllocator(cfg.KubernetesAllocator, allocationService, logger)
+		if allocatorErr != nil {
+			return fmt.Errorf("initialize kubernetes load balancer allocator: %w", allocatorErr)
+		}
+		go allocator.Run(ctx)
+	}
+	if cfg.IPAMProvider.Enabled {
+		claimService := domain.NewTracingKubernetesAddressClaimService(domain.NewKubernetesAddressClaimService(appdb.NewKubernetesAddressClaimRepository(pool), dnsZoneRepo))
+		provider, providerErr := kubediscovery.NewIPAMProvider(cfg.IPAMProvider, networkService, claimService, logger)
+		if providerErr != nil {
+			return fmt.Errorf("initialize kubernetes ipam provider: %w", providerErr)
+		}
+		go provider.Run(ctx)
+	}
 
 	server := &http.Server{
 		Addr:         listener.Addr().String(),
@@ -136,6 +302,7 @@ func Serve(ctx context.Context, cfg Config, listener net.Listener) error {
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 	}
+	server.RegisterOnShutdown(eventBroker.Close)
 
 	errCh := make(chan error, 1)
 	go func() {
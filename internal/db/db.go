@@ -11,6 +11,7 @@ func NewPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, err
 	}
+	cfg.ConnConfig.Tracer = queryTracer{}
 
 	return pgxpool.NewWithConfig(ctx, cfg)
 }
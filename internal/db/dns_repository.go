@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const pgUniqueViolation = "23505"
+
+type DNSZoneRepository struct {
+	queries *sqlc.Queries
+}
+
+var _ domain.DNSZoneRepository = (*DNSZoneRepository)(nil)
+
+func NewDNSZoneRepository(queries *sqlc.Queries) *DNSZoneRepository {
+	return &DNSZoneRepository{queries: queries}
+}
+
+func (r *DNSZoneRepository) ListZones(ctx context.Context) ([]domain.DNSZone, error) {
+	rows, err := r.queries.ListDNSZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]domain.DNSZone, 0, len(rows))
+	for _, row := range rows {
+		zones = append(zones, toDomainDNSZone(row))
+	}
+	return zones, nil
+}
+
+func (r *DNSZoneRepository) FindZoneByName(ctx context.Context, name string) (domain.DNSZone, error) {
+	row, err := r.queries.GetDNSZoneByName(ctx, name)
+	if err != nil {
+		if isNoRows(err) {
+			return domain.DNSZone{}, domain.ErrNotFound
+		}
+		return domain.DNSZone{}, err
+	}
+	return toDomainDNSZone(row), nil
+}
+
+func (r *DNSZoneRepository) FindZoneForSubnet(ctx context.Context, subnetID int64) (domain.DNSZone, error) {
+	row, err := r.queries.GetDNSZoneForSubnet(ctx, subnetID)
+	if err != nil {
+		if isNoRows(err) {
+			return domain.DNSZone{}, domain.ErrNotFound
+		}
+		return domain.DNSZone{}, err
+	}
+	return toDomainDNSZone(row), nil
+}
+
+// CreateZone reports a duplicate name, or a site or subnet that already has
+// a zone, as domain.ErrConflict.
+func (r *DNSZoneRepository) CreateZone(ctx context.Context, zone domain.DNSZone) (domain.DNSZone, error) {
+	params := sqlc.CreateDNSZoneParams{Name: zone.Name, SiteID: siteIDParam(zone.SiteID), Ttl: zone.TTL}
+	if zone.SubnetID != nil {
+		params.SubnetID = pgtype.Int8{Int64: *zone.SubnetID, Valid: true}
+	}
+	row, err := r.queries.CreateDNSZone(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.DNSZone{}, fmt.Errorf("%w: %s", domain.ErrConflict, dnsZoneConflictMessage(pgErr.ConstraintName))
+		}
+		return domain.DNSZone{}, err
+	}
+	created := toDomainDNSZone(row)
+	if err := notifyChangeEvent(ctx, r.queries, dnsZoneChangeEvent(domain.EventDNSZoneCreated, created)); err != nil {
+		return created, fmt.Errorf("notifying dns zone listeners: %w", err)
+	}
+	return created, nil
+}
+
+func dnsZoneConflictMessage(constraint string) string {
+	switch constraint {
+	case "dns_zones_site_id_key":
+		return "site already has a dns zone"
+	case "dns_zones_subnet_id_key":
+		return "subnet already has a dns zone"
+	default:
+		return "dns zone already exists"
+	}
+}
+
+func (r *DNSZoneRepository) DeleteZone(ctx context.Context, name string) (bool, error) {
+	deleted, err := r.queries.DeleteDNSZoneByName(ctx, name)
+	if err != nil || deleted == 0 {
+		return false, err
+	}
+	if err := notifyChangeEvent(ctx, r.queries, dnsZoneChangeEvent(domain.EventDNSZoneDeleted, domain.DNSZone{Name: name})); err != nil {
+		return true, fmt.Errorf("notifying dns zone listeners: %w", err)
+	}
+	return true, nil
+}
+
+// dnsZoneChangeEvent is published on live change notifications only; zones
+// are not written to the outbox, so webhooks do not see them.
+func dnsZoneChangeEvent(eventType string, zone domain.DNSZone) domain.ChangeEvent {
+	return domain.ChangeEvent{
+		Type:       eventType,
+		ObjectType: domain.ObjectTypeDNSZone,
+		ObjectID:   zone.Name,
+		SiteID:     zone.SiteID,
+		SubnetID:   zone.SubnetID,
+		OccurredAt: time.Now(),
+	}
+}
+
+func (r *DNSZoneRepository) ListHostRecords(ctx context.Context) ([]domain.DNSHostRecord, error) {
+	rows, err := r.queries.ListDNSHostRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]domain.DNSHostRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, domain.DNSHostRecord{
+			IP: row.Ip, Hostname: row.Hostname, SubnetID: row.SubnetID, CIDR: row.Cidr, Zone: row.Zone, TTL: row.Ttl,
+		})
+	}
+	return records, nil
+}
+
+func (r *DNSZoneRepository) ListHostRecordsForIP(ctx context.Context, ip netip.Addr) ([]domain.DNSHostRecord, error) {
+	rows, err := r.queries.ListDNSHostRecordsForIP(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]domain.DNSHostRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, domain.DNSHostRecord{
+			IP: row.Ip, Hostname: row.Hostname, SubnetID: row.SubnetID, CIDR: row.Cidr, Zone: row.Zone, TTL: row.Ttl,
+		})
+	}
+	return records, nil
+}
+
+func (r *DNSZoneRepository) BumpSerial(ctx context.Context, zone, contentHash string, candidate uint32) (uint32, error) {
+	serial, err := r.queries.BumpDNSZoneSerial(ctx, sqlc.BumpDNSZoneSerialParams{
+		Zone:        zone,
+		Serial:      int64(candidate),
+		ContentHash: contentHash,
+	})
+	return uint32(serial), err
+}
+
+func toDomainDNSZone(row sqlc.DnsZone) domain.DNSZone {
+	zone := domain.DNSZone{
+		ID:        pgUUIDToUUID(row.ID),
+		Name:      row.Name,
+		Kind:      domain.DNSZoneForward,
+		TTL:       row.Ttl,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	if row.SiteID.Valid {
+		siteID := pgUUIDToUUID(row.SiteID)
+		zone.SiteID = &siteID
+	}
+	if row.SubnetID.Valid {
+		subnetID := row.SubnetID.Int64
+		zone.SubnetID = &subnetID
+	}
+	return zone
+}
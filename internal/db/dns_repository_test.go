@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDNSZoneRepositoryCreateMapsUniqueViolations(t *testing.T) {
+	tests := map[string]string{
+		"dns_zones_site_id_key":   "conflict: site already has a dns zone",
+		"dns_zones_subnet_id_key": "conflict: subnet already has a dns zone",
+		"dns_zones_name_key":      "conflict: dns zone already exists",
+	}
+	for constraint, want := range tests {
+		t.Run(constraint, func(t *testing.T) {
+			repository := NewDNSZoneRepository(sqlc.New(stubDBTX{queryRowFn: func(context.Context, string, ...any) pgx.Row {
+				return stubRow{err: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: constraint}}
+			}}))
+			siteID := uuid.New()
+			_, err := repository.CreateZone(context.Background(), domain.DNSZone{Name: "lab.example.com", SiteID: &siteID, TTL: 3600})
+			if !errors.Is(err, domain.ErrConflict) || err.Error() != want {
+				t.Fatalf("expected %q, got %v", want, err)
+			}
+		})
+	}
+}
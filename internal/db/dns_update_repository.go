@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+type DNSUpdateRepository struct {
+	queries *sqlc.Queries
+}
+
+var _ domain.DNSUpdateRepository = (*DNSUpdateRepository)(nil)
+
+func NewDNSUpdateRepository(queries *sqlc.Queries) *DNSUpdateRepository {
+	return &DNSUpdateRepository{queries: queries}
+}
+
+func (r *DNSUpdateRepository) ClaimDueUpdates(ctx context.Context, limit int32, leaseUntil time.Time) ([]domain.DNSUpdateTask, error) {
+	rows, err := r.queries.ClaimDueDNSUpdates(ctx, sqlc.ClaimDueDNSUpdatesParams{Limit: limit, Column2: timestamp(leaseUntil)})
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]domain.DNSUpdateTask, 0, len(rows))
+	for _, row := range rows {
+		tasks = append(tasks, domain.DNSUpdateTask{IP: row.Ip, Generation: row.Generation, Attempts: row.Attempts})
+	}
+	return tasks, nil
+}
+
+func (r *DNSUpdateRepository) CompleteUpdate(ctx context.Context, task domain.DNSUpdateTask) error {
+	return r.queries.CompleteDNSUpdate(ctx, sqlc.CompleteDNSUpdateParams{Ip: task.IP, Generation: task.Generation})
+}
+
+func (r *DNSUpdateRepository) FailUpdate(ctx context.Context, failure domain.DNSUpdateFailure) error {
+	return r.queries.FailDNSUpdate(ctx, sqlc.FailDNSUpdateParams{
+		Ip:            failure.IP,
+		Generation:    failure.Generation,
+		NextAttemptAt: timestamp(failure.NextAttemptAt),
+		LastError:     failure.Error,
+	})
+}
+
+func (r *DNSUpdateRepository) EnqueueUpdates(ctx context.Context, ips []netip.Addr) error {
+	return r.queries.EnqueueDNSUpdates(ctx, ips)
+}
+
+func (r *DNSUpdateRepository) FindRegistration(ctx context.Context, ip netip.Addr) (domain.DNSRegistration, error) {
+	row, err := r.queries.GetDNSRegistration(ctx, ip)
+	if err != nil {
+		if isNoRows(err) {
+			return domain.DNSRegistration{}, domain.ErrNotFound
+		}
+		return domain.DNSRegistration{}, err
+	}
+	return toDomainDNSRegistration(row), nil
+}
+
+func (r *DNSUpdateRepository) ListRegistrations(ctx context.Context) ([]domain.DNSRegistration, error) {
+	rows, err := r.queries.ListDNSRegistrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registrations := make([]domain.DNSRegistration, 0, len(rows))
+	for _, row := range rows {
+		registrations = append(registrations, toDomainDNSRegistration(row))
+	}
+	return registrations, nil
+}
+
+func (r *DNSUpdateRepository) SaveRegistration(ctx context.Context, registration domain.DNSRegistration) error {
+	return r.queries.UpsertDNSRegistration(ctx, sqlc.UpsertDNSRegistrationParams{
+		Ip:          registration.IP,
+		Name:        registration.Name,
+		Zone:        registration.Zone,
+		ReverseZone: registration.ReverseZone,
+		Ttl:         registration.TTL,
+	})
+}
+
+func (r *DNSUpdateRepository) DeleteRegistration(ctx context.Context, ip netip.Addr) error {
+	return r.queries.DeleteDNSRegistration(ctx, ip)
+}
+
+func toDomainDNSRegistration(row sqlc.DnsRegistration) domain.DNSRegistration {
+	return domain.DNSRegistration{
+		IP:          row.Ip,
+		Name:        row.Name,
+		Zone:        row.Zone,
+		ReverseZone: row.ReverseZone,
+		TTL:         row.Ttl,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EventChannel is the PostgreSQL NOTIFY channel carrying live change events.
+// The outbox trigger and the application publish to it, so every replica's
+// listener sees changes made through any other replica.
+const EventChannel = "ipam_events"
+
+type eventNotification struct {
+	ID         int64      `json:"id,omitempty"`
+	Type       string     `json:"type"`
+	ObjectType string     `json:"object_type"`
+	ObjectID   string     `json:"object_id"`
+	SiteID     *uuid.UUID `json:"site_id"`
+	SubnetID   *int64     `json:"subnet_id"`
+	OccurredAt time.Time  `json:"occurred_at"`
+	// Payload is only sent for on-demand Kubernetes cycles; NOTIFY payloads
+	// are capped at 8000 bytes.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func notifyChangeEvent(ctx context.Context, queries *sqlc.Queries, event domain.ChangeEvent) error {
+	payload, err := json.Marshal(eventNotification{
+		ID:         event.ID,
+		Type:       event.Type,
+		ObjectType: event.ObjectType,
+		ObjectID:   event.ObjectID,
+		SiteID:     event.SiteID,
+		SubnetID:   event.SubnetID,
+		OccurredAt: event.OccurredAt.UTC(),
+		Payload:    event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	return queries.NotifyChangeEvent(ctx, string(payload))
+}
+
+func parseEventNotification(payload string) (domain.ChangeEvent, error) {
+	var notification eventNotification
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		return domain.ChangeEvent{}, fmt.Errorf("decoding event notification: %w", err)
+	}
+	return domain.ChangeEvent{
+		ID:         notification.ID,
+		Type:       notification.Type,
+		ObjectType: notification.ObjectType,
+		ObjectID:   notification.ObjectID,
+		SiteID:     notification.SiteID,
+		SubnetID:   notification.SubnetID,
+		Payload:    notification.Payload,
+		OccurredAt: notification.OccurredAt.UTC(),
+	}, nil
+}
+
+// EventListener holds a dedicated pool connection subscribed to EventChannel.
+type EventListener struct {
+	pool *pgxpool.Pool
+}
+
+func NewEventListener(pool *pgxpool.Pool) *EventListener {
+	return &EventListener{pool: pool}
+}
+
+// Listen blocks, passing every notification to handle, until ctx is cancelled
+// or the connection fails. Malformed payloads are skipped.
+func (l *EventListener) Listen(ctx context.Context, handle func(domain.ChangeEvent)) error {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The LISTEN registration is session state, so the connection is closed
+	// rather than returned to the pool.
+	defer func() {
+		_ = conn.Conn().Close(context.Background())
+		conn.Release()
+	}()
+	if _, err := conn.Exec(ctx, "LISTEN "+EventChannel); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		event, err := parseEventNotification(notification.Payload)
+		if err != nil {
+			continue
+		}
+		handle(event)
+	}
+}
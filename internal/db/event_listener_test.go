@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type execRecorder struct {
+	stubDBTX
+	sql  string
+	args []any
+}
+
+func (r *execRecorder) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	r.sql = sql
+	r.args = args
+	return pgconn.CommandTag{}, nil
+}
+
+func TestNotifyChangeEventRoundTrips(t *testing.T) {
+	siteID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	occurredAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	recorder := &execRecorder{}
+	event := domain.ChangeEvent{
+		Type: domain.EventKubernetesReconciled, ObjectType: domain.ObjectTypeKubernetes,
+		ObjectID: "prod", SiteID: &siteID, OccurredAt: occurredAt,
+	}
+	event, err := withKubernetesCycleOutcome(domain.WithKubernetesCycleRequest(context.Background(), "r1"), event, domain.KubernetesReconcileResult{Services: 3}, "")
+	if err != nil {
+		t.Fatalf("attach outcome: %v", err)
+	}
+	if err := notifyChangeEvent(context.Background(), sqlc.New(recorder), event); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if len(recorder.args) != 1 {
+		t.Fatalf("expected one NOTIFY argument, got %v", recorder.args)
+	}
+	parsed, err := parseEventNotification(recorder.args[0].(string))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Type != event.Type || parsed.ObjectID != "prod" || parsed.SiteID == nil || *parsed.SiteID != siteID || !parsed.OccurredAt.Equal(occurredAt) {
+		t.Fatalf("unexpected event: %+v", parsed)
+	}
+	var outcome domain.KubernetesCycleOutcome
+	if err := json.Unmarshal(parsed.Payload, &outcome); err != nil || outcome.RequestID != "r1" || outcome.Result.Services != 3 {
+		t.Fatalf("unexpected outcome: %s err=%v", parsed.Payload, err)
+	}
+}
+
+func TestParseEventNotificationFromTrigger(t *testing.T) {
+	// Shape produced by json_build_object in record_outbox_event().
+	payload, _ := json.Marshal(map[string]any{
+		"id": 42, "type": "ip.created", "object_type": "ip", "object_id": "0b7c",
+		"site_id": nil, "subnet_id": 7, "occurred_at": "2026-10-01T12:00:00.123456+02:00",
+	})
+	event, err := parseEventNotification(string(payload))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if event.ID != 42 || event.SiteID != nil || event.SubnetID == nil || *event.SubnetID != 7 || event.OccurredAt.Location() != time.UTC {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if _, err := parseEventNotification("{"); err == nil {
+		t.Fatal("expected malformed payload to fail")
+	}
+}
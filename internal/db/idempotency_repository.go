@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type IdempotencyRepository struct {
+	queries *sqlc.Queries
+}
+
+var _ domain.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+func NewIdempotencyRepository(queries *sqlc.Queries) *IdempotencyRepository {
+	return &IdempotencyRepository{queries: queries}
+}
+
+func (r *IdempotencyRepository) Claim(ctx context.Context, record domain.IdempotencyRecord, staleBefore time.Time) (domain.IdempotencyRecord, bool, error) {
+	row, err := r.queries.ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+		Scope:          record.Scope,
+		IdempotencyKey: record.Key,
+		Method:         record.Method,
+		Path:           record.Path,
+		RequestHash:    record.RequestHash,
+		CreatedAt:      timestamp(record.CreatedAt),
+		ExpiresAt:      timestamp(record.ExpiresAt),
+		Column8:        timestamp(staleBefore),
+	})
+	if err == nil {
+		return toDomainIdempotencyRecord(row), true, nil
+	}
+	if !isNoRows(err) {
+		return domain.IdempotencyRecord{}, false, err
+	}
+	existing, err := r.queries.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{Scope: record.Scope, IdempotencyKey: record.Key})
+	if err != nil {
+		if isNoRows(err) {
+			// The holder released the key between the two statements.
+			return domain.IdempotencyRecord{}, false, fmt.Errorf("%w: idempotency key was released concurrently; retry the request", domain.ErrConflict)
+		}
+		return domain.IdempotencyRecord{}, false, err
+	}
+	return toDomainIdempotencyRecord(existing), false, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, response domain.IdempotentResponse) error {
+	return r.queries.CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		Scope:          scope,
+		IdempotencyKey: key,
+		StatusCode:     pgtype.Int4{Int32: int32(response.StatusCode), Valid: true},
+		ContentType:    response.ContentType,
+		ResponseBody:   response.Body,
+	})
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	return r.queries.ReleaseIdempotencyKey(ctx, sqlc.ReleaseIdempotencyKeyParams{Scope: scope, IdempotencyKey: key})
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.DeleteExpiredIdempotencyKeys(ctx, timestamp(before))
+}
+
+func toDomainIdempotencyRecord(row sqlc.IdempotencyKey) domain.IdempotencyRecord {
+	record := domain.IdempotencyRecord{
+		Scope:       row.Scope,
+		Key:         row.IdempotencyKey,
+		Method:      row.Method,
+		Path:        row.Path,
+		RequestHash: row.RequestHash,
+		CreatedAt:   row.CreatedAt.Time,
+		ExpiresAt:   row.ExpiresAt.Time,
+	}
+	if row.StatusCode.Valid {
+		record.Response = domain.IdempotentResponse{
+			StatusCode:  int(row.StatusCode.Int32),
+			ContentType: row.ContentType,
+			Body:        row.ResponseBody,
+		}
+	}
+	return record
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestIdempotencyRepositoryClaimReturnsExistingRecord(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repository := NewIdempotencyRepository(sqlc.New(stubDBTX{queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+		if strings.Contains(sql, "ClaimIdempotencyKey") {
+			return stubRow{err: pgx.ErrNoRows}
+		}
+		return stubRow{values: []any{
+			"admin", "deploy-42", "POST", "/api/v1/sites", "abc",
+			pgtype.Int4{Int32: 201, Valid: true}, "application/json", []byte(`{"id":"1"}`),
+			pgtype.Timestamptz{Time: createdAt, Valid: true}, pgtype.Timestamptz{Time: createdAt.Add(time.Hour), Valid: true},
+		}}
+	}}))
+
+	record, claimed, err := repository.Claim(context.Background(), domain.IdempotencyRecord{Scope: "admin", Key: "deploy-42"}, createdAt)
+	if err != nil || claimed {
+		t.Fatalf("expected existing record, got claimed=%v err=%v", claimed, err)
+	}
+	if record.Response.StatusCode != 201 || string(record.Response.Body) != `{"id":"1"}` || record.RequestHash != "abc" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
+
+func TestIdempotencyRepositoryClaimReportsConcurrentRelease(t *testing.T) {
+	repository := NewIdempotencyRepository(sqlc.New(stubDBTX{}))
+	_, _, err := repository.Claim(context.Background(), domain.IdempotencyRecord{Scope: "admin", Key: "deploy-42"}, time.Now())
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
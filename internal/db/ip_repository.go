@@ -26,9 +26,39 @@ func (r *IPRepository) ListBySubnetID(ctx context.Context, subnetID int64) ([]do
 		return nil, err
 	}
 
+	allocations, err := r.queries.ListKubernetesAllocationsBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[pgtype.UUID]sqlc.KubernetesLoadBalancerAllocation, len(allocations))
+	for _, allocation := range allocations {
+		owners[allocation.IpAddressID] = allocation
+	}
+
+	registrations, err := r.queries.ListKubernetesRegisteredAddressesBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[pgtype.UUID]sqlc.ListKubernetesRegisteredAddressesBySubnetRow, len(registrations))
+	for _, registration := range registrations {
+		registered[registration.IpAddressID] = registration
+	}
+
 	out := make([]domain.IPAddress, 0, len(ips))
 	for _, ip := range ips {
-		out = append(out, toDomainIP(ip))
+		address := toDomainIP(ip)
+		if owner, ok := owners[ip.ID]; ok {
+			allocation := toDomainKubernetesAllocation(owner, ip.Ip, ip.SubnetID)
+			address.KubernetesAllocation = &allocation
+		}
+		if registration, ok := registered[ip.ID]; ok {
+			address.KubernetesRegistration = &domain.KubernetesAddressRegistration{
+				Source:     domain.KubernetesSource{Key: registration.SourceKey, Name: registration.SourceName},
+				ServiceUID: registration.ServiceUid, Namespace: registration.Namespace, Name: registration.Name,
+				RegisteredAt: registration.RegisteredAt.Time,
+			}
+		}
+		out = append(out, address)
 	}
 
 	return out, nil
@@ -56,34 +86,44 @@ func (r *IPRepository) FindByIDAndSubnet(ctx context.Context, id domain.IPAddres
 
 func (r *IPRepository) Create(ctx context.Context, input domain.CreateIPRecord, subnetID int64) (domain.IPAddress, error) {
 	ip, err := r.queries.CreateIPAddress(ctx, sqlc.CreateIPAddressParams{
-		Ip:       input.IP,
-		Hostname: input.Hostname,
-		SubnetID: subnetID,
+		Ip:         input.IP,
+		Hostname:   input.Hostname,
+		SubnetID:   subnetID,
+		MacAddress: input.MACAddress,
+		LastSeenAt: optionalTimestamp(input.LastSeenAt),
 	})
 	if err != nil {
 		if isUniqueIPViolation(err) {
 			return domain.IPAddress{}, domain.ErrConflict
 		}
+		if isUniqueMACViolation(err) {
+			return domain.IPAddress{}, errMACReserved
+		}
 		return domain.IPAddress{}, err
 	}
 
 	return toDomainIP(ip), nil
 }
 
-func (r *IPRepository) UpdateHostname(ctx context.Context, id domain.IPAddressID, input domain.UpdateIPInput) (domain.IPAddress, error) {
+func (r *IPRepository) Update(ctx context.Context, id domain.IPAddressID, input domain.UpdateIPRecord) (domain.IPAddress, error) {
 	parsedID, err := parseDomainIPID(id)
 	if err != nil {
 		return domain.IPAddress{}, fmt.Errorf("%w: invalid ip id", domain.ErrInvalidInput)
 	}
 
 	ip, err := r.queries.UpdateIPByUUID(ctx, sqlc.UpdateIPByUUIDParams{
-		Hostname: input.Hostname,
-		ID:       parsedID,
+		Hostname:   input.Hostname,
+		ID:         parsedID,
+		MacAddress: input.MACAddress,
+		LastSeenAt: optionalTimestamp(input.LastSeenAt),
 	})
 	if err != nil {
 		if isNoRows(err) {
 			return domain.IPAddress{}, domain.ErrNotFound
 		}
+		if isUniqueMACViolation(err) {
+			return domain.IPAddress{}, errMACReserved
+		}
 		return domain.IPAddress{}, err
 	}
 
@@ -112,12 +152,14 @@ func (r *IPRepository) DeleteByIDAndSubnet(ctx context.Context, id domain.IPAddr
 
 func toDomainIP(ip sqlc.IpAddress) domain.IPAddress {
 	return domain.IPAddress{
-		ID:        domain.IPAddressID(ip.ID.String()),
-		IP:        ip.Ip,
-		Hostname:  ip.Hostname,
-		SubnetID:  ip.SubnetID,
-		CreatedAt: ip.CreatedAt.Time,
-		UpdatedAt: ip.UpdatedAt.Time,
+		ID:         domain.IPAddressID(ip.ID.String()),
+		IP:         ip.Ip,
+		Hostname:   ip.Hostname,
+		MACAddress: ip.MacAddress,
+		LastSeenAt: optionalTime(ip.LastSeenAt),
+		SubnetID:   ip.SubnetID,
+		CreatedAt:  ip.CreatedAt.Time,
+		UpdatedAt:  ip.UpdatedAt.Time,
 	}
 }
 
@@ -138,3 +180,10 @@ func isUniqueIPViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	return errors.As(err, &pgErr) && pgErr.ConstraintName == "unique_ip"
 }
+
+var errMACReserved = fmt.Errorf("%w: mac address already reserved in subnet", domain.ErrConflict)
+
+func isUniqueMACViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "ip_addresses_subnet_mac_key"
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"net/netip"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type KubernetesAddressClaimRepository struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+var _ domain.KubernetesAddressClaimRepository = (*KubernetesAddressClaimRepository)(nil)
+
+func NewKubernetesAddressClaimRepository(pool *pgxpool.Pool) *KubernetesAddressClaimRepository {
+	return &KubernetesAddressClaimRepository{pool: pool, queries: sqlc.New(pool)}
+}
+
+// Claim takes the same per-subnet lock as LoadBalancer allocations, so the
+// two controllers never pick the same address. The address record is named
+// after the claim. An existing address in another subnet, left from an
+// earlier pool setting, is released first.
+func (r *KubernetesAddressClaimRepository) Claim(ctx context.Context, input domain.ClaimAddressInput) (domain.KubernetesAddressClaim, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return domain.KubernetesAddressClaim{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := sqlc.New(tx)
+	if err = queries.LockSubnetAllocations(ctx, input.SubnetID); err != nil {
+		return domain.KubernetesAddressClaim{}, err
+	}
+	existing, err := queries.GetKubernetesAddressClaim(ctx, input.ClaimUID)
+	switch {
+	case err == nil && existing.SubnetID == input.SubnetID:
+		return toDomainKubernetesAddressClaim(sqlc.KubernetesAddressClaim{
+			IpAddressID: existing.IpAddressID, ClaimUid: existing.ClaimUid,
+			Namespace: existing.Namespace, Name: existing.Name, ClaimedAt: existing.ClaimedAt,
+		}, existing.Ip, existing.SubnetID), nil
+	case err == nil:
+		if _, err = queries.DeleteKubernetesAddressClaimAddress(ctx, input.ClaimUID); err != nil {
+			return domain.KubernetesAddressClaim{}, err
+		}
+	case !isNoRows(err):
+		return domain.KubernetesAddressClaim{}, err
+	}
+
+	ip, err := recordFreeAddress(ctx, queries, input.SubnetID, netip.Addr{}, input.Name)
+	if err != nil {
+		return domain.KubernetesAddressClaim{}, err
+	}
+	claim, err := queries.CreateKubernetesAddressClaim(ctx, sqlc.CreateKubernetesAddressClaimParams{
+		IpAddressID: ip.ID,
+		ClaimUid:    input.ClaimUID,
+		Namespace:   input.Namespace,
+		Name:        input.Name,
+	})
+	if err != nil {
+		return domain.KubernetesAddressClaim{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return domain.KubernetesAddressClaim{}, err
+	}
+	return toDomainKubernetesAddressClaim(claim, ip.Ip, ip.SubnetID), nil
+}
+
+// Release deletes the claim's address record, and with it the link.
+func (r *KubernetesAddressClaimRepository) Release(ctx context.Context, claimUID string) (bool, error) {
+	deleted, err := r.queries.DeleteKubernetesAddressClaimAddress(ctx, claimUID)
+	return deleted > 0, err
+}
+
+func toDomainKubernetesAddressClaim(claim sqlc.KubernetesAddressClaim, ip netip.Addr, subnetID int64) domain.KubernetesAddressClaim {
+	return domain.KubernetesAddressClaim{
+		ClaimUID:    claim.ClaimUid,
+		Namespace:   claim.Namespace,
+		Name:        claim.Name,
+		IPAddressID: domain.IPAddressID(claim.IpAddressID.String()),
+		IP:          ip,
+		SubnetID:    subnetID,
+		ClaimedAt:   claim.ClaimedAt.Time,
+	}
+}
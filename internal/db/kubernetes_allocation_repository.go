@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type KubernetesAllocationRepository struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+var _ domain.KubernetesAllocationRepository = (*KubernetesAllocationRepository)(nil)
+
+func NewKubernetesAllocationRepository(pool *pgxpool.Pool) *KubernetesAllocationRepository {
+	return &KubernetesAllocationRepository{pool: pool, queries: sqlc.New(pool)}
+}
+
+// Allocate serializes allocations in a subnet with an advisory lock. An
+// existing allocation in another subnet, left from an earlier setting, is
+// released first. A manual address created concurrently can still take the
+// chosen address; the insert then fails with ErrConflict and the caller
+// retries.
+func (r *KubernetesAllocationRepository) Allocate(ctx context.Context, input domain.AllocateLoadBalancerIPInput) (domain.KubernetesLoadBalancerAllocation, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return domain.KubernetesLoadBalancerAllocation{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := sqlc.New(tx)
+	if err = queries.LockSubnetAllocations(ctx, input.SubnetID); err != nil {
+		return domain.KubernetesLoadBalancerAllocation{}, err
+	}
+	existing, err := queries.GetKubernetesAllocation(ctx, sqlc.GetKubernetesAllocationParams{
+		ControllerKey: input.Controller,
+		ServiceUid:    input.ServiceUID,
+	})
+	switch {
+	case err == nil && existing.SubnetID == input.SubnetID:
+		return toDomainKubernetesAllocation(sqlc.KubernetesLoadBalancerAllocation{
+			IpAddressID: existing.IpAddressID, ControllerKey: existing.ControllerKey, ServiceUid: existing.ServiceUid,
+			Namespace: existing.Namespace, Name: existing.Name, AllocatedAt: existing.AllocatedAt,
+		}, existing.Ip, existing.SubnetID), nil
+	case err == nil:
+		if _, err = queries.DeleteKubernetesAllocationAddress(ctx, sqlc.DeleteKubernetesAllocationAddressParams{
+			ControllerKey: input.Controller,
+			ServiceUid:    input.ServiceUID,
+		}); err != nil {
+			return domain.KubernetesLoadBalancerAllocation{}, err
+		}
+	case !isNoRows(err):
+		return domain.KubernetesLoadBalancerAllocation{}, err
+	}
+
+	ip, err := recordFreeAddress(ctx, queries, input.SubnetID, input.Preferred, "")
+	if err != nil {
+		return domain.KubernetesLoadBalancerAllocation{}, err
+	}
+	allocation, err := queries.CreateKubernetesAllocation(ctx, sqlc.CreateKubernetesAllocationParams{
+		IpAddressID:   ip.ID,
+		ControllerKey: input.Controller,
+		ServiceUid:    input.ServiceUID,
+		Namespace:     input.Namespace,
+		Name:          input.Name,
+	})
+	if err != nil {
+		return domain.KubernetesLoadBalancerAllocation{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return domain.KubernetesLoadBalancerAllocation{}, err
+	}
+	return toDomainKubernetesAllocation(allocation, ip.Ip, ip.SubnetID), nil
+}
+
+// Release deletes the Service's address record, and with it the allocation.
+func (r *KubernetesAllocationRepository) Release(ctx context.Context, controller, serviceUID string) (bool, error) {
+	deleted, err := r.queries.DeleteKubernetesAllocationAddress(ctx, sqlc.DeleteKubernetesAllocationAddressParams{
+		ControllerKey: controller,
+		ServiceUid:    serviceUID,
+	})
+	return deleted > 0, err
+}
+
+// ReleaseExcept compares listedAt with allocated_at, which the database sets,
+// so replica clocks must stay close to the database's.
+func (r *KubernetesAllocationRepository) ReleaseExcept(ctx context.Context, controller string, serviceUIDs []string, listedAt time.Time) (int64, error) {
+	return r.queries.DeleteKubernetesAllocationAddressesExcept(ctx, sqlc.DeleteKubernetesAllocationAddressesExceptParams{
+		ControllerKey: controller,
+		ServiceUids:   serviceUIDs,
+		ListedAt:      timestamp(listedAt),
+	})
+}
+
+// recordFreeAddress creates an address record for a free address of the
+// subnet, preferred when possible. The caller holds the subnet's allocation
+// lock; a manual address created concurrently still makes the insert fail
+// with ErrConflict.
+func recordFreeAddress(ctx context.Context, queries *sqlc.Queries, subnetID int64, preferred netip.Addr, hostname string) (sqlc.IpAddress, error) {
+	row, err := queries.GetSubnetByID(ctx, subnetID)
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.IpAddress{}, fmt.Errorf("%w: %w", domain.ErrNotFound, domain.ErrSubnetNotFound)
+		}
+		return sqlc.IpAddress{}, err
+	}
+	subnet, err := withDHCPSettings(domain.Subnet{ID: row.ID, CIDR: row.Cidr}, row.Gateway, row.DnsServers, row.DhcpPools)
+	if err != nil {
+		return sqlc.IpAddress{}, err
+	}
+	ips, err := queries.ListIPsBySubnetID(ctx, subnetID)
+	if err != nil {
+		return sqlc.IpAddress{}, err
+	}
+	used := make(map[netip.Addr]bool, len(ips))
+	for _, ip := range ips {
+		used[ip.Ip] = true
+	}
+	address, ok := domain.FreeAddress(subnet, used, preferred)
+	if !ok {
+		return sqlc.IpAddress{}, fmt.Errorf("%w: %s", domain.ErrSubnetFull, subnet.CIDR)
+	}
+	ip, err := queries.CreateIPAddress(ctx, sqlc.CreateIPAddressParams{Ip: address, Hostname: hostname, SubnetID: subnetID})
+	if err != nil {
+		if isUniqueIPViolation(err) {
+			return sqlc.IpAddress{}, domain.ErrConflict
+		}
+		return sqlc.IpAddress{}, err
+	}
+	return ip, nil
+}
+
+func toDomainKubernetesAllocation(allocation sqlc.KubernetesLoadBalancerAllocation, ip netip.Addr, subnetID int64) domain.KubernetesLoadBalancerAllocation {
+	return domain.KubernetesLoadBalancerAllocation{
+		Controller:  allocation.ControllerKey,
+		ServiceUID:  allocation.ServiceUid,
+		Namespace:   allocation.Namespace,
+		Name:        allocation.Name,
+		IPAddressID: domain.IPAddressID(allocation.IpAddressID.String()),
+		IP:          ip,
+		SubnetID:    subnetID,
+		AllocatedAt: allocation.AllocatedAt.Time,
+	}
+}
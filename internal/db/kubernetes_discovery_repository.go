@@ -2,7 +2,10 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/netip"
+	"slices"
 	"time"
 
 	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
@@ -21,7 +24,7 @@ func NewKubernetesDiscoveryRepository(pool *pgxpool.Pool) *KubernetesDiscoveryRe
 	return &KubernetesDiscoveryRepository{pool: pool, queries: sqlc.New(pool)}
 }
 
-func (r *KubernetesDiscoveryRepository) Reconcile(ctx context.Context, source domain.KubernetesSourceConfig, services []domain.KubernetesServiceSnapshot, observedAt time.Time) (result domain.KubernetesReconcileResult, err error) {
+func (r *KubernetesDiscoveryRepository) Reconcile(ctx context.Context, source domain.KubernetesSourceConfig, services []domain.KubernetesServiceSnapshot, startedAt, observedAt time.Time) (result domain.KubernetesReconcileResult, err error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return result, err
@@ -37,67 +40,218 @@ func (r *KubernetesDiscoveryRepository) Reconcile(ctx context.Context, source do
 		return result, domain.ErrDiscoveryBusy
 	}
 
-	sourceRow, err := upsertKubernetesSource(ctx, queries, source)
+	sourceRow, err := reconciledKubernetesSource(ctx, queries, source)
 	if err != nil {
 		return result, err
 	}
 
 	uids := make([]string, 0, len(services))
+	var events []domain.KubernetesDiscoveryEvent
 	for _, snapshot := range services {
-		if snapshot.UID == "" {
-			return result, fmt.Errorf("%w: kubernetes service UID is required", domain.ErrInvalidInput)
+		if err = upsertKubernetesService(ctx, queries, source, sourceRow.ID, snapshot, observedAt, &result, &events); err != nil {
+			return result, err
+		}
+		uids = append(uids, snapshot.UID)
+	}
+
+	result.Services = len(services)
+	staleRows, err := queries.MarkMissingKubernetesServicesInactive(ctx, sqlc.MarkMissingKubernetesServicesInactiveParams{
+		SourceID: sourceRow.ID,
+		Column2:  uids,
+		StaleAt:  timestamp(observedAt),
+	})
+	if err != nil {
+		return result, err
+	}
+	for _, row := range staleRows {
+		events = append(events, staleKubernetesServiceEvent(row.KubernetesUid, row.Namespace, row.Name, row.Addresses, observedAt))
+	}
+	purgedRows, err := queries.DeleteStaleKubernetesServices(ctx, sqlc.DeleteStaleKubernetesServicesParams{
+		SourceID: sourceRow.ID, StaleAt: timestamp(observedAt.Add(-source.StaleRetention)),
+	})
+	if err != nil {
+		return result, err
+	}
+	for _, row := range purgedRows {
+		events = append(events, domain.KubernetesDiscoveryEvent{
+			ServiceUID: row.KubernetesUid, Namespace: row.Namespace, Name: row.Name,
+			Change: domain.KubernetesServicePurged, OccurredAt: observedAt,
+		})
+	}
+	// Registered addresses go with their Service, or with the address it
+	// reported, whatever the current policy.
+	if _, err = queries.DeleteOrphanedKubernetesRegisteredAddresses(ctx, sourceRow.ID); err != nil {
+		return result, err
+	}
+	if err = queries.RecordKubernetesSourceSuccess(ctx, sqlc.RecordKubernetesSourceSuccessParams{
+		ID:              sourceRow.ID,
+		LastAttemptAt:   timestamp(observedAt),
+		ServiceCount:    int32(result.Services),
+		MatchedCount:    int32(result.Matched),
+		UnmatchedCount:  int32(result.Unmatched),
+		AmbiguousCount:  int32(result.Ambiguous),
+		NoUsableIpCount: int32(result.NoUsableIP),
+	}); err != nil {
+		return result, err
+	}
+	if err = recordKubernetesRun(ctx, queries, source, sourceRow.ID, startedAt, result, "", events); err != nil {
+		return result, err
+	}
+	event, err := withKubernetesCycleOutcome(ctx, kubernetesChangeEvent(domain.EventKubernetesReconciled, source, observedAt), result, "")
+	if err != nil {
+		return result, err
+	}
+	if err = notifyChangeEvent(ctx, queries, event); err != nil {
+		return result, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *KubernetesDiscoveryRepository) ApplyChanges(ctx context.Context, source domain.KubernetesSourceConfig, changes domain.KubernetesServiceChanges, observedAt time.Time) (result domain.KubernetesReconcileResult, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := sqlc.New(tx)
+	locked, err := queries.TryKubernetesSourceLock(ctx, source.Key)
+	if err != nil {
+		return result, err
+	}
+	if !locked {
+		return result, domain.ErrDiscoveryBusy
+	}
+	sourceRow, err := reconciledKubernetesSource(ctx, queries, source)
+	if err != nil {
+		return result, err
+	}
+
+	// The per-change counts are discarded: the source status reports every
+	// active Service, which is recounted below.
+	var changed domain.KubernetesReconcileResult
+	var events []domain.KubernetesDiscoveryEvent
+	for _, snapshot := range changes.Upserted {
+		if err = upsertKubernetesService(ctx, queries, source, sourceRow.ID, snapshot, observedAt, &changed, &events); err != nil {
+			return result, err
+		}
+	}
+	if len(changes.DeletedUIDs) > 0 {
+		staleRows, markErr := queries.MarkKubernetesServicesInactive(ctx, sqlc.MarkKubernetesServicesInactiveParams{
+			SourceID: sourceRow.ID, Column2: changes.DeletedUIDs, StaleAt: timestamp(observedAt),
+		})
+		if markErr != nil {
+			return result, markErr
 		}
-		serviceRow, upsertErr := queries.UpsertKubernetesService(ctx, sqlc.UpsertKubernetesServiceParams{
+		for _, row := range staleRows {
+			events = append(events, staleKubernetesServiceEvent(row.KubernetesUid, row.Namespace, row.Name, row.Addresses, observedAt))
+		}
+	}
+	if _, err = queries.DeleteOrphanedKubernetesRegisteredAddresses(ctx, sourceRow.ID); err != nil {
+		return result, err
+	}
+	counts, err := queries.CountKubernetesSourceObservations(ctx, sourceRow.ID)
+	if err != nil {
+		return result, err
+	}
+	result = domain.KubernetesReconcileResult{
+		Services: int(counts.Services), Matched: int(counts.Matched), Unmatched: int(counts.Unmatched),
+		Ambiguous: int(counts.Ambiguous), NoUsableIP: int(counts.NoUsableIp),
+	}
+	if err = queries.RecordKubernetesSourceSuccess(ctx, sqlc.RecordKubernetesSourceSuccessParams{
+		ID:              sourceRow.ID,
+		LastAttemptAt:   timestamp(observedAt),
+		ServiceCount:    counts.Services,
+		MatchedCount:    counts.Matched,
+		UnmatchedCount:  counts.Unmatched,
+		AmbiguousCount:  counts.Ambiguous,
+		NoUsableIpCount: counts.NoUsableIp,
+	}); err != nil {
+		return result, err
+	}
+	// Informer batches are frequent, so only those that changed a Service in
+	// the run history are recorded as runs.
+	if len(events) > 0 {
+		if err = recordKubernetesRun(ctx, queries, source, sourceRow.ID, observedAt, result, "", events); err != nil {
+			return result, err
+		}
+	}
+	if err = notifyChangeEvent(ctx, queries, kubernetesChangeEvent(domain.EventKubernetesReconciled, source, observedAt)); err != nil {
+		return result, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ReconcileObjects sends no change notification of its own: runners publish
+// objects just before the Services, whose Reconcile sends it.
+func (r *KubernetesDiscoveryRepository) ReconcileObjects(ctx context.Context, source domain.KubernetesSourceConfig, objects []domain.KubernetesObjectSnapshot, observedAt time.Time) (result domain.KubernetesObjectReconcileResult, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := sqlc.New(tx)
+	locked, err := queries.TryKubernetesSourceLock(ctx, source.Key)
+	if err != nil {
+		return result, err
+	}
+	if !locked {
+		return result, domain.ErrDiscoveryBusy
+	}
+	sourceRow, err := reconciledKubernetesSource(ctx, queries, source)
+	if err != nil {
+		return result, err
+	}
+
+	uids := make([]string, 0, len(objects))
+	for _, object := range objects {
+		if object.UID == "" {
+			return result, fmt.Errorf("%w: kubernetes object UID is required", domain.ErrInvalidInput)
+		}
+		objectRow, upsertErr := queries.UpsertKubernetesObject(ctx, sqlc.UpsertKubernetesObjectParams{
 			SourceID:        sourceRow.ID,
-			KubernetesUid:   snapshot.UID,
-			Namespace:       snapshot.Namespace,
-			Name:            snapshot.Name,
-			ServiceType:     snapshot.Type,
-			ResourceVersion: snapshot.ResourceVersion,
-			ExternalName:    snapshot.ExternalName,
-			DnsName:         snapshot.DNSName,
+			Kind:            object.Kind,
+			KubernetesUid:   object.UID,
+			Namespace:       object.Namespace,
+			Name:            object.Name,
+			HostNetwork:     object.HostNetwork,
+			ResourceVersion: object.ResourceVersion,
 			ObservedAt:      timestamp(observedAt),
 		})
 		if upsertErr != nil {
 			return result, upsertErr
 		}
-		uids = append(uids, snapshot.UID)
-		if len(snapshot.Addresses) == 0 {
-			result.NoUsableIP++
-		}
-
-		if err = replaceKubernetesServicePorts(ctx, queries, serviceRow.ID, snapshot.Ports); err != nil {
+		if err = replaceKubernetesObjectAddresses(ctx, queries, source.SiteID, objectRow.ID, object.Addresses, &result); err != nil {
 			return result, err
 		}
-		if err = replaceKubernetesServiceAddresses(ctx, queries, source.SiteID, serviceRow.ID, snapshot.Addresses, &result); err != nil {
-			return result, err
-		}
-		if err = replaceKubernetesServiceHostnames(ctx, queries, serviceRow.ID, snapshot.Hostnames); err != nil {
+		if err = replaceKubernetesObjectHostnames(ctx, queries, objectRow.ID, object.Hostnames); err != nil {
 			return result, err
 		}
+		uids = append(uids, object.UID)
 	}
 
-	result.Services = len(services)
-	if err = queries.MarkMissingKubernetesServicesInactive(ctx, sqlc.MarkMissingKubernetesServicesInactiveParams{
+	result.Objects = len(objects)
+	if err = queries.MarkMissingKubernetesObjectsInactive(ctx, sqlc.MarkMissingKubernetesObjectsInactiveParams{
 		SourceID: sourceRow.ID,
 		Column2:  uids,
 		StaleAt:  timestamp(observedAt),
 	}); err != nil {
 		return result, err
 	}
-	if err = queries.DeleteStaleKubernetesServices(ctx, sqlc.DeleteStaleKubernetesServicesParams{
+	if err = queries.DeleteStaleKubernetesObjects(ctx, sqlc.DeleteStaleKubernetesObjectsParams{
 		SourceID: sourceRow.ID, StaleAt: timestamp(observedAt.Add(-source.StaleRetention)),
 	}); err != nil {
 		return result, err
 	}
-	if err = queries.RecordKubernetesSourceSuccess(ctx, sqlc.RecordKubernetesSourceSuccessParams{
-		ID:              sourceRow.ID,
-		LastAttemptAt:   timestamp(observedAt),
-		ServiceCount:    int32(result.Services),
-		MatchedCount:    int32(result.Matched),
-		UnmatchedCount:  int32(result.Unmatched),
-		AmbiguousCount:  int32(result.Ambiguous),
-		NoUsableIpCount: int32(result.NoUsableIP),
+	if err = queries.RecordKubernetesObjectCount(ctx, sqlc.RecordKubernetesObjectCountParams{
+		ID: sourceRow.ID, ObjectCount: int32(result.Objects),
 	}); err != nil {
 		return result, err
 	}
@@ -121,11 +275,17 @@ func (r *KubernetesDiscoveryRepository) RecordFailure(ctx context.Context, sourc
 	if !locked {
 		return domain.ErrDiscoveryBusy
 	}
-	if err = ensureKubernetesSource(ctx, queries, source); err != nil {
-		return err
+	// A deleted managed source has nothing left to record the failure on.
+	if !source.Managed {
+		if err = ensureKubernetesSource(ctx, queries, source); err != nil {
+			return err
+		}
 	}
 	sourceRow, err := queries.GetKubernetesSourceByKey(ctx, source.Key)
 	if err != nil {
+		if source.Managed && isNoRows(err) {
+			return nil
+		}
 		return err
 	}
 	if err = queries.RecordKubernetesSourceFailure(ctx, sqlc.RecordKubernetesSourceFailureParams{
@@ -133,9 +293,44 @@ func (r *KubernetesDiscoveryRepository) RecordFailure(ctx context.Context, sourc
 	}); err != nil {
 		return err
 	}
+	if err = recordKubernetesRun(ctx, queries, source, sourceRow.ID, attemptedAt, domain.KubernetesReconcileResult{}, message, nil); err != nil {
+		return err
+	}
+	event, err := withKubernetesCycleOutcome(ctx, kubernetesChangeEvent(domain.EventKubernetesReconcileFailed, source, attemptedAt), domain.KubernetesReconcileResult{}, message)
+	if err != nil {
+		return err
+	}
+	if err = notifyChangeEvent(ctx, queries, event); err != nil {
+		return err
+	}
 	return tx.Commit(ctx)
 }
 
+// RecordObjectFailure needs no source lock: it only writes the object error,
+// which a concurrent ReconcileObjects may clear.
+func (r *KubernetesDiscoveryRepository) RecordObjectFailure(ctx context.Context, source domain.KubernetesSourceConfig, message string) error {
+	return r.queries.RecordKubernetesObjectFailure(ctx, sqlc.RecordKubernetesObjectFailureParams{SourceKey: source.Key, ObjectError: message})
+}
+
+// RequestReconcile notifies every replica that a cycle of the source was
+// requested. Nothing is stored; a replica without the source's runner
+// ignores the notification.
+func (r *KubernetesDiscoveryRepository) RequestReconcile(ctx context.Context, key, requestID string, requestedAt time.Time) error {
+	sourceRow, err := r.queries.GetKubernetesSourceByKey(ctx, key)
+	if err != nil {
+		if isNoRows(err) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	source := domain.KubernetesSourceConfig{Key: key, SiteID: pgUUIDToUUID(sourceRow.SiteID)}
+	event := kubernetesChangeEvent(domain.EventKubernetesReconcileRequested, source, requestedAt)
+	if event.Payload, err = json.Marshal(domain.KubernetesCycleRequest{RequestID: requestID}); err != nil {
+		return err
+	}
+	return notifyChangeEvent(ctx, r.queries, event)
+}
+
 func (r *KubernetesDiscoveryRepository) ListSourceStatuses(ctx context.Context) ([]domain.KubernetesSourceStatus, error) {
 	rows, err := r.queries.ListKubernetesSourceStatuses(ctx)
 	if err != nil {
@@ -143,31 +338,73 @@ func (r *KubernetesDiscoveryRepository) ListSourceStatuses(ctx context.Context)
 	}
 	statuses := make([]domain.KubernetesSourceStatus, 0, len(rows))
 	for _, row := range rows {
-		state := "pending"
-		if row.LastError != "" {
-			state = "degraded"
-		} else if row.LastSuccessAt.Valid {
-			state = "healthy"
-		}
-		statuses = append(statuses, domain.KubernetesSourceStatus{
-			Source:        domain.KubernetesSource{Key: row.SourceKey, Name: row.Name},
-			SiteID:        uuid.UUID(row.SiteID.Bytes),
-			ClusterDomain: row.ClusterDomain,
-			Namespaces:    append([]string(nil), row.NamespaceScope...),
-			State:         state,
-			LastAttemptAt: optionalTime(row.LastAttemptAt),
-			LastSuccessAt: optionalTime(row.LastSuccessAt),
-			LastError:     row.LastError,
-			Services:      int(row.ServiceCount),
-			Matched:       int(row.MatchedCount),
-			Unmatched:     int(row.UnmatchedCount),
-			Ambiguous:     int(row.AmbiguousCount),
-			NoUsableIP:    int(row.NoUsableIpCount),
-		})
+		statuses = append(statuses, toDomainKubernetesSourceStatus(row))
 	}
 	return statuses, nil
 }
 
+func (r *KubernetesDiscoveryRepository) ListRuns(ctx context.Context, key string, limit int32) ([]domain.KubernetesDiscoveryRun, error) {
+	sourceRow, err := r.queries.GetKubernetesSourceByKey(ctx, key)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	rows, err := r.queries.ListKubernetesDiscoveryRuns(ctx, sqlc.ListKubernetesDiscoveryRunsParams{SourceID: sourceRow.ID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]domain.KubernetesDiscoveryRun, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, domain.KubernetesDiscoveryRun{
+			ID:         row.ID,
+			StartedAt:  row.StartedAt.Time.UTC(),
+			FinishedAt: row.FinishedAt.Time.UTC(),
+			Result: domain.KubernetesReconcileResult{
+				Services: int(row.ServiceCount), Matched: int(row.MatchedCount), Unmatched: int(row.UnmatchedCount),
+				Ambiguous: int(row.AmbiguousCount), NoUsableIP: int(row.NoUsableIpCount),
+			},
+			Error:   row.Error,
+			Changes: int(row.ChangeCount),
+		})
+	}
+	return runs, nil
+}
+
+func (r *KubernetesDiscoveryRepository) ListEvents(ctx context.Context, key string, runID *int64, limit int32) ([]domain.KubernetesDiscoveryEvent, error) {
+	sourceRow, err := r.queries.GetKubernetesSourceByKey(ctx, key)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	params := sqlc.ListKubernetesDiscoveryEventsParams{SourceID: sourceRow.ID, Limit: limit}
+	if runID != nil {
+		params.RunID = pgtype.Int8{Int64: *runID, Valid: true}
+	}
+	rows, err := r.queries.ListKubernetesDiscoveryEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]domain.KubernetesDiscoveryEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, domain.KubernetesDiscoveryEvent{
+			ID:                row.ID,
+			RunID:             row.RunID,
+			ServiceUID:        row.ServiceUid,
+			Namespace:         row.Namespace,
+			Name:              row.Name,
+			Change:            row.Change,
+			PreviousAddresses: historyAddresses(row.PreviousAddresses),
+			Addresses:         historyAddresses(row.Addresses),
+			OccurredAt:        row.OccurredAt.Time.UTC(),
+		})
+	}
+	return events, nil
+}
+
 func (r *KubernetesDiscoveryRepository) ListServicesBySubnetID(ctx context.Context, subnetID int64) (map[domain.IPAddressID][]domain.KubernetesServiceEnrichment, error) {
 	rows, err := r.queries.ListMatchedKubernetesServicesBySubnet(ctx, subnetID)
 	if err != nil {
@@ -311,17 +548,247 @@ func (r *KubernetesDiscoveryRepository) ListAllServicesBySubnetID(ctx context.Co
 	return services, nil
 }
 
-func upsertKubernetesSource(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig) (sqlc.KubernetesSource, error) {
-	return queries.UpsertKubernetesSource(ctx, sqlc.UpsertKubernetesSourceParams{
+func (r *KubernetesDiscoveryRepository) ListObjectsBySubnetID(ctx context.Context, subnetID int64) (map[domain.IPAddressID][]domain.KubernetesObjectEnrichment, error) {
+	rows, err := r.queries.ListMatchedKubernetesObjectsBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	hostnames, err := r.objectHostnamesBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[domain.IPAddressID][]domain.KubernetesObjectEnrichment)
+	for _, row := range rows {
+		ipID := domain.IPAddressID(uuid.UUID(row.IpAddressID.Bytes).String())
+		result[ipID] = append(result[ipID], domain.KubernetesObjectEnrichment{
+			Source:      domain.KubernetesSource{Key: row.SourceKey, Name: row.SourceName},
+			Kind:        row.Kind,
+			UID:         row.KubernetesUid,
+			Namespace:   row.Namespace,
+			Name:        row.Name,
+			HostNetwork: row.HostNetwork,
+			AddressKind: row.AddressKind,
+			NodeName:    row.NodeName,
+			Hostnames:   objectHostnames(hostnames, row.ObjectID),
+			ObservedAt:  row.ObservedAt.Time,
+		})
+	}
+	return result, nil
+}
+
+func (r *KubernetesDiscoveryRepository) ListAllObjectsBySubnetID(ctx context.Context, subnetID int64) ([]domain.KubernetesObjectObservation, error) {
+	rows, err := r.queries.ListKubernetesObjectAddressesBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	hostnames, err := r.objectHostnamesBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]domain.KubernetesObjectObservation, 0)
+	indexes := make(map[string]int)
+	for _, row := range rows {
+		objectKey := row.SourceKey + "\x00" + row.KubernetesUid
+		index, ok := indexes[objectKey]
+		if !ok {
+			index = len(objects)
+			indexes[objectKey] = index
+			objects = append(objects, domain.KubernetesObjectObservation{
+				Source:      domain.KubernetesSource{Key: row.SourceKey, Name: row.SourceName},
+				Kind:        row.Kind,
+				UID:         row.KubernetesUid,
+				Namespace:   row.Namespace,
+				Name:        row.Name,
+				HostNetwork: row.HostNetwork,
+				Addresses:   make([]domain.KubernetesObjectAddressObservation, 0),
+				Hostnames:   objectHostnames(hostnames, row.ObjectID),
+				ObservedAt:  row.ObservedAt.Time,
+			})
+		}
+		address := domain.KubernetesObjectAddressObservation{
+			IP:                   row.Address,
+			Kind:                 row.AddressKind,
+			NodeName:             row.NodeName,
+			MatchStatus:          domain.KubernetesMatchStatus(row.MatchStatus),
+			MatchCount:           int(row.MatchCount),
+			LoadBalancerServices: row.LoadBalancerServices,
+		}
+		if address.LoadBalancerServices == nil {
+			address.LoadBalancerServices = make([]string, 0)
+		}
+		if row.IpAddressID.Valid {
+			id := domain.IPAddressID(uuid.UUID(row.IpAddressID.Bytes).String())
+			address.MatchedIPAddressID = &id
+			if row.MatchedSubnetID.Valid {
+				subnetID := row.MatchedSubnetID.Int64
+				address.MatchedSubnetID = &subnetID
+			}
+		}
+		objects[index].Addresses = append(objects[index].Addresses, address)
+	}
+	return objects, nil
+}
+
+// objectHostnamesBySubnet returns the hostnames of the active objects whose
+// source is bound to the subnet's site, by object ID.
+func (r *KubernetesDiscoveryRepository) objectHostnamesBySubnet(ctx context.Context, subnetID int64) (map[pgtype.UUID][]domain.KubernetesObjectHostname, error) {
+	rows, err := r.queries.ListKubernetesObjectHostnamesBySubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	hostnames := make(map[pgtype.UUID][]domain.KubernetesObjectHostname)
+	for _, row := range rows {
+		hostnames[row.ObjectID] = append(hostnames[row.ObjectID], domain.KubernetesObjectHostname{Kind: row.Kind, Hostname: row.Hostname})
+	}
+	return hostnames, nil
+}
+
+func objectHostnames(hostnames map[pgtype.UUID][]domain.KubernetesObjectHostname, objectID pgtype.UUID) []domain.KubernetesObjectHostname {
+	return append(make([]domain.KubernetesObjectHostname, 0, len(hostnames[objectID])), hostnames[objectID]...)
+}
+
+// reconciledKubernetesSource returns the row a snapshot is published to.
+// Configured sources write their settings on every cycle, but never over a
+// source managed through the API; managed sources are only read.
+func reconciledKubernetesSource(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig) (sqlc.KubernetesSource, error) {
+	if source.Managed {
+		row, err := queries.GetKubernetesSourceByKey(ctx, source.Key)
+		if isNoRows(err) || err == nil && !row.Managed {
+			return row, fmt.Errorf("%w: kubernetes source %q is no longer managed through the API", domain.ErrNotFound, source.Key)
+		}
+		return row, err
+	}
+	row, err := queries.UpsertKubernetesSource(ctx, sqlc.UpsertKubernetesSourceParams{
 		SourceKey: source.Key, Name: source.Name, SiteID: siteIDParam(&source.SiteID),
 		ClusterDomain: source.ClusterDomain, Column5: source.Namespaces,
+		Column6: objectKindsParam(source.ObjectKinds), PodSelector: source.PodSelector,
+		AutoRegister: autoRegisterParam(source.AutoRegister),
+	})
+	if isNoRows(err) {
+		return row, fmt.Errorf("%w: kubernetes source %q is managed through the API; remove it from the configuration", domain.ErrConflict, source.Key)
+	}
+	return row, err
+}
+
+// upsertKubernetesService writes one Service with its ports, addresses and
+// hostnames, replacing what was stored for its UID, and appends the change
+// it makes to events: a Service that was not active appears, and an active
+// one whose set of addresses differs changes address.
+func upsertKubernetesService(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig, sourceID pgtype.UUID, snapshot domain.KubernetesServiceSnapshot, observedAt time.Time, result *domain.KubernetesReconcileResult, events *[]domain.KubernetesDiscoveryEvent) error {
+	if snapshot.UID == "" {
+		return fmt.Errorf("%w: kubernetes service UID is required", domain.ErrInvalidInput)
+	}
+	previous, err := queries.GetKubernetesServiceHistoryState(ctx, sqlc.GetKubernetesServiceHistoryStateParams{
+		SourceID: sourceID, KubernetesUid: snapshot.UID,
+	})
+	if err != nil && !isNoRows(err) {
+		return err
+	}
+	addresses := snapshotAddresses(snapshot.Addresses)
+	event := domain.KubernetesDiscoveryEvent{
+		ServiceUID: snapshot.UID, Namespace: snapshot.Namespace, Name: snapshot.Name,
+		PreviousAddresses: make([]netip.Addr, 0), Addresses: addresses, OccurredAt: observedAt,
+	}
+	switch previousAddresses := historyAddresses(previous.Addresses); {
+	case !previous.Active:
+		event.Change = domain.KubernetesServiceAppeared
+		*events = append(*events, event)
+	case !slices.Equal(previousAddresses, addresses):
+		event.Change = domain.KubernetesServiceAddressChanged
+		event.PreviousAddresses = previousAddresses
+		*events = append(*events, event)
+	}
+	serviceRow, err := queries.UpsertKubernetesService(ctx, sqlc.UpsertKubernetesServiceParams{
+		SourceID:        sourceID,
+		KubernetesUid:   snapshot.UID,
+		Namespace:       snapshot.Namespace,
+		Name:            snapshot.Name,
+		ServiceType:     snapshot.Type,
+		ResourceVersion: snapshot.ResourceVersion,
+		ExternalName:    snapshot.ExternalName,
+		DnsName:         snapshot.DNSName,
+		ObservedAt:      timestamp(observedAt),
+	})
+	if err != nil {
+		return err
+	}
+	if len(snapshot.Addresses) == 0 {
+		result.NoUsableIP++
+	}
+	if err := replaceKubernetesServicePorts(ctx, queries, serviceRow.ID, snapshot.Ports); err != nil {
+		return err
+	}
+	if err := replaceKubernetesServiceAddresses(ctx, queries, source, sourceID, serviceRow.ID, snapshot, result); err != nil {
+		return err
+	}
+	return replaceKubernetesServiceHostnames(ctx, queries, serviceRow.ID, snapshot.Hostnames)
+}
+
+// recordKubernetesRun stores a run with the Service changes it made and
+// deletes the source's runs that started longer than its stale retention
+// before it.
+func recordKubernetesRun(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig, sourceID pgtype.UUID, startedAt time.Time, result domain.KubernetesReconcileResult, message string, events []domain.KubernetesDiscoveryEvent) error {
+	runID, err := queries.CreateKubernetesDiscoveryRun(ctx, sqlc.CreateKubernetesDiscoveryRunParams{
+		SourceID:        sourceID,
+		StartedAt:       timestamp(startedAt),
+		ServiceCount:    int32(result.Services),
+		MatchedCount:    int32(result.Matched),
+		UnmatchedCount:  int32(result.Unmatched),
+		AmbiguousCount:  int32(result.Ambiguous),
+		NoUsableIpCount: int32(result.NoUsableIP),
+		Error:           message,
+	})
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := queries.CreateKubernetesDiscoveryEvent(ctx, sqlc.CreateKubernetesDiscoveryEventParams{
+			RunID: runID, SourceID: sourceID, ServiceUid: event.ServiceUID,
+			Namespace: event.Namespace, Name: event.Name, Change: event.Change,
+			OccurredAt: timestamp(event.OccurredAt), PreviousAddresses: historyAddresses(event.PreviousAddresses),
+			Addresses: historyAddresses(event.Addresses),
+		}); err != nil {
+			return err
+		}
+	}
+	return queries.DeleteExpiredKubernetesDiscoveryRuns(ctx, sqlc.DeleteExpiredKubernetesDiscoveryRunsParams{
+		SourceID: sourceID, StartedAt: timestamp(startedAt.Add(-source.StaleRetention)),
 	})
 }
 
+func staleKubernetesServiceEvent(uid, namespace, name string, addresses []netip.Addr, observedAt time.Time) domain.KubernetesDiscoveryEvent {
+	return domain.KubernetesDiscoveryEvent{
+		ServiceUID: uid, Namespace: namespace, Name: name, Change: domain.KubernetesServiceStale,
+		PreviousAddresses: historyAddresses(addresses), Addresses: make([]netip.Addr, 0), OccurredAt: observedAt,
+	}
+}
+
+// snapshotAddresses returns the distinct addresses of a Service in the order
+// the history stores them.
+func snapshotAddresses(addresses []domain.KubernetesServiceAddress) []netip.Addr {
+	result := make([]netip.Addr, 0, len(addresses))
+	for _, address := range addresses {
+		result = append(result, address.Address)
+	}
+	slices.SortFunc(result, netip.Addr.Compare)
+	return slices.Compact(result)
+}
+
+// historyAddresses stores and reports a Service without addresses as an
+// empty array, never NULL.
+func historyAddresses(addresses []netip.Addr) []netip.Addr {
+	if addresses == nil {
+		return make([]netip.Addr, 0)
+	}
+	return addresses
+}
+
 func ensureKubernetesSource(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig) error {
 	return queries.EnsureKubernetesSource(ctx, sqlc.EnsureKubernetesSourceParams{
 		SourceKey: source.Key, Name: source.Name, SiteID: siteIDParam(&source.SiteID),
 		ClusterDomain: source.ClusterDomain, Column5: source.Namespaces,
+		Column6: objectKindsParam(source.ObjectKinds), PodSelector: source.PodSelector,
+		AutoRegister: autoRegisterParam(source.AutoRegister),
 	})
 }
 
@@ -344,33 +811,24 @@ func replaceKubernetesServicePorts(ctx context.Context, queries *sqlc.Queries, s
 	return nil
 }
 
-func replaceKubernetesServiceAddresses(ctx context.Context, queries *sqlc.Queries, siteID uuid.UUID, serviceID pgtype.UUID, addresses []domain.KubernetesServiceAddress, result *domain.KubernetesReconcileResult) error {
+func replaceKubernetesServiceAddresses(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig, sourceID, serviceID pgtype.UUID, snapshot domain.KubernetesServiceSnapshot, result *domain.KubernetesReconcileResult) error {
 	if err := queries.DeleteKubernetesServiceAddresses(ctx, serviceID); err != nil {
 		return err
 	}
-	for _, address := range addresses {
-		candidates, err := queries.FindIPCandidatesBySiteAndAddress(ctx, sqlc.FindIPCandidatesBySiteAndAddressParams{
-			SiteID: siteIDParam(&siteID), Column2: address.Address,
-		})
+	for _, address := range snapshot.Addresses {
+		match, err := matchKubernetesAddress(ctx, queries, source.SiteID, address.Address)
 		if err != nil {
 			return err
 		}
-		status := "unmatched"
-		var ipAddressID pgtype.UUID
-		switch len(candidates) {
-		case 0:
-			result.Unmatched++
-		case 1:
-			status = "matched"
-			ipAddressID = candidates[0]
-			result.Matched++
-		default:
-			status = "ambiguous"
-			result.Ambiguous++
+		if match.status == "unmatched" && source.RegistersAddress(address.Kind) {
+			if match, err = registerKubernetesAddress(ctx, queries, source, sourceID, snapshot, address.Address, match); err != nil {
+				return err
+			}
 		}
+		match.count(&result.Matched, &result.Unmatched, &result.Ambiguous)
 		if err := queries.CreateKubernetesServiceAddress(ctx, sqlc.CreateKubernetesServiceAddressParams{
 			ServiceID: serviceID, Kind: address.Kind, Address: address.Address, Column4: address.IPMode,
-			IpAddressID: ipAddressID, MatchStatus: status, MatchCount: int32(len(candidates)),
+			IpAddressID: match.ipAddressID, MatchStatus: match.status, MatchCount: match.candidates,
 		}); err != nil {
 			return err
 		}
@@ -378,6 +836,131 @@ func replaceKubernetesServiceAddresses(ctx context.Context, queries *sqlc.Querie
 	return nil
 }
 
+// registerKubernetesAddress records an unmatched address, named after the
+// Service, in the most specific subnet of the source's site and links it.
+// An address outside every subnet, or a network or broadcast address, stays
+// unmatched.
+func registerKubernetesAddress(ctx context.Context, queries *sqlc.Queries, source domain.KubernetesSourceConfig, sourceID pgtype.UUID, snapshot domain.KubernetesServiceSnapshot, address netip.Addr, match kubernetesAddressMatch) (kubernetesAddressMatch, error) {
+	subnet, err := queries.FindKubernetesRegistrationSubnet(ctx, sqlc.FindKubernetesRegistrationSubnetParams{
+		SiteID: siteIDParam(&source.SiteID), Address: address,
+	})
+	if isNoRows(err) {
+		return match, nil
+	}
+	if err != nil {
+		return match, err
+	}
+	if !domain.UsableAddress(subnet.Cidr, address) {
+		return match, nil
+	}
+	ipAddressID, err := queries.CreateKubernetesRegisteredIP(ctx, sqlc.CreateKubernetesRegisteredIPParams{
+		Ip: address, Hostname: snapshot.DNSName, SubnetID: subnet.ID,
+	})
+	if isNoRows(err) {
+		// Someone recorded the address since it was matched.
+		return matchKubernetesAddress(ctx, queries, source.SiteID, address)
+	}
+	if err != nil {
+		return match, err
+	}
+	if err := queries.CreateKubernetesRegisteredAddress(ctx, sqlc.CreateKubernetesRegisteredAddressParams{
+		IpAddressID: ipAddressID, SourceID: sourceID, ServiceUid: snapshot.UID,
+		Namespace: snapshot.Namespace, Name: snapshot.Name,
+	}); err != nil {
+		return match, err
+	}
+	return kubernetesAddressMatch{status: "matched", ipAddressID: ipAddressID, candidates: 1}, nil
+}
+
+func replaceKubernetesObjectAddresses(ctx context.Context, queries *sqlc.Queries, siteID uuid.UUID, objectID pgtype.UUID, addresses []domain.KubernetesObjectAddress, result *domain.KubernetesObjectReconcileResult) error {
+	if err := queries.DeleteKubernetesObjectAddresses(ctx, objectID); err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		match, err := matchKubernetesAddress(ctx, queries, siteID, address.Address)
+		if err != nil {
+			return err
+		}
+		match.count(&result.Matched, &result.Unmatched, &result.Ambiguous)
+		if err := queries.CreateKubernetesObjectAddress(ctx, sqlc.CreateKubernetesObjectAddressParams{
+			ObjectID: objectID, Kind: address.Kind, Address: address.Address, NodeName: address.NodeName,
+			IpAddressID: match.ipAddressID, MatchStatus: match.status, MatchCount: match.candidates,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replaceKubernetesObjectHostnames(ctx context.Context, queries *sqlc.Queries, objectID pgtype.UUID, hostnames []domain.KubernetesObjectHostname) error {
+	if err := queries.DeleteKubernetesObjectHostnames(ctx, objectID); err != nil {
+		return err
+	}
+	for _, hostname := range hostnames {
+		if err := queries.CreateKubernetesObjectHostname(ctx, sqlc.CreateKubernetesObjectHostnameParams{
+			ObjectID: objectID, Kind: hostname.Kind, Hostname: hostname.Hostname,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kubernetesAddressMatch is an observed address matched against the IP
+// address records of the source's site. Only a single candidate links.
+type kubernetesAddressMatch struct {
+	status      string
+	ipAddressID pgtype.UUID
+	candidates  int32
+}
+
+func matchKubernetesAddress(ctx context.Context, queries *sqlc.Queries, siteID uuid.UUID, address netip.Addr) (kubernetesAddressMatch, error) {
+	candidates, err := queries.FindIPCandidatesBySiteAndAddress(ctx, sqlc.FindIPCandidatesBySiteAndAddressParams{
+		SiteID: siteIDParam(&siteID), Column2: address,
+	})
+	if err != nil {
+		return kubernetesAddressMatch{}, err
+	}
+	match := kubernetesAddressMatch{status: "unmatched", candidates: int32(len(candidates))}
+	switch len(candidates) {
+	case 0:
+	case 1:
+		match.status = "matched"
+		match.ipAddressID = candidates[0]
+	default:
+		match.status = "ambiguous"
+	}
+	return match, nil
+}
+
+func (m kubernetesAddressMatch) count(matched, unmatched, ambiguous *int) {
+	switch m.status {
+	case "matched":
+		*matched++
+	case "ambiguous":
+		*ambiguous++
+	default:
+		*unmatched++
+	}
+}
+
+// objectKindsParam stores a source without object kinds as an empty array,
+// never NULL.
+func objectKindsParam(kinds []string) []string {
+	if kinds == nil {
+		return []string{}
+	}
+	return kinds
+}
+
+// autoRegisterParam stores a source without a policy as off.
+func autoRegisterParam(policy string) string {
+	if policy == "" {
+		return domain.KubernetesAutoRegisterOff
+	}
+	return policy
+}
+
 func replaceKubernetesServiceHostnames(ctx context.Context, queries *sqlc.Queries, serviceID pgtype.UUID, hostnames []domain.KubernetesServiceHostname) error {
 	if err := queries.DeleteKubernetesServiceHostnames(ctx, serviceID); err != nil {
 		return err
@@ -392,10 +975,45 @@ func replaceKubernetesServiceHostnames(ctx context.Context, queries *sqlc.Querie
 	return nil
 }
 
+// kubernetesChangeEvent describes a source status change for live streams.
+// Kubernetes results are not written to the outbox, so the event has no ID.
+func kubernetesChangeEvent(eventType string, source domain.KubernetesSourceConfig, occurredAt time.Time) domain.ChangeEvent {
+	siteID := source.SiteID
+	return domain.ChangeEvent{
+		Type:       eventType,
+		ObjectType: domain.ObjectTypeKubernetes,
+		ObjectID:   source.Key,
+		SiteID:     &siteID,
+		OccurredAt: occurredAt,
+	}
+}
+
+// withKubernetesCycleOutcome attaches the result or error message of the
+// cycle to its notification when ctx carries out a requested cycle.
+func withKubernetesCycleOutcome(ctx context.Context, event domain.ChangeEvent, result domain.KubernetesReconcileResult, message string) (domain.ChangeEvent, error) {
+	requestID := domain.KubernetesCycleRequestID(ctx)
+	if requestID == "" {
+		return event, nil
+	}
+	payload, err := json.Marshal(domain.KubernetesCycleOutcome{RequestID: requestID, Result: result, Error: message})
+	if err != nil {
+		return event, err
+	}
+	event.Payload = payload
+	return event, nil
+}
+
 func timestamp(value time.Time) pgtype.Timestamptz {
 	return pgtype.Timestamptz{Time: value.UTC(), Valid: true}
 }
 
+func optionalTimestamp(value *time.Time) pgtype.Timestamptz {
+	if value == nil {
+		return pgtype.Timestamptz{}
+	}
+	return timestamp(*value)
+}
+
 func optionalTime(value pgtype.Timestamptz) *time.Time {
 	if !value.Valid {
 		return nil
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type KubernetesSourceRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewKubernetesSourceRepository(queries *sqlc.Queries) *KubernetesSourceRepository {
+	return &KubernetesSourceRepository{queries: queries}
+}
+
+func (r *KubernetesSourceRepository) FindSourceByKey(ctx context.Context, key string) (domain.KubernetesSourceRecord, error) {
+	row, err := r.queries.GetKubernetesSourceByKey(ctx, key)
+	if err != nil {
+		if isNoRows(err) {
+			return domain.KubernetesSourceRecord{}, fmt.Errorf("%w: kubernetes source not found", domain.ErrNotFound)
+		}
+		return domain.KubernetesSourceRecord{}, err
+	}
+	return toDomainKubernetesSourceRecord(row), nil
+}
+
+// CreateSource reports a key already managed through the API as
+// domain.ErrConflict.
+func (r *KubernetesSourceRepository) CreateSource(ctx context.Context, source domain.KubernetesSourceRecord) (domain.KubernetesSourceStatus, error) {
+	row, err := r.queries.CreateManagedKubernetesSource(ctx, sqlc.CreateManagedKubernetesSourceParams{
+		SourceKey: source.Key, Name: source.Name, SiteID: siteIDParam(&source.SiteID),
+		ClusterDomain: source.ClusterDomain, Column5: source.Namespaces, AuthMode: source.AuthMode,
+		KubeconfigCiphertext: source.KubeconfigCiphertext, KubeconfigContext: source.KubeconfigContext,
+		ReconcileIntervalSeconds: seconds(source.ReconcileInterval), RequestTimeoutSeconds: seconds(source.RequestTimeout),
+		StaleRetentionSeconds: seconds(source.StaleRetention), Column12: objectKindsParam(source.ObjectKinds), PodSelector: source.PodSelector,
+		AutoRegister: autoRegisterParam(source.AutoRegister),
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return domain.KubernetesSourceStatus{}, fmt.Errorf("%w: kubernetes source %q already exists", domain.ErrConflict, source.Key)
+		}
+		return domain.KubernetesSourceStatus{}, err
+	}
+	return r.notify(ctx, domain.EventKubernetesSourceCreated, row)
+}
+
+func (r *KubernetesSourceRepository) UpdateSource(ctx context.Context, source domain.KubernetesSourceRecord) (domain.KubernetesSourceStatus, error) {
+	row, err := r.queries.UpdateManagedKubernetesSource(ctx, sqlc.UpdateManagedKubernetesSourceParams{
+		SourceKey: source.Key, Name: source.Name, SiteID: siteIDParam(&source.SiteID),
+		ClusterDomain: source.ClusterDomain, Column5: source.Namespaces, AuthMode: source.AuthMode,
+		KubeconfigCiphertext: source.KubeconfigCiphertext, KubeconfigContext: source.KubeconfigContext,
+		ReconcileIntervalSeconds: seconds(source.ReconcileInterval), RequestTimeoutSeconds: seconds(source.RequestTimeout),
+		StaleRetentionSeconds: seconds(source.StaleRetention), Column12: objectKindsParam(source.ObjectKinds), PodSelector: source.PodSelector,
+		AutoRegister: autoRegisterParam(source.AutoRegister),
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return domain.KubernetesSourceStatus{}, fmt.Errorf("%w: kubernetes source not found", domain.ErrNotFound)
+		}
+		return domain.KubernetesSourceStatus{}, err
+	}
+	return r.notify(ctx, domain.EventKubernetesSourceUpdated, row)
+}
+
+func (r *KubernetesSourceRepository) DeleteSource(ctx context.Context, key string) (bool, error) {
+	deleted, err := r.queries.DeleteManagedKubernetesSource(ctx, key)
+	if err != nil || deleted == 0 {
+		return false, err
+	}
+	event := domain.ChangeEvent{
+		Type: domain.EventKubernetesSourceDeleted, ObjectType: domain.ObjectTypeKubernetesSource, ObjectID: key, OccurredAt: time.Now(),
+	}
+	if err := notifyChangeEvent(ctx, r.queries, event); err != nil {
+		return true, fmt.Errorf("notifying kubernetes source listeners: %w", err)
+	}
+	return true, nil
+}
+
+func (r *KubernetesSourceRepository) ListManagedSources(ctx context.Context) ([]domain.KubernetesSourceRecord, error) {
+	rows, err := r.queries.ListManagedKubernetesSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]domain.KubernetesSourceRecord, 0, len(rows))
+	for _, row := range rows {
+		sources = append(sources, toDomainKubernetesSourceRecord(row))
+	}
+	return sources, nil
+}
+
+// notify tells the discovery runners of every instance to reload the
+// source. Like DNS zones, sources are not written to the outbox.
+func (r *KubernetesSourceRepository) notify(ctx context.Context, eventType string, row sqlc.KubernetesSource) (domain.KubernetesSourceStatus, error) {
+	status := toDomainKubernetesSourceStatus(row)
+	siteID := status.SiteID
+	event := domain.ChangeEvent{
+		Type: eventType, ObjectType: domain.ObjectTypeKubernetesSource, ObjectID: row.SourceKey, SiteID: &siteID, OccurredAt: time.Now(),
+	}
+	if err := notifyChangeEvent(ctx, r.queries, event); err != nil {
+		return status, fmt.Errorf("notifying kubernetes source listeners: %w", err)
+	}
+	return status, nil
+}
+
+func toDomainKubernetesSourceRecord(row sqlc.KubernetesSource) domain.KubernetesSourceRecord {
+	return domain.KubernetesSourceRecord{
+		Key:                  row.SourceKey,
+		Name:                 row.Name,
+		SiteID:               uuid.UUID(row.SiteID.Bytes),
+		ClusterDomain:        row.ClusterDomain,
+		Namespaces:           append([]string(nil), row.NamespaceScope...),
+		ObjectKinds:          append([]string(nil), row.ObjectKinds...),
+		PodSelector:          row.PodSelector,
+		AutoRegister:         row.AutoRegister,
+		Managed:              row.Managed,
+		AuthMode:             row.AuthMode,
+		KubeconfigCiphertext: row.KubeconfigCiphertext,
+		KubeconfigContext:    row.KubeconfigContext,
+		ReconcileInterval:    time.Duration(row.ReconcileIntervalSeconds) * time.Second,
+		RequestTimeout:       time.Duration(row.RequestTimeoutSeconds) * time.Second,
+		StaleRetention:       time.Duration(row.StaleRetentionSeconds) * time.Second,
+	}
+}
+
+func toDomainKubernetesSourceStatus(row sqlc.KubernetesSource) domain.KubernetesSourceStatus {
+	state := "pending"
+	if row.LastError != "" {
+		state = "degraded"
+	} else if row.LastSuccessAt.Valid {
+		state = "healthy"
+	}
+	status := domain.KubernetesSourceStatus{
+		Source:        domain.KubernetesSource{Key: row.SourceKey, Name: row.Name},
+		SiteID:        uuid.UUID(row.SiteID.Bytes),
+		ClusterDomain: row.ClusterDomain,
+		Namespaces:    append([]string(nil), row.NamespaceScope...),
+		ObjectKinds:   append([]string(nil), row.ObjectKinds...),
+		PodSelector:   row.PodSelector,
+		AutoRegister:  row.AutoRegister,
+		State:         state,
+		LastAttemptAt: optionalTime(row.LastAttemptAt),
+		LastSuccessAt: optionalTime(row.LastSuccessAt),
+		LastError:     row.LastError,
+		Services:      int(row.ServiceCount),
+		Matched:       int(row.MatchedCount),
+		Unmatched:     int(row.UnmatchedCount),
+		Ambiguous:     int(row.AmbiguousCount),
+		NoUsableIP:    int(row.NoUsableIpCount),
+		Objects:       int(row.ObjectCount),
+		ObjectError:   row.ObjectError,
+		Managed:       row.Managed,
+	}
+	if row.Managed {
+		status.Settings = &domain.KubernetesSourceSettings{
+			AuthMode:          row.AuthMode,
+			KubeconfigContext: row.KubeconfigContext,
+			ReconcileInterval: time.Duration(row.ReconcileIntervalSeconds) * time.Second,
+			RequestTimeout:    time.Duration(row.RequestTimeoutSeconds) * time.Second,
+			StaleRetention:    time.Duration(row.StaleRetentionSeconds) * time.Second,
+		}
+	}
+	return status
+}
+
+func seconds(duration time.Duration) int32 {
+	return int32(duration / time.Second)
+}
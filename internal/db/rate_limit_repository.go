@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// RateLimitRepository shares rate limit windows across replicas. Counters
+// live in an UNLOGGED table: losing them on a crash only resets budgets.
+type RateLimitRepository struct {
+	queries *sqlc.Queries
+}
+
+var _ domain.RateLimitRepository = (*RateLimitRepository)(nil)
+
+func NewRateLimitRepository(queries *sqlc.Queries) *RateLimitRepository {
+	return &RateLimitRepository{queries: queries}
+}
+
+func (r *RateLimitRepository) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int64, error) {
+	return r.queries.IncrementRateLimitCounter(ctx, sqlc.IncrementRateLimitCounterParams{
+		BucketKey:   key,
+		WindowStart: timestamp(windowStart),
+		ExpiresAt:   timestamp(expiresAt),
+	})
+}
+
+func (r *RateLimitRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.DeleteExpiredRateLimitCounters(ctx, timestamp(before))
+}
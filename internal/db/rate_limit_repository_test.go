@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestRateLimitRepositoryIncrementPassesWindow(t *testing.T) {
+	windowStart := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repository := NewRateLimitRepository(sqlc.New(stubDBTX{queryRowFn: func(_ context.Context, _ string, args ...any) pgx.Row {
+		if args[0] != "sub:ci:write" || args[1].(pgtype.Timestamptz).Time != windowStart || args[2].(pgtype.Timestamptz).Time != windowStart.Add(time.Minute) {
+			t.Fatalf("unexpected args: %v", args)
+		}
+		return stubRow{values: []any{int64(3)}}
+	}}))
+
+	hits, err := repository.Increment(context.Background(), "sub:ci:write", windowStart, windowStart.Add(time.Minute))
+	if err != nil || hits != 3 {
+		t.Fatalf("expected 3 hits, got %d err=%v", hits, err)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
@@ -58,7 +59,18 @@ func (r *ReportingRepository) ListSnapshots(ctx context.Context, subnetID int64,
 }
 
 func (r *ReportingRepository) CaptureDueSnapshots(ctx context.Context) (int64, error) {
-	return r.queries.CaptureDueSubnetUsageSnapshots(ctx)
+	captured, err := r.queries.CaptureDueSubnetUsageSnapshots(ctx)
+	if err != nil || captured == 0 {
+		return captured, err
+	}
+	if err := notifyChangeEvent(ctx, r.queries, domain.ChangeEvent{
+		Type:       domain.EventReportingSnapshotCaptured,
+		ObjectType: domain.ObjectTypeReporting,
+		OccurredAt: time.Now(),
+	}); err != nil {
+		return captured, fmt.Errorf("notifying snapshot listeners: %w", err)
+	}
+	return captured, nil
 }
 
 func (r *ReportingRepository) DeleteExpiredSnapshots(ctx context.Context) (int64, error) {
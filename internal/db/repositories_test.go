@@ -3,8 +3,10 @@ package db
 import (
 	"context"
 	"errors"
+	"net"
 	"net/netip"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -197,11 +199,12 @@ func TestSubnetRepositoryDeleteReturnsFalseWhenNothingDeleted(t *testing.T) {
 
 func TestSubnetRepositoryListMapsRowsToDomain(t *testing.T) {
 	now := testTimestamptz()
+	gateway := mustAddr(t, "10.0.0.1")
 	repo := NewSubnetRepository(sqlc.New(stubDBTX{
 		queryFn: func(context.Context, string, ...any) (pgx.Rows, error) {
 			return &stubRows{
 				rows: [][]any{
-					{int64(7), mustPrefix(t, "10.0.0.0/24"), "office", now, now, pgtype.UUID{Bytes: [16]byte{}, Valid: true}, int64(0)},
+					{int64(7), mustPrefix(t, "10.0.0.0/24"), "office", now, now, pgtype.UUID{Bytes: [16]byte{}, Valid: true}, &gateway, []netip.Addr{mustAddr(t, "10.0.0.53")}, []byte(`[{"start":"10.0.0.100","end":"10.0.0.199"}]`), int64(0)},
 				},
 			}, nil
 		},
@@ -217,6 +220,10 @@ func TestSubnetRepositoryListMapsRowsToDomain(t *testing.T) {
 	if subnets[0].ID != 7 || subnets[0].CIDR.String() != "10.0.0.0/24" || subnets[0].Description != "office" {
 		t.Fatalf("unexpected subnet: %+v", subnets[0])
 	}
+	wantPools := []domain.DHCPPool{{Start: mustAddr(t, "10.0.0.100"), End: mustAddr(t, "10.0.0.199")}}
+	if subnets[0].Gateway != gateway || len(subnets[0].DNSServers) != 1 || !reflect.DeepEqual(subnets[0].DHCPPools, wantPools) {
+		t.Fatalf("unexpected dhcp settings: %+v", subnets[0])
+	}
 }
 
 func TestIPRepositoryFindByIDAndSubnetRejectsInvalidUUID(t *testing.T) {
@@ -257,14 +264,30 @@ func TestIPRepositoryCreateMapsUniqueViolationToConflict(t *testing.T) {
 	}
 }
 
-func TestIPRepositoryUpdateHostnameMapsNoRowsToNotFound(t *testing.T) {
+func TestIPRepositoryCreateMapsDuplicateMACToConflict(t *testing.T) {
+	repo := NewIPRepository(sqlc.New(stubDBTX{
+		queryRowFn: func(context.Context, string, ...any) pgx.Row {
+			return stubRow{err: &pgconn.PgError{ConstraintName: "ip_addresses_subnet_mac_key"}}
+		},
+	}))
+
+	_, err := repo.Create(context.Background(), domain.CreateIPRecord{
+		IP:         mustAddr(t, "10.0.0.11"),
+		MACAddress: net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56},
+	}, 42)
+	if !errors.Is(err, domain.ErrConflict) || err.Error() != "conflict: mac address already reserved in subnet" {
+		t.Fatalf("expected mac conflict, got %v", err)
+	}
+}
+
+func TestIPRepositoryUpdateMapsNoRowsToNotFound(t *testing.T) {
 	repo := NewIPRepository(sqlc.New(stubDBTX{
 		queryRowFn: func(context.Context, string, ...any) pgx.Row {
 			return stubRow{err: pgx.ErrNoRows}
 		},
 	}))
 
-	_, err := repo.UpdateHostname(context.Background(), domain.IPAddressID("550e8400-e29b-41d4-a716-446655440000"), domain.UpdateIPInput{Hostname: "new-host"})
+	_, err := repo.Update(context.Background(), domain.IPAddressID("550e8400-e29b-41d4-a716-446655440000"), domain.UpdateIPRecord{Hostname: "new-host"})
 	if !errors.Is(err, domain.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -301,10 +324,20 @@ func TestIPRepositoryDeleteByIDAndSubnetReturnsFalseOnNoRows(t *testing.T) {
 func TestIPRepositoryListBySubnetIDMapsRowsToDomain(t *testing.T) {
 	now := testTimestamptz()
 	repo := NewIPRepository(sqlc.New(stubDBTX{
-		queryFn: func(context.Context, string, ...any) (pgx.Rows, error) {
+		queryFn: func(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+			if strings.Contains(sql, "kubernetes_load_balancer_allocations") {
+				return &stubRows{rows: [][]any{
+					{mustUUID(t, "550e8400-e29b-41d4-a716-446655440000"), "prod", "uid-ingress", "ingress-nginx", "controller", now},
+				}}, nil
+			}
+			if strings.Contains(sql, "kubernetes_registered_addresses") {
+				return &stubRows{rows: [][]any{
+					{mustUUID(t, "550e8400-e29b-41d4-a716-446655440000"), "uid-ingress", "ingress-nginx", "controller", now, "prod", "Production"},
+				}}, nil
+			}
 			return &stubRows{
 				rows: [][]any{
-					{mustUUID(t, "550e8400-e29b-41d4-a716-446655440000"), mustAddr(t, "10.0.0.10"), "printer", now, now, int64(42)},
+					{mustUUID(t, "550e8400-e29b-41d4-a716-446655440000"), mustAddr(t, "10.0.0.10"), "printer", now, now, int64(42), net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}, now},
 				},
 			}, nil
 		},
@@ -317,7 +350,13 @@ func TestIPRepositoryListBySubnetIDMapsRowsToDomain(t *testing.T) {
 	if len(ips) != 1 {
 		t.Fatalf("expected 1 ip, got %d", len(ips))
 	}
-	if ips[0].ID != domain.IPAddressID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000").String()) || ips[0].IP.String() != "10.0.0.10" || ips[0].Hostname != "printer" {
+	if ips[0].ID != domain.IPAddressID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000").String()) || ips[0].IP.String() != "10.0.0.10" || ips[0].Hostname != "printer" || ips[0].MACAddress.String() != "52:54:00:12:34:56" || ips[0].LastSeenAt == nil || !ips[0].LastSeenAt.Equal(now.Time) {
 		t.Fatalf("unexpected ip: %+v", ips[0])
 	}
+	if allocation := ips[0].KubernetesAllocation; allocation == nil || allocation.Controller != "prod" || allocation.Name != "controller" || allocation.IP != ips[0].IP {
+		t.Fatalf("expected the allocation owner, got %+v", allocation)
+	}
+	if registration := ips[0].KubernetesRegistration; registration == nil || registration.Source.Name != "Production" || registration.ServiceUID != "uid-ingress" {
+		t.Fatalf("expected the registering Service, got %+v", registration)
+	}
 }
@@ -0,0 +1,267 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: dns.sql
+
+package db
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const bumpDNSZoneSerial = `-- name: BumpDNSZoneSerial :one
+INSERT INTO dns_zone_serials AS current (zone, serial, content_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (zone) DO UPDATE
+SET serial = CASE
+        WHEN current.content_hash = EXCLUDED.content_hash THEN current.serial
+        ELSE GREATEST(current.serial + 1, EXCLUDED.serial)
+    END,
+    content_hash = EXCLUDED.content_hash,
+    updated_at = CASE
+        WHEN current.content_hash = EXCLUDED.content_hash THEN current.updated_at
+        ELSE now()
+    END
+RETURNING serial
+`
+
+type BumpDNSZoneSerialParams struct {
+	Zone        string `json:"zone"`
+	Serial      int64  `json:"serial"`
+	ContentHash string `json:"content_hash"`
+}
+
+// The serial only moves when the zone content hash changes. It becomes the
+// larger of the next serial and today's YYYYMMDD01 candidate.
+func (q *Queries) BumpDNSZoneSerial(ctx context.Context, arg BumpDNSZoneSerialParams) (int64, error) {
+	row := q.db.QueryRow(ctx, bumpDNSZoneSerial, arg.Zone, arg.Serial, arg.ContentHash)
+	var serial int64
+	err := row.Scan(&serial)
+	return serial, err
+}
+
+const createDNSZone = `-- name: CreateDNSZone :one
+INSERT INTO dns_zones (name, site_id, subnet_id, ttl)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, site_id, subnet_id, ttl, created_at, updated_at
+`
+
+type CreateDNSZoneParams struct {
+	Name     string      `json:"name"`
+	SiteID   pgtype.UUID `json:"site_id"`
+	SubnetID pgtype.Int8 `json:"subnet_id"`
+	Ttl      int32       `json:"ttl"`
+}
+
+func (q *Queries) CreateDNSZone(ctx context.Context, arg CreateDNSZoneParams) (DnsZone, error) {
+	row := q.db.QueryRow(ctx, createDNSZone,
+		arg.Name,
+		arg.SiteID,
+		arg.SubnetID,
+		arg.Ttl,
+	)
+	var i DnsZone
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SiteID,
+		&i.SubnetID,
+		&i.Ttl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteDNSZoneByName = `-- name: DeleteDNSZoneByName :execrows
+DELETE FROM dns_zones
+WHERE name = $1
+`
+
+func (q *Queries) DeleteDNSZoneByName(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDNSZoneByName, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getDNSZoneByName = `-- name: GetDNSZoneByName :one
+SELECT id, name, site_id, subnet_id, ttl, created_at, updated_at
+FROM dns_zones
+WHERE name = $1
+`
+
+func (q *Queries) GetDNSZoneByName(ctx context.Context, name string) (DnsZone, error) {
+	row := q.db.QueryRow(ctx, getDNSZoneByName, name)
+	var i DnsZone
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SiteID,
+		&i.SubnetID,
+		&i.Ttl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDNSZoneForSubnet = `-- name: GetDNSZoneForSubnet :one
+SELECT z.id, z.name, z.site_id, z.subnet_id, z.ttl, z.created_at, z.updated_at
+FROM subnets s
+JOIN dns_zones z ON z.subnet_id = s.id OR (z.site_id IS NOT NULL AND z.site_id = s.site_id)
+WHERE s.id = $1
+ORDER BY z.subnet_id IS NULL
+LIMIT 1
+`
+
+// A zone attached to the subnet itself takes precedence over its site's.
+func (q *Queries) GetDNSZoneForSubnet(ctx context.Context, id int64) (DnsZone, error) {
+	row := q.db.QueryRow(ctx, getDNSZoneForSubnet, id)
+	var i DnsZone
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SiteID,
+		&i.SubnetID,
+		&i.Ttl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listDNSHostRecords = `-- name: ListDNSHostRecords :many
+SELECT ip.ip, ip.hostname, ip.subnet_id, s.cidr,
+       COALESCE(subnet_zone.name, site_zone.name, '')::text AS zone,
+       COALESCE(subnet_zone.ttl, site_zone.ttl, 0)::integer AS ttl
+FROM ip_addresses ip
+JOIN subnets s ON s.id = ip.subnet_id
+LEFT JOIN dns_zones subnet_zone ON subnet_zone.subnet_id = s.id
+LEFT JOIN dns_zones site_zone ON site_zone.site_id = s.site_id
+WHERE ip.hostname <> ''
+ORDER BY ip.ip, ip.created_at
+`
+
+type ListDNSHostRecordsRow struct {
+	Ip       netip.Addr   `json:"ip"`
+	Hostname string       `json:"hostname"`
+	SubnetID int64        `json:"subnet_id"`
+	Cidr     netip.Prefix `json:"cidr"`
+	Zone     string       `json:"zone"`
+	Ttl      int32        `json:"ttl"`
+}
+
+// Every named address with the forward zone it belongs to, if any.
+func (q *Queries) ListDNSHostRecords(ctx context.Context) ([]ListDNSHostRecordsRow, error) {
+	rows, err := q.db.Query(ctx, listDNSHostRecords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDNSHostRecordsRow
+	for rows.Next() {
+		var i ListDNSHostRecordsRow
+		if err := rows.Scan(
+			&i.Ip,
+			&i.Hostname,
+			&i.SubnetID,
+			&i.Cidr,
+			&i.Zone,
+			&i.Ttl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDNSHostRecordsForIP = `-- name: ListDNSHostRecordsForIP :many
+SELECT ip.ip, ip.hostname, ip.subnet_id, s.cidr,
+       COALESCE(subnet_zone.name, site_zone.name, '')::text AS zone,
+       COALESCE(subnet_zone.ttl, site_zone.ttl, 0)::integer AS ttl
+FROM ip_addresses ip
+JOIN subnets s ON s.id = ip.subnet_id
+LEFT JOIN dns_zones subnet_zone ON subnet_zone.subnet_id = s.id
+LEFT JOIN dns_zones site_zone ON site_zone.site_id = s.site_id
+WHERE ip.hostname <> '' AND ip.ip = $1
+ORDER BY ip.created_at
+`
+
+type ListDNSHostRecordsForIPRow struct {
+	Ip       netip.Addr   `json:"ip"`
+	Hostname string       `json:"hostname"`
+	SubnetID int64        `json:"subnet_id"`
+	Cidr     netip.Prefix `json:"cidr"`
+	Zone     string       `json:"zone"`
+	Ttl      int32        `json:"ttl"`
+}
+
+func (q *Queries) ListDNSHostRecordsForIP(ctx context.Context, ip netip.Addr) ([]ListDNSHostRecordsForIPRow, error) {
+	rows, err := q.db.Query(ctx, listDNSHostRecordsForIP, ip)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDNSHostRecordsForIPRow
+	for rows.Next() {
+		var i ListDNSHostRecordsForIPRow
+		if err := rows.Scan(
+			&i.Ip,
+			&i.Hostname,
+			&i.SubnetID,
+			&i.Cidr,
+			&i.Zone,
+			&i.Ttl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDNSZones = `-- name: ListDNSZones :many
+SELECT id, name, site_id, subnet_id, ttl, created_at, updated_at
+FROM dns_zones
+ORDER BY name
+`
+
+func (q *Queries) ListDNSZones(ctx context.Context) ([]DnsZone, error) {
+	rows, err := q.db.Query(ctx, listDNSZones)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DnsZone
+	for rows.Next() {
+		var i DnsZone
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.SiteID,
+			&i.SubnetID,
+			&i.Ttl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,203 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: dns_updates.sql
+
+package db
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimDueDNSUpdates = `-- name: ClaimDueDNSUpdates :many
+WITH due AS (
+    SELECT ip
+    FROM dns_update_queue
+    WHERE next_attempt_at <= now()
+    ORDER BY next_attempt_at, ip
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE dns_update_queue
+SET attempts = dns_update_queue.attempts + 1,
+    next_attempt_at = $2::timestamptz
+FROM due
+WHERE dns_update_queue.ip = due.ip
+RETURNING dns_update_queue.ip, dns_update_queue.generation, dns_update_queue.attempts
+`
+
+type ClaimDueDNSUpdatesParams struct {
+	Limit   int32              `json:"limit"`
+	Column2 pgtype.Timestamptz `json:"column_2"`
+}
+
+type ClaimDueDNSUpdatesRow struct {
+	Ip         netip.Addr `json:"ip"`
+	Generation int64      `json:"generation"`
+	Attempts   int32      `json:"attempts"`
+}
+
+func (q *Queries) ClaimDueDNSUpdates(ctx context.Context, arg ClaimDueDNSUpdatesParams) ([]ClaimDueDNSUpdatesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueDNSUpdates, arg.Limit, arg.Column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueDNSUpdatesRow
+	for rows.Next() {
+		var i ClaimDueDNSUpdatesRow
+		if err := rows.Scan(&i.Ip, &i.Generation, &i.Attempts); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeDNSUpdate = `-- name: CompleteDNSUpdate :exec
+DELETE FROM dns_update_queue
+WHERE ip = $1 AND generation = $2
+`
+
+type CompleteDNSUpdateParams struct {
+	Ip         netip.Addr `json:"ip"`
+	Generation int64      `json:"generation"`
+}
+
+// A newer generation was enqueued while this one ran and stays queued.
+func (q *Queries) CompleteDNSUpdate(ctx context.Context, arg CompleteDNSUpdateParams) error {
+	_, err := q.db.Exec(ctx, completeDNSUpdate, arg.Ip, arg.Generation)
+	return err
+}
+
+const deleteDNSRegistration = `-- name: DeleteDNSRegistration :exec
+DELETE FROM dns_registrations
+WHERE ip = $1
+`
+
+func (q *Queries) DeleteDNSRegistration(ctx context.Context, ip netip.Addr) error {
+	_, err := q.db.Exec(ctx, deleteDNSRegistration, ip)
+	return err
+}
+
+const enqueueDNSUpdates = `-- name: EnqueueDNSUpdates :exec
+SELECT enqueue_dns_updates($1::inet[])
+`
+
+func (q *Queries) EnqueueDNSUpdates(ctx context.Context, ips []netip.Addr) error {
+	_, err := q.db.Exec(ctx, enqueueDNSUpdates, ips)
+	return err
+}
+
+const failDNSUpdate = `-- name: FailDNSUpdate :exec
+UPDATE dns_update_queue
+SET next_attempt_at = $3,
+    last_error = $4
+WHERE ip = $1 AND generation = $2
+`
+
+type FailDNSUpdateParams struct {
+	Ip            netip.Addr         `json:"ip"`
+	Generation    int64              `json:"generation"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     string             `json:"last_error"`
+}
+
+func (q *Queries) FailDNSUpdate(ctx context.Context, arg FailDNSUpdateParams) error {
+	_, err := q.db.Exec(ctx, failDNSUpdate,
+		arg.Ip,
+		arg.Generation,
+		arg.NextAttemptAt,
+		arg.LastError,
+	)
+	return err
+}
+
+const getDNSRegistration = `-- name: GetDNSRegistration :one
+SELECT ip, name, zone, reverse_zone, ttl, updated_at
+FROM dns_registrations
+WHERE ip = $1
+`
+
+func (q *Queries) GetDNSRegistration(ctx context.Context, ip netip.Addr) (DnsRegistration, error) {
+	row := q.db.QueryRow(ctx, getDNSRegistration, ip)
+	var i DnsRegistration
+	err := row.Scan(
+		&i.Ip,
+		&i.Name,
+		&i.Zone,
+		&i.ReverseZone,
+		&i.Ttl,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listDNSRegistrations = `-- name: ListDNSRegistrations :many
+SELECT ip, name, zone, reverse_zone, ttl, updated_at
+FROM dns_registrations
+ORDER BY ip
+`
+
+func (q *Queries) ListDNSRegistrations(ctx context.Context) ([]DnsRegistration, error) {
+	rows, err := q.db.Query(ctx, listDNSRegistrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DnsRegistration
+	for rows.Next() {
+		var i DnsRegistration
+		if err := rows.Scan(
+			&i.Ip,
+			&i.Name,
+			&i.Zone,
+			&i.ReverseZone,
+			&i.Ttl,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertDNSRegistration = `-- name: UpsertDNSRegistration :exec
+INSERT INTO dns_registrations (ip, name, zone, reverse_zone, ttl)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (ip) DO UPDATE
+SET name = EXCLUDED.name,
+    zone = EXCLUDED.zone,
+    reverse_zone = EXCLUDED.reverse_zone,
+    ttl = EXCLUDED.ttl,
+    updated_at = now()
+`
+
+type UpsertDNSRegistrationParams struct {
+	Ip          netip.Addr `json:"ip"`
+	Name        string     `json:"name"`
+	Zone        string     `json:"zone"`
+	ReverseZone string     `json:"reverse_zone"`
+	Ttl         int32      `json:"ttl"`
+}
+
+func (q *Queries) UpsertDNSRegistration(ctx context.Context, arg UpsertDNSRegistrationParams) error {
+	_, err := q.db.Exec(ctx, upsertDNSRegistration,
+		arg.Ip,
+		arg.Name,
+		arg.Zone,
+		arg.ReverseZone,
+		arg.Ttl,
+	)
+	return err
+}
@@ -0,0 +1,19 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: events.sql
+
+package db
+
+import (
+	"context"
+)
+
+const notifyChangeEvent = `-- name: NotifyChangeEvent :exec
+SELECT pg_notify('ipam_events', $1::text)
+`
+
+func (q *Queries) NotifyChangeEvent(ctx context.Context, dollar_1 string) error {
+	_, err := q.db.Exec(ctx, notifyChangeEvent, dollar_1)
+	return err
+}
@@ -0,0 +1,152 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: idempotency.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (scope, idempotency_key, method, path, request_hash, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (scope, idempotency_key) DO UPDATE
+SET method = EXCLUDED.method,
+    path = EXCLUDED.path,
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    content_type = '',
+    response_body = NULL,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= $8::timestamptz)
+RETURNING scope, idempotency_key, method, path, request_hash, status_code, content_type, response_body, created_at, expires_at
+`
+
+type ClaimIdempotencyKeyParams struct {
+	Scope          string             `json:"scope"`
+	IdempotencyKey string             `json:"idempotency_key"`
+	Method         string             `json:"method"`
+	Path           string             `json:"path"`
+	RequestHash    string             `json:"request_hash"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	Column8        pgtype.Timestamptz `json:"column_8"`
+}
+
+// Inserts a pending key, or takes over one that has expired or whose
+// request was abandoned before completing. Returns no row when the key is
+// held by a live or completed request.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, claimIdempotencyKey,
+		arg.Scope,
+		arg.IdempotencyKey,
+		arg.Method,
+		arg.Path,
+		arg.RequestHash,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.Column8,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Scope,
+		&i.IdempotencyKey,
+		&i.Method,
+		&i.Path,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE scope = $1 AND idempotency_key = $2
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Scope          string      `json:"scope"`
+	IdempotencyKey string      `json:"idempotency_key"`
+	StatusCode     pgtype.Int4 `json:"status_code"`
+	ContentType    string      `json:"content_type"`
+	ResponseBody   []byte      `json:"response_body"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey,
+		arg.Scope,
+		arg.IdempotencyKey,
+		arg.StatusCode,
+		arg.ContentType,
+		arg.ResponseBody,
+	)
+	return err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT scope, idempotency_key, method, path, request_hash, status_code, content_type, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE scope = $1 AND idempotency_key = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	Scope          string `json:"scope"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.Scope, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Scope,
+		&i.IdempotencyKey,
+		&i.Method,
+		&i.Path,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const releaseIdempotencyKey = `-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE scope = $1 AND idempotency_key = $2 AND status_code IS NULL
+`
+
+type ReleaseIdempotencyKeyParams struct {
+	Scope          string `json:"scope"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) ReleaseIdempotencyKey(ctx context.Context, arg ReleaseIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, releaseIdempotencyKey, arg.Scope, arg.IdempotencyKey)
+	return err
+}
@@ -7,25 +7,34 @@ package db
 
 import (
 	"context"
+	"net"
 	"net/netip"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createIPAddress = `-- name: CreateIPAddress :one
-INSERT INTO ip_addresses (ip, hostname, subnet_id)
-VALUES ($1, $2, $3)
-RETURNING id, ip, hostname, created_at, updated_at, subnet_id
+INSERT INTO ip_addresses (ip, hostname, subnet_id, mac_address, last_seen_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at
 `
 
 type CreateIPAddressParams struct {
-	Ip       netip.Addr `json:"ip"`
-	Hostname string     `json:"hostname"`
-	SubnetID int64      `json:"subnet_id"`
+	Ip         netip.Addr         `json:"ip"`
+	Hostname   string             `json:"hostname"`
+	SubnetID   int64              `json:"subnet_id"`
+	MacAddress net.HardwareAddr   `json:"mac_address"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
 }
 
 func (q *Queries) CreateIPAddress(ctx context.Context, arg CreateIPAddressParams) (IpAddress, error) {
-	row := q.db.QueryRow(ctx, createIPAddress, arg.Ip, arg.Hostname, arg.SubnetID)
+	row := q.db.QueryRow(ctx, createIPAddress,
+		arg.Ip,
+		arg.Hostname,
+		arg.SubnetID,
+		arg.MacAddress,
+		arg.LastSeenAt,
+	)
 	var i IpAddress
 	err := row.Scan(
 		&i.ID,
@@ -34,6 +43,8 @@ func (q *Queries) CreateIPAddress(ctx context.Context, arg CreateIPAddressParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SubnetID,
+		&i.MacAddress,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
@@ -57,7 +68,7 @@ func (q *Queries) DeleteIPByUUIDandSubnetID(ctx context.Context, arg DeleteIPByU
 }
 
 const getIPByUUIDandSubnetID = `-- name: GetIPByUUIDandSubnetID :one
-SELECT id, ip, hostname, created_at, updated_at, subnet_id FROM ip_addresses
+SELECT id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at FROM ip_addresses
 WHERE id = $1 AND subnet_id = $2
 `
 
@@ -76,12 +87,14 @@ func (q *Queries) GetIPByUUIDandSubnetID(ctx context.Context, arg GetIPByUUIDand
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SubnetID,
+		&i.MacAddress,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
 const listIPsBySubnetID = `-- name: ListIPsBySubnetID :many
-SELECT id, ip, hostname, created_at, updated_at, subnet_id
+SELECT id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at
 FROM ip_addresses
 WHERE subnet_id = $1
 ORDER by ip
@@ -103,6 +116,8 @@ func (q *Queries) ListIPsBySubnetID(ctx context.Context, subnetID int64) ([]IpAd
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.SubnetID,
+			&i.MacAddress,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -116,18 +131,25 @@ func (q *Queries) ListIPsBySubnetID(ctx context.Context, subnetID int64) ([]IpAd
 
 const updateIPByUUID = `-- name: UpdateIPByUUID :one
 UPDATE ip_addresses
-SET hostname = $1, updated_at = NOW()
+SET hostname = $1, mac_address = $3, last_seen_at = $4, updated_at = NOW()
 WHERE id = $2
-RETURNING id, ip, hostname, created_at, updated_at, subnet_id
+RETURNING id, ip, hostname, created_at, updated_at, subnet_id, mac_address, last_seen_at
 `
 
 type UpdateIPByUUIDParams struct {
-	Hostname string      `json:"hostname"`
-	ID       pgtype.UUID `json:"id"`
+	Hostname   string             `json:"hostname"`
+	ID         pgtype.UUID        `json:"id"`
+	MacAddress net.HardwareAddr   `json:"mac_address"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
 }
 
 func (q *Queries) UpdateIPByUUID(ctx context.Context, arg UpdateIPByUUIDParams) (IpAddress, error) {
-	row := q.db.QueryRow(ctx, updateIPByUUID, arg.Hostname, arg.ID)
+	row := q.db.QueryRow(ctx, updateIPByUUID,
+		arg.Hostname,
+		arg.ID,
+		arg.MacAddress,
+		arg.LastSeenAt,
+	)
 	var i IpAddress
 	err := row.Scan(
 		&i.ID,
@@ -136,6 +158,8 @@ func (q *Queries) UpdateIPByUUID(ctx context.Context, arg UpdateIPByUUIDParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SubnetID,
+		&i.MacAddress,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
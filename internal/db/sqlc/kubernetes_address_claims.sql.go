@@ -0,0 +1,93 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: kubernetes_address_claims.sql
+
+package db
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createKubernetesAddressClaim = `-- name: CreateKubernetesAddressClaim :one
+INSERT INTO kubernetes_address_claims (ip_address_id, claim_uid, namespace, name)
+VALUES ($1, $2, $3, $4)
+RETURNING ip_address_id, claim_uid, namespace, name, claimed_at
+`
+
+type CreateKubernetesAddressClaimParams struct {
+	IpAddressID pgtype.UUID `json:"ip_address_id"`
+	ClaimUid    string      `json:"claim_uid"`
+	Namespace   string      `json:"namespace"`
+	Name        string      `json:"name"`
+}
+
+func (q *Queries) CreateKubernetesAddressClaim(ctx context.Context, arg CreateKubernetesAddressClaimParams) (KubernetesAddressClaim, error) {
+	row := q.db.QueryRow(ctx, createKubernetesAddressClaim,
+		arg.IpAddressID,
+		arg.ClaimUid,
+		arg.Namespace,
+		arg.Name,
+	)
+	var i KubernetesAddressClaim
+	err := row.Scan(
+		&i.IpAddressID,
+		&i.ClaimUid,
+		&i.Namespace,
+		&i.Name,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
+
+const deleteKubernetesAddressClaimAddress = `-- name: DeleteKubernetesAddressClaimAddress :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT ip_address_id FROM kubernetes_address_claims
+    WHERE claim_uid = $1
+)
+`
+
+func (q *Queries) DeleteKubernetesAddressClaimAddress(ctx context.Context, claimUid string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteKubernetesAddressClaimAddress, claimUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getKubernetesAddressClaim = `-- name: GetKubernetesAddressClaim :one
+SELECT claim.ip_address_id, claim.claim_uid, claim.namespace, claim.name, claim.claimed_at,
+    ip.ip, ip.subnet_id
+FROM kubernetes_address_claims claim
+JOIN ip_addresses ip ON ip.id = claim.ip_address_id
+WHERE claim.claim_uid = $1
+`
+
+type GetKubernetesAddressClaimRow struct {
+	IpAddressID pgtype.UUID        `json:"ip_address_id"`
+	ClaimUid    string             `json:"claim_uid"`
+	Namespace   string             `json:"namespace"`
+	Name        string             `json:"name"`
+	ClaimedAt   pgtype.Timestamptz `json:"claimed_at"`
+	Ip          netip.Addr         `json:"ip"`
+	SubnetID    int64              `json:"subnet_id"`
+}
+
+func (q *Queries) GetKubernetesAddressClaim(ctx context.Context, claimUid string) (GetKubernetesAddressClaimRow, error) {
+	row := q.db.QueryRow(ctx, getKubernetesAddressClaim, claimUid)
+	var i GetKubernetesAddressClaimRow
+	err := row.Scan(
+		&i.IpAddressID,
+		&i.ClaimUid,
+		&i.Namespace,
+		&i.Name,
+		&i.ClaimedAt,
+		&i.Ip,
+		&i.SubnetID,
+	)
+	return i, err
+}
@@ -0,0 +1,174 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: kubernetes_allocations.sql
+
+package db
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createKubernetesAllocation = `-- name: CreateKubernetesAllocation :one
+INSERT INTO kubernetes_load_balancer_allocations (ip_address_id, controller_key, service_uid, namespace, name)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING ip_address_id, controller_key, service_uid, namespace, name, allocated_at
+`
+
+type CreateKubernetesAllocationParams struct {
+	IpAddressID   pgtype.UUID `json:"ip_address_id"`
+	ControllerKey string      `json:"controller_key"`
+	ServiceUid    string      `json:"service_uid"`
+	Namespace     string      `json:"namespace"`
+	Name          string      `json:"name"`
+}
+
+func (q *Queries) CreateKubernetesAllocation(ctx context.Context, arg CreateKubernetesAllocationParams) (KubernetesLoadBalancerAllocation, error) {
+	row := q.db.QueryRow(ctx, createKubernetesAllocation,
+		arg.IpAddressID,
+		arg.ControllerKey,
+		arg.ServiceUid,
+		arg.Namespace,
+		arg.Name,
+	)
+	var i KubernetesLoadBalancerAllocation
+	err := row.Scan(
+		&i.IpAddressID,
+		&i.ControllerKey,
+		&i.ServiceUid,
+		&i.Namespace,
+		&i.Name,
+		&i.AllocatedAt,
+	)
+	return i, err
+}
+
+const deleteKubernetesAllocationAddress = `-- name: DeleteKubernetesAllocationAddress :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT ip_address_id FROM kubernetes_load_balancer_allocations
+    WHERE controller_key = $1 AND service_uid = $2
+)
+`
+
+type DeleteKubernetesAllocationAddressParams struct {
+	ControllerKey string `json:"controller_key"`
+	ServiceUid    string `json:"service_uid"`
+}
+
+func (q *Queries) DeleteKubernetesAllocationAddress(ctx context.Context, arg DeleteKubernetesAllocationAddressParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteKubernetesAllocationAddress, arg.ControllerKey, arg.ServiceUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteKubernetesAllocationAddressesExcept = `-- name: DeleteKubernetesAllocationAddressesExcept :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT ip_address_id FROM kubernetes_load_balancer_allocations
+    WHERE controller_key = $1 AND NOT (service_uid = ANY($2::text[]))
+        AND allocated_at < $3::timestamptz
+)
+`
+
+type DeleteKubernetesAllocationAddressesExceptParams struct {
+	ControllerKey string             `json:"controller_key"`
+	ServiceUids   []string           `json:"service_uids"`
+	ListedAt      pgtype.Timestamptz `json:"listed_at"`
+}
+
+func (q *Queries) DeleteKubernetesAllocationAddressesExcept(ctx context.Context, arg DeleteKubernetesAllocationAddressesExceptParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteKubernetesAllocationAddressesExcept, arg.ControllerKey, arg.ServiceUids, arg.ListedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getKubernetesAllocation = `-- name: GetKubernetesAllocation :one
+SELECT alloc.ip_address_id, alloc.controller_key, alloc.service_uid, alloc.namespace, alloc.name, alloc.allocated_at,
+    ip.ip, ip.subnet_id
+FROM kubernetes_load_balancer_allocations alloc
+JOIN ip_addresses ip ON ip.id = alloc.ip_address_id
+WHERE alloc.controller_key = $1 AND alloc.service_uid = $2
+`
+
+type GetKubernetesAllocationParams struct {
+	ControllerKey string `json:"controller_key"`
+	ServiceUid    string `json:"service_uid"`
+}
+
+type GetKubernetesAllocationRow struct {
+	IpAddressID   pgtype.UUID        `json:"ip_address_id"`
+	ControllerKey string             `json:"controller_key"`
+	ServiceUid    string             `json:"service_uid"`
+	Namespace     string             `json:"namespace"`
+	Name          string             `json:"name"`
+	AllocatedAt   pgtype.Timestamptz `json:"allocated_at"`
+	Ip            netip.Addr         `json:"ip"`
+	SubnetID      int64              `json:"subnet_id"`
+}
+
+func (q *Queries) GetKubernetesAllocation(ctx context.Context, arg GetKubernetesAllocationParams) (GetKubernetesAllocationRow, error) {
+	row := q.db.QueryRow(ctx, getKubernetesAllocation, arg.ControllerKey, arg.ServiceUid)
+	var i GetKubernetesAllocationRow
+	err := row.Scan(
+		&i.IpAddressID,
+		&i.ControllerKey,
+		&i.ServiceUid,
+		&i.Namespace,
+		&i.Name,
+		&i.AllocatedAt,
+		&i.Ip,
+		&i.SubnetID,
+	)
+	return i, err
+}
+
+const listKubernetesAllocationsBySubnet = `-- name: ListKubernetesAllocationsBySubnet :many
+SELECT alloc.ip_address_id, alloc.controller_key, alloc.service_uid, alloc.namespace, alloc.name, alloc.allocated_at
+FROM kubernetes_load_balancer_allocations alloc
+JOIN ip_addresses ip ON ip.id = alloc.ip_address_id
+WHERE ip.subnet_id = $1
+`
+
+func (q *Queries) ListKubernetesAllocationsBySubnet(ctx context.Context, subnetID int64) ([]KubernetesLoadBalancerAllocation, error) {
+	rows, err := q.db.Query(ctx, listKubernetesAllocationsBySubnet, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KubernetesLoadBalancerAllocation
+	for rows.Next() {
+		var i KubernetesLoadBalancerAllocation
+		if err := rows.Scan(
+			&i.IpAddressID,
+			&i.ControllerKey,
+			&i.ServiceUid,
+			&i.Namespace,
+			&i.Name,
+			&i.AllocatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockSubnetAllocations = `-- name: LockSubnetAllocations :exec
+SELECT pg_advisory_xact_lock(hashtextextended('ip_allocation:' || $1::bigint::text, 0))
+`
+
+func (q *Queries) LockSubnetAllocations(ctx context.Context, subnetID int64) error {
+	_, err := q.db.Exec(ctx, lockSubnetAllocations, subnetID)
+	return err
+}
@@ -12,6 +12,197 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countKubernetesSourceObservations = `-- name: CountKubernetesSourceObservations :one
+SELECT count(DISTINCT svc.id)::integer AS services,
+       count(a.service_id) FILTER (WHERE a.match_status = 'matched')::integer AS matched,
+       count(a.service_id) FILTER (WHERE a.match_status = 'unmatched')::integer AS unmatched,
+       count(a.service_id) FILTER (WHERE a.match_status = 'ambiguous')::integer AS ambiguous,
+       count(DISTINCT svc.id) FILTER (WHERE a.service_id IS NULL)::integer AS no_usable_ip
+FROM kubernetes_services svc
+LEFT JOIN kubernetes_service_addresses a ON a.service_id = svc.id
+WHERE svc.source_id = $1 AND svc.active = true
+`
+
+type CountKubernetesSourceObservationsRow struct {
+	Services   int32 `json:"services"`
+	Matched    int32 `json:"matched"`
+	Unmatched  int32 `json:"unmatched"`
+	Ambiguous  int32 `json:"ambiguous"`
+	NoUsableIp int32 `json:"no_usable_ip"`
+}
+
+func (q *Queries) CountKubernetesSourceObservations(ctx context.Context, sourceID pgtype.UUID) (CountKubernetesSourceObservationsRow, error) {
+	row := q.db.QueryRow(ctx, countKubernetesSourceObservations, sourceID)
+	var i CountKubernetesSourceObservationsRow
+	err := row.Scan(
+		&i.Services,
+		&i.Matched,
+		&i.Unmatched,
+		&i.Ambiguous,
+		&i.NoUsableIp,
+	)
+	return i, err
+}
+
+const createKubernetesDiscoveryEvent = `-- name: CreateKubernetesDiscoveryEvent :exec
+INSERT INTO kubernetes_discovery_events (
+    run_id, source_id, service_uid, namespace, name, change,
+    previous_addresses, addresses, occurred_at
+) VALUES ($1, $2, $3, $4, $5, $6, $8::inet[], $9::inet[], $7)
+`
+
+type CreateKubernetesDiscoveryEventParams struct {
+	RunID             int64              `json:"run_id"`
+	SourceID          pgtype.UUID        `json:"source_id"`
+	ServiceUid        string             `json:"service_uid"`
+	Namespace         string             `json:"namespace"`
+	Name              string             `json:"name"`
+	Change            string             `json:"change"`
+	OccurredAt        pgtype.Timestamptz `json:"occurred_at"`
+	PreviousAddresses []netip.Addr       `json:"previous_addresses"`
+	Addresses         []netip.Addr       `json:"addresses"`
+}
+
+func (q *Queries) CreateKubernetesDiscoveryEvent(ctx context.Context, arg CreateKubernetesDiscoveryEventParams) error {
+	_, err := q.db.Exec(ctx, createKubernetesDiscoveryEvent,
+		arg.RunID,
+		arg.SourceID,
+		arg.ServiceUid,
+		arg.Namespace,
+		arg.Name,
+		arg.Change,
+		arg.OccurredAt,
+		arg.PreviousAddresses,
+		arg.Addresses,
+	)
+	return err
+}
+
+const createKubernetesDiscoveryRun = `-- name: CreateKubernetesDiscoveryRun :one
+INSERT INTO kubernetes_discovery_runs (
+    source_id, started_at, finished_at, service_count, matched_count,
+    unmatched_count, ambiguous_count, no_usable_ip_count, error
+) VALUES ($1, $2, clock_timestamp(), $3, $4, $5, $6, $7, $8)
+RETURNING id
+`
+
+type CreateKubernetesDiscoveryRunParams struct {
+	SourceID        pgtype.UUID        `json:"source_id"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	ServiceCount    int32              `json:"service_count"`
+	MatchedCount    int32              `json:"matched_count"`
+	UnmatchedCount  int32              `json:"unmatched_count"`
+	AmbiguousCount  int32              `json:"ambiguous_count"`
+	NoUsableIpCount int32              `json:"no_usable_ip_count"`
+	Error           string             `json:"error"`
+}
+
+func (q *Queries) CreateKubernetesDiscoveryRun(ctx context.Context, arg CreateKubernetesDiscoveryRunParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createKubernetesDiscoveryRun,
+		arg.SourceID,
+		arg.StartedAt,
+		arg.ServiceCount,
+		arg.MatchedCount,
+		arg.UnmatchedCount,
+		arg.AmbiguousCount,
+		arg.NoUsableIpCount,
+		arg.Error,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createKubernetesObjectAddress = `-- name: CreateKubernetesObjectAddress :exec
+INSERT INTO kubernetes_object_addresses (
+    object_id, kind, address, node_name, ip_address_id, match_status, match_count
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateKubernetesObjectAddressParams struct {
+	ObjectID    pgtype.UUID `json:"object_id"`
+	Kind        string      `json:"kind"`
+	Address     netip.Addr  `json:"address"`
+	NodeName    string      `json:"node_name"`
+	IpAddressID pgtype.UUID `json:"ip_address_id"`
+	MatchStatus string      `json:"match_status"`
+	MatchCount  int32       `json:"match_count"`
+}
+
+func (q *Queries) CreateKubernetesObjectAddress(ctx context.Context, arg CreateKubernetesObjectAddressParams) error {
+	_, err := q.db.Exec(ctx, createKubernetesObjectAddress,
+		arg.ObjectID,
+		arg.Kind,
+		arg.Address,
+		arg.NodeName,
+		arg.IpAddressID,
+		arg.MatchStatus,
+		arg.MatchCount,
+	)
+	return err
+}
+
+const createKubernetesObjectHostname = `-- name: CreateKubernetesObjectHostname :exec
+INSERT INTO kubernetes_object_hostnames (object_id, kind, hostname)
+VALUES ($1, $2, $3)
+`
+
+type CreateKubernetesObjectHostnameParams struct {
+	ObjectID pgtype.UUID `json:"object_id"`
+	Kind     string      `json:"kind"`
+	Hostname string      `json:"hostname"`
+}
+
+func (q *Queries) CreateKubernetesObjectHostname(ctx context.Context, arg CreateKubernetesObjectHostnameParams) error {
+	_, err := q.db.Exec(ctx, createKubernetesObjectHostname, arg.ObjectID, arg.Kind, arg.Hostname)
+	return err
+}
+
+const createKubernetesRegisteredAddress = `-- name: CreateKubernetesRegisteredAddress :exec
+INSERT INTO kubernetes_registered_addresses (ip_address_id, source_id, service_uid, namespace, name)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateKubernetesRegisteredAddressParams struct {
+	IpAddressID pgtype.UUID `json:"ip_address_id"`
+	SourceID    pgtype.UUID `json:"source_id"`
+	ServiceUid  string      `json:"service_uid"`
+	Namespace   string      `json:"namespace"`
+	Name        string      `json:"name"`
+}
+
+func (q *Queries) CreateKubernetesRegisteredAddress(ctx context.Context, arg CreateKubernetesRegisteredAddressParams) error {
+	_, err := q.db.Exec(ctx, createKubernetesRegisteredAddress,
+		arg.IpAddressID,
+		arg.SourceID,
+		arg.ServiceUid,
+		arg.Namespace,
+		arg.Name,
+	)
+	return err
+}
+
+const createKubernetesRegisteredIP = `-- name: CreateKubernetesRegisteredIP :one
+INSERT INTO ip_addresses (ip, hostname, subnet_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (ip, subnet_id) DO NOTHING
+RETURNING id
+`
+
+type CreateKubernetesRegisteredIPParams struct {
+	Ip       netip.Addr `json:"ip"`
+	Hostname string     `json:"hostname"`
+	SubnetID int64      `json:"subnet_id"`
+}
+
+// No row is returned when the address was recorded concurrently.
+func (q *Queries) CreateKubernetesRegisteredIP(ctx context.Context, arg CreateKubernetesRegisteredIPParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createKubernetesRegisteredIP, arg.Ip, arg.Hostname, arg.SubnetID)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
 const createKubernetesServiceAddress = `-- name: CreateKubernetesServiceAddress :exec
 INSERT INTO kubernetes_service_addresses (
     service_id, kind, address, ip_mode, ip_address_id, match_status, match_count
@@ -86,6 +277,39 @@ func (q *Queries) CreateKubernetesServicePort(ctx context.Context, arg CreateKub
 	return err
 }
 
+const deleteExpiredKubernetesDiscoveryRuns = `-- name: DeleteExpiredKubernetesDiscoveryRuns :exec
+DELETE FROM kubernetes_discovery_runs
+WHERE source_id = $1 AND started_at < $2
+`
+
+type DeleteExpiredKubernetesDiscoveryRunsParams struct {
+	SourceID  pgtype.UUID        `json:"source_id"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
+}
+
+func (q *Queries) DeleteExpiredKubernetesDiscoveryRuns(ctx context.Context, arg DeleteExpiredKubernetesDiscoveryRunsParams) error {
+	_, err := q.db.Exec(ctx, deleteExpiredKubernetesDiscoveryRuns, arg.SourceID, arg.StartedAt)
+	return err
+}
+
+const deleteKubernetesObjectAddresses = `-- name: DeleteKubernetesObjectAddresses :exec
+DELETE FROM kubernetes_object_addresses WHERE object_id = $1
+`
+
+func (q *Queries) DeleteKubernetesObjectAddresses(ctx context.Context, objectID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteKubernetesObjectAddresses, objectID)
+	return err
+}
+
+const deleteKubernetesObjectHostnames = `-- name: DeleteKubernetesObjectHostnames :exec
+DELETE FROM kubernetes_object_hostnames WHERE object_id = $1
+`
+
+func (q *Queries) DeleteKubernetesObjectHostnames(ctx context.Context, objectID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteKubernetesObjectHostnames, objectID)
+	return err
+}
+
 const deleteKubernetesServiceAddresses = `-- name: DeleteKubernetesServiceAddresses :exec
 DELETE FROM kubernetes_service_addresses WHERE service_id = $1
 `
@@ -113,9 +337,52 @@ func (q *Queries) DeleteKubernetesServicePorts(ctx context.Context, serviceID pg
 	return err
 }
 
-const deleteStaleKubernetesServices = `-- name: DeleteStaleKubernetesServices :exec
+const deleteOrphanedKubernetesRegisteredAddresses = `-- name: DeleteOrphanedKubernetesRegisteredAddresses :execrows
+DELETE FROM ip_addresses
+WHERE id IN (
+    SELECT reg.ip_address_id
+    FROM kubernetes_registered_addresses reg
+    WHERE reg.source_id = $1
+      AND NOT EXISTS (
+          SELECT 1
+          FROM kubernetes_service_addresses a
+          JOIN kubernetes_services svc ON svc.id = a.service_id
+          WHERE a.ip_address_id = reg.ip_address_id
+            AND (svc.active OR (svc.source_id = reg.source_id AND svc.kubernetes_uid = reg.service_uid))
+      )
+)
+`
+
+// Deletes the address records the source registered for Services that no
+// longer report them, because the Service is gone or its address changed,
+// unless an active Service still reports the address.
+func (q *Queries) DeleteOrphanedKubernetesRegisteredAddresses(ctx context.Context, sourceID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrphanedKubernetesRegisteredAddresses, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteStaleKubernetesObjects = `-- name: DeleteStaleKubernetesObjects :exec
+DELETE FROM kubernetes_objects
+WHERE source_id = $1 AND active = false AND stale_at <= $2
+`
+
+type DeleteStaleKubernetesObjectsParams struct {
+	SourceID pgtype.UUID        `json:"source_id"`
+	StaleAt  pgtype.Timestamptz `json:"stale_at"`
+}
+
+func (q *Queries) DeleteStaleKubernetesObjects(ctx context.Context, arg DeleteStaleKubernetesObjectsParams) error {
+	_, err := q.db.Exec(ctx, deleteStaleKubernetesObjects, arg.SourceID, arg.StaleAt)
+	return err
+}
+
+const deleteStaleKubernetesServices = `-- name: DeleteStaleKubernetesServices :many
 DELETE FROM kubernetes_services
 WHERE source_id = $1 AND active = false AND stale_at <= $2
+RETURNING kubernetes_uid, namespace, name
 `
 
 type DeleteStaleKubernetesServicesParams struct {
@@ -123,15 +390,36 @@ type DeleteStaleKubernetesServicesParams struct {
 	StaleAt  pgtype.Timestamptz `json:"stale_at"`
 }
 
-func (q *Queries) DeleteStaleKubernetesServices(ctx context.Context, arg DeleteStaleKubernetesServicesParams) error {
-	_, err := q.db.Exec(ctx, deleteStaleKubernetesServices, arg.SourceID, arg.StaleAt)
-	return err
+type DeleteStaleKubernetesServicesRow struct {
+	KubernetesUid string `json:"kubernetes_uid"`
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+}
+
+func (q *Queries) DeleteStaleKubernetesServices(ctx context.Context, arg DeleteStaleKubernetesServicesParams) ([]DeleteStaleKubernetesServicesRow, error) {
+	rows, err := q.db.Query(ctx, deleteStaleKubernetesServices, arg.SourceID, arg.StaleAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteStaleKubernetesServicesRow
+	for rows.Next() {
+		var i DeleteStaleKubernetesServicesRow
+		if err := rows.Scan(&i.KubernetesUid, &i.Namespace, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const ensureKubernetesSource = `-- name: EnsureKubernetesSource :exec
 INSERT INTO kubernetes_sources (
-    source_key, name, site_id, cluster_domain, namespace_scope
-) VALUES ($1, $2, $3, $4, $5::text[])
+    source_key, name, site_id, cluster_domain, namespace_scope, object_kinds, pod_selector, auto_register
+) VALUES ($1, $2, $3, $4, $5::text[], $6::text[], $7, $8)
 ON CONFLICT (source_key) DO NOTHING
 `
 
@@ -141,6 +429,9 @@ type EnsureKubernetesSourceParams struct {
 	SiteID        pgtype.UUID `json:"site_id"`
 	ClusterDomain string      `json:"cluster_domain"`
 	Column5       []string    `json:"column_5"`
+	Column6       []string    `json:"column_6"`
+	PodSelector   string      `json:"pod_selector"`
+	AutoRegister  string      `json:"auto_register"`
 }
 
 func (q *Queries) EnsureKubernetesSource(ctx context.Context, arg EnsureKubernetesSourceParams) error {
@@ -150,6 +441,9 @@ func (q *Queries) EnsureKubernetesSource(ctx context.Context, arg EnsureKubernet
 		arg.SiteID,
 		arg.ClusterDomain,
 		arg.Column5,
+		arg.Column6,
+		arg.PodSelector,
+		arg.AutoRegister,
 	)
 	return err
 }
@@ -187,8 +481,62 @@ func (q *Queries) FindIPCandidatesBySiteAndAddress(ctx context.Context, arg Find
 	return items, nil
 }
 
+const findKubernetesRegistrationSubnet = `-- name: FindKubernetesRegistrationSubnet :one
+SELECT id, cidr
+FROM subnets
+WHERE site_id = $1 AND cidr >>= $2::inet
+ORDER BY masklen(cidr) DESC, id
+LIMIT 1
+`
+
+type FindKubernetesRegistrationSubnetParams struct {
+	SiteID  pgtype.UUID `json:"site_id"`
+	Address netip.Addr  `json:"address"`
+}
+
+type FindKubernetesRegistrationSubnetRow struct {
+	ID   int64        `json:"id"`
+	Cidr netip.Prefix `json:"cidr"`
+}
+
+// The most specific subnet of the site that contains the address.
+func (q *Queries) FindKubernetesRegistrationSubnet(ctx context.Context, arg FindKubernetesRegistrationSubnetParams) (FindKubernetesRegistrationSubnetRow, error) {
+	row := q.db.QueryRow(ctx, findKubernetesRegistrationSubnet, arg.SiteID, arg.Address)
+	var i FindKubernetesRegistrationSubnetRow
+	err := row.Scan(&i.ID, &i.Cidr)
+	return i, err
+}
+
+const getKubernetesServiceHistoryState = `-- name: GetKubernetesServiceHistoryState :one
+SELECT svc.active,
+       ARRAY(
+           SELECT DISTINCT a.address FROM kubernetes_service_addresses a
+           WHERE a.service_id = svc.id ORDER BY a.address
+       )::inet[] AS addresses
+FROM kubernetes_services svc
+WHERE svc.source_id = $1 AND svc.kubernetes_uid = $2
+`
+
+type GetKubernetesServiceHistoryStateParams struct {
+	SourceID      pgtype.UUID `json:"source_id"`
+	KubernetesUid string      `json:"kubernetes_uid"`
+}
+
+type GetKubernetesServiceHistoryStateRow struct {
+	Active    bool         `json:"active"`
+	Addresses []netip.Addr `json:"addresses"`
+}
+
+// The state a Service is compared against before it is replaced.
+func (q *Queries) GetKubernetesServiceHistoryState(ctx context.Context, arg GetKubernetesServiceHistoryStateParams) (GetKubernetesServiceHistoryStateRow, error) {
+	row := q.db.QueryRow(ctx, getKubernetesServiceHistoryState, arg.SourceID, arg.KubernetesUid)
+	var i GetKubernetesServiceHistoryStateRow
+	err := row.Scan(&i.Active, &i.Addresses)
+	return i, err
+}
+
 const getKubernetesSourceByKey = `-- name: GetKubernetesSourceByKey :one
-SELECT id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count FROM kubernetes_sources WHERE source_key = $1
+SELECT id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count, managed, auth_mode, kubeconfig_ciphertext, kubeconfig_context, reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds, object_kinds, pod_selector, object_count, auto_register, object_error FROM kubernetes_sources WHERE source_key = $1
 `
 
 func (q *Queries) GetKubernetesSourceByKey(ctx context.Context, sourceKey string) (KubernetesSource, error) {
@@ -211,6 +559,18 @@ func (q *Queries) GetKubernetesSourceByKey(ctx context.Context, sourceKey string
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.NoUsableIpCount,
+		&i.Managed,
+		&i.AuthMode,
+		&i.KubeconfigCiphertext,
+		&i.KubeconfigContext,
+		&i.ReconcileIntervalSeconds,
+		&i.RequestTimeoutSeconds,
+		&i.StaleRetentionSeconds,
+		&i.ObjectKinds,
+		&i.PodSelector,
+		&i.ObjectCount,
+		&i.AutoRegister,
+		&i.ObjectError,
 	)
 	return i, err
 }
@@ -278,6 +638,306 @@ func (q *Queries) ListActiveKubernetesServicesBySubnet(ctx context.Context, id i
 	return items, nil
 }
 
+const listKubernetesDiscoveryEvents = `-- name: ListKubernetesDiscoveryEvents :many
+SELECT id, run_id, source_id, service_uid, namespace, name, change, previous_addresses, addresses, occurred_at
+FROM kubernetes_discovery_events
+WHERE source_id = $1
+  AND ($3::bigint IS NULL OR run_id = $3)
+ORDER BY occurred_at DESC, id DESC
+LIMIT $2
+`
+
+type ListKubernetesDiscoveryEventsParams struct {
+	SourceID pgtype.UUID `json:"source_id"`
+	Limit    int32       `json:"limit"`
+	RunID    pgtype.Int8 `json:"run_id"`
+}
+
+// A null run_id lists the changes of every run.
+func (q *Queries) ListKubernetesDiscoveryEvents(ctx context.Context, arg ListKubernetesDiscoveryEventsParams) ([]KubernetesDiscoveryEvent, error) {
+	rows, err := q.db.Query(ctx, listKubernetesDiscoveryEvents, arg.SourceID, arg.Limit, arg.RunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KubernetesDiscoveryEvent
+	for rows.Next() {
+		var i KubernetesDiscoveryEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.SourceID,
+			&i.ServiceUid,
+			&i.Namespace,
+			&i.Name,
+			&i.Change,
+			&i.PreviousAddresses,
+			&i.Addresses,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKubernetesDiscoveryRuns = `-- name: ListKubernetesDiscoveryRuns :many
+SELECT r.id, r.source_id, r.started_at, r.finished_at, r.service_count, r.matched_count, r.unmatched_count, r.ambiguous_count, r.no_usable_ip_count, r.error,
+       (SELECT count(*) FROM kubernetes_discovery_events e WHERE e.run_id = r.id)::integer AS change_count
+FROM kubernetes_discovery_runs r
+WHERE r.source_id = $1
+ORDER BY r.started_at DESC, r.id DESC
+LIMIT $2
+`
+
+type ListKubernetesDiscoveryRunsParams struct {
+	SourceID pgtype.UUID `json:"source_id"`
+	Limit    int32       `json:"limit"`
+}
+
+type ListKubernetesDiscoveryRunsRow struct {
+	ID              int64              `json:"id"`
+	SourceID        pgtype.UUID        `json:"source_id"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	FinishedAt      pgtype.Timestamptz `json:"finished_at"`
+	ServiceCount    int32              `json:"service_count"`
+	MatchedCount    int32              `json:"matched_count"`
+	UnmatchedCount  int32              `json:"unmatched_count"`
+	AmbiguousCount  int32              `json:"ambiguous_count"`
+	NoUsableIpCount int32              `json:"no_usable_ip_count"`
+	Error           string             `json:"error"`
+	ChangeCount     int32              `json:"change_count"`
+}
+
+func (q *Queries) ListKubernetesDiscoveryRuns(ctx context.Context, arg ListKubernetesDiscoveryRunsParams) ([]ListKubernetesDiscoveryRunsRow, error) {
+	rows, err := q.db.Query(ctx, listKubernetesDiscoveryRuns, arg.SourceID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListKubernetesDiscoveryRunsRow
+	for rows.Next() {
+		var i ListKubernetesDiscoveryRunsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SourceID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.ServiceCount,
+			&i.MatchedCount,
+			&i.UnmatchedCount,
+			&i.AmbiguousCount,
+			&i.NoUsableIpCount,
+			&i.Error,
+			&i.ChangeCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKubernetesObjectAddressesBySubnet = `-- name: ListKubernetesObjectAddressesBySubnet :many
+SELECT obj.id AS object_id,
+       src.source_key,
+       src.name AS source_name,
+       obj.kind,
+       obj.kubernetes_uid,
+       obj.namespace,
+       obj.name,
+       obj.host_network,
+       obj.observed_at,
+       a.kind AS address_kind,
+       a.address,
+       a.node_name,
+       CASE
+           WHEN a.match_status = 'matched' AND matched_subnet.id IS NULL THEN 'unmatched'
+           ELSE a.match_status
+       END::text AS match_status,
+       CASE
+           WHEN a.match_status = 'matched' AND matched_subnet.id IS NULL THEN 0
+           ELSE a.match_count
+       END::integer AS match_count,
+       CASE
+           WHEN a.match_status = 'matched' AND matched_subnet.id IS NOT NULL THEN a.ip_address_id
+           ELSE NULL
+       END::uuid AS ip_address_id,
+       matched_subnet.id AS matched_subnet_id,
+       ARRAY(
+           SELECT DISTINCT svc.namespace || '/' || svc.name
+           FROM kubernetes_service_addresses lb
+           JOIN kubernetes_services svc ON svc.id = lb.service_id
+           WHERE svc.source_id = obj.source_id
+             AND svc.active = true
+             AND lb.kind = 'load_balancer'
+             AND lb.address = a.address
+           ORDER BY 1
+       )::text[] AS load_balancer_services
+FROM subnets subnet
+JOIN kubernetes_sources src ON src.site_id = subnet.site_id
+JOIN kubernetes_objects obj ON obj.source_id = src.id AND obj.active = true
+JOIN kubernetes_object_addresses a ON a.object_id = obj.id AND a.address <<= subnet.cidr
+LEFT JOIN ip_addresses matched_ip ON matched_ip.id = a.ip_address_id
+LEFT JOIN subnets matched_subnet ON matched_subnet.id = matched_ip.subnet_id
+                                AND matched_subnet.site_id = src.site_id
+WHERE subnet.id = $1
+ORDER BY src.source_key, obj.kind, obj.namespace, obj.name, obj.kubernetes_uid, a.kind, a.address
+`
+
+type ListKubernetesObjectAddressesBySubnetRow struct {
+	ObjectID             pgtype.UUID        `json:"object_id"`
+	SourceKey            string             `json:"source_key"`
+	SourceName           string             `json:"source_name"`
+	Kind                 string             `json:"kind"`
+	KubernetesUid        string             `json:"kubernetes_uid"`
+	Namespace            string             `json:"namespace"`
+	Name                 string             `json:"name"`
+	HostNetwork          bool               `json:"host_network"`
+	ObservedAt           pgtype.Timestamptz `json:"observed_at"`
+	AddressKind          string             `json:"address_kind"`
+	Address              netip.Addr         `json:"address"`
+	NodeName             string             `json:"node_name"`
+	MatchStatus          string             `json:"match_status"`
+	MatchCount           int32              `json:"match_count"`
+	IpAddressID          pgtype.UUID        `json:"ip_address_id"`
+	MatchedSubnetID      pgtype.Int8        `json:"matched_subnet_id"`
+	LoadBalancerServices []string           `json:"load_balancer_services"`
+}
+
+// Every observed address inside the subnet's CIDR, including the ones no IP
+// address record matches. load_balancer_services names the Services that
+// have the address as a load-balancer IP, such as an ingress controller's.
+func (q *Queries) ListKubernetesObjectAddressesBySubnet(ctx context.Context, id int64) ([]ListKubernetesObjectAddressesBySubnetRow, error) {
+	rows, err := q.db.Query(ctx, listKubernetesObjectAddressesBySubnet, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListKubernetesObjectAddressesBySubnetRow
+	for rows.Next() {
+		var i ListKubernetesObjectAddressesBySubnetRow
+		if err := rows.Scan(
+			&i.ObjectID,
+			&i.SourceKey,
+			&i.SourceName,
+			&i.Kind,
+			&i.KubernetesUid,
+			&i.Namespace,
+			&i.Name,
+			&i.HostNetwork,
+			&i.ObservedAt,
+			&i.AddressKind,
+			&i.Address,
+			&i.NodeName,
+			&i.MatchStatus,
+			&i.MatchCount,
+			&i.IpAddressID,
+			&i.MatchedSubnetID,
+			&i.LoadBalancerServices,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKubernetesObjectHostnamesBySubnet = `-- name: ListKubernetesObjectHostnamesBySubnet :many
+SELECT hostname.object_id,
+       hostname.kind,
+       hostname.hostname
+FROM subnets subnet
+JOIN kubernetes_sources src ON src.site_id = subnet.site_id
+JOIN kubernetes_objects obj ON obj.source_id = src.id AND obj.active = true
+JOIN kubernetes_object_hostnames hostname ON hostname.object_id = obj.id
+WHERE subnet.id = $1
+ORDER BY hostname.object_id, hostname.kind, hostname.hostname
+`
+
+type ListKubernetesObjectHostnamesBySubnetRow struct {
+	ObjectID pgtype.UUID `json:"object_id"`
+	Kind     string      `json:"kind"`
+	Hostname string      `json:"hostname"`
+}
+
+func (q *Queries) ListKubernetesObjectHostnamesBySubnet(ctx context.Context, id int64) ([]ListKubernetesObjectHostnamesBySubnetRow, error) {
+	rows, err := q.db.Query(ctx, listKubernetesObjectHostnamesBySubnet, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListKubernetesObjectHostnamesBySubnetRow
+	for rows.Next() {
+		var i ListKubernetesObjectHostnamesBySubnetRow
+		if err := rows.Scan(&i.ObjectID, &i.Kind, &i.Hostname); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKubernetesRegisteredAddressesBySubnet = `-- name: ListKubernetesRegisteredAddressesBySubnet :many
+SELECT reg.ip_address_id, reg.service_uid, reg.namespace, reg.name, reg.registered_at,
+       src.source_key, src.name AS source_name
+FROM kubernetes_registered_addresses reg
+JOIN ip_addresses ip ON ip.id = reg.ip_address_id
+JOIN kubernetes_sources src ON src.id = reg.source_id
+WHERE ip.subnet_id = $1
+`
+
+type ListKubernetesRegisteredAddressesBySubnetRow struct {
+	IpAddressID  pgtype.UUID        `json:"ip_address_id"`
+	ServiceUid   string             `json:"service_uid"`
+	Namespace    string             `json:"namespace"`
+	Name         string             `json:"name"`
+	RegisteredAt pgtype.Timestamptz `json:"registered_at"`
+	SourceKey    string             `json:"source_key"`
+	SourceName   string             `json:"source_name"`
+}
+
+func (q *Queries) ListKubernetesRegisteredAddressesBySubnet(ctx context.Context, subnetID int64) ([]ListKubernetesRegisteredAddressesBySubnetRow, error) {
+	rows, err := q.db.Query(ctx, listKubernetesRegisteredAddressesBySubnet, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListKubernetesRegisteredAddressesBySubnetRow
+	for rows.Next() {
+		var i ListKubernetesRegisteredAddressesBySubnetRow
+		if err := rows.Scan(
+			&i.IpAddressID,
+			&i.ServiceUid,
+			&i.Namespace,
+			&i.Name,
+			&i.RegisteredAt,
+			&i.SourceKey,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listKubernetesServiceAddressesBySubnet = `-- name: ListKubernetesServiceAddressesBySubnet :many
 SELECT a.service_id,
        a.address,
@@ -440,40 +1100,20 @@ func (q *Queries) ListKubernetesServicePortsBySubnet(ctx context.Context, id int
 }
 
 const listKubernetesSourceStatuses = `-- name: ListKubernetesSourceStatuses :many
-SELECT source_key, name, site_id, cluster_domain, namespace_scope,
-       last_attempt_at, last_success_at, last_error,
-       service_count, matched_count, unmatched_count, ambiguous_count,
-       no_usable_ip_count
-FROM kubernetes_sources
-ORDER BY source_key
+SELECT id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count, managed, auth_mode, kubeconfig_ciphertext, kubeconfig_context, reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds, object_kinds, pod_selector, object_count, auto_register, object_error FROM kubernetes_sources ORDER BY source_key
 `
 
-type ListKubernetesSourceStatusesRow struct {
-	SourceKey       string             `json:"source_key"`
-	Name            string             `json:"name"`
-	SiteID          pgtype.UUID        `json:"site_id"`
-	ClusterDomain   string             `json:"cluster_domain"`
-	NamespaceScope  []string           `json:"namespace_scope"`
-	LastAttemptAt   pgtype.Timestamptz `json:"last_attempt_at"`
-	LastSuccessAt   pgtype.Timestamptz `json:"last_success_at"`
-	LastError       string             `json:"last_error"`
-	ServiceCount    int32              `json:"service_count"`
-	MatchedCount    int32              `json:"matched_count"`
-	UnmatchedCount  int32              `json:"unmatched_count"`
-	AmbiguousCount  int32              `json:"ambiguous_count"`
-	NoUsableIpCount int32              `json:"no_usable_ip_count"`
-}
-
-func (q *Queries) ListKubernetesSourceStatuses(ctx context.Context) ([]ListKubernetesSourceStatusesRow, error) {
+func (q *Queries) ListKubernetesSourceStatuses(ctx context.Context) ([]KubernetesSource, error) {
 	rows, err := q.db.Query(ctx, listKubernetesSourceStatuses)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListKubernetesSourceStatusesRow
+	var items []KubernetesSource
 	for rows.Next() {
-		var i ListKubernetesSourceStatusesRow
+		var i KubernetesSource
 		if err := rows.Scan(
+			&i.ID,
 			&i.SourceKey,
 			&i.Name,
 			&i.SiteID,
@@ -486,7 +1126,94 @@ func (q *Queries) ListKubernetesSourceStatuses(ctx context.Context) ([]ListKuber
 			&i.MatchedCount,
 			&i.UnmatchedCount,
 			&i.AmbiguousCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 			&i.NoUsableIpCount,
+			&i.Managed,
+			&i.AuthMode,
+			&i.KubeconfigCiphertext,
+			&i.KubeconfigContext,
+			&i.ReconcileIntervalSeconds,
+			&i.RequestTimeoutSeconds,
+			&i.StaleRetentionSeconds,
+			&i.ObjectKinds,
+			&i.PodSelector,
+			&i.ObjectCount,
+			&i.AutoRegister,
+			&i.ObjectError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchedKubernetesObjectsBySubnet = `-- name: ListMatchedKubernetesObjectsBySubnet :many
+SELECT a.ip_address_id,
+       obj.id AS object_id,
+       src.source_key,
+       src.name AS source_name,
+       obj.kind,
+       obj.kubernetes_uid,
+       obj.namespace,
+       obj.name,
+       obj.host_network,
+       obj.observed_at,
+       a.kind AS address_kind,
+       a.node_name
+FROM kubernetes_object_addresses a
+JOIN kubernetes_objects obj ON obj.id = a.object_id
+JOIN kubernetes_sources src ON src.id = obj.source_id
+JOIN ip_addresses ip ON ip.id = a.ip_address_id
+JOIN subnets subnet ON subnet.id = ip.subnet_id
+WHERE ip.subnet_id = $1
+  AND subnet.site_id = src.site_id
+  AND obj.active = true
+  AND a.match_status = 'matched'
+ORDER BY a.ip_address_id, src.source_key, obj.kind, obj.namespace, obj.name, obj.kubernetes_uid, a.kind
+`
+
+type ListMatchedKubernetesObjectsBySubnetRow struct {
+	IpAddressID   pgtype.UUID        `json:"ip_address_id"`
+	ObjectID      pgtype.UUID        `json:"object_id"`
+	SourceKey     string             `json:"source_key"`
+	SourceName    string             `json:"source_name"`
+	Kind          string             `json:"kind"`
+	KubernetesUid string             `json:"kubernetes_uid"`
+	Namespace     string             `json:"namespace"`
+	Name          string             `json:"name"`
+	HostNetwork   bool               `json:"host_network"`
+	ObservedAt    pgtype.Timestamptz `json:"observed_at"`
+	AddressKind   string             `json:"address_kind"`
+	NodeName      string             `json:"node_name"`
+}
+
+func (q *Queries) ListMatchedKubernetesObjectsBySubnet(ctx context.Context, subnetID int64) ([]ListMatchedKubernetesObjectsBySubnetRow, error) {
+	rows, err := q.db.Query(ctx, listMatchedKubernetesObjectsBySubnet, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMatchedKubernetesObjectsBySubnetRow
+	for rows.Next() {
+		var i ListMatchedKubernetesObjectsBySubnetRow
+		if err := rows.Scan(
+			&i.IpAddressID,
+			&i.ObjectID,
+			&i.SourceKey,
+			&i.SourceName,
+			&i.Kind,
+			&i.KubernetesUid,
+			&i.Namespace,
+			&i.Name,
+			&i.HostNetwork,
+			&i.ObservedAt,
+			&i.AddressKind,
+			&i.NodeName,
 		); err != nil {
 			return nil, err
 		}
@@ -588,22 +1315,156 @@ func (q *Queries) ListMatchedKubernetesServicesBySubnet(ctx context.Context, sub
 	return items, nil
 }
 
-const markMissingKubernetesServicesInactive = `-- name: MarkMissingKubernetesServicesInactive :exec
+const markKubernetesServicesInactive = `-- name: MarkKubernetesServicesInactive :many
 UPDATE kubernetes_services
 SET active = false, stale_at = $3, updated_at = now()
+WHERE source_id = $1
+  AND active = true
+  AND kubernetes_uid = ANY($2::text[])
+RETURNING kubernetes_uid, namespace, name,
+    ARRAY(
+        SELECT DISTINCT a.address FROM kubernetes_service_addresses a
+        WHERE a.service_id = kubernetes_services.id ORDER BY a.address
+    )::inet[] AS addresses
+`
+
+type MarkKubernetesServicesInactiveParams struct {
+	SourceID pgtype.UUID        `json:"source_id"`
+	Column2  []string           `json:"column_2"`
+	StaleAt  pgtype.Timestamptz `json:"stale_at"`
+}
+
+type MarkKubernetesServicesInactiveRow struct {
+	KubernetesUid string       `json:"kubernetes_uid"`
+	Namespace     string       `json:"namespace"`
+	Name          string       `json:"name"`
+	Addresses     []netip.Addr `json:"addresses"`
+}
+
+func (q *Queries) MarkKubernetesServicesInactive(ctx context.Context, arg MarkKubernetesServicesInactiveParams) ([]MarkKubernetesServicesInactiveRow, error) {
+	rows, err := q.db.Query(ctx, markKubernetesServicesInactive, arg.SourceID, arg.Column2, arg.StaleAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarkKubernetesServicesInactiveRow
+	for rows.Next() {
+		var i MarkKubernetesServicesInactiveRow
+		if err := rows.Scan(
+			&i.KubernetesUid,
+			&i.Namespace,
+			&i.Name,
+			&i.Addresses,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markMissingKubernetesObjectsInactive = `-- name: MarkMissingKubernetesObjectsInactive :exec
+UPDATE kubernetes_objects
+SET active = false, stale_at = $3, updated_at = now()
 WHERE source_id = $1
   AND active = true
   AND NOT (kubernetes_uid = ANY($2::text[]))
 `
 
+type MarkMissingKubernetesObjectsInactiveParams struct {
+	SourceID pgtype.UUID        `json:"source_id"`
+	Column2  []string           `json:"column_2"`
+	StaleAt  pgtype.Timestamptz `json:"stale_at"`
+}
+
+func (q *Queries) MarkMissingKubernetesObjectsInactive(ctx context.Context, arg MarkMissingKubernetesObjectsInactiveParams) error {
+	_, err := q.db.Exec(ctx, markMissingKubernetesObjectsInactive, arg.SourceID, arg.Column2, arg.StaleAt)
+	return err
+}
+
+const markMissingKubernetesServicesInactive = `-- name: MarkMissingKubernetesServicesInactive :many
+UPDATE kubernetes_services
+SET active = false, stale_at = $3, updated_at = now()
+WHERE source_id = $1
+  AND active = true
+  AND NOT (kubernetes_uid = ANY($2::text[]))
+RETURNING kubernetes_uid, namespace, name,
+    ARRAY(
+        SELECT DISTINCT a.address FROM kubernetes_service_addresses a
+        WHERE a.service_id = kubernetes_services.id ORDER BY a.address
+    )::inet[] AS addresses
+`
+
 type MarkMissingKubernetesServicesInactiveParams struct {
 	SourceID pgtype.UUID        `json:"source_id"`
 	Column2  []string           `json:"column_2"`
 	StaleAt  pgtype.Timestamptz `json:"stale_at"`
 }
 
-func (q *Queries) MarkMissingKubernetesServicesInactive(ctx context.Context, arg MarkMissingKubernetesServicesInactiveParams) error {
-	_, err := q.db.Exec(ctx, markMissingKubernetesServicesInactive, arg.SourceID, arg.Column2, arg.StaleAt)
+type MarkMissingKubernetesServicesInactiveRow struct {
+	KubernetesUid string       `json:"kubernetes_uid"`
+	Namespace     string       `json:"namespace"`
+	Name          string       `json:"name"`
+	Addresses     []netip.Addr `json:"addresses"`
+}
+
+func (q *Queries) MarkMissingKubernetesServicesInactive(ctx context.Context, arg MarkMissingKubernetesServicesInactiveParams) ([]MarkMissingKubernetesServicesInactiveRow, error) {
+	rows, err := q.db.Query(ctx, markMissingKubernetesServicesInactive, arg.SourceID, arg.Column2, arg.StaleAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarkMissingKubernetesServicesInactiveRow
+	for rows.Next() {
+		var i MarkMissingKubernetesServicesInactiveRow
+		if err := rows.Scan(
+			&i.KubernetesUid,
+			&i.Namespace,
+			&i.Name,
+			&i.Addresses,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordKubernetesObjectCount = `-- name: RecordKubernetesObjectCount :exec
+UPDATE kubernetes_sources
+SET object_count = $2, object_error = '', updated_at = now()
+WHERE id = $1
+`
+
+type RecordKubernetesObjectCountParams struct {
+	ID          pgtype.UUID `json:"id"`
+	ObjectCount int32       `json:"object_count"`
+}
+
+func (q *Queries) RecordKubernetesObjectCount(ctx context.Context, arg RecordKubernetesObjectCountParams) error {
+	_, err := q.db.Exec(ctx, recordKubernetesObjectCount, arg.ID, arg.ObjectCount)
+	return err
+}
+
+const recordKubernetesObjectFailure = `-- name: RecordKubernetesObjectFailure :exec
+UPDATE kubernetes_sources
+SET object_error = $2, updated_at = now()
+WHERE source_key = $1
+`
+
+type RecordKubernetesObjectFailureParams struct {
+	SourceKey   string `json:"source_key"`
+	ObjectError string `json:"object_error"`
+}
+
+func (q *Queries) RecordKubernetesObjectFailure(ctx context.Context, arg RecordKubernetesObjectFailureParams) error {
+	_, err := q.db.Exec(ctx, recordKubernetesObjectFailure, arg.SourceKey, arg.ObjectError)
 	return err
 }
 
@@ -672,6 +1533,65 @@ func (q *Queries) TryKubernetesSourceLock(ctx context.Context, hashtextextended
 	return pg_try_advisory_xact_lock, err
 }
 
+const upsertKubernetesObject = `-- name: UpsertKubernetesObject :one
+INSERT INTO kubernetes_objects (
+    source_id, kind, kubernetes_uid, namespace, name, host_network,
+    resource_version, observed_at, active, stale_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true, NULL)
+ON CONFLICT (source_id, kubernetes_uid) DO UPDATE SET
+    kind = EXCLUDED.kind,
+    namespace = EXCLUDED.namespace,
+    name = EXCLUDED.name,
+    host_network = EXCLUDED.host_network,
+    resource_version = EXCLUDED.resource_version,
+    observed_at = EXCLUDED.observed_at,
+    active = true,
+    stale_at = NULL,
+    updated_at = now()
+RETURNING id, source_id, kind, kubernetes_uid, namespace, name, host_network, resource_version, observed_at, active, stale_at, created_at, updated_at
+`
+
+type UpsertKubernetesObjectParams struct {
+	SourceID        pgtype.UUID        `json:"source_id"`
+	Kind            string             `json:"kind"`
+	KubernetesUid   string             `json:"kubernetes_uid"`
+	Namespace       string             `json:"namespace"`
+	Name            string             `json:"name"`
+	HostNetwork     bool               `json:"host_network"`
+	ResourceVersion string             `json:"resource_version"`
+	ObservedAt      pgtype.Timestamptz `json:"observed_at"`
+}
+
+func (q *Queries) UpsertKubernetesObject(ctx context.Context, arg UpsertKubernetesObjectParams) (KubernetesObject, error) {
+	row := q.db.QueryRow(ctx, upsertKubernetesObject,
+		arg.SourceID,
+		arg.Kind,
+		arg.KubernetesUid,
+		arg.Namespace,
+		arg.Name,
+		arg.HostNetwork,
+		arg.ResourceVersion,
+		arg.ObservedAt,
+	)
+	var i KubernetesObject
+	err := row.Scan(
+		&i.ID,
+		&i.SourceID,
+		&i.Kind,
+		&i.KubernetesUid,
+		&i.Namespace,
+		&i.Name,
+		&i.HostNetwork,
+		&i.ResourceVersion,
+		&i.ObservedAt,
+		&i.Active,
+		&i.StaleAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const upsertKubernetesService = `-- name: UpsertKubernetesService :one
 INSERT INTO kubernetes_services (
     source_id, kubernetes_uid, namespace, name, service_type,
@@ -737,15 +1657,19 @@ func (q *Queries) UpsertKubernetesService(ctx context.Context, arg UpsertKuberne
 
 const upsertKubernetesSource = `-- name: UpsertKubernetesSource :one
 INSERT INTO kubernetes_sources (
-    source_key, name, site_id, cluster_domain, namespace_scope, updated_at
-) VALUES ($1, $2, $3, $4, $5::text[], now())
+    source_key, name, site_id, cluster_domain, namespace_scope, object_kinds, pod_selector, auto_register, updated_at
+) VALUES ($1, $2, $3, $4, $5::text[], $6::text[], $7, $8, now())
 ON CONFLICT (source_key) DO UPDATE SET
     name = EXCLUDED.name,
     site_id = EXCLUDED.site_id,
     cluster_domain = EXCLUDED.cluster_domain,
     namespace_scope = EXCLUDED.namespace_scope,
+    object_kinds = EXCLUDED.object_kinds,
+    pod_selector = EXCLUDED.pod_selector,
+    auto_register = EXCLUDED.auto_register,
     updated_at = now()
-RETURNING id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count
+WHERE NOT kubernetes_sources.managed
+RETURNING id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count, managed, auth_mode, kubeconfig_ciphertext, kubeconfig_context, reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds, object_kinds, pod_selector, object_count, auto_register, object_error
 `
 
 type UpsertKubernetesSourceParams struct {
@@ -754,6 +1678,9 @@ type UpsertKubernetesSourceParams struct {
 	SiteID        pgtype.UUID `json:"site_id"`
 	ClusterDomain string      `json:"cluster_domain"`
 	Column5       []string    `json:"column_5"`
+	Column6       []string    `json:"column_6"`
+	PodSelector   string      `json:"pod_selector"`
+	AutoRegister  string      `json:"auto_register"`
 }
 
 func (q *Queries) UpsertKubernetesSource(ctx context.Context, arg UpsertKubernetesSourceParams) (KubernetesSource, error) {
@@ -763,6 +1690,9 @@ func (q *Queries) UpsertKubernetesSource(ctx context.Context, arg UpsertKubernet
 		arg.SiteID,
 		arg.ClusterDomain,
 		arg.Column5,
+		arg.Column6,
+		arg.PodSelector,
+		arg.AutoRegister,
 	)
 	var i KubernetesSource
 	err := row.Scan(
@@ -782,6 +1712,18 @@ func (q *Queries) UpsertKubernetesSource(ctx context.Context, arg UpsertKubernet
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.NoUsableIpCount,
+		&i.Managed,
+		&i.AuthMode,
+		&i.KubeconfigCiphertext,
+		&i.KubeconfigContext,
+		&i.ReconcileIntervalSeconds,
+		&i.RequestTimeoutSeconds,
+		&i.StaleRetentionSeconds,
+		&i.ObjectKinds,
+		&i.PodSelector,
+		&i.ObjectCount,
+		&i.AutoRegister,
+		&i.ObjectError,
 	)
 	return i, err
 }
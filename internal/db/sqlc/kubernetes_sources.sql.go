@@ -0,0 +1,263 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: kubernetes_sources.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createManagedKubernetesSource = `-- name: CreateManagedKubernetesSource :one
+INSERT INTO kubernetes_sources (
+    source_key, name, site_id, cluster_domain, namespace_scope, managed,
+    auth_mode, kubeconfig_ciphertext, kubeconfig_context,
+    reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds,
+    object_kinds, pod_selector, auto_register
+) VALUES ($1, $2, $3, $4, $5::text[], true, $6, $7, $8, $9, $10, $11, $12::text[], $13, $14)
+ON CONFLICT (source_key) DO UPDATE SET
+    name = EXCLUDED.name,
+    site_id = EXCLUDED.site_id,
+    cluster_domain = EXCLUDED.cluster_domain,
+    namespace_scope = EXCLUDED.namespace_scope,
+    managed = true,
+    auth_mode = EXCLUDED.auth_mode,
+    kubeconfig_ciphertext = EXCLUDED.kubeconfig_ciphertext,
+    kubeconfig_context = EXCLUDED.kubeconfig_context,
+    reconcile_interval_seconds = EXCLUDED.reconcile_interval_seconds,
+    request_timeout_seconds = EXCLUDED.request_timeout_seconds,
+    stale_retention_seconds = EXCLUDED.stale_retention_seconds,
+    object_kinds = EXCLUDED.object_kinds,
+    pod_selector = EXCLUDED.pod_selector,
+    auto_register = EXCLUDED.auto_register,
+    updated_at = now()
+WHERE NOT kubernetes_sources.managed
+RETURNING id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count, managed, auth_mode, kubeconfig_ciphertext, kubeconfig_context, reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds, object_kinds, pod_selector, object_count, auto_register, object_error
+`
+
+type CreateManagedKubernetesSourceParams struct {
+	SourceKey                string      `json:"source_key"`
+	Name                     string      `json:"name"`
+	SiteID                   pgtype.UUID `json:"site_id"`
+	ClusterDomain            string      `json:"cluster_domain"`
+	Column5                  []string    `json:"column_5"`
+	AuthMode                 string      `json:"auth_mode"`
+	KubeconfigCiphertext     []byte      `json:"kubeconfig_ciphertext"`
+	KubeconfigContext        string      `json:"kubeconfig_context"`
+	ReconcileIntervalSeconds int32       `json:"reconcile_interval_seconds"`
+	RequestTimeoutSeconds    int32       `json:"request_timeout_seconds"`
+	StaleRetentionSeconds    int32       `json:"stale_retention_seconds"`
+	Column12                 []string    `json:"column_12"`
+	PodSelector              string      `json:"pod_selector"`
+	AutoRegister             string      `json:"auto_register"`
+}
+
+// A source first seen through configuration is taken over, keeping its
+// observations; an existing managed source is left alone and no row is
+// returned.
+func (q *Queries) CreateManagedKubernetesSource(ctx context.Context, arg CreateManagedKubernetesSourceParams) (KubernetesSource, error) {
+	row := q.db.QueryRow(ctx, createManagedKubernetesSource,
+		arg.SourceKey,
+		arg.Name,
+		arg.SiteID,
+		arg.ClusterDomain,
+		arg.Column5,
+		arg.AuthMode,
+		arg.KubeconfigCiphertext,
+		arg.KubeconfigContext,
+		arg.ReconcileIntervalSeconds,
+		arg.RequestTimeoutSeconds,
+		arg.StaleRetentionSeconds,
+		arg.Column12,
+		arg.PodSelector,
+		arg.AutoRegister,
+	)
+	var i KubernetesSource
+	err := row.Scan(
+		&i.ID,
+		&i.SourceKey,
+		&i.Name,
+		&i.SiteID,
+		&i.ClusterDomain,
+		&i.NamespaceScope,
+		&i.LastAttemptAt,
+		&i.LastSuccessAt,
+		&i.LastError,
+		&i.ServiceCount,
+		&i.MatchedCount,
+		&i.UnmatchedCount,
+		&i.AmbiguousCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NoUsableIpCount,
+		&i.Managed,
+		&i.AuthMode,
+		&i.KubeconfigCiphertext,
+		&i.KubeconfigContext,
+		&i.ReconcileIntervalSeconds,
+		&i.RequestTimeoutSeconds,
+		&i.StaleRetentionSeconds,
+		&i.ObjectKinds,
+		&i.PodSelector,
+		&i.ObjectCount,
+		&i.AutoRegister,
+		&i.ObjectError,
+	)
+	return i, err
+}
+
+const deleteManagedKubernetesSource = `-- name: DeleteManagedKubernetesSource :execrows
+DELETE FROM kubernetes_sources WHERE source_key = $1 AND managed
+`
+
+func (q *Queries) DeleteManagedKubernetesSource(ctx context.Context, sourceKey string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteManagedKubernetesSource, sourceKey)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listManagedKubernetesSources = `-- name: ListManagedKubernetesSources :many
+SELECT id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count, managed, auth_mode, kubeconfig_ciphertext, kubeconfig_context, reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds, object_kinds, pod_selector, object_count, auto_register, object_error FROM kubernetes_sources WHERE managed ORDER BY source_key
+`
+
+func (q *Queries) ListManagedKubernetesSources(ctx context.Context) ([]KubernetesSource, error) {
+	rows, err := q.db.Query(ctx, listManagedKubernetesSources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KubernetesSource
+	for rows.Next() {
+		var i KubernetesSource
+		if err := rows.Scan(
+			&i.ID,
+			&i.SourceKey,
+			&i.Name,
+			&i.SiteID,
+			&i.ClusterDomain,
+			&i.NamespaceScope,
+			&i.LastAttemptAt,
+			&i.LastSuccessAt,
+			&i.LastError,
+			&i.ServiceCount,
+			&i.MatchedCount,
+			&i.UnmatchedCount,
+			&i.AmbiguousCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NoUsableIpCount,
+			&i.Managed,
+			&i.AuthMode,
+			&i.KubeconfigCiphertext,
+			&i.KubeconfigContext,
+			&i.ReconcileIntervalSeconds,
+			&i.RequestTimeoutSeconds,
+			&i.StaleRetentionSeconds,
+			&i.ObjectKinds,
+			&i.PodSelector,
+			&i.ObjectCount,
+			&i.AutoRegister,
+			&i.ObjectError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateManagedKubernetesSource = `-- name: UpdateManagedKubernetesSource :one
+UPDATE kubernetes_sources
+SET name = $2,
+    site_id = $3,
+    cluster_domain = $4,
+    namespace_scope = $5::text[],
+    auth_mode = $6,
+    kubeconfig_ciphertext = $7,
+    kubeconfig_context = $8,
+    reconcile_interval_seconds = $9,
+    request_timeout_seconds = $10,
+    stale_retention_seconds = $11,
+    object_kinds = $12::text[],
+    pod_selector = $13,
+    auto_register = $14,
+    updated_at = now()
+WHERE source_key = $1 AND managed
+RETURNING id, source_key, name, site_id, cluster_domain, namespace_scope, last_attempt_at, last_success_at, last_error, service_count, matched_count, unmatched_count, ambiguous_count, created_at, updated_at, no_usable_ip_count, managed, auth_mode, kubeconfig_ciphertext, kubeconfig_context, reconcile_interval_seconds, request_timeout_seconds, stale_retention_seconds, object_kinds, pod_selector, object_count, auto_register, object_error
+`
+
+type UpdateManagedKubernetesSourceParams struct {
+	SourceKey                string      `json:"source_key"`
+	Name                     string      `json:"name"`
+	SiteID                   pgtype.UUID `json:"site_id"`
+	ClusterDomain            string      `json:"cluster_domain"`
+	Column5                  []string    `json:"column_5"`
+	AuthMode                 string      `json:"auth_mode"`
+	KubeconfigCiphertext     []byte      `json:"kubeconfig_ciphertext"`
+	KubeconfigContext        string      `json:"kubeconfig_context"`
+	ReconcileIntervalSeconds int32       `json:"reconcile_interval_seconds"`
+	RequestTimeoutSeconds    int32       `json:"request_timeout_seconds"`
+	StaleRetentionSeconds    int32       `json:"stale_retention_seconds"`
+	Column12                 []string    `json:"column_12"`
+	PodSelector              string      `json:"pod_selector"`
+	AutoRegister             string      `json:"auto_register"`
+}
+
+func (q *Queries) UpdateManagedKubernetesSource(ctx context.Context, arg UpdateManagedKubernetesSourceParams) (KubernetesSource, error) {
+	row := q.db.QueryRow(ctx, updateManagedKubernetesSource,
+		arg.SourceKey,
+		arg.Name,
+		arg.SiteID,
+		arg.ClusterDomain,
+		arg.Column5,
+		arg.AuthMode,
+		arg.KubeconfigCiphertext,
+		arg.KubeconfigContext,
+		arg.ReconcileIntervalSeconds,
+		arg.RequestTimeoutSeconds,
+		arg.StaleRetentionSeconds,
+		arg.Column12,
+		arg.PodSelector,
+		arg.AutoRegister,
+	)
+	var i KubernetesSource
+	err := row.Scan(
+		&i.ID,
+		&i.SourceKey,
+		&i.Name,
+		&i.SiteID,
+		&i.ClusterDomain,
+		&i.NamespaceScope,
+		&i.LastAttemptAt,
+		&i.LastSuccessAt,
+		&i.LastError,
+		&i.ServiceCount,
+		&i.MatchedCount,
+		&i.UnmatchedCount,
+		&i.AmbiguousCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NoUsableIpCount,
+		&i.Managed,
+		&i.AuthMode,
+		&i.KubeconfigCiphertext,
+		&i.KubeconfigContext,
+		&i.ReconcileIntervalSeconds,
+		&i.RequestTimeoutSeconds,
+		&i.StaleRetentionSeconds,
+		&i.ObjectKinds,
+		&i.PodSelector,
+		&i.ObjectCount,
+		&i.AutoRegister,
+		&i.ObjectError,
+	)
+	return i, err
+}
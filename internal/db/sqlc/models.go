@@ -5,18 +5,155 @@
 package db
 
 import (
+	"net"
 	"net/netip"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type IpAddress struct {
+type DnsRegistration struct {
+	Ip          netip.Addr         `json:"ip"`
+	Name        string             `json:"name"`
+	Zone        string             `json:"zone"`
+	ReverseZone string             `json:"reverse_zone"`
+	Ttl         int32              `json:"ttl"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DnsUpdateQueue struct {
+	Ip            netip.Addr         `json:"ip"`
+	Generation    int64              `json:"generation"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     string             `json:"last_error"`
+	EnqueuedAt    pgtype.Timestamptz `json:"enqueued_at"`
+}
+
+type DnsZone struct {
 	ID        pgtype.UUID        `json:"id"`
-	Ip        netip.Addr         `json:"ip"`
-	Hostname  string             `json:"hostname"`
+	Name      string             `json:"name"`
+	SiteID    pgtype.UUID        `json:"site_id"`
+	SubnetID  pgtype.Int8        `json:"subnet_id"`
+	Ttl       int32              `json:"ttl"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	SubnetID  int64              `json:"subnet_id"`
+}
+
+type DnsZoneSerial struct {
+	Zone        string             `json:"zone"`
+	Serial      int64              `json:"serial"`
+	ContentHash string             `json:"content_hash"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Scope          string             `json:"scope"`
+	IdempotencyKey string             `json:"idempotency_key"`
+	Method         string             `json:"method"`
+	Path           string             `json:"path"`
+	RequestHash    string             `json:"request_hash"`
+	StatusCode     pgtype.Int4        `json:"status_code"`
+	ContentType    string             `json:"content_type"`
+	ResponseBody   []byte             `json:"response_body"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+}
+
+type IpAddress struct {
+	ID         pgtype.UUID        `json:"id"`
+	Ip         netip.Addr         `json:"ip"`
+	Hostname   string             `json:"hostname"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	SubnetID   int64              `json:"subnet_id"`
+	MacAddress net.HardwareAddr   `json:"mac_address"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+}
+
+type KubernetesAddressClaim struct {
+	IpAddressID pgtype.UUID        `json:"ip_address_id"`
+	ClaimUid    string             `json:"claim_uid"`
+	Namespace   string             `json:"namespace"`
+	Name        string             `json:"name"`
+	ClaimedAt   pgtype.Timestamptz `json:"claimed_at"`
+}
+
+type KubernetesDiscoveryEvent struct {
+	ID                int64              `json:"id"`
+	RunID             int64              `json:"run_id"`
+	SourceID          pgtype.UUID        `json:"source_id"`
+	ServiceUid        string             `json:"service_uid"`
+	Namespace         string             `json:"namespace"`
+	Name              string             `json:"name"`
+	Change            string             `json:"change"`
+	PreviousAddresses []netip.Addr       `json:"previous_addresses"`
+	Addresses         []netip.Addr       `json:"addresses"`
+	OccurredAt        pgtype.Timestamptz `json:"occurred_at"`
+}
+
+type KubernetesDiscoveryRun struct {
+	ID              int64              `json:"id"`
+	SourceID        pgtype.UUID        `json:"source_id"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	FinishedAt      pgtype.Timestamptz `json:"finished_at"`
+	ServiceCount    int32              `json:"service_count"`
+	MatchedCount    int32              `json:"matched_count"`
+	UnmatchedCount  int32              `json:"unmatched_count"`
+	AmbiguousCount  int32              `json:"ambiguous_count"`
+	NoUsableIpCount int32              `json:"no_usable_ip_count"`
+	Error           string             `json:"error"`
+}
+
+type KubernetesLoadBalancerAllocation struct {
+	IpAddressID   pgtype.UUID        `json:"ip_address_id"`
+	ControllerKey string             `json:"controller_key"`
+	ServiceUid    string             `json:"service_uid"`
+	Namespace     string             `json:"namespace"`
+	Name          string             `json:"name"`
+	AllocatedAt   pgtype.Timestamptz `json:"allocated_at"`
+}
+
+type KubernetesObject struct {
+	ID              pgtype.UUID        `json:"id"`
+	SourceID        pgtype.UUID        `json:"source_id"`
+	Kind            string             `json:"kind"`
+	KubernetesUid   string             `json:"kubernetes_uid"`
+	Namespace       string             `json:"namespace"`
+	Name            string             `json:"name"`
+	HostNetwork     bool               `json:"host_network"`
+	ResourceVersion string             `json:"resource_version"`
+	ObservedAt      pgtype.Timestamptz `json:"observed_at"`
+	Active          bool               `json:"active"`
+	StaleAt         pgtype.Timestamptz `json:"stale_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KubernetesObjectAddress struct {
+	ID          int64       `json:"id"`
+	ObjectID    pgtype.UUID `json:"object_id"`
+	Kind        string      `json:"kind"`
+	Address     netip.Addr  `json:"address"`
+	NodeName    string      `json:"node_name"`
+	IpAddressID pgtype.UUID `json:"ip_address_id"`
+	MatchStatus string      `json:"match_status"`
+	MatchCount  int32       `json:"match_count"`
+}
+
+type KubernetesObjectHostname struct {
+	ID       int64       `json:"id"`
+	ObjectID pgtype.UUID `json:"object_id"`
+	Kind     string      `json:"kind"`
+	Hostname string      `json:"hostname"`
+}
+
+type KubernetesRegisteredAddress struct {
+	IpAddressID  pgtype.UUID        `json:"ip_address_id"`
+	SourceID     pgtype.UUID        `json:"source_id"`
+	ServiceUid   string             `json:"service_uid"`
+	Namespace    string             `json:"namespace"`
+	Name         string             `json:"name"`
+	RegisteredAt pgtype.Timestamptz `json:"registered_at"`
 }
 
 type KubernetesService struct {
@@ -66,22 +203,52 @@ type KubernetesServicePort struct {
 }
 
 type KubernetesSource struct {
-	ID              pgtype.UUID        `json:"id"`
-	SourceKey       string             `json:"source_key"`
-	Name            string             `json:"name"`
-	SiteID          pgtype.UUID        `json:"site_id"`
-	ClusterDomain   string             `json:"cluster_domain"`
-	NamespaceScope  []string           `json:"namespace_scope"`
-	LastAttemptAt   pgtype.Timestamptz `json:"last_attempt_at"`
-	LastSuccessAt   pgtype.Timestamptz `json:"last_success_at"`
-	LastError       string             `json:"last_error"`
-	ServiceCount    int32              `json:"service_count"`
-	MatchedCount    int32              `json:"matched_count"`
-	UnmatchedCount  int32              `json:"unmatched_count"`
-	AmbiguousCount  int32              `json:"ambiguous_count"`
-	CreatedAt       pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
-	NoUsableIpCount int32              `json:"no_usable_ip_count"`
+	ID                       pgtype.UUID        `json:"id"`
+	SourceKey                string             `json:"source_key"`
+	Name                     string             `json:"name"`
+	SiteID                   pgtype.UUID        `json:"site_id"`
+	ClusterDomain            string             `json:"cluster_domain"`
+	NamespaceScope           []string           `json:"namespace_scope"`
+	LastAttemptAt            pgtype.Timestamptz `json:"last_attempt_at"`
+	LastSuccessAt            pgtype.Timestamptz `json:"last_success_at"`
+	LastError                string             `json:"last_error"`
+	ServiceCount             int32              `json:"service_count"`
+	MatchedCount             int32              `json:"matched_count"`
+	UnmatchedCount           int32              `json:"unmatched_count"`
+	AmbiguousCount           int32              `json:"ambiguous_count"`
+	CreatedAt                pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt                pgtype.Timestamptz `json:"updated_at"`
+	NoUsableIpCount          int32              `json:"no_usable_ip_count"`
+	Managed                  bool               `json:"managed"`
+	AuthMode                 string             `json:"auth_mode"`
+	KubeconfigCiphertext     []byte             `json:"kubeconfig_ciphertext"`
+	KubeconfigContext        string             `json:"kubeconfig_context"`
+	ReconcileIntervalSeconds int32              `json:"reconcile_interval_seconds"`
+	RequestTimeoutSeconds    int32              `json:"request_timeout_seconds"`
+	StaleRetentionSeconds    int32              `json:"stale_retention_seconds"`
+	ObjectKinds              []string           `json:"object_kinds"`
+	PodSelector              string             `json:"pod_selector"`
+	ObjectCount              int32              `json:"object_count"`
+	AutoRegister             string             `json:"auto_register"`
+	ObjectError              string             `json:"object_error"`
+}
+
+type OutboxEvent struct {
+	ID           int64              `json:"id"`
+	EventType    string             `json:"event_type"`
+	ObjectType   string             `json:"object_type"`
+	ObjectID     string             `json:"object_id"`
+	SiteID       pgtype.UUID        `json:"site_id"`
+	Payload      []byte             `json:"payload"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	DispatchedAt pgtype.Timestamptz `json:"dispatched_at"`
+}
+
+type RateLimitCounter struct {
+	BucketKey   string             `json:"bucket_key"`
+	WindowStart pgtype.Timestamptz `json:"window_start"`
+	Hits        int64              `json:"hits"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
 }
 
 type ReportingSetting struct {
@@ -106,6 +273,9 @@ type Subnet struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 	SiteID      pgtype.UUID        `json:"site_id"`
+	Gateway     *netip.Addr        `json:"gateway"`
+	DnsServers  []netip.Addr       `json:"dns_servers"`
+	DhcpPools   []byte             `json:"dhcp_pools"`
 }
 
 type SubnetUsageSnapshot struct {
@@ -114,3 +284,28 @@ type SubnetUsageSnapshot struct {
 	UsedIps    int64              `json:"used_ips"`
 	TotalIps   int64              `json:"total_ips"`
 }
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	SubscriptionID pgtype.UUID        `json:"subscription_id"`
+	EventID        int64              `json:"event_id"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	LastAttemptAt  pgtype.Timestamptz `json:"last_attempt_at"`
+	LastStatusCode pgtype.Int4        `json:"last_status_code"`
+	LastError      string             `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID          pgtype.UUID        `json:"id"`
+	Url         string             `json:"url"`
+	Description string             `json:"description"`
+	EventTypes  []string           `json:"event_types"`
+	Secret      string             `json:"secret"`
+	Active      bool               `json:"active"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: rate_limits.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteExpiredRateLimitCounters = `-- name: DeleteExpiredRateLimitCounters :execrows
+DELETE FROM rate_limit_counters
+WHERE expires_at <= $1
+`
+
+func (q *Queries) DeleteExpiredRateLimitCounters(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredRateLimitCounters, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const incrementRateLimitCounter = `-- name: IncrementRateLimitCounter :one
+INSERT INTO rate_limit_counters (bucket_key, window_start, hits, expires_at)
+VALUES ($1, $2, 1, $3)
+ON CONFLICT (bucket_key, window_start) DO UPDATE
+SET hits = rate_limit_counters.hits + 1
+RETURNING hits
+`
+
+type IncrementRateLimitCounterParams struct {
+	BucketKey   string             `json:"bucket_key"`
+	WindowStart pgtype.Timestamptz `json:"window_start"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) IncrementRateLimitCounter(ctx context.Context, arg IncrementRateLimitCounterParams) (int64, error) {
+	row := q.db.QueryRow(ctx, incrementRateLimitCounter, arg.BucketKey, arg.WindowStart, arg.ExpiresAt)
+	var hits int64
+	err := row.Scan(&hits)
+	return hits, err
+}
@@ -24,9 +24,18 @@ type AssignSubnetSiteParams struct {
 	SiteID pgtype.UUID `json:"site_id"`
 }
 
-func (q *Queries) AssignSubnetSite(ctx context.Context, arg AssignSubnetSiteParams) (Subnet, error) {
+type AssignSubnetSiteRow struct {
+	ID          int64              `json:"id"`
+	Cidr        netip.Prefix       `json:"cidr"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	SiteID      pgtype.UUID        `json:"site_id"`
+}
+
+func (q *Queries) AssignSubnetSite(ctx context.Context, arg AssignSubnetSiteParams) (AssignSubnetSiteRow, error) {
 	row := q.db.QueryRow(ctx, assignSubnetSite, arg.ID, arg.SiteID)
-	var i Subnet
+	var i AssignSubnetSiteRow
 	err := row.Scan(
 		&i.ID,
 		&i.Cidr,
@@ -50,9 +59,18 @@ type CreateSubnetParams struct {
 	Description string       `json:"description"`
 }
 
-func (q *Queries) CreateSubnet(ctx context.Context, arg CreateSubnetParams) (Subnet, error) {
+type CreateSubnetRow struct {
+	ID          int64              `json:"id"`
+	Cidr        netip.Prefix       `json:"cidr"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	SiteID      pgtype.UUID        `json:"site_id"`
+}
+
+func (q *Queries) CreateSubnet(ctx context.Context, arg CreateSubnetParams) (CreateSubnetRow, error) {
 	row := q.db.QueryRow(ctx, createSubnet, arg.Cidr, arg.SiteID, arg.Description)
-	var i Subnet
+	var i CreateSubnetRow
 	err := row.Scan(
 		&i.ID,
 		&i.Cidr,
@@ -68,7 +86,7 @@ const deleteSubnetByID = `-- name: DeleteSubnetByID :one
 WITH deleted_rows AS (
     DELETE FROM subnets
     WHERE id = $1
-    RETURNING id, cidr, description, created_at, updated_at, site_id
+    RETURNING id, cidr, description, created_at, updated_at, site_id, gateway, dns_servers, dhcp_pools
 )
 SELECT count(*) FROM deleted_rows
 `
@@ -82,6 +100,7 @@ func (q *Queries) DeleteSubnetByID(ctx context.Context, id int64) (int64, error)
 
 const getSubnetByID = `-- name: GetSubnetByID :one
 SELECT subnets.id, subnets.cidr, subnets.description, subnets.created_at, subnets.updated_at, subnets.site_id,
+       subnets.gateway, subnets.dns_servers, subnets.dhcp_pools,
        (SELECT COUNT(*) FROM ip_addresses WHERE subnet_id = subnets.id) AS used_ips
 FROM subnets
 WHERE subnets.id = $1
@@ -94,6 +113,9 @@ type GetSubnetByIDRow struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 	SiteID      pgtype.UUID        `json:"site_id"`
+	Gateway     *netip.Addr        `json:"gateway"`
+	DnsServers  []netip.Addr       `json:"dns_servers"`
+	DhcpPools   []byte             `json:"dhcp_pools"`
 	UsedIps     int64              `json:"used_ips"`
 }
 
@@ -107,6 +129,9 @@ func (q *Queries) GetSubnetByID(ctx context.Context, id int64) (GetSubnetByIDRow
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SiteID,
+		&i.Gateway,
+		&i.DnsServers,
+		&i.DhcpPools,
 		&i.UsedIps,
 	)
 	return i, err
@@ -114,6 +139,7 @@ func (q *Queries) GetSubnetByID(ctx context.Context, id int64) (GetSubnetByIDRow
 
 const listSubnets = `-- name: ListSubnets :many
 SELECT subnets.id, subnets.cidr, subnets.description, subnets.created_at, subnets.updated_at, subnets.site_id,
+       subnets.gateway, subnets.dns_servers, subnets.dhcp_pools,
        (SELECT COUNT(*) FROM ip_addresses WHERE subnet_id = subnets.id) AS used_ips
 FROM subnets
 ORDER BY subnets.id
@@ -126,6 +152,9 @@ type ListSubnetsRow struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 	SiteID      pgtype.UUID        `json:"site_id"`
+	Gateway     *netip.Addr        `json:"gateway"`
+	DnsServers  []netip.Addr       `json:"dns_servers"`
+	DhcpPools   []byte             `json:"dhcp_pools"`
 	UsedIps     int64              `json:"used_ips"`
 }
 
@@ -145,6 +174,9 @@ func (q *Queries) ListSubnets(ctx context.Context) ([]ListSubnetsRow, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.SiteID,
+			&i.Gateway,
+			&i.DnsServers,
+			&i.DhcpPools,
 			&i.UsedIps,
 		); err != nil {
 			return nil, err
@@ -171,14 +203,23 @@ type UpdateSubnetParams struct {
 	Description string       `json:"description"`
 }
 
-func (q *Queries) UpdateSubnet(ctx context.Context, arg UpdateSubnetParams) (Subnet, error) {
+type UpdateSubnetRow struct {
+	ID          int64              `json:"id"`
+	Cidr        netip.Prefix       `json:"cidr"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	SiteID      pgtype.UUID        `json:"site_id"`
+}
+
+func (q *Queries) UpdateSubnet(ctx context.Context, arg UpdateSubnetParams) (UpdateSubnetRow, error) {
 	row := q.db.QueryRow(ctx, updateSubnet,
 		arg.ID,
 		arg.Cidr,
 		arg.SiteID,
 		arg.Description,
 	)
-	var i Subnet
+	var i UpdateSubnetRow
 	err := row.Scan(
 		&i.ID,
 		&i.Cidr,
@@ -189,3 +230,29 @@ func (q *Queries) UpdateSubnet(ctx context.Context, arg UpdateSubnetParams) (Sub
 	)
 	return i, err
 }
+
+const updateSubnetDHCP = `-- name: UpdateSubnetDHCP :one
+UPDATE subnets
+SET gateway = $2, dns_servers = $3, dhcp_pools = $4, updated_at = now() AT TIME ZONE 'UTC'
+WHERE id = $1
+RETURNING id
+`
+
+type UpdateSubnetDHCPParams struct {
+	ID         int64        `json:"id"`
+	Gateway    *netip.Addr  `json:"gateway"`
+	DnsServers []netip.Addr `json:"dns_servers"`
+	DhcpPools  []byte       `json:"dhcp_pools"`
+}
+
+func (q *Queries) UpdateSubnetDHCP(ctx context.Context, arg UpdateSubnetDHCPParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateSubnetDHCP,
+		arg.ID,
+		arg.Gateway,
+		arg.DnsServers,
+		arg.DhcpPools,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
@@ -0,0 +1,427 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhooks.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+WITH due AS (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at, id
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries
+SET attempts = webhook_deliveries.attempts + 1,
+    next_attempt_at = $2::timestamptz
+FROM due, webhook_subscriptions, outbox_events
+WHERE webhook_deliveries.id = due.id
+  AND webhook_subscriptions.id = webhook_deliveries.subscription_id
+  AND outbox_events.id = webhook_deliveries.event_id
+RETURNING webhook_deliveries.id,
+          webhook_deliveries.subscription_id,
+          webhook_deliveries.attempts,
+          webhook_subscriptions.url,
+          webhook_subscriptions.secret,
+          outbox_events.id AS event_id,
+          outbox_events.event_type,
+          outbox_events.object_type,
+          outbox_events.object_id,
+          outbox_events.site_id,
+          outbox_events.payload,
+          outbox_events.created_at AS occurred_at
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	Limit   int32              `json:"limit"`
+	Column2 pgtype.Timestamptz `json:"column_2"`
+}
+
+type ClaimDueWebhookDeliveriesRow struct {
+	ID             int64              `json:"id"`
+	SubscriptionID pgtype.UUID        `json:"subscription_id"`
+	Attempts       int32              `json:"attempts"`
+	Url            string             `json:"url"`
+	Secret         string             `json:"secret"`
+	EventID        int64              `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	ObjectType     string             `json:"object_type"`
+	ObjectID       string             `json:"object_id"`
+	SiteID         pgtype.UUID        `json:"site_id"`
+	Payload        []byte             `json:"payload"`
+	OccurredAt     pgtype.Timestamptz `json:"occurred_at"`
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookDeliveries, arg.Limit, arg.Column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+			&i.EventID,
+			&i.EventType,
+			&i.ObjectType,
+			&i.ObjectID,
+			&i.SiteID,
+			&i.Payload,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, description, event_types, secret, active)
+VALUES ($1, $2, $3::text[], $4, $5)
+RETURNING id, url, description, event_types, secret, active, created_at, updated_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	Url         string   `json:"url"`
+	Description string   `json:"description"`
+	Column3     []string `json:"column_3"`
+	Secret      string   `json:"secret"`
+	Active      bool     `json:"active"`
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.Url,
+		arg.Description,
+		arg.Column3,
+		arg.Secret,
+		arg.Active,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteDispatchedOutboxEvents = `-- name: DeleteDispatchedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE dispatched_at < $1
+  AND NOT EXISTS (
+      SELECT 1 FROM webhook_deliveries
+      WHERE webhook_deliveries.event_id = outbox_events.id
+        AND webhook_deliveries.status <> 'delivered'
+  )
+`
+
+func (q *Queries) DeleteDispatchedOutboxEvents(ctx context.Context, dispatchedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDispatchedOutboxEvents, dispatchedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteWebhookSubscriptionByID = `-- name: DeleteWebhookSubscriptionByID :execrows
+DELETE FROM webhook_subscriptions WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhookSubscriptionByID(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhookSubscriptionByID, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getWebhookSubscriptionByID = `-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, description, event_types, secret, active, created_at, updated_at FROM webhook_subscriptions WHERE id = $1
+`
+
+func (q *Queries) GetWebhookSubscriptionByID(ctx context.Context, id pgtype.UUID) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, getWebhookSubscriptionByID, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listDeadWebhookDeliveries = `-- name: ListDeadWebhookDeliveries :many
+SELECT webhook_deliveries.id,
+       webhook_deliveries.subscription_id,
+       webhook_subscriptions.url,
+       outbox_events.id AS event_id,
+       outbox_events.event_type,
+       outbox_events.object_type,
+       outbox_events.object_id,
+       webhook_deliveries.attempts,
+       webhook_deliveries.last_status_code,
+       webhook_deliveries.last_error,
+       webhook_deliveries.last_attempt_at,
+       webhook_deliveries.created_at
+FROM webhook_deliveries
+JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id
+JOIN outbox_events ON outbox_events.id = webhook_deliveries.event_id
+WHERE webhook_deliveries.status = 'dead'
+ORDER BY webhook_deliveries.last_attempt_at DESC, webhook_deliveries.id DESC
+LIMIT $1
+`
+
+type ListDeadWebhookDeliveriesRow struct {
+	ID             int64              `json:"id"`
+	SubscriptionID pgtype.UUID        `json:"subscription_id"`
+	Url            string             `json:"url"`
+	EventID        int64              `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	ObjectType     string             `json:"object_type"`
+	ObjectID       string             `json:"object_id"`
+	Attempts       int32              `json:"attempts"`
+	LastStatusCode pgtype.Int4        `json:"last_status_code"`
+	LastError      string             `json:"last_error"`
+	LastAttemptAt  pgtype.Timestamptz `json:"last_attempt_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListDeadWebhookDeliveries(ctx context.Context, limit int32) ([]ListDeadWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, listDeadWebhookDeliveries, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDeadWebhookDeliveriesRow
+	for rows.Next() {
+		var i ListDeadWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.Url,
+			&i.EventID,
+			&i.EventType,
+			&i.ObjectType,
+			&i.ObjectID,
+			&i.Attempts,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.LastAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, url, description, event_types, secret, active, created_at, updated_at FROM webhook_subscriptions ORDER BY created_at, id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Description,
+			&i.EventTypes,
+			&i.Secret,
+			&i.Active,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookDeliveryDelivered = `-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    last_attempt_at = now(),
+    last_status_code = $2,
+    last_error = '',
+    delivered_at = now()
+WHERE id = $1
+`
+
+type MarkWebhookDeliveryDeliveredParams struct {
+	ID             int64       `json:"id"`
+	LastStatusCode pgtype.Int4 `json:"last_status_code"`
+}
+
+func (q *Queries) MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDeliveryDelivered, arg.ID, arg.LastStatusCode)
+	return err
+}
+
+const markWebhookDeliveryFailed = `-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = CASE WHEN $2::boolean THEN 'dead' ELSE 'pending' END,
+    last_attempt_at = now(),
+    last_status_code = $3,
+    last_error = $4,
+    next_attempt_at = $5
+WHERE id = $1
+`
+
+type MarkWebhookDeliveryFailedParams struct {
+	ID             int64              `json:"id"`
+	Column2        bool               `json:"column_2"`
+	LastStatusCode pgtype.Int4        `json:"last_status_code"`
+	LastError      string             `json:"last_error"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+}
+
+func (q *Queries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDeliveryFailed,
+		arg.ID,
+		arg.Column2,
+		arg.LastStatusCode,
+		arg.LastError,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const queueWebhookDeliveries = `-- name: QueueWebhookDeliveries :execrows
+WITH events AS (
+    SELECT id, event_type, object_type
+    FROM outbox_events
+    WHERE dispatched_at IS NULL
+    ORDER BY id
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+), queued AS (
+    INSERT INTO webhook_deliveries (subscription_id, event_id)
+    SELECT webhook_subscriptions.id, events.id
+    FROM events
+    JOIN webhook_subscriptions ON webhook_subscriptions.active
+        AND (
+            events.event_type = ANY(webhook_subscriptions.event_types)
+            OR events.object_type || '.*' = ANY(webhook_subscriptions.event_types)
+            OR '*' = ANY(webhook_subscriptions.event_types)
+        )
+    ON CONFLICT (subscription_id, event_id) DO NOTHING
+)
+UPDATE outbox_events
+SET dispatched_at = now()
+FROM events
+WHERE outbox_events.id = events.id
+`
+
+func (q *Queries) QueueWebhookDeliveries(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, queueWebhookDeliveries, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retryDeadWebhookDelivery = `-- name: RetryDeadWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    last_error = ''
+WHERE id = $1 AND status = 'dead'
+`
+
+func (q *Queries) RetryDeadWebhookDelivery(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, retryDeadWebhookDelivery, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = $2,
+    description = $3,
+    event_types = $4::text[],
+    secret = $5,
+    active = $6,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, url, description, event_types, secret, active, created_at, updated_at
+`
+
+type UpdateWebhookSubscriptionParams struct {
+	ID          pgtype.UUID `json:"id"`
+	Url         string      `json:"url"`
+	Description string      `json:"description"`
+	Column4     []string    `json:"column_4"`
+	Secret      string      `json:"secret"`
+	Active      bool        `json:"active"`
+}
+
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, updateWebhookSubscription,
+		arg.ID,
+		arg.Url,
+		arg.Description,
+		arg.Column4,
+		arg.Secret,
+		arg.Active,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -2,7 +2,10 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/netip"
 
 	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
@@ -27,7 +30,11 @@ func (r *SubnetRepository) List(ctx context.Context) ([]domain.Subnet, error) {
 
 	out := make([]domain.Subnet, 0, len(subnets))
 	for _, subnet := range subnets {
-		out = append(out, domain.Subnet{ID: subnet.ID, CIDR: subnet.Cidr, SiteID: subnet.SiteID.Bytes, UsedIPCount: subnet.UsedIps, Description: subnet.Description, CreatedAt: subnet.CreatedAt.Time, UpdatedAt: subnet.UpdatedAt.Time})
+		mapped, err := withDHCPSettings(domain.Subnet{ID: subnet.ID, CIDR: subnet.Cidr, SiteID: subnet.SiteID.Bytes, UsedIPCount: subnet.UsedIps, Description: subnet.Description, CreatedAt: subnet.CreatedAt.Time, UpdatedAt: subnet.UpdatedAt.Time}, subnet.Gateway, subnet.DnsServers, subnet.DhcpPools)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, mapped)
 	}
 
 	return out, nil
@@ -42,7 +49,7 @@ func (r *SubnetRepository) FindByID(ctx context.Context, id int64) (domain.Subne
 		return domain.Subnet{}, err
 	}
 
-	return domain.Subnet{ID: subnet.ID, CIDR: subnet.Cidr, SiteID: subnet.SiteID.Bytes, UsedIPCount: subnet.UsedIps, Description: subnet.Description, CreatedAt: subnet.CreatedAt.Time, UpdatedAt: subnet.UpdatedAt.Time}, nil
+	return withDHCPSettings(domain.Subnet{ID: subnet.ID, CIDR: subnet.Cidr, SiteID: subnet.SiteID.Bytes, UsedIPCount: subnet.UsedIps, Description: subnet.Description, CreatedAt: subnet.CreatedAt.Time, UpdatedAt: subnet.UpdatedAt.Time}, subnet.Gateway, subnet.DnsServers, subnet.DhcpPools)
 }
 
 func (r *SubnetRepository) Create(ctx context.Context, input domain.CreateSubnetRecord) (domain.Subnet, error) {
@@ -88,6 +95,34 @@ func (r *SubnetRepository) Update(ctx context.Context, input domain.UpdateSubnet
 	return r.FindByID(ctx, subnet.ID)
 }
 
+func (r *SubnetRepository) UpdateDHCP(ctx context.Context, input domain.UpdateSubnetDHCPRecord) (domain.Subnet, error) {
+	pools := make([]dhcpPool, 0, len(input.Pools))
+	for _, pool := range input.Pools {
+		pools = append(pools, dhcpPool(pool))
+	}
+	encodedPools, err := json.Marshal(pools)
+	if err != nil {
+		return domain.Subnet{}, err
+	}
+	var gateway *netip.Addr
+	if input.Gateway.IsValid() {
+		gateway = &input.Gateway
+	}
+	id, err := r.queries.UpdateSubnetDHCP(ctx, sqlc.UpdateSubnetDHCPParams{
+		ID:         input.ID,
+		Gateway:    gateway,
+		DnsServers: input.DNSServers,
+		DhcpPools:  encodedPools,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return domain.Subnet{}, domain.ErrNotFound
+		}
+		return domain.Subnet{}, err
+	}
+	return r.FindByID(ctx, id)
+}
+
 func (r *SubnetRepository) Delete(ctx context.Context, id int64) (bool, error) {
 	deleted, err := r.queries.DeleteSubnetByID(ctx, id)
 	if err != nil {
@@ -108,6 +143,29 @@ func toDomainSubnet(subnet sqlc.Subnet) domain.Subnet {
 	}
 }
 
+// dhcpPool is the JSON form of a pool in subnets.dhcp_pools.
+type dhcpPool struct {
+	Start netip.Addr `json:"start"`
+	End   netip.Addr `json:"end"`
+}
+
+func withDHCPSettings(subnet domain.Subnet, gateway *netip.Addr, dnsServers []netip.Addr, encodedPools []byte) (domain.Subnet, error) {
+	if gateway != nil {
+		subnet.Gateway = *gateway
+	}
+	subnet.DNSServers = dnsServers
+	var pools []dhcpPool
+	if len(encodedPools) > 0 {
+		if err := json.Unmarshal(encodedPools, &pools); err != nil {
+			return domain.Subnet{}, fmt.Errorf("decode dhcp pools of subnet %d: %w", subnet.ID, err)
+		}
+	}
+	for _, pool := range pools {
+		subnet.DHCPPools = append(subnet.DHCPPools, domain.DHCPPool(pool))
+	}
+	return subnet, nil
+}
+
 func isNoRows(err error) bool {
 	return errors.Is(err, pgx.ErrNoRows)
 }
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracerNamesSpansAfterSQLCQueries(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	tracer := queryTracer{}
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: ListSubnets :many\nSELECT 1", Args: []any{"secret"}})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 3")})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "begin"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("conn closed")})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "ListSubnets" || spans[1].Name != "BEGIN" {
+		t.Fatalf("unexpected spans: %+v", spans)
+	}
+	for _, attr := range spans[0].Attributes {
+		if attr.Value.AsString() == "secret" {
+			t.Fatal("expected query arguments to stay out of spans")
+		}
+	}
+	if spans[1].Status.Code != codes.Error {
+		t.Fatalf("expected failed query to fail the span, got %+v", spans[1].Status)
+	}
+}
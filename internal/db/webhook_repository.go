@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WebhookRepository struct {
+	queries *sqlc.Queries
+}
+
+var _ domain.WebhookRepository = (*WebhookRepository)(nil)
+
+func NewWebhookRepository(queries *sqlc.Queries) *WebhookRepository {
+	return &WebhookRepository{queries: queries}
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows, err := r.queries.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toDomainWebhookSubscription(row))
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) FindSubscriptionByID(ctx context.Context, id uuid.UUID) (domain.WebhookSubscription, error) {
+	row, err := r.queries.GetWebhookSubscriptionByID(ctx, uUIDtoPgUUID(id))
+	if err != nil {
+		if isNoRows(err) {
+			return domain.WebhookSubscription{}, domain.ErrNotFound
+		}
+		return domain.WebhookSubscription{}, err
+	}
+	return toDomainWebhookSubscription(row), nil
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	row, err := r.queries.CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		Url:         subscription.URL,
+		Description: subscription.Description,
+		Column3:     subscription.EventTypes,
+		Secret:      subscription.Secret,
+		Active:      subscription.Active,
+	})
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return toDomainWebhookSubscription(row), nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	row, err := r.queries.UpdateWebhookSubscription(ctx, sqlc.UpdateWebhookSubscriptionParams{
+		ID:          uUIDtoPgUUID(subscription.ID),
+		Url:         subscription.URL,
+		Description: subscription.Description,
+		Column4:     subscription.EventTypes,
+		Secret:      subscription.Secret,
+		Active:      subscription.Active,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return domain.WebhookSubscription{}, domain.ErrNotFound
+		}
+		return domain.WebhookSubscription{}, err
+	}
+	return toDomainWebhookSubscription(row), nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	deleted, err := r.queries.DeleteWebhookSubscriptionByID(ctx, uUIDtoPgUUID(id))
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+func (r *WebhookRepository) QueueDeliveries(ctx context.Context, limit int32) (int64, error) {
+	return r.queries.QueueWebhookDeliveries(ctx, limit)
+}
+
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int32, leaseUntil time.Time) ([]domain.WebhookDelivery, error) {
+	rows, err := r.queries.ClaimDueWebhookDeliveries(ctx, sqlc.ClaimDueWebhookDeliveriesParams{
+		Limit:   limit,
+		Column2: timestamp(leaseUntil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.WebhookDelivery{
+			ID:             row.ID,
+			SubscriptionID: pgUUIDToUUID(row.SubscriptionID),
+			URL:            row.Url,
+			Secret:         row.Secret,
+			Attempts:       row.Attempts,
+			Event: domain.ChangeEvent{
+				ID:         row.EventID,
+				Type:       row.EventType,
+				ObjectType: row.ObjectType,
+				ObjectID:   row.ObjectID,
+				SiteID:     optionalUUID(row.SiteID),
+				Payload:    row.Payload,
+				OccurredAt: row.OccurredAt.Time.UTC(),
+			},
+		})
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	return r.queries.MarkWebhookDeliveryDelivered(ctx, sqlc.MarkWebhookDeliveryDeliveredParams{
+		ID:             id,
+		LastStatusCode: pgtype.Int4{Int32: int32(statusCode), Valid: true},
+	})
+}
+
+func (r *WebhookRepository) MarkFailed(ctx context.Context, failure domain.WebhookDeliveryFailure) error {
+	params := sqlc.MarkWebhookDeliveryFailedParams{
+		ID:            failure.ID,
+		Column2:       failure.Dead,
+		LastError:     failure.Error,
+		NextAttemptAt: timestamp(failure.NextAttemptAt),
+	}
+	if failure.StatusCode != nil {
+		params.LastStatusCode = pgtype.Int4{Int32: *failure.StatusCode, Valid: true}
+	}
+	return r.queries.MarkWebhookDeliveryFailed(ctx, params)
+}
+
+func (r *WebhookRepository) ListDeadLetters(ctx context.Context, limit int32) ([]domain.WebhookDeadLetter, error) {
+	rows, err := r.queries.ListDeadWebhookDeliveries(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.WebhookDeadLetter, 0, len(rows))
+	for _, row := range rows {
+		deadLetter := domain.WebhookDeadLetter{
+			ID:             row.ID,
+			SubscriptionID: pgUUIDToUUID(row.SubscriptionID),
+			URL:            row.Url,
+			EventID:        row.EventID,
+			EventType:      row.EventType,
+			ObjectType:     row.ObjectType,
+			ObjectID:       row.ObjectID,
+			Attempts:       row.Attempts,
+			LastError:      row.LastError,
+			LastAttemptAt:  optionalTime(row.LastAttemptAt),
+			CreatedAt:      row.CreatedAt.Time.UTC(),
+		}
+		if row.LastStatusCode.Valid {
+			statusCode := row.LastStatusCode.Int32
+			deadLetter.LastStatusCode = &statusCode
+		}
+		out = append(out, deadLetter)
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) RetryDeadLetter(ctx context.Context, id int64) (bool, error) {
+	retried, err := r.queries.RetryDeadWebhookDelivery(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return retried > 0, nil
+}
+
+func (r *WebhookRepository) DeleteDispatchedEvents(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.DeleteDispatchedOutboxEvents(ctx, timestamp(before))
+}
+
+func toDomainWebhookSubscription(row sqlc.WebhookSubscription) domain.WebhookSubscription {
+	return domain.WebhookSubscription{
+		ID:          pgUUIDToUUID(row.ID),
+		URL:         row.Url,
+		Description: row.Description,
+		EventTypes:  row.EventTypes,
+		Secret:      row.Secret,
+		Active:      row.Active,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
+
+func optionalUUID(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes)
+	return &value
+}
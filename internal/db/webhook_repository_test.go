@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlc "github.com/Flarenzy/simple-k8s-app/internal/db/sqlc"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestWebhookRepositoryFindSubscriptionMapsNoRowsToNotFound(t *testing.T) {
+	repository := NewWebhookRepository(sqlc.New(stubDBTX{}))
+	_, err := repository.FindSubscriptionByID(context.Background(), uuid.New())
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWebhookRepositoryClaimMapsDeliveriesWithEvent(t *testing.T) {
+	subscriptionID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	siteID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	occurredAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	leaseUntil := occurredAt.Add(2 * time.Minute)
+	repository := NewWebhookRepository(sqlc.New(stubDBTX{queryFn: func(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+		if args[0] != int32(10) || args[1] != timestamp(leaseUntil) {
+			t.Fatalf("unexpected claim args: %v", args)
+		}
+		return &stubRows{rows: [][]any{{
+			int64(7), uUIDtoPgUUID(subscriptionID), int32(3), "https://hooks.example.test", "secret",
+			int64(99), domain.EventSubnetCreated, "subnet", "42", uUIDtoPgUUID(siteID), []byte(`{"id":42}`),
+			pgtype.Timestamptz{Time: occurredAt, Valid: true},
+		}}}, nil
+	}}))
+
+	deliveries, err := repository.ClaimDueDeliveries(context.Background(), 10, leaseUntil)
+	if err != nil {
+		t.Fatalf("claim deliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected one delivery, got %+v", deliveries)
+	}
+	delivery := deliveries[0]
+	if delivery.ID != 7 || delivery.SubscriptionID != subscriptionID || delivery.Attempts != 3 || delivery.URL != "https://hooks.example.test" {
+		t.Fatalf("unexpected delivery: %+v", delivery)
+	}
+	event := delivery.Event
+	if event.ID != 99 || event.Type != domain.EventSubnetCreated || event.ObjectID != "42" || event.SiteID == nil || *event.SiteID != siteID || string(event.Payload) != `{"id":42}` || !event.OccurredAt.Equal(occurredAt) {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
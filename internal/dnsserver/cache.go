@@ -0,0 +1,191 @@
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/miekg/dns"
+)
+
+// zone is a zone compiled for lookups. Owner names are lower-case FQDNs.
+type zone struct {
+	name    string
+	soa     *dns.SOA
+	ns      *dns.NS
+	records map[string][]dns.RR
+	// names holds every owner name and the empty non-terminals between the
+	// owners and the apex, which answer NODATA rather than NXDOMAIN.
+	names map[string]bool
+}
+
+func compileZone(data domain.DNSZoneData) *zone {
+	apex := dns.Fqdn(data.Zone.Name)
+	ttl := uint32(data.Zone.TTL)
+	header := func(name string, rrtype uint16, ttl uint32) dns.RR_Header {
+		return dns.RR_Header{Name: dns.Fqdn(name), Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl}
+	}
+	z := &zone{
+		name: apex,
+		soa: &dns.SOA{
+			Hdr:     header(apex, dns.TypeSOA, ttl),
+			Ns:      dns.Fqdn(data.SOA.PrimaryNS),
+			Mbox:    dns.Fqdn(data.SOA.Hostmaster),
+			Serial:  data.SOA.Serial,
+			Refresh: data.SOA.Refresh,
+			Retry:   data.SOA.Retry,
+			Expire:  data.SOA.Expire,
+			Minttl:  data.SOA.Minimum,
+		},
+		ns:      &dns.NS{Hdr: header(apex, dns.TypeNS, ttl), Ns: dns.Fqdn(data.SOA.PrimaryNS)},
+		records: make(map[string][]dns.RR),
+		names:   map[string]bool{apex: true},
+	}
+	for _, record := range data.Records {
+		var rr dns.RR
+		switch record.Type {
+		case domain.DNSRecordA:
+			rr = &dns.A{Hdr: header(record.Name, dns.TypeA, ttl), A: net.ParseIP(record.Data)}
+		case domain.DNSRecordAAAA:
+			rr = &dns.AAAA{Hdr: header(record.Name, dns.TypeAAAA, ttl), AAAA: net.ParseIP(record.Data)}
+		case domain.DNSRecordPTR:
+			rr = &dns.PTR{Hdr: header(record.Name, dns.TypePTR, ttl), Ptr: dns.Fqdn(record.Data)}
+		default:
+			continue
+		}
+		owner := rr.Header().Name
+		z.records[owner] = append(z.records[owner], rr)
+		for name := owner; name != apex && dns.IsSubDomain(apex, name); {
+			z.names[name] = true
+			_, rest, _ := strings.Cut(name, ".")
+			name = rest
+		}
+	}
+	return z
+}
+
+// lookup returns the records of qtype owned by name, and whether name exists
+// in the zone at all.
+func (z *zone) lookup(name string, qtype uint16) ([]dns.RR, bool) {
+	if !z.names[name] {
+		return nil, false
+	}
+	var answers []dns.RR
+	if name == z.name {
+		if qtype == dns.TypeSOA || qtype == dns.TypeANY {
+			answers = append(answers, z.soa)
+		}
+		if qtype == dns.TypeNS || qtype == dns.TypeANY {
+			answers = append(answers, z.ns)
+		}
+	}
+	for _, rr := range z.records[name] {
+		if qtype == dns.TypeANY || rr.Header().Rrtype == qtype {
+			answers = append(answers, rr)
+		}
+	}
+	return answers, true
+}
+
+// negativeSOA is the SOA sent with NXDOMAIN and NODATA answers. Its TTL is
+// the negative caching TTL, as RFC 2308 requires.
+func (z *zone) negativeSOA() dns.RR {
+	soa := dns.Copy(z.soa).(*dns.SOA)
+	soa.Hdr.Ttl = min(soa.Hdr.Ttl, soa.Minttl)
+	return soa
+}
+
+// cache holds the zone list and compiled zones until a change invalidates
+// them. maxAge bounds staleness when change notifications were missed, for
+// example while the listener reconnected.
+type cache struct {
+	source ZoneSource
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu         sync.Mutex
+	generation uint64
+	loadedAt   time.Time
+	zoneNames  []string
+	zones      map[string]*zone
+}
+
+func newCache(source ZoneSource, maxAge time.Duration) *cache {
+	return &cache{source: source, maxAge: maxAge, now: time.Now, zones: make(map[string]*zone)}
+}
+
+func (c *cache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.zoneNames = nil
+	c.zones = make(map[string]*zone)
+}
+
+// zoneFor returns the most specific zone containing name, or nil when the
+// server is not authoritative for it.
+func (c *cache) zoneFor(ctx context.Context, name string) (*zone, error) {
+	names, generation, err := c.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var apex string
+	for _, candidate := range names {
+		if dns.IsSubDomain(candidate, name) && len(candidate) > len(apex) {
+			apex = candidate
+		}
+	}
+	if apex == "" {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	z, ok := c.zones[apex]
+	c.mu.Unlock()
+	if ok {
+		return z, nil
+	}
+	data, err := c.source.ZoneData(ctx, strings.TrimSuffix(apex, "."))
+	if err != nil {
+		return nil, err
+	}
+	z = compileZone(data)
+	c.mu.Lock()
+	if c.generation == generation {
+		c.zones[apex] = z
+	}
+	c.mu.Unlock()
+	return z, nil
+}
+
+func (c *cache) names(ctx context.Context) ([]string, uint64, error) {
+	c.mu.Lock()
+	if c.zoneNames != nil && c.now().Sub(c.loadedAt) >= c.maxAge {
+		c.generation++
+		c.zoneNames = nil
+		c.zones = make(map[string]*zone)
+	}
+	names, generation := c.zoneNames, c.generation
+	c.mu.Unlock()
+	if names != nil {
+		return names, generation, nil
+	}
+
+	zones, err := c.source.ListZones(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	names = make([]string, 0, len(zones))
+	for _, z := range zones {
+		names = append(names, dns.Fqdn(z.Name))
+	}
+	c.mu.Lock()
+	if c.generation == generation {
+		c.zoneNames, c.loadedAt = names, c.now()
+	}
+	c.mu.Unlock()
+	return names, generation, nil
+}
@@ -0,0 +1,179 @@
+// Package dnsserver answers DNS queries authoritatively from the IPAM zones.
+package dnsserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/miekg/dns"
+)
+
+const (
+	defaultCacheMaxAge = 5 * time.Minute
+	queryTimeout       = 2 * time.Second
+)
+
+// ZoneSource supplies zones and their records; domain.DNSService implements
+// it.
+type ZoneSource interface {
+	ListZones(ctx context.Context) ([]domain.DNSZone, error)
+	ZoneData(ctx context.Context, name string) (domain.DNSZoneData, error)
+}
+
+// ChangeSource delivers change events; events.Broker implements it.
+type ChangeSource interface {
+	Subscribe(ctx context.Context, filter domain.EventFilter) <-chan domain.ChangeEvent
+}
+
+// invalidatingObjectTypes are the changes that can alter a zone's records.
+var invalidatingObjectTypes = []string{
+	domain.ObjectTypeSubnet, domain.ObjectTypeIP, domain.ObjectTypeSite, domain.ObjectTypeDNSZone,
+}
+
+// Server answers A, AAAA, PTR, SOA and NS queries for the forward and
+// reverse zones of the IPAM. Zones are loaded on first use and kept until a
+// change event invalidates them.
+type Server struct {
+	cache  *cache
+	logger *slog.Logger
+}
+
+func NewServer(source ZoneSource, logger *slog.Logger) *Server {
+	return &Server{cache: newCache(source, defaultCacheMaxAge), logger: logger}
+}
+
+// Listen opens the UDP and TCP sockets for addr, so that a bad address fails
+// at startup rather than in the background.
+func Listen(addr string) (net.PacketConn, net.Listener, error) {
+	packetConn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		_ = packetConn.Close()
+		return nil, nil, err
+	}
+	return packetConn, listener, nil
+}
+
+// Serve answers queries on packetConn and listener until ctx is cancelled,
+// then closes both.
+func (s *Server) Serve(ctx context.Context, packetConn net.PacketConn, listener net.Listener) error {
+	servers := []*dns.Server{
+		{PacketConn: packetConn, Handler: s},
+		{Listener: listener, Handler: s},
+	}
+	errCh := make(chan error, len(servers))
+	started := make(chan struct{}, len(servers))
+	for _, server := range servers {
+		server.NotifyStartedFunc = func() { started <- struct{}{} }
+		go func() {
+			errCh <- server.ActivateAndServe()
+		}()
+	}
+
+	// Shutdown fails on a server that has not started yet, which would leave
+	// it serving after ctx ends.
+	var serveErr error
+	for range servers {
+		select {
+		case <-started:
+		case serveErr = <-errCh:
+		}
+		if serveErr != nil {
+			break
+		}
+	}
+	if serveErr == nil {
+		select {
+		case serveErr = <-errCh:
+		case <-ctx.Done():
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, server := range servers {
+		_ = server.ShutdownContext(shutdownCtx)
+	}
+	if serveErr != nil {
+		return fmt.Errorf("serve dns: %w", serveErr)
+	}
+	return nil
+}
+
+// WatchChanges drops cached zones on every change that may affect them,
+// until ctx is cancelled or changes closes the subscription.
+func (s *Server) WatchChanges(ctx context.Context, changes ChangeSource) {
+	for range changes.Subscribe(ctx, domain.EventFilter{ObjectTypes: invalidatingObjectTypes}) {
+		s.cache.invalidate()
+	}
+}
+
+// ServeDNS implements dns.Handler.
+func (s *Server) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	resp := s.answer(ctx, req)
+
+	size := dns.MinMsgSize
+	if opt := req.IsEdns0(); opt != nil {
+		size = max(int(opt.UDPSize()), dns.MinMsgSize)
+		resp.SetEdns0(uint16(size), false)
+	}
+	if _, udp := w.RemoteAddr().(*net.UDPAddr); udp {
+		resp.Truncate(size)
+	}
+	if err := w.WriteMsg(resp); err != nil {
+		s.logger.Debug("writing dns response failed", "err", err)
+	}
+}
+
+func (s *Server) answer(ctx context.Context, req *dns.Msg) *dns.Msg {
+	resp := new(dns.Msg)
+	switch {
+	case req.Opcode != dns.OpcodeQuery:
+		return resp.SetRcode(req, dns.RcodeNotImplemented)
+	case len(req.Question) != 1:
+		return resp.SetRcode(req, dns.RcodeFormatError)
+	}
+	question := req.Question[0]
+	if question.Qclass != dns.ClassINET && question.Qclass != dns.ClassANY {
+		return resp.SetRcode(req, dns.RcodeRefused)
+	}
+	name := strings.ToLower(dns.Fqdn(question.Name))
+
+	z, err := s.cache.zoneFor(ctx, name)
+	if err != nil {
+		s.logger.Error("loading dns zone failed", "name", name, "err", err)
+		return resp.SetRcode(req, dns.RcodeServerFailure)
+	}
+	if z == nil {
+		return resp.SetRcode(req, dns.RcodeRefused)
+	}
+
+	resp.SetReply(req)
+	resp.Authoritative = true
+	answers, exists := z.lookup(name, question.Qtype)
+	switch {
+	case !exists:
+		resp.Rcode = dns.RcodeNameError
+		resp.Ns = []dns.RR{z.negativeSOA()}
+	case len(answers) == 0:
+		resp.Ns = []dns.RR{z.negativeSOA()}
+	default:
+		for _, rr := range answers {
+			// Echo the question's spelling, as case-sensitive resolvers
+			// using 0x20 randomization expect.
+			rr = dns.Copy(rr)
+			rr.Header().Name = question.Name
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+	return resp
+}
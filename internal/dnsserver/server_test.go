@@ -0,0 +1,229 @@
+package dnsserver
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/miekg/dns"
+)
+
+type fakeZoneSource struct {
+	mu      sync.Mutex
+	records []domain.DNSRecord
+	loads   int
+}
+
+func (s *fakeZoneSource) ListZones(context.Context) ([]domain.DNSZone, error) {
+	return []domain.DNSZone{
+		{Name: "lab.example.com", Kind: domain.DNSZoneForward, TTL: 300},
+		{Name: "0.0.10.in-addr.arpa", Kind: domain.DNSZoneReverse, Network: netip.MustParsePrefix("10.0.0.0/24"), TTL: 300},
+	}, nil
+}
+
+func (s *fakeZoneSource) ZoneData(_ context.Context, name string) (domain.DNSZoneData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	data := domain.DNSZoneData{
+		Zone: domain.DNSZone{Name: name, TTL: 300},
+		SOA: domain.DNSSOA{
+			PrimaryNS: "ns1.example.com", Hostmaster: `dns\.admin.example.com`, Serial: 2026101801,
+			Refresh: 3600, Retry: 600, Expire: 1209600, Minimum: 60,
+		},
+	}
+	for _, record := range s.records {
+		if dns.IsSubDomain(dns.Fqdn(name), dns.Fqdn(record.Name)) {
+			data.Records = append(data.Records, record)
+		}
+	}
+	return data, nil
+}
+
+func (s *fakeZoneSource) loadCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads
+}
+
+func (s *fakeZoneSource) setRecords(records ...domain.DNSRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = records
+}
+
+type fakeChangeSource struct {
+	events chan domain.ChangeEvent
+}
+
+func (s fakeChangeSource) Subscribe(context.Context, domain.EventFilter) <-chan domain.ChangeEvent {
+	return s.events
+}
+
+// startServer serves on loopback UDP and TCP sockets sharing one port and
+// returns their address.
+func startServer(t *testing.T, server *Server) string {
+	t.Helper()
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	addr := packetConn.LocalAddr().String()
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		_ = packetConn.Close()
+		t.Skipf("tcp port of %s is taken: %v", addr, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, packetConn, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+	return addr
+}
+
+func query(t *testing.T, network, addr, name string, qtype uint16) *dns.Msg {
+	t.Helper()
+	req := new(dns.Msg)
+	req.SetQuestion(name, qtype)
+	client := &dns.Client{Net: network, Timeout: 2 * time.Second}
+	resp, _, err := client.Exchange(req, addr)
+	if err != nil {
+		t.Fatalf("%s query %s %s: %v", network, name, dns.TypeToString[qtype], err)
+	}
+	return resp
+}
+
+func TestServerAnswersAuthoritatively(t *testing.T) {
+	source := &fakeZoneSource{records: []domain.DNSRecord{
+		{Name: "web-1.lab.example.com", Type: domain.DNSRecordA, Data: "10.0.0.5"},
+		{Name: "web-1.lab.example.com", Type: domain.DNSRecordAAAA, Data: "2001:db8::5"},
+		{Name: "5.0.0.10.in-addr.arpa", Type: domain.DNSRecordPTR, Data: "web-1.lab.example.com"},
+	}}
+	addr := startServer(t, NewServer(source, slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			resp := query(t, network, addr, "Web-1.Lab.Example.com.", dns.TypeA)
+			if resp.Rcode != dns.RcodeSuccess || !resp.Authoritative || len(resp.Answer) != 1 {
+				t.Fatalf("unexpected A response: %v", resp)
+			}
+			a := resp.Answer[0].(*dns.A)
+			if a.A.String() != "10.0.0.5" || a.Hdr.Name != "Web-1.Lab.Example.com." || a.Hdr.Ttl != 300 {
+				t.Fatalf("unexpected A record: %v", a)
+			}
+
+			resp = query(t, network, addr, "web-1.lab.example.com.", dns.TypeAAAA)
+			if len(resp.Answer) != 1 || resp.Answer[0].(*dns.AAAA).AAAA.String() != "2001:db8::5" {
+				t.Fatalf("unexpected AAAA response: %v", resp)
+			}
+
+			resp = query(t, network, addr, "5.0.0.10.in-addr.arpa.", dns.TypePTR)
+			if len(resp.Answer) != 1 || resp.Answer[0].(*dns.PTR).Ptr != "web-1.lab.example.com." {
+				t.Fatalf("unexpected PTR response: %v", resp)
+			}
+
+			resp = query(t, network, addr, "lab.example.com.", dns.TypeSOA)
+			soa, ok := resp.Answer[0].(*dns.SOA)
+			if !ok || soa.Serial != 2026101801 || soa.Mbox != `dns\.admin.example.com.` {
+				t.Fatalf("unexpected SOA response: %v", resp)
+			}
+		})
+	}
+}
+
+func TestServerNegativeAnswers(t *testing.T) {
+	source := &fakeZoneSource{records: []domain.DNSRecord{
+		{Name: "web-1.lab.example.com", Type: domain.DNSRecordA, Data: "10.0.0.5"},
+		{Name: "5.0.0.10.in-addr.arpa", Type: domain.DNSRecordPTR, Data: "web-1.lab.example.com"},
+	}}
+	addr := startServer(t, NewServer(source, slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	tests := []struct {
+		name      string
+		qname     string
+		qtype     uint16
+		rcode     int
+		authority bool
+	}{
+		{name: "missing name", qname: "db-1.lab.example.com.", qtype: dns.TypeA, rcode: dns.RcodeNameError, authority: true},
+		{name: "missing type", qname: "web-1.lab.example.com.", qtype: dns.TypeAAAA, rcode: dns.RcodeSuccess, authority: true},
+		{name: "missing PTR", qname: "6.0.0.10.in-addr.arpa.", qtype: dns.TypePTR, rcode: dns.RcodeNameError, authority: true},
+		{name: "other zone", qname: "example.org.", qtype: dns.TypeA, rcode: dns.RcodeRefused},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := query(t, "udp", addr, test.qname, test.qtype)
+			if resp.Rcode != test.rcode || len(resp.Answer) != 0 {
+				t.Fatalf("expected %s with no answers, got %v", dns.RcodeToString[test.rcode], resp)
+			}
+			if !test.authority {
+				return
+			}
+			soa, ok := resp.Ns[0].(*dns.SOA)
+			if len(resp.Ns) != 1 || !ok || soa.Hdr.Ttl != 60 || !resp.Authoritative {
+				t.Fatalf("expected the SOA with the negative TTL in authority, got %v", resp)
+			}
+		})
+	}
+}
+
+func TestServerReloadsZonesAfterChange(t *testing.T) {
+	source := &fakeZoneSource{records: []domain.DNSRecord{
+		{Name: "web-1.lab.example.com", Type: domain.DNSRecordA, Data: "10.0.0.5"},
+	}}
+	server := NewServer(source, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	changes := fakeChangeSource{events: make(chan domain.ChangeEvent)}
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	go server.WatchChanges(watchCtx, changes)
+	addr := startServer(t, server)
+
+	query(t, "udp", addr, "web-1.lab.example.com.", dns.TypeA)
+	query(t, "udp", addr, "web-1.lab.example.com.", dns.TypeA)
+	if loads := source.loadCount(); loads != 1 {
+		t.Fatalf("expected the zone to be loaded once, got %d loads", loads)
+	}
+
+	source.setRecords(domain.DNSRecord{Name: "web-1.lab.example.com", Type: domain.DNSRecordA, Data: "10.0.0.9"})
+	changes.events <- domain.ChangeEvent{Type: domain.EventIPUpdated, ObjectType: domain.ObjectTypeIP}
+	// The unbuffered send returns once WatchChanges received the event; a
+	// second send guarantees the first invalidation has completed.
+	changes.events <- domain.ChangeEvent{Type: domain.EventIPUpdated, ObjectType: domain.ObjectTypeIP}
+
+	resp := query(t, "udp", addr, "web-1.lab.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 || resp.Answer[0].(*dns.A).A.String() != "10.0.0.9" {
+		t.Fatalf("expected the changed address, got %v", resp)
+	}
+}
+
+func TestCacheExpiresAfterMaxAge(t *testing.T) {
+	source := &fakeZoneSource{}
+	cache := newCache(source, time.Minute)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	for range 2 {
+		if _, err := cache.zoneFor(context.Background(), "lab.example.com."); err != nil {
+			t.Fatalf("zone for: %v", err)
+		}
+	}
+	now = now.Add(time.Minute)
+	if _, err := cache.zoneFor(context.Background(), "lab.example.com."); err != nil {
+		t.Fatalf("zone for: %v", err)
+	}
+	if source.loads != 2 {
+		t.Fatalf("expected a reload after max age, got %d loads", source.loads)
+	}
+}
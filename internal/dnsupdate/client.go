@@ -0,0 +1,154 @@
+// Package dnsupdate pushes the IPAM's host records to an external DNS server
+// with dynamic updates (RFC 2136) signed by TSIG (RFC 8945).
+package dnsupdate
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/miekg/dns"
+)
+
+// tsigFudge is the clock skew the server tolerates on signed messages.
+const tsigFudge = 300
+
+// Client implements domain.DNSUpdateClient. Messages go over TCP, so large
+// updates are never truncated.
+type Client struct {
+	server    string
+	keyName   string
+	algorithm string
+	secret    string
+	timeout   time.Duration
+}
+
+var _ domain.DNSUpdateClient = (*Client)(nil)
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		server:    cfg.Server,
+		keyName:   dns.Fqdn(strings.ToLower(cfg.TSIGKeyName)),
+		algorithm: tsigAlgorithms[cfg.TSIGAlgorithm],
+		secret:    cfg.TSIGSecret,
+		timeout:   cfg.Timeout,
+	}
+}
+
+// Apply sends one UPDATE per zone touched by change. Within a zone the old
+// records are deleted before the new ones are added. Every operation is
+// idempotent, so a change that failed halfway can simply be sent again.
+func (c *Client) Apply(ctx context.Context, change domain.DNSChange) error {
+	updates := make(map[string]*dns.Msg)
+	var zones []string
+	update := func(zone string) *dns.Msg {
+		zone = dns.Fqdn(zone)
+		msg, ok := updates[zone]
+		if !ok {
+			msg = new(dns.Msg)
+			msg.SetUpdate(zone)
+			updates[zone] = msg
+			zones = append(zones, zone)
+		}
+		return msg
+	}
+
+	reverseName, err := dns.ReverseAddr(change.IP.String())
+	if err != nil {
+		return err
+	}
+	if old := change.Remove; old != nil {
+		update(old.Zone).Remove([]dns.RR{addressRecord(*old)})
+		update(old.ReverseZone).RemoveRRset([]dns.RR{&dns.PTR{Hdr: dns.RR_Header{Name: reverseName, Rrtype: dns.TypePTR}}})
+	}
+	if registration := change.Add; registration != nil {
+		update(registration.Zone).Insert([]dns.RR{addressRecord(*registration)})
+		msg := update(registration.ReverseZone)
+		ptr := &dns.PTR{
+			Hdr: dns.RR_Header{Name: reverseName, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: uint32(registration.TTL)},
+			Ptr: dns.Fqdn(registration.Name),
+		}
+		// An address has a single PTR record, whoever wrote it before.
+		msg.RemoveRRset([]dns.RR{ptr})
+		msg.Insert([]dns.RR{ptr})
+	}
+
+	for _, zone := range zones {
+		resp, err := c.exchange(ctx, updates[zone])
+		if err != nil {
+			return fmt.Errorf("update zone %s: %w", zone, err)
+		}
+		if resp.Rcode != dns.RcodeSuccess {
+			return fmt.Errorf("update zone %s: server answered %s", zone, dns.RcodeToString[resp.Rcode])
+		}
+	}
+	return nil
+}
+
+// Serves reports whether the server answers with both the address record
+// and the PTR record of registration.
+func (c *Client) Serves(ctx context.Context, registration domain.DNSRegistration) (bool, error) {
+	address := addressRecord(registration)
+	found, err := c.lookup(ctx, address.Header().Name, address.Header().Rrtype, func(rr dns.RR) bool {
+		switch rr := rr.(type) {
+		case *dns.A:
+			return rr.A.Equal(net.IP(registration.IP.AsSlice()))
+		case *dns.AAAA:
+			return rr.AAAA.Equal(net.IP(registration.IP.AsSlice()))
+		}
+		return false
+	})
+	if err != nil || !found {
+		return false, err
+	}
+	reverseName, err := dns.ReverseAddr(registration.IP.String())
+	if err != nil {
+		return false, err
+	}
+	return c.lookup(ctx, reverseName, dns.TypePTR, func(rr dns.RR) bool {
+		ptr, ok := rr.(*dns.PTR)
+		return ok && strings.EqualFold(ptr.Ptr, dns.Fqdn(registration.Name))
+	})
+}
+
+func (c *Client) lookup(ctx context.Context, name string, qtype uint16, match func(dns.RR) bool) (bool, error) {
+	req := new(dns.Msg)
+	req.SetQuestion(name, qtype)
+	req.RecursionDesired = false
+	resp, err := c.exchange(ctx, req)
+	if err != nil {
+		return false, fmt.Errorf("query %s %s: %w", name, dns.TypeToString[qtype], err)
+	}
+	switch resp.Rcode {
+	case dns.RcodeSuccess:
+		return slices.ContainsFunc(resp.Answer, match), nil
+	case dns.RcodeNameError:
+		return false, nil
+	default:
+		return false, fmt.Errorf("query %s %s: server answered %s", name, dns.TypeToString[qtype], dns.RcodeToString[resp.Rcode])
+	}
+}
+
+// exchange signs msg and sends it; the client rejects responses whose
+// signature does not verify.
+func (c *Client) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	msg.SetTsig(c.keyName, c.algorithm, tsigFudge, time.Now().Unix())
+	client := &dns.Client{Net: "tcp", Timeout: c.timeout, TsigSecret: map[string]string{c.keyName: c.secret}}
+	resp, _, err := client.ExchangeContext(ctx, msg, c.server)
+	return resp, err
+}
+
+// addressRecord is the A or AAAA record of registration.
+func addressRecord(registration domain.DNSRegistration) dns.RR {
+	header := dns.RR_Header{Name: dns.Fqdn(registration.Name), Class: dns.ClassINET, Ttl: uint32(registration.TTL)}
+	if registration.IP.Is4() {
+		header.Rrtype = dns.TypeA
+		return &dns.A{Hdr: header, A: net.IP(registration.IP.AsSlice())}
+	}
+	header.Rrtype = dns.TypeAAAA
+	return &dns.AAAA{Hdr: header, AAAA: net.IP(registration.IP.AsSlice())}
+}
@@ -0,0 +1,217 @@
+package dnsupdate
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/miekg/dns"
+)
+
+const (
+	testKeyName = "ipam-key"
+	testSecret  = "c2VjcmV0LXNoYXJlZC13aXRoLWJpbmQ="
+)
+
+// fakeBIND stands in for BIND: it accepts TSIG-signed updates to the zones
+// whose update policy allows the key, refuses the others, and answers
+// queries from what the updates wrote.
+type fakeBIND struct {
+	zones []string
+
+	mu      sync.Mutex
+	records []dns.RR
+}
+
+func (b *fakeBIND) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	if req.IsTsig() == nil || w.TsigStatus() != nil {
+		_ = w.WriteMsg(resp.SetRcode(req, dns.RcodeNotAuth))
+		return
+	}
+	resp.SetReply(req)
+	switch req.Opcode {
+	case dns.OpcodeUpdate:
+		if !b.servesZone(req.Question[0].Name) {
+			resp.Rcode = dns.RcodeRefused
+			break
+		}
+		b.mu.Lock()
+		for _, rr := range req.Ns {
+			b.update(rr)
+		}
+		b.mu.Unlock()
+	default:
+		question := req.Question[0]
+		resp.Authoritative = true
+		b.mu.Lock()
+		exists := false
+		for _, rr := range b.records {
+			if strings.EqualFold(rr.Header().Name, question.Name) {
+				exists = true
+				if rr.Header().Rrtype == question.Qtype {
+					resp.Answer = append(resp.Answer, rr)
+				}
+			}
+		}
+		b.mu.Unlock()
+		if !exists {
+			resp.Rcode = dns.RcodeNameError
+		}
+	}
+	resp.SetTsig(req.IsTsig().Hdr.Name, dns.HmacSHA256, tsigFudge, time.Now().Unix())
+	_ = w.WriteMsg(resp)
+}
+
+func (b *fakeBIND) servesZone(zone string) bool {
+	for _, served := range b.zones {
+		if strings.EqualFold(dns.Fqdn(served), zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// update applies one RFC 2136 section 2.5 operation.
+func (b *fakeBIND) update(op dns.RR) {
+	header := op.Header()
+	kept := b.records[:0]
+	for _, rr := range b.records {
+		sameSet := strings.EqualFold(rr.Header().Name, header.Name) && rr.Header().Rrtype == header.Rrtype
+		switch {
+		case header.Class == dns.ClassANY && sameSet:
+		case header.Class == dns.ClassNONE && sameSet && rdata(rr) == rdata(op):
+		default:
+			kept = append(kept, rr)
+		}
+	}
+	b.records = kept
+	if header.Class == dns.ClassINET {
+		for _, rr := range b.records {
+			if rr.Header().Rrtype == header.Rrtype && strings.EqualFold(rr.Header().Name, header.Name) && rdata(rr) == rdata(op) {
+				return
+			}
+		}
+		b.records = append(b.records, dns.Copy(op))
+	}
+}
+
+func (b *fakeBIND) lookup(name string, rrtype uint16) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for _, rr := range b.records {
+		if strings.EqualFold(rr.Header().Name, name) && rr.Header().Rrtype == rrtype {
+			out = append(out, rdata(rr))
+		}
+	}
+	return out
+}
+
+func rdata(rr dns.RR) string {
+	return strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String()))
+}
+
+func startFakeBIND(t *testing.T, bind *fakeBIND) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           bind,
+		TsigSecret:        map[string]string{dns.Fqdn(testKeyName): testSecret},
+		NotifyStartedFunc: func() { close(started) },
+		// The default accept func answers UPDATE with NOTIMP.
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return listener.Addr().String()
+}
+
+func newTestClient(addr, secret string) *Client {
+	return NewClient(Config{
+		Server:        addr,
+		TSIGKeyName:   testKeyName,
+		TSIGSecret:    secret,
+		TSIGAlgorithm: "hmac-sha256",
+		Timeout:       2 * time.Second,
+	})
+}
+
+func TestClientAppliesAndReplacesRecords(t *testing.T) {
+	bind := &fakeBIND{zones: []string{"lab.example.com", "2.1.10.in-addr.arpa"}}
+	bind.records = append(bind.records, &dns.PTR{
+		Hdr: dns.RR_Header{Name: "5.2.1.10.in-addr.arpa.", Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: 60},
+		Ptr: "stale.example.com.",
+	})
+	client := newTestClient(startFakeBIND(t, bind), testSecret)
+	ctx := context.Background()
+	ip := netip.MustParseAddr("10.1.2.5")
+	web1 := domain.DNSRegistration{IP: ip, Name: "web-1.lab.example.com", Zone: "lab.example.com", ReverseZone: "2.1.10.in-addr.arpa", TTL: 300}
+	web2 := web1
+	web2.Name = "web-2.lab.example.com"
+
+	if err := client.Apply(ctx, domain.DNSChange{IP: ip, Add: &web1}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := bind.lookup("5.2.1.10.in-addr.arpa.", dns.TypePTR); len(got) != 1 || got[0] != "web-1.lab.example.com." {
+		t.Fatalf("expected the stale PTR replaced, got %v", got)
+	}
+	if serves, err := client.Serves(ctx, web1); err != nil || !serves {
+		t.Fatalf("expected web-1 served, got %v %v", serves, err)
+	}
+
+	if err := client.Apply(ctx, domain.DNSChange{IP: ip, Remove: &web1, Add: &web2}); err != nil {
+		t.Fatalf("apply rename: %v", err)
+	}
+	if got := bind.lookup("web-1.lab.example.com.", dns.TypeA); len(got) != 0 {
+		t.Fatalf("expected the old A record removed, got %v", got)
+	}
+	if got := bind.lookup("web-2.lab.example.com.", dns.TypeA); len(got) != 1 || got[0] != "10.1.2.5" {
+		t.Fatalf("expected the new A record, got %v", got)
+	}
+	if serves, err := client.Serves(ctx, web1); err != nil || serves {
+		t.Fatalf("expected web-1 no longer served, got %v %v", serves, err)
+	}
+
+	if err := client.Apply(ctx, domain.DNSChange{IP: ip, Remove: &web2}); err != nil {
+		t.Fatalf("apply delete: %v", err)
+	}
+	if len(bind.records) != 0 {
+		t.Fatalf("expected every record removed, got %v", bind.records)
+	}
+}
+
+func TestClientReportsRefusedUpdates(t *testing.T) {
+	bind := &fakeBIND{zones: []string{"lab.example.com"}}
+	addr := startFakeBIND(t, bind)
+	ip := netip.MustParseAddr("2001:db8::5")
+	registration := domain.DNSRegistration{IP: ip, Name: "web-1.lab.example.com", Zone: "lab.example.com", ReverseZone: "8.b.d.0.1.0.0.2.ip6.arpa", TTL: 300}
+
+	tests := []struct {
+		name   string
+		client *Client
+		want   string
+	}{
+		{name: "wrong secret", client: newTestClient(addr, "b3RoZXItc2VjcmV0"), want: "update zone lab.example.com.: server answered NOTAUTH"},
+		{name: "zone not allowed", client: newTestClient(addr, testSecret), want: "update zone 8.b.d.0.1.0.0.2.ip6.arpa.: server answered REFUSED"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.client.Apply(context.Background(), domain.DNSChange{IP: ip, Add: &registration})
+			if err == nil || err.Error() != test.want {
+				t.Fatalf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package dnsupdate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Config selects the DNS server that receives dynamic updates. Updates are
+// disabled while Server is empty.
+type Config struct {
+	Server            string
+	TSIGKeyName       string
+	TSIGSecret        string
+	TSIGAlgorithm     string
+	ReconcileInterval time.Duration
+	Timeout           time.Duration
+}
+
+var tsigAlgorithms = map[string]string{
+	"hmac-sha1":   dns.HmacSHA1,
+	"hmac-sha224": dns.HmacSHA224,
+	"hmac-sha256": dns.HmacSHA256,
+	"hmac-sha384": dns.HmacSHA384,
+	"hmac-sha512": dns.HmacSHA512,
+}
+
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		Server:            strings.TrimSpace(getenv("DNS_UPDATE_SERVER")),
+		TSIGKeyName:       strings.TrimSpace(getenv("DNS_UPDATE_TSIG_KEY")),
+		TSIGSecret:        strings.TrimSpace(getenv("DNS_UPDATE_TSIG_SECRET")),
+		TSIGAlgorithm:     strings.ToLower(strings.TrimSpace(getenv("DNS_UPDATE_TSIG_ALGORITHM"))),
+		ReconcileInterval: 15 * time.Minute,
+		Timeout:           5 * time.Second,
+	}
+	if cfg.TSIGAlgorithm == "" {
+		cfg.TSIGAlgorithm = "hmac-sha256"
+	}
+	if cfg.Server != "" {
+		if _, _, err := net.SplitHostPort(cfg.Server); err != nil {
+			cfg.Server = net.JoinHostPort(strings.Trim(cfg.Server, "[]"), "53")
+		}
+	}
+	if raw := strings.TrimSpace(getenv("DNS_UPDATE_RECONCILE_INTERVAL")); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("DNS_UPDATE_RECONCILE_INTERVAL: %w", err)
+		}
+		cfg.ReconcileInterval = interval
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c Config) Enabled() bool {
+	return c.Server != ""
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.TSIGKeyName == "" || c.TSIGSecret == "" {
+		return fmt.Errorf("DNS_UPDATE_TSIG_KEY and DNS_UPDATE_TSIG_SECRET are required when DNS_UPDATE_SERVER is set")
+	}
+	if _, ok := dns.IsDomainName(c.TSIGKeyName); !ok {
+		return fmt.Errorf("invalid DNS_UPDATE_TSIG_KEY %q", c.TSIGKeyName)
+	}
+	if _, err := base64.StdEncoding.DecodeString(c.TSIGSecret); err != nil {
+		return fmt.Errorf("DNS_UPDATE_TSIG_SECRET must be base64: %w", err)
+	}
+	if _, ok := tsigAlgorithms[c.TSIGAlgorithm]; !ok {
+		return fmt.Errorf("unsupported DNS_UPDATE_TSIG_ALGORITHM %q", c.TSIGAlgorithm)
+	}
+	if c.ReconcileInterval <= 0 {
+		return fmt.Errorf("dns update reconcile interval must be positive")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("dns update timeout must be positive")
+	}
+	return nil
+}
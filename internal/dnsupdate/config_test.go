@@ -0,0 +1,82 @@
+package dnsupdate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnvDefaultsToDisabled(t *testing.T) {
+	cfg, err := ConfigFromEnv(func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Enabled() || cfg.TSIGAlgorithm != "hmac-sha256" || cfg.ReconcileInterval != 15*time.Minute {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestConfigFromEnvAddsDefaultPort(t *testing.T) {
+	for server, want := range map[string]string{
+		"ns1.example.com":      "ns1.example.com:53",
+		"10.0.0.53:5353":       "10.0.0.53:5353",
+		"2001:db8::53":         "[2001:db8::53]:53",
+		"[2001:db8::53]:10053": "[2001:db8::53]:10053",
+	} {
+		env := map[string]string{
+			"DNS_UPDATE_SERVER":      server,
+			"DNS_UPDATE_TSIG_KEY":    "ipam-key",
+			"DNS_UPDATE_TSIG_SECRET": testSecret,
+		}
+		cfg, err := ConfigFromEnv(func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("ConfigFromEnv(%q): %v", server, err)
+		}
+		if !cfg.Enabled() || cfg.Server != want {
+			t.Fatalf("expected server %q, got %+v", want, cfg)
+		}
+	}
+}
+
+func TestConfigRejectsIncompleteTSIG(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "missing secret",
+			env:  map[string]string{"DNS_UPDATE_SERVER": "ns1", "DNS_UPDATE_TSIG_KEY": "ipam-key"},
+			want: "DNS_UPDATE_TSIG_SECRET are required",
+		},
+		{
+			name: "secret not base64",
+			env:  map[string]string{"DNS_UPDATE_SERVER": "ns1", "DNS_UPDATE_TSIG_KEY": "ipam-key", "DNS_UPDATE_TSIG_SECRET": "not base64!"},
+			want: "must be base64",
+		},
+		{
+			name: "algorithm",
+			env: map[string]string{
+				"DNS_UPDATE_SERVER": "ns1", "DNS_UPDATE_TSIG_KEY": "ipam-key", "DNS_UPDATE_TSIG_SECRET": testSecret,
+				"DNS_UPDATE_TSIG_ALGORITHM": "hmac-md5",
+			},
+			want: "unsupported DNS_UPDATE_TSIG_ALGORITHM",
+		},
+		{
+			name: "interval",
+			env: map[string]string{
+				"DNS_UPDATE_SERVER": "ns1", "DNS_UPDATE_TSIG_KEY": "ipam-key", "DNS_UPDATE_TSIG_SECRET": testSecret,
+				"DNS_UPDATE_RECONCILE_INTERVAL": "0s",
+			},
+			want: "reconcile interval must be positive",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ConfigFromEnv(func(key string) string { return test.env[key] })
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+}
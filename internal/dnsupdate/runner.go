@@ -0,0 +1,80 @@
+package dnsupdate
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultBatchSize    = 50
+)
+
+// Runner drains the update queue every few seconds and reconciles against
+// the server at startup and every ReconcileInterval after that.
+type Runner struct {
+	service           domain.DNSUpdateService
+	logger            *slog.Logger
+	interval          time.Duration
+	reconcileInterval time.Duration
+	batchSize         int32
+	now               func() time.Time
+}
+
+func NewRunner(service domain.DNSUpdateService, cfg Config, logger *slog.Logger) *Runner {
+	return &Runner{
+		service:           service,
+		logger:            logger,
+		interval:          defaultPollInterval,
+		reconcileInterval: cfg.ReconcileInterval,
+		batchSize:         defaultBatchSize,
+		now:               time.Now,
+	}
+}
+
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	lastReconcile := time.Time{}
+	for {
+		if r.now().Sub(lastReconcile) >= r.reconcileInterval {
+			lastReconcile = r.now()
+			r.reconcile(ctx)
+		}
+		r.ProcessOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOnce sends one batch of queued updates and logs the failures.
+func (r *Runner) ProcessOnce(ctx context.Context) {
+	results, err := r.service.ProcessUpdates(ctx, r.batchSize)
+	for _, result := range results {
+		if result.Err != nil {
+			r.logger.WarnContext(ctx, "dns update failed", "ip", result.IP, "attempts", result.Attempts, "err", result.Err)
+		}
+	}
+	if err != nil && ctx.Err() == nil {
+		r.logger.ErrorContext(ctx, "dns update cycle failed", "err", err)
+	}
+}
+
+func (r *Runner) reconcile(ctx context.Context) {
+	queued, err := r.service.Reconcile(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "reconciling dns records failed", "err", err)
+		}
+		return
+	}
+	if queued > 0 {
+		r.logger.InfoContext(ctx, "queued dns updates for drifted records", "queued", queued)
+	}
+}
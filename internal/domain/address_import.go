@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// importedAddress is an address found by the lease or zone import. A nil MAC
+// or SeenAt leaves the stored value alone.
+type importedAddress struct {
+	IP       netip.Addr
+	Hostname string
+	MAC      net.HardwareAddr
+	SeenAt   *time.Time
+}
+
+// addressImporter records imported addresses against the subnets that
+// contain them. It caches each subnet's addresses and applies its own writes
+// to the cache, so a dry run sees the same state as a real import.
+type addressImporter struct {
+	network     NetworkService
+	subnets     []Subnet
+	ipsBySubnet map[int64][]IPAddress
+	dryRun      bool
+}
+
+func newAddressImporter(network NetworkService, subnets []Subnet, dryRun bool) *addressImporter {
+	return &addressImporter{network: network, subnets: subnets, ipsBySubnet: make(map[int64][]IPAddress), dryRun: dryRun}
+}
+
+// record creates the address, or fills in an existing one: a hostname only
+// when it has none, the MAC address when one is given and the last-seen time
+// when it is later than the stored one.
+func (i *addressImporter) record(ctx context.Context, address importedAddress) (importOutcome, error) {
+	subnet, err := containingSubnet(i.subnets, address.IP)
+	if err != nil {
+		return importUnchanged, err
+	}
+	if err := validateIPInSubnet(subnet.CIDR, address.IP); err != nil {
+		return importUnchanged, err
+	}
+	ips, loaded := i.ipsBySubnet[subnet.ID]
+	if !loaded {
+		if ips, err = i.network.ListIPs(ctx, subnet.ID); err != nil {
+			return importUnchanged, fmt.Errorf("list ips: %w", err)
+		}
+		i.ipsBySubnet[subnet.ID] = ips
+	}
+	if len(address.MAC) > 0 {
+		for _, other := range ips {
+			if other.IP != address.IP && bytes.Equal(other.MACAddress, address.MAC) {
+				return importUnchanged, fmt.Errorf("mac address %s is already reserved for %s", address.MAC, other.IP)
+			}
+		}
+	}
+
+	index := slices.IndexFunc(ips, func(ip IPAddress) bool { return ip.IP == address.IP })
+	if index < 0 {
+		created := IPAddress{IP: address.IP, Hostname: address.Hostname, MACAddress: address.MAC, LastSeenAt: address.SeenAt, SubnetID: subnet.ID}
+		if !i.dryRun {
+			created, err = i.network.CreateIP(ctx, subnet.ID, CreateIPInput{
+				IP:         address.IP.String(),
+				Hostname:   address.Hostname,
+				MACAddress: address.MAC.String(),
+				LastSeenAt: address.SeenAt,
+			})
+			if err != nil {
+				return importUnchanged, fmt.Errorf("create ip: %w", err)
+			}
+		}
+		i.ipsBySubnet[subnet.ID] = append(ips, created)
+		return importCreated, nil
+	}
+
+	existing := ips[index]
+	updated := existing
+	if updated.Hostname == "" {
+		updated.Hostname = address.Hostname
+	}
+	if len(address.MAC) > 0 {
+		updated.MACAddress = address.MAC
+	}
+	if address.SeenAt != nil && (existing.LastSeenAt == nil || address.SeenAt.After(*existing.LastSeenAt)) {
+		updated.LastSeenAt = address.SeenAt
+	}
+	if updated.Hostname == existing.Hostname && bytes.Equal(updated.MACAddress, existing.MACAddress) && updated.LastSeenAt == existing.LastSeenAt {
+		return importUnchanged, nil
+	}
+	if !i.dryRun {
+		mac := updated.MACAddress.String()
+		updated, err = i.network.UpdateIPHostname(ctx, subnet.ID, existing.ID, UpdateIPInput{
+			Hostname:   updated.Hostname,
+			MACAddress: &mac,
+			LastSeenAt: updated.LastSeenAt,
+		})
+		if err != nil {
+			return importUnchanged, fmt.Errorf("update ip: %w", err)
+		}
+	}
+	ips[index] = updated
+	return importUpdated, nil
+}
+
+// containingSubnet returns the most specific subnet that contains ip. The
+// same CIDR in two sites cannot be told apart, so that match is an error.
+func containingSubnet(subnets []Subnet, ip netip.Addr) (Subnet, error) {
+	var match Subnet
+	found, ambiguous := false, false
+	for _, subnet := range subnets {
+		if !subnet.CIDR.IsValid() || !subnet.CIDR.Contains(ip) {
+			continue
+		}
+		switch {
+		case !found || subnet.CIDR.Bits() > match.CIDR.Bits():
+			match, found, ambiguous = subnet, true, false
+		case subnet.CIDR.Bits() == match.CIDR.Bits():
+			ambiguous = true
+		}
+	}
+	if !found {
+		return Subnet{}, fmt.Errorf("no subnet contains %s", ip)
+	}
+	if ambiguous {
+		return Subnet{}, fmt.Errorf("%s matches more than one %s subnet", ip, match.CIDR)
+	}
+	return match, nil
+}
+
+func (r *ImportResult) count(outcome importOutcome) {
+	switch outcome {
+	case importCreated:
+		r.Created++
+	case importUpdated:
+		r.Updated++
+	}
+}
+
+// sortRowErrors orders errors by row, keeping the order of errors on the
+// same row.
+func sortRowErrors(rowErrors []RowError) []RowError {
+	slices.SortStableFunc(rowErrors, func(a, b RowError) int { return cmp.Compare(a.Row, b.Row) })
+	return rowErrors
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// retryBackoff doubles the delay after every failed attempt, starting at
+// base and capped at maxDelay.
+func retryBackoff(base, maxDelay time.Duration, attempts int32) time.Duration {
+	delay := base
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
+
+// retryJitter adds up to a fifth of delay, so retries that failed together
+// do not all come due at once.
+func retryJitter(delay time.Duration) time.Duration {
+	return delay + rand.N(delay/5+1)
+}
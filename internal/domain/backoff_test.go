@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryJitterAddsAtMostAFifth(t *testing.T) {
+	for range 100 {
+		if got := retryJitter(time.Minute); got < time.Minute || got > time.Minute+12*time.Second {
+			t.Fatalf("jittered delay %s is outside [1m, 1m12s]", got)
+		}
+	}
+}
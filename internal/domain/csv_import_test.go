@@ -72,7 +72,8 @@ func (s *importNetworkStub) ListIPs(_ context.Context, subnetID int64) ([]IPAddr
 	return append([]IPAddress(nil), s.ips[subnetID]...), nil
 }
 func (s *importNetworkStub) CreateIP(_ context.Context, subnetID int64, input CreateIPInput) (IPAddress, error) {
-	ip := IPAddress{ID: IPAddressID(uuid.NewString()), IP: mustImportAddr(input.IP), Hostname: input.Hostname, SubnetID: subnetID}
+	mac, _ := parseMACAddress(input.MACAddress)
+	ip := IPAddress{ID: IPAddressID(uuid.NewString()), IP: mustImportAddr(input.IP), Hostname: input.Hostname, MACAddress: mac, LastSeenAt: input.LastSeenAt, SubnetID: subnetID}
 	s.ips[subnetID] = append(s.ips[subnetID], ip)
 	s.createdIPs++
 	return ip, nil
@@ -81,6 +82,12 @@ func (s *importNetworkStub) UpdateIPHostname(_ context.Context, subnetID int64,
 	for i := range s.ips[subnetID] {
 		if s.ips[subnetID][i].ID == id {
 			s.ips[subnetID][i].Hostname = input.Hostname
+			if input.MACAddress != nil {
+				s.ips[subnetID][i].MACAddress, _ = parseMACAddress(*input.MACAddress)
+			}
+			if input.LastSeenAt != nil {
+				s.ips[subnetID][i].LastSeenAt = input.LastSeenAt
+			}
 			s.updatedIPs++
 			return s.ips[subnetID][i], nil
 		}
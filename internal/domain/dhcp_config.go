@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// RenderDHCPConfig renders subnets for a DHCP server. Subnets are ordered by
+// ID and reservations by address, so the same data always renders the same
+// bytes and the output can be kept under version control.
+func RenderDHCPConfig(format DHCPConfigFormat, subnets []DHCPSubnetConfig) ([]byte, error) {
+	subnets = slices.Clone(subnets)
+	slices.SortFunc(subnets, func(a, b DHCPSubnetConfig) int { return cmp.Compare(a.Subnet.ID, b.Subnet.ID) })
+	for i := range subnets {
+		subnets[i].Reservations = slices.Clone(subnets[i].Reservations)
+		slices.SortFunc(subnets[i].Reservations, func(a, b DHCPReservation) int { return a.IP.Compare(b.IP) })
+	}
+	switch format {
+	case DHCPConfigKea:
+		return renderKeaConfig(subnets)
+	case DHCPConfigDnsmasq:
+		return renderDnsmasqConfig(subnets), nil
+	default:
+		return nil, InvalidField("format", "format must be kea or dnsmasq")
+	}
+}
+
+type keaConfig struct {
+	Dhcp4 *keaDhcp4 `json:"Dhcp4,omitempty"`
+	Dhcp6 *keaDhcp6 `json:"Dhcp6,omitempty"`
+}
+
+type keaDhcp4 struct {
+	Subnets []keaSubnet `json:"subnet4"`
+}
+
+type keaDhcp6 struct {
+	Subnets []keaSubnet `json:"subnet6"`
+}
+
+type keaSubnet struct {
+	ID           int64            `json:"id"`
+	Subnet       string           `json:"subnet"`
+	Pools        []keaPool        `json:"pools"`
+	OptionData   []keaOption      `json:"option-data"`
+	Reservations []keaReservation `json:"reservations"`
+}
+
+type keaPool struct {
+	Pool string `json:"pool"`
+}
+
+type keaOption struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+type keaReservation struct {
+	HWAddress   string   `json:"hw-address"`
+	IPAddress   string   `json:"ip-address,omitempty"`
+	IPAddresses []string `json:"ip-addresses,omitempty"`
+	Hostname    string   `json:"hostname,omitempty"`
+}
+
+// renderKeaConfig writes the subnet4 and subnet6 lists of a Kea
+// configuration; IPv4 and IPv6 subnets are served by separate daemons.
+func renderKeaConfig(subnets []DHCPSubnetConfig) ([]byte, error) {
+	var config keaConfig
+	for _, subnet := range subnets {
+		is4 := subnet.Subnet.CIDR.Addr().Is4()
+		entry := keaSubnet{
+			ID:           subnet.Subnet.ID,
+			Subnet:       subnet.Subnet.CIDR.String(),
+			Pools:        []keaPool{},
+			OptionData:   []keaOption{},
+			Reservations: []keaReservation{},
+		}
+		for _, pool := range subnet.Subnet.DHCPPools {
+			entry.Pools = append(entry.Pools, keaPool{Pool: pool.Start.String() + " - " + pool.End.String()})
+		}
+		servers := strings.Join(addrStrings(dhcpDNSServers(subnet.Subnet)), ", ")
+		if is4 {
+			if subnet.Subnet.Gateway.IsValid() {
+				entry.OptionData = append(entry.OptionData, keaOption{Name: "routers", Data: subnet.Subnet.Gateway.String()})
+			}
+			if servers != "" {
+				entry.OptionData = append(entry.OptionData, keaOption{Name: "domain-name-servers", Data: servers})
+			}
+			if subnet.DomainName != "" {
+				entry.OptionData = append(entry.OptionData, keaOption{Name: "domain-name", Data: subnet.DomainName})
+			}
+		} else {
+			if servers != "" {
+				entry.OptionData = append(entry.OptionData, keaOption{Name: "dns-servers", Data: servers})
+			}
+			if subnet.DomainName != "" {
+				entry.OptionData = append(entry.OptionData, keaOption{Name: "domain-search", Data: subnet.DomainName})
+			}
+		}
+		for _, reservation := range subnet.Reservations {
+			keaReservation := keaReservation{HWAddress: reservation.MACAddress.String(), Hostname: reservation.Hostname}
+			if is4 {
+				keaReservation.IPAddress = reservation.IP.String()
+			} else {
+				keaReservation.IPAddresses = []string{reservation.IP.String()}
+			}
+			entry.Reservations = append(entry.Reservations, keaReservation)
+		}
+		if is4 {
+			if config.Dhcp4 == nil {
+				config.Dhcp4 = &keaDhcp4{}
+			}
+			config.Dhcp4.Subnets = append(config.Dhcp4.Subnets, entry)
+		} else {
+			if config.Dhcp6 == nil {
+				config.Dhcp6 = &keaDhcp6{}
+			}
+			config.Dhcp6.Subnets = append(config.Dhcp6.Subnets, entry)
+		}
+	}
+	body, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+// renderDnsmasqConfig tags every subnet's range so its options and hosts
+// only apply to clients on that subnet.
+func renderDnsmasqConfig(subnets []DHCPSubnetConfig) []byte {
+	var out bytes.Buffer
+	for i, subnet := range subnets {
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+		prefix := subnet.Subnet.CIDR
+		is4 := prefix.Addr().Is4()
+		tag := fmt.Sprintf("subnet-%d", subnet.Subnet.ID)
+		mask := fmt.Sprint(prefix.Bits())
+		if is4 {
+			mask = net.IP(net.CIDRMask(prefix.Bits(), 32)).String()
+		}
+		fmt.Fprintf(&out, "# subnet %d: %s\n", subnet.Subnet.ID, prefix)
+		if len(subnet.Subnet.DHCPPools) == 0 {
+			fmt.Fprintf(&out, "dhcp-range=set:%s,%s,static,%s\n", tag, prefix.Masked().Addr(), mask)
+		}
+		for _, pool := range subnet.Subnet.DHCPPools {
+			fmt.Fprintf(&out, "dhcp-range=set:%s,%s,%s,%s\n", tag, pool.Start, pool.End, mask)
+		}
+		servers := addrStrings(dhcpDNSServers(subnet.Subnet))
+		if is4 {
+			if subnet.Subnet.Gateway.IsValid() {
+				fmt.Fprintf(&out, "dhcp-option=tag:%s,option:router,%s\n", tag, subnet.Subnet.Gateway)
+			}
+			if len(servers) > 0 {
+				fmt.Fprintf(&out, "dhcp-option=tag:%s,option:dns-server,%s\n", tag, strings.Join(servers, ","))
+			}
+			if subnet.DomainName != "" {
+				fmt.Fprintf(&out, "dhcp-option=tag:%s,option:domain-name,%s\n", tag, subnet.DomainName)
+			}
+		} else {
+			if len(servers) > 0 {
+				fmt.Fprintf(&out, "dhcp-option=tag:%s,option6:dns-server,[%s]\n", tag, strings.Join(servers, "],["))
+			}
+			if subnet.DomainName != "" {
+				fmt.Fprintf(&out, "dhcp-option=tag:%s,option6:domain-search,%s\n", tag, subnet.DomainName)
+			}
+		}
+		for _, reservation := range subnet.Reservations {
+			address := reservation.IP.String()
+			if !is4 {
+				address = "[" + address + "]"
+			}
+			fmt.Fprintf(&out, "dhcp-host=%s,%s", reservation.MACAddress, address)
+			if reservation.Hostname != "" {
+				fmt.Fprintf(&out, ",%s", reservation.Hostname)
+			}
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+// dhcpDNSServers returns the subnet's DNS servers of its own address family,
+// the only ones a DHCP option for the subnet can carry.
+func dhcpDNSServers(subnet Subnet) []netip.Addr {
+	var servers []netip.Addr
+	for _, server := range subnet.DNSServers {
+		if server.Is4() == subnet.CIDR.Addr().Is4() {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+func addrStrings(addrs []netip.Addr) []string {
+	out := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		out = append(out, addr.String())
+	}
+	return out
+}
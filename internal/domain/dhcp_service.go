@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DHCPConfigFormat selects the DHCP server a configuration is rendered for.
+type DHCPConfigFormat string
+
+const (
+	DHCPConfigKea     DHCPConfigFormat = "kea"
+	DHCPConfigDnsmasq DHCPConfigFormat = "dnsmasq"
+)
+
+// ParseDHCPConfigFormat defaults to Kea when value is empty.
+func ParseDHCPConfigFormat(value string) (DHCPConfigFormat, error) {
+	switch format := DHCPConfigFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return DHCPConfigKea, nil
+	case DHCPConfigKea, DHCPConfigDnsmasq:
+		return format, nil
+	default:
+		return "", InvalidField("format", "format must be kea or dnsmasq")
+	}
+}
+
+// DHCPSubnetConfig is everything rendered for one subnet. DomainName is the
+// forward zone of the subnet, or empty when it has none.
+type DHCPSubnetConfig struct {
+	Subnet       Subnet
+	DomainName   string
+	Reservations []DHCPReservation
+}
+
+// DHCPReservation binds a MAC address to an address. Hostname is empty when
+// the address has no hostname a DHCP server would accept.
+type DHCPReservation struct {
+	MACAddress net.HardwareAddr
+	IP         netip.Addr
+	Hostname   string
+}
+
+type dhcpService struct {
+	subnets SubnetRepository
+	ips     IPRepository
+	sites   SiteRepository
+	zones   DNSZoneRepository
+}
+
+func NewDHCPService(subnets SubnetRepository, ips IPRepository, sites SiteRepository, zones DNSZoneRepository) DHCPService {
+	return &dhcpService{subnets: subnets, ips: ips, sites: sites, zones: zones}
+}
+
+func (s *dhcpService) UpdateSubnetSettings(ctx context.Context, input UpdateSubnetDHCPInput) (Subnet, error) {
+	subnet, err := s.subnets.FindByID(ctx, input.ID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Subnet{}, fmt.Errorf("%w: %w", ErrNotFound, ErrSubnetNotFound)
+		}
+		return Subnet{}, err
+	}
+	record := UpdateSubnetDHCPRecord{ID: input.ID, DNSServers: []netip.Addr{}, Pools: []DHCPPool{}}
+	if gateway := strings.TrimSpace(input.Gateway); gateway != "" {
+		if record.Gateway, err = netip.ParseAddr(gateway); err != nil {
+			return Subnet{}, InvalidField("gateway", "invalid gateway")
+		}
+		if err := validateIPInSubnet(subnet.CIDR, record.Gateway); err != nil {
+			return Subnet{}, InvalidField("gateway", "gateway "+err.Error())
+		}
+	}
+	for _, raw := range input.DNSServers {
+		server, err := netip.ParseAddr(strings.TrimSpace(raw))
+		if err != nil {
+			return Subnet{}, InvalidField("dns_servers", fmt.Sprintf("invalid dns server %q", raw))
+		}
+		if !slices.Contains(record.DNSServers, server) {
+			record.DNSServers = append(record.DNSServers, server)
+		}
+	}
+	for _, raw := range input.Pools {
+		start, startErr := netip.ParseAddr(strings.TrimSpace(raw.Start))
+		end, endErr := netip.ParseAddr(strings.TrimSpace(raw.End))
+		if startErr != nil || endErr != nil {
+			return Subnet{}, InvalidField("pools", fmt.Sprintf("invalid pool %s - %s", raw.Start, raw.End))
+		}
+		record.Pools = append(record.Pools, DHCPPool{Start: start, End: end})
+	}
+	if err := validateDHCPPools(subnet.CIDR, record.Pools); err != nil {
+		return Subnet{}, InvalidField("pools", err.Error())
+	}
+	updated, err := s.subnets.UpdateDHCP(ctx, record)
+	if errors.Is(err, ErrNotFound) {
+		return Subnet{}, fmt.Errorf("%w: %w", ErrNotFound, ErrSubnetNotFound)
+	}
+	return enrichSubnet(updated), err
+}
+
+func (s *dhcpService) SubnetConfig(ctx context.Context, subnetID int64, format DHCPConfigFormat) ([]byte, error) {
+	subnet, err := s.subnets.FindByID(ctx, subnetID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, ErrSubnetNotFound)
+		}
+		return nil, err
+	}
+	config, err := s.subnetConfig(ctx, subnet)
+	if err != nil {
+		return nil, err
+	}
+	return RenderDHCPConfig(format, []DHCPSubnetConfig{config})
+}
+
+func (s *dhcpService) SiteConfig(ctx context.Context, siteID uuid.UUID, format DHCPConfigFormat) ([]byte, error) {
+	if _, err := s.sites.FindByID(ctx, siteID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: site not found", ErrNotFound)
+		}
+		return nil, err
+	}
+	subnets, err := s.subnets.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var configs []DHCPSubnetConfig
+	for _, subnet := range subnets {
+		if subnet.SiteID != siteID {
+			continue
+		}
+		config, err := s.subnetConfig(ctx, subnet)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return RenderDHCPConfig(format, configs)
+}
+
+func (s *dhcpService) subnetConfig(ctx context.Context, subnet Subnet) (DHCPSubnetConfig, error) {
+	config := DHCPSubnetConfig{Subnet: subnet}
+	if s.zones != nil {
+		zone, err := s.zones.FindZoneForSubnet(ctx, subnet.ID)
+		switch {
+		case err == nil:
+			config.DomainName = zone.Name
+		case !errors.Is(err, ErrNotFound):
+			return DHCPSubnetConfig{}, err
+		}
+	}
+	ips, err := s.ips.ListBySubnetID(ctx, subnet.ID)
+	if err != nil {
+		return DHCPSubnetConfig{}, err
+	}
+	for _, ip := range ips {
+		if len(ip.MACAddress) == 0 {
+			continue
+		}
+		config.Reservations = append(config.Reservations, DHCPReservation{
+			MACAddress: ip.MACAddress,
+			IP:         ip.IP,
+			Hostname:   reservationHostname(ip.Hostname, config.DomainName),
+		})
+	}
+	return config, nil
+}
+
+// reservationHostname strips the subnet's domain from hostname and keeps it
+// only when what remains is a valid DNS name.
+func reservationHostname(hostname, domain string) string {
+	name := normalizeDNSName(hostname)
+	if domain != "" {
+		name = strings.TrimSuffix(name, "."+domain)
+	}
+	if validateDNSName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// validateDHCPPools checks that pools lie inside prefix, run upwards and do
+// not overlap.
+func validateDHCPPools(prefix netip.Prefix, pools []DHCPPool) error {
+	if err := validateDHCPSettingsInCIDR(prefix, netip.Addr{}, pools); err != nil {
+		return fmt.Errorf("%v is outside the subnet", err)
+	}
+	sorted := slices.Clone(pools)
+	slices.SortFunc(sorted, func(a, b DHCPPool) int { return a.Start.Compare(b.Start) })
+	for i, pool := range sorted {
+		if pool.End.Less(pool.Start) {
+			return fmt.Errorf("pool %s - %s ends before it starts", pool.Start, pool.End)
+		}
+		if i > 0 && !sorted[i-1].End.Less(pool.Start) {
+			return fmt.Errorf("pool %s - %s overlaps pool %s - %s", pool.Start, pool.End, sorted[i-1].Start, sorted[i-1].End)
+		}
+	}
+	return nil
+}
+
+// validateDHCPSettingsInCIDR reports the gateway or pool that prefix does not
+// contain. The network and broadcast addresses of IPv4 subnets are excluded.
+func validateDHCPSettingsInCIDR(prefix netip.Prefix, gateway netip.Addr, pools []DHCPPool) error {
+	if gateway.IsValid() && validateIPInSubnet(prefix, gateway) != nil {
+		return fmt.Errorf("gateway %s", gateway)
+	}
+	for _, pool := range pools {
+		if validateIPInSubnet(prefix, pool.Start) != nil || validateIPInSubnet(prefix, pool.End) != nil {
+			return fmt.Errorf("pool %s - %s", pool.Start, pool.End)
+		}
+	}
+	return nil
+}
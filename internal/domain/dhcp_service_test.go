@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type stubDHCPSiteRepository struct {
+	SiteRepository
+	sites []uuid.UUID
+}
+
+func (s stubDHCPSiteRepository) FindByID(_ context.Context, id uuid.UUID) (Site, error) {
+	for _, site := range s.sites {
+		if site == id {
+			return Site{ID: id}, nil
+		}
+	}
+	return Site{}, ErrNotFound
+}
+
+func mustMAC(t *testing.T, value string) net.HardwareAddr {
+	t.Helper()
+	mac, err := net.ParseMAC(value)
+	if err != nil {
+		t.Fatalf("parse mac %q: %v", value, err)
+	}
+	return mac
+}
+
+func TestUpdateSubnetSettingsValidatesAgainstCIDR(t *testing.T) {
+	subnets := stubSubnetRepository{findFn: func(context.Context, int64) (Subnet, error) {
+		return Subnet{ID: 7, CIDR: netip.MustParsePrefix("10.0.0.0/24")}, nil
+	}}
+	svc := NewDHCPService(subnets, stubIPRepository{}, nil, nil)
+
+	tests := []struct {
+		name  string
+		input UpdateSubnetDHCPInput
+		want  string
+	}{
+		{name: "gateway outside", input: UpdateSubnetDHCPInput{Gateway: "10.0.1.1"}, want: "gateway ip not in subnet"},
+		{name: "gateway broadcast", input: UpdateSubnetDHCPInput{Gateway: "10.0.0.255"}, want: "gateway network or broadcast ip"},
+		{name: "dns server", input: UpdateSubnetDHCPInput{DNSServers: []string{"ns1"}}, want: `invalid dns server "ns1"`},
+		{name: "pool outside", input: UpdateSubnetDHCPInput{Pools: []DHCPPoolInput{{Start: "10.0.0.200", End: "10.0.1.10"}}}, want: "pool 10.0.0.200 - 10.0.1.10 is outside the subnet"},
+		{name: "pool reversed", input: UpdateSubnetDHCPInput{Pools: []DHCPPoolInput{{Start: "10.0.0.200", End: "10.0.0.100"}}}, want: "pool 10.0.0.200 - 10.0.0.100 ends before it starts"},
+		{
+			name:  "pools overlap",
+			input: UpdateSubnetDHCPInput{Pools: []DHCPPoolInput{{Start: "10.0.0.150", End: "10.0.0.250"}, {Start: "10.0.0.100", End: "10.0.0.150"}}},
+			want:  "pool 10.0.0.150 - 10.0.0.250 overlaps pool 10.0.0.100 - 10.0.0.150",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.input.ID = 7
+			_, err := svc.UpdateSubnetSettings(context.Background(), test.input)
+			var validation *ValidationError
+			if !errors.As(err, &validation) || validation.Fields[0].Message != test.want {
+				t.Fatalf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestUpdateSubnetSettingsStoresParsedSettings(t *testing.T) {
+	var stored UpdateSubnetDHCPRecord
+	subnets := stubSubnetRepository{
+		findFn: func(context.Context, int64) (Subnet, error) {
+			return Subnet{ID: 7, CIDR: netip.MustParsePrefix("10.0.0.0/24")}, nil
+		},
+		dhcpFn: func(_ context.Context, input UpdateSubnetDHCPRecord) (Subnet, error) {
+			stored = input
+			return Subnet{ID: input.ID}, nil
+		},
+	}
+	_, err := NewDHCPService(subnets, stubIPRepository{}, nil, nil).UpdateSubnetSettings(context.Background(), UpdateSubnetDHCPInput{
+		ID:         7,
+		Gateway:    "10.0.0.1",
+		DNSServers: []string{"10.0.0.53", " 10.0.0.53", "2001:db8::53"},
+		Pools:      []DHCPPoolInput{{Start: "10.0.0.100", End: "10.0.0.199"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateSubnetSettings: %v", err)
+	}
+	want := UpdateSubnetDHCPRecord{
+		ID:         7,
+		Gateway:    netip.MustParseAddr("10.0.0.1"),
+		DNSServers: []netip.Addr{netip.MustParseAddr("10.0.0.53"), netip.MustParseAddr("2001:db8::53")},
+		Pools:      []DHCPPool{{Start: netip.MustParseAddr("10.0.0.100"), End: netip.MustParseAddr("10.0.0.199")}},
+	}
+	if !reflect.DeepEqual(stored, want) {
+		t.Fatalf("unexpected record:\n got %+v\nwant %+v", stored, want)
+	}
+}
+
+func TestUpdateSubnetRejectsCIDRWithoutGateway(t *testing.T) {
+	subnets := stubSubnetRepository{
+		findFn: func(context.Context, int64) (Subnet, error) {
+			return Subnet{ID: 7, CIDR: netip.MustParsePrefix("10.0.0.0/24"), Gateway: netip.MustParseAddr("10.0.0.1")}, nil
+		},
+		updateFn: func(context.Context, UpdateSubnetRecord) (Subnet, error) {
+			t.Fatal("update should not be called")
+			return Subnet{}, nil
+		},
+	}
+	siteID := uuid.New()
+	_, err := NewNetworkService(subnets, stubIPRepository{}).UpdateSubnet(context.Background(), UpdateSubnetInput{ID: 7, CIDR: "10.0.0.128/25", SiteID: &siteID})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Fields[0].Message != "cidr no longer contains the subnet's gateway 10.0.0.1" {
+		t.Fatalf("expected cidr validation error, got %v", err)
+	}
+}
+
+func TestUpdateIPHostnameKeepsMACAddressWhenOmitted(t *testing.T) {
+	mac := mustMAC(t, "52:54:00:12:34:56")
+	lastSeen := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	var stored UpdateIPRecord
+	ips := stubIPRepository{
+		findFn: func(context.Context, IPAddressID, int64) (IPAddress, error) {
+			return IPAddress{MACAddress: mac, LastSeenAt: &lastSeen}, nil
+		},
+		updateFn: func(_ context.Context, _ IPAddressID, input UpdateIPRecord) (IPAddress, error) {
+			stored = input
+			return IPAddress{}, nil
+		},
+	}
+	svc := NewNetworkService(stubSubnetRepository{}, ips)
+
+	if _, err := svc.UpdateIPHostname(context.Background(), 7, "id", UpdateIPInput{Hostname: "printer"}); err != nil {
+		t.Fatalf("UpdateIPHostname: %v", err)
+	}
+	if stored.MACAddress.String() != mac.String() || stored.LastSeenAt == nil || !stored.LastSeenAt.Equal(lastSeen) {
+		t.Fatalf("expected mac and last seen kept, got %+v", stored)
+	}
+
+	cleared := ""
+	if _, err := svc.UpdateIPHostname(context.Background(), 7, "id", UpdateIPInput{Hostname: "printer", MACAddress: &cleared}); err != nil {
+		t.Fatalf("UpdateIPHostname: %v", err)
+	}
+	if stored.MACAddress != nil {
+		t.Fatalf("expected mac cleared, got %v", stored.MACAddress)
+	}
+
+	invalid := "52:54:00"
+	_, err := svc.UpdateIPHostname(context.Background(), 7, "id", UpdateIPInput{MACAddress: &invalid})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid mac address, got %v", err)
+	}
+}
+
+func dhcpTestService(t *testing.T, siteID uuid.UUID) DHCPService {
+	t.Helper()
+	subnetID := int64(7)
+	subnets := []Subnet{
+		{
+			ID: 9, CIDR: netip.MustParsePrefix("2001:db8:1::/64"), SiteID: siteID,
+			DNSServers: []netip.Addr{netip.MustParseAddr("10.0.0.53"), netip.MustParseAddr("2001:db8::53")},
+			DHCPPools:  []DHCPPool{{Start: netip.MustParseAddr("2001:db8:1::100"), End: netip.MustParseAddr("2001:db8:1::1ff")}},
+		},
+		{ID: 8, CIDR: netip.MustParsePrefix("10.9.0.0/24"), SiteID: uuid.New()},
+		{
+			ID: subnetID, CIDR: netip.MustParsePrefix("10.0.0.0/24"), SiteID: siteID,
+			Gateway:    netip.MustParseAddr("10.0.0.1"),
+			DNSServers: []netip.Addr{netip.MustParseAddr("10.0.0.53"), netip.MustParseAddr("10.0.0.54")},
+			DHCPPools:  []DHCPPool{{Start: netip.MustParseAddr("10.0.0.100"), End: netip.MustParseAddr("10.0.0.199")}},
+		},
+	}
+	ips := map[int64][]IPAddress{
+		subnetID: {
+			{IP: netip.MustParseAddr("10.0.0.20"), Hostname: "db.lab.example.com", MACAddress: mustMAC(t, "52:54:00:00:00:02")},
+			{IP: netip.MustParseAddr("10.0.0.10"), Hostname: "Printer 1", MACAddress: mustMAC(t, "52:54:00:00:00:01")},
+			{IP: netip.MustParseAddr("10.0.0.30"), Hostname: "no-mac"},
+		},
+		9: {{IP: netip.MustParseAddr("2001:db8:1::10"), Hostname: "web-1", MACAddress: mustMAC(t, "52:54:00:00:00:03")}},
+	}
+	return NewDHCPService(
+		stubSubnetRepository{
+			listFn: func(context.Context) ([]Subnet, error) { return subnets, nil },
+			findFn: func(_ context.Context, id int64) (Subnet, error) {
+				for _, subnet := range subnets {
+					if subnet.ID == id {
+						return subnet, nil
+					}
+				}
+				return Subnet{}, ErrNotFound
+			},
+		},
+		stubIPRepository{listFn: func(_ context.Context, id int64) ([]IPAddress, error) { return ips[id], nil }},
+		stubDHCPSiteRepository{sites: []uuid.UUID{siteID}},
+		&stubDNSZoneRepository{zones: []DNSZone{{Name: "lab.example.com", SubnetID: &subnetID}}},
+	)
+}
+
+func TestSubnetConfigRendersKea(t *testing.T) {
+	config, err := dhcpTestService(t, uuid.New()).SubnetConfig(context.Background(), 7, DHCPConfigKea)
+	if err != nil {
+		t.Fatalf("SubnetConfig: %v", err)
+	}
+	want := `{
+  "Dhcp4": {
+    "subnet4": [
+      {
+        "id": 7,
+        "subnet": "10.0.0.0/24",
+        "pools": [
+          {
+            "pool": "10.0.0.100 - 10.0.0.199"
+          }
+        ],
+        "option-data": [
+          {
+            "name": "routers",
+            "data": "10.0.0.1"
+          },
+          {
+            "name": "domain-name-servers",
+            "data": "10.0.0.53, 10.0.0.54"
+          },
+          {
+            "name": "domain-name",
+            "data": "lab.example.com"
+          }
+        ],
+        "reservations": [
+          {
+            "hw-address": "52:54:00:00:00:01",
+            "ip-address": "10.0.0.10"
+          },
+          {
+            "hw-address": "52:54:00:00:00:02",
+            "ip-address": "10.0.0.20",
+            "hostname": "db"
+          }
+        ]
+      }
+    ]
+  }
+}
+`
+	if string(config) != want {
+		t.Fatalf("unexpected config:\n%s", config)
+	}
+}
+
+func TestSiteConfigRendersDnsmasq(t *testing.T) {
+	siteID := uuid.New()
+	svc := dhcpTestService(t, siteID)
+	config, err := svc.SiteConfig(context.Background(), siteID, DHCPConfigDnsmasq)
+	if err != nil {
+		t.Fatalf("SiteConfig: %v", err)
+	}
+	want := `# subnet 7: 10.0.0.0/24
+dhcp-range=set:subnet-7,10.0.0.100,10.0.0.199,255.255.255.0
+dhcp-option=tag:subnet-7,option:router,10.0.0.1
+dhcp-option=tag:subnet-7,option:dns-server,10.0.0.53,10.0.0.54
+dhcp-option=tag:subnet-7,option:domain-name,lab.example.com
+dhcp-host=52:54:00:00:00:01,10.0.0.10
+dhcp-host=52:54:00:00:00:02,10.0.0.20,db
+
+# subnet 9: 2001:db8:1::/64
+dhcp-range=set:subnet-9,2001:db8:1::100,2001:db8:1::1ff,64
+dhcp-option=tag:subnet-9,option6:dns-server,[2001:db8::53]
+dhcp-host=52:54:00:00:00:03,[2001:db8:1::10],web-1
+`
+	if string(config) != want {
+		t.Fatalf("unexpected config:\n%s", config)
+	}
+	again, _ := svc.SiteConfig(context.Background(), siteID, DHCPConfigDnsmasq)
+	if string(again) != string(config) {
+		t.Fatal("expected identical output for identical data")
+	}
+
+	if _, err := svc.SiteConfig(context.Background(), uuid.New(), DHCPConfigDnsmasq); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected unknown site to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"go4.org/netipx"
+)
+
+const (
+	maxDNSNameLength  = 253
+	maxDNSLabelLength = 63
+
+	reverseZoneSuffixIPv4 = "in-addr.arpa"
+	reverseZoneSuffixIPv6 = "ip6.arpa"
+)
+
+// normalizeDNSName lower-cases name and drops a trailing dot.
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+// validateDNSName checks that name is made of letter-digit-hyphen labels of
+// at most 63 characters, as RFC 1123 requires of host names.
+func validateDNSName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if len(name) > maxDNSNameLength {
+		return fmt.Errorf("name is longer than %d characters", maxDNSNameLength)
+	}
+	for label := range strings.SplitSeq(name, ".") {
+		if err := validateDNSLabel(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDNSLabel(label string) error {
+	if label == "" {
+		return fmt.Errorf("name has an empty label")
+	}
+	if len(label) > maxDNSLabelLength {
+		return fmt.Errorf("label %q is longer than %d characters", label, maxDNSLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, r := range label {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return fmt.Errorf("label %q may only contain letters, digits and hyphens", label)
+		}
+	}
+	return nil
+}
+
+// hostnameFQDN returns the name an address's hostname has in zone. Hostnames
+// are relative to the zone unless they already end with it.
+func hostnameFQDN(hostname, zone string) (string, error) {
+	name := normalizeDNSName(hostname)
+	if name != zone && !strings.HasSuffix(name, "."+zone) {
+		name += "." + zone
+	}
+	if err := validateDNSName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func isReverseZoneName(name string) bool {
+	return name == reverseZoneSuffixIPv4 || strings.HasSuffix(name, "."+reverseZoneSuffixIPv4) ||
+		name == reverseZoneSuffixIPv6 || strings.HasSuffix(name, "."+reverseZoneSuffixIPv6)
+}
+
+// reverseZoneNetworks returns the reverse zones covering prefix. Zones are
+// cut on octet boundaries for IPv4 and nibble boundaries for IPv6, so a /22
+// is four /24 zones. Prefixes longer than /24 or /124 share the enclosing
+// zone; classless delegation (RFC 2317) is not generated.
+func reverseZoneNetworks(prefix netip.Prefix) []netip.Prefix {
+	prefix = prefix.Masked()
+	step, longest := 4, 124
+	if prefix.Addr().Is4() {
+		step, longest = 8, 24
+	}
+	bits := prefix.Bits()
+	zoneBits := max(step, (bits+step-1)/step*step)
+	if bits > longest {
+		zoneBits = longest
+	}
+	if zoneBits <= bits {
+		return []netip.Prefix{netip.PrefixFrom(prefix.Addr(), zoneBits).Masked()}
+	}
+	networks := make([]netip.Prefix, 0, 1<<(zoneBits-bits))
+	for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); {
+		network := netip.PrefixFrom(addr, zoneBits)
+		networks = append(networks, network)
+		addr = netipx.PrefixLastIP(network).Next()
+	}
+	return networks
+}
+
+// reverseZoneName names the reverse zone for a network cut by
+// reverseZoneNetworks.
+func reverseZoneName(network netip.Prefix) string {
+	addr := network.Addr()
+	if addr.Is4() {
+		octets := addr.As4()
+		labels := make([]string, 0, 5)
+		for i := network.Bits()/8 - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(octets[i])))
+		}
+		return strings.Join(append(labels, reverseZoneSuffixIPv4), ".")
+	}
+	nibbles := ipv6Nibbles(addr)
+	labels := make([]string, 0, 33)
+	for i := network.Bits()/4 - 1; i >= 0; i-- {
+		labels = append(labels, nibbles[i])
+	}
+	return strings.Join(append(labels, reverseZoneSuffixIPv6), ".")
+}
+
+// ReverseName returns the PTR owner name of addr, such as
+// 5.0.0.10.in-addr.arpa.
+func ReverseName(addr netip.Addr) string {
+	addr = addr.Unmap()
+	bits := 128
+	if addr.Is4() {
+		bits = 32
+	}
+	return reverseZoneName(netip.PrefixFrom(addr, bits))
+}
+
+func ipv6Nibbles(addr netip.Addr) []string {
+	bytes := addr.As16()
+	nibbles := make([]string, 0, 32)
+	for _, b := range bytes {
+		nibbles = append(nibbles, strconv.FormatUint(uint64(b>>4), 16), strconv.FormatUint(uint64(b&0x0f), 16))
+	}
+	return nibbles
+}
+
+// parseReverseName is the inverse of ReverseName. Names of reverse zones and
+// classless delegations do not name one address and are rejected.
+func parseReverseName(name string) (netip.Addr, bool) {
+	name = normalizeDNSName(name)
+	if labels, ok := strings.CutSuffix(name, "."+reverseZoneSuffixIPv4); ok {
+		parts := strings.Split(labels, ".")
+		if len(parts) != 4 {
+			return netip.Addr{}, false
+		}
+		var octets [4]byte
+		for i, part := range parts {
+			value, err := strconv.ParseUint(part, 10, 8)
+			if err != nil || (len(part) > 1 && part[0] == '0') {
+				return netip.Addr{}, false
+			}
+			octets[3-i] = byte(value)
+		}
+		return netip.AddrFrom4(octets), true
+	}
+	if labels, ok := strings.CutSuffix(name, "."+reverseZoneSuffixIPv6); ok {
+		parts := strings.Split(labels, ".")
+		if len(parts) != 32 {
+			return netip.Addr{}, false
+		}
+		var bytes [16]byte
+		for i, part := range parts {
+			value, err := strconv.ParseUint(part, 16, 4)
+			if err != nil || len(part) != 1 {
+				return netip.Addr{}, false
+			}
+			nibble := 31 - i
+			if nibble%2 == 0 {
+				bytes[nibble/2] |= byte(value) << 4
+			} else {
+				bytes[nibble/2] |= byte(value)
+			}
+		}
+		return netip.AddrFrom16(bytes), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,296 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDNSTTL       int32 = 3600
+	defaultDNSPrimaryNS       = "localhost"
+
+	dnsSOARefresh uint32 = 3600
+	dnsSOARetry   uint32 = 600
+	dnsSOAExpire  uint32 = 14 * 24 * 3600
+	// dnsSOAMinimum is the negative caching TTL (RFC 2308).
+	dnsSOAMinimum uint32 = 300
+)
+
+// DNSSettings are the SOA and NS values shared by every zone. PrimaryNS
+// defaults to localhost and Hostmaster to hostmaster.<PrimaryNS>; Hostmaster
+// may be given as an email address.
+type DNSSettings struct {
+	PrimaryNS  string
+	Hostmaster string
+	DefaultTTL int32
+}
+
+type dnsService struct {
+	zones    DNSZoneRepository
+	subnets  SubnetRepository
+	sites    SiteRepository
+	settings DNSSettings
+	now      func() time.Time
+}
+
+func NewDNSService(zones DNSZoneRepository, subnets SubnetRepository, sites SiteRepository, settings DNSSettings) DNSService {
+	settings.PrimaryNS = normalizeDNSName(settings.PrimaryNS)
+	if settings.PrimaryNS == "" {
+		settings.PrimaryNS = defaultDNSPrimaryNS
+	}
+	settings.Hostmaster = hostmasterName(settings.Hostmaster, settings.PrimaryNS)
+	if settings.DefaultTTL <= 0 {
+		settings.DefaultTTL = DefaultDNSTTL
+	}
+	return &dnsService{zones: zones, subnets: subnets, sites: sites, settings: settings, now: time.Now}
+}
+
+// hostmasterName converts a mailbox to the SOA RNAME form, escaping dots in
+// the local part: dns.admin@example.com becomes dns\.admin.example.com.
+func hostmasterName(mailbox, primaryNS string) string {
+	mailbox = normalizeDNSName(mailbox)
+	if mailbox == "" {
+		return "hostmaster." + primaryNS
+	}
+	local, domain, ok := strings.Cut(mailbox, "@")
+	if !ok {
+		return mailbox
+	}
+	return strings.ReplaceAll(local, ".", `\.`) + "." + domain
+}
+
+// ListZones returns the stored forward zones followed by the reverse zones
+// computed from every subnet.
+func (s *dnsService) ListZones(ctx context.Context) ([]DNSZone, error) {
+	zones, err := s.zones.ListZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reverse, err := s.reverseZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(zones, reverse...), nil
+}
+
+func (s *dnsService) GetZone(ctx context.Context, name string) (DNSZone, error) {
+	name = normalizeDNSName(name)
+	if !isReverseZoneName(name) {
+		zone, err := s.zones.FindZoneByName(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			return DNSZone{}, fmt.Errorf("%w: dns zone not found", ErrNotFound)
+		}
+		return zone, err
+	}
+	reverse, err := s.reverseZones(ctx)
+	if err != nil {
+		return DNSZone{}, err
+	}
+	for _, zone := range reverse {
+		if zone.Name == name {
+			return zone, nil
+		}
+	}
+	return DNSZone{}, fmt.Errorf("%w: dns zone not found", ErrNotFound)
+}
+
+func (s *dnsService) CreateZone(ctx context.Context, input CreateDNSZoneInput) (DNSZone, error) {
+	name := normalizeDNSName(input.Name)
+	if err := validateDNSName(name); err != nil {
+		return DNSZone{}, InvalidField("name", err.Error())
+	}
+	if isReverseZoneName(name) {
+		return DNSZone{}, InvalidField("name", "reverse zones are computed from subnets")
+	}
+	ttl := s.settings.DefaultTTL
+	if input.TTL != nil {
+		if *input.TTL <= 0 {
+			return DNSZone{}, InvalidField("ttl", "ttl must be positive")
+		}
+		ttl = *input.TTL
+	}
+	zone := DNSZone{Name: name, Kind: DNSZoneForward, TTL: ttl}
+	switch {
+	case (input.SiteID == nil) == (input.SubnetID == nil):
+		return DNSZone{}, InvalidField("site_id", "set exactly one of site_id and subnet_id")
+	case input.SiteID != nil:
+		if _, err := s.sites.FindByID(ctx, *input.SiteID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return DNSZone{}, InvalidField("site_id", "site not found")
+			}
+			return DNSZone{}, err
+		}
+		zone.SiteID = input.SiteID
+	default:
+		if _, err := s.subnets.FindByID(ctx, *input.SubnetID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return DNSZone{}, InvalidField("subnet_id", "subnet not found")
+			}
+			return DNSZone{}, err
+		}
+		zone.SubnetID = input.SubnetID
+	}
+	return s.zones.CreateZone(ctx, zone)
+}
+
+func (s *dnsService) DeleteZone(ctx context.Context, name string) (bool, error) {
+	name = normalizeDNSName(name)
+	if isReverseZoneName(name) {
+		return false, InvalidField("name", "reverse zones are computed from subnets and cannot be deleted")
+	}
+	return s.zones.DeleteZone(ctx, name)
+}
+
+// ZoneData collects the zone's records and moves its serial when they
+// changed since the last call.
+func (s *dnsService) ZoneData(ctx context.Context, name string) (DNSZoneData, error) {
+	zone, err := s.GetZone(ctx, name)
+	if err != nil {
+		return DNSZoneData{}, err
+	}
+	hosts, err := s.zones.ListHostRecords(ctx)
+	if err != nil {
+		return DNSZoneData{}, err
+	}
+	var reverse []DNSZone
+	if zone.Kind == DNSZoneReverse {
+		if reverse, err = s.reverseZones(ctx); err != nil {
+			return DNSZoneData{}, err
+		}
+	}
+	data := DNSZoneData{
+		Zone: zone,
+		SOA: DNSSOA{
+			PrimaryNS:  s.settings.PrimaryNS,
+			Hostmaster: s.settings.Hostmaster,
+			Refresh:    dnsSOARefresh,
+			Retry:      dnsSOARetry,
+			Expire:     dnsSOAExpire,
+			Minimum:    dnsSOAMinimum,
+		},
+	}
+	data.Records, data.Skipped = zoneRecords(zone, hosts, reverse)
+
+	hash := sha256.Sum256(fmt.Appendf(nil, "%d\n%s", zone.TTL, data.body()))
+	serial, err := s.zones.BumpSerial(ctx, zone.Name, hex.EncodeToString(hash[:]), candidateSerial(s.now()))
+	if err != nil {
+		return DNSZoneData{}, err
+	}
+	data.SOA.Serial = serial
+	return data, nil
+}
+
+// candidateSerial is the first serial of the day in the conventional
+// YYYYMMDDnn form.
+func candidateSerial(now time.Time) uint32 {
+	year, month, day := now.UTC().Date()
+	return uint32(year*1_000_000 + int(month)*10_000 + day*100 + 1)
+}
+
+// reverseZones computes the reverse zones covering every subnet.
+func (s *dnsService) reverseZones(ctx context.Context) ([]DNSZone, error) {
+	subnets, err := s.subnets.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var zones []DNSZone
+	for _, subnet := range subnets {
+		for _, network := range reverseZoneNetworks(subnet.CIDR) {
+			name := reverseZoneName(network)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			zones = append(zones, DNSZone{Name: name, Kind: DNSZoneReverse, Network: network, TTL: s.settings.DefaultTTL})
+		}
+	}
+	slices.SortFunc(zones, func(a, b DNSZone) int {
+		if c := cmp.Compare(a.Network.Addr().BitLen(), b.Network.Addr().BitLen()); c != 0 {
+			return c
+		}
+		if c := a.Network.Addr().Compare(b.Network.Addr()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Network.Bits(), b.Network.Bits())
+	})
+	return zones, nil
+}
+
+// zoneRecords selects the records of zone from every named address. A
+// reverse zone only holds addresses for which it is the most specific
+// reverse zone, and only those whose subnet has a forward zone to point at.
+func zoneRecords(zone DNSZone, hosts []DNSHostRecord, reverse []DNSZone) ([]DNSRecord, []DNSSkippedHost) {
+	var (
+		records []DNSRecord
+		skipped []DNSSkippedHost
+	)
+	for _, host := range hosts {
+		addr := host.IP.Unmap()
+		if host.Zone == "" {
+			continue
+		}
+		switch zone.Kind {
+		case DNSZoneForward:
+			if host.Zone != zone.Name {
+				continue
+			}
+		case DNSZoneReverse:
+			if !zone.Network.Contains(addr) || mostSpecificReverseZone(reverse, addr) != zone.Name {
+				continue
+			}
+		}
+		name, err := hostnameFQDN(host.Hostname, host.Zone)
+		if err != nil {
+			skipped = append(skipped, DNSSkippedHost{IP: addr, Hostname: host.Hostname, Reason: err.Error()})
+			continue
+		}
+		if zone.Kind == DNSZoneReverse {
+			records = append(records, DNSRecord{Name: ReverseName(addr), Type: DNSRecordPTR, Data: name})
+			continue
+		}
+		recordType := DNSRecordAAAA
+		if addr.Is4() {
+			recordType = DNSRecordA
+		}
+		records = append(records, DNSRecord{Name: name, Type: recordType, Data: addr.String()})
+	}
+	return records, skipped
+}
+
+func mostSpecificReverseZone(zones []DNSZone, addr netip.Addr) string {
+	name, bits := "", -1
+	for _, zone := range zones {
+		if zone.Network.Contains(addr) && zone.Network.Bits() > bits {
+			name, bits = zone.Name, zone.Network.Bits()
+		}
+	}
+	return name
+}
+
+// validateZoneHostname rejects hostnames that cannot be published in the DNS
+// zone the subnet belongs to. Subnets without a zone accept any hostname.
+func validateZoneHostname(ctx context.Context, zones DNSZoneRepository, subnetID int64, hostname string) error {
+	if zones == nil || hostname == "" {
+		return nil
+	}
+	zone, err := zones.FindZoneForSubnet(ctx, subnetID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := hostnameFQDN(hostname, zone.Name); err != nil {
+		return InvalidField("hostname", fmt.Sprintf("hostname is not a valid DNS name in zone %s: %v", zone.Name, err))
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type stubDNSZoneRepository struct {
+	zones   []DNSZone
+	hosts   []DNSHostRecord
+	created DNSZone
+	serials map[string]struct {
+		serial uint32
+		hash   string
+	}
+}
+
+func (s *stubDNSZoneRepository) ListZones(context.Context) ([]DNSZone, error) {
+	return s.zones, nil
+}
+
+func (s *stubDNSZoneRepository) FindZoneByName(_ context.Context, name string) (DNSZone, error) {
+	for _, zone := range s.zones {
+		if zone.Name == name {
+			return zone, nil
+		}
+	}
+	return DNSZone{}, ErrNotFound
+}
+
+func (s *stubDNSZoneRepository) FindZoneForSubnet(_ context.Context, subnetID int64) (DNSZone, error) {
+	for _, zone := range s.zones {
+		if zone.SubnetID != nil && *zone.SubnetID == subnetID {
+			return zone, nil
+		}
+	}
+	return DNSZone{}, ErrNotFound
+}
+
+func (s *stubDNSZoneRepository) CreateZone(_ context.Context, zone DNSZone) (DNSZone, error) {
+	s.created = zone
+	return zone, nil
+}
+
+func (s *stubDNSZoneRepository) DeleteZone(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func (s *stubDNSZoneRepository) ListHostRecords(context.Context) ([]DNSHostRecord, error) {
+	return s.hosts, nil
+}
+
+func (s *stubDNSZoneRepository) ListHostRecordsForIP(_ context.Context, ip netip.Addr) ([]DNSHostRecord, error) {
+	var hosts []DNSHostRecord
+	for _, host := range s.hosts {
+		if host.IP == ip {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nil
+}
+
+// BumpSerial mirrors the BumpDNSZoneSerial query.
+func (s *stubDNSZoneRepository) BumpSerial(_ context.Context, zone, hash string, candidate uint32) (uint32, error) {
+	if s.serials == nil {
+		s.serials = map[string]struct {
+			serial uint32
+			hash   string
+		}{}
+	}
+	current, ok := s.serials[zone]
+	switch {
+	case !ok:
+		current.serial = candidate
+	case current.hash != hash:
+		current.serial = max(current.serial+1, candidate)
+	}
+	current.hash = hash
+	s.serials[zone] = current
+	return current.serial, nil
+}
+
+func subnetsStub(cidrs ...string) stubSubnetRepository {
+	return stubSubnetRepository{listFn: func(context.Context) ([]Subnet, error) {
+		subnets := make([]Subnet, 0, len(cidrs))
+		for i, cidr := range cidrs {
+			subnets = append(subnets, Subnet{ID: int64(i + 1), CIDR: netip.MustParsePrefix(cidr)})
+		}
+		return subnets, nil
+	}}
+}
+
+func TestReverseZoneNetworks(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"10.1.2.0/24", []string{"2.1.10.in-addr.arpa"}},
+		{"10.1.2.64/26", []string{"2.1.10.in-addr.arpa"}},
+		{"10.1.0.0/16", []string{"1.10.in-addr.arpa"}},
+		{"10.1.4.0/22", []string{"4.1.10.in-addr.arpa", "5.1.10.in-addr.arpa", "6.1.10.in-addr.arpa", "7.1.10.in-addr.arpa"}},
+		{"2001:db8::/32", []string{"8.b.d.0.1.0.0.2.ip6.arpa"}},
+		{"2001:db8:0:10::/63", []string{
+			"0.1.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+			"1.1.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+		}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, network := range reverseZoneNetworks(netip.MustParsePrefix(tt.prefix)) {
+			got = append(got, reverseZoneName(network))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("reverse zones of %s = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+	if got := ReverseName(netip.MustParseAddr("10.1.2.5")); got != "5.2.1.10.in-addr.arpa" {
+		t.Fatalf("ReverseName() = %q", got)
+	}
+}
+
+func TestHostnameFQDN(t *testing.T) {
+	valid := map[string]string{
+		"web-1":                 "web-1.lab.example.com",
+		"Web-1.Rack3":           "web-1.rack3.lab.example.com",
+		"db.lab.example.com.":   "db.lab.example.com",
+		"lab.example.com":       "lab.example.com",
+		"api.other.example.com": "api.other.example.com.lab.example.com",
+		strings.Repeat("a", 63): strings.Repeat("a", 63) + ".lab.example.com",
+	}
+	for hostname, want := range valid {
+		got, err := hostnameFQDN(hostname, "lab.example.com")
+		if err != nil || got != want {
+			t.Errorf("hostnameFQDN(%q) = %q, %v, want %q", hostname, got, err, want)
+		}
+	}
+	for _, hostname := range []string{"web_1", "-web", "web-", "a..b", "web 1", strings.Repeat("a", 64)} {
+		if _, err := hostnameFQDN(hostname, "lab.example.com"); err == nil {
+			t.Errorf("hostnameFQDN(%q) accepted an invalid name", hostname)
+		}
+	}
+}
+
+func TestDNSServiceCreateZoneValidation(t *testing.T) {
+	siteID := uuid.New()
+	subnetID := int64(4)
+	negativeTTL := int32(-1)
+	zones := &stubDNSZoneRepository{}
+	svc := NewDNSService(zones, subnetsStub(), siteRepositoryStub{}, DNSSettings{})
+
+	for name, input := range map[string]CreateDNSZoneInput{
+		"name":         {Name: "bad_zone", SiteID: &siteID},
+		"reverse":      {Name: "2.1.10.in-addr.arpa", SiteID: &siteID},
+		"attachment":   {Name: "lab.example.com"},
+		"both":         {Name: "lab.example.com", SiteID: &siteID, SubnetID: &subnetID},
+		"negative ttl": {Name: "lab.example.com", SiteID: &siteID, TTL: &negativeTTL},
+	} {
+		if _, err := svc.CreateZone(context.Background(), input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+
+	zone, err := svc.CreateZone(context.Background(), CreateDNSZoneInput{Name: "Lab.Example.COM.", SiteID: &siteID})
+	if err != nil {
+		t.Fatalf("CreateZone() error = %v", err)
+	}
+	if zone.Name != "lab.example.com" || zone.Kind != DNSZoneForward || zone.TTL != DefaultDNSTTL || *zone.SiteID != siteID {
+		t.Fatalf("created zone = %+v", zone)
+	}
+}
+
+func TestDNSServiceZoneDataBuildsForwardAndReverseRecords(t *testing.T) {
+	subnetID := int64(1)
+	zones := &stubDNSZoneRepository{
+		zones: []DNSZone{{Name: "lab.example.com", Kind: DNSZoneForward, SubnetID: &subnetID, TTL: 300}},
+		hosts: []DNSHostRecord{
+			{IP: netip.MustParseAddr("10.1.2.5"), Hostname: "web-1", SubnetID: 1, Zone: "lab.example.com"},
+			{IP: netip.MustParseAddr("10.1.2.6"), Hostname: "bad_name", SubnetID: 1, Zone: "lab.example.com"},
+			{IP: netip.MustParseAddr("2001:db8::5"), Hostname: "web-1", SubnetID: 2, Zone: "lab.example.com"},
+			{IP: netip.MustParseAddr("10.1.2.7"), Hostname: "unzoned", SubnetID: 3},
+			{IP: netip.MustParseAddr("10.9.0.1"), Hostname: "other", SubnetID: 4, Zone: "other.example.com"},
+		},
+	}
+	svc := NewDNSService(zones, subnetsStub("10.1.2.0/24", "2001:db8::/64", "10.1.0.0/16"), siteRepositoryStub{},
+		DNSSettings{PrimaryNS: "ns1.example.com.", Hostmaster: "dns.admin@example.com"}).(*dnsService)
+	svc.now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+
+	forward, err := svc.ZoneData(context.Background(), "LAB.example.com.")
+	if err != nil {
+		t.Fatalf("ZoneData(forward) error = %v", err)
+	}
+	file := string(forward.ZoneFile())
+	for _, want := range []string{
+		"$ORIGIN lab.example.com.\n$TTL 300\n",
+		"@\tIN\tSOA\tns1.example.com. dns\\.admin.example.com. (\n\t\t2026101801\t; serial",
+		"@\tIN\tNS\tns1.example.com.\n",
+		"web-1\tIN\tA\t10.1.2.5\n",
+		"web-1\tIN\tAAAA\t2001:db8::5\n",
+		"; skipped 10.1.2.6 \"bad_name\": label \"bad_name\" may only contain letters, digits and hyphens\n",
+	} {
+		if !strings.Contains(file, want) {
+			t.Fatalf("zone file missing %q:\n%s", want, file)
+		}
+	}
+	if strings.Contains(file, "unzoned") || strings.Contains(file, "other") {
+		t.Fatalf("zone file holds records of other zones:\n%s", file)
+	}
+
+	reverse, err := svc.ZoneData(context.Background(), "2.1.10.in-addr.arpa")
+	if err != nil {
+		t.Fatalf("ZoneData(reverse) error = %v", err)
+	}
+	if len(reverse.Records) != 1 || reverse.Records[0] != (DNSRecord{Name: "5.2.1.10.in-addr.arpa", Type: DNSRecordPTR, Data: "web-1.lab.example.com"}) {
+		t.Fatalf("reverse records = %+v", reverse.Records)
+	}
+	if !strings.Contains(string(reverse.ZoneFile()), "5\tIN\tPTR\tweb-1.lab.example.com.\n") {
+		t.Fatalf("reverse zone file:\n%s", reverse.ZoneFile())
+	}
+	// 10.9.0.1 is only in the /16 zone; 10.1.2.5 belongs to the /24.
+	enclosing, err := svc.ZoneData(context.Background(), "1.10.in-addr.arpa")
+	if err != nil || len(enclosing.Records) != 0 {
+		t.Fatalf("enclosing reverse zone = %+v, %v", enclosing.Records, err)
+	}
+}
+
+func TestDNSServiceSerialOnlyMovesOnChange(t *testing.T) {
+	subnetID := int64(1)
+	zones := &stubDNSZoneRepository{
+		zones: []DNSZone{{Name: "lab.example.com", Kind: DNSZoneForward, SubnetID: &subnetID, TTL: 300}},
+		hosts: []DNSHostRecord{{IP: netip.MustParseAddr("10.0.0.5"), Hostname: "a", SubnetID: 1, Zone: "lab.example.com"}},
+	}
+	svc := NewDNSService(zones, subnetsStub("10.0.0.0/24"), siteRepositoryStub{}, DNSSettings{}).(*dnsService)
+	svc.now = func() time.Time { return time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC) }
+
+	serial := func() uint32 {
+		t.Helper()
+		data, err := svc.ZoneData(context.Background(), "lab.example.com")
+		if err != nil {
+			t.Fatalf("ZoneData() error = %v", err)
+		}
+		return data.SOA.Serial
+	}
+	if got := serial(); got != 2026101801 {
+		t.Fatalf("first serial = %d", got)
+	}
+	if got := serial(); got != 2026101801 {
+		t.Fatalf("unchanged zone serial = %d", got)
+	}
+	zones.hosts[0].Hostname = "b"
+	if got := serial(); got != 2026101802 {
+		t.Fatalf("changed zone serial = %d", got)
+	}
+	svc.now = func() time.Time { return time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) }
+	zones.hosts[0].Hostname = "c"
+	if got := serial(); got != 2026101901 {
+		t.Fatalf("next day serial = %d", got)
+	}
+}
+
+func TestNetworkServiceValidatesHostnamesInZonedSubnets(t *testing.T) {
+	zonedSubnet := int64(1)
+	zones := &stubDNSZoneRepository{zones: []DNSZone{{Name: "lab.example.com", SubnetID: &zonedSubnet}}}
+	subnets := stubSubnetRepository{findFn: func(_ context.Context, id int64) (Subnet, error) {
+		return Subnet{ID: id, CIDR: netip.MustParsePrefix("10.0.0.0/24")}, nil
+	}}
+	ips := stubIPRepository{
+		createFn: func(_ context.Context, input CreateIPRecord, subnetID int64) (IPAddress, error) {
+			return IPAddress{IP: input.IP, Hostname: input.Hostname, SubnetID: subnetID}, nil
+		},
+		updateFn: func(_ context.Context, id IPAddressID, input UpdateIPRecord) (IPAddress, error) {
+			return IPAddress{ID: id, Hostname: input.Hostname}, nil
+		},
+	}
+	svc := NewNetworkServiceWithDiscovery(subnets, ips, nil, nil, zones)
+
+	_, err := svc.CreateIP(context.Background(), 1, CreateIPInput{IP: "10.0.0.5", Hostname: "web_1"})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Fields[0].Field != "hostname" {
+		t.Fatalf("expected hostname validation error, got %v", err)
+	}
+	if _, err := svc.UpdateIPHostname(context.Background(), 1, "ip-1", UpdateIPInput{Hostname: "-bad"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected rename to be validated, got %v", err)
+	}
+	if _, err := svc.CreateIP(context.Background(), 1, CreateIPInput{IP: "10.0.0.6", Hostname: "web-1"}); err != nil {
+		t.Fatalf("valid hostname rejected: %v", err)
+	}
+	if _, err := svc.CreateIP(context.Background(), 2, CreateIPInput{IP: "10.0.0.7", Hostname: "free form_name"}); err != nil {
+		t.Fatalf("subnet without a zone should accept any hostname: %v", err)
+	}
+}
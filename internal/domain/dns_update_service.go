@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"time"
+)
+
+const (
+	dnsUpdateLease       = time.Minute
+	dnsUpdateBaseBackoff = 10 * time.Second
+	dnsUpdateMaxBackoff  = time.Hour
+)
+
+// DNSUpdateClient sends dynamic updates (RFC 2136) to the DNS server and
+// reads back what it serves.
+type DNSUpdateClient interface {
+	Apply(ctx context.Context, change DNSChange) error
+	// Serves reports whether the server answers with both of the
+	// registration's records.
+	Serves(ctx context.Context, registration DNSRegistration) (bool, error)
+}
+
+type dnsUpdateService struct {
+	updates DNSUpdateRepository
+	zones   DNSZoneRepository
+	client  DNSUpdateClient
+	now     func() time.Time
+	jitter  func(time.Duration) time.Duration
+}
+
+func NewDNSUpdateService(updates DNSUpdateRepository, zones DNSZoneRepository, client DNSUpdateClient) DNSUpdateService {
+	return &dnsUpdateService{updates: updates, zones: zones, client: client, now: time.Now, jitter: retryJitter}
+}
+
+// ProcessUpdates leases due queue entries and brings each address's records
+// on the server in line with the IPAM. Failed updates are retried with
+// exponential backoff; they are never dropped, since the address stays
+// wrong on the server until an update succeeds.
+func (s *dnsUpdateService) ProcessUpdates(ctx context.Context, limit int32) ([]DNSUpdateResult, error) {
+	tasks, err := s.updates.ClaimDueUpdates(ctx, limit, s.now().Add(dnsUpdateLease))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]DNSUpdateResult, 0, len(tasks))
+	for _, task := range tasks {
+		result := DNSUpdateResult{IP: task.IP, Attempts: task.Attempts}
+		change, err := s.plan(ctx, task.IP)
+		if err == nil && (change.Remove != nil || change.Add != nil) {
+			err = s.client.Apply(ctx, change)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			result.Err = err
+			failure := DNSUpdateFailure{
+				IP:            task.IP,
+				Generation:    task.Generation,
+				NextAttemptAt: s.now().Add(s.jitter(dnsUpdateBackoff(task.Attempts))),
+				Error:         err.Error(),
+			}
+			if err := s.updates.FailUpdate(ctx, failure); err != nil {
+				return results, err
+			}
+			results = append(results, result)
+			continue
+		}
+		if err := s.settle(ctx, task, change); err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// plan compares the records registered for ip with the ones it should have.
+func (s *dnsUpdateService) plan(ctx context.Context, ip netip.Addr) (DNSChange, error) {
+	hosts, err := s.zones.ListHostRecordsForIP(ctx, ip)
+	if err != nil {
+		return DNSChange{}, err
+	}
+	change := DNSChange{IP: ip, Add: desiredRegistration(hosts)}
+	current, err := s.updates.FindRegistration(ctx, ip)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return DNSChange{}, err
+	default:
+		change.Remove = &current
+	}
+	if sameRegistration(change.Remove, change.Add) {
+		return DNSChange{IP: ip}, nil
+	}
+	return change, nil
+}
+
+func (s *dnsUpdateService) settle(ctx context.Context, task DNSUpdateTask, change DNSChange) error {
+	switch {
+	case change.Add != nil:
+		if err := s.updates.SaveRegistration(ctx, *change.Add); err != nil {
+			return err
+		}
+	case change.Remove != nil:
+		if err := s.updates.DeleteRegistration(ctx, task.IP); err != nil {
+			return err
+		}
+	}
+	return s.updates.CompleteUpdate(ctx, task)
+}
+
+// Reconcile queues addresses whose registration no longer matches the IPAM,
+// and addresses the server has lost records for. The registration of the
+// latter is dropped first so the update re-adds both records.
+func (s *dnsUpdateService) Reconcile(ctx context.Context) (int, error) {
+	hosts, err := s.zones.ListHostRecords(ctx)
+	if err != nil {
+		return 0, err
+	}
+	registrations, err := s.updates.ListRegistrations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	byIP := make(map[netip.Addr][]DNSHostRecord)
+	for _, host := range hosts {
+		byIP[host.IP.Unmap()] = append(byIP[host.IP.Unmap()], host)
+	}
+	registered := make(map[netip.Addr]DNSRegistration, len(registrations))
+	var stale []netip.Addr
+	for _, registration := range registrations {
+		registered[registration.IP] = registration
+		desired := desiredRegistration(byIP[registration.IP])
+		if !sameRegistration(&registration, desired) {
+			stale = append(stale, registration.IP)
+		}
+	}
+	for ip, records := range byIP {
+		desired := desiredRegistration(records)
+		if desired == nil {
+			continue
+		}
+		registration, ok := registered[ip]
+		if !ok {
+			stale = append(stale, ip)
+			continue
+		}
+		if !sameRegistration(&registration, desired) {
+			continue
+		}
+		serves, err := s.client.Serves(ctx, registration)
+		if err != nil {
+			return 0, err
+		}
+		if !serves {
+			if err := s.updates.DeleteRegistration(ctx, ip); err != nil {
+				return 0, err
+			}
+			stale = append(stale, ip)
+		}
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+	return len(stale), s.updates.EnqueueUpdates(ctx, stale)
+}
+
+// desiredRegistration picks the records an address should have from its
+// named rows: the first one in a zone whose hostname is valid there. The
+// reverse zone is cut from the address's subnet as for the export.
+func desiredRegistration(hosts []DNSHostRecord) *DNSRegistration {
+	for _, host := range hosts {
+		if host.Zone == "" {
+			continue
+		}
+		name, err := hostnameFQDN(host.Hostname, host.Zone)
+		if err != nil {
+			continue
+		}
+		addr := host.IP.Unmap()
+		for _, network := range reverseZoneNetworks(host.CIDR) {
+			if network.Contains(addr) {
+				return &DNSRegistration{IP: addr, Name: name, Zone: host.Zone, ReverseZone: reverseZoneName(network), TTL: host.TTL}
+			}
+		}
+	}
+	return nil
+}
+
+func sameRegistration(a, b *DNSRegistration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.IP == b.IP && a.Name == b.Name && a.Zone == b.Zone && a.ReverseZone == b.ReverseZone && a.TTL == b.TTL
+}
+
+// dnsUpdateBackoff doubles the delay after every failed attempt, starting at
+// dnsUpdateBaseBackoff and capped at dnsUpdateMaxBackoff.
+func dnsUpdateBackoff(attempts int32) time.Duration {
+	return retryBackoff(dnsUpdateBaseBackoff, dnsUpdateMaxBackoff, attempts)
+}
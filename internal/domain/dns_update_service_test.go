@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+)
+
+type stubDNSUpdateRepository struct {
+	tasks         []DNSUpdateTask
+	registrations map[netip.Addr]DNSRegistration
+	completed     []DNSUpdateTask
+	failures      []DNSUpdateFailure
+	enqueued      []netip.Addr
+}
+
+func (s *stubDNSUpdateRepository) ClaimDueUpdates(context.Context, int32, time.Time) ([]DNSUpdateTask, error) {
+	return s.tasks, nil
+}
+
+func (s *stubDNSUpdateRepository) CompleteUpdate(_ context.Context, task DNSUpdateTask) error {
+	s.completed = append(s.completed, task)
+	return nil
+}
+
+func (s *stubDNSUpdateRepository) FailUpdate(_ context.Context, failure DNSUpdateFailure) error {
+	s.failures = append(s.failures, failure)
+	return nil
+}
+
+func (s *stubDNSUpdateRepository) EnqueueUpdates(_ context.Context, ips []netip.Addr) error {
+	s.enqueued = append(s.enqueued, ips...)
+	return nil
+}
+
+func (s *stubDNSUpdateRepository) FindRegistration(_ context.Context, ip netip.Addr) (DNSRegistration, error) {
+	registration, ok := s.registrations[ip]
+	if !ok {
+		return DNSRegistration{}, ErrNotFound
+	}
+	return registration, nil
+}
+
+func (s *stubDNSUpdateRepository) ListRegistrations(context.Context) ([]DNSRegistration, error) {
+	var registrations []DNSRegistration
+	for _, registration := range s.registrations {
+		registrations = append(registrations, registration)
+	}
+	return registrations, nil
+}
+
+func (s *stubDNSUpdateRepository) SaveRegistration(_ context.Context, registration DNSRegistration) error {
+	s.registrations[registration.IP] = registration
+	return nil
+}
+
+func (s *stubDNSUpdateRepository) DeleteRegistration(_ context.Context, ip netip.Addr) error {
+	delete(s.registrations, ip)
+	return nil
+}
+
+type stubDNSUpdateClient struct {
+	applied  []DNSChange
+	applyErr error
+	missing  map[netip.Addr]bool
+}
+
+func (s *stubDNSUpdateClient) Apply(_ context.Context, change DNSChange) error {
+	if s.applyErr != nil {
+		return s.applyErr
+	}
+	s.applied = append(s.applied, change)
+	return nil
+}
+
+func (s *stubDNSUpdateClient) Serves(_ context.Context, registration DNSRegistration) (bool, error) {
+	return !s.missing[registration.IP], nil
+}
+
+func newDNSUpdateTestService(updates *stubDNSUpdateRepository, hosts []DNSHostRecord, client *stubDNSUpdateClient, now time.Time) *dnsUpdateService {
+	return &dnsUpdateService{
+		updates: updates,
+		zones:   &stubDNSZoneRepository{hosts: hosts},
+		client:  client,
+		now:     func() time.Time { return now },
+		jitter:  func(d time.Duration) time.Duration { return d },
+	}
+}
+
+func TestProcessUpdatesReplacesRenamedRecords(t *testing.T) {
+	ip := netip.MustParseAddr("10.1.2.5")
+	old := DNSRegistration{IP: ip, Name: "web-1.lab.example.com", Zone: "lab.example.com", ReverseZone: "2.1.10.in-addr.arpa", TTL: 300}
+	updates := &stubDNSUpdateRepository{
+		tasks:         []DNSUpdateTask{{IP: ip, Generation: 3, Attempts: 1}},
+		registrations: map[netip.Addr]DNSRegistration{ip: old},
+	}
+	hosts := []DNSHostRecord{{IP: ip, Hostname: "web-2", SubnetID: 1, CIDR: netip.MustParsePrefix("10.1.2.0/24"), Zone: "lab.example.com", TTL: 300}}
+	client := &stubDNSUpdateClient{}
+	service := newDNSUpdateTestService(updates, hosts, client, time.Now())
+
+	results, err := service.ProcessUpdates(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("process updates: %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	want := DNSRegistration{IP: ip, Name: "web-2.lab.example.com", Zone: "lab.example.com", ReverseZone: "2.1.10.in-addr.arpa", TTL: 300}
+	if len(client.applied) != 1 || *client.applied[0].Remove != old || *client.applied[0].Add != want {
+		t.Fatalf("unexpected change: %+v", client.applied)
+	}
+	if updates.registrations[ip] != want || !slices.Equal(updates.completed, updates.tasks) {
+		t.Fatalf("expected the new registration saved and the task completed, got %+v %+v", updates.registrations, updates.completed)
+	}
+
+	// The address is now up to date, so processing it again sends nothing.
+	if _, err := service.ProcessUpdates(context.Background(), 10); err != nil {
+		t.Fatalf("process updates: %v", err)
+	}
+	if len(client.applied) != 1 || len(updates.completed) != 2 {
+		t.Fatalf("expected no second update, got %+v", client.applied)
+	}
+}
+
+func TestProcessUpdatesRemovesDeletedAddress(t *testing.T) {
+	ip := netip.MustParseAddr("2001:db8::5")
+	old := DNSRegistration{IP: ip, Name: "web-1.lab.example.com", Zone: "lab.example.com", ReverseZone: "8.b.d.0.1.0.0.2.ip6.arpa", TTL: 300}
+	updates := &stubDNSUpdateRepository{
+		tasks:         []DNSUpdateTask{{IP: ip, Generation: 1, Attempts: 1}},
+		registrations: map[netip.Addr]DNSRegistration{ip: old},
+	}
+	client := &stubDNSUpdateClient{}
+	service := newDNSUpdateTestService(updates, nil, client, time.Now())
+
+	if _, err := service.ProcessUpdates(context.Background(), 10); err != nil {
+		t.Fatalf("process updates: %v", err)
+	}
+	if len(client.applied) != 1 || client.applied[0].Add != nil || *client.applied[0].Remove != old {
+		t.Fatalf("unexpected change: %+v", client.applied)
+	}
+	if _, ok := updates.registrations[ip]; ok {
+		t.Fatal("expected the registration to be deleted")
+	}
+}
+
+func TestProcessUpdatesBacksOffFailures(t *testing.T) {
+	ip := netip.MustParseAddr("10.1.2.5")
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	updates := &stubDNSUpdateRepository{
+		tasks:         []DNSUpdateTask{{IP: ip, Generation: 2, Attempts: 3}},
+		registrations: map[netip.Addr]DNSRegistration{},
+	}
+	hosts := []DNSHostRecord{{IP: ip, Hostname: "web-1", SubnetID: 1, CIDR: netip.MustParsePrefix("10.1.2.0/24"), Zone: "lab.example.com", TTL: 300}}
+	client := &stubDNSUpdateClient{applyErr: errors.New("update refused: NOTAUTH")}
+	service := newDNSUpdateTestService(updates, hosts, client, now)
+
+	results, err := service.ProcessUpdates(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("process updates: %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("expected a failed result, got %+v", results)
+	}
+	want := DNSUpdateFailure{IP: ip, Generation: 2, NextAttemptAt: now.Add(40 * time.Second), Error: "update refused: NOTAUTH"}
+	if len(updates.failures) != 1 || updates.failures[0] != want {
+		t.Fatalf("unexpected failure: %+v", updates.failures)
+	}
+	if len(updates.completed) != 0 || len(updates.registrations) != 0 {
+		t.Fatal("expected a failed update to leave the queue entry and registrations alone")
+	}
+	if got := dnsUpdateBackoff(20); got != dnsUpdateMaxBackoff {
+		t.Fatalf("expected capped backoff, got %s", got)
+	}
+}
+
+func TestReconcileQueuesDrift(t *testing.T) {
+	prefix := netip.MustParsePrefix("10.1.2.0/24")
+	registration := func(ip, name string) DNSRegistration {
+		return DNSRegistration{IP: netip.MustParseAddr(ip), Name: name, Zone: "lab.example.com", ReverseZone: "2.1.10.in-addr.arpa", TTL: 300}
+	}
+	host := func(ip, hostname string) DNSHostRecord {
+		return DNSHostRecord{IP: netip.MustParseAddr(ip), Hostname: hostname, SubnetID: 1, CIDR: prefix, Zone: "lab.example.com", TTL: 300}
+	}
+	updates := &stubDNSUpdateRepository{registrations: map[netip.Addr]DNSRegistration{
+		netip.MustParseAddr("10.1.2.1"): registration("10.1.2.1", "ok.lab.example.com"),
+		netip.MustParseAddr("10.1.2.2"): registration("10.1.2.2", "old.lab.example.com"),
+		netip.MustParseAddr("10.1.2.3"): registration("10.1.2.3", "gone.lab.example.com"),
+		netip.MustParseAddr("10.1.2.4"): registration("10.1.2.4", "lost.lab.example.com"),
+	}}
+	hosts := []DNSHostRecord{
+		host("10.1.2.1", "ok"),
+		host("10.1.2.2", "renamed"),
+		host("10.1.2.4", "lost"),
+		host("10.1.2.5", "new"),
+	}
+	client := &stubDNSUpdateClient{missing: map[netip.Addr]bool{netip.MustParseAddr("10.1.2.4"): true}}
+	service := newDNSUpdateTestService(updates, hosts, client, time.Now())
+
+	queued, err := service.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	slices.SortFunc(updates.enqueued, netip.Addr.Compare)
+	want := []netip.Addr{
+		netip.MustParseAddr("10.1.2.2"), netip.MustParseAddr("10.1.2.3"),
+		netip.MustParseAddr("10.1.2.4"), netip.MustParseAddr("10.1.2.5"),
+	}
+	if queued != len(want) || !slices.Equal(updates.enqueued, want) {
+		t.Fatalf("expected %v queued, got %d %v", want, queued, updates.enqueued)
+	}
+	if _, ok := updates.registrations[netip.MustParseAddr("10.1.2.4")]; ok {
+		t.Fatal("expected the registration of the lost records to be dropped")
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ZoneFile renders the zone as an RFC 1035 master file, as loaded by BIND.
+// Owner names are relative to $ORIGIN. Skipped hosts are listed as comments
+// so the export shows why an address has no record.
+func (d DNSZoneData) ZoneFile() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s.\n", d.Zone.Name)
+	fmt.Fprintf(&b, "$TTL %d\n", d.Zone.TTL)
+	fmt.Fprintf(&b, "@\tIN\tSOA\t%s. %s. (\n", d.SOA.PrimaryNS, d.SOA.Hostmaster)
+	fmt.Fprintf(&b, "\t\t%d\t; serial\n", d.SOA.Serial)
+	fmt.Fprintf(&b, "\t\t%d\t; refresh\n", d.SOA.Refresh)
+	fmt.Fprintf(&b, "\t\t%d\t; retry\n", d.SOA.Retry)
+	fmt.Fprintf(&b, "\t\t%d\t; expire\n", d.SOA.Expire)
+	fmt.Fprintf(&b, "\t\t%d\t; minimum\n", d.SOA.Minimum)
+	b.WriteString("\t\t)\n")
+	b.WriteString(d.body())
+	return []byte(b.String())
+}
+
+// body is the zone content below the SOA record. Its hash decides whether
+// the serial moves.
+func (d DNSZoneData) body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@\tIN\tNS\t%s.\n", d.SOA.PrimaryNS)
+	for _, record := range d.Records {
+		data := record.Data
+		if record.Type == DNSRecordPTR {
+			data += "."
+		}
+		fmt.Fprintf(&b, "%s\tIN\t%s\t%s\n", d.relativeName(record.Name), record.Type, data)
+	}
+	for _, skipped := range d.Skipped {
+		fmt.Fprintf(&b, "; skipped %s %q: %s\n", skipped.IP, skipped.Hostname, skipped.Reason)
+	}
+	return b.String()
+}
+
+func (d DNSZoneData) relativeName(name string) string {
+	if name == d.Zone.Name {
+		return "@"
+	}
+	if relative, ok := strings.CutSuffix(name, "."+d.Zone.Name); ok {
+		return relative
+	}
+	return name + "."
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrNotFound        = errors.New("not found")
@@ -11,4 +14,41 @@ var (
 	ErrUnauthorized    = errors.New("unauthorized")
 	ErrDiscoveryBusy   = errors.New("kubernetes discovery reconciliation already running")
 	ErrIPv6Unsupported = errors.New("IPv6 subnet usage reporting is not supported")
+	ErrSubnetFull      = errors.New("subnet has no free address")
+
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+	// ErrDiscoveryUnanswered reports that an on-demand discovery cycle was
+	// requested from the other replicas but none reported an outcome, for
+	// example because none could start the source's runner.
+	ErrDiscoveryUnanswered = errors.New("no kubernetes discovery runner answered")
 )
+
+// FieldError ties a validation failure to one input field, named as it
+// appears in the API request (for example "cidr" or "site_id").
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError reports invalid input field by field. It matches
+// ErrInvalidInput with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// InvalidField returns a ValidationError for a single field.
+func InvalidField(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var eventObjectTypes = []string{
+	ObjectTypeSubnet, ObjectTypeIP, ObjectTypeSite, ObjectTypeKubernetes, ObjectTypeReporting, ObjectTypeDNSZone,
+	ObjectTypeKubernetesSource,
+}
+
+// EventFilter selects the live events a stream subscriber receives. An empty
+// ObjectTypes matches every type. Events without a site, such as reporting
+// snapshots, match every site filter.
+type EventFilter struct {
+	ObjectTypes []string
+	SiteID      *uuid.UUID
+}
+
+// NewEventFilter validates object types and removes duplicates.
+func NewEventFilter(objectTypes []string, siteID *uuid.UUID) (EventFilter, error) {
+	filter := EventFilter{SiteID: siteID}
+	for _, objectType := range objectTypes {
+		objectType = strings.TrimSpace(objectType)
+		if objectType == "" {
+			continue
+		}
+		if !slices.Contains(eventObjectTypes, objectType) {
+			return EventFilter{}, InvalidField("types", fmt.Sprintf("unsupported event object type %q", objectType))
+		}
+		if !slices.Contains(filter.ObjectTypes, objectType) {
+			filter.ObjectTypes = append(filter.ObjectTypes, objectType)
+		}
+	}
+	return filter, nil
+}
+
+func (f EventFilter) Matches(event ChangeEvent) bool {
+	if len(f.ObjectTypes) > 0 && !slices.Contains(f.ObjectTypes, event.ObjectType) {
+		return false
+	}
+	if f.SiteID != nil && event.SiteID != nil && *f.SiteID != *event.SiteID {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewEventFilterValidatesObjectTypes(t *testing.T) {
+	if _, err := NewEventFilter([]string{"subnet", "vlan"}, nil); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+	filter, err := NewEventFilter([]string{" ip ", "", "subnet", "ip"}, nil)
+	if err != nil {
+		t.Fatalf("new filter: %v", err)
+	}
+	if !slices.Equal(filter.ObjectTypes, []string{"ip", "subnet"}) {
+		t.Fatalf("unexpected object types: %v", filter.ObjectTypes)
+	}
+}
+
+func TestEventFilterMatches(t *testing.T) {
+	siteA := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	siteB := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	filter := EventFilter{ObjectTypes: []string{ObjectTypeIP, ObjectTypeReporting}, SiteID: &siteA}
+
+	tests := []struct {
+		name  string
+		event ChangeEvent
+		want  bool
+	}{
+		{name: "matching type and site", event: ChangeEvent{ObjectType: ObjectTypeIP, SiteID: &siteA}, want: true},
+		{name: "other site", event: ChangeEvent{ObjectType: ObjectTypeIP, SiteID: &siteB}, want: false},
+		{name: "other type", event: ChangeEvent{ObjectType: ObjectTypeSubnet, SiteID: &siteA}, want: false},
+		{name: "siteless event", event: ChangeEvent{ObjectType: ObjectTypeReporting}, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := filter.Matches(test.event); got != test.want {
+				t.Fatalf("Matches() = %v, want %v", got, test.want)
+			}
+		})
+	}
+	if !(EventFilter{}).Matches(ChangeEvent{ObjectType: ObjectTypeSite, SiteID: &siteB}) {
+		t.Fatal("expected empty filter to match everything")
+	}
+}
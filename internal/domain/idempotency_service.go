@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultIdempotencyWindow = 24 * time.Hour
+	MaxIdempotencyKeyLength  = 255
+	// idempotencyPendingTimeout bounds how long a pending key blocks retries
+	// after the replica running the original request died.
+	idempotencyPendingTimeout = 5 * time.Minute
+)
+
+type idempotencyService struct {
+	keys   IdempotencyRepository
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewIdempotencyService stores completed responses for window; a
+// non-positive window uses DefaultIdempotencyWindow.
+func NewIdempotencyService(keys IdempotencyRepository, window time.Duration) IdempotencyService {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	return &idempotencyService{keys: keys, window: window, now: time.Now}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, request IdempotencyRequest) (*IdempotentResponse, error) {
+	if err := validateIdempotencyKey(request.Key); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	record, claimed, err := s.keys.Claim(ctx, IdempotencyRecord{
+		Scope:       request.Scope,
+		Key:         request.Key,
+		Method:      request.Method,
+		Path:        request.Path,
+		RequestHash: request.RequestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.window),
+	}, now.Add(-idempotencyPendingTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+	if record.Method != request.Method || record.Path != request.Path || record.RequestHash != request.RequestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.Response.StatusCode == 0 {
+		return nil, fmt.Errorf("%w: a request with this idempotency key is still in progress", ErrConflict)
+	}
+	response := record.Response
+	return &response, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, request IdempotencyRequest, response IdempotentResponse) error {
+	return s.keys.Complete(ctx, request.Scope, request.Key, response)
+}
+
+func (s *idempotencyService) Release(ctx context.Context, request IdempotencyRequest) error {
+	return s.keys.Release(ctx, request.Scope, request.Key)
+}
+
+func (s *idempotencyService) PruneExpired(ctx context.Context) (int64, error) {
+	return s.keys.DeleteExpired(ctx, s.now().UTC())
+}
+
+func validateIdempotencyKey(key string) error {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return InvalidField("Idempotency-Key", fmt.Sprintf("idempotency key must be 1-%d characters", MaxIdempotencyKeyLength))
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return InvalidField("Idempotency-Key", "idempotency key must contain only visible ASCII characters")
+		}
+	}
+	return nil
+}
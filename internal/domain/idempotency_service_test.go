@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubIdempotencyRepository struct {
+	existing    *IdempotencyRecord
+	claimed     IdempotencyRecord
+	staleBefore time.Time
+	deleteAt    time.Time
+}
+
+func (s *stubIdempotencyRepository) Claim(_ context.Context, record IdempotencyRecord, staleBefore time.Time) (IdempotencyRecord, bool, error) {
+	s.staleBefore = staleBefore
+	if s.existing != nil {
+		return *s.existing, false, nil
+	}
+	s.claimed = record
+	return record, true, nil
+}
+
+func (s *stubIdempotencyRepository) Complete(context.Context, string, string, IdempotentResponse) error {
+	return nil
+}
+
+func (s *stubIdempotencyRepository) Release(context.Context, string, string) error {
+	return nil
+}
+
+func (s *stubIdempotencyRepository) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	s.deleteAt = before
+	return 0, nil
+}
+
+func TestIdempotencyBeginClaimsNewKeys(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repository := &stubIdempotencyRepository{}
+	service := &idempotencyService{keys: repository, window: time.Hour, now: func() time.Time { return now }}
+	request := IdempotencyRequest{Scope: "ci", Key: "deploy-42", Method: "POST", Path: "/api/v1/sites", RequestHash: "abc"}
+
+	response, err := service.Begin(context.Background(), request)
+	if err != nil || response != nil {
+		t.Fatalf("expected claim, got response=%v err=%v", response, err)
+	}
+	if !repository.claimed.ExpiresAt.Equal(now.Add(time.Hour)) || !repository.staleBefore.Equal(now.Add(-idempotencyPendingTimeout)) {
+		t.Fatalf("unexpected claim: %+v stale=%s", repository.claimed, repository.staleBefore)
+	}
+}
+
+func TestIdempotencyBeginResolvesExistingKeys(t *testing.T) {
+	request := IdempotencyRequest{Scope: "ci", Key: "deploy-42", Method: "POST", Path: "/api/v1/sites", RequestHash: "abc"}
+	completed := IdempotencyRecord{Method: "POST", Path: "/api/v1/sites", RequestHash: "abc", Response: IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":"1"}`)}}
+	pending := completed
+	pending.Response = IdempotentResponse{}
+	otherBody := completed
+	otherBody.RequestHash = "def"
+	otherPath := completed
+	otherPath.Path = "/api/v1/subnets"
+
+	tests := []struct {
+		name     string
+		existing IdempotencyRecord
+		wantErr  error
+	}{
+		{name: "completed", existing: completed},
+		{name: "pending", existing: pending, wantErr: ErrConflict},
+		{name: "different body", existing: otherBody, wantErr: ErrIdempotencyKeyReused},
+		{name: "different route", existing: otherPath, wantErr: ErrIdempotencyKeyReused},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewIdempotencyService(&stubIdempotencyRepository{existing: &test.existing}, time.Hour)
+			response, err := service.Begin(context.Background(), request)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected %v, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil || response == nil || response.StatusCode != 201 || string(response.Body) != `{"id":"1"}` {
+				t.Fatalf("expected stored response, got %+v err=%v", response, err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyBeginValidatesKey(t *testing.T) {
+	service := NewIdempotencyService(&stubIdempotencyRepository{}, 0)
+	for _, key := range []string{"", "has space", strings.Repeat("k", MaxIdempotencyKeyLength+1)} {
+		if _, err := service.Begin(context.Background(), IdempotencyRequest{Key: key}); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("expected invalid input for %q, got %v", key, err)
+		}
+	}
+}
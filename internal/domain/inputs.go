@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"io"
+	"net"
 	"net/netip"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -24,14 +27,46 @@ type UpdateSubnetInput struct {
 	Description string
 }
 
+// UpdateSubnetDHCPInput replaces a subnet's DHCP settings. An empty Gateway
+// removes the gateway.
+type UpdateSubnetDHCPInput struct {
+	ID         int64
+	Gateway    string
+	DNSServers []string
+	Pools      []DHCPPoolInput
+}
+
+type DHCPPoolInput struct {
+	Start string
+	End   string
+}
+
 type AssignSubnetSiteInput struct {
 	ID     int64
 	SiteID uuid.UUID
 }
 
 type CreateIPInput struct {
-	IP       string
-	Hostname string
+	IP         string
+	Hostname   string
+	MACAddress string
+	LastSeenAt *time.Time
+}
+
+// LeaseImportInput is a DHCP lease file. A dry run reports what would change
+// without writing anything.
+type LeaseImportInput struct {
+	Format LeaseFileFormat
+	File   io.Reader
+	DryRun bool
+}
+
+// ZoneImportInput is a BIND zone file. Origin names the zone when the file
+// has no $ORIGIN of its own.
+type ZoneImportInput struct {
+	Origin string
+	File   io.Reader
+	DryRun bool
 }
 
 type CreateSiteInput struct {
@@ -39,8 +74,12 @@ type CreateSiteInput struct {
 	Description string
 }
 
+// UpdateIPInput leaves the MAC address unchanged when MACAddress is nil and
+// clears it when it is empty. A nil LastSeenAt keeps the current time.
 type UpdateIPInput struct {
-	Hostname string
+	Hostname   string
+	MACAddress *string
+	LastSeenAt *time.Time
 }
 
 type UpdateSiteInput struct {
@@ -62,12 +101,142 @@ type UpdateSubnetRecord struct {
 	Description string
 }
 
+type UpdateSubnetDHCPRecord struct {
+	ID         int64
+	Gateway    netip.Addr
+	DNSServers []netip.Addr
+	Pools      []DHCPPool
+}
+
 type CreateIPRecord struct {
-	IP       netip.Addr
-	Hostname string
+	IP         netip.Addr
+	Hostname   string
+	MACAddress net.HardwareAddr
+	LastSeenAt *time.Time
+}
+
+type UpdateIPRecord struct {
+	Hostname   string
+	MACAddress net.HardwareAddr
+	LastSeenAt *time.Time
 }
 
 type CreateSiteRecord struct {
 	Name        string
 	Description string
 }
+
+// AllocateLoadBalancerIPInput asks for an address in SubnetID for a
+// Service. Preferred, usually the address already in the Service status, is
+// used when it is free in the subnet.
+type AllocateLoadBalancerIPInput struct {
+	Controller string
+	SubnetID   int64
+	ServiceUID string
+	Namespace  string
+	Name       string
+	Preferred  netip.Addr
+}
+
+// ClaimAddressInput asks for an address in SubnetID for a Cluster API
+// IPAddressClaim.
+type ClaimAddressInput struct {
+	SubnetID  int64
+	ClaimUID  string
+	Namespace string
+	Name      string
+}
+
+// CreateKubernetesSourceInput leaves the intervals at their defaults when
+// they are nil.
+type CreateKubernetesSourceInput struct {
+	Key               string
+	Name              string
+	SiteID            uuid.UUID
+	ClusterDomain     string
+	Namespaces        []string
+	ObjectKinds       []string
+	PodSelector       string
+	AutoRegister      string
+	AuthMode          string
+	Kubeconfig        string
+	KubeconfigContext string
+	ReconcileInterval *time.Duration
+	RequestTimeout    *time.Duration
+	StaleRetention    *time.Duration
+}
+
+// UpdateKubernetesSourceInput leaves nil fields unchanged.
+type UpdateKubernetesSourceInput struct {
+	Key               string
+	Name              *string
+	SiteID            *uuid.UUID
+	ClusterDomain     *string
+	Namespaces        []string
+	ObjectKinds       []string
+	PodSelector       *string
+	AutoRegister      *string
+	AuthMode          *string
+	Kubeconfig        *string
+	KubeconfigContext *string
+	ReconcileInterval *time.Duration
+	RequestTimeout    *time.Duration
+	StaleRetention    *time.Duration
+}
+
+// KubernetesSourceRecord is a stored source. The kubeconfig is kept only as
+// ciphertext.
+type KubernetesSourceRecord struct {
+	Key                  string
+	Name                 string
+	SiteID               uuid.UUID
+	ClusterDomain        string
+	Namespaces           []string
+	ObjectKinds          []string
+	PodSelector          string
+	AutoRegister         string
+	Managed              bool
+	AuthMode             string
+	KubeconfigCiphertext []byte
+	KubeconfigContext    string
+	ReconcileInterval    time.Duration
+	RequestTimeout       time.Duration
+	StaleRetention       time.Duration
+}
+
+type CreateWebhookSubscriptionInput struct {
+	URL         string
+	Description string
+	EventTypes  []string
+	Secret      string
+	Active      *bool
+}
+
+// UpdateWebhookSubscriptionInput leaves nil fields unchanged.
+type UpdateWebhookSubscriptionInput struct {
+	ID          uuid.UUID
+	URL         *string
+	Description *string
+	EventTypes  []string
+	Secret      *string
+	Active      *bool
+}
+
+// IdempotencyRequest identifies a keyed request. Keys are scoped to Scope,
+// the authenticated subject, so clients cannot replay each other's results.
+type IdempotencyRequest struct {
+	Scope       string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+}
+
+// CreateDNSZoneInput attaches a forward zone to exactly one of a site or a
+// subnet. TTL defaults to DNSSettings.DefaultTTL.
+type CreateDNSZoneInput struct {
+	Name     string
+	SiteID   *uuid.UUID
+	SubnetID *int64
+	TTL      *int32
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "context"
+
+type kubernetesAddressClaimService struct {
+	repository KubernetesAddressClaimRepository
+	dnsZones   DNSZoneRepository
+}
+
+// NewKubernetesAddressClaimService records claim addresses through the
+// repository rather than NetworkService.CreateIP, because the address is
+// picked and inserted under the subnet's allocation lock in one transaction.
+// Claim applies the checks CreateIP would to such a record: the address is
+// a free one of the subnet by construction, and the hostname, the claim's
+// name, must be valid in the subnet's DNS zone.
+func NewKubernetesAddressClaimService(repository KubernetesAddressClaimRepository, dnsZones DNSZoneRepository) KubernetesAddressClaimService {
+	return &kubernetesAddressClaimService{repository: repository, dnsZones: dnsZones}
+}
+
+func (s *kubernetesAddressClaimService) Claim(ctx context.Context, input ClaimAddressInput) (KubernetesAddressClaim, error) {
+	switch {
+	case input.SubnetID <= 0:
+		return KubernetesAddressClaim{}, InvalidField("subnet_id", "subnet_id must be positive")
+	case input.ClaimUID == "":
+		return KubernetesAddressClaim{}, InvalidField("claim_uid", "claim_uid is required")
+	case input.Namespace == "" || input.Name == "":
+		return KubernetesAddressClaim{}, InvalidField("name", "claim namespace and name are required")
+	}
+	if err := validateZoneHostname(ctx, s.dnsZones, input.SubnetID, input.Name); err != nil {
+		return KubernetesAddressClaim{}, err
+	}
+	return s.repository.Claim(ctx, input)
+}
+
+func (s *kubernetesAddressClaimService) Release(ctx context.Context, claimUID string) (bool, error) {
+	if claimUID == "" {
+		return false, InvalidField("claim_uid", "claim_uid is required")
+	}
+	return s.repository.Release(ctx, claimUID)
+}
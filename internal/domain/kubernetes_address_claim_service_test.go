@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+type stubKubernetesAddressClaimRepository struct {
+	inputs []ClaimAddressInput
+}
+
+func (s *stubKubernetesAddressClaimRepository) Claim(_ context.Context, input ClaimAddressInput) (KubernetesAddressClaim, error) {
+	s.inputs = append(s.inputs, input)
+	return KubernetesAddressClaim{ClaimUID: input.ClaimUID, SubnetID: input.SubnetID}, nil
+}
+
+func (s *stubKubernetesAddressClaimRepository) Release(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func TestKubernetesAddressClaimServiceValidatesInput(t *testing.T) {
+	valid := ClaimAddressInput{SubnetID: 1, ClaimUID: "uid", Namespace: "capi", Name: "worker-0"}
+	tests := []struct {
+		name   string
+		mutate func(*ClaimAddressInput)
+		field  string
+	}{
+		{name: "subnet", mutate: func(input *ClaimAddressInput) { input.SubnetID = 0 }, field: "subnet_id"},
+		{name: "uid", mutate: func(input *ClaimAddressInput) { input.ClaimUID = "" }, field: "claim_uid"},
+		{name: "name", mutate: func(input *ClaimAddressInput) { input.Namespace = "" }, field: "name"},
+	}
+	repo := &stubKubernetesAddressClaimRepository{}
+	service := NewKubernetesAddressClaimService(repo, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.mutate(&input)
+			_, err := service.Claim(context.Background(), input)
+			var validation *ValidationError
+			if !errors.As(err, &validation) || validation.Fields[0].Field != tt.field {
+				t.Fatalf("expected a validation error for %s, got %v", tt.field, err)
+			}
+		})
+	}
+	if len(repo.inputs) != 0 {
+		t.Fatalf("invalid claims reached the repository: %+v", repo.inputs)
+	}
+
+	if _, err := service.Release(context.Background(), ""); err == nil {
+		t.Fatal("expected a release without a claim UID to fail")
+	}
+}
+
+// Claims skip NetworkService.CreateIP, so the claim name, which becomes the
+// record's hostname, must pass the same zone check as a manual address.
+func TestKubernetesAddressClaimServiceValidatesHostnamesInZonedSubnets(t *testing.T) {
+	zonedSubnet := int64(1)
+	zones := &stubDNSZoneRepository{zones: []DNSZone{{Name: "lab.example.com", SubnetID: &zonedSubnet}}}
+	repo := &stubKubernetesAddressClaimRepository{}
+	service := NewKubernetesAddressClaimService(repo, zones)
+	network := NewNetworkServiceWithDiscovery(stubSubnetRepository{findFn: func(_ context.Context, id int64) (Subnet, error) {
+		return Subnet{ID: id, CIDR: netip.MustParsePrefix("10.0.0.0/24")}, nil
+	}}, stubIPRepository{}, nil, nil, zones)
+
+	input := ClaimAddressInput{SubnetID: 1, ClaimUID: "uid", Namespace: "capi", Name: "worker_0"}
+	_, claimErr := service.Claim(context.Background(), input)
+	_, createErr := network.CreateIP(context.Background(), 1, CreateIPInput{IP: "10.0.0.5", Hostname: input.Name})
+	var claimValidation, createValidation *ValidationError
+	if !errors.As(claimErr, &claimValidation) || !errors.As(createErr, &createValidation) || claimErr.Error() != createErr.Error() {
+		t.Fatalf("expected the CreateIP hostname validation, got claim=%v create=%v", claimErr, createErr)
+	}
+	if len(repo.inputs) != 0 {
+		t.Fatalf("a claim with an invalid hostname reached the repository: %+v", repo.inputs)
+	}
+
+	input.Name = "worker-0"
+	if _, err := service.Claim(context.Background(), input); err != nil {
+		t.Fatalf("valid claim name rejected: %v", err)
+	}
+	input.SubnetID, input.Name = 2, "worker_0"
+	if _, err := service.Claim(context.Background(), input); err != nil {
+		t.Fatalf("subnet without a zone should accept any claim name: %v", err)
+	}
+}
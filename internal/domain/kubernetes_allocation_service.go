@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"maps"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+type kubernetesAllocationService struct {
+	repository KubernetesAllocationRepository
+}
+
+func NewKubernetesAllocationService(repository KubernetesAllocationRepository) KubernetesAllocationService {
+	return &kubernetesAllocationService{repository: repository}
+}
+
+func (s *kubernetesAllocationService) Allocate(ctx context.Context, input AllocateLoadBalancerIPInput) (KubernetesLoadBalancerAllocation, error) {
+	input.Controller = strings.TrimSpace(input.Controller)
+	switch {
+	case input.Controller == "":
+		return KubernetesLoadBalancerAllocation{}, InvalidField("controller", "controller is required")
+	case input.SubnetID <= 0:
+		return KubernetesLoadBalancerAllocation{}, InvalidField("subnet_id", "subnet_id must be positive")
+	case input.ServiceUID == "":
+		return KubernetesLoadBalancerAllocation{}, InvalidField("service_uid", "service_uid is required")
+	case input.Namespace == "" || input.Name == "":
+		return KubernetesLoadBalancerAllocation{}, InvalidField("name", "service namespace and name are required")
+	}
+	if input.Preferred.IsValid() {
+		input.Preferred = input.Preferred.Unmap()
+	}
+	return s.repository.Allocate(ctx, input)
+}
+
+func (s *kubernetesAllocationService) Release(ctx context.Context, controller, serviceUID string) (bool, error) {
+	if strings.TrimSpace(controller) == "" || serviceUID == "" {
+		return false, InvalidField("service_uid", "controller and service_uid are required")
+	}
+	return s.repository.Release(ctx, strings.TrimSpace(controller), serviceUID)
+}
+
+func (s *kubernetesAllocationService) ReleaseExcept(ctx context.Context, controller string, serviceUIDs []string, listedAt time.Time) (int64, error) {
+	if strings.TrimSpace(controller) == "" {
+		return 0, InvalidField("controller", "controller is required")
+	}
+	if listedAt.IsZero() {
+		return 0, InvalidField("listed_at", "listed_at is required")
+	}
+	if serviceUIDs == nil {
+		serviceUIDs = make([]string, 0)
+	}
+	return s.repository.ReleaseExcept(ctx, strings.TrimSpace(controller), serviceUIDs, listedAt)
+}
+
+// PickFreeAddress returns preferred when it is a usable address of prefix
+// that is not in used, and otherwise the lowest such address. It reports
+// false when every usable address is taken.
+func PickFreeAddress(prefix netip.Prefix, used map[netip.Addr]bool, preferred netip.Addr) (netip.Addr, bool) {
+	return pickAddress(prefix, used, nil, preferred)
+}
+
+// FreeAddress is PickFreeAddress for subnet that also treats its gateway and
+// every address of its DHCP pools as used, so allocations outside of DHCP
+// never hand out an address a DHCP server may lease.
+func FreeAddress(subnet Subnet, used map[netip.Addr]bool, preferred netip.Addr) (netip.Addr, bool) {
+	if subnet.Gateway.IsValid() && !used[subnet.Gateway] {
+		used = maps.Clone(used)
+		if used == nil {
+			used = make(map[netip.Addr]bool, 1)
+		}
+		used[subnet.Gateway] = true
+	}
+	return pickAddress(subnet.CIDR, used, subnet.DHCPPools, preferred)
+}
+
+// pickAddress skips over a whole pool at once rather than address by
+// address, which keeps large IPv6 pools cheap.
+func pickAddress(prefix netip.Prefix, used map[netip.Addr]bool, pools []DHCPPool, preferred netip.Addr) (netip.Addr, bool) {
+	prefix = prefix.Masked()
+	if preferred.IsValid() && !used[preferred] && poolContaining(pools, preferred) == nil && validateIPInSubnet(prefix, preferred) == nil {
+		return preferred, true
+	}
+	for address := prefix.Addr(); address.IsValid() && prefix.Contains(address); address = address.Next() {
+		if pool := poolContaining(pools, address); pool != nil {
+			address = pool.End
+			continue
+		}
+		if !used[address] && validateIPInSubnet(prefix, address) == nil {
+			return address, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+func poolContaining(pools []DHCPPool, address netip.Addr) *DHCPPool {
+	for i := range pools {
+		if pools[i].Start.Compare(address) <= 0 && address.Compare(pools[i].End) <= 0 {
+			return &pools[i]
+		}
+	}
+	return nil
+}
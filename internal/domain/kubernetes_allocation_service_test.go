@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+type stubKubernetesAllocationRepository struct {
+	inputs []AllocateLoadBalancerIPInput
+	kept   []string
+}
+
+func (s *stubKubernetesAllocationRepository) Allocate(_ context.Context, input AllocateLoadBalancerIPInput) (KubernetesLoadBalancerAllocation, error) {
+	s.inputs = append(s.inputs, input)
+	return KubernetesLoadBalancerAllocation{Controller: input.Controller, ServiceUID: input.ServiceUID, IP: input.Preferred}, nil
+}
+
+func (s *stubKubernetesAllocationRepository) Release(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+func (s *stubKubernetesAllocationRepository) ReleaseExcept(_ context.Context, _ string, serviceUIDs []string, _ time.Time) (int64, error) {
+	s.kept = serviceUIDs
+	return 0, nil
+}
+
+func TestKubernetesAllocationServiceValidatesInput(t *testing.T) {
+	valid := AllocateLoadBalancerIPInput{Controller: "prod", SubnetID: 1, ServiceUID: "uid", Namespace: "apps", Name: "orders"}
+	tests := []struct {
+		name   string
+		mutate func(*AllocateLoadBalancerIPInput)
+		field  string
+	}{
+		{name: "controller", mutate: func(input *AllocateLoadBalancerIPInput) { input.Controller = " " }, field: "controller"},
+		{name: "subnet", mutate: func(input *AllocateLoadBalancerIPInput) { input.SubnetID = 0 }, field: "subnet_id"},
+		{name: "uid", mutate: func(input *AllocateLoadBalancerIPInput) { input.ServiceUID = "" }, field: "service_uid"},
+		{name: "name", mutate: func(input *AllocateLoadBalancerIPInput) { input.Name = "" }, field: "name"},
+	}
+	service := NewKubernetesAllocationService(&stubKubernetesAllocationRepository{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.mutate(&input)
+			_, err := service.Allocate(context.Background(), input)
+			var validation *ValidationError
+			if !errors.As(err, &validation) || validation.Fields[0].Field != tt.field {
+				t.Fatalf("expected a validation error for %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestKubernetesAllocationServiceNormalizesInput(t *testing.T) {
+	repo := &stubKubernetesAllocationRepository{}
+	service := NewKubernetesAllocationService(repo)
+	_, err := service.Allocate(context.Background(), AllocateLoadBalancerIPInput{
+		Controller: " prod ", SubnetID: 1, ServiceUID: "uid", Namespace: "apps", Name: "orders",
+		Preferred: netip.MustParseAddr("::ffff:192.0.2.5"),
+	})
+	if err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+	if repo.inputs[0].Controller != "prod" || repo.inputs[0].Preferred != netip.MustParseAddr("192.0.2.5") {
+		t.Fatalf("unexpected input: %+v", repo.inputs[0])
+	}
+
+	if _, err := service.ReleaseExcept(context.Background(), "prod", nil, time.Now()); err != nil {
+		t.Fatalf("ReleaseExcept: %v", err)
+	}
+	if repo.kept == nil {
+		t.Fatal("expected an empty list rather than nil, which would release nothing")
+	}
+	var validation *ValidationError
+	if _, err := service.ReleaseExcept(context.Background(), "prod", nil, time.Time{}); !errors.As(err, &validation) || validation.Fields[0].Field != "listed_at" {
+		t.Fatalf("expected a validation error for listed_at, got %v", err)
+	}
+}
+
+func TestPickFreeAddress(t *testing.T) {
+	used := func(addresses ...string) map[netip.Addr]bool {
+		set := make(map[netip.Addr]bool, len(addresses))
+		for _, address := range addresses {
+			set[netip.MustParseAddr(address)] = true
+		}
+		return set
+	}
+	tests := []struct {
+		name      string
+		prefix    string
+		used      map[netip.Addr]bool
+		preferred string
+		want      string
+	}{
+		{name: "lowest usable", prefix: "192.0.2.0/29", used: used("192.0.2.1"), want: "192.0.2.2"},
+		{name: "preferred", prefix: "192.0.2.0/29", used: used(), preferred: "192.0.2.5", want: "192.0.2.5"},
+		{name: "preferred taken", prefix: "192.0.2.0/29", used: used("192.0.2.5"), preferred: "192.0.2.5", want: "192.0.2.1"},
+		{name: "preferred outside", prefix: "192.0.2.0/29", used: used(), preferred: "198.51.100.1", want: "192.0.2.1"},
+		{name: "preferred broadcast", prefix: "192.0.2.0/29", used: used(), preferred: "192.0.2.7", want: "192.0.2.1"},
+		{name: "point to point", prefix: "192.0.2.0/31", used: used(), want: "192.0.2.0"},
+		{name: "ipv6", prefix: "2001:db8::/64", used: used("2001:db8::"), want: "2001:db8::1"},
+		{name: "full", prefix: "192.0.2.0/30", used: used("192.0.2.1", "192.0.2.2")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var preferred netip.Addr
+			if tt.preferred != "" {
+				preferred = netip.MustParseAddr(tt.preferred)
+			}
+			got, ok := PickFreeAddress(netip.MustParsePrefix(tt.prefix), tt.used, preferred)
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("expected a full subnet, got %s", got)
+				}
+				return
+			}
+			if !ok || got.String() != tt.want {
+				t.Fatalf("expected %s, got %s (%v)", tt.want, got, ok)
+			}
+		})
+	}
+}
+
+func TestFreeAddressSkipsGatewayAndDHCPPools(t *testing.T) {
+	subnet := Subnet{
+		CIDR:    netip.MustParsePrefix("192.0.2.0/28"),
+		Gateway: netip.MustParseAddr("192.0.2.1"),
+		DHCPPools: []DHCPPool{
+			{Start: netip.MustParseAddr("192.0.2.2"), End: netip.MustParseAddr("192.0.2.9")},
+		},
+	}
+	used := map[netip.Addr]bool{netip.MustParseAddr("192.0.2.10"): true}
+
+	got, ok := FreeAddress(subnet, used, netip.Addr{})
+	if !ok || got.String() != "192.0.2.11" {
+		t.Fatalf("expected 192.0.2.11, got %s (%v)", got, ok)
+	}
+	if len(used) != 1 {
+		t.Fatalf("expected the caller's used set to stay untouched, got %v", used)
+	}
+	for _, preferred := range []string{"192.0.2.1", "192.0.2.5"} {
+		got, ok = FreeAddress(subnet, used, netip.MustParseAddr(preferred))
+		if !ok || got.String() != "192.0.2.11" {
+			t.Fatalf("preferred %s: expected 192.0.2.11, got %s (%v)", preferred, got, ok)
+		}
+	}
+	got, ok = FreeAddress(subnet, used, netip.MustParseAddr("192.0.2.12"))
+	if !ok || got.String() != "192.0.2.12" {
+		t.Fatalf("expected the free preferred address, got %s (%v)", got, ok)
+	}
+
+	subnet.DHCPPools = append(subnet.DHCPPools, DHCPPool{Start: netip.MustParseAddr("192.0.2.11"), End: netip.MustParseAddr("192.0.2.14")})
+	if got, ok = FreeAddress(subnet, used, netip.Addr{}); ok {
+		t.Fatalf("expected a full subnet, got %s", got)
+	}
+}
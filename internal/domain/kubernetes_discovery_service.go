@@ -9,6 +9,10 @@ import (
 
 const maxDiscoveryErrorLength = 2048
 
+// MaxKubernetesDiscoveryHistory bounds the runs or Service changes returned
+// by one history request.
+const MaxKubernetesDiscoveryHistory int32 = 500
+
 type kubernetesDiscoveryService struct {
 	repository KubernetesDiscoveryRepository
 }
@@ -17,19 +21,60 @@ func NewKubernetesDiscoveryService(repository KubernetesDiscoveryRepository) Kub
 	return &kubernetesDiscoveryService{repository: repository}
 }
 
-func (s *kubernetesDiscoveryService) Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, observedAt time.Time) (KubernetesReconcileResult, error) {
-	return s.repository.Reconcile(ctx, source, services, observedAt)
+func (s *kubernetesDiscoveryService) Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, startedAt, observedAt time.Time) (KubernetesReconcileResult, error) {
+	return s.repository.Reconcile(ctx, source, services, startedAt, observedAt)
+}
+
+func (s *kubernetesDiscoveryService) ApplyChanges(ctx context.Context, source KubernetesSourceConfig, changes KubernetesServiceChanges, observedAt time.Time) (KubernetesReconcileResult, error) {
+	return s.repository.ApplyChanges(ctx, source, changes, observedAt)
+}
+
+func (s *kubernetesDiscoveryService) ReconcileObjects(ctx context.Context, source KubernetesSourceConfig, objects []KubernetesObjectSnapshot, observedAt time.Time) (KubernetesObjectReconcileResult, error) {
+	return s.repository.ReconcileObjects(ctx, source, objects, observedAt)
 }
 
 func (s *kubernetesDiscoveryService) RecordFailure(ctx context.Context, source KubernetesSourceConfig, attemptedAt time.Time, discoveryErr error) error {
-	message := strings.TrimSpace(discoveryErr.Error())
+	if err := s.repository.RecordFailure(ctx, source, attemptedAt, discoveryErrorMessage(discoveryErr)); err != nil {
+		return fmt.Errorf("record kubernetes discovery failure: %w", err)
+	}
+	return nil
+}
+
+func (s *kubernetesDiscoveryService) RecordObjectFailure(ctx context.Context, source KubernetesSourceConfig, discoveryErr error) error {
+	if err := s.repository.RecordObjectFailure(ctx, source, discoveryErrorMessage(discoveryErr)); err != nil {
+		return fmt.Errorf("record kubernetes object discovery failure: %w", err)
+	}
+	return nil
+}
+
+func discoveryErrorMessage(err error) string {
+	message := strings.TrimSpace(err.Error())
 	if len(message) > maxDiscoveryErrorLength {
 		message = message[:maxDiscoveryErrorLength]
 	}
-	if err := s.repository.RecordFailure(ctx, source, attemptedAt, message); err != nil {
-		return fmt.Errorf("record kubernetes discovery failure: %w", err)
+	return message
+}
+
+func (s *kubernetesDiscoveryService) RequestReconcile(ctx context.Context, key, requestID string, requestedAt time.Time) error {
+	if requestID == "" {
+		return InvalidField("request_id", "request_id is required")
 	}
-	return nil
+	return s.repository.RequestReconcile(ctx, strings.TrimSpace(key), requestID, requestedAt)
+}
+
+type kubernetesCycleRequestKey struct{}
+
+// WithKubernetesCycleRequest marks ctx as carrying out the on-demand cycle
+// requested as requestID, so the cycle's notification answers the request.
+func WithKubernetesCycleRequest(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, kubernetesCycleRequestKey{}, requestID)
+}
+
+// KubernetesCycleRequestID returns the ID of the request ctx carries out, or
+// "" for a cycle nobody requested.
+func KubernetesCycleRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(kubernetesCycleRequestKey{}).(string)
+	return requestID
 }
 
 func (s *kubernetesDiscoveryService) ListSourceStatuses(ctx context.Context) ([]KubernetesSourceStatus, error) {
@@ -40,6 +85,35 @@ func (s *kubernetesDiscoveryService) ListSourceStatuses(ctx context.Context) ([]
 	return statuses, err
 }
 
+func (s *kubernetesDiscoveryService) ListRuns(ctx context.Context, key string, limit int32) ([]KubernetesDiscoveryRun, error) {
+	if err := validateKubernetesHistoryLimit(limit); err != nil {
+		return nil, err
+	}
+	runs, err := s.repository.ListRuns(ctx, strings.TrimSpace(key), limit)
+	if runs == nil {
+		runs = make([]KubernetesDiscoveryRun, 0)
+	}
+	return runs, err
+}
+
+func (s *kubernetesDiscoveryService) ListEvents(ctx context.Context, key string, runID *int64, limit int32) ([]KubernetesDiscoveryEvent, error) {
+	if err := validateKubernetesHistoryLimit(limit); err != nil {
+		return nil, err
+	}
+	events, err := s.repository.ListEvents(ctx, strings.TrimSpace(key), runID, limit)
+	if events == nil {
+		events = make([]KubernetesDiscoveryEvent, 0)
+	}
+	return events, err
+}
+
+func validateKubernetesHistoryLimit(limit int32) error {
+	if limit <= 0 || limit > MaxKubernetesDiscoveryHistory {
+		return InvalidField("limit", fmt.Sprintf("limit must be between 1 and %d", MaxKubernetesDiscoveryHistory))
+	}
+	return nil
+}
+
 func (s *kubernetesDiscoveryService) ListServicesBySubnetID(ctx context.Context, subnetID int64) ([]KubernetesServiceObservation, error) {
 	services, err := s.repository.ListAllServicesBySubnetID(ctx, subnetID)
 	if services == nil {
@@ -47,3 +121,11 @@ func (s *kubernetesDiscoveryService) ListServicesBySubnetID(ctx context.Context,
 	}
 	return services, err
 }
+
+func (s *kubernetesDiscoveryService) ListObjectsBySubnetID(ctx context.Context, subnetID int64) ([]KubernetesObjectObservation, error) {
+	objects, err := s.repository.ListAllObjectsBySubnetID(ctx, subnetID)
+	if objects == nil {
+		objects = make([]KubernetesObjectObservation, 0)
+	}
+	return objects, err
+}
@@ -0,0 +1,360 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultKubernetesClusterDomain     = "cluster.local"
+	DefaultKubernetesReconcileInterval = 5 * time.Minute
+	DefaultKubernetesRequestTimeout    = 15 * time.Second
+	DefaultKubernetesStaleRetention    = 7 * 24 * time.Hour
+)
+
+const maxKubernetesSourceSeconds = math.MaxInt32 * time.Second
+
+type kubernetesSourceService struct {
+	sources         KubernetesSourceRepository
+	sites           SiteRepository
+	cipher          SecretCipher
+	checkKubeconfig func(kubeconfig []byte, context string) error
+}
+
+// NewKubernetesSourceService stores kubeconfigs encrypted with cipher. A nil
+// cipher means no key is configured, so only in_cluster sources can be
+// created. checkKubeconfig rejects kubeconfigs a runner could not use.
+func NewKubernetesSourceService(sources KubernetesSourceRepository, sites SiteRepository, cipher SecretCipher, checkKubeconfig func(kubeconfig []byte, context string) error) KubernetesSourceService {
+	return &kubernetesSourceService{sources: sources, sites: sites, cipher: cipher, checkKubeconfig: checkKubeconfig}
+}
+
+func (s *kubernetesSourceService) CreateSource(ctx context.Context, input CreateKubernetesSourceInput) (KubernetesSourceStatus, error) {
+	source := KubernetesSourceRecord{
+		Key:               strings.TrimSpace(input.Key),
+		Name:              strings.TrimSpace(input.Name),
+		SiteID:            input.SiteID,
+		ClusterDomain:     strings.TrimSpace(input.ClusterDomain),
+		Namespaces:        input.Namespaces,
+		ObjectKinds:       input.ObjectKinds,
+		PodSelector:       strings.TrimSpace(input.PodSelector),
+		AutoRegister:      strings.TrimSpace(input.AutoRegister),
+		Managed:           true,
+		AuthMode:          strings.TrimSpace(input.AuthMode),
+		KubeconfigContext: strings.TrimSpace(input.KubeconfigContext),
+		ReconcileInterval: durationOrDefault(input.ReconcileInterval, DefaultKubernetesReconcileInterval),
+		RequestTimeout:    durationOrDefault(input.RequestTimeout, DefaultKubernetesRequestTimeout),
+		StaleRetention:    durationOrDefault(input.StaleRetention, DefaultKubernetesStaleRetention),
+	}
+	if err := validateKubernetesSourceKey(source.Key); err != nil {
+		return KubernetesSourceStatus{}, err
+	}
+	if source.Name == "" {
+		source.Name = source.Key
+	}
+	if source.ClusterDomain == "" {
+		source.ClusterDomain = DefaultKubernetesClusterDomain
+	}
+	if source.AuthMode == "" {
+		source.AuthMode = KubernetesAuthInCluster
+	}
+	var kubeconfig []byte
+	if input.Kubeconfig != "" {
+		kubeconfig = []byte(input.Kubeconfig)
+	}
+	if err := s.prepare(ctx, &source, kubeconfig); err != nil {
+		return KubernetesSourceStatus{}, err
+	}
+	return s.sources.CreateSource(ctx, source)
+}
+
+func (s *kubernetesSourceService) UpdateSource(ctx context.Context, input UpdateKubernetesSourceInput) (KubernetesSourceStatus, error) {
+	source, err := s.findManagedSource(ctx, input.Key)
+	if err != nil {
+		return KubernetesSourceStatus{}, err
+	}
+	if input.Name != nil {
+		if source.Name = strings.TrimSpace(*input.Name); source.Name == "" {
+			return KubernetesSourceStatus{}, InvalidField("name", "name cannot be empty")
+		}
+	}
+	if input.SiteID != nil {
+		source.SiteID = *input.SiteID
+	}
+	if input.ClusterDomain != nil {
+		source.ClusterDomain = strings.TrimSpace(*input.ClusterDomain)
+	}
+	if input.Namespaces != nil {
+		source.Namespaces = input.Namespaces
+	}
+	if input.ObjectKinds != nil {
+		source.ObjectKinds = input.ObjectKinds
+	}
+	if input.PodSelector != nil {
+		source.PodSelector = strings.TrimSpace(*input.PodSelector)
+	}
+	if input.AutoRegister != nil {
+		source.AutoRegister = strings.TrimSpace(*input.AutoRegister)
+	}
+	if input.AuthMode != nil {
+		source.AuthMode = strings.TrimSpace(*input.AuthMode)
+		if source.AuthMode == KubernetesAuthInCluster {
+			// Switching to in_cluster drops the stored credentials.
+			source.KubeconfigCiphertext = nil
+			source.KubeconfigContext = ""
+		}
+	}
+	if input.KubeconfigContext != nil {
+		source.KubeconfigContext = strings.TrimSpace(*input.KubeconfigContext)
+	}
+	if input.ReconcileInterval != nil {
+		source.ReconcileInterval = *input.ReconcileInterval
+	}
+	if input.RequestTimeout != nil {
+		source.RequestTimeout = *input.RequestTimeout
+	}
+	if input.StaleRetention != nil {
+		source.StaleRetention = *input.StaleRetention
+	}
+	var kubeconfig []byte
+	if input.Kubeconfig != nil {
+		if *input.Kubeconfig == "" {
+			return KubernetesSourceStatus{}, InvalidField("kubeconfig", "kubeconfig cannot be empty")
+		}
+		kubeconfig = []byte(*input.Kubeconfig)
+	}
+	if err := s.prepare(ctx, &source, kubeconfig); err != nil {
+		return KubernetesSourceStatus{}, err
+	}
+	return s.sources.UpdateSource(ctx, source)
+}
+
+// DeleteSource also removes the source's Service observations.
+func (s *kubernetesSourceService) DeleteSource(ctx context.Context, key string) (bool, error) {
+	if _, err := s.findManagedSource(ctx, key); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return s.sources.DeleteSource(ctx, key)
+}
+
+func (s *kubernetesSourceService) ListManagedSources(ctx context.Context) ([]ManagedKubernetesSource, error) {
+	records, err := s.sources.ListManagedSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]ManagedKubernetesSource, 0, len(records))
+	for _, record := range records {
+		source := ManagedKubernetesSource{
+			Config: KubernetesSourceConfig{
+				Key: record.Key, Name: record.Name, SiteID: record.SiteID, ClusterDomain: record.ClusterDomain,
+				Namespaces: record.Namespaces, StaleRetention: record.StaleRetention, Managed: true,
+				ObjectKinds: record.ObjectKinds, PodSelector: record.PodSelector, AutoRegister: record.AutoRegister,
+			},
+			AuthMode:          record.AuthMode,
+			KubeconfigContext: record.KubeconfigContext,
+			ReconcileInterval: record.ReconcileInterval,
+			RequestTimeout:    record.RequestTimeout,
+		}
+		if record.KubeconfigCiphertext != nil {
+			if source.Kubeconfig, err = s.decrypt(record.KubeconfigCiphertext); err != nil {
+				return nil, fmt.Errorf("kubernetes source %q: %w", record.Key, err)
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func (s *kubernetesSourceService) findManagedSource(ctx context.Context, key string) (KubernetesSourceRecord, error) {
+	source, err := s.sources.FindSourceByKey(ctx, key)
+	if err != nil {
+		return KubernetesSourceRecord{}, err
+	}
+	if !source.Managed {
+		return KubernetesSourceRecord{}, fmt.Errorf("%w: kubernetes source %q is configured by its deployment", ErrConflict, key)
+	}
+	return source, nil
+}
+
+// prepare validates source and encrypts kubeconfig, the plaintext supplied
+// with the request. A nil kubeconfig keeps the stored one.
+func (s *kubernetesSourceService) prepare(ctx context.Context, source *KubernetesSourceRecord, kubeconfig []byte) error {
+	if _, err := s.sites.FindByID(ctx, source.SiteID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return InvalidField("site_id", "site not found")
+		}
+		return err
+	}
+	source.ClusterDomain = strings.ToLower(strings.Trim(source.ClusterDomain, "."))
+	if err := validateKubernetesSubdomain(source.ClusterDomain); err != nil {
+		return InvalidField("cluster_domain", "invalid cluster_domain: "+err.Error())
+	}
+	var err error
+	if source.Namespaces, err = normalizeKubernetesNamespaces(source.Namespaces); err != nil {
+		return err
+	}
+	if source.ObjectKinds, err = normalizeKubernetesObjectKinds(source.ObjectKinds); err != nil {
+		return err
+	}
+	if source.PodSelector != "" && !slices.Contains(source.ObjectKinds, KubernetesObjectPod) {
+		return InvalidField("pod_selector", "pod_selector needs pod in object_kinds")
+	}
+	if source.AutoRegister == "" {
+		source.AutoRegister = KubernetesAutoRegisterOff
+	}
+	if !slices.Contains(KubernetesAutoRegisterPolicies, source.AutoRegister) {
+		return InvalidField("auto_register", "auto_register must be "+strings.Join(KubernetesAutoRegisterPolicies, ", "))
+	}
+	durations := []struct {
+		field string
+		value time.Duration
+	}{
+		{"interval_seconds", source.ReconcileInterval},
+		{"request_timeout_seconds", source.RequestTimeout},
+		{"stale_retention_seconds", source.StaleRetention},
+	}
+	for _, duration := range durations {
+		if duration.value < time.Second || duration.value > maxKubernetesSourceSeconds {
+			return InvalidField(duration.field, fmt.Sprintf("%s must be between 1 and %d", duration.field, math.MaxInt32))
+		}
+	}
+
+	switch source.AuthMode {
+	case KubernetesAuthInCluster:
+		if kubeconfig != nil || source.KubeconfigContext != "" {
+			return InvalidField("kubeconfig", "kubeconfig and kubeconfig_context cannot be set with in_cluster auth")
+		}
+		source.KubeconfigCiphertext = nil
+		return nil
+	case KubernetesAuthKubeconfig:
+	default:
+		return InvalidField("auth_mode", "auth_mode must be in_cluster or kubeconfig")
+	}
+	plaintext := kubeconfig
+	if plaintext == nil {
+		if source.KubeconfigCiphertext == nil {
+			return InvalidField("kubeconfig", "kubeconfig is required with kubeconfig auth")
+		}
+		if plaintext, err = s.decrypt(source.KubeconfigCiphertext); err != nil {
+			return err
+		}
+	}
+	if err := s.checkKubeconfig(plaintext, source.KubeconfigContext); err != nil {
+		return InvalidField("kubeconfig", "invalid kubeconfig: "+err.Error())
+	}
+	if kubeconfig == nil {
+		return nil
+	}
+	if s.cipher == nil {
+		return InvalidField("kubeconfig", "kubeconfig credentials cannot be stored because no encryption key is configured")
+	}
+	source.KubeconfigCiphertext, err = s.cipher.Encrypt(kubeconfig)
+	return err
+}
+
+func (s *kubernetesSourceService) decrypt(ciphertext []byte) ([]byte, error) {
+	if s.cipher == nil {
+		return nil, errors.New("stored kubeconfig cannot be read because no encryption key is configured")
+	}
+	plaintext, err := s.cipher.Decrypt(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("stored kubeconfig cannot be read with the configured encryption key: %w", err)
+	}
+	return plaintext, nil
+}
+
+func durationOrDefault(value *time.Duration, fallback time.Duration) time.Duration {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+func validateKubernetesSourceKey(key string) error {
+	if key == "" {
+		return InvalidField("key", "key is required")
+	}
+	if err := validateKubernetesSubdomain(key); err != nil {
+		return InvalidField("key", "invalid key: "+err.Error())
+	}
+	return nil
+}
+
+// normalizeKubernetesNamespaces trims and deduplicates namespaces. "*"
+// selects every namespace and must stand alone.
+func normalizeKubernetesNamespaces(namespaces []string) ([]string, error) {
+	normalized := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || slices.Contains(normalized, namespace) {
+			continue
+		}
+		if namespace != "*" {
+			if err := validateKubernetesLabel(namespace); err != nil {
+				return nil, InvalidField("namespaces", fmt.Sprintf("invalid namespace %q: %v", namespace, err))
+			}
+		}
+		normalized = append(normalized, namespace)
+	}
+	switch {
+	case len(normalized) == 0:
+		return nil, InvalidField("namespaces", "at least one namespace, or *, is required")
+	case len(normalized) > 1 && slices.Contains(normalized, "*"):
+		return nil, InvalidField("namespaces", "* cannot be mixed with named namespaces")
+	}
+	return normalized, nil
+}
+
+// normalizeKubernetesObjectKinds deduplicates kinds and orders them as
+// KubernetesObjectKinds does.
+func normalizeKubernetesObjectKinds(kinds []string) ([]string, error) {
+	for _, kind := range kinds {
+		if !slices.Contains(KubernetesObjectKinds, strings.TrimSpace(kind)) {
+			return nil, InvalidField("object_kinds", fmt.Sprintf("unknown object kind %q; use %s", kind, strings.Join(KubernetesObjectKinds, ", ")))
+		}
+	}
+	normalized := make([]string, 0, len(kinds))
+	for _, kind := range KubernetesObjectKinds {
+		if slices.ContainsFunc(kinds, func(candidate string) bool { return strings.TrimSpace(candidate) == kind }) {
+			normalized = append(normalized, kind)
+		}
+	}
+	return normalized, nil
+}
+
+// validateKubernetesSubdomain applies the RFC 1123 subdomain rule
+// Kubernetes uses for object names.
+func validateKubernetesSubdomain(name string) error {
+	if name == "" || len(name) > 253 {
+		return errors.New("must be between 1 and 253 characters")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if err := validateKubernetesLabel(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKubernetesLabel applies the RFC 1123 label rule Kubernetes uses
+// for namespaces.
+func validateKubernetesLabel(label string) error {
+	if label == "" || len(label) > 63 {
+		return fmt.Errorf("label %q must be between 1 and 63 characters", label)
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		alphanumeric := c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+		if !alphanumeric && (c != '-' || i == 0 || i == len(label)-1) {
+			return fmt.Errorf("label %q must be lower-case letters, digits and inner hyphens", label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type stubKubernetesSourceRepository struct {
+	records map[string]KubernetesSourceRecord
+}
+
+func (s *stubKubernetesSourceRepository) FindSourceByKey(_ context.Context, key string) (KubernetesSourceRecord, error) {
+	record, ok := s.records[key]
+	if !ok {
+		return KubernetesSourceRecord{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *stubKubernetesSourceRepository) CreateSource(_ context.Context, record KubernetesSourceRecord) (KubernetesSourceStatus, error) {
+	s.records[record.Key] = record
+	return KubernetesSourceStatus{Source: KubernetesSource{Key: record.Key, Name: record.Name}, Managed: true}, nil
+}
+
+func (s *stubKubernetesSourceRepository) UpdateSource(_ context.Context, record KubernetesSourceRecord) (KubernetesSourceStatus, error) {
+	s.records[record.Key] = record
+	return KubernetesSourceStatus{Source: KubernetesSource{Key: record.Key, Name: record.Name}, Managed: true}, nil
+}
+
+func (s *stubKubernetesSourceRepository) DeleteSource(_ context.Context, key string) (bool, error) {
+	_, ok := s.records[key]
+	delete(s.records, key)
+	return ok, nil
+}
+
+func (s *stubKubernetesSourceRepository) ListManagedSources(context.Context) ([]KubernetesSourceRecord, error) {
+	var records []KubernetesSourceRecord
+	for _, record := range s.records {
+		if record.Managed {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// reversingCipher stands in for AES so tests can tell ciphertext from
+// plaintext.
+type reversingCipher struct{}
+
+func (reversingCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	out := bytes.Clone(plaintext)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+func (c reversingCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	return c.Encrypt(ciphertext)
+}
+
+func newKubernetesSourceTestService(cipher SecretCipher) (KubernetesSourceService, *stubKubernetesSourceRepository, uuid.UUID) {
+	site := uuid.New()
+	repo := &stubKubernetesSourceRepository{records: map[string]KubernetesSourceRecord{}}
+	check := func(kubeconfig []byte, _ string) error {
+		if !strings.HasPrefix(string(kubeconfig), "apiVersion") {
+			return errors.New("not a kubeconfig")
+		}
+		return nil
+	}
+	return NewKubernetesSourceService(repo, stubDHCPSiteRepository{sites: []uuid.UUID{site}}, cipher, check), repo, site
+}
+
+func TestCreateKubernetesSourceAppliesDefaultsAndEncrypts(t *testing.T) {
+	service, repo, site := newKubernetesSourceTestService(reversingCipher{})
+	_, err := service.CreateSource(context.Background(), CreateKubernetesSourceInput{
+		Key: "prod-a", SiteID: site, Namespaces: []string{"apps", "default", "apps"},
+		AuthMode: KubernetesAuthKubeconfig, Kubeconfig: "apiVersion: v1",
+	})
+	if err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+	record := repo.records["prod-a"]
+	if record.Name != "prod-a" || record.ClusterDomain != DefaultKubernetesClusterDomain || record.ReconcileInterval != DefaultKubernetesReconcileInterval || record.StaleRetention != DefaultKubernetesStaleRetention {
+		t.Fatalf("defaults not applied: %+v", record)
+	}
+	if len(record.Namespaces) != 2 || !record.Managed || record.AutoRegister != KubernetesAutoRegisterOff {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if string(record.KubeconfigCiphertext) != "1v :noisreVipa" {
+		t.Fatalf("kubeconfig was not encrypted: %q", record.KubeconfigCiphertext)
+	}
+
+	sources, err := service.ListManagedSources(context.Background())
+	if err != nil || len(sources) != 1 || string(sources[0].Kubeconfig) != "apiVersion: v1" || !sources[0].Config.Managed {
+		t.Fatalf("unexpected managed sources: %+v, %v", sources, err)
+	}
+}
+
+func TestCreateKubernetesSourceRejectsInvalidInput(t *testing.T) {
+	service, _, site := newKubernetesSourceTestService(reversingCipher{})
+	zero := time.Duration(0)
+	tests := []struct {
+		name  string
+		input CreateKubernetesSourceInput
+		field string
+	}{
+		{"missing key", CreateKubernetesSourceInput{SiteID: site, Namespaces: []string{"default"}}, "key"},
+		{"unknown site", CreateKubernetesSourceInput{Key: "a", SiteID: uuid.New(), Namespaces: []string{"default"}}, "site_id"},
+		{"no namespaces", CreateKubernetesSourceInput{Key: "a", SiteID: site}, "namespaces"},
+		{"zero interval", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, ReconcileInterval: &zero}, "interval_seconds"},
+		{"bad auth mode", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, AuthMode: "token"}, "auth_mode"},
+		{"missing kubeconfig", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, AuthMode: KubernetesAuthKubeconfig}, "kubeconfig"},
+		{"bad kubeconfig", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, AuthMode: KubernetesAuthKubeconfig, Kubeconfig: "{}"}, "kubeconfig"},
+		{"in-cluster kubeconfig", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, Kubeconfig: "apiVersion: v1"}, "kubeconfig"},
+		{"unknown object kind", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, ObjectKinds: []string{"deployment"}}, "object_kinds"},
+		{"selector without pods", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, ObjectKinds: []string{"node"}, PodSelector: "app=edge"}, "pod_selector"},
+		{"unknown auto-register policy", CreateKubernetesSourceInput{Key: "a", SiteID: site, Namespaces: []string{"default"}, AutoRegister: "always"}, "auto_register"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateSource(context.Background(), tt.input)
+			var validation *ValidationError
+			if !errors.As(err, &validation) || validation.Fields[0].Field != tt.field {
+				t.Fatalf("expected %s validation error, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestKubernetesSourceKubeconfigNeedsEncryptionKey(t *testing.T) {
+	service, _, site := newKubernetesSourceTestService(nil)
+	_, err := service.CreateSource(context.Background(), CreateKubernetesSourceInput{
+		Key: "a", SiteID: site, Namespaces: []string{"default"}, AuthMode: KubernetesAuthKubeconfig, Kubeconfig: "apiVersion: v1",
+	})
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "no encryption key") {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+	if _, err := service.CreateSource(context.Background(), CreateKubernetesSourceInput{Key: "b", SiteID: site, Namespaces: []string{"default"}}); err != nil {
+		t.Fatalf("in-cluster source without a key: %v", err)
+	}
+}
+
+func TestUpdateKubernetesSource(t *testing.T) {
+	service, repo, site := newKubernetesSourceTestService(reversingCipher{})
+	ctx := context.Background()
+	if _, err := service.CreateSource(ctx, CreateKubernetesSourceInput{
+		Key: "a", SiteID: site, Namespaces: []string{"default"}, AuthMode: KubernetesAuthKubeconfig, Kubeconfig: "apiVersion: v1", KubeconfigContext: "prod",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	interval := time.Minute
+	if _, err := service.UpdateSource(ctx, UpdateKubernetesSourceInput{Key: "a", Namespaces: []string{"*"}, ReconcileInterval: &interval}); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	record := repo.records["a"]
+	if record.Namespaces[0] != "*" || record.ReconcileInterval != time.Minute || record.KubeconfigContext != "prod" || record.KubeconfigCiphertext == nil {
+		t.Fatalf("update lost fields: %+v", record)
+	}
+
+	if _, err := service.UpdateSource(ctx, UpdateKubernetesSourceInput{Key: "a", ObjectKinds: []string{"pod", "node", "pod"}}); err != nil {
+		t.Fatalf("add object kinds: %v", err)
+	}
+	if record = repo.records["a"]; len(record.ObjectKinds) != 2 || record.ObjectKinds[0] != KubernetesObjectNode {
+		t.Fatalf("object kinds not normalized: %v", record.ObjectKinds)
+	}
+	if _, err := service.UpdateSource(ctx, UpdateKubernetesSourceInput{Key: "a", ObjectKinds: []string{}}); err != nil || len(repo.records["a"].ObjectKinds) != 0 {
+		t.Fatalf("empty object kinds did not clear them: %v, %v", repo.records["a"].ObjectKinds, err)
+	}
+
+	inCluster := KubernetesAuthInCluster
+	if _, err := service.UpdateSource(ctx, UpdateKubernetesSourceInput{Key: "a", AuthMode: &inCluster}); err != nil {
+		t.Fatalf("switch to in_cluster: %v", err)
+	}
+	if record = repo.records["a"]; record.KubeconfigCiphertext != nil || record.KubeconfigContext != "" {
+		t.Fatalf("credentials kept after switching to in_cluster: %+v", record)
+	}
+
+	repo.records["configured"] = KubernetesSourceRecord{Key: "configured", SiteID: site}
+	if _, err := service.UpdateSource(ctx, UpdateKubernetesSourceInput{Key: "configured"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected conflict for a configured source, got %v", err)
+	}
+	if _, err := service.DeleteSource(ctx, "configured"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected conflict deleting a configured source, got %v", err)
+	}
+	if deleted, err := service.DeleteSource(ctx, "missing"); deleted || err != nil {
+		t.Fatalf("expected missing source to report not deleted, got %t, %v", deleted, err)
+	}
+}
+
+func TestKubernetesSourceConfigRegistersAddress(t *testing.T) {
+	tests := []struct {
+		policy        string
+		clusterIP, lb bool
+	}{
+		{KubernetesAutoRegisterOff, false, false},
+		{"", false, false},
+		{KubernetesAutoRegisterAll, true, true},
+		{KubernetesAutoRegisterLoadBalancerOnly, false, true},
+	}
+	for _, tt := range tests {
+		source := KubernetesSourceConfig{AutoRegister: tt.policy}
+		if source.RegistersAddress("cluster_ip") != tt.clusterIP || source.RegistersAddress("load_balancer") != tt.lb {
+			t.Fatalf("policy %q: unexpected registration", tt.policy)
+		}
+	}
+}
@@ -0,0 +1,514 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LeaseFileFormat names the DHCP server that wrote a lease file.
+type LeaseFileFormat string
+
+const (
+	LeaseFileISC     LeaseFileFormat = "isc"
+	LeaseFileKea     LeaseFileFormat = "kea"
+	LeaseFileDnsmasq LeaseFileFormat = "dnsmasq"
+)
+
+// ParseLeaseFileFormat requires one of the supported formats; lease files do
+// not identify their server reliably enough to guess.
+func ParseLeaseFileFormat(value string) (LeaseFileFormat, error) {
+	switch format := LeaseFileFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case LeaseFileISC, LeaseFileKea, LeaseFileDnsmasq:
+		return format, nil
+	default:
+		return "", InvalidField("format", "format must be isc, kea or dnsmasq")
+	}
+}
+
+// dhcpLease is one lease read from a lease file. Line is where the lease
+// starts. SeenAt is zero when the file does not say when the client was last
+// heard from, and Active is false for released, expired and declined leases.
+type dhcpLease struct {
+	Line     int
+	IP       netip.Addr
+	MAC      net.HardwareAddr
+	Hostname string
+	SeenAt   time.Time
+	Active   bool
+}
+
+// infiniteLeaseLifetime is the valid lifetime Kea stores for leases that
+// never expire.
+const infiniteLeaseLifetime = 0xffffffff
+
+// parseLeaseFile returns the leases of input in file order, with an error for
+// every lease that could not be read. An error is returned only when the file
+// as a whole cannot be parsed.
+func parseLeaseFile(format LeaseFileFormat, input io.Reader, now time.Time) ([]dhcpLease, []RowError, error) {
+	switch format {
+	case LeaseFileISC:
+		return parseISCLeases(input, now)
+	case LeaseFileKea:
+		return parseKeaLeases(input, now)
+	case LeaseFileDnsmasq:
+		return parseDnsmasqLeases(input, now)
+	default:
+		return nil, nil, InvalidField("format", "format must be isc, kea or dnsmasq")
+	}
+}
+
+// iscStatement is a statement of an ISC dhcpd lease file: the words up to a
+// semicolon, or the words before a braced block and the block's statements.
+type iscStatement struct {
+	Line     int
+	Words    []string
+	Block    []iscStatement
+	HasBlock bool
+}
+
+type iscToken struct {
+	Text   string
+	Line   int
+	Quoted bool
+}
+
+// parseISCLeases reads IPv4 "lease" blocks and the "iaaddr" blocks of IPv6
+// "ia-na" and "ia-ta" declarations. Every other declaration is skipped.
+func parseISCLeases(input io.Reader, now time.Time) ([]dhcpLease, []RowError, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := tokenizeISCLeases(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	statements, rest, err := parseISCStatements(tokens, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rest) > 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected } on line %d", ErrInvalidInput, rest[0].Line)
+	}
+	var leases []dhcpLease
+	var rowErrors []RowError
+	for _, statement := range statements {
+		if !statement.HasBlock || len(statement.Words) == 0 {
+			continue
+		}
+		switch statement.Words[0] {
+		case "lease":
+			lease, err := iscLease(statement, now)
+			if err != nil {
+				rowErrors = append(rowErrors, RowError{Row: statement.Line, Message: err.Error()})
+				continue
+			}
+			leases = append(leases, lease)
+		case "ia-na", "ia-ta":
+			iaLeases, iaErrors := iscIALeases(statement, now)
+			leases = append(leases, iaLeases...)
+			rowErrors = append(rowErrors, iaErrors...)
+		}
+	}
+	return leases, rowErrors, nil
+}
+
+func iscLease(statement iscStatement, now time.Time) (dhcpLease, error) {
+	if len(statement.Words) != 2 {
+		return dhcpLease{}, fmt.Errorf("lease declaration must name one address")
+	}
+	ip, err := netip.ParseAddr(statement.Words[1])
+	if err != nil || !ip.Is4() {
+		return dhcpLease{}, fmt.Errorf("invalid lease address %q", statement.Words[1])
+	}
+	lease := dhcpLease{Line: statement.Line, IP: ip, Active: true}
+	var starts, ends time.Time
+	for _, field := range statement.Block {
+		words := field.Words
+		if field.HasBlock || len(words) == 0 {
+			continue
+		}
+		switch words[0] {
+		case "starts", "cltt", "ends":
+			value, err := parseISCTime(words[1:])
+			if err != nil {
+				return dhcpLease{}, fmt.Errorf("invalid %s time on line %d", words[0], field.Line)
+			}
+			switch words[0] {
+			case "starts":
+				starts = value
+			case "cltt":
+				lease.SeenAt = value
+			case "ends":
+				ends = value
+			}
+		case "binding":
+			if len(words) == 3 && words[1] == "state" {
+				lease.Active = words[2] == "active"
+			}
+		case "hardware":
+			if len(words) == 3 && words[1] == "ethernet" {
+				lease.MAC = parseLeaseMAC(words[2])
+			}
+		case "client-hostname":
+			if len(words) == 2 {
+				lease.Hostname = words[1]
+			}
+		}
+	}
+	if lease.SeenAt.IsZero() {
+		lease.SeenAt = starts
+	}
+	if !ends.IsZero() && !ends.After(now) {
+		lease.Active = false
+	}
+	return lease, nil
+}
+
+// iscIALeases reads the addresses of an IPv6 identity association. The
+// association's cltt applies to all of them; DHCPv6 leases carry no MAC.
+func iscIALeases(statement iscStatement, now time.Time) ([]dhcpLease, []RowError) {
+	var seenAt time.Time
+	for _, field := range statement.Block {
+		if !field.HasBlock && len(field.Words) > 0 && field.Words[0] == "cltt" {
+			value, err := parseISCTime(field.Words[1:])
+			if err != nil {
+				return nil, []RowError{{Row: field.Line, Message: fmt.Sprintf("invalid cltt time on line %d", field.Line)}}
+			}
+			seenAt = value
+		}
+	}
+	var leases []dhcpLease
+	var rowErrors []RowError
+	for _, field := range statement.Block {
+		if !field.HasBlock || len(field.Words) != 2 || field.Words[0] != "iaaddr" {
+			continue
+		}
+		lease, err := iscIAAddress(field, seenAt, now)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: field.Line, Message: err.Error()})
+			continue
+		}
+		leases = append(leases, lease)
+	}
+	return leases, rowErrors
+}
+
+func iscIAAddress(statement iscStatement, seenAt, now time.Time) (dhcpLease, error) {
+	ip, err := netip.ParseAddr(statement.Words[1])
+	if err != nil || !ip.Is6() {
+		return dhcpLease{}, fmt.Errorf("invalid lease address %q", statement.Words[1])
+	}
+	lease := dhcpLease{Line: statement.Line, IP: ip, SeenAt: seenAt, Active: true}
+	for _, field := range statement.Block {
+		words := field.Words
+		if field.HasBlock || len(words) == 0 {
+			continue
+		}
+		switch {
+		case words[0] == "binding" && len(words) == 3 && words[1] == "state":
+			lease.Active = words[2] == "active"
+		case words[0] == "ends":
+			ends, err := parseISCTime(words[1:])
+			if err != nil {
+				return dhcpLease{}, fmt.Errorf("invalid ends time on line %d", field.Line)
+			}
+			if !ends.IsZero() && !ends.After(now) {
+				lease.Active = false
+			}
+		}
+	}
+	return lease, nil
+}
+
+// parseISCTime reads "W YYYY/MM/DD HH:MM:SS" in UTC, "epoch N" or "never";
+// never is returned as the zero time.
+func parseISCTime(words []string) (time.Time, error) {
+	switch {
+	case len(words) == 1 && words[0] == "never":
+		return time.Time{}, nil
+	case len(words) == 2 && words[0] == "epoch":
+		seconds, err := strconv.ParseInt(words[1], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	case len(words) == 3:
+		return time.Parse("2006/01/02 15:04:05", words[1]+" "+words[2])
+	default:
+		return time.Time{}, fmt.Errorf("unrecognised time")
+	}
+}
+
+// parseISCStatements parses tokens up to the end of input, or up to the
+// closing brace of the enclosing block when nested is set. It returns the
+// tokens after that brace.
+func parseISCStatements(tokens []iscToken, nested bool) ([]iscStatement, []iscToken, error) {
+	var statements []iscStatement
+	var current iscStatement
+	for len(tokens) > 0 {
+		token := tokens[0]
+		tokens = tokens[1:]
+		if token.Quoted {
+			if len(current.Words) == 0 {
+				current.Line = token.Line
+			}
+			current.Words = append(current.Words, token.Text)
+			continue
+		}
+		switch token.Text {
+		case ";":
+			if len(current.Words) > 0 {
+				statements = append(statements, current)
+			}
+			current = iscStatement{}
+		case "{":
+			if len(current.Words) == 0 {
+				return nil, nil, fmt.Errorf("%w: unexpected { on line %d", ErrInvalidInput, token.Line)
+			}
+			block, rest, err := parseISCStatements(tokens, true)
+			if err != nil {
+				return nil, nil, err
+			}
+			current.Block, current.HasBlock = block, true
+			statements = append(statements, current)
+			current, tokens = iscStatement{}, rest
+		case "}":
+			if len(current.Words) > 0 {
+				return nil, nil, fmt.Errorf("%w: missing ; before line %d", ErrInvalidInput, token.Line)
+			}
+			if !nested {
+				return statements, append([]iscToken{token}, tokens...), nil
+			}
+			return statements, tokens, nil
+		default:
+			if len(current.Words) == 0 {
+				current.Line = token.Line
+			}
+			current.Words = append(current.Words, token.Text)
+		}
+	}
+	if nested {
+		return nil, nil, fmt.Errorf("%w: missing } at end of file", ErrInvalidInput)
+	}
+	if len(current.Words) > 0 {
+		return nil, nil, fmt.Errorf("%w: missing ; at end of file", ErrInvalidInput)
+	}
+	return statements, nil, nil
+}
+
+// tokenizeISCLeases splits a lease file into words, quoted strings and the
+// punctuation ; { and }. Comments run from # to the end of the line.
+func tokenizeISCLeases(data []byte) ([]iscToken, error) {
+	var tokens []iscToken
+	line := 1
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == ';' || c == '{' || c == '}':
+			tokens = append(tokens, iscToken{Text: string(c), Line: line})
+			i++
+		case c == '"':
+			start := line
+			var text bytes.Buffer
+			i++
+			for ; i < len(data) && data[i] != '"'; i++ {
+				switch {
+				case data[i] == '\n':
+					line++
+					text.WriteByte('\n')
+				case data[i] == '\\' && i+3 < len(data) && isOctalDigits(data[i+1:i+4]):
+					value, _ := strconv.ParseUint(string(data[i+1:i+4]), 8, 8)
+					text.WriteByte(byte(value))
+					i += 3
+				case data[i] == '\\' && i+1 < len(data):
+					i++
+					text.WriteByte(data[i])
+				default:
+					text.WriteByte(data[i])
+				}
+			}
+			if i == len(data) {
+				return nil, fmt.Errorf("%w: unterminated string on line %d", ErrInvalidInput, start)
+			}
+			tokens = append(tokens, iscToken{Text: text.String(), Line: start, Quoted: true})
+			i++
+		default:
+			start := i
+			for i < len(data) && !strings.ContainsRune(" \t\r\n;{}\"#", rune(data[i])) {
+				i++
+			}
+			tokens = append(tokens, iscToken{Text: string(data[start:i]), Line: line})
+		}
+	}
+	return tokens, nil
+}
+
+func isOctalDigits(digits []byte) bool {
+	for _, digit := range digits {
+		if digit < '0' || digit > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseKeaLeases reads a Kea memfile lease CSV. Columns are found by name,
+// so both the lease4 and lease6 layouts are accepted; delegated prefixes are
+// skipped.
+func parseKeaLeases(input io.Reader, now time.Time) ([]dhcpLease, []RowError, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("%w: missing header", ErrInvalidInput)
+		}
+		return nil, nil, fmt.Errorf("%w: invalid csv: %v", ErrInvalidInput, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"address", "valid_lifetime", "expire"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%w: header is missing the %s column", ErrInvalidInput, required)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	var leases []dhcpLease
+	var rowErrors []RowError
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Message: "invalid csv row: " + err.Error()})
+			continue
+		}
+		if leaseType := field(row, "lease_type"); leaseType != "" && leaseType != "0" && leaseType != "1" {
+			continue
+		}
+		ip, err := netip.ParseAddr(field(row, "address"))
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Message: fmt.Sprintf("invalid lease address %q", field(row, "address"))})
+			continue
+		}
+		lifetime, lifetimeErr := strconv.ParseUint(field(row, "valid_lifetime"), 10, 32)
+		expire, expireErr := strconv.ParseInt(field(row, "expire"), 10, 64)
+		if lifetimeErr != nil || expireErr != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Message: "invalid valid_lifetime or expire"})
+			continue
+		}
+		state := field(row, "state")
+		lease := dhcpLease{
+			Line:     line,
+			IP:       ip,
+			MAC:      parseLeaseMAC(field(row, "hwaddr")),
+			Hostname: strings.ReplaceAll(field(row, "hostname"), "&#x2c", ","),
+			Active:   (state == "" || state == "0") && lifetime > 0,
+		}
+		if lifetime != infiniteLeaseLifetime {
+			expires := time.Unix(expire, 0).UTC()
+			lease.SeenAt = expires.Add(-time.Duration(lifetime) * time.Second)
+			if !expires.After(now) {
+				lease.Active = false
+			}
+		}
+		leases = append(leases, lease)
+	}
+	return leases, rowErrors, nil
+}
+
+// parseDnsmasqLeases reads "expiry mac ip hostname client-id" lines. DHCPv6
+// lines carry an IAID in place of the MAC, and the "duid" line names the
+// server. dnsmasq drops leases once they end, so every lease it still lists
+// was in use when the file was written; SeenAt is left to the caller.
+func parseDnsmasqLeases(input io.Reader, now time.Time) ([]dhcpLease, []RowError, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxCSVRowBytes)
+	var leases []dhcpLease
+	var rowErrors []RowError
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+		if len(fields) < 4 {
+			rowErrors = append(rowErrors, RowError{Row: line, Message: "expected expiry, mac, ip and hostname fields"})
+			continue
+		}
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Message: "invalid expiry"})
+			continue
+		}
+		ip, err := netip.ParseAddr(fields[2])
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Message: fmt.Sprintf("invalid lease address %q", fields[2])})
+			continue
+		}
+		lease := dhcpLease{Line: line, IP: ip, Active: expiry == 0 || time.Unix(expiry, 0).After(now)}
+		if ip.Is4() {
+			lease.MAC = parseLeaseMAC(fields[1])
+		}
+		if fields[3] != "*" {
+			lease.Hostname = fields[3]
+		}
+		leases = append(leases, lease)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, nil, fmt.Errorf("%w: line exceeds maximum size of %d bytes", ErrInvalidInput, maxCSVRowBytes)
+		}
+		return nil, nil, err
+	}
+	return leases, rowErrors, nil
+}
+
+// parseLeaseMAC reads a 48-bit MAC address. ISC dhcpd drops leading zeros
+// from each octet, so single-digit octets are accepted. Anything else, such
+// as the hardware-type prefixed addresses of other link layers, yields nil.
+func parseLeaseMAC(value string) net.HardwareAddr {
+	octets := strings.Split(value, ":")
+	if len(octets) != 6 {
+		return nil
+	}
+	mac := make(net.HardwareAddr, 0, 6)
+	for _, octet := range octets {
+		if len(octet) == 0 || len(octet) > 2 {
+			return nil
+		}
+		parsed, err := strconv.ParseUint(octet, 16, 8)
+		if err != nil {
+			return nil
+		}
+		mac = append(mac, byte(parsed))
+	}
+	return mac
+}
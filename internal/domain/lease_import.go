@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// MaxLeaseImportBytes is the maximum number of bytes accepted for one lease
+// file.
+const MaxLeaseImportBytes int64 = 64 << 20
+
+type leaseImportService struct {
+	network NetworkService
+	now     func() time.Time
+}
+
+func NewLeaseImportService(network NetworkService) LeaseImportService {
+	return &leaseImportService{network: network, now: time.Now}
+}
+
+// ImportLeases records the active leases of a DHCP lease file against the
+// subnets that contain them. Lease files are append-only logs, so only the
+// last entry for an address counts. Hostnames fill in addresses that have
+// none, MAC addresses replace the stored one and the last-seen time only
+// moves forward. A dry run reports the same result without writing.
+func (s *leaseImportService) ImportLeases(ctx context.Context, input LeaseImportInput) (ImportResult, error) {
+	now := s.now().UTC()
+	limitedInput := &io.LimitedReader{R: input.File, N: MaxLeaseImportBytes + 1}
+	leases, rowErrors, err := parseLeaseFile(input.Format, limitedInput, now)
+	if limitedInput.N == 0 {
+		return ImportResult{}, fmt.Errorf("%w: lease file exceeds maximum size of %d bytes", ErrInvalidInput, MaxLeaseImportBytes)
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+	subnets, err := s.network.ListSubnets(ctx)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	latest := make(map[netip.Addr]dhcpLease, len(leases))
+	for _, lease := range leases {
+		latest[lease.IP] = lease
+	}
+	leases = leases[:0]
+	for _, lease := range latest {
+		leases = append(leases, lease)
+	}
+	slices.SortFunc(leases, func(a, b dhcpLease) int { return cmp.Compare(a.Line, b.Line) })
+
+	importer := newAddressImporter(s.network, subnets, input.DryRun)
+	result := ImportResult{Processed: len(rowErrors), Failed: len(rowErrors), Errors: rowErrors}
+	for _, lease := range leases {
+		result.Processed++
+		if !lease.Active {
+			continue
+		}
+		seenAt := lease.SeenAt
+		if seenAt.IsZero() {
+			seenAt = now
+		}
+		outcome, err := importer.record(ctx, importedAddress{IP: lease.IP, Hostname: leaseHostname(lease.Hostname), MAC: lease.MAC, SeenAt: &seenAt})
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, RowError{Row: lease.Line, Message: err.Error()})
+			continue
+		}
+		result.count(outcome)
+	}
+	result.Errors = sortRowErrors(result.Errors)
+	return result, nil
+}
+
+// leaseHostname keeps a client-supplied hostname only when it is a valid DNS
+// name; clients send all sorts of names.
+func leaseHostname(hostname string) string {
+	name := normalizeDNSName(hostname)
+	if validateDNSName(name) != nil {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var leaseImportNow = time.Date(2025, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func leaseSummary(leases []dhcpLease) []string {
+	out := make([]string, 0, len(leases))
+	for _, lease := range leases {
+		seen := ""
+		if !lease.SeenAt.IsZero() {
+			seen = lease.SeenAt.Format(time.RFC3339)
+		}
+		out = append(out, fmt.Sprintf("%d %s mac=%s host=%s seen=%s active=%t", lease.Line, lease.IP, lease.MAC, lease.Hostname, seen, lease.Active))
+	}
+	return out
+}
+
+func TestParseLeaseFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		format LeaseFileFormat
+		input  string
+		leases []string
+		errors []RowError
+	}{
+		{
+			name:   "isc",
+			format: LeaseFileISC,
+			input: `# The format of this file is documented in the dhcpd.leases(5) manual page.
+authoring-byte-order little-endian;
+server-duid "\000\001\000\001";
+
+lease 10.0.0.10 {
+  starts 2 2025/10/14 10:00:00;
+  ends 2 2025/10/14 22:00:00;
+  cltt 2 2025/10/14 11:00:00;
+  binding state active;
+  next binding state free;
+  hardware ethernet 52:54:0:12:34:56;
+  uid "\001RT\000\0224V";
+  client-hostname "printer";
+  on expiry { set ddns-fwd-name = "x"; }
+}
+lease 10.0.0.11 {
+  starts epoch 1760428800; # Tue Oct 14 08:00:00 2025
+  ends never;
+  binding state free;
+}
+lease 10.0.0.12 {
+  starts 0 2025/10/12 10:00:00;
+  ends 0 2025/10/12 11:00:00;
+  binding state active;
+}
+lease 10.0.0.300 {
+  binding state active;
+}
+ia-na "\001\000" {
+  cltt 2 2025/10/14 09:00:00;
+  iaaddr 2001:db8::10 {
+    binding state active;
+    preferred-life 375;
+    ends 2 2025/10/14 23:00:00;
+  }
+}
+failover peer "peer" state {
+  my state normal at 2 2025/10/14 10:00:00;
+}
+`,
+			leases: []string{
+				"5 10.0.0.10 mac=52:54:00:12:34:56 host=printer seen=2025-10-14T11:00:00Z active=true",
+				"16 10.0.0.11 mac= host= seen=2025-10-14T08:00:00Z active=false",
+				"21 10.0.0.12 mac= host= seen=2025-10-12T10:00:00Z active=false",
+				"31 2001:db8::10 mac= host= seen=2025-10-14T09:00:00Z active=true",
+			},
+			errors: []RowError{{Row: 26, Message: `invalid lease address "10.0.0.300"`}},
+		},
+		{
+			name:   "kea v4",
+			format: LeaseFileKea,
+			input: `address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context,pool_id
+10.0.0.20,52:54:00:aa:bb:cc,01:52:54:00:aa:bb:cc,3600,1760446800,1,0,0,laptop.example.com.,0,,0
+10.0.0.21,52:54:00:aa:bb:cd,,3600,1760446800,1,0,0,,1,,0
+10.0.0.22,52:54:00:aa:bb:ce,,0,1760443200,1,0,0,a&#x2cb,0,,0
+10.0.0.23,52:54:00:aa:bb:cf,,3600,1760439600,1,0,0,,0,,0
+bogus,52:54:00:aa:bb:cc,,3600,1760446800,1,0,0,,0,,0
+10.0.0.24,52:54:00:aa:bb:d0,,forever,1760446800,1,0,0,,0,,0
+`,
+			leases: []string{
+				"2 10.0.0.20 mac=52:54:00:aa:bb:cc host=laptop.example.com. seen=2025-10-14T12:00:00Z active=true",
+				"3 10.0.0.21 mac=52:54:00:aa:bb:cd host= seen=2025-10-14T12:00:00Z active=false",
+				"4 10.0.0.22 mac=52:54:00:aa:bb:ce host=a,b seen=2025-10-14T12:00:00Z active=false",
+				"5 10.0.0.23 mac=52:54:00:aa:bb:cf host= seen=2025-10-14T10:00:00Z active=false",
+			},
+			errors: []RowError{{Row: 6, Message: `invalid lease address "bogus"`}, {Row: 7, Message: "invalid valid_lifetime or expire"}},
+		},
+		{
+			name:   "kea v6",
+			format: LeaseFileKea,
+			input: `address,duid,valid_lifetime,expire,subnet_id,pref_lifetime,lease_type,iaid,prefix_len,fqdn_fwd,fqdn_rev,hostname,hwaddr,state,user_context,hwtype,hwaddr_source,pool_id
+2001:db8::20,00:01:00:01,3600,1760446800,1,1800,0,1,128,0,0,phone,52:54:00:aa:bb:cc,0,,1,2,0
+2001:db8:1::,00:01:00:01,3600,1760446800,1,1800,2,1,56,0,0,,,0,,1,2,0
+`,
+			leases: []string{"2 2001:db8::20 mac=52:54:00:aa:bb:cc host=phone seen=2025-10-14T12:00:00Z active=true"},
+		},
+		{
+			name:   "dnsmasq",
+			format: LeaseFileDnsmasq,
+			input: `1760444400 52:54:00:12:34:56 10.0.0.30 nas 01:52:54:00:12:34:56
+0 52:54:00:12:34:57 10.0.0.31 * *
+1760443190 52:54:00:12:34:58 10.0.0.32 old *
+1760444400 06-52-54-00-12-34-59 10.0.0.33 ring *
+duid 00:01:00:01:2c:5b:6d:aa:52:54:00:00:00:01
+1760444400 1234 2001:db8::30 phone 00:01:00:01
+1760444400 52:54:00:12:34:5a
+`,
+			leases: []string{
+				"1 10.0.0.30 mac=52:54:00:12:34:56 host=nas seen= active=true",
+				"2 10.0.0.31 mac=52:54:00:12:34:57 host= seen= active=true",
+				"3 10.0.0.32 mac=52:54:00:12:34:58 host=old seen= active=false",
+				"4 10.0.0.33 mac= host=ring seen= active=true",
+				"6 2001:db8::30 mac= host=phone seen= active=true",
+			},
+			errors: []RowError{{Row: 7, Message: "expected expiry, mac, ip and hostname fields"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leases, rowErrors, err := parseLeaseFile(test.format, strings.NewReader(test.input), leaseImportNow)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := leaseSummary(leases); fmt.Sprint(got) != fmt.Sprint(test.leases) {
+				t.Fatalf("unexpected leases:\n%s", strings.Join(got, "\n"))
+			}
+			if fmt.Sprint(rowErrors) != fmt.Sprint(test.errors) {
+				t.Fatalf("unexpected row errors: %+v", rowErrors)
+			}
+		})
+	}
+}
+
+func TestParseLeaseFilesRejectsMalformedFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		format LeaseFileFormat
+		input  string
+		detail string
+	}{
+		{name: "isc unclosed block", format: LeaseFileISC, input: "lease 10.0.0.1 {\n  binding state active;\n", detail: "missing } at end of file"},
+		{name: "isc stray brace", format: LeaseFileISC, input: "}\n", detail: "unexpected } on line 1"},
+		{name: "isc unterminated string", format: LeaseFileISC, input: "lease 10.0.0.1 {\n  client-hostname \"x;\n}\n", detail: "unterminated string on line 2"},
+		{name: "kea missing column", format: LeaseFileKea, input: "address,hwaddr\n", detail: "header is missing the valid_lifetime column"},
+		{name: "kea empty", format: LeaseFileKea, input: "", detail: "missing header"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := parseLeaseFile(test.format, strings.NewReader(test.input), leaseImportNow)
+			if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), test.detail) {
+				t.Fatalf("expected %q, got %v", test.detail, err)
+			}
+		})
+	}
+}
+
+func TestParseLeaseFileFormat(t *testing.T) {
+	if format, err := ParseLeaseFileFormat(" Kea "); err != nil || format != LeaseFileKea {
+		t.Fatalf("unexpected format %q: %v", format, err)
+	}
+	if _, err := ParseLeaseFileFormat(""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input for a missing format, got %v", err)
+	}
+}
+
+func newLeaseImportNetwork(t *testing.T) *importNetworkStub {
+	lastSeen := leaseImportNow.Add(-time.Minute)
+	siteA, siteB := uuid.New(), uuid.New()
+	return &importNetworkStub{
+		subnets: []Subnet{
+			{ID: 1, CIDR: netip.MustParsePrefix("10.0.0.0/16"), SiteID: siteA},
+			{ID: 2, CIDR: netip.MustParsePrefix("10.0.1.0/24"), SiteID: siteA},
+			{ID: 3, CIDR: netip.MustParsePrefix("10.2.0.0/24"), SiteID: siteA},
+			{ID: 4, CIDR: netip.MustParsePrefix("10.2.0.0/24"), SiteID: siteB},
+		},
+		ips: map[int64][]IPAddress{
+			2: {
+				{ID: "existing", IP: netip.MustParseAddr("10.0.1.10"), Hostname: "printer", SubnetID: 2, LastSeenAt: &lastSeen},
+				{ID: "reserved", IP: netip.MustParseAddr("10.0.1.11"), MACAddress: mustMAC(t, "52:54:00:00:00:11"), SubnetID: 2},
+			},
+		},
+	}
+}
+
+func TestLeaseImportCreatesAndUpdatesAddresses(t *testing.T) {
+	leases := strings.Join([]string{
+		"1760446800 52:54:00:00:00:01 10.0.1.10 Copier *",
+		"1760446800 52:54:00:00:00:02 10.0.1.20 laptop *",
+		"1760446800 52:54:00:00:00:03 10.0.1.20 laptop-2 *",
+		"1760446800 52:54:00:00:00:04 10.0.5.5 not_a_dns_name *",
+		"1760446800 52:54:00:00:00:11 10.0.1.30 clash *",
+		"1760446800 52:54:00:00:00:05 10.9.0.1 nowhere *",
+		"1760446800 52:54:00:00:00:06 10.2.0.5 twice *",
+		"1760446800 52:54:00:00:00:07 10.0.1.255 broadcast *",
+		"1760430000 52:54:00:00:00:08 10.0.1.40 expired *",
+		"garbage",
+	}, "\n")
+	for _, dryRun := range []bool{true, false} {
+		t.Run(fmt.Sprintf("dry run %t", dryRun), func(t *testing.T) {
+			network := newLeaseImportNetwork(t)
+			service := &leaseImportService{network: network, now: func() time.Time { return leaseImportNow }}
+
+			result, err := service.ImportLeases(context.Background(), LeaseImportInput{Format: LeaseFileDnsmasq, File: strings.NewReader(leases), DryRun: dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Processed != 9 || result.Created != 2 || result.Updated != 1 || result.Failed != 5 {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			wantErrors := []RowError{
+				{Row: 5, Message: "mac address 52:54:00:00:00:11 is already reserved for 10.0.1.11"},
+				{Row: 6, Message: "no subnet contains 10.9.0.1"},
+				{Row: 7, Message: "10.2.0.5 matches more than one 10.2.0.0/24 subnet"},
+				{Row: 8, Message: "network or broadcast ip"},
+				{Row: 10, Message: "expected expiry, mac, ip and hostname fields"},
+			}
+			if fmt.Sprint(result.Errors) != fmt.Sprint(wantErrors) {
+				t.Fatalf("unexpected errors: %+v", result.Errors)
+			}
+			if dryRun {
+				if network.createdIPs != 0 || network.updatedIPs != 0 || len(network.ips[2]) != 2 {
+					t.Fatalf("dry run wrote: created=%d updated=%d", network.createdIPs, network.updatedIPs)
+				}
+				return
+			}
+			existing := network.ips[2][0]
+			if existing.Hostname != "printer" || existing.MACAddress.String() != "52:54:00:00:00:01" || !existing.LastSeenAt.Equal(leaseImportNow) {
+				t.Fatalf("unexpected update: %+v", existing)
+			}
+			laptop := network.ips[2][2]
+			if laptop.IP.String() != "10.0.1.20" || laptop.Hostname != "laptop-2" || laptop.MACAddress.String() != "52:54:00:00:00:03" || !laptop.LastSeenAt.Equal(leaseImportNow) {
+				t.Fatalf("unexpected created address: %+v", laptop)
+			}
+			if created := network.ips[1]; len(created) != 1 || created[0].IP.String() != "10.0.5.5" || created[0].Hostname != "" {
+				t.Fatalf("expected the /16 to receive a nameless address, got %+v", created)
+			}
+		})
+	}
+}
+
+func TestLeaseImportKeepsNewerLastSeen(t *testing.T) {
+	network := newLeaseImportNetwork(t)
+	later := leaseImportNow.Add(time.Hour)
+	network.ips[2][0].LastSeenAt = &later
+	network.ips[2][0].MACAddress = mustMAC(t, "52:54:00:00:00:01")
+	service := &leaseImportService{network: network, now: func() time.Time { return leaseImportNow }}
+
+	result, err := service.ImportLeases(context.Background(), LeaseImportInput{Format: LeaseFileDnsmasq, File: strings.NewReader("0 52:54:00:00:00:01 10.0.1.10 printer *\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Processed != 1 || result.Created != 0 || result.Updated != 0 || network.updatedIPs != 0 {
+		t.Fatalf("expected an unchanged address, got %+v", result)
+	}
+}
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"encoding/json"
+	"net"
 	"net/netip"
 	"time"
 
@@ -16,8 +18,19 @@ type Subnet struct {
 	UsedIPCount  int64
 	TotalIPCount int64
 	Description  string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// Gateway, DNSServers and DHCPPools are rendered into DHCP server
+	// configuration; the zero Gateway means none.
+	Gateway    netip.Addr
+	DNSServers []netip.Addr
+	DHCPPools  []DHCPPool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// DHCPPool is an inclusive range of addresses a DHCP server hands out.
+type DHCPPool struct {
+	Start netip.Addr
+	End   netip.Addr
 }
 
 type ReportingCadence string
@@ -56,13 +69,24 @@ type SnapshotCycleResult struct {
 }
 
 type IPAddress struct {
-	ID                 IPAddressID
-	IP                 netip.Addr
-	Hostname           string
-	SubnetID           int64
+	ID       IPAddressID
+	IP       netip.Addr
+	Hostname string
+	SubnetID int64
+	// MACAddress, when set, makes the address a DHCP host reservation.
+	MACAddress net.HardwareAddr
+	// LastSeenAt is when a DHCP lease last showed the address in use.
+	LastSeenAt         *time.Time
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 	KubernetesServices []KubernetesServiceEnrichment
+	KubernetesObjects  []KubernetesObjectEnrichment
+	// KubernetesAllocation is set when the LoadBalancer allocator gave the
+	// address to a Service.
+	KubernetesAllocation *KubernetesLoadBalancerAllocation
+	// KubernetesRegistration is set when discovery created the record for
+	// an address a Service reported.
+	KubernetesRegistration *KubernetesAddressRegistration
 }
 
 type KubernetesSource struct {
@@ -154,6 +178,162 @@ type KubernetesServiceSnapshot struct {
 	Hostnames       []KubernetesServiceHostname
 }
 
+// KubernetesServiceChanges is an incremental update to a source's Services:
+// the Services added or changed since the last publication and the UIDs of
+// the deleted ones. Services it does not mention are left as they are.
+type KubernetesServiceChanges struct {
+	Upserted    []KubernetesServiceSnapshot
+	DeletedUIDs []string
+}
+
+// Kinds a source can discover besides Services, which are always
+// discovered.
+const (
+	KubernetesObjectNode          = "node"
+	KubernetesObjectPod           = "pod"
+	KubernetesObjectEndpointSlice = "endpoint_slice"
+	KubernetesObjectIngress       = "ingress"
+	KubernetesObjectGateway       = "gateway"
+	KubernetesObjectHTTPRoute     = "http_route"
+)
+
+// KubernetesObjectKinds lists every object kind in the order sources
+// store them.
+var KubernetesObjectKinds = []string{
+	KubernetesObjectNode, KubernetesObjectPod, KubernetesObjectEndpointSlice,
+	KubernetesObjectIngress, KubernetesObjectGateway, KubernetesObjectHTTPRoute,
+}
+
+// KubernetesObjectAddress is a Node InternalIP or ExternalIP, a Pod IP, an
+// EndpointSlice endpoint, an Ingress or Gateway status address, or the
+// address of an HTTPRoute's parent Gateway. NodeName is the Node the address
+// belongs to or runs on, when known.
+type KubernetesObjectAddress struct {
+	Kind     string
+	Address  netip.Addr
+	NodeName string
+}
+
+type KubernetesObjectSnapshot struct {
+	UID             string
+	Kind            string
+	Namespace       string
+	Name            string
+	ResourceVersion string
+	// HostNetwork is set for Pods sharing their Node's network namespace,
+	// whose Pod IPs are Node addresses.
+	HostNetwork bool
+	Addresses   []KubernetesObjectAddress
+	Hostnames   []KubernetesObjectHostname
+}
+
+// KubernetesObjectHostname is a hostname an Ingress, Gateway or HTTPRoute
+// serves (kind host), or a load-balancer hostname from its status (kind
+// load_balancer).
+type KubernetesObjectHostname struct {
+	Kind     string
+	Hostname string
+}
+
+type KubernetesObjectReconcileResult struct {
+	Objects   int
+	Matched   int
+	Unmatched int
+	Ambiguous int
+}
+
+// KubernetesObjectEnrichment is a discovered object whose address matched an
+// IP address record.
+type KubernetesObjectEnrichment struct {
+	Source      KubernetesSource
+	Kind        string
+	UID         string
+	Namespace   string
+	Name        string
+	HostNetwork bool
+	AddressKind string
+	NodeName    string
+	Hostnames   []KubernetesObjectHostname
+	ObservedAt  time.Time
+}
+
+type KubernetesObjectAddressObservation struct {
+	IP                 netip.Addr
+	Kind               string
+	NodeName           string
+	MatchStatus        KubernetesMatchStatus
+	MatchCount         int
+	MatchedIPAddressID *IPAddressID
+	MatchedSubnetID    *int64
+	// LoadBalancerServices are the active Services of the same source,
+	// as namespace/name, with this address as a load-balancer IP.
+	LoadBalancerServices []string
+}
+
+// KubernetesObjectObservation is a discovered object with the observed
+// addresses that fall inside one subnet.
+type KubernetesObjectObservation struct {
+	Source      KubernetesSource
+	Kind        string
+	UID         string
+	Namespace   string
+	Name        string
+	HostNetwork bool
+	Addresses   []KubernetesObjectAddressObservation
+	Hostnames   []KubernetesObjectHostname
+	ObservedAt  time.Time
+}
+
+// KubernetesLoadBalancerAllocation is an address the LoadBalancer allocator
+// gave a Service. Controller identifies the allocator's cluster, and the
+// Service owns the address until the allocation is released.
+type KubernetesLoadBalancerAllocation struct {
+	Controller  string
+	ServiceUID  string
+	Namespace   string
+	Name        string
+	IPAddressID IPAddressID
+	IP          netip.Addr
+	SubnetID    int64
+	AllocatedAt time.Time
+}
+
+// KubernetesAddressClaim is an address the Cluster API IPAM provider
+// recorded for an IPAddressClaim, which owns it until it is released.
+type KubernetesAddressClaim struct {
+	ClaimUID    string
+	Namespace   string
+	Name        string
+	IPAddressID IPAddressID
+	IP          netip.Addr
+	SubnetID    int64
+	ClaimedAt   time.Time
+}
+
+// KubernetesAddressRegistration is an address record a source's
+// auto-registration policy created for a Service. Discovery deletes the
+// record once the Service has been gone past the source's stale retention.
+type KubernetesAddressRegistration struct {
+	Source       KubernetesSource
+	ServiceUID   string
+	Namespace    string
+	Name         string
+	RegisteredAt time.Time
+}
+
+// Auto-registration policies for addresses inside a subnet of the source's
+// site that have no IP address record. register records every such
+// address; register_lb_only only LoadBalancer addresses.
+const (
+	KubernetesAutoRegisterOff              = "off"
+	KubernetesAutoRegisterAll              = "register"
+	KubernetesAutoRegisterLoadBalancerOnly = "register_lb_only"
+)
+
+var KubernetesAutoRegisterPolicies = []string{
+	KubernetesAutoRegisterOff, KubernetesAutoRegisterAll, KubernetesAutoRegisterLoadBalancerOnly,
+}
+
 type KubernetesSourceConfig struct {
 	Key            string
 	Name           string
@@ -161,6 +341,28 @@ type KubernetesSourceConfig struct {
 	ClusterDomain  string
 	Namespaces     []string
 	StaleRetention time.Duration
+	// ObjectKinds lists the KubernetesObject* kinds discovered besides
+	// Services. PodSelector, a label selector, limits the Pods.
+	ObjectKinds []string
+	PodSelector string
+	// AutoRegister is one of the KubernetesAutoRegister* policies.
+	AutoRegister string
+	// Managed sources were created through the API, which owns their
+	// settings; reconciling one does not write them back.
+	Managed bool
+}
+
+// RegistersAddress reports whether the source's auto-registration policy
+// records an unmatched Service address of kind.
+func (c KubernetesSourceConfig) RegistersAddress(kind string) bool {
+	switch c.AutoRegister {
+	case KubernetesAutoRegisterAll:
+		return true
+	case KubernetesAutoRegisterLoadBalancerOnly:
+		return kind == "load_balancer"
+	default:
+		return false
+	}
 }
 
 type KubernetesReconcileResult struct {
@@ -171,11 +373,67 @@ type KubernetesReconcileResult struct {
 	NoUsableIP int
 }
 
+// KubernetesCycleRequest is the payload of a kubernetes.reconcile_requested
+// notification.
+type KubernetesCycleRequest struct {
+	RequestID string `json:"request_id"`
+}
+
+// KubernetesCycleOutcome is the payload of the reconciled or failed
+// notification a requested cycle ends with. RequestID echoes the request,
+// and Error is set when the cycle failed.
+type KubernetesCycleOutcome struct {
+	RequestID string                    `json:"request_id"`
+	Result    KubernetesReconcileResult `json:"result"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+// KubernetesDiscoveryRun is one publication of a source's Services, either a
+// complete snapshot or an incremental update, or one failed attempt, which
+// has an Error and no counts.
+type KubernetesDiscoveryRun struct {
+	ID         int64
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Result     KubernetesReconcileResult
+	Error      string
+	// Changes counts the run's KubernetesDiscoveryEvents.
+	Changes int
+}
+
+// Changes a discovery run can make to a Service.
+const (
+	KubernetesServiceAppeared       = "appeared"
+	KubernetesServiceAddressChanged = "address_changed"
+	KubernetesServiceStale          = "stale"
+	KubernetesServicePurged         = "purged"
+)
+
+// KubernetesDiscoveryEvent records one change a run made to a Service. A
+// Service appears when it is first seen or seen again after going stale.
+// PreviousAddresses is empty when the Service appeared, and Addresses when
+// it went stale or was purged; a purge keeps no addresses, since the stale
+// event before it recorded them.
+type KubernetesDiscoveryEvent struct {
+	ID                int64
+	RunID             int64
+	ServiceUID        string
+	Namespace         string
+	Name              string
+	Change            string
+	PreviousAddresses []netip.Addr
+	Addresses         []netip.Addr
+	OccurredAt        time.Time
+}
+
 type KubernetesSourceStatus struct {
 	Source        KubernetesSource
 	SiteID        uuid.UUID
 	ClusterDomain string
 	Namespaces    []string
+	ObjectKinds   []string
+	PodSelector   string
+	AutoRegister  string
 	State         string
 	LastAttemptAt *time.Time
 	LastSuccessAt *time.Time
@@ -185,6 +443,40 @@ type KubernetesSourceStatus struct {
 	Unmatched     int
 	Ambiguous     int
 	NoUsableIP    int
+	Objects       int
+	// ObjectError is why the latest object listing failed. The Service
+	// status above does not depend on it.
+	ObjectError string
+	// Managed is set for sources created through the API. Only they report
+	// Settings; other sources are configured by their deployment.
+	Managed  bool
+	Settings *KubernetesSourceSettings
+}
+
+const (
+	KubernetesAuthInCluster  = "in_cluster"
+	KubernetesAuthKubeconfig = "kubeconfig"
+)
+
+// KubernetesSourceSettings describe how a managed source connects and how
+// often it is reconciled. The kubeconfig itself is never reported.
+type KubernetesSourceSettings struct {
+	AuthMode          string
+	KubeconfigContext string
+	ReconcileInterval time.Duration
+	RequestTimeout    time.Duration
+	StaleRetention    time.Duration
+}
+
+// ManagedKubernetesSource is a source created through the API, with its
+// kubeconfig decrypted for a discovery runner.
+type ManagedKubernetesSource struct {
+	Config            KubernetesSourceConfig
+	AuthMode          string
+	Kubeconfig        []byte
+	KubeconfigContext string
+	ReconcileInterval time.Duration
+	RequestTimeout    time.Duration
 }
 
 type Site struct {
@@ -220,3 +512,248 @@ type SiteStatistics struct {
 	TotalIPCount int64
 	FreeIPCount  int64
 }
+
+const (
+	EventSubnetCreated = "subnet.created"
+	EventSubnetUpdated = "subnet.updated"
+	EventSubnetDeleted = "subnet.deleted"
+	EventIPCreated     = "ip.created"
+	EventIPUpdated     = "ip.updated"
+	EventIPDeleted     = "ip.deleted"
+	EventSiteCreated   = "site.created"
+	EventSiteUpdated   = "site.updated"
+	EventSiteDeleted   = "site.deleted"
+
+	EventKubernetesReconciled      = "kubernetes.reconciled"
+	EventKubernetesReconcileFailed = "kubernetes.reconcile_failed"
+	EventReportingSnapshotCaptured = "reporting.snapshot_captured"
+	EventDNSZoneCreated            = "dns_zone.created"
+	EventDNSZoneDeleted            = "dns_zone.deleted"
+	EventKubernetesSourceCreated   = "kubernetes_source.created"
+	EventKubernetesSourceUpdated   = "kubernetes_source.updated"
+	EventKubernetesSourceDeleted   = "kubernetes_source.deleted"
+
+	// EventKubernetesReconcileRequested asks the replica running a source's
+	// discovery to start a cycle now.
+	EventKubernetesReconcileRequested = "kubernetes.reconcile_requested"
+)
+
+const (
+	ObjectTypeSubnet     = "subnet"
+	ObjectTypeIP         = "ip"
+	ObjectTypeSite       = "site"
+	ObjectTypeKubernetes = "kubernetes"
+	ObjectTypeReporting  = "reporting"
+	ObjectTypeDNSZone    = "dns_zone"
+
+	ObjectTypeKubernetesSource = "kubernetes_source"
+)
+
+// ChangeEvent is a row from the transactional outbox. Payload holds the
+// mutated row as JSON, using the database column names. Live notifications
+// carry only identifiers, so their Payload is empty, except for the
+// KubernetesCycleRequest and KubernetesCycleOutcome of on-demand Kubernetes
+// cycles.
+type ChangeEvent struct {
+	ID         int64
+	Type       string
+	ObjectType string
+	ObjectID   string
+	SiteID     *uuid.UUID
+	SubnetID   *int64
+	Payload    json.RawMessage
+	OccurredAt time.Time
+}
+
+type WebhookSubscription struct {
+	ID          uuid.UUID
+	URL         string
+	Description string
+	EventTypes  []string
+	Secret      string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID uuid.UUID
+	URL            string
+	Secret         string
+	Attempts       int32
+	Event          ChangeEvent
+}
+
+type WebhookDeliveryFailure struct {
+	ID            int64
+	StatusCode    *int32
+	Error         string
+	NextAttemptAt time.Time
+	Dead          bool
+}
+
+type WebhookDeadLetter struct {
+	ID             int64
+	SubscriptionID uuid.UUID
+	URL            string
+	EventID        int64
+	EventType      string
+	ObjectType     string
+	ObjectID       string
+	Attempts       int32
+	LastStatusCode *int32
+	LastError      string
+	LastAttemptAt  *time.Time
+	CreatedAt      time.Time
+}
+
+// IdempotencyRecord is a stored Idempotency-Key. StatusCode is zero while
+// the original request is still running.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+	Response    IdempotentResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// RateLimit allows Requests per fixed Window. Zero Requests means unlimited.
+type RateLimit struct {
+	Requests int64
+	Window   time.Duration
+}
+
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	RetryAfter time.Duration
+}
+
+type DNSZoneKind string
+
+const (
+	DNSZoneForward DNSZoneKind = "forward"
+	DNSZoneReverse DNSZoneKind = "reverse"
+)
+
+// DNSZone is a forward zone attached to a site or a subnet, or a reverse
+// zone computed from subnet prefixes. Reverse zones are not stored: they
+// have no ID and Network is the address range they cover.
+type DNSZone struct {
+	ID        uuid.UUID
+	Name      string
+	Kind      DNSZoneKind
+	SiteID    *uuid.UUID
+	SubnetID  *int64
+	Network   netip.Prefix
+	TTL       int32
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// DNSHostRecord is a named address and the forward zone its subnet belongs
+// to. Zone and TTL are empty when neither the subnet nor its site has a zone.
+type DNSHostRecord struct {
+	IP       netip.Addr
+	Hostname string
+	SubnetID int64
+	CIDR     netip.Prefix
+	Zone     string
+	TTL      int32
+}
+
+type DNSRecordType string
+
+const (
+	DNSRecordA    DNSRecordType = "A"
+	DNSRecordAAAA DNSRecordType = "AAAA"
+	DNSRecordPTR  DNSRecordType = "PTR"
+)
+
+// DNSRecord is one resource record. Name and, for PTR records, Data are
+// fully qualified names without the trailing dot.
+type DNSRecord struct {
+	Name string
+	Type DNSRecordType
+	Data string
+}
+
+// DNSSOA holds the start of authority values of a zone.
+type DNSSOA struct {
+	PrimaryNS  string
+	Hostmaster string
+	Serial     uint32
+	Refresh    uint32
+	Retry      uint32
+	Expire     uint32
+	Minimum    uint32
+}
+
+// DNSSkippedHost is an address left out of a zone because its hostname is
+// not a valid DNS name.
+type DNSSkippedHost struct {
+	IP       netip.Addr
+	Hostname string
+	Reason   string
+}
+
+// DNSZoneData is the full content of a zone, ready to export or serve.
+type DNSZoneData struct {
+	Zone    DNSZone
+	SOA     DNSSOA
+	Records []DNSRecord
+	Skipped []DNSSkippedHost
+}
+
+// DNSRegistration is what the dynamic DNS server holds for an address: an A
+// or AAAA record at Name in Zone and a PTR record in ReverseZone.
+type DNSRegistration struct {
+	IP          netip.Addr
+	Name        string
+	Zone        string
+	ReverseZone string
+	TTL         int32
+	UpdatedAt   time.Time
+}
+
+// DNSUpdateTask is a leased entry of the dynamic DNS update queue. A newer
+// Generation means the address changed again after this one was queued.
+type DNSUpdateTask struct {
+	IP         netip.Addr
+	Generation int64
+	Attempts   int32
+}
+
+type DNSUpdateFailure struct {
+	IP            netip.Addr
+	Generation    int64
+	NextAttemptAt time.Time
+	Error         string
+}
+
+// DNSChange replaces an address's registered records. Remove is what the
+// server holds now and Add what it should hold; either may be nil.
+type DNSChange struct {
+	IP     netip.Addr
+	Remove *DNSRegistration
+	Add    *DNSRegistration
+}
+
+// DNSUpdateResult is the outcome of one queued update. Err is nil when the
+// server accepted the change or no change was needed.
+type DNSUpdateResult struct {
+	IP       netip.Addr
+	Attempts int32
+	Err      error
+}
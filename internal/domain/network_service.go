@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/netip"
+	"strings"
 
 	"github.com/google/uuid"
 	"go4.org/netipx"
@@ -15,10 +17,14 @@ type networkService struct {
 	ips       IPRepository
 	sites     SiteRepository
 	discovery KubernetesDiscoveryRepository
+	dnsZones  DNSZoneRepository
 }
 
-func NewNetworkServiceWithDiscovery(subnets SubnetRepository, ips IPRepository, sites SiteRepository, discovery KubernetesDiscoveryRepository) NetworkService {
-	return &networkService{subnets: subnets, ips: ips, sites: sites, discovery: discovery}
+// NewNetworkServiceWithDiscovery enriches addresses with Kubernetes
+// observations. When dnsZones is set, hostnames in subnets that belong to a
+// DNS zone must be valid DNS names.
+func NewNetworkServiceWithDiscovery(subnets SubnetRepository, ips IPRepository, sites SiteRepository, discovery KubernetesDiscoveryRepository, dnsZones DNSZoneRepository) NetworkService {
+	return &networkService{subnets: subnets, ips: ips, sites: sites, discovery: discovery, dnsZones: dnsZones}
 }
 
 func NewNetworkService(subnets SubnetRepository, ips IPRepository, sites ...SiteRepository) NetworkService {
@@ -39,14 +45,14 @@ func (s *networkService) ListSubnets(ctx context.Context) ([]Subnet, error) {
 
 func (s *networkService) CreateSubnet(ctx context.Context, input CreateSubnetInput) (Subnet, error) {
 	if input.SiteID == nil || *input.SiteID == uuid.Nil {
-		return Subnet{}, fmt.Errorf("%w: site is required", ErrInvalidInput)
+		return Subnet{}, InvalidField("site_id", "site is required")
 	}
 	if err := s.validateSite(ctx, *input.SiteID); err != nil {
 		return Subnet{}, err
 	}
 	cidr, err := netip.ParsePrefix(input.CIDR)
 	if err != nil {
-		return Subnet{}, fmt.Errorf("%w: invalid cidr", ErrInvalidInput)
+		return Subnet{}, InvalidField("cidr", "invalid cidr")
 	}
 	subnet, err := s.subnets.Create(ctx, CreateSubnetRecord{
 		CIDR:        cidr,
@@ -58,7 +64,7 @@ func (s *networkService) CreateSubnet(ctx context.Context, input CreateSubnetInp
 
 func (s *networkService) AssignSubnetSite(ctx context.Context, input AssignSubnetSiteInput) (Subnet, error) {
 	if input.SiteID == uuid.Nil {
-		return Subnet{}, fmt.Errorf("%w: site is required", ErrInvalidInput)
+		return Subnet{}, InvalidField("site_id", "site is required")
 	}
 	if err := s.validateSite(ctx, input.SiteID); err != nil {
 		return Subnet{}, err
@@ -77,14 +83,23 @@ func (s *networkService) GetSubnet(ctx context.Context, id int64) (Subnet, error
 
 func (s *networkService) UpdateSubnet(ctx context.Context, input UpdateSubnetInput) (Subnet, error) {
 	if input.SiteID == nil || *input.SiteID == uuid.Nil {
-		return Subnet{}, fmt.Errorf("%w: site is required", ErrInvalidInput)
+		return Subnet{}, InvalidField("site_id", "site is required")
 	}
 	if err := s.validateSite(ctx, *input.SiteID); err != nil {
 		return Subnet{}, err
 	}
 	cidr, err := netip.ParsePrefix(input.CIDR)
 	if err != nil {
-		return Subnet{}, fmt.Errorf("%w: invalid cidr", ErrInvalidInput)
+		return Subnet{}, InvalidField("cidr", "invalid cidr")
+	}
+	current, err := s.subnets.FindByID(ctx, input.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Subnet{}, err
+	}
+	if err == nil {
+		if err := validateDHCPSettingsInCIDR(cidr, current.Gateway, current.DHCPPools); err != nil {
+			return Subnet{}, InvalidField("cidr", "cidr no longer contains the subnet's "+err.Error())
+		}
 	}
 	subnet, err := s.subnets.Update(ctx, UpdateSubnetRecord{
 		ID:          input.ID,
@@ -151,8 +166,13 @@ func (s *networkService) ListIPs(ctx context.Context, subnetID int64) ([]IPAddre
 	if err != nil {
 		return nil, err
 	}
+	objects, err := s.discovery.ListObjectsBySubnetID(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
 	for i := range ips {
 		ips[i].KubernetesServices = enrichments[ips[i].ID]
+		ips[i].KubernetesObjects = objects[ips[i].ID]
 	}
 	return ips, nil
 }
@@ -168,16 +188,25 @@ func (s *networkService) CreateIP(ctx context.Context, subnetID int64, input Cre
 
 	ip, err := netip.ParseAddr(input.IP)
 	if err != nil {
-		return IPAddress{}, fmt.Errorf("%w: invalid ip", ErrInvalidInput)
+		return IPAddress{}, InvalidField("ip", "invalid ip")
 	}
 
 	if err = validateIPInSubnet(subnet.CIDR, ip); err != nil {
-		return IPAddress{}, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		return IPAddress{}, InvalidField("ip", err.Error())
+	}
+	if err = validateZoneHostname(ctx, s.dnsZones, subnetID, input.Hostname); err != nil {
+		return IPAddress{}, err
+	}
+	mac, err := parseMACAddress(input.MACAddress)
+	if err != nil {
+		return IPAddress{}, err
 	}
 
 	return s.ips.Create(ctx, CreateIPRecord{
-		IP:       ip,
-		Hostname: input.Hostname,
+		IP:         ip,
+		Hostname:   input.Hostname,
+		MACAddress: mac,
+		LastSeenAt: input.LastSeenAt,
 	}, subnetID)
 }
 
@@ -188,13 +217,40 @@ func (s *networkService) UpdateIPHostname(ctx context.Context, subnetID int64, i
 		}
 		return IPAddress{}, err
 	}
-	if _, err := s.ips.FindByIDAndSubnet(ctx, id, subnetID); err != nil {
+	current, err := s.ips.FindByIDAndSubnet(ctx, id, subnetID)
+	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return IPAddress{}, fmt.Errorf("%w: %w", ErrNotFound, ErrIPNotFound)
 		}
 		return IPAddress{}, err
 	}
-	return s.ips.UpdateHostname(ctx, id, input)
+	if err := validateZoneHostname(ctx, s.dnsZones, subnetID, input.Hostname); err != nil {
+		return IPAddress{}, err
+	}
+	mac := current.MACAddress
+	if input.MACAddress != nil {
+		if mac, err = parseMACAddress(*input.MACAddress); err != nil {
+			return IPAddress{}, err
+		}
+	}
+	lastSeenAt := current.LastSeenAt
+	if input.LastSeenAt != nil {
+		lastSeenAt = input.LastSeenAt
+	}
+	return s.ips.Update(ctx, id, UpdateIPRecord{Hostname: input.Hostname, MACAddress: mac, LastSeenAt: lastSeenAt})
+}
+
+// parseMACAddress accepts a 48-bit MAC address; an empty value means none.
+func parseMACAddress(value string) (net.HardwareAddr, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	mac, err := net.ParseMAC(value)
+	if err != nil || len(mac) != 6 {
+		return nil, InvalidField("mac_address", "invalid mac address")
+	}
+	return mac, nil
 }
 
 func (s *networkService) DeleteIP(ctx context.Context, subnetID int64, id IPAddressID) error {
@@ -208,6 +264,11 @@ func (s *networkService) DeleteIP(ctx context.Context, subnetID int64, id IPAddr
 	return nil
 }
 
+// UsableAddress reports whether ip can be recorded in a subnet with prefix.
+func UsableAddress(prefix netip.Prefix, ip netip.Addr) bool {
+	return validateIPInSubnet(prefix.Masked(), ip) == nil
+}
+
 func validateIPInSubnet(prefix netip.Prefix, ip netip.Addr) error {
 	if !prefix.Contains(ip) {
 		return fmt.Errorf("ip not in subnet")
@@ -15,6 +15,7 @@ type stubSubnetRepository struct {
 	createFn     func(context.Context, CreateSubnetRecord) (Subnet, error)
 	updateFn     func(context.Context, UpdateSubnetRecord) (Subnet, error)
 	assignSiteFn func(context.Context, int64, uuid.UUID) (Subnet, error)
+	dhcpFn       func(context.Context, UpdateSubnetDHCPRecord) (Subnet, error)
 	deleteFn     func(context.Context, int64) (bool, error)
 }
 
@@ -53,6 +54,13 @@ func (s stubSubnetRepository) AssignSite(ctx context.Context, id int64, siteID u
 	return s.assignSiteFn(ctx, id, siteID)
 }
 
+func (s stubSubnetRepository) UpdateDHCP(ctx context.Context, input UpdateSubnetDHCPRecord) (Subnet, error) {
+	if s.dhcpFn == nil {
+		return Subnet{}, nil
+	}
+	return s.dhcpFn(ctx, input)
+}
+
 func (s stubSubnetRepository) Delete(ctx context.Context, id int64) (bool, error) {
 	if s.deleteFn == nil {
 		return false, nil
@@ -64,7 +72,7 @@ type stubIPRepository struct {
 	listFn   func(context.Context, int64) ([]IPAddress, error)
 	findFn   func(context.Context, IPAddressID, int64) (IPAddress, error)
 	createFn func(context.Context, CreateIPRecord, int64) (IPAddress, error)
-	updateFn func(context.Context, IPAddressID, UpdateIPInput) (IPAddress, error)
+	updateFn func(context.Context, IPAddressID, UpdateIPRecord) (IPAddress, error)
 	deleteFn func(context.Context, IPAddressID, int64) (bool, error)
 }
 
@@ -89,7 +97,7 @@ func (s stubIPRepository) Create(ctx context.Context, input CreateIPRecord, subn
 	return s.createFn(ctx, input, subnetID)
 }
 
-func (s stubIPRepository) UpdateHostname(ctx context.Context, id IPAddressID, input UpdateIPInput) (IPAddress, error) {
+func (s stubIPRepository) Update(ctx context.Context, id IPAddressID, input UpdateIPRecord) (IPAddress, error) {
 	if s.updateFn == nil {
 		return IPAddress{}, nil
 	}
@@ -388,7 +396,7 @@ func TestUpdateIPHostnameReturnsRepositoryError(t *testing.T) {
 			findFn: func(context.Context, IPAddressID, int64) (IPAddress, error) {
 				return IPAddress{ID: IPAddressID("ip-1")}, nil
 			},
-			updateFn: func(context.Context, IPAddressID, UpdateIPInput) (IPAddress, error) {
+			updateFn: func(context.Context, IPAddressID, UpdateIPRecord) (IPAddress, error) {
 				return IPAddress{}, repoErr
 			},
 		},
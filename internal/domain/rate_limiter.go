@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type rateLimiter struct {
+	counters RateLimitRepository
+	now      func() time.Time
+}
+
+// NewRateLimiter counts requests in fixed windows. Backed by the PostgreSQL
+// repository the budget is shared by every replica; backed by
+// NewMemoryRateLimitCounters it is per instance.
+func NewRateLimiter(counters RateLimitRepository) RateLimiter {
+	return &rateLimiter{counters: counters, now: time.Now}
+}
+
+func (l *rateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error) {
+	if limit.Requests <= 0 || limit.Window <= 0 {
+		return RateLimitDecision{Allowed: true}, nil
+	}
+	now := l.now().UTC()
+	windowStart := now.Truncate(limit.Window)
+	windowEnd := windowStart.Add(limit.Window)
+	hits, err := l.counters.Increment(ctx, key, windowStart, windowEnd)
+	if err != nil {
+		return RateLimitDecision{}, err
+	}
+	decision := RateLimitDecision{Allowed: hits <= limit.Requests, Limit: limit.Requests, Remaining: max(limit.Requests-hits, 0)}
+	if !decision.Allowed {
+		decision.RetryAfter = windowEnd.Sub(now)
+	}
+	return decision, nil
+}
+
+func (l *rateLimiter) PruneExpired(ctx context.Context) (int64, error) {
+	return l.counters.DeleteExpired(ctx, l.now().UTC())
+}
+
+type memoryRateLimitKey struct {
+	key         string
+	windowStart time.Time
+}
+
+type memoryRateLimitCounter struct {
+	hits      int64
+	expiresAt time.Time
+}
+
+type memoryRateLimitCounters struct {
+	mu       sync.Mutex
+	counters map[memoryRateLimitKey]*memoryRateLimitCounter
+}
+
+// NewMemoryRateLimitCounters keeps rate limit windows in process memory.
+func NewMemoryRateLimitCounters() RateLimitRepository {
+	return &memoryRateLimitCounters{counters: make(map[memoryRateLimitKey]*memoryRateLimitCounter)}
+}
+
+func (c *memoryRateLimitCounters) Increment(_ context.Context, key string, windowStart, expiresAt time.Time) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counterKey := memoryRateLimitKey{key: key, windowStart: windowStart}
+	counter, ok := c.counters[counterKey]
+	if !ok {
+		counter = &memoryRateLimitCounter{expiresAt: expiresAt}
+		c.counters[counterKey] = counter
+	}
+	counter.hits++
+	return counter.hits, nil
+}
+
+func (c *memoryRateLimitCounters) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var deleted int64
+	for key, counter := range c.counters {
+		if !counter.expiresAt.After(before) {
+			delete(c.counters, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
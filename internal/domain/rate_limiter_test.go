@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsBudgetPerWindow(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 15, 0, time.UTC)
+	limiter := &rateLimiter{counters: NewMemoryRateLimitCounters(), now: func() time.Time { return now }}
+	limit := RateLimit{Requests: 2, Window: time.Minute}
+	ctx := context.Background()
+
+	for want := int64(1); want >= 0; want-- {
+		decision, err := limiter.Allow(ctx, "sub:ci", limit)
+		if err != nil || !decision.Allowed || decision.Remaining != want {
+			t.Fatalf("expected allowed with %d remaining, got %+v err=%v", want, decision, err)
+		}
+	}
+	decision, err := limiter.Allow(ctx, "sub:ci", limit)
+	if err != nil || decision.Allowed || decision.RetryAfter != 45*time.Second {
+		t.Fatalf("expected rejection retrying in 45s, got %+v err=%v", decision, err)
+	}
+	if other, _ := limiter.Allow(ctx, "sub:other", limit); !other.Allowed {
+		t.Fatal("expected separate budget per key")
+	}
+
+	now = now.Add(time.Minute)
+	if next, _ := limiter.Allow(ctx, "sub:ci", limit); !next.Allowed {
+		t.Fatal("expected a new window to reset the budget")
+	}
+	deleted, err := limiter.PruneExpired(ctx)
+	if err != nil || deleted != 2 {
+		t.Fatalf("expected the two expired windows to be pruned, got %d err=%v", deleted, err)
+	}
+}
+
+func TestRateLimiterTreatsZeroAsUnlimited(t *testing.T) {
+	limiter := NewRateLimiter(NewMemoryRateLimitCounters())
+	for range 5 {
+		if decision, err := limiter.Allow(context.Background(), "sub:ci", RateLimit{}); err != nil || !decision.Allowed {
+			t.Fatalf("expected unlimited budget, got %+v err=%v", decision, err)
+		}
+	}
+}
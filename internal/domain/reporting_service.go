@@ -35,10 +35,10 @@ func (s *reportingService) GetSettings(ctx context.Context) (ReportingSettings,
 
 func (s *reportingService) UpdateSettings(ctx context.Context, input UpdateReportingSettingsInput) (ReportingSettings, error) {
 	if !validReportingCadence(input.Cadence) {
-		return ReportingSettings{}, fmt.Errorf("%w: cadence must be hourly, daily, or weekly", ErrInvalidInput)
+		return ReportingSettings{}, InvalidField("cadence", "cadence must be hourly, daily, or weekly")
 	}
 	if input.RetentionDays < MinReportingRetentionDays || input.RetentionDays > MaxReportingRetentionDays {
-		return ReportingSettings{}, fmt.Errorf("%w: retention_days must be between %d and %d", ErrInvalidInput, MinReportingRetentionDays, MaxReportingRetentionDays)
+		return ReportingSettings{}, InvalidField("retention_days", fmt.Sprintf("retention_days must be between %d and %d", MinReportingRetentionDays, MaxReportingRetentionDays))
 	}
 	return s.reports.UpdateSettings(ctx, input)
 }
@@ -46,7 +46,7 @@ func (s *reportingService) UpdateSettings(ctx context.Context, input UpdateRepor
 func (s *reportingService) GetSubnetUsageHistory(ctx context.Context, subnetID int64, usageRange string) (SubnetUsageHistory, error) {
 	duration, ok := usageRangeDurations[usageRange]
 	if !ok {
-		return SubnetUsageHistory{}, fmt.Errorf("%w: range must be 24h, 7d, 30d, 90d, or 180d", ErrInvalidInput)
+		return SubnetUsageHistory{}, InvalidField("range", "range must be 24h, 7d, 30d, 90d, or 180d")
 	}
 	subnet, err := s.subnets.FindByID(ctx, subnetID)
 	if err != nil {
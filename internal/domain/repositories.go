@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,7 @@ type SubnetRepository interface {
 	Create(ctx context.Context, input CreateSubnetRecord) (Subnet, error)
 	Update(ctx context.Context, input UpdateSubnetRecord) (Subnet, error)
 	AssignSite(ctx context.Context, id int64, siteID uuid.UUID) (Subnet, error)
+	UpdateDHCP(ctx context.Context, input UpdateSubnetDHCPRecord) (Subnet, error)
 	Delete(ctx context.Context, id int64) (bool, error)
 }
 
@@ -20,7 +22,7 @@ type IPRepository interface {
 	ListBySubnetID(ctx context.Context, subnetID int64) ([]IPAddress, error)
 	FindByIDAndSubnet(ctx context.Context, id IPAddressID, subnetID int64) (IPAddress, error)
 	Create(ctx context.Context, input CreateIPRecord, subnetID int64) (IPAddress, error)
-	UpdateHostname(ctx context.Context, id IPAddressID, input UpdateIPInput) (IPAddress, error)
+	Update(ctx context.Context, id IPAddressID, input UpdateIPRecord) (IPAddress, error)
 	DeleteByIDAndSubnet(ctx context.Context, id IPAddressID, subnetID int64) (bool, error)
 }
 
@@ -34,11 +36,76 @@ type SiteRepository interface {
 }
 
 type KubernetesDiscoveryRepository interface {
-	Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, observedAt time.Time) (KubernetesReconcileResult, error)
+	// Reconcile publishes a complete snapshot taken at observedAt by a cycle
+	// that started at startedAt, and records the cycle as a run.
+	Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, startedAt, observedAt time.Time) (KubernetesReconcileResult, error)
+	// ApplyChanges publishes changes without touching the source's other
+	// Services. The result counts every active Service of the source.
+	ApplyChanges(ctx context.Context, source KubernetesSourceConfig, changes KubernetesServiceChanges, observedAt time.Time) (KubernetesReconcileResult, error)
+	// ReconcileObjects replaces the source's Nodes, Pods and EndpointSlices
+	// with a complete snapshot of the kinds it discovers.
+	ReconcileObjects(ctx context.Context, source KubernetesSourceConfig, objects []KubernetesObjectSnapshot, observedAt time.Time) (KubernetesObjectReconcileResult, error)
 	RecordFailure(ctx context.Context, source KubernetesSourceConfig, attemptedAt time.Time, message string) error
+	// RecordObjectFailure stores why the source's objects could not be
+	// listed, apart from its Service status. The next published object
+	// snapshot clears it.
+	RecordObjectFailure(ctx context.Context, source KubernetesSourceConfig, message string) error
+	// RequestReconcile signals every replica that a cycle of the source was
+	// requested as requestID, or returns ErrNotFound when no source has the
+	// key. Reconcile and RecordFailure echo the ID of the request their ctx
+	// carries out, see WithKubernetesCycleRequest, in their notification.
+	RequestReconcile(ctx context.Context, key, requestID string, requestedAt time.Time) error
 	ListSourceStatuses(ctx context.Context) ([]KubernetesSourceStatus, error)
+	// ListRuns and ListEvents return the source's newest runs and Service
+	// changes first, or ErrNotFound when no source has the key. A nil runID
+	// lists the changes of every run.
+	ListRuns(ctx context.Context, key string, limit int32) ([]KubernetesDiscoveryRun, error)
+	ListEvents(ctx context.Context, key string, runID *int64, limit int32) ([]KubernetesDiscoveryEvent, error)
 	ListServicesBySubnetID(ctx context.Context, subnetID int64) (map[IPAddressID][]KubernetesServiceEnrichment, error)
 	ListAllServicesBySubnetID(ctx context.Context, subnetID int64) ([]KubernetesServiceObservation, error)
+	ListObjectsBySubnetID(ctx context.Context, subnetID int64) (map[IPAddressID][]KubernetesObjectEnrichment, error)
+	ListAllObjectsBySubnetID(ctx context.Context, subnetID int64) ([]KubernetesObjectObservation, error)
+}
+
+// KubernetesSourceRepository stores the sources managed through the API.
+// Creating a source whose key belongs to a configured source takes it over;
+// updating or deleting a configured source is a conflict.
+// KubernetesAllocationRepository stores LoadBalancer allocations as IP
+// address records owned by Services.
+type KubernetesAllocationRepository interface {
+	// Allocate returns the Service's existing allocation in the subnet, or
+	// records a new address for it. It returns ErrSubnetFull when the subnet
+	// has no free address.
+	Allocate(ctx context.Context, input AllocateLoadBalancerIPInput) (KubernetesLoadBalancerAllocation, error)
+	Release(ctx context.Context, controller, serviceUID string) (bool, error)
+	// ReleaseExcept releases every allocation of the controller made before
+	// listedAt that does not belong to one of serviceUIDs. An allocation
+	// another replica made after the Services were listed is kept.
+	ReleaseExcept(ctx context.Context, controller string, serviceUIDs []string, listedAt time.Time) (int64, error)
+}
+
+// KubernetesAddressClaimRepository stores the addresses of Cluster API
+// IPAddressClaims as IP address records owned by the claims.
+type KubernetesAddressClaimRepository interface {
+	// Claim returns the claim's existing address in the subnet, or records
+	// a new one for it. It returns ErrSubnetFull when the subnet has no free
+	// address.
+	Claim(ctx context.Context, input ClaimAddressInput) (KubernetesAddressClaim, error)
+	Release(ctx context.Context, claimUID string) (bool, error)
+}
+
+type KubernetesSourceRepository interface {
+	FindSourceByKey(ctx context.Context, key string) (KubernetesSourceRecord, error)
+	CreateSource(ctx context.Context, source KubernetesSourceRecord) (KubernetesSourceStatus, error)
+	UpdateSource(ctx context.Context, source KubernetesSourceRecord) (KubernetesSourceStatus, error)
+	DeleteSource(ctx context.Context, key string) (bool, error)
+	ListManagedSources(ctx context.Context) ([]KubernetesSourceRecord, error)
+}
+
+// SecretCipher encrypts credentials before they are stored.
+type SecretCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
 }
 
 type ReportingRepository interface {
@@ -48,3 +115,64 @@ type ReportingRepository interface {
 	CaptureDueSnapshots(ctx context.Context) (int64, error)
 	DeleteExpiredSnapshots(ctx context.Context) (int64, error)
 }
+
+type WebhookRepository interface {
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	FindSubscriptionByID(ctx context.Context, id uuid.UUID) (WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) (bool, error)
+	QueueDeliveries(ctx context.Context, limit int32) (int64, error)
+	ClaimDueDeliveries(ctx context.Context, limit int32, leaseUntil time.Time) ([]WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	MarkFailed(ctx context.Context, failure WebhookDeliveryFailure) error
+	ListDeadLetters(ctx context.Context, limit int32) ([]WebhookDeadLetter, error)
+	RetryDeadLetter(ctx context.Context, id int64) (bool, error)
+	DeleteDispatchedEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IdempotencyRepository interface {
+	// Claim stores record as pending and reports true, or returns the record
+	// currently holding the key and false. Expired records and pending ones
+	// created at or before staleBefore are taken over.
+	Claim(ctx context.Context, record IdempotencyRecord, staleBefore time.Time) (IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, scope, key string, response IdempotentResponse) error
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RateLimitRepository interface {
+	// Increment counts one hit in the window starting at windowStart and
+	// returns the total for that window.
+	Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int64, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type DNSZoneRepository interface {
+	ListZones(ctx context.Context) ([]DNSZone, error)
+	FindZoneByName(ctx context.Context, name string) (DNSZone, error)
+	// FindZoneForSubnet returns the zone attached to the subnet, or else the
+	// one attached to its site.
+	FindZoneForSubnet(ctx context.Context, subnetID int64) (DNSZone, error)
+	CreateZone(ctx context.Context, zone DNSZone) (DNSZone, error)
+	DeleteZone(ctx context.Context, name string) (bool, error)
+	ListHostRecords(ctx context.Context) ([]DNSHostRecord, error)
+	ListHostRecordsForIP(ctx context.Context, ip netip.Addr) ([]DNSHostRecord, error)
+	// BumpSerial stores the zone's content hash and returns its serial, which
+	// only changes when the hash does.
+	BumpSerial(ctx context.Context, zone, contentHash string, candidate uint32) (uint32, error)
+}
+
+type DNSUpdateRepository interface {
+	// ClaimDueUpdates leases due queue entries until leaseUntil.
+	ClaimDueUpdates(ctx context.Context, limit int32, leaseUntil time.Time) ([]DNSUpdateTask, error)
+	// CompleteUpdate removes the entry unless the address was queued again
+	// since it was claimed.
+	CompleteUpdate(ctx context.Context, task DNSUpdateTask) error
+	FailUpdate(ctx context.Context, failure DNSUpdateFailure) error
+	EnqueueUpdates(ctx context.Context, ips []netip.Addr) error
+	FindRegistration(ctx context.Context, ip netip.Addr) (DNSRegistration, error)
+	ListRegistrations(ctx context.Context) ([]DNSRegistration, error)
+	SaveRegistration(ctx context.Context, registration DNSRegistration) error
+	DeleteRegistration(ctx context.Context, ip netip.Addr) error
+}
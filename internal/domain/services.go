@@ -12,6 +12,14 @@ type ImportService interface {
 	ImportCSV(ctx context.Context, input io.Reader) (ImportResult, error)
 }
 
+type LeaseImportService interface {
+	ImportLeases(ctx context.Context, input LeaseImportInput) (ImportResult, error)
+}
+
+type ZoneImportService interface {
+	ImportZone(ctx context.Context, input ZoneImportInput) (ImportResult, error)
+}
+
 type NetworkService interface {
 	ListSubnets(ctx context.Context) ([]Subnet, error)
 	CreateSubnet(ctx context.Context, input CreateSubnetInput) (Subnet, error)
@@ -35,10 +43,19 @@ type SitesService interface {
 }
 
 type KubernetesDiscoveryService interface {
-	Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, observedAt time.Time) (KubernetesReconcileResult, error)
+	Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, startedAt, observedAt time.Time) (KubernetesReconcileResult, error)
+	ApplyChanges(ctx context.Context, source KubernetesSourceConfig, changes KubernetesServiceChanges, observedAt time.Time) (KubernetesReconcileResult, error)
+	ReconcileObjects(ctx context.Context, source KubernetesSourceConfig, objects []KubernetesObjectSnapshot, observedAt time.Time) (KubernetesObjectReconcileResult, error)
 	RecordFailure(ctx context.Context, source KubernetesSourceConfig, attemptedAt time.Time, err error) error
+	RecordObjectFailure(ctx context.Context, source KubernetesSourceConfig, err error) error
+	RequestReconcile(ctx context.Context, key, requestID string, requestedAt time.Time) error
 	ListSourceStatuses(ctx context.Context) ([]KubernetesSourceStatus, error)
+	ListRuns(ctx context.Context, key string, limit int32) ([]KubernetesDiscoveryRun, error)
+	ListEvents(ctx context.Context, key string, runID *int64, limit int32) ([]KubernetesDiscoveryEvent, error)
 	ListServicesBySubnetID(ctx context.Context, subnetID int64) ([]KubernetesServiceObservation, error)
+	// ListObjectsBySubnetID returns the Nodes, Pods and EndpointSlices with
+	// an address inside the subnet, whether or not an IP record matches it.
+	ListObjectsBySubnetID(ctx context.Context, subnetID int64) ([]KubernetesObjectObservation, error)
 }
 
 type ReportingService interface {
@@ -47,3 +64,80 @@ type ReportingService interface {
 	GetSubnetUsageHistory(ctx context.Context, subnetID int64, usageRange string) (SubnetUsageHistory, error)
 	RunSnapshotCycle(ctx context.Context) (SnapshotCycleResult, error)
 }
+
+type WebhookService interface {
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, input CreateWebhookSubscriptionInput) (WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, input UpdateWebhookSubscriptionInput) (WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) (bool, error)
+	ListDeadLetters(ctx context.Context, limit int32) ([]WebhookDeadLetter, error)
+	RetryDeadLetter(ctx context.Context, id int64) error
+	ClaimDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, delivery WebhookDelivery, statusCode int, deliveryErr error) error
+	PruneEvents(ctx context.Context) (int64, error)
+}
+
+// KubernetesAllocationService hands out addresses from an IPAM subnet to
+// Kubernetes LoadBalancer Services and releases them again.
+type KubernetesAllocationService interface {
+	Allocate(ctx context.Context, input AllocateLoadBalancerIPInput) (KubernetesLoadBalancerAllocation, error)
+	Release(ctx context.Context, controller, serviceUID string) (bool, error)
+	ReleaseExcept(ctx context.Context, controller string, serviceUIDs []string, listedAt time.Time) (int64, error)
+}
+
+// KubernetesAddressClaimService hands out addresses from an IPAM subnet to
+// Cluster API IPAddressClaims and releases them again.
+type KubernetesAddressClaimService interface {
+	Claim(ctx context.Context, input ClaimAddressInput) (KubernetesAddressClaim, error)
+	Release(ctx context.Context, claimUID string) (bool, error)
+}
+
+// KubernetesSourceService manages discovery sources through the API.
+// ListManagedSources decrypts their kubeconfigs for the discovery runners.
+type KubernetesSourceService interface {
+	CreateSource(ctx context.Context, input CreateKubernetesSourceInput) (KubernetesSourceStatus, error)
+	UpdateSource(ctx context.Context, input UpdateKubernetesSourceInput) (KubernetesSourceStatus, error)
+	DeleteSource(ctx context.Context, key string) (bool, error)
+	ListManagedSources(ctx context.Context) ([]ManagedKubernetesSource, error)
+}
+
+type DNSService interface {
+	ListZones(ctx context.Context) ([]DNSZone, error)
+	GetZone(ctx context.Context, name string) (DNSZone, error)
+	CreateZone(ctx context.Context, input CreateDNSZoneInput) (DNSZone, error)
+	DeleteZone(ctx context.Context, name string) (bool, error)
+	ZoneData(ctx context.Context, name string) (DNSZoneData, error)
+}
+
+// DHCPService renders DHCP server configuration from subnets and the
+// addresses reserved in them by MAC address.
+type DHCPService interface {
+	UpdateSubnetSettings(ctx context.Context, input UpdateSubnetDHCPInput) (Subnet, error)
+	SubnetConfig(ctx context.Context, subnetID int64, format DHCPConfigFormat) ([]byte, error)
+	SiteConfig(ctx context.Context, siteID uuid.UUID, format DHCPConfigFormat) ([]byte, error)
+}
+
+// DNSUpdateService keeps an external DNS server in step with the IPAM
+// through dynamic updates.
+type DNSUpdateService interface {
+	ProcessUpdates(ctx context.Context, limit int32) ([]DNSUpdateResult, error)
+	// Reconcile queues every address whose records differ from what the
+	// server holds and returns how many were queued.
+	Reconcile(ctx context.Context) (int, error)
+}
+
+type IdempotencyService interface {
+	// Begin claims the key. It returns the stored response when the key has
+	// already completed for the same request, or nil when the caller should
+	// run the request and then call Complete or Release.
+	Begin(ctx context.Context, request IdempotencyRequest) (*IdempotentResponse, error)
+	Complete(ctx context.Context, request IdempotencyRequest, response IdempotentResponse) error
+	Release(ctx context.Context, request IdempotencyRequest) error
+	PruneExpired(ctx context.Context) (int64, error)
+}
+
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error)
+	PruneExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,567 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName identifies spans started by the domain service decorators.
+const TracerName = "github.com/Flarenzy/simple-k8s-app/internal/domain"
+
+// startSpan looks the tracer up per call so a provider installed after the
+// services were built still receives the spans.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan marks the span as failed for unexpected errors only; not-found,
+// validation and conflict errors are ordinary client outcomes.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrInvalidInput) && !errors.Is(err, ErrConflict) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func subnetAttr(id int64) attribute.KeyValue {
+	return attribute.Int64("ipam.subnet_id", id)
+}
+
+type tracingNetworkService struct {
+	next NetworkService
+}
+
+func NewTracingNetworkService(next NetworkService) NetworkService {
+	if next == nil {
+		return nil
+	}
+	return &tracingNetworkService{next: next}
+}
+
+func (s *tracingNetworkService) ListSubnets(ctx context.Context) (subnets []Subnet, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.ListSubnets")
+	defer func() { endSpan(span, err) }()
+	return s.next.ListSubnets(ctx)
+}
+
+func (s *tracingNetworkService) CreateSubnet(ctx context.Context, input CreateSubnetInput) (subnet Subnet, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.CreateSubnet")
+	defer func() { endSpan(span, err) }()
+	return s.next.CreateSubnet(ctx, input)
+}
+
+func (s *tracingNetworkService) UpdateSubnet(ctx context.Context, input UpdateSubnetInput) (subnet Subnet, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.UpdateSubnet", subnetAttr(input.ID))
+	defer func() { endSpan(span, err) }()
+	return s.next.UpdateSubnet(ctx, input)
+}
+
+func (s *tracingNetworkService) AssignSubnetSite(ctx context.Context, input AssignSubnetSiteInput) (subnet Subnet, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.AssignSubnetSite", subnetAttr(input.ID))
+	defer func() { endSpan(span, err) }()
+	return s.next.AssignSubnetSite(ctx, input)
+}
+
+func (s *tracingNetworkService) GetSubnet(ctx context.Context, id int64) (subnet Subnet, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.GetSubnet", subnetAttr(id))
+	defer func() { endSpan(span, err) }()
+	return s.next.GetSubnet(ctx, id)
+}
+
+func (s *tracingNetworkService) DeleteSubnet(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "NetworkService.DeleteSubnet", subnetAttr(id))
+	defer func() { endSpan(span, err) }()
+	return s.next.DeleteSubnet(ctx, id)
+}
+
+func (s *tracingNetworkService) ListIPs(ctx context.Context, subnetID int64) (ips []IPAddress, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.ListIPs", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.ListIPs(ctx, subnetID)
+}
+
+func (s *tracingNetworkService) CreateIP(ctx context.Context, subnetID int64, input CreateIPInput) (ip IPAddress, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.CreateIP", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.CreateIP(ctx, subnetID, input)
+}
+
+func (s *tracingNetworkService) UpdateIPHostname(ctx context.Context, subnetID int64, id IPAddressID, input UpdateIPInput) (ip IPAddress, err error) {
+	ctx, span := startSpan(ctx, "NetworkService.UpdateIPHostname", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.UpdateIPHostname(ctx, subnetID, id, input)
+}
+
+func (s *tracingNetworkService) DeleteIP(ctx context.Context, subnetID int64, id IPAddressID) (err error) {
+	ctx, span := startSpan(ctx, "NetworkService.DeleteIP", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.DeleteIP(ctx, subnetID, id)
+}
+
+type tracingSitesService struct {
+	next SitesService
+}
+
+func NewTracingSitesService(next SitesService) SitesService {
+	if next == nil {
+		return nil
+	}
+	return &tracingSitesService{next: next}
+}
+
+func (s *tracingSitesService) List(ctx context.Context) (sites []Site, err error) {
+	ctx, span := startSpan(ctx, "SitesService.List")
+	defer func() { endSpan(span, err) }()
+	return s.next.List(ctx)
+}
+
+func (s *tracingSitesService) FindByID(ctx context.Context, id uuid.UUID) (site Site, err error) {
+	ctx, span := startSpan(ctx, "SitesService.FindByID")
+	defer func() { endSpan(span, err) }()
+	return s.next.FindByID(ctx, id)
+}
+
+func (s *tracingSitesService) Create(ctx context.Context, input CreateSiteInput) (site Site, err error) {
+	ctx, span := startSpan(ctx, "SitesService.Create")
+	defer func() { endSpan(span, err) }()
+	return s.next.Create(ctx, input)
+}
+
+func (s *tracingSitesService) Update(ctx context.Context, input UpdateSiteInput) (site Site, err error) {
+	ctx, span := startSpan(ctx, "SitesService.Update")
+	defer func() { endSpan(span, err) }()
+	return s.next.Update(ctx, input)
+}
+
+func (s *tracingSitesService) Delete(ctx context.Context, id uuid.UUID) (deleted bool, err error) {
+	ctx, span := startSpan(ctx, "SitesService.Delete")
+	defer func() { endSpan(span, err) }()
+	return s.next.Delete(ctx, id)
+}
+
+func (s *tracingSitesService) Statistics(ctx context.Context) (stats []SiteStatistics, err error) {
+	ctx, span := startSpan(ctx, "SitesService.Statistics")
+	defer func() { endSpan(span, err) }()
+	return s.next.Statistics(ctx)
+}
+
+type tracingImportService struct {
+	next ImportService
+}
+
+func NewTracingImportService(next ImportService) ImportService {
+	if next == nil {
+		return nil
+	}
+	return &tracingImportService{next: next}
+}
+
+func (s *tracingImportService) ImportCSV(ctx context.Context, input io.Reader) (result ImportResult, err error) {
+	ctx, span := startSpan(ctx, "ImportService.ImportCSV")
+	defer func() {
+		span.SetAttributes(attribute.Int("ipam.import.created", result.Created), attribute.Int("ipam.import.errors", len(result.Errors)))
+		endSpan(span, err)
+	}()
+	return s.next.ImportCSV(ctx, input)
+}
+
+type tracingLeaseImportService struct {
+	next LeaseImportService
+}
+
+func NewTracingLeaseImportService(next LeaseImportService) LeaseImportService {
+	if next == nil {
+		return nil
+	}
+	return &tracingLeaseImportService{next: next}
+}
+
+func (s *tracingLeaseImportService) ImportLeases(ctx context.Context, input LeaseImportInput) (result ImportResult, err error) {
+	ctx, span := startSpan(ctx, "LeaseImportService.ImportLeases", attribute.String("ipam.import.format", string(input.Format)), attribute.Bool("ipam.import.dry_run", input.DryRun))
+	defer func() {
+		span.SetAttributes(attribute.Int("ipam.import.created", result.Created), attribute.Int("ipam.import.errors", len(result.Errors)))
+		endSpan(span, err)
+	}()
+	return s.next.ImportLeases(ctx, input)
+}
+
+type tracingZoneImportService struct {
+	next ZoneImportService
+}
+
+func NewTracingZoneImportService(next ZoneImportService) ZoneImportService {
+	if next == nil {
+		return nil
+	}
+	return &tracingZoneImportService{next: next}
+}
+
+func (s *tracingZoneImportService) ImportZone(ctx context.Context, input ZoneImportInput) (result ImportResult, err error) {
+	ctx, span := startSpan(ctx, "ZoneImportService.ImportZone", attribute.Bool("ipam.import.dry_run", input.DryRun))
+	defer func() {
+		span.SetAttributes(attribute.Int("ipam.import.created", result.Created), attribute.Int("ipam.import.errors", len(result.Errors)))
+		endSpan(span, err)
+	}()
+	return s.next.ImportZone(ctx, input)
+}
+
+type tracingKubernetesDiscoveryService struct {
+	next KubernetesDiscoveryService
+}
+
+func NewTracingKubernetesDiscoveryService(next KubernetesDiscoveryService) KubernetesDiscoveryService {
+	if next == nil {
+		return nil
+	}
+	return &tracingKubernetesDiscoveryService{next: next}
+}
+
+func (s *tracingKubernetesDiscoveryService) Reconcile(ctx context.Context, source KubernetesSourceConfig, services []KubernetesServiceSnapshot, startedAt, observedAt time.Time) (result KubernetesReconcileResult, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.Reconcile",
+		attribute.String("ipam.kubernetes.source", source.Key), attribute.Int("ipam.kubernetes.services", len(services)))
+	defer func() { endSpan(span, err) }()
+	return s.next.Reconcile(ctx, source, services, startedAt, observedAt)
+}
+
+func (s *tracingKubernetesDiscoveryService) ApplyChanges(ctx context.Context, source KubernetesSourceConfig, changes KubernetesServiceChanges, observedAt time.Time) (result KubernetesReconcileResult, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ApplyChanges",
+		attribute.String("ipam.kubernetes.source", source.Key),
+		attribute.Int("ipam.kubernetes.upserted", len(changes.Upserted)), attribute.Int("ipam.kubernetes.deleted", len(changes.DeletedUIDs)))
+	defer func() { endSpan(span, err) }()
+	return s.next.ApplyChanges(ctx, source, changes, observedAt)
+}
+
+func (s *tracingKubernetesDiscoveryService) ReconcileObjects(ctx context.Context, source KubernetesSourceConfig, objects []KubernetesObjectSnapshot, observedAt time.Time) (result KubernetesObjectReconcileResult, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ReconcileObjects",
+		attribute.String("ipam.kubernetes.source", source.Key), attribute.Int("ipam.kubernetes.objects", len(objects)))
+	defer func() { endSpan(span, err) }()
+	return s.next.ReconcileObjects(ctx, source, objects, observedAt)
+}
+
+func (s *tracingKubernetesDiscoveryService) RecordFailure(ctx context.Context, source KubernetesSourceConfig, attemptedAt time.Time, cause error) (err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.RecordFailure", attribute.String("ipam.kubernetes.source", source.Key))
+	defer func() { endSpan(span, err) }()
+	return s.next.RecordFailure(ctx, source, attemptedAt, cause)
+}
+
+func (s *tracingKubernetesDiscoveryService) RecordObjectFailure(ctx context.Context, source KubernetesSourceConfig, cause error) (err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.RecordObjectFailure", attribute.String("ipam.kubernetes.source", source.Key))
+	defer func() { endSpan(span, err) }()
+	return s.next.RecordObjectFailure(ctx, source, cause)
+}
+
+func (s *tracingKubernetesDiscoveryService) RequestReconcile(ctx context.Context, key, requestID string, requestedAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.RequestReconcile", attribute.String("ipam.kubernetes.source", key))
+	defer func() { endSpan(span, err) }()
+	return s.next.RequestReconcile(ctx, key, requestID, requestedAt)
+}
+
+func (s *tracingKubernetesDiscoveryService) ListSourceStatuses(ctx context.Context) (statuses []KubernetesSourceStatus, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ListSourceStatuses")
+	defer func() { endSpan(span, err) }()
+	return s.next.ListSourceStatuses(ctx)
+}
+
+func (s *tracingKubernetesDiscoveryService) ListRuns(ctx context.Context, key string, limit int32) (runs []KubernetesDiscoveryRun, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ListRuns", attribute.String("ipam.kubernetes.source", key))
+	defer func() { endSpan(span, err) }()
+	return s.next.ListRuns(ctx, key, limit)
+}
+
+func (s *tracingKubernetesDiscoveryService) ListEvents(ctx context.Context, key string, runID *int64, limit int32) (events []KubernetesDiscoveryEvent, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ListEvents", attribute.String("ipam.kubernetes.source", key))
+	defer func() { endSpan(span, err) }()
+	return s.next.ListEvents(ctx, key, runID, limit)
+}
+
+func (s *tracingKubernetesDiscoveryService) ListServicesBySubnetID(ctx context.Context, subnetID int64) (services []KubernetesServiceObservation, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ListServicesBySubnetID", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.ListServicesBySubnetID(ctx, subnetID)
+}
+
+func (s *tracingKubernetesDiscoveryService) ListObjectsBySubnetID(ctx context.Context, subnetID int64) (objects []KubernetesObjectObservation, err error) {
+	ctx, span := startSpan(ctx, "KubernetesDiscoveryService.ListObjectsBySubnetID", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.ListObjectsBySubnetID(ctx, subnetID)
+}
+
+type tracingKubernetesAllocationService struct {
+	next KubernetesAllocationService
+}
+
+func NewTracingKubernetesAllocationService(next KubernetesAllocationService) KubernetesAllocationService {
+	if next == nil {
+		return nil
+	}
+	return &tracingKubernetesAllocationService{next: next}
+}
+
+func (s *tracingKubernetesAllocationService) Allocate(ctx context.Context, input AllocateLoadBalancerIPInput) (allocation KubernetesLoadBalancerAllocation, err error) {
+	ctx, span := startSpan(ctx, "KubernetesAllocationService.Allocate",
+		attribute.String("ipam.kubernetes.controller", input.Controller), subnetAttr(input.SubnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.Allocate(ctx, input)
+}
+
+func (s *tracingKubernetesAllocationService) Release(ctx context.Context, controller, serviceUID string) (released bool, err error) {
+	ctx, span := startSpan(ctx, "KubernetesAllocationService.Release", attribute.String("ipam.kubernetes.controller", controller))
+	defer func() { endSpan(span, err) }()
+	return s.next.Release(ctx, controller, serviceUID)
+}
+
+func (s *tracingKubernetesAllocationService) ReleaseExcept(ctx context.Context, controller string, serviceUIDs []string, listedAt time.Time) (released int64, err error) {
+	ctx, span := startSpan(ctx, "KubernetesAllocationService.ReleaseExcept",
+		attribute.String("ipam.kubernetes.controller", controller), attribute.Int("ipam.kubernetes.services", len(serviceUIDs)))
+	defer func() { endSpan(span, err) }()
+	return s.next.ReleaseExcept(ctx, controller, serviceUIDs, listedAt)
+}
+
+type tracingKubernetesAddressClaimService struct {
+	next KubernetesAddressClaimService
+}
+
+func NewTracingKubernetesAddressClaimService(next KubernetesAddressClaimService) KubernetesAddressClaimService {
+	if next == nil {
+		return nil
+	}
+	return &tracingKubernetesAddressClaimService{next: next}
+}
+
+func (s *tracingKubernetesAddressClaimService) Claim(ctx context.Context, input ClaimAddressInput) (claim KubernetesAddressClaim, err error) {
+	ctx, span := startSpan(ctx, "KubernetesAddressClaimService.Claim", subnetAttr(input.SubnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.Claim(ctx, input)
+}
+
+func (s *tracingKubernetesAddressClaimService) Release(ctx context.Context, claimUID string) (released bool, err error) {
+	ctx, span := startSpan(ctx, "KubernetesAddressClaimService.Release")
+	defer func() { endSpan(span, err) }()
+	return s.next.Release(ctx, claimUID)
+}
+
+type tracingKubernetesSourceService struct {
+	next KubernetesSourceService
+}
+
+func NewTracingKubernetesSourceService(next KubernetesSourceService) KubernetesSourceService {
+	if next == nil {
+		return nil
+	}
+	return &tracingKubernetesSourceService{next: next}
+}
+
+func (s *tracingKubernetesSourceService) CreateSource(ctx context.Context, input CreateKubernetesSourceInput) (status KubernetesSourceStatus, err error) {
+	ctx, span := startSpan(ctx, "KubernetesSourceService.CreateSource", attribute.String("ipam.kubernetes.source", input.Key))
+	defer func() { endSpan(span, err) }()
+	return s.next.CreateSource(ctx, input)
+}
+
+func (s *tracingKubernetesSourceService) UpdateSource(ctx context.Context, input UpdateKubernetesSourceInput) (status KubernetesSourceStatus, err error) {
+	ctx, span := startSpan(ctx, "KubernetesSourceService.UpdateSource", attribute.String("ipam.kubernetes.source", input.Key))
+	defer func() { endSpan(span, err) }()
+	return s.next.UpdateSource(ctx, input)
+}
+
+func (s *tracingKubernetesSourceService) DeleteSource(ctx context.Context, key string) (deleted bool, err error) {
+	ctx, span := startSpan(ctx, "KubernetesSourceService.DeleteSource", attribute.String("ipam.kubernetes.source", key))
+	defer func() { endSpan(span, err) }()
+	return s.next.DeleteSource(ctx, key)
+}
+
+func (s *tracingKubernetesSourceService) ListManagedSources(ctx context.Context) (sources []ManagedKubernetesSource, err error) {
+	ctx, span := startSpan(ctx, "KubernetesSourceService.ListManagedSources")
+	defer func() { endSpan(span, err) }()
+	return s.next.ListManagedSources(ctx)
+}
+
+type tracingReportingService struct {
+	next ReportingService
+}
+
+func NewTracingReportingService(next ReportingService) ReportingService {
+	if next == nil {
+		return nil
+	}
+	return &tracingReportingService{next: next}
+}
+
+func (s *tracingReportingService) GetSettings(ctx context.Context) (settings ReportingSettings, err error) {
+	ctx, span := startSpan(ctx, "ReportingService.GetSettings")
+	defer func() { endSpan(span, err) }()
+	return s.next.GetSettings(ctx)
+}
+
+func (s *tracingReportingService) UpdateSettings(ctx context.Context, input UpdateReportingSettingsInput) (settings ReportingSettings, err error) {
+	ctx, span := startSpan(ctx, "ReportingService.UpdateSettings")
+	defer func() { endSpan(span, err) }()
+	return s.next.UpdateSettings(ctx, input)
+}
+
+func (s *tracingReportingService) GetSubnetUsageHistory(ctx context.Context, subnetID int64, usageRange string) (history SubnetUsageHistory, err error) {
+	ctx, span := startSpan(ctx, "ReportingService.GetSubnetUsageHistory", subnetAttr(subnetID))
+	defer func() { endSpan(span, err) }()
+	return s.next.GetSubnetUsageHistory(ctx, subnetID, usageRange)
+}
+
+func (s *tracingReportingService) RunSnapshotCycle(ctx context.Context) (result SnapshotCycleResult, err error) {
+	ctx, span := startSpan(ctx, "ReportingService.RunSnapshotCycle")
+	defer func() { endSpan(span, err) }()
+	return s.next.RunSnapshotCycle(ctx)
+}
+
+type tracingWebhookService struct {
+	next WebhookService
+}
+
+func NewTracingWebhookService(next WebhookService) WebhookService {
+	if next == nil {
+		return nil
+	}
+	return &tracingWebhookService{next: next}
+}
+
+func (s *tracingWebhookService) ListSubscriptions(ctx context.Context) (subscriptions []WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.ListSubscriptions")
+	defer func() { endSpan(span, err) }()
+	return s.next.ListSubscriptions(ctx)
+}
+
+func (s *tracingWebhookService) GetSubscription(ctx context.Context, id uuid.UUID) (subscription WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.GetSubscription")
+	defer func() { endSpan(span, err) }()
+	return s.next.GetSubscription(ctx, id)
+}
+
+func (s *tracingWebhookService) CreateSubscription(ctx context.Context, input CreateWebhookSubscriptionInput) (subscription WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.CreateSubscription")
+	defer func() { endSpan(span, err) }()
+	return s.next.CreateSubscription(ctx, input)
+}
+
+func (s *tracingWebhookService) UpdateSubscription(ctx context.Context, input UpdateWebhookSubscriptionInput) (subscription WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.UpdateSubscription")
+	defer func() { endSpan(span, err) }()
+	return s.next.UpdateSubscription(ctx, input)
+}
+
+func (s *tracingWebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID) (deleted bool, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.DeleteSubscription")
+	defer func() { endSpan(span, err) }()
+	return s.next.DeleteSubscription(ctx, id)
+}
+
+func (s *tracingWebhookService) ListDeadLetters(ctx context.Context, limit int32) (deadLetters []WebhookDeadLetter, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.ListDeadLetters")
+	defer func() { endSpan(span, err) }()
+	return s.next.ListDeadLetters(ctx, limit)
+}
+
+func (s *tracingWebhookService) RetryDeadLetter(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "WebhookService.RetryDeadLetter")
+	defer func() { endSpan(span, err) }()
+	return s.next.RetryDeadLetter(ctx, id)
+}
+
+func (s *tracingWebhookService) ClaimDeliveries(ctx context.Context, limit int32) (deliveries []WebhookDelivery, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.ClaimDeliveries")
+	defer func() { endSpan(span, err) }()
+	return s.next.ClaimDeliveries(ctx, limit)
+}
+
+func (s *tracingWebhookService) RecordDeliveryAttempt(ctx context.Context, delivery WebhookDelivery, statusCode int, deliveryErr error) (err error) {
+	ctx, span := startSpan(ctx, "WebhookService.RecordDeliveryAttempt", attribute.Int("http.response.status_code", statusCode))
+	defer func() { endSpan(span, err) }()
+	return s.next.RecordDeliveryAttempt(ctx, delivery, statusCode, deliveryErr)
+}
+
+func (s *tracingWebhookService) PruneEvents(ctx context.Context) (deleted int64, err error) {
+	ctx, span := startSpan(ctx, "WebhookService.PruneEvents")
+	defer func() { endSpan(span, err) }()
+	return s.next.PruneEvents(ctx)
+}
+
+type tracingDNSService struct {
+	next DNSService
+}
+
+func NewTracingDNSService(next DNSService) DNSService {
+	if next == nil {
+		return nil
+	}
+	return &tracingDNSService{next: next}
+}
+
+func dnsZoneAttr(name string) attribute.KeyValue {
+	return attribute.String("ipam.dns_zone", name)
+}
+
+func (s *tracingDNSService) ListZones(ctx context.Context) (zones []DNSZone, err error) {
+	ctx, span := startSpan(ctx, "DNSService.ListZones")
+	defer func() { endSpan(span, err) }()
+	return s.next.ListZones(ctx)
+}
+
+func (s *tracingDNSService) GetZone(ctx context.Context, name string) (zone DNSZone, err error) {
+	ctx, span := startSpan(ctx, "DNSService.GetZone", dnsZoneAttr(name))
+	defer func() { endSpan(span, err) }()
+	return s.next.GetZone(ctx, name)
+}
+
+func (s *tracingDNSService) CreateZone(ctx context.Context, input CreateDNSZoneInput) (zone DNSZone, err error) {
+	ctx, span := startSpan(ctx, "DNSService.CreateZone", dnsZoneAttr(input.Name))
+	defer func() { endSpan(span, err) }()
+	return s.next.CreateZone(ctx, input)
+}
+
+func (s *tracingDNSService) DeleteZone(ctx context.Context, name string) (deleted bool, err error) {
+	ctx, span := startSpan(ctx, "DNSService.DeleteZone", dnsZoneAttr(name))
+	defer func() { endSpan(span, err) }()
+	return s.next.DeleteZone(ctx, name)
+}
+
+func (s *tracingDNSService) ZoneData(ctx context.Context, name string) (data DNSZoneData, err error) {
+	ctx, span := startSpan(ctx, "DNSService.ZoneData", dnsZoneAttr(name))
+	defer func() { endSpan(span, err) }()
+	return s.next.ZoneData(ctx, name)
+}
+
+type tracingDHCPService struct {
+	next DHCPService
+}
+
+func NewTracingDHCPService(next DHCPService) DHCPService {
+	if next == nil {
+		return nil
+	}
+	return &tracingDHCPService{next: next}
+}
+
+func (s *tracingDHCPService) UpdateSubnetSettings(ctx context.Context, input UpdateSubnetDHCPInput) (subnet Subnet, err error) {
+	ctx, span := startSpan(ctx, "DHCPService.UpdateSubnetSettings", subnetAttr(input.ID))
+	defer func() { endSpan(span, err) }()
+	return s.next.UpdateSubnetSettings(ctx, input)
+}
+
+func (s *tracingDHCPService) SubnetConfig(ctx context.Context, subnetID int64, format DHCPConfigFormat) (config []byte, err error) {
+	ctx, span := startSpan(ctx, "DHCPService.SubnetConfig", subnetAttr(subnetID), attribute.String("ipam.dhcp.format", string(format)))
+	defer func() { endSpan(span, err) }()
+	return s.next.SubnetConfig(ctx, subnetID, format)
+}
+
+func (s *tracingDHCPService) SiteConfig(ctx context.Context, siteID uuid.UUID, format DHCPConfigFormat) (config []byte, err error) {
+	ctx, span := startSpan(ctx, "DHCPService.SiteConfig", attribute.String("ipam.site_id", siteID.String()), attribute.String("ipam.dhcp.format", string(format)))
+	defer func() { endSpan(span, err) }()
+	return s.next.SiteConfig(ctx, siteID, format)
+}
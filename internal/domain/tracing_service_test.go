@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func useSpanRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func TestTracingNetworkServiceRecordsSpans(t *testing.T) {
+	exporter := useSpanRecorder(t)
+	var innerSpan trace.SpanContext
+	service := NewTracingNetworkService(stubNetworkService{
+		listSubnetsFn: func(ctx context.Context) ([]Subnet, error) {
+			innerSpan = trace.SpanContextFromContext(ctx)
+			return nil, nil
+		},
+		getSubnetFn: func(context.Context, int64) (Subnet, error) {
+			return Subnet{}, ErrNotFound
+		},
+		deleteSubnetFn: func(context.Context, int64) error {
+			return errors.New("connection reset")
+		},
+	})
+
+	_, _ = service.ListSubnets(context.Background())
+	_, _ = service.GetSubnet(context.Background(), 7)
+	_ = service.DeleteSubnet(context.Background(), 7)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	if spans[0].Name != "NetworkService.ListSubnets" || spans[0].SpanContext.SpanID() != innerSpan.SpanID() {
+		t.Fatalf("expected the wrapped service to run inside the span, got %+v", spans[0])
+	}
+	if spans[1].Status.Code == codes.Error || len(spans[1].Events) != 1 {
+		t.Fatalf("expected not found to be recorded without failing the span, got %+v", spans[1].Status)
+	}
+	if spans[2].Status.Code != codes.Error {
+		t.Fatalf("expected unexpected errors to fail the span, got %+v", spans[2].Status)
+	}
+}
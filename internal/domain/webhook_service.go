@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookMaxAttempts          int32 = 10
+	MinWebhookSecretLength            = 16
+	MaxWebhookDeadLetters       int32 = 500
+	webhookBaseBackoff                = 10 * time.Second
+	webhookMaxBackoff                 = time.Hour
+	webhookDeliveryLease              = 2 * time.Minute
+	webhookQueueBatchSize       int32 = 500
+	webhookOutboxRetention            = 7 * 24 * time.Hour
+	generatedWebhookSecretBytes       = 32
+)
+
+var webhookEventTypes = []string{
+	EventSubnetCreated, EventSubnetUpdated, EventSubnetDeleted,
+	EventIPCreated, EventIPUpdated, EventIPDeleted,
+	EventSiteCreated, EventSiteUpdated, EventSiteDeleted,
+}
+
+var webhookObjectTypes = []string{"subnet", "ip", "site"}
+
+type webhookService struct {
+	webhooks WebhookRepository
+	now      func() time.Time
+	jitter   func(time.Duration) time.Duration
+}
+
+func NewWebhookService(webhooks WebhookRepository) WebhookService {
+	return &webhookService{webhooks: webhooks, now: time.Now, jitter: retryJitter}
+}
+
+// WebhookEventTypes returns every concrete event type a subscription can
+// select. Subscriptions may also use "<object>.*" or "*".
+func WebhookEventTypes() []string {
+	return slices.Clone(webhookEventTypes)
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	return s.webhooks.ListSubscriptions(ctx)
+}
+
+func (s *webhookService) GetSubscription(ctx context.Context, id uuid.UUID) (WebhookSubscription, error) {
+	return s.webhooks.FindSubscriptionByID(ctx, id)
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, input CreateWebhookSubscriptionInput) (WebhookSubscription, error) {
+	subscription := WebhookSubscription{
+		URL:         strings.TrimSpace(input.URL),
+		Description: input.Description,
+		Secret:      input.Secret,
+		Active:      input.Active == nil || *input.Active,
+	}
+	var err error
+	if subscription.EventTypes, err = normalizeWebhookEventTypes(input.EventTypes); err != nil {
+		return WebhookSubscription{}, err
+	}
+	if err := validateWebhookURL(subscription.URL); err != nil {
+		return WebhookSubscription{}, err
+	}
+	if subscription.Secret == "" {
+		if subscription.Secret, err = generateWebhookSecret(); err != nil {
+			return WebhookSubscription{}, err
+		}
+	} else if err := validateWebhookSecret(subscription.Secret); err != nil {
+		return WebhookSubscription{}, err
+	}
+	return s.webhooks.CreateSubscription(ctx, subscription)
+}
+
+func (s *webhookService) UpdateSubscription(ctx context.Context, input UpdateWebhookSubscriptionInput) (WebhookSubscription, error) {
+	subscription, err := s.webhooks.FindSubscriptionByID(ctx, input.ID)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	if input.URL != nil {
+		subscription.URL = strings.TrimSpace(*input.URL)
+		if err := validateWebhookURL(subscription.URL); err != nil {
+			return WebhookSubscription{}, err
+		}
+	}
+	if input.Description != nil {
+		subscription.Description = *input.Description
+	}
+	if input.EventTypes != nil {
+		if subscription.EventTypes, err = normalizeWebhookEventTypes(input.EventTypes); err != nil {
+			return WebhookSubscription{}, err
+		}
+	}
+	if input.Secret != nil {
+		if err := validateWebhookSecret(*input.Secret); err != nil {
+			return WebhookSubscription{}, err
+		}
+		subscription.Secret = *input.Secret
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+	return s.webhooks.UpdateSubscription(ctx, subscription)
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.webhooks.DeleteSubscription(ctx, id)
+}
+
+func (s *webhookService) ListDeadLetters(ctx context.Context, limit int32) ([]WebhookDeadLetter, error) {
+	if limit <= 0 || limit > MaxWebhookDeadLetters {
+		return nil, InvalidField("limit", fmt.Sprintf("limit must be between 1 and %d", MaxWebhookDeadLetters))
+	}
+	return s.webhooks.ListDeadLetters(ctx, limit)
+}
+
+func (s *webhookService) RetryDeadLetter(ctx context.Context, id int64) error {
+	retried, err := s.webhooks.RetryDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !retried {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimDeliveries fans new outbox events out to matching subscriptions and
+// leases the deliveries that are due. A lease that is never settled (for
+// example because the replica crashed) expires and the delivery is retried.
+func (s *webhookService) ClaimDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error) {
+	if _, err := s.webhooks.QueueDeliveries(ctx, webhookQueueBatchSize); err != nil {
+		return nil, err
+	}
+	return s.webhooks.ClaimDueDeliveries(ctx, limit, s.now().Add(webhookDeliveryLease))
+}
+
+func (s *webhookService) RecordDeliveryAttempt(ctx context.Context, delivery WebhookDelivery, statusCode int, deliveryErr error) error {
+	if deliveryErr == nil && statusCode >= 200 && statusCode < 300 {
+		return s.webhooks.MarkDelivered(ctx, delivery.ID, statusCode)
+	}
+	failure := WebhookDeliveryFailure{
+		ID:            delivery.ID,
+		Dead:          delivery.Attempts >= WebhookMaxAttempts,
+		NextAttemptAt: s.now().Add(s.jitter(webhookBackoff(delivery.Attempts))),
+	}
+	if statusCode > 0 {
+		code := int32(statusCode)
+		failure.StatusCode = &code
+	}
+	if deliveryErr != nil {
+		failure.Error = deliveryErr.Error()
+	} else {
+		failure.Error = fmt.Sprintf("unexpected response status %d", statusCode)
+	}
+	return s.webhooks.MarkFailed(ctx, failure)
+}
+
+func (s *webhookService) PruneEvents(ctx context.Context) (int64, error) {
+	return s.webhooks.DeleteDispatchedEvents(ctx, s.now().Add(-webhookOutboxRetention))
+}
+
+// webhookBackoff doubles the delay after every failed attempt, starting at
+// webhookBaseBackoff and capped at webhookMaxBackoff.
+func webhookBackoff(attempts int32) time.Duration {
+	return retryBackoff(webhookBaseBackoff, webhookMaxBackoff, attempts)
+}
+
+func normalizeWebhookEventTypes(eventTypes []string) ([]string, error) {
+	out := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !validWebhookEventType(eventType) {
+			return nil, InvalidField("event_types", fmt.Sprintf("unsupported event type %q", eventType))
+		}
+		if !slices.Contains(out, eventType) {
+			out = append(out, eventType)
+		}
+	}
+	if len(out) == 0 {
+		return nil, InvalidField("event_types", "at least one event type is required")
+	}
+	return out, nil
+}
+
+func validWebhookEventType(eventType string) bool {
+	if eventType == "*" || slices.Contains(webhookEventTypes, eventType) {
+		return true
+	}
+	objectType, ok := strings.CutSuffix(eventType, ".*")
+	return ok && slices.Contains(webhookObjectTypes, objectType)
+}
+
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return InvalidField("url", "url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if len(secret) < MinWebhookSecretLength {
+		return InvalidField("secret", fmt.Sprintf("secret must be at least %d characters", MinWebhookSecretLength))
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, generatedWebhookSecretBytes)
+	if _, err := cryptorand.Read(secret); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type stubWebhookRepository struct {
+	subscription WebhookSubscription
+	created      WebhookSubscription
+	updated      WebhookSubscription
+	delivered    []int64
+	failures     []WebhookDeliveryFailure
+	queued       int
+	leaseUntil   time.Time
+	retried      bool
+	findErr      error
+}
+
+func (s *stubWebhookRepository) ListSubscriptions(context.Context) ([]WebhookSubscription, error) {
+	return []WebhookSubscription{s.subscription}, nil
+}
+
+func (s *stubWebhookRepository) FindSubscriptionByID(context.Context, uuid.UUID) (WebhookSubscription, error) {
+	return s.subscription, s.findErr
+}
+
+func (s *stubWebhookRepository) CreateSubscription(_ context.Context, subscription WebhookSubscription) (WebhookSubscription, error) {
+	s.created = subscription
+	return subscription, nil
+}
+
+func (s *stubWebhookRepository) UpdateSubscription(_ context.Context, subscription WebhookSubscription) (WebhookSubscription, error) {
+	s.updated = subscription
+	return subscription, nil
+}
+
+func (s *stubWebhookRepository) DeleteSubscription(context.Context, uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func (s *stubWebhookRepository) QueueDeliveries(context.Context, int32) (int64, error) {
+	s.queued++
+	return 0, nil
+}
+
+func (s *stubWebhookRepository) ClaimDueDeliveries(_ context.Context, _ int32, leaseUntil time.Time) ([]WebhookDelivery, error) {
+	s.leaseUntil = leaseUntil
+	return nil, nil
+}
+
+func (s *stubWebhookRepository) MarkDelivered(_ context.Context, id int64, _ int) error {
+	s.delivered = append(s.delivered, id)
+	return nil
+}
+
+func (s *stubWebhookRepository) MarkFailed(_ context.Context, failure WebhookDeliveryFailure) error {
+	s.failures = append(s.failures, failure)
+	return nil
+}
+
+func (s *stubWebhookRepository) ListDeadLetters(context.Context, int32) ([]WebhookDeadLetter, error) {
+	return nil, nil
+}
+
+func (s *stubWebhookRepository) RetryDeadLetter(context.Context, int64) (bool, error) {
+	return s.retried, nil
+}
+
+func (s *stubWebhookRepository) DeleteDispatchedEvents(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestCreateWebhookSubscriptionValidatesAndGeneratesSecret(t *testing.T) {
+	repository := &stubWebhookRepository{}
+	service := NewWebhookService(repository)
+	for _, input := range []CreateWebhookSubscriptionInput{
+		{URL: "ftp://hooks.example.test", EventTypes: []string{"*"}},
+		{URL: "/relative", EventTypes: []string{"*"}},
+		{URL: "https://hooks.example.test", EventTypes: nil},
+		{URL: "https://hooks.example.test", EventTypes: []string{"vlan.created"}},
+		{URL: "https://hooks.example.test", EventTypes: []string{"*"}, Secret: "short"},
+	} {
+		if _, err := service.CreateSubscription(context.Background(), input); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("expected invalid input for %+v, got %v", input, err)
+		}
+	}
+
+	subscription, err := service.CreateSubscription(context.Background(), CreateWebhookSubscriptionInput{
+		URL:        " https://hooks.example.test/ipam ",
+		EventTypes: []string{EventSubnetCreated, "ip.*", EventSubnetCreated},
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	if subscription.URL != "https://hooks.example.test/ipam" || !subscription.Active || len(subscription.Secret) != 2*generatedWebhookSecretBytes {
+		t.Fatalf("unexpected subscription: %+v", subscription)
+	}
+	if !slices.Equal(subscription.EventTypes, []string{EventSubnetCreated, "ip.*"}) {
+		t.Fatalf("expected deduplicated event types, got %v", subscription.EventTypes)
+	}
+}
+
+func TestUpdateWebhookSubscriptionOnlyChangesProvidedFields(t *testing.T) {
+	id := uuid.New()
+	repository := &stubWebhookRepository{subscription: WebhookSubscription{
+		ID: id, URL: "https://hooks.example.test", EventTypes: []string{"*"}, Secret: "0123456789abcdef", Active: true,
+	}}
+	service := NewWebhookService(repository)
+	active := false
+	if _, err := service.UpdateSubscription(context.Background(), UpdateWebhookSubscriptionInput{ID: id, Active: &active}); err != nil {
+		t.Fatalf("update subscription: %v", err)
+	}
+	if repository.updated.Active || repository.updated.URL != "https://hooks.example.test" || repository.updated.Secret != "0123456789abcdef" {
+		t.Fatalf("unexpected update: %+v", repository.updated)
+	}
+
+	repository.findErr = ErrNotFound
+	if _, err := service.UpdateSubscription(context.Background(), UpdateWebhookSubscriptionInput{ID: id}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRecordDeliveryAttemptBacksOffAndDeadLetters(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repository := &stubWebhookRepository{}
+	service := &webhookService{webhooks: repository, now: func() time.Time { return now }, jitter: func(d time.Duration) time.Duration { return d }}
+	ctx := context.Background()
+
+	if err := service.RecordDeliveryAttempt(ctx, WebhookDelivery{ID: 1, Attempts: 1}, 204, nil); err != nil {
+		t.Fatalf("record success: %v", err)
+	}
+	if err := service.RecordDeliveryAttempt(ctx, WebhookDelivery{ID: 2, Attempts: 3}, 503, nil); err != nil {
+		t.Fatalf("record failure: %v", err)
+	}
+	if err := service.RecordDeliveryAttempt(ctx, WebhookDelivery{ID: 3, Attempts: WebhookMaxAttempts}, 0, errors.New("connection refused")); err != nil {
+		t.Fatalf("record final failure: %v", err)
+	}
+
+	if !slices.Equal(repository.delivered, []int64{1}) {
+		t.Fatalf("expected delivery 1 marked delivered, got %v", repository.delivered)
+	}
+	if len(repository.failures) != 2 {
+		t.Fatalf("expected two failures, got %+v", repository.failures)
+	}
+	retry := repository.failures[0]
+	if retry.Dead || retry.StatusCode == nil || *retry.StatusCode != 503 || !retry.NextAttemptAt.Equal(now.Add(40*time.Second)) {
+		t.Fatalf("unexpected retry: %+v", retry)
+	}
+	dead := repository.failures[1]
+	if !dead.Dead || dead.StatusCode != nil || dead.Error != "connection refused" {
+		t.Fatalf("unexpected dead letter: %+v", dead)
+	}
+}
+
+func TestWebhookBackoffIsCapped(t *testing.T) {
+	if got := webhookBackoff(1); got != webhookBaseBackoff {
+		t.Fatalf("expected base backoff, got %s", got)
+	}
+	if got := webhookBackoff(30); got != webhookMaxBackoff {
+		t.Fatalf("expected capped backoff, got %s", got)
+	}
+}
+
+func TestClaimDeliveriesQueuesOutboxAndLeases(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repository := &stubWebhookRepository{}
+	service := &webhookService{webhooks: repository, now: func() time.Time { return now }, jitter: retryJitter}
+	if _, err := service.ClaimDeliveries(context.Background(), 10); err != nil {
+		t.Fatalf("claim deliveries: %v", err)
+	}
+	if repository.queued != 1 || !repository.leaseUntil.Equal(now.Add(webhookDeliveryLease)) {
+		t.Fatalf("unexpected claim: queued=%d lease=%s", repository.queued, repository.leaseUntil)
+	}
+	if err := service.RetryDeadLetter(context.Background(), 5); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for non-dead delivery, got %v", err)
+	}
+}
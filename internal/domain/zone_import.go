@@ -0,0 +1,293 @@
+package domain
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// MaxZoneImportBytes is the maximum number of bytes accepted for one zone
+// file.
+const MaxZoneImportBytes int64 = 64 << 20
+
+const maxZoneFileLineBytes = 64 << 10
+
+type zoneImportService struct {
+	network NetworkService
+}
+
+func NewZoneImportService(network NetworkService) ZoneImportService {
+	return &zoneImportService{network: network}
+}
+
+// zoneFileEntry is one logical line of a zone file: a directive or a
+// resource record, joined across parentheses. BlankOwner is set when the
+// line starts with whitespace, so the record reuses the previous owner.
+type zoneFileEntry struct {
+	Line       int
+	Fields     []string
+	BlankOwner bool
+}
+
+// zoneAddressRecord is an A, AAAA or PTR record reduced to the address and
+// the host name it gives that address.
+type zoneAddressRecord struct {
+	Line     int
+	IP       netip.Addr
+	Hostname string
+}
+
+// ImportZone names addresses after the A, AAAA and PTR records of a BIND
+// zone file. Addresses are created in the most specific subnet containing
+// them and existing hostnames are kept, so when several records name an
+// address the first one wins. Other record types are ignored. Hostnames are
+// stored as fully qualified names without the trailing dot.
+func (s *zoneImportService) ImportZone(ctx context.Context, input ZoneImportInput) (ImportResult, error) {
+	origin := ""
+	if input.Origin != "" {
+		origin = normalizeDNSName(input.Origin)
+		if err := validateDNSName(origin); err != nil {
+			return ImportResult{}, InvalidField("origin", "invalid origin: "+err.Error())
+		}
+	}
+	limitedInput := &io.LimitedReader{R: input.File, N: MaxZoneImportBytes + 1}
+	entries, err := scanZoneFile(limitedInput)
+	if limitedInput.N == 0 {
+		return ImportResult{}, fmt.Errorf("%w: zone file exceeds maximum size of %d bytes", ErrInvalidInput, MaxZoneImportBytes)
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+	records, rowErrors := zoneAddressRecords(entries, origin)
+	subnets, err := s.network.ListSubnets(ctx)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	importer := newAddressImporter(s.network, subnets, input.DryRun)
+	result := ImportResult{Processed: len(rowErrors), Failed: len(rowErrors)}
+	for _, record := range records {
+		result.Processed++
+		outcome, err := importer.record(ctx, importedAddress{IP: record.IP, Hostname: record.Hostname})
+		if err != nil {
+			result.Failed++
+			rowErrors = append(rowErrors, RowError{Row: record.Line, Message: err.Error()})
+			continue
+		}
+		result.count(outcome)
+	}
+	result.Errors = sortRowErrors(rowErrors)
+	return result, nil
+}
+
+// zoneAddressRecords interprets the entries of a zone file, following
+// $ORIGIN and the owner of the previous record. Only A, AAAA and PTR records
+// are returned or reported.
+func zoneAddressRecords(entries []zoneFileEntry, origin string) ([]zoneAddressRecord, []RowError) {
+	var records []zoneAddressRecord
+	var rowErrors []RowError
+	fail := func(line int, format string, args ...any) {
+		rowErrors = append(rowErrors, RowError{Row: line, Message: fmt.Sprintf(format, args...)})
+	}
+	owner := ""
+	var ownerErr error
+	for _, entry := range entries {
+		fields := entry.Fields
+		if !entry.BlankOwner && strings.HasPrefix(fields[0], "$") {
+			switch directive := strings.ToUpper(fields[0]); directive {
+			case "$ORIGIN":
+				if len(fields) != 2 {
+					fail(entry.Line, "$ORIGIN takes one name")
+					continue
+				}
+				name, err := zoneFileName(fields[1], origin)
+				if err != nil {
+					fail(entry.Line, "%v", err)
+					continue
+				}
+				origin = name
+			case "$TTL":
+			case "$INCLUDE", "$GENERATE":
+				fail(entry.Line, "%s is not supported", directive)
+			default:
+				fail(entry.Line, "unknown directive %s", fields[0])
+			}
+			continue
+		}
+		if !entry.BlankOwner {
+			owner, ownerErr = zoneFileName(fields[0], origin)
+			fields = fields[1:]
+		}
+		recordType, data := zoneRecordTypeAndData(fields)
+		if recordType != "A" && recordType != "AAAA" && recordType != "PTR" {
+			continue
+		}
+		if ownerErr != nil {
+			fail(entry.Line, "%v", ownerErr)
+			continue
+		}
+		if owner == "" {
+			fail(entry.Line, "record has no owner name")
+			continue
+		}
+		switch recordType {
+		case "A", "AAAA":
+			if len(data) != 1 {
+				fail(entry.Line, "%s record must have one address", recordType)
+				continue
+			}
+			ip, err := netip.ParseAddr(data[0])
+			if err != nil || ip.Is4() != (recordType == "A") || ip.Zone() != "" {
+				fail(entry.Line, "invalid %s address %q", recordType, data[0])
+				continue
+			}
+			if err := validateDNSName(owner); err != nil {
+				fail(entry.Line, "invalid hostname %q: %v", owner, err)
+				continue
+			}
+			records = append(records, zoneAddressRecord{Line: entry.Line, IP: ip, Hostname: owner})
+		case "PTR":
+			ip, ok := parseReverseName(owner)
+			if !ok {
+				fail(entry.Line, "%s does not name a single address", owner)
+				continue
+			}
+			if len(data) != 1 {
+				fail(entry.Line, "PTR record must have one target")
+				continue
+			}
+			target, err := zoneFileName(data[0], origin)
+			if err == nil {
+				err = validateDNSName(target)
+			}
+			if err != nil {
+				fail(entry.Line, "invalid hostname %q: %v", data[0], err)
+				continue
+			}
+			records = append(records, zoneAddressRecord{Line: entry.Line, IP: ip, Hostname: target})
+		}
+	}
+	return records, rowErrors
+}
+
+// zoneRecordTypeAndData skips the optional TTL and class, which may come in
+// either order, and returns the upper-cased type and the record data.
+func zoneRecordTypeAndData(fields []string) (string, []string) {
+	for skipped := 0; len(fields) > 0 && skipped < 2; skipped++ {
+		field := strings.ToUpper(fields[0])
+		isTTL := field[0] >= '0' && field[0] <= '9'
+		isClass := field == "IN" || field == "CH" || field == "HS" || field == "CS" || strings.HasPrefix(field, "CLASS")
+		if !isTTL && !isClass {
+			break
+		}
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return strings.ToUpper(fields[0]), fields[1:]
+}
+
+// zoneFileName resolves a name against origin and returns it without the
+// trailing dot. "@" is the origin itself.
+func zoneFileName(name, origin string) (string, error) {
+	switch {
+	case name == "@":
+		if origin == "" {
+			return "", fmt.Errorf("@ used without an origin")
+		}
+		return origin, nil
+	case strings.HasSuffix(name, "."):
+		return normalizeDNSName(name), nil
+	case origin == "":
+		return "", fmt.Errorf("relative name %q used without an origin", name)
+	default:
+		return normalizeDNSName(name + "." + origin), nil
+	}
+}
+
+// scanZoneFile splits a zone file into entries. Comments start with ";"
+// outside quotes, and parentheses continue an entry over several lines.
+func scanZoneFile(input io.Reader) ([]zoneFileEntry, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxZoneFileLineBytes)
+	var entries []zoneFileEntry
+	var current zoneFileEntry
+	depth := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if depth == 0 {
+			current = zoneFileEntry{Line: line, BlankOwner: text != "" && (text[0] == ' ' || text[0] == '\t')}
+		}
+		var field strings.Builder
+		inField, quoted := false, false
+		flush := func() {
+			if inField {
+				current.Fields = append(current.Fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		}
+	scan:
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+			switch {
+			case quoted:
+				if c == '"' {
+					quoted = false
+					flush()
+					continue
+				}
+				if c == '\\' && i+1 < len(text) {
+					i++
+					c = text[i]
+				}
+				field.WriteByte(c)
+			case c == '\\' && i+1 < len(text):
+				i++
+				field.WriteByte(text[i])
+				inField = true
+			case c == ';':
+				break scan
+			case c == '"':
+				flush()
+				quoted, inField = true, true
+			case c == '(':
+				flush()
+				depth++
+			case c == ')':
+				flush()
+				if depth == 0 {
+					return nil, fmt.Errorf("%w: unbalanced ) on line %d", ErrInvalidInput, line)
+				}
+				depth--
+			case c == ' ' || c == '\t' || c == '\r':
+				flush()
+			default:
+				field.WriteByte(c)
+				inField = true
+			}
+		}
+		if quoted {
+			return nil, fmt.Errorf("%w: unterminated string on line %d", ErrInvalidInput, line)
+		}
+		flush()
+		if depth == 0 && len(current.Fields) > 0 {
+			entries = append(entries, current)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: line exceeds maximum size of %d bytes", ErrInvalidInput, maxZoneFileLineBytes)
+		}
+		return nil, err
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("%w: unbalanced ( in the entry starting on line %d", ErrInvalidInput, current.Line)
+	}
+	return entries, nil
+}
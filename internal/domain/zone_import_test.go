@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const forwardZoneFile = `$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	ns1.example.com. hostmaster.example.com. (
+		2026101401	; serial
+		3600 900 604800 300 )
+	IN	NS	ns1
+ns1	IN	A	10.0.1.53
+www	300	IN	A	10.0.1.80
+	IN	AAAA	2001:db8::80
+mail	IN	MX	10 mail
+mail	IN	A	10.0.1.25
+alias	IN	A	10.0.1.80
+printer.office.example.net.	A	10.0.1.10
+txt	IN	TXT	"a ; quoted" "string"
+far	IN	A	192.0.2.1
+*.wild	IN	A	10.0.1.99
+bad	IN	A	10.0.1.300
+$INCLUDE other.zone
+`
+
+func TestZoneAddressRecordsFollowsOriginAndOwners(t *testing.T) {
+	entries, err := scanZoneFile(strings.NewReader(forwardZoneFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, rowErrors := zoneAddressRecords(entries, "")
+	var got []string
+	for _, record := range records {
+		got = append(got, fmt.Sprintf("%d %s %s", record.Line, record.IP, record.Hostname))
+	}
+	want := []string{
+		"7 10.0.1.53 ns1.example.com",
+		"8 10.0.1.80 www.example.com",
+		"9 2001:db8::80 www.example.com",
+		"11 10.0.1.25 mail.example.com",
+		"12 10.0.1.80 alias.example.com",
+		"13 10.0.1.10 printer.office.example.net",
+		"15 192.0.2.1 far.example.com",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected records:\n%s", strings.Join(got, "\n"))
+	}
+	wantErrors := []RowError{
+		{Row: 16, Message: `invalid hostname "*.wild.example.com": label "*" may only contain letters, digits and hyphens`},
+		{Row: 17, Message: `invalid A address "10.0.1.300"`},
+		{Row: 18, Message: "$INCLUDE is not supported"},
+	}
+	if fmt.Sprint(rowErrors) != fmt.Sprint(wantErrors) {
+		t.Fatalf("unexpected row errors: %+v", rowErrors)
+	}
+}
+
+func TestZoneAddressRecordsReadsReverseZones(t *testing.T) {
+	zone := `$TTL 3600
+@	SOA	ns1.example.com. hostmaster.example.com. 1 3600 900 604800 300
+10	PTR	printer.example.com.
+11	IN	PTR	scanner
+0-25	PTR	delegated.example.com.
+`
+	entries, err := scanZoneFile(strings.NewReader(zone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, rowErrors := zoneAddressRecords(entries, "1.0.10.in-addr.arpa")
+	if len(records) != 2 || records[0].IP.String() != "10.0.1.10" || records[0].Hostname != "printer.example.com" || records[1].Hostname != "scanner.1.0.10.in-addr.arpa" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Row != 5 || rowErrors[0].Message != "0-25.1.0.10.in-addr.arpa does not name a single address" {
+		t.Fatalf("unexpected row errors: %+v", rowErrors)
+	}
+
+	ipv6 := "$ORIGIN 8.b.d.0.1.0.0.2.ip6.arpa.\n0.8.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0 PTR www.example.com.\n"
+	if entries, err = scanZoneFile(strings.NewReader(ipv6)); err != nil {
+		t.Fatal(err)
+	}
+	records, rowErrors = zoneAddressRecords(entries, "")
+	if len(rowErrors) != 0 || len(records) != 1 || records[0].IP != netip.MustParseAddr("2001:db8::80") {
+		t.Fatalf("unexpected ipv6 records: %+v %+v", records, rowErrors)
+	}
+}
+
+func TestZoneAddressRecordsNeedAnOrigin(t *testing.T) {
+	entries, err := scanZoneFile(strings.NewReader("@ SOA ns1 hostmaster 1 2 3 4 5\nwww A 10.0.1.80\nroot.example.com. A 10.0.1.81\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, rowErrors := zoneAddressRecords(entries, "")
+	if len(records) != 1 || records[0].Hostname != "root.example.com" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Row != 2 || rowErrors[0].Message != `relative name "www" used without an origin` {
+		t.Fatalf("unexpected row errors: %+v", rowErrors)
+	}
+}
+
+func TestScanZoneFileRejectsMalformedFiles(t *testing.T) {
+	tests := map[string]string{
+		"@ SOA ns1 hostmaster (\n1 2 3\n":  "unbalanced ( in the entry starting on line 1",
+		"www A 10.0.0.1 )\n":               "unbalanced ) on line 1",
+		"txt TXT \"no end\n":               "unterminated string on line 1",
+		strings.Repeat("a", 70<<10) + "\n": "line exceeds maximum size",
+	}
+	for input, detail := range tests {
+		_, err := scanZoneFile(strings.NewReader(input))
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), detail) {
+			t.Fatalf("expected %q, got %v", detail, err)
+		}
+	}
+}
+
+func TestParseReverseName(t *testing.T) {
+	for _, addr := range []string{"10.0.1.10", "2001:db8::80", "0.0.0.0"} {
+		parsed, ok := parseReverseName(ReverseName(netip.MustParseAddr(addr)) + ".")
+		if !ok || parsed.String() != addr {
+			t.Fatalf("round trip of %s gave %s, %t", addr, parsed, ok)
+		}
+	}
+	for _, name := range []string{"1.0.10.in-addr.arpa", "010.1.0.10.in-addr.arpa", "256.1.0.10.in-addr.arpa", "a.b.ip6.arpa", "example.com"} {
+		if _, ok := parseReverseName(name); ok {
+			t.Fatalf("expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestZoneImportNamesAddresses(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		t.Run(fmt.Sprintf("dry run %t", dryRun), func(t *testing.T) {
+			network := &importNetworkStub{
+				subnets: []Subnet{
+					{ID: 1, CIDR: netip.MustParsePrefix("10.0.1.0/24"), SiteID: uuid.New()},
+					{ID: 2, CIDR: netip.MustParsePrefix("2001:db8::/64"), SiteID: uuid.New()},
+				},
+				ips: map[int64][]IPAddress{
+					1: {
+						{ID: "named", IP: netip.MustParseAddr("10.0.1.53"), Hostname: "dns1", SubnetID: 1},
+						{ID: "unnamed", IP: netip.MustParseAddr("10.0.1.25"), SubnetID: 1},
+					},
+				},
+			}
+			service := NewZoneImportService(network)
+
+			result, err := service.ImportZone(context.Background(), ZoneImportInput{File: strings.NewReader(forwardZoneFile), DryRun: dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Processed != 10 || result.Created != 3 || result.Updated != 1 || result.Failed != 4 {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			if result.Errors[0].Row != 15 || result.Errors[0].Message != "no subnet contains 192.0.2.1" || result.Errors[3].Row != 18 {
+				t.Fatalf("unexpected errors: %+v", result.Errors)
+			}
+			if dryRun {
+				if network.createdIPs != 0 || network.updatedIPs != 0 {
+					t.Fatalf("dry run wrote: created=%d updated=%d", network.createdIPs, network.updatedIPs)
+				}
+				return
+			}
+			names := map[string]string{}
+			for _, subnetID := range []int64{1, 2} {
+				for _, ip := range network.ips[subnetID] {
+					names[ip.IP.String()] = ip.Hostname
+				}
+			}
+			want := map[string]string{
+				"10.0.1.53":    "dns1",
+				"10.0.1.25":    "mail.example.com",
+				"10.0.1.80":    "www.example.com",
+				"2001:db8::80": "www.example.com",
+				"10.0.1.10":    "printer.office.example.net",
+			}
+			if fmt.Sprint(names) != fmt.Sprint(want) {
+				t.Fatalf("unexpected hostnames: %v", names)
+			}
+		})
+	}
+}
+
+func TestZoneImportRejectsInvalidOrigin(t *testing.T) {
+	_, err := NewZoneImportService(&importNetworkStub{}).ImportZone(context.Background(), ZoneImportInput{Origin: "bad_name", File: strings.NewReader("")})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid origin, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+const (
+	subscriberBuffer     = 64
+	minReconnectDelay    = time.Second
+	maxReconnectDelay    = 30 * time.Second
+	healthyListenSession = time.Minute
+)
+
+// Listener delivers change notifications until ctx ends or the connection
+// fails. The PostgreSQL implementation is db.EventListener.
+type Listener interface {
+	Listen(ctx context.Context, handle func(domain.ChangeEvent)) error
+}
+
+type subscriber struct {
+	filter domain.EventFilter
+	events chan domain.ChangeEvent
+}
+
+// Broker fans change events out to in-process stream subscribers. Each
+// replica runs its own broker fed by a Listener, so subscribers see changes
+// made through any replica.
+type Broker struct {
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewBroker(logger *slog.Logger) *Broker {
+	return &Broker{logger: logger, subscribers: make(map[*subscriber]struct{})}
+}
+
+// Subscribe returns a channel of events matching filter. The channel is
+// closed when ctx is done. Events are dropped rather than blocking the broker
+// when a subscriber falls behind; clients refetch state on every event, so a
+// later event recovers the view.
+func (b *Broker) Subscribe(ctx context.Context, filter domain.EventFilter) <-chan domain.ChangeEvent {
+	sub := &subscriber{filter: filter, events: make(chan domain.ChangeEvent, subscriberBuffer)}
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(sub)
+	}()
+	return sub.events
+}
+
+// Close ends every open subscription so streaming handlers return and the
+// HTTP server can shut down gracefully.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+func (b *Broker) remove(sub *subscriber) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+func (b *Broker) Publish(event domain.ChangeEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.logger.Warn("dropping live event for slow subscriber", "type", event.Type, "object_id", event.ObjectID)
+		}
+	}
+}
+
+// Run feeds the broker from listener, reconnecting with jittered backoff
+// until ctx is cancelled.
+func (b *Broker) Run(ctx context.Context, listener Listener) {
+	delay := minReconnectDelay
+	for {
+		startedAt := time.Now()
+		err := listener.Listen(ctx, b.Publish)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(startedAt) >= healthyListenSession {
+			delay = minReconnectDelay
+		}
+		b.logger.WarnContext(ctx, "live event listener disconnected", "err", err, "retry_in", delay)
+
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func jitter(delay time.Duration) time.Duration {
+	return time.Duration(float64(delay) * (0.8 + rand.Float64()*0.4))
+}
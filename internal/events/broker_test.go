@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+func newTestBroker() *Broker {
+	return NewBroker(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func receive(t *testing.T, events <-chan domain.ChangeEvent) domain.ChangeEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return domain.ChangeEvent{}
+	}
+}
+
+func TestBrokerDeliversMatchingEvents(t *testing.T) {
+	siteID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	otherSite := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	broker := newTestBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ipEvents := broker.Subscribe(ctx, domain.EventFilter{ObjectTypes: []string{domain.ObjectTypeIP}, SiteID: &siteID})
+	allEvents := broker.Subscribe(ctx, domain.EventFilter{})
+
+	broker.Publish(domain.ChangeEvent{ID: 1, Type: domain.EventSubnetCreated, ObjectType: domain.ObjectTypeSubnet, SiteID: &siteID})
+	broker.Publish(domain.ChangeEvent{ID: 2, Type: domain.EventIPCreated, ObjectType: domain.ObjectTypeIP, SiteID: &otherSite})
+	broker.Publish(domain.ChangeEvent{ID: 3, Type: domain.EventIPCreated, ObjectType: domain.ObjectTypeIP, SiteID: &siteID})
+
+	if event := receive(t, ipEvents); event.ID != 3 {
+		t.Fatalf("expected only event 3 for filtered subscriber, got %+v", event)
+	}
+	for _, want := range []int64{1, 2, 3} {
+		if event := receive(t, allEvents); event.ID != want {
+			t.Fatalf("expected event %d, got %+v", want, event)
+		}
+	}
+}
+
+func TestBrokerClosesSubscriptionAndDropsForSlowSubscribers(t *testing.T) {
+	broker := newTestBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	events := broker.Subscribe(ctx, domain.EventFilter{})
+	for i := range subscriberBuffer + 10 {
+		broker.Publish(domain.ChangeEvent{ID: int64(i)})
+	}
+	if len(events) != subscriberBuffer {
+		t.Fatalf("expected buffered events to be capped at %d, got %d", subscriberBuffer, len(events))
+	}
+
+	cancel()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("subscription was not closed after cancel")
+		}
+	}
+}
+
+func TestBrokerCloseEndsSubscriptions(t *testing.T) {
+	broker := newTestBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := broker.Subscribe(ctx, domain.EventFilter{})
+	broker.Close()
+	if _, ok := <-events; ok {
+		t.Fatal("expected closed subscription")
+	}
+	cancel()
+	broker.Publish(domain.ChangeEvent{ID: 1})
+}
+
+type stubListener struct {
+	calls chan struct{}
+}
+
+func (l *stubListener) Listen(ctx context.Context, handle func(domain.ChangeEvent)) error {
+	l.calls <- struct{}{}
+	handle(domain.ChangeEvent{ID: 9, ObjectType: domain.ObjectTypeSite})
+	return errors.New("connection reset")
+}
+
+func TestBrokerRunPublishesFromListener(t *testing.T) {
+	broker := newTestBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := broker.Subscribe(ctx, domain.EventFilter{})
+	listener := &stubListener{calls: make(chan struct{}, 1)}
+
+	done := make(chan struct{})
+	go func() {
+		broker.Run(ctx, listener)
+		close(done)
+	}()
+	<-listener.calls
+	if event := receive(t, events); event.ID != 9 {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
@@ -14,16 +14,40 @@ type HealthChecker interface {
 	Ping(ctx context.Context) error
 }
 
+// EventStream delivers live change events to stream subscribers.
+type EventStream interface {
+	Subscribe(ctx context.Context, filter domain.EventFilter) <-chan domain.ChangeEvent
+}
+
+// KubernetesReconciler runs a discovery cycle of a source on demand.
+type KubernetesReconciler interface {
+	Reconcile(ctx context.Context, key string) (domain.KubernetesReconcileResult, error)
+}
+
 type API struct {
-	Logger             *slog.Logger
-	Health             HealthChecker
-	NetService         domain.NetworkService
-	SitesService       domain.SitesService
-	ImportService      domain.ImportService
-	DiscoveryService   domain.KubernetesDiscoveryService
-	ReportingService   domain.ReportingService
-	Authenticator      apiauth.Authenticator
-	CORSAllowedOrigins []string
+	Logger                  *slog.Logger
+	Health                  HealthChecker
+	NetService              domain.NetworkService
+	SitesService            domain.SitesService
+	ImportService           domain.ImportService
+	LeaseImportService      domain.LeaseImportService
+	ZoneImportService       domain.ZoneImportService
+	DiscoveryService        domain.KubernetesDiscoveryService
+	KubernetesSourceService domain.KubernetesSourceService
+	KubernetesReconciler    KubernetesReconciler
+	ReportingService        domain.ReportingService
+	WebhookService          domain.WebhookService
+	DNSService              domain.DNSService
+	DHCPService             domain.DHCPService
+	EventStream             EventStream
+	IdempotencyService      domain.IdempotencyService
+	RateLimiter             domain.RateLimiter
+	RateLimits              RateLimits
+	RequestObserver         RequestObserver
+	MetricsHandler          http.Handler
+	MetricsToken            string
+	Authenticator           apiauth.Authenticator
+	CORSAllowedOrigins      []string
 }
 
 func NewAPI(logger *slog.Logger,
@@ -55,32 +79,61 @@ func (a *API) Router() http.Handler {
 
 	mux.HandleFunc("/healthz", a.handleHealthz)
 	mux.HandleFunc("/readyz", a.handleReadyz)
+	if a.MetricsHandler != nil {
+		mux.Handle("GET /metrics", a.metricsTokenMiddleware(a.MetricsHandler))
+	}
 	mux.Handle("/swagger/", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 
 	mux.HandleFunc("GET /api/v1/subnets", a.handleGetAllSubnets)
-	mux.HandleFunc("POST /api/v1/subnets", a.handleCreateSubnet)
+	mux.HandleFunc("POST /api/v1/subnets", a.idempotent(a.handleCreateSubnet))
 	mux.HandleFunc("GET /api/v1/subnets/{id}", a.handleGetSubnetByID)
 	mux.HandleFunc("PATCH /api/v1/subnets/{id}", a.handleUpdateSubnet)
 	mux.HandleFunc("PATCH /api/v1/subnets/{id}/site", a.handleAssignSubnetSite)
+	mux.HandleFunc("PATCH /api/v1/subnets/{id}/dhcp", a.handleUpdateSubnetDHCP)
+	mux.HandleFunc("GET /api/v1/subnets/{id}/dhcp-config", a.handleGetSubnetDHCPConfig)
 	mux.HandleFunc("DELETE /api/v1/subnets/{id}", a.handleDeleteSubnetByID)
 	mux.HandleFunc("GET /api/v1/sites", a.handleGetAllSites)
-	mux.HandleFunc("POST /api/v1/sites", a.handleCreateSite)
+	mux.HandleFunc("POST /api/v1/sites", a.idempotent(a.handleCreateSite))
 	mux.HandleFunc("GET /api/v1/sites/statistics", a.handleGetSiteStatistics)
 	mux.HandleFunc("GET /api/v1/sites/{id}", a.handleGetSiteByID)
 	mux.HandleFunc("PATCH /api/v1/sites/{id}", a.handleUpdateSite)
 	mux.HandleFunc("DELETE /api/v1/sites/{id}", a.handleDeleteSiteByID)
-	mux.HandleFunc("POST /api/v1/import/csv", a.handleImportCSV)
-	mux.HandleFunc("POST /api/v1/subnets/{id}/ips", a.handleCreateIPBySubnetID)
+	mux.HandleFunc("GET /api/v1/sites/{id}/dhcp-config", a.handleGetSiteDHCPConfig)
+	mux.HandleFunc("POST /api/v1/import/csv", a.idempotent(a.handleImportCSV))
+	mux.HandleFunc("POST /api/v1/import/leases", a.idempotent(a.handleImportLeases))
+	mux.HandleFunc("POST /api/v1/import/zone", a.idempotent(a.handleImportZone))
+	mux.HandleFunc("POST /api/v1/subnets/{id}/ips", a.idempotent(a.handleCreateIPBySubnetID))
 	mux.HandleFunc("GET /api/v1/subnets/{id}/ips", a.handleGetIPsBySubnetID)
 	mux.HandleFunc("GET /api/v1/subnets/{id}/kubernetes-services", a.handleGetKubernetesServicesBySubnetID)
+	mux.HandleFunc("GET /api/v1/subnets/{id}/kubernetes-objects", a.handleGetKubernetesObjectsBySubnetID)
 	mux.HandleFunc("GET /api/v1/kubernetes/sources", a.handleGetKubernetesSources)
+	mux.HandleFunc("POST /api/v1/kubernetes/sources", a.handleCreateKubernetesSource)
+	mux.HandleFunc("PATCH /api/v1/kubernetes/sources/{key}", a.handleUpdateKubernetesSource)
+	mux.HandleFunc("DELETE /api/v1/kubernetes/sources/{key}", a.handleDeleteKubernetesSource)
+	mux.HandleFunc("POST /api/v1/kubernetes/sources/{key}/reconcile", a.handleReconcileKubernetesSource)
+	mux.HandleFunc("GET /api/v1/kubernetes/sources/{key}/runs", a.handleGetKubernetesSourceRuns)
+	mux.HandleFunc("GET /api/v1/kubernetes/sources/{key}/events", a.handleGetKubernetesSourceEvents)
 	mux.HandleFunc("GET /api/v1/reporting/settings", a.handleGetReportingSettings)
 	mux.HandleFunc("PATCH /api/v1/reporting/settings", a.handleUpdateReportingSettings)
 	mux.HandleFunc("GET /api/v1/subnets/{id}/usage-history", a.handleGetSubnetUsageHistory)
+	mux.HandleFunc("GET /api/v1/webhooks", a.handleGetWebhookSubscriptions)
+	mux.HandleFunc("POST /api/v1/webhooks", a.handleCreateWebhookSubscription)
+	mux.HandleFunc("GET /api/v1/webhooks/dead-letters", a.handleGetWebhookDeadLetters)
+	mux.HandleFunc("POST /api/v1/webhooks/deliveries/{id}/retry", a.handleRetryWebhookDelivery)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}", a.handleGetWebhookSubscription)
+	mux.HandleFunc("PATCH /api/v1/webhooks/{id}", a.handleUpdateWebhookSubscription)
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", a.handleDeleteWebhookSubscription)
+	mux.HandleFunc("GET /api/v1/events/stream", a.handleEventStream)
+	mux.HandleFunc("GET /api/v1/dns/zones", a.handleGetDNSZones)
+	mux.HandleFunc("POST /api/v1/dns/zones", a.handleCreateDNSZone)
+	mux.HandleFunc("GET /api/v1/dns/zones/{zone}", a.handleGetDNSZone)
+	mux.HandleFunc("DELETE /api/v1/dns/zones/{zone}", a.handleDeleteDNSZone)
+	mux.HandleFunc("GET /api/v1/dns/zones/{zone}/export", a.handleExportDNSZone)
 	mux.HandleFunc("PATCH /api/v1/subnets/{id}/ips/{uuid}", a.handleUpdateIPByUUID)
 	mux.HandleFunc("DELETE /api/v1/subnets/{id}/ips/{uuid}", a.handleDeleteIPByUUIDandSubnetID)
 
-	return a.corsMiddleware(a.authMiddleware(mux))
+	handler := a.corsMiddleware(a.authMiddleware(a.rateLimitMiddleware(routeSpanName(mux))))
+	return a.tracingMiddleware(requestIDMiddleware(a.metricsMiddleware(mux, handler)))
 }
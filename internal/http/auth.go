@@ -1,10 +1,13 @@
 package http
 
 import (
+	"context"
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
 	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	"go.opentelemetry.io/otel"
 )
 
 func (a *API) authMiddleware(next http.Handler) http.Handler {
@@ -18,21 +21,21 @@ func (a *API) authMiddleware(next http.Handler) http.Handler {
 		if a.Authenticator != nil {
 			authz := r.Header.Get("Authorization")
 			if authz == "" || !strings.HasPrefix(authz, "Bearer ") {
-				http.Error(w, "missing token", http.StatusUnauthorized)
+				a.writeUnauthorized(w, r, "missing token")
 				return
 			}
 
 			tokenStr := strings.TrimPrefix(authz, "Bearer ")
 			var err error
-			principal, err = a.Authenticator.Authenticate(r.Context(), tokenStr)
+			principal, err = a.authenticate(r.Context(), tokenStr)
 			if err != nil {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				a.writeUnauthorized(w, r, "invalid token")
 				return
 			}
 		}
 
 		if !authorized(principal, r.Method) {
-			http.Error(w, "forbidden", http.StatusForbidden)
+			a.writeProblem(w, r, http.StatusForbidden, "forbidden", nil)
 			return
 		}
 
@@ -41,8 +44,40 @@ func (a *API) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// metricsTokenMiddleware requires MetricsToken as the bearer token when it
+// is set. /metrics skips authMiddleware, so this is its only check.
+func (a *API) metricsTokenMiddleware(next http.Handler) http.Handler {
+	if a.MetricsToken == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(a.MetricsToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			a.writeProblem(w, r, http.StatusUnauthorized, "invalid metrics token", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *API) writeUnauthorized(w http.ResponseWriter, r *http.Request, detail string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	a.writeProblem(w, r, http.StatusUnauthorized, detail, nil)
+}
+
+func (a *API) authenticate(ctx context.Context, token string) (apiauth.Principal, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "auth.Authenticate")
+	defer span.End()
+	principal, err := a.Authenticator.Authenticate(ctx, token)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return principal, err
+}
+
 func isPublicPath(path string) bool {
-	return path == "/healthz" || path == "/readyz" || strings.HasPrefix(path, "/swagger/")
+	return path == "/healthz" || path == "/readyz" || path == "/metrics" || strings.HasPrefix(path, "/swagger/")
 }
 
 func authorized(principal apiauth.Principal, method string) bool {
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
@@ -91,8 +92,12 @@ func TestAuthMiddlewareRejectsMissingToken(t *testing.T) {
 	rec := httptest.NewRecorder()
 	handler.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusUnauthorized {
-		t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rec.Code)
+	problem := assertProblem(t, rec, http.StatusUnauthorized, "missing token")
+	if !strings.HasSuffix(problem.Type, "#unauthorized") {
+		t.Fatalf("expected unauthorized problem type, got %q", problem.Type)
+	}
+	if rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("expected bearer challenge, got %q", rec.Header().Get("WWW-Authenticate"))
 	}
 }
 
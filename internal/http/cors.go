@@ -20,7 +20,7 @@ func (a *API) corsMiddleware(next http.Handler) http.Handler {
 		}
 		if _, ok := allowed[origin]; !ok {
 			if r.Method == http.MethodOptions {
-				http.Error(w, "origin not allowed", http.StatusForbidden)
+				a.writeProblem(w, r, http.StatusForbidden, "origin not allowed", nil)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -29,7 +29,8 @@ func (a *API) corsMiddleware(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Idempotency-Key, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "Idempotent-Replayed, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-Request-ID")
 		w.Header().Add("Vary", "Origin")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
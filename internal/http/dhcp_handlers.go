@@ -0,0 +1,142 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// @Summary Update subnet DHCP settings
+// @Description Replaces the gateway, DNS servers and address pools rendered into DHCP configuration.
+// @Description The gateway and pools must lie inside the subnet, and pools may not overlap.
+// @Tags dhcp
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Subnet ID"
+// @Param payload body SubnetDHCPRequest true "DHCP settings"
+// @Success 200 {object} SubnetResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/subnets/{id}/dhcp [patch]
+func (a *API) handleUpdateSubnetDHCP(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDHCPService(w, r) {
+		return
+	}
+	_, id, _, done := parseID(w, r, a)
+	if done {
+		return
+	}
+	request, err := decode[SubnetDHCPRequest](r)
+	defer r.Body.Close()
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	subnet, err := a.DHCPService.UpdateSubnetSettings(r.Context(), request.toInput(id))
+	if err != nil {
+		a.writeDHCPError(w, r, "subnet not found", "updating subnet dhcp settings", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, subnetToResponse(subnet))
+}
+
+// @Summary Export subnet DHCP configuration
+// @Description Renders the subnet, its pools, gateway and DNS options and a host reservation for every address with a MAC address.
+// @Description The output is deterministic, so it can be committed and diffed.
+// @Tags dhcp
+// @Security BearerAuth
+// @Produce json,plain
+// @Param id path int true "Subnet ID"
+// @Param format query string false "kea (default) or dnsmasq"
+// @Success 200 {string} string "Kea JSON or dnsmasq configuration"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/subnets/{id}/dhcp-config [get]
+func (a *API) handleGetSubnetDHCPConfig(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDHCPService(w, r) {
+		return
+	}
+	_, id, _, done := parseID(w, r, a)
+	if done {
+		return
+	}
+	format, err := domain.ParseDHCPConfigFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	config, err := a.DHCPService.SubnetConfig(r.Context(), id, format)
+	if err != nil {
+		a.writeDHCPError(w, r, "subnet not found", "rendering subnet dhcp config", err)
+		return
+	}
+	a.writeDHCPConfig(w, r, format, config)
+}
+
+// @Summary Export site DHCP configuration
+// @Description Renders every subnet of the site, ordered by subnet ID.
+// @Tags dhcp
+// @Security BearerAuth
+// @Produce json,plain
+// @Param id path string true "Site ID"
+// @Param format query string false "kea (default) or dnsmasq"
+// @Success 200 {string} string "Kea JSON or dnsmasq configuration"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/sites/{id}/dhcp-config [get]
+func (a *API) handleGetSiteDHCPConfig(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDHCPService(w, r) {
+		return
+	}
+	id, err := parseSiteID(r)
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	format, err := domain.ParseDHCPConfigFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	config, err := a.DHCPService.SiteConfig(r.Context(), id, format)
+	if err != nil {
+		a.writeDHCPError(w, r, "site not found", "rendering site dhcp config", err)
+		return
+	}
+	a.writeDHCPConfig(w, r, format, config)
+}
+
+func (a *API) writeDHCPConfig(w http.ResponseWriter, r *http.Request, format domain.DHCPConfigFormat, config []byte) {
+	contentType := "application/json"
+	if format == domain.DHCPConfigDnsmasq {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(config); err != nil {
+		a.Logger.ErrorContext(r.Context(), "writing dhcp config", "format", format, "err", err)
+	}
+}
+
+func (a *API) requireDHCPService(w http.ResponseWriter, r *http.Request) bool {
+	if a.DHCPService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "dhcp service unavailable", nil)
+		return false
+	}
+	return true
+}
+
+func (a *API) writeDHCPError(w http.ResponseWriter, r *http.Request, notFound, operation string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, domain.ErrNotFound):
+		a.writeProblem(w, r, http.StatusNotFound, notFound, err)
+	default:
+		a.writeSiteError(w, r, http.StatusInternalServerError, "internal server error", operation, err)
+	}
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type dhcpServiceStub struct {
+	input     domain.UpdateSubnetDHCPInput
+	subnet    domain.Subnet
+	subnetID  int64
+	siteID    uuid.UUID
+	format    domain.DHCPConfigFormat
+	rendering []byte
+	err       error
+}
+
+func (s *dhcpServiceStub) UpdateSubnetSettings(_ context.Context, input domain.UpdateSubnetDHCPInput) (domain.Subnet, error) {
+	s.input = input
+	return s.subnet, s.err
+}
+
+func (s *dhcpServiceStub) SubnetConfig(_ context.Context, subnetID int64, format domain.DHCPConfigFormat) ([]byte, error) {
+	s.subnetID, s.format = subnetID, format
+	return s.rendering, s.err
+}
+
+func (s *dhcpServiceStub) SiteConfig(_ context.Context, siteID uuid.UUID, format domain.DHCPConfigFormat) ([]byte, error) {
+	s.siteID, s.format = siteID, format
+	return s.rendering, s.err
+}
+
+func newDHCPTestAPI(service domain.DHCPService) *API {
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	api.DHCPService = service
+	return api
+}
+
+func TestUpdateSubnetDHCPDecodesSettings(t *testing.T) {
+	service := &dhcpServiceStub{subnet: domain.Subnet{
+		ID:         7,
+		CIDR:       netip.MustParsePrefix("10.0.0.0/24"),
+		Gateway:    netip.MustParseAddr("10.0.0.1"),
+		DNSServers: []netip.Addr{netip.MustParseAddr("10.0.0.53")},
+		DHCPPools:  []domain.DHCPPool{{Start: netip.MustParseAddr("10.0.0.100"), End: netip.MustParseAddr("10.0.0.199")}},
+	}}
+	body := `{"gateway":"10.0.0.1","dns_servers":["10.0.0.53"],"pools":[{"start":"10.0.0.100","end":"10.0.0.199"}]}`
+	rec := httptest.NewRecorder()
+	newDHCPTestAPI(service).Router().ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/api/v1/subnets/7/dhcp", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := domain.UpdateSubnetDHCPInput{ID: 7, Gateway: "10.0.0.1", DNSServers: []string{"10.0.0.53"}, Pools: []domain.DHCPPoolInput{{Start: "10.0.0.100", End: "10.0.0.199"}}}
+	if fmt.Sprint(service.input) != fmt.Sprint(want) {
+		t.Fatalf("unexpected input: %+v", service.input)
+	}
+	var subnet SubnetResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &subnet); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if subnet.Gateway != "10.0.0.1" || len(subnet.DNSServers) != 1 || len(subnet.DHCPPools) != 1 || subnet.DHCPPools[0].End != "10.0.0.199" {
+		t.Fatalf("unexpected subnet: %+v", subnet)
+	}
+}
+
+func TestDHCPConfigRoutesSelectFormat(t *testing.T) {
+	siteID := uuid.New()
+	tests := []struct {
+		name        string
+		path        string
+		format      domain.DHCPConfigFormat
+		contentType string
+	}{
+		{name: "subnet default", path: "/api/v1/subnets/7/dhcp-config", format: domain.DHCPConfigKea, contentType: "application/json"},
+		{name: "subnet dnsmasq", path: "/api/v1/subnets/7/dhcp-config?format=dnsmasq", format: domain.DHCPConfigDnsmasq, contentType: "text/plain; charset=utf-8"},
+		{name: "site kea", path: "/api/v1/sites/" + siteID.String() + "/dhcp-config?format=kea", format: domain.DHCPConfigKea, contentType: "application/json"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &dhcpServiceStub{rendering: []byte("config\n")}
+			rec := httptest.NewRecorder()
+			newDHCPTestAPI(service).Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			if rec.Code != http.StatusOK || rec.Body.String() != "config\n" || rec.Header().Get("Content-Type") != test.contentType {
+				t.Fatalf("unexpected response: %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+			}
+			if service.format != test.format || (service.subnetID != 7 && service.siteID != siteID) {
+				t.Fatalf("unexpected request: %+v", service)
+			}
+		})
+	}
+}
+
+func TestDHCPRoutesMapErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		path   string
+		body   string
+		status int
+		detail string
+	}{
+		{name: "format", method: http.MethodGet, path: "/api/v1/subnets/7/dhcp-config?format=isc", status: http.StatusBadRequest, detail: "invalid input: format must be kea or dnsmasq"},
+		{name: "site id", method: http.MethodGet, path: "/api/v1/sites/nope/dhcp-config", status: http.StatusBadRequest, detail: "bad request"},
+		{name: "missing subnet", err: fmt.Errorf("%w: %w", domain.ErrNotFound, domain.ErrSubnetNotFound), method: http.MethodGet, path: "/api/v1/subnets/7/dhcp-config", status: http.StatusNotFound, detail: "subnet not found"},
+		{name: "missing site", err: fmt.Errorf("%w: site not found", domain.ErrNotFound), method: http.MethodGet, path: "/api/v1/sites/" + uuid.NewString() + "/dhcp-config", status: http.StatusNotFound, detail: "site not found"},
+		{name: "malformed", method: http.MethodPatch, path: "/api/v1/subnets/7/dhcp", body: `{`, status: http.StatusBadRequest, detail: "bad request"},
+		{name: "validation", err: domain.InvalidField("gateway", "gateway ip not in subnet"), method: http.MethodPatch, path: "/api/v1/subnets/7/dhcp", body: `{"gateway":"10.9.0.1"}`, status: http.StatusBadRequest, detail: "invalid input: gateway ip not in subnet"},
+		{name: "unexpected", err: errors.New("db down"), method: http.MethodGet, path: "/api/v1/subnets/7/dhcp-config", status: http.StatusInternalServerError, detail: "internal server error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newDHCPTestAPI(&dhcpServiceStub{err: test.err}).Router().ServeHTTP(rec, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+			assertProblem(t, rec, test.status, test.detail)
+		})
+	}
+}
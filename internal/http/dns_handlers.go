@@ -0,0 +1,160 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// zoneFileContentType is the media type for master files (RFC 4027).
+const zoneFileContentType = "text/dns"
+
+// @Summary List DNS zones
+// @Description Forward zones attached to sites or subnets, followed by the reverse zones computed from subnet prefixes.
+// @Tags dns
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} DNSZoneResponse
+// @Failure 500 {object} Problem
+// @Router /api/v1/dns/zones [get]
+func (a *API) handleGetDNSZones(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDNSService(w, r) {
+		return
+	}
+	zones, err := a.DNSService.ListZones(r.Context())
+	if err != nil {
+		a.writeDNSError(w, r, "listing dns zones", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, dnsZonesToResponse(zones))
+}
+
+// @Summary Create DNS zone
+// @Description Attaches a forward zone to a site or to a subnet; a subnet's own zone takes precedence over its site's.
+// @Description Hostnames in the zone's subnets must then be valid DNS names relative to the zone.
+// @Tags dns
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param zone body DNSZoneRequest true "DNS zone"
+// @Success 201 {object} DNSZoneResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/dns/zones [post]
+func (a *API) handleCreateDNSZone(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDNSService(w, r) {
+		return
+	}
+	request, err := decode[DNSZoneRequest](r)
+	defer r.Body.Close()
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	zone, err := a.DNSService.CreateZone(r.Context(), request.toInput())
+	if err != nil {
+		a.writeDNSError(w, r, "creating dns zone", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusCreated, dnsZoneToResponse(zone))
+}
+
+// @Summary Get DNS zone
+// @Tags dns
+// @Security BearerAuth
+// @Produce json
+// @Param zone path string true "Zone name, such as lab.example.com or 0.10.in-addr.arpa"
+// @Success 200 {object} DNSZoneResponse
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/dns/zones/{zone} [get]
+func (a *API) handleGetDNSZone(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDNSService(w, r) {
+		return
+	}
+	zone, err := a.DNSService.GetZone(r.Context(), r.PathValue("zone"))
+	if err != nil {
+		a.writeDNSError(w, r, "reading dns zone", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, dnsZoneToResponse(zone))
+}
+
+// @Summary Delete DNS zone
+// @Description Only forward zones can be deleted; reverse zones follow the subnets.
+// @Tags dns
+// @Security BearerAuth
+// @Param zone path string true "Zone name"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/dns/zones/{zone} [delete]
+func (a *API) handleDeleteDNSZone(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDNSService(w, r) {
+		return
+	}
+	deleted, err := a.DNSService.DeleteZone(r.Context(), r.PathValue("zone"))
+	if err != nil {
+		a.writeDNSError(w, r, "deleting dns zone", err)
+		return
+	}
+	if !deleted {
+		a.writeDNSError(w, r, "deleting dns zone", domain.ErrNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Export DNS zone file
+// @Description Renders the zone as an RFC 1035 master file with SOA, NS and A/AAAA or PTR records built from IP hostnames.
+// @Description The SOA serial (YYYYMMDDnn) only increases when the zone content changed since the previous export.
+// @Tags dns
+// @Security BearerAuth
+// @Produce text/dns
+// @Param zone path string true "Zone name"
+// @Success 200 {string} string "BIND zone file"
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/dns/zones/{zone}/export [get]
+func (a *API) handleExportDNSZone(w http.ResponseWriter, r *http.Request) {
+	if !a.requireDNSService(w, r) {
+		return
+	}
+	data, err := a.DNSService.ZoneData(r.Context(), r.PathValue("zone"))
+	if err != nil {
+		a.writeDNSError(w, r, "exporting dns zone", err)
+		return
+	}
+	w.Header().Set("Content-Type", zoneFileContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+strings.TrimSuffix(data.Zone.Name, ".")+`.zone"`)
+	w.Header().Set("X-DNS-Zone-Serial", strconv.FormatUint(uint64(data.SOA.Serial), 10))
+	if _, err := w.Write(data.ZoneFile()); err != nil {
+		a.Logger.ErrorContext(r.Context(), "writing zone file", "zone", data.Zone.Name, "err", err)
+	}
+}
+
+func (a *API) requireDNSService(w http.ResponseWriter, r *http.Request) bool {
+	if a.DNSService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "dns service unavailable", nil)
+		return false
+	}
+	return true
+}
+
+func (a *API) writeDNSError(w http.ResponseWriter, r *http.Request, operation string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, domain.ErrNotFound):
+		a.writeProblem(w, r, http.StatusNotFound, "dns zone not found", err)
+	case errors.Is(err, domain.ErrConflict):
+		a.writeProblem(w, r, http.StatusConflict, conflictDetail(err, "conflict"), err)
+	default:
+		a.writeSiteError(w, r, http.StatusInternalServerError, "internal server error", operation, err)
+	}
+}
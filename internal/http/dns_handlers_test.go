@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type dnsServiceStub struct {
+	domain.DNSService
+	zones       []domain.DNSZone
+	data        domain.DNSZoneData
+	createInput domain.CreateDNSZoneInput
+	requested   string
+	deleted     bool
+	err         error
+}
+
+func (s *dnsServiceStub) ListZones(context.Context) ([]domain.DNSZone, error) {
+	return s.zones, s.err
+}
+
+func (s *dnsServiceStub) GetZone(_ context.Context, name string) (domain.DNSZone, error) {
+	s.requested = name
+	return s.zones[0], s.err
+}
+
+func (s *dnsServiceStub) CreateZone(_ context.Context, input domain.CreateDNSZoneInput) (domain.DNSZone, error) {
+	s.createInput = input
+	return s.zones[0], s.err
+}
+
+func (s *dnsServiceStub) DeleteZone(_ context.Context, name string) (bool, error) {
+	s.requested = name
+	return s.deleted, s.err
+}
+
+func (s *dnsServiceStub) ZoneData(_ context.Context, name string) (domain.DNSZoneData, error) {
+	s.requested = name
+	return s.data, s.err
+}
+
+func newDNSTestAPI(service domain.DNSService) *API {
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	api.DNSService = service
+	return api
+}
+
+func TestDNSZoneRoutes(t *testing.T) {
+	siteID := uuid.New()
+	forward := domain.DNSZone{ID: uuid.New(), Name: "lab.example.com", Kind: domain.DNSZoneForward, SiteID: &siteID, TTL: 3600}
+	reverse := domain.DNSZone{Name: "2.1.10.in-addr.arpa", Kind: domain.DNSZoneReverse, Network: netip.MustParsePrefix("10.1.2.0/24"), TTL: 3600}
+	service := &dnsServiceStub{zones: []domain.DNSZone{forward, reverse}, deleted: true}
+	api := newDNSTestAPI(service)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dns/zones", nil))
+	var zones []DNSZoneResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &zones); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(zones) != 2 || zones[0].ID == nil || zones[0].Network != "" || zones[1].ID != nil || zones[1].Network != "10.1.2.0/24" {
+		t.Fatalf("unexpected zones: %+v", zones)
+	}
+
+	rec = httptest.NewRecorder()
+	body := fmt.Sprintf(`{"name":"lab.example.com","site_id":%q,"ttl":300}`, siteID)
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/dns/zones", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated || service.createInput.Name != "lab.example.com" || *service.createInput.SiteID != siteID || *service.createInput.TTL != 300 {
+		t.Fatalf("create: %d %s %+v", rec.Code, rec.Body.String(), service.createInput)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/dns/zones/lab.example.com.", nil))
+	if rec.Code != http.StatusNoContent || service.requested != "lab.example.com." {
+		t.Fatalf("delete: %d %q", rec.Code, service.requested)
+	}
+}
+
+func TestDNSZoneExportWritesZoneFile(t *testing.T) {
+	service := &dnsServiceStub{data: domain.DNSZoneData{
+		Zone:    domain.DNSZone{Name: "lab.example.com", Kind: domain.DNSZoneForward, TTL: 300},
+		SOA:     domain.DNSSOA{PrimaryNS: "ns1.example.com", Hostmaster: "hostmaster.example.com", Serial: 2026101803},
+		Records: []domain.DNSRecord{{Name: "web-1.lab.example.com", Type: domain.DNSRecordA, Data: "10.0.0.5"}},
+	}}
+	rec := httptest.NewRecorder()
+	newDNSTestAPI(service).Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dns/zones/lab.example.com/export", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != zoneFileContentType || rec.Header().Get("X-DNS-Zone-Serial") != "2026101803" ||
+		rec.Header().Get("Content-Disposition") != `attachment; filename="lab.example.com.zone"` {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+	if !strings.Contains(rec.Body.String(), "web-1\tIN\tA\t10.0.0.5\n") {
+		t.Fatalf("unexpected zone file:\n%s", rec.Body.String())
+	}
+}
+
+func TestDNSZoneRoutesMapErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		path   string
+		body   string
+		status int
+		detail string
+	}{
+		{name: "missing", err: fmt.Errorf("%w: dns zone not found", domain.ErrNotFound), method: http.MethodGet, path: "/api/v1/dns/zones/nope.example.com/export", status: http.StatusNotFound, detail: "dns zone not found"},
+		{name: "malformed", method: http.MethodPost, path: "/api/v1/dns/zones", body: `{`, status: http.StatusBadRequest, detail: "bad request"},
+		{name: "validation", err: domain.InvalidField("site_id", "set exactly one of site_id and subnet_id"), method: http.MethodPost, path: "/api/v1/dns/zones", body: `{"name":"lab.example.com"}`, status: http.StatusBadRequest, detail: "invalid input: set exactly one of site_id and subnet_id"},
+		{name: "conflict", err: fmt.Errorf("%w: site already has a dns zone", domain.ErrConflict), method: http.MethodPost, path: "/api/v1/dns/zones", body: `{"name":"lab.example.com"}`, status: http.StatusConflict, detail: "site already has a dns zone"},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/v1/dns/zones/lab.example.com", status: http.StatusNotFound, detail: "dns zone not found"},
+		{name: "unexpected", err: errors.New("db down"), method: http.MethodGet, path: "/api/v1/dns/zones", status: http.StatusInternalServerError, detail: "internal server error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &dnsServiceStub{zones: []domain.DNSZone{{}}, err: test.err}
+			rec := httptest.NewRecorder()
+			newDNSTestAPI(service).Router().ServeHTTP(rec, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+			assertProblem(t, rec, test.status, test.detail)
+		})
+	}
+}
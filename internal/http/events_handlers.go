@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+// eventStreamHeartbeat keeps idle streams alive through proxies that close
+// silent connections.
+var eventStreamHeartbeat = 20 * time.Second
+
+// @Summary Stream live changes
+// @Description Server-sent events for subnet, IP, site, Kubernetes reconcile, reporting snapshot and DNS zone changes.
+// @Description Each frame carries the event type in the event field and a ChangeEventResponse as data.
+// @Description Events without a site, such as reporting snapshots, are sent to every site filter.
+// @Tags events
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param types query string false "Comma-separated object types: subnet, ip, site, kubernetes, reporting, dns_zone"
+// @Param site_id query string false "Only events for this site"
+// @Success 200 {object} ChangeEventResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/events/stream [get]
+func (a *API) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	if a.EventStream == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "event stream unavailable", nil)
+		return
+	}
+	var siteID *uuid.UUID
+	if raw := r.URL.Query().Get("site_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			a.writeProblem(w, r, http.StatusBadRequest, "site_id must be a UUID", nil)
+			return
+		}
+		siteID = &parsed
+	}
+	var objectTypes []string
+	if raw := r.URL.Query().Get("types"); raw != "" {
+		objectTypes = strings.Split(raw, ",")
+	}
+	filter, err := domain.NewEventFilter(objectTypes, siteID)
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Streams outlive the server's write timeout.
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		a.Logger.ErrorContext(r.Context(), "clearing event stream write deadline", "err", err)
+		return
+	}
+
+	events := a.EventStream.Subscribe(r.Context(), filter)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, "retry: 5000\n\n"); err != nil || controller.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEventFrame(w, event); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEventFrame(w http.ResponseWriter, event domain.ChangeEvent) error {
+	data, err := json.Marshal(changeEventToResponse(event))
+	if err != nil {
+		return err
+	}
+	if event.ID != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
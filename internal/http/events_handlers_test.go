@@ -0,0 +1,161 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type eventStreamStub struct {
+	events     chan domain.ChangeEvent
+	subscribed chan domain.EventFilter
+}
+
+func newEventStreamStub() *eventStreamStub {
+	return &eventStreamStub{events: make(chan domain.ChangeEvent, 4), subscribed: make(chan domain.EventFilter, 1)}
+}
+
+func (s *eventStreamStub) Subscribe(_ context.Context, filter domain.EventFilter) <-chan domain.ChangeEvent {
+	s.subscribed <- filter
+	return s.events
+}
+
+func readEventFrame(t *testing.T, reader *bufio.Reader) map[string]string {
+	t.Helper()
+	frame := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(frame) > 0 {
+				return frame
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		frame[field] = value
+	}
+}
+
+func TestEventStreamWritesFilteredFrames(t *testing.T) {
+	stream := newEventStreamStub()
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	api.EventStream = stream
+	server := httptest.NewServer(api.Router())
+	defer server.Close()
+
+	siteID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	response, err := http.Get(server.URL + "/api/v1/events/stream?types=ip,subnet&site_id=" + siteID.String())
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response: %d %q", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	filter := <-stream.subscribed
+	if len(filter.ObjectTypes) != 2 || filter.SiteID == nil || *filter.SiteID != siteID {
+		t.Fatalf("unexpected filter: %+v", filter)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	if frame := readEventFrame(t, reader); frame["retry"] != "5000" {
+		t.Fatalf("expected retry preamble, got %v", frame)
+	}
+	subnetID := int64(7)
+	stream.events <- domain.ChangeEvent{
+		ID: 42, Type: domain.EventIPCreated, ObjectType: domain.ObjectTypeIP, ObjectID: "0b7c",
+		SiteID: &siteID, SubnetID: &subnetID, OccurredAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+	frame := readEventFrame(t, reader)
+	if frame["id"] != "42" || frame["event"] != domain.EventIPCreated {
+		t.Fatalf("unexpected frame: %v", frame)
+	}
+	var data ChangeEventResponse
+	if err := json.Unmarshal([]byte(frame["data"]), &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if data.ObjectID != "0b7c" || data.SubnetID == nil || *data.SubnetID != 7 {
+		t.Fatalf("unexpected data: %+v", data)
+	}
+}
+
+func TestEventStreamSendsHeartbeats(t *testing.T) {
+	previous := eventStreamHeartbeat
+	eventStreamHeartbeat = 10 * time.Millisecond
+	defer func() { eventStreamHeartbeat = previous }()
+
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	api.EventStream = newEventStreamStub()
+	server := httptest.NewServer(api.Router())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/api/v1/events/stream")
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readEventFrame(t, reader)
+	line, err := reader.ReadString('\n')
+	if err != nil || line != ": ping\n" {
+		t.Fatalf("expected heartbeat comment, got %q err=%v", line, err)
+	}
+}
+
+func TestEventStreamRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  EventStream
+		path    string
+		status  int
+		message string
+	}{
+		{name: "unknown type", stream: newEventStreamStub(), path: "/api/v1/events/stream?types=vlan", status: http.StatusBadRequest, message: "invalid input: unsupported event object type \"vlan\""},
+		{name: "bad site", stream: newEventStreamStub(), path: "/api/v1/events/stream?site_id=nope", status: http.StatusBadRequest, message: "site_id must be a UUID"},
+		{name: "unavailable", path: "/api/v1/events/stream", status: http.StatusInternalServerError, message: "event stream unavailable"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+			api.EventStream = test.stream
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+			assertProblem(t, rec, test.status, test.message)
+		})
+	}
+}
+
+func TestEventStreamRequiresReadPermission(t *testing.T) {
+	api := newTestAPI()
+	api.Authenticator = stubAuthenticator{principal: apiauth.Principal{}}
+	api.EventStream = newEventStreamStub()
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events/stream", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d without token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream", nil)
+	request.Header.Set("Authorization", "Bearer valid-token")
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, request)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected %d without a role, got %d", http.StatusForbidden, rec.Code)
+	}
+}
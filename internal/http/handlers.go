@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
 )
@@ -17,49 +19,161 @@ const maxCSVMultipartOverhead int64 = 1 << 20
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "CSV file with site,cidr,ip,description columns"
+// @Param Idempotency-Key header string false "Replays the stored response for a repeated key"
 // @Success 200 {object} ImportResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/import/csv [post]
 func (a *API) handleImportCSV(w http.ResponseWriter, r *http.Request) {
 	if a.ImportService == nil {
-		_ = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "import service unavailable"})
+		a.writeProblem(w, r, http.StatusInternalServerError, "import service unavailable", nil)
 		return
 	}
-	maxRequestBytes := domain.MaxCSVImportBytes + maxCSVMultipartOverhead
-	if r.ContentLength > maxRequestBytes {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "csv file exceeds maximum size"})
+	file, ok := a.importFile(w, r, domain.MaxCSVImportBytes, "csv file exceeds maximum size")
+	if !ok {
 		return
 	}
-	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
-	if err := r.ParseMultipartForm(1 << 20); err != nil {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "multipart file is required"})
+	defer file.Close()
+	result, err := a.ImportService.ImportCSV(r.Context(), file)
+	if err != nil {
+		a.writeImportError(w, r, err)
 		return
 	}
-	file, header, err := r.FormFile("file")
+	_ = encode(w, r, http.StatusOK, importResultToResponse(result))
+}
+
+// @Summary Import addresses from a DHCP lease file
+// @Description Matches every active lease to the most specific subnet containing its address and creates or updates the address with the lease's hostname, MAC address and last-seen time.
+// @Description Only the last entry for an address counts. Existing hostnames are kept. With dry_run=true nothing is written.
+// @Tags import
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "ISC dhcpd.leases, Kea memfile CSV or dnsmasq lease file"
+// @Param format query string true "isc, kea or dnsmasq"
+// @Param dry_run query bool false "Report the result without writing"
+// @Param Idempotency-Key header string false "Replays the stored response for a repeated key"
+// @Success 200 {object} ImportResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/import/leases [post]
+func (a *API) handleImportLeases(w http.ResponseWriter, r *http.Request) {
+	if a.LeaseImportService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "lease import service unavailable", nil)
+		return
+	}
+	format, err := domain.ParseLeaseFileFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	dryRun, err := parseDryRun(r)
 	if err != nil {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "multipart file is required"})
+		a.writeProblem(w, r, http.StatusBadRequest, "dry_run must be true or false", nil)
+		return
+	}
+	file, ok := a.importFile(w, r, domain.MaxLeaseImportBytes, "lease file exceeds maximum size")
+	if !ok {
 		return
 	}
 	defer file.Close()
-	if header.Size > domain.MaxCSVImportBytes {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "csv file exceeds maximum size"})
+	result, err := a.LeaseImportService.ImportLeases(r.Context(), domain.LeaseImportInput{Format: format, File: file, DryRun: dryRun})
+	if err != nil {
+		a.writeImportError(w, r, err)
 		return
 	}
-	result, err := a.ImportService.ImportCSV(r.Context(), file)
+	_ = encode(w, r, http.StatusOK, importResultToResponse(result))
+}
+
+// @Summary Import hostnames from a BIND zone file
+// @Description Names addresses after the A, AAAA and PTR records of a forward or reverse zone file. Each address is created in the most specific subnet containing it; existing hostnames are kept.
+// @Description Records that match no subnet or cannot be read are reported by line. With dry_run=true nothing is written.
+// @Tags import
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "BIND zone file"
+// @Param origin query string false "Zone name for relative names when the file has no $ORIGIN"
+// @Param dry_run query bool false "Report the result without writing"
+// @Param Idempotency-Key header string false "Replays the stored response for a repeated key"
+// @Success 200 {object} ImportResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/import/zone [post]
+func (a *API) handleImportZone(w http.ResponseWriter, r *http.Request) {
+	if a.ZoneImportService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "zone import service unavailable", nil)
+		return
+	}
+	dryRun, err := parseDryRun(r)
 	if err != nil {
-		status := http.StatusInternalServerError
-		response := ErrorResponse{Error: "internal server error"}
-		if errors.Is(err, domain.ErrInvalidInput) {
-			status = http.StatusBadRequest
-			response = ErrorResponse{Error: err.Error()}
-		}
-		_ = encode(w, r, status, response)
+		a.writeProblem(w, r, http.StatusBadRequest, "dry_run must be true or false", nil)
+		return
+	}
+	file, ok := a.importFile(w, r, domain.MaxZoneImportBytes, "zone file exceeds maximum size")
+	if !ok {
+		return
+	}
+	defer file.Close()
+	input := domain.ZoneImportInput{Origin: r.URL.Query().Get("origin"), File: file, DryRun: dryRun}
+	result, err := a.ZoneImportService.ImportZone(r.Context(), input)
+	if err != nil {
+		a.writeImportError(w, r, err)
 		return
 	}
 	_ = encode(w, r, http.StatusOK, importResultToResponse(result))
 }
 
+func parseDryRun(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("dry_run")
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// importFile returns the "file" part of a multipart upload of at most
+// maxBytes, or writes a problem and returns false.
+func (a *API) importFile(w http.ResponseWriter, r *http.Request, maxBytes int64, tooLarge string) (multipart.File, bool) {
+	maxRequestBytes := maxBytes + maxCSVMultipartOverhead
+	if r.ContentLength > maxRequestBytes {
+		a.writeProblem(w, r, http.StatusBadRequest, tooLarge, nil)
+		return nil, false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "multipart file is required", nil)
+		return nil, false
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "multipart file is required", nil)
+		return nil, false
+	}
+	if header.Size > maxBytes {
+		file.Close()
+		a.writeProblem(w, r, http.StatusBadRequest, tooLarge, nil)
+		return nil, false
+	}
+	return file, true
+}
+
+func (a *API) writeImportError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	detail := "internal server error"
+	if errors.Is(err, domain.ErrInvalidInput) {
+		status = http.StatusBadRequest
+		detail = err.Error()
+	}
+	a.writeProblem(w, r, status, detail, err)
+}
+
 // @Summary Health check
 // @Tags health
 // @Success 200 {string} string "ok"
@@ -72,13 +186,13 @@ func (a *API) handleHealthz(w http.ResponseWriter, _ *http.Request) {
 // @Summary Readiness check
 // @Tags health
 // @Success 200 {string} string "ready"
-// @Failure 503 {string} string "db unavailable"
+// @Failure 503 {object} Problem
 // @Router /readyz [get]
 func (a *API) handleReadyz(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if err := a.Health.Ping(ctx); err != nil {
 		a.Logger.Error("db ping failed", "err", err)
-		http.Error(w, "db unavailable", http.StatusServiceUnavailable)
+		a.writeProblem(w, r, http.StatusServiceUnavailable, "db unavailable", nil)
 		return
 	}
 
@@ -91,17 +205,14 @@ func (a *API) handleReadyz(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} SubnetResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets [get]
 func (a *API) handleGetAllSubnets(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	subnets, err := a.NetService.ListSubnets(ctx)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "reading subnets", "err", err.Error())
-		err = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "couldn't encode response", "err", err)
-		}
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		return
 	}
 	err = encode(w, r, http.StatusOK, subnetsToResponse(subnets))
@@ -116,9 +227,12 @@ func (a *API) handleGetAllSubnets(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param subnet body CreateSubnetRequest true "Subnet payload"
+// @Param Idempotency-Key header string false "Replays the stored response for a repeated key"
 // @Success 201 {object} SubnetResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets [post]
 func (a *API) handleCreateSubnet(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -131,34 +245,28 @@ func (a *API) handleCreateSubnet(w http.ResponseWriter, r *http.Request) {
 	}(r.Body)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "unmarshaling subnet from request", "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "responding to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 	if subnetReq.SiteID == nil {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "site_id is required"})
+		a.writeProblem(w, r, http.StatusBadRequest, "site_id is required", domain.InvalidField("site_id", "site_id is required"))
 		return
 	}
 
 	respSubnet, err := a.NetService.CreateSubnet(ctx, subnetReq.toInput())
 	if err != nil {
 		status := http.StatusInternalServerError
-		resp := ErrorResponse{Error: "internal server error while saving subnet to db"}
+		detail := "internal server error while saving subnet to db"
 		if errors.Is(err, domain.ErrInvalidInput) {
 			status = http.StatusBadRequest
-			resp = ErrorResponse{Error: "invalid cidr"}
+			detail = err.Error()
 		} else if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			resp = ErrorResponse{Error: "site not found"}
+			detail = "site not found"
 		}
 
 		a.Logger.ErrorContext(ctx, "creating subnet", "err", err.Error(), "cidr", subnetReq.CIDR)
-		err = encode(w, r, status, resp)
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "responding to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 	err = encode(w, r, http.StatusCreated, subnetToResponse(respSubnet))
@@ -175,9 +283,9 @@ func (a *API) handleCreateSubnet(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Subnet ID"
 // @Param site body AssignSubnetSiteRequest true "Site assignment"
 // @Success 200 {object} SubnetResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/site [patch]
 func (a *API) handleAssignSubnetSite(w http.ResponseWriter, r *http.Request) {
 	ctx, id, _, done := parseID(w, r, a)
@@ -187,22 +295,22 @@ func (a *API) handleAssignSubnetSite(w http.ResponseWriter, r *http.Request) {
 	request, err := decode[AssignSubnetSiteRequest](r)
 	defer r.Body.Close()
 	if err != nil || request.SiteID == nil {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "site_id is required"})
+		a.writeProblem(w, r, http.StatusBadRequest, "site_id is required", nil)
 		return
 	}
 
 	subnet, err := a.NetService.AssignSubnetSite(ctx, domain.AssignSubnetSiteInput{ID: id, SiteID: *request.SiteID})
 	if err != nil {
 		status := http.StatusInternalServerError
-		response := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrInvalidInput) {
 			status = http.StatusBadRequest
-			response = ErrorResponse{Error: "bad request"}
+			detail = "bad request"
 		} else if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			response = ErrorResponse{Error: "subnet or site not found"}
+			detail = "subnet or site not found"
 		}
-		_ = encode(w, r, status, response)
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 	_ = encode(w, r, http.StatusOK, subnetToResponse(subnet))
@@ -214,9 +322,9 @@ func (a *API) handleAssignSubnetSite(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Subnet ID"
 // @Success 200 {object} SubnetResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id} [get]
 func (a *API) handleGetSubnetByID(w http.ResponseWriter, r *http.Request) {
 	ctx, id, err, done := parseID(w, r, a)
@@ -228,15 +336,12 @@ func (a *API) handleGetSubnetByID(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "failed to get subnet by id", "id", id, "err", err.Error())
 		status := http.StatusInternalServerError
-		resp := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			resp = ErrorResponse{Error: "subnet not found"}
-		}
-		err = encode(w, r, status, resp)
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "responding to client", "err", err.Error())
+			detail = "subnet not found"
 		}
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 
@@ -254,9 +359,9 @@ func (a *API) handleGetSubnetByID(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Subnet ID"
 // @Param subnet body CreateSubnetRequest true "Subnet payload"
 // @Success 200 {object} SubnetResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id} [patch]
 func (a *API) handleUpdateSubnet(w http.ResponseWriter, r *http.Request) {
 	ctx, id, err, done := parseID(w, r, a)
@@ -268,7 +373,7 @@ func (a *API) handleUpdateSubnet(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "unmarshaling subnet from request", "err", err.Error())
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 
@@ -280,16 +385,16 @@ func (a *API) handleUpdateSubnet(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		status := http.StatusInternalServerError
-		response := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrInvalidInput) {
 			status = http.StatusBadRequest
-			response = ErrorResponse{Error: "invalid cidr"}
+			detail = err.Error()
 		} else if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			response = ErrorResponse{Error: "subnet not found"}
+			detail = "subnet not found"
 		}
 		a.Logger.ErrorContext(ctx, "updating subnet", "id", id, "err", err.Error())
-		_ = encode(w, r, status, response)
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 
@@ -303,10 +408,13 @@ func (a *API) handleUpdateSubnet(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Subnet id in which the ip is created."
 // @Param payload body CreateIPRequest true "IP address to create."
+// @Param Idempotency-Key header string false "Replays the stored response for a repeated key"
 // @Success 201 {object} IPResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/ips [post]
 func (a *API) handleCreateIPBySubnetID(w http.ResponseWriter, r *http.Request) {
 	ctx, id, err, done := parseID(w, r, a)
@@ -323,10 +431,7 @@ func (a *API) handleCreateIPBySubnetID(w http.ResponseWriter, r *http.Request) {
 	}(r.Body)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "unmarshaling ip from request", "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 
@@ -334,33 +439,21 @@ func (a *API) handleCreateIPBySubnetID(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, domain.ErrConflict) {
 			a.Logger.DebugContext(ctx, "tried to enter duplicate ip", "ip", ipReq.IP, "err", err.Error())
-			err := encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request, ip exists"})
-			if err != nil {
-				a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-			}
+			a.writeProblem(w, r, http.StatusConflict, conflictDetail(err, "ip already exists"), err)
 			return
 		}
 		if errors.Is(err, domain.ErrNotFound) {
 			a.Logger.InfoContext(ctx, "subnet doesn't exist for given id", "id", id)
-			err := encode(w, r, http.StatusNotFound, ErrorResponse{Error: "subnet not found"})
-			if err != nil {
-				a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-			}
+			a.writeProblem(w, r, http.StatusNotFound, "subnet not found", err)
 			return
 		}
 		if errors.Is(err, domain.ErrInvalidInput) {
 			a.Logger.DebugContext(ctx, "invalid ip request", "ip", ipReq.IP, "err", err.Error())
-			err := encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-			if err != nil {
-				a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-			}
+			a.writeProblem(w, r, http.StatusBadRequest, "bad request", err)
 			return
 		}
 		a.Logger.ErrorContext(ctx, "cant create IP address", "err", err, "ip", ipReq.IP)
-		err := encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error while creating ip"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error while creating ip", nil)
 		return
 	}
 	a.Logger.DebugContext(ctx, "ip created", "ip", respIP)
@@ -377,9 +470,9 @@ func (a *API) handleCreateIPBySubnetID(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Subnet ID"
 // @Success 200 {array} IPResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/ips [get]
 func (a *API) handleGetIPsBySubnetID(w http.ResponseWriter, r *http.Request) {
 	ctx, id, err, done := parseID(w, r, a)
@@ -390,16 +483,13 @@ func (a *API) handleGetIPsBySubnetID(w http.ResponseWriter, r *http.Request) {
 	respIPs, err := a.NetService.ListIPs(ctx, id)
 	if err != nil {
 		status := http.StatusInternalServerError
-		resp := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			resp = ErrorResponse{Error: "subnet not found"}
+			detail = "subnet not found"
 		}
 		a.Logger.ErrorContext(ctx, "can't list ips by subnet id", "id", id, "err", err.Error())
-		err := encode(w, r, status, resp)
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 
@@ -418,9 +508,10 @@ func (a *API) handleGetIPsBySubnetID(w http.ResponseWriter, r *http.Request) {
 // @Param uuid path string true "UUID of the ip to be updated."
 // @Param payload body UpdateIPRequest true "IP address to update"
 // @Success 200 {object} IPResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/ips/{uuid} [patch]
 func (a *API) handleUpdateIPByUUID(w http.ResponseWriter, r *http.Request) {
 	ctx, id, err, done := parseID(w, r, a)
@@ -432,43 +523,38 @@ func (a *API) handleUpdateIPByUUID(w http.ResponseWriter, r *http.Request) {
 	reqID, err := parseIPAddressID(strUUID)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "invalid uuid", "uuid", strUUID, "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 
 	reqHostname, err := decode[UpdateIPRequest](r)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "can't unmarshal hostname in request", "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 
 	respIP, err := a.NetService.UpdateIPHostname(ctx, id, reqID, reqHostname.toInput())
 	if err != nil {
 		status := http.StatusInternalServerError
-		resp := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrSubnetNotFound) {
 			status = http.StatusNotFound
-			resp = ErrorResponse{Error: "subnet not found"}
+			detail = "subnet not found"
 		} else if errors.Is(err, domain.ErrIPNotFound) || errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			resp = ErrorResponse{Error: "ip not found"}
+			detail = "ip not found"
 		}
 		if errors.Is(err, domain.ErrInvalidInput) {
 			status = http.StatusBadRequest
-			resp = ErrorResponse{Error: "bad request"}
+			detail = "bad request"
 		}
-		a.Logger.ErrorContext(ctx, "failed to update IP with given UUID", "uuid", string(reqID), "err", err.Error())
-		err = encode(w, r, status, resp)
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
+		if errors.Is(err, domain.ErrConflict) {
+			status = http.StatusConflict
+			detail = conflictDetail(err, "conflict")
 		}
+		a.Logger.ErrorContext(ctx, "failed to update IP with given UUID", "uuid", string(reqID), "err", err.Error())
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 
@@ -486,9 +572,9 @@ func (a *API) handleUpdateIPByUUID(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Subnet id in which the ip is deleted."
 // @Param uuid path string true "UUID of the ip to be deleted."
 // @Success 204 "No content"
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/ips/{uuid} [delete]
 func (a *API) handleDeleteIPByUUIDandSubnetID(w http.ResponseWriter, r *http.Request) {
 	ctx, id, err, done := parseID(w, r, a)
@@ -500,10 +586,7 @@ func (a *API) handleDeleteIPByUUIDandSubnetID(w http.ResponseWriter, r *http.Req
 	reqID, err := parseIPAddressID(strUUID)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "invalid uuid", "uuid", strUUID, "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 
@@ -511,24 +594,15 @@ func (a *API) handleDeleteIPByUUIDandSubnetID(w http.ResponseWriter, r *http.Req
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			a.Logger.DebugContext(ctx, "subnet id or ip uuid not found", "id", id, "uuid", string(reqID), "err", err.Error())
-			err = encode(w, r, http.StatusNotFound, ErrorResponse{Error: "subnet or ip not found"})
-			if err != nil {
-				a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-			}
+			a.writeProblem(w, r, http.StatusNotFound, "subnet or ip not found", err)
 			return
 		}
 		if errors.Is(err, domain.ErrInvalidInput) {
-			err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-			if err != nil {
-				a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-			}
+			a.writeProblem(w, r, http.StatusBadRequest, "bad request", err)
 			return
 		}
 		a.Logger.ErrorContext(ctx, "uncaught error while deleting for ip", "id", id, "uuid", string(reqID), "err", err.Error())
-		err = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		return
 	}
 
@@ -541,10 +615,7 @@ func parseID(w http.ResponseWriter, r *http.Request, a *API) (context.Context, i
 	id, err := parsePathInt64(r, "id")
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "unable to convert string id to int64", "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return nil, 0, nil, true
 	}
 	return ctx, id, err, false
@@ -555,35 +626,29 @@ func parseID(w http.ResponseWriter, r *http.Request, a *API) (context.Context, i
 // @Security BearerAuth
 // @Param id path int true "Subnet ID of the subnet to delete."
 // @Success 204 "No content"
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id} [delete]
 func (a *API) handleDeleteSubnetByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id, err := parsePathInt64(r, "id")
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "unable to convert string id to int64", "err", err.Error())
-		err = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 
 	err = a.NetService.DeleteSubnet(ctx, id)
 	if err != nil {
 		status := http.StatusInternalServerError
-		resp := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			resp = ErrorResponse{Error: "subnet not found"}
+			detail = "subnet not found"
 		}
 		a.Logger.ErrorContext(ctx, "failed to delete subnet", "id", id, "err", err.Error())
-		err = encode(w, r, status, resp)
-		if err != nil {
-			a.Logger.ErrorContext(ctx, "cant respond to client", "err", err.Error())
-		}
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 
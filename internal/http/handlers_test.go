@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -140,21 +141,28 @@ func mustAddr(t *testing.T, addr string) netip.Addr {
 	return ip
 }
 
-func assertJSONError(t *testing.T, rec *httptest.ResponseRecorder, wantStatus int, wantErr string) {
+func assertProblem(t *testing.T, rec *httptest.ResponseRecorder, wantStatus int, wantDetail string) Problem {
 	t.Helper()
 
 	if rec.Code != wantStatus {
 		t.Fatalf("expected %d, got %d", wantStatus, rec.Code)
 	}
+	if got := rec.Header().Get("Content-Type"); got != ProblemContentType {
+		t.Fatalf("expected content type %q, got %q", ProblemContentType, got)
+	}
 
-	var resp ErrorResponse
-	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
-		t.Fatalf("decode error response: %v", err)
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem response: %v", err)
 	}
 
-	if resp.Error != wantErr {
-		t.Fatalf("expected error %q, got %q", wantErr, resp.Error)
+	if problem.Status != wantStatus {
+		t.Fatalf("expected problem status %d, got %d", wantStatus, problem.Status)
 	}
+	if problem.Detail != wantDetail {
+		t.Fatalf("expected detail %q, got %q", wantDetail, problem.Detail)
+	}
+	return problem
 }
 
 func TestGetAllSubnetsReturnsJSONPayload(t *testing.T) {
@@ -205,7 +213,7 @@ func TestGetAllSubnetsReturnsInternalServerError(t *testing.T) {
 	rec := httptest.NewRecorder()
 	api.Router().ServeHTTP(rec, req)
 
-	assertJSONError(t, rec, http.StatusInternalServerError, "internal server error")
+	assertProblem(t, rec, http.StatusInternalServerError, "internal server error")
 }
 
 func TestReadyzReturnsServiceUnavailableWhenHealthCheckFails(t *testing.T) {
@@ -274,7 +282,7 @@ func TestGetSubnetByIDCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -314,7 +322,7 @@ func TestGetSubnetByIDReturnsJSONPayload(t *testing.T) {
 func TestCreateSubnetReturnsBadRequestOnInvalidInput(t *testing.T) {
 	api := newHandlerTestAPI(stubService{
 		createSubnetFn: func(context.Context, domain.CreateSubnetInput) (domain.Subnet, error) {
-			return domain.Subnet{}, domain.ErrInvalidInput
+			return domain.Subnet{}, domain.InvalidField("cidr", "invalid cidr")
 		},
 	}, nil)
 
@@ -322,11 +330,12 @@ func TestCreateSubnetReturnsBadRequestOnInvalidInput(t *testing.T) {
 	rec := httptest.NewRecorder()
 	api.Router().ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rec.Code)
+	problem := assertProblem(t, rec, http.StatusBadRequest, "invalid input: invalid cidr")
+	if !strings.HasSuffix(problem.Type, "#validation-failed") {
+		t.Fatalf("expected validation-failed type, got %q", problem.Type)
 	}
-	if !strings.Contains(rec.Body.String(), "invalid cidr") {
-		t.Fatalf("expected invalid cidr body, got %q", rec.Body.String())
+	if len(problem.Errors) != 1 || problem.Errors[0] != (ProblemFieldError{Field: "cidr", Message: "invalid cidr"}) {
+		t.Fatalf("unexpected field errors: %+v", problem.Errors)
 	}
 }
 
@@ -365,7 +374,7 @@ func TestCreateSubnetCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -415,7 +424,7 @@ func TestCreateSubnetRequiresSiteID(t *testing.T) {
 	rec := httptest.NewRecorder()
 	api.Router().ServeHTTP(rec, req)
 
-	assertJSONError(t, rec, http.StatusBadRequest, "site_id is required")
+	assertProblem(t, rec, http.StatusBadRequest, "site_id is required")
 	if called {
 		t.Fatal("expected missing site id to stop before service call")
 	}
@@ -445,10 +454,10 @@ func TestAssignSubnetSite(t *testing.T) {
 	req = httptest.NewRequest(http.MethodPatch, "/api/v1/subnets/42/site", strings.NewReader(`{"site_id":null}`))
 	rec = httptest.NewRecorder()
 	api.Router().ServeHTTP(rec, req)
-	assertJSONError(t, rec, http.StatusBadRequest, "site_id is required")
+	assertProblem(t, rec, http.StatusBadRequest, "site_id is required")
 }
 
-func TestCreateIPReturnsConflictAsBadRequest(t *testing.T) {
+func TestCreateIPReturnsConflictProblem(t *testing.T) {
 	api := newHandlerTestAPI(stubService{
 		createIPFn: func(context.Context, int64, domain.CreateIPInput) (domain.IPAddress, error) {
 			return domain.IPAddress{}, domain.ErrConflict
@@ -459,11 +468,9 @@ func TestCreateIPReturnsConflictAsBadRequest(t *testing.T) {
 	rec := httptest.NewRecorder()
 	api.Router().ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rec.Code)
-	}
-	if !strings.Contains(rec.Body.String(), "ip exists") {
-		t.Fatalf("expected ip exists body, got %q", rec.Body.String())
+	problem := assertProblem(t, rec, http.StatusConflict, "ip already exists")
+	if !strings.HasSuffix(problem.Type, "#conflict") {
+		t.Fatalf("expected conflict type, got %q", problem.Type)
 	}
 }
 
@@ -528,7 +535,7 @@ func TestGetIPsBySubnetIDCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -653,7 +660,7 @@ func TestCreateIPBySubnetIDCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -737,6 +744,14 @@ func TestUpdateIPByUUIDCoversRequestAndErrorMapping(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantErr:    "bad request",
 		},
+		{
+			name:       "mac already reserved",
+			target:     "/api/v1/subnets/42/ips/550e8400-e29b-41d4-a716-446655440000",
+			body:       `{"hostname":"h","mac_address":"52:54:00:12:34:56"}`,
+			serviceErr: fmt.Errorf("%w: mac address already reserved in subnet", domain.ErrConflict),
+			wantStatus: http.StatusConflict,
+			wantErr:    "mac address already reserved in subnet",
+		},
 		{
 			name:       "internal error",
 			target:     "/api/v1/subnets/42/ips/550e8400-e29b-41d4-a716-446655440000",
@@ -759,7 +774,7 @@ func TestUpdateIPByUUIDCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -852,7 +867,7 @@ func TestDeleteIPByUUIDAndSubnetIDCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -918,7 +933,7 @@ func TestDeleteSubnetByIDCoversRequestAndErrorMapping(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, tc.wantStatus, tc.wantErr)
+			assertProblem(t, rec, tc.wantStatus, tc.wantErr)
 		})
 	}
 }
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotentRequestBytes = domain.MaxCSVImportBytes + maxCSVMultipartOverhead
+)
+
+// idempotent replays the stored response for a repeated Idempotency-Key
+// instead of running next again. Requests without the header, or APIs
+// without an IdempotencyService, pass straight through. Server errors
+// release the key so the client's retry runs the request again.
+func (a *API) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || a.IdempotencyService == nil {
+			next(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		_ = r.Body.Close()
+		if err != nil {
+			a.writeProblem(w, r, http.StatusBadRequest, "request body exceeds maximum size", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		request := domain.IdempotencyRequest{
+			Scope:       idempotencyScope(r.Context()),
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			RequestHash: requestFingerprint(r.Header.Get("Content-Type"), body),
+		}
+		stored, err := a.IdempotencyService.Begin(r.Context(), request)
+		if err != nil {
+			a.writeIdempotencyError(w, r, err)
+			return
+		}
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(stored.StatusCode)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		completed := false
+		// The original client may have timed out and gone away; the result
+		// must still be stored for its retry.
+		storeCtx := context.WithoutCancel(r.Context())
+		defer func() {
+			if !completed {
+				if err := a.IdempotencyService.Release(storeCtx, request); err != nil {
+					a.Logger.ErrorContext(storeCtx, "releasing idempotency key", "key", key, "err", err)
+				}
+			}
+		}()
+		next(recorder, r)
+		if recorder.statusCode() >= http.StatusInternalServerError {
+			return
+		}
+		if err := a.IdempotencyService.Complete(storeCtx, request, domain.IdempotentResponse{
+			StatusCode:  recorder.statusCode(),
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}); err != nil {
+			a.Logger.ErrorContext(storeCtx, "storing idempotent response", "key", key, "err", err)
+			return
+		}
+		completed = true
+	}
+}
+
+func (a *API) writeIdempotencyError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		a.writeProblem(w, r, http.StatusUnprocessableEntity, err.Error(), err)
+	case errors.Is(err, domain.ErrConflict):
+		a.writeProblem(w, r, http.StatusConflict, err.Error(), err)
+	default:
+		a.writeSiteError(w, r, http.StatusInternalServerError, "internal server error", "checking idempotency key", err)
+	}
+}
+
+func idempotencyScope(ctx context.Context) string {
+	principal, ok := apiauth.PrincipalFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if principal.Subject != "" {
+		return principal.Subject
+	}
+	return principal.Username
+}
+
+// requestFingerprint hashes the request body. Multipart bodies are hashed by
+// part name, filename and content, because clients pick a new boundary on
+// every retry.
+func requestFingerprint(contentType string, body []byte) string {
+	digest := sha256.New()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		if hashMultipart(digest, multipart.NewReader(bytes.NewReader(body), params["boundary"])) == nil {
+			return hex.EncodeToString(digest.Sum(nil))
+		}
+		digest.Reset()
+	}
+	_, _ = digest.Write(body)
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+func hashMultipart(digest hash.Hash, reader *multipart.Reader) error {
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(digest, "%q %q\n", part.FormName(), part.FileName())
+		if _, err := io.Copy(digest, part); err != nil {
+			return err
+		}
+	}
+}
+
+// responseRecorder passes the response through while keeping a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
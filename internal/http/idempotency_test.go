@@ -0,0 +1,170 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+type idempotencyServiceStub struct {
+	stored    map[string]domain.IdempotentResponse
+	hashes    map[string]string
+	released  []string
+	beginErr  error
+	lastScope string
+}
+
+func newIdempotencyServiceStub() *idempotencyServiceStub {
+	return &idempotencyServiceStub{stored: map[string]domain.IdempotentResponse{}, hashes: map[string]string{}}
+}
+
+func (s *idempotencyServiceStub) Begin(_ context.Context, request domain.IdempotencyRequest) (*domain.IdempotentResponse, error) {
+	s.lastScope = request.Scope
+	if s.beginErr != nil {
+		return nil, s.beginErr
+	}
+	if hash, ok := s.hashes[request.Key]; ok && hash != request.RequestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+	s.hashes[request.Key] = request.RequestHash
+	if response, ok := s.stored[request.Key]; ok {
+		return &response, nil
+	}
+	return nil, nil
+}
+
+func (s *idempotencyServiceStub) Complete(_ context.Context, request domain.IdempotencyRequest, response domain.IdempotentResponse) error {
+	s.stored[request.Key] = response
+	return nil
+}
+
+func (s *idempotencyServiceStub) Release(_ context.Context, request domain.IdempotencyRequest) error {
+	delete(s.hashes, request.Key)
+	s.released = append(s.released, request.Key)
+	return nil
+}
+
+func (s *idempotencyServiceStub) PruneExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func newIdempotencyTestAPI(service domain.IdempotencyService) *API {
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	api.IdempotencyService = service
+	return api
+}
+
+func postWithKey(handler http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotentReplaysStoredResponse(t *testing.T) {
+	service := newIdempotencyServiceStub()
+	api := newIdempotencyTestAPI(service)
+	calls := 0
+	handler := api.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		api.writeJSON(w, r, http.StatusCreated, map[string]string{"echo": string(body)})
+	})
+
+	first := postWithKey(handler, "/api/v1/sites", "ci-run-1", `{"name":"edge"}`)
+	second := postWithKey(handler, "/api/v1/sites", "ci-run-1", `{"name":"edge"}`)
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() || second.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("expected replay of %d %q, got %d %q", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatal("expected only the replay to be marked")
+	}
+	if service.lastScope != "" {
+		t.Fatalf("expected empty scope outside auth middleware, got %q", service.lastScope)
+	}
+
+	postWithKey(handler, "/api/v1/sites", "", `{"name":"edge"}`)
+	if calls != 2 {
+		t.Fatalf("expected requests without a key to pass through, ran %d times", calls)
+	}
+}
+
+func TestIdempotentRejectsReusedKeyAndReleasesServerErrors(t *testing.T) {
+	service := newIdempotencyServiceStub()
+	api := newIdempotencyTestAPI(service)
+	status := http.StatusInternalServerError
+	handler := api.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		api.writeProblem(w, r, status, "internal server error", nil)
+	})
+
+	postWithKey(handler, "/api/v1/sites", "ci-run-2", `{"name":"edge"}`)
+	if len(service.released) != 1 || len(service.stored) != 0 {
+		t.Fatalf("expected server error to release the key: released=%v stored=%v", service.released, service.stored)
+	}
+
+	status = http.StatusCreated
+	postWithKey(handler, "/api/v1/sites", "ci-run-2", `{"name":"edge"}`)
+	rec := postWithKey(handler, "/api/v1/sites", "ci-run-2", `{"name":"core"}`)
+	assertProblem(t, rec, http.StatusUnprocessableEntity, domain.ErrIdempotencyKeyReused.Error())
+}
+
+func TestIdempotentMapsServiceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{name: "invalid key", err: domain.ErrInvalidInput, status: http.StatusBadRequest, message: "invalid input"},
+		{name: "in progress", err: domain.ErrConflict, status: http.StatusConflict, message: "conflict"},
+		{name: "unexpected", err: io.ErrUnexpectedEOF, status: http.StatusInternalServerError, message: "internal server error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newIdempotencyServiceStub()
+			service.beginErr = test.err
+			rec := postWithKey(newIdempotencyTestAPI(service).Router(), "/api/v1/sites", "ci-run-3", `{"name":"edge"}`)
+			assertProblem(t, rec, test.status, test.message)
+		})
+	}
+}
+
+func TestRequestFingerprintIgnoresMultipartBoundary(t *testing.T) {
+	form := func(boundary, content string) (string, []byte) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		_ = writer.SetBoundary(boundary)
+		part, _ := writer.CreateFormFile("file", "inventory.csv")
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+		return writer.FormDataContentType(), body.Bytes()
+	}
+	firstType, firstBody := form("boundary-one", "site,cidr\nedge,10.0.0.0/24\n")
+	retryType, retryBody := form("boundary-two", "site,cidr\nedge,10.0.0.0/24\n")
+	otherType, otherBody := form("boundary-one", "site,cidr\nedge,10.0.1.0/24\n")
+
+	if requestFingerprint(firstType, firstBody) != requestFingerprint(retryType, retryBody) {
+		t.Fatal("expected retries with a new boundary to match")
+	}
+	if requestFingerprint(firstType, firstBody) == requestFingerprint(otherType, otherBody) {
+		t.Fatal("expected different files to differ")
+	}
+	if requestFingerprint("application/json", []byte(`{"a":1}`)) == requestFingerprint("application/json", []byte(`{"a":2}`)) {
+		t.Fatal("expected different JSON bodies to differ")
+	}
+}
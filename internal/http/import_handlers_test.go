@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"mime/multipart"
@@ -25,11 +26,33 @@ func (s importServiceStub) ImportCSV(context.Context, io.Reader) (domain.ImportR
 	return s.result, s.err
 }
 
+type leaseImportServiceStub struct {
+	input    domain.LeaseImportInput
+	received string
+	result   domain.ImportResult
+	err      error
+}
+
+func (s *leaseImportServiceStub) ImportLeases(_ context.Context, input domain.LeaseImportInput) (domain.ImportResult, error) {
+	s.input = input
+	data, err := io.ReadAll(input.File)
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	s.received = string(data)
+	return s.result, s.err
+}
+
 func csvUploadRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	return uploadRequest(t, "/api/v1/import/csv", "inventory.csv", body)
+}
+
+func uploadRequest(t *testing.T, target, filename, body string) *http.Request {
 	t.Helper()
 	var buffer bytes.Buffer
 	writer := multipart.NewWriter(&buffer)
-	part, err := writer.CreateFormFile("file", "inventory.csv")
+	part, err := writer.CreateFormFile("file", filename)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -39,7 +62,7 @@ func csvUploadRequest(t *testing.T, body string) *http.Request {
 	if err = writer.Close(); err != nil {
 		t.Fatal(err)
 	}
-	request := httptest.NewRequest(http.MethodPost, "/api/v1/import/csv", &buffer)
+	request := httptest.NewRequest(http.MethodPost, target, &buffer)
 	request.Header.Set("Content-Type", writer.FormDataContentType())
 	return request
 }
@@ -111,3 +134,87 @@ func TestImportCSVRouteMapsServiceErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestImportLeasesRoutePassesFormatAndDryRun(t *testing.T) {
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	service := &leaseImportServiceStub{result: domain.ImportResult{
+		Processed: 2, Created: 1, Failed: 1, Errors: []domain.RowError{{Row: 2, Message: "no subnet contains 10.9.0.1"}},
+	}}
+	api.LeaseImportService = service
+	lease := "0 52:54:00:12:34:56 10.0.0.5 nas *\n"
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, uploadRequest(t, "/api/v1/import/leases?format=dnsmasq&dry_run=true", "dnsmasq.leases", lease))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if service.input.Format != domain.LeaseFileDnsmasq || !service.input.DryRun || service.received != lease {
+		t.Fatalf("unexpected input: %+v %q", service.input, service.received)
+	}
+	var result ImportResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 1 || len(result.Errors) != 1 || result.Errors[0].Row != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestImportLeasesRouteMapsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		serviceErr error
+		status     int
+		detail     string
+	}{
+		{name: "missing format", target: "/api/v1/import/leases", status: http.StatusBadRequest, detail: "invalid input: format must be isc, kea or dnsmasq"},
+		{name: "invalid dry run", target: "/api/v1/import/leases?format=kea&dry_run=maybe", status: http.StatusBadRequest, detail: "dry_run must be true or false"},
+		{name: "malformed file", target: "/api/v1/import/leases?format=isc", serviceErr: fmt.Errorf("%w: missing } at end of file", domain.ErrInvalidInput), status: http.StatusBadRequest, detail: "invalid input: missing } at end of file"},
+		{name: "internal error", target: "/api/v1/import/leases?format=isc", serviceErr: errors.New("database unavailable"), status: http.StatusInternalServerError, detail: "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+			api.LeaseImportService = &leaseImportServiceStub{err: tt.serviceErr}
+			recorder := httptest.NewRecorder()
+			api.Router().ServeHTTP(recorder, uploadRequest(t, tt.target, "dhcpd.leases", "lease 10.0.0.5 {\n"))
+			assertProblem(t, recorder, tt.status, tt.detail)
+		})
+	}
+}
+
+type zoneImportServiceStub struct {
+	input    domain.ZoneImportInput
+	received string
+	err      error
+}
+
+func (s *zoneImportServiceStub) ImportZone(_ context.Context, input domain.ZoneImportInput) (domain.ImportResult, error) {
+	s.input = input
+	data, err := io.ReadAll(input.File)
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	s.received = string(data)
+	return domain.ImportResult{Processed: 1, Created: 1}, s.err
+}
+
+func TestImportZoneRoutePassesOriginAndDryRun(t *testing.T) {
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	service := &zoneImportServiceStub{}
+	api.ZoneImportService = service
+	zone := "www IN A 10.0.0.80\n"
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, uploadRequest(t, "/api/v1/import/zone?origin=example.com&dry_run=1", "db.example.com", zone))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if service.input.Origin != "example.com" || !service.input.DryRun || service.received != zone {
+		t.Fatalf("unexpected input: %+v %q", service.input, service.received)
+	}
+
+	recorder = httptest.NewRecorder()
+	api.ZoneImportService = &zoneImportServiceStub{err: fmt.Errorf("%w: unbalanced ) on line 1", domain.ErrInvalidInput)}
+	api.Router().ServeHTTP(recorder, uploadRequest(t, "/api/v1/import/zone", "db.example.com", zone))
+	assertProblem(t, recorder, http.StatusBadRequest, "invalid input: unbalanced ) on line 1")
+}
@@ -1,8 +1,11 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
 )
@@ -13,9 +16,9 @@ import (
 // @Produce json
 // @Param id path int true "Subnet ID"
 // @Success 200 {array} KubernetesServiceObservationResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/kubernetes-services [get]
 func (a *API) handleGetKubernetesServicesBySubnetID(w http.ResponseWriter, r *http.Request) {
 	ctx, id, _, done := parseID(w, r, a)
@@ -24,13 +27,13 @@ func (a *API) handleGetKubernetesServicesBySubnetID(w http.ResponseWriter, r *ht
 	}
 	if _, err := a.NetService.GetSubnet(ctx, id); err != nil {
 		status := http.StatusInternalServerError
-		response := ErrorResponse{Error: "internal server error"}
+		detail := "internal server error"
 		if errors.Is(err, domain.ErrNotFound) {
 			status = http.StatusNotFound
-			response = ErrorResponse{Error: "subnet not found"}
+			detail = "subnet not found"
 		}
 		a.Logger.ErrorContext(ctx, "reading subnet for kubernetes services", "id", id, "err", err)
-		_ = encode(w, r, status, response)
+		a.writeProblem(w, r, status, detail, err)
 		return
 	}
 	if a.DiscoveryService == nil {
@@ -40,18 +43,58 @@ func (a *API) handleGetKubernetesServicesBySubnetID(w http.ResponseWriter, r *ht
 	services, err := a.DiscoveryService.ListServicesBySubnetID(ctx, id)
 	if err != nil {
 		a.Logger.ErrorContext(ctx, "listing kubernetes services by subnet", "id", id, "err", err)
-		_ = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		return
 	}
 	_ = encode(w, r, http.StatusOK, kubernetesServiceObservationsToResponse(services))
 }
 
+// @Summary List discovered Kubernetes objects with addresses in a subnet
+// @Description Includes addresses that match no IP in IPAM, such as hostNetwork Pods.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Subnet ID"
+// @Success 200 {array} KubernetesObjectObservationResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/subnets/{id}/kubernetes-objects [get]
+func (a *API) handleGetKubernetesObjectsBySubnetID(w http.ResponseWriter, r *http.Request) {
+	ctx, id, _, done := parseID(w, r, a)
+	if done {
+		return
+	}
+	if _, err := a.NetService.GetSubnet(ctx, id); err != nil {
+		status := http.StatusInternalServerError
+		detail := "internal server error"
+		if errors.Is(err, domain.ErrNotFound) {
+			status = http.StatusNotFound
+			detail = "subnet not found"
+		}
+		a.Logger.ErrorContext(ctx, "reading subnet for kubernetes objects", "id", id, "err", err)
+		a.writeProblem(w, r, status, detail, err)
+		return
+	}
+	if a.DiscoveryService == nil {
+		_ = encode(w, r, http.StatusOK, make([]KubernetesObjectObservationResponse, 0))
+		return
+	}
+	objects, err := a.DiscoveryService.ListObjectsBySubnetID(ctx, id)
+	if err != nil {
+		a.Logger.ErrorContext(ctx, "listing kubernetes objects by subnet", "id", id, "err", err)
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
+		return
+	}
+	_ = encode(w, r, http.StatusOK, kubernetesObjectObservationsToResponse(objects))
+}
+
 // @Summary List Kubernetes discovery source status
 // @Tags kubernetes
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} KubernetesDiscoveryStatusResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} Problem
 // @Router /api/v1/kubernetes/sources [get]
 func (a *API) handleGetKubernetesSources(w http.ResponseWriter, r *http.Request) {
 	if a.DiscoveryService == nil {
@@ -61,8 +104,250 @@ func (a *API) handleGetKubernetesSources(w http.ResponseWriter, r *http.Request)
 	statuses, err := a.DiscoveryService.ListSourceStatuses(r.Context())
 	if err != nil {
 		a.Logger.ErrorContext(r.Context(), "listing kubernetes discovery sources", "err", err)
-		_ = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		return
 	}
 	_ = encode(w, r, http.StatusOK, kubernetesStatusesToResponse(statuses))
 }
+
+// @Summary Create Kubernetes discovery source
+// @Description Discovery starts without a restart. Creating a source with the key of one from the deployment's configuration takes it over.
+// @Description Kubeconfigs must embed their credentials and are encrypted at rest; they are never returned.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param source body KubernetesSourceRequest true "Discovery source"
+// @Success 201 {object} KubernetesDiscoveryStatusResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/kubernetes/sources [post]
+func (a *API) handleCreateKubernetesSource(w http.ResponseWriter, r *http.Request) {
+	if !a.requireKubernetesSourceService(w, r) {
+		return
+	}
+	request, err := decode[KubernetesSourceRequest](r)
+	defer r.Body.Close()
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	status, err := a.KubernetesSourceService.CreateSource(r.Context(), request.toInput())
+	if err != nil {
+		a.writeKubernetesSourceError(w, r, "creating kubernetes source", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusCreated, kubernetesStatusesToResponse([]domain.KubernetesSourceStatus{status})[0])
+}
+
+// @Summary Update Kubernetes discovery source
+// @Description Only sources created through the API can be changed. The running discovery restarts with the new settings.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key path string true "Source key"
+// @Param source body UpdateKubernetesSourceRequest true "Fields to change"
+// @Success 200 {object} KubernetesDiscoveryStatusResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/kubernetes/sources/{key} [patch]
+func (a *API) handleUpdateKubernetesSource(w http.ResponseWriter, r *http.Request) {
+	if !a.requireKubernetesSourceService(w, r) {
+		return
+	}
+	request, err := decode[UpdateKubernetesSourceRequest](r)
+	defer r.Body.Close()
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	status, err := a.KubernetesSourceService.UpdateSource(r.Context(), request.toInput(r.PathValue("key")))
+	if err != nil {
+		a.writeKubernetesSourceError(w, r, "updating kubernetes source", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, kubernetesStatusesToResponse([]domain.KubernetesSourceStatus{status})[0])
+}
+
+// @Summary Delete Kubernetes discovery source
+// @Description Stops discovery and removes the source with its observations. Only sources created through the API can be deleted.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Param key path string true "Source key"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/kubernetes/sources/{key} [delete]
+func (a *API) handleDeleteKubernetesSource(w http.ResponseWriter, r *http.Request) {
+	if !a.requireKubernetesSourceService(w, r) {
+		return
+	}
+	deleted, err := a.KubernetesSourceService.DeleteSource(r.Context(), r.PathValue("key"))
+	if err != nil {
+		a.writeKubernetesSourceError(w, r, "deleting kubernetes source", err)
+		return
+	}
+	if !deleted {
+		a.writeKubernetesSourceError(w, r, "deleting kubernetes source", domain.ErrNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// kubernetesReconcileTimeout bounds an on-demand discovery cycle, including
+// the wait for another replica to run it.
+const kubernetesReconcileTimeout = time.Minute
+
+// @Summary Reconcile Kubernetes discovery source now
+// @Description Runs a discovery cycle without waiting for the source's interval. When this replica has no runner for the source, the request is signalled to the other replicas and answered with the outcome of the cycle one of them runs.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Produce json
+// @Param key path string true "Source key"
+// @Success 200 {object} KubernetesReconcileResultResponse
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Failure 502 {object} Problem
+// @Failure 503 {object} Problem
+// @Router /api/v1/kubernetes/sources/{key}/reconcile [post]
+func (a *API) handleReconcileKubernetesSource(w http.ResponseWriter, r *http.Request) {
+	if a.KubernetesReconciler == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "kubernetes discovery unavailable", nil)
+		return
+	}
+	// The cycle can outlast the server's write timeout.
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Now().Add(kubernetesReconcileTimeout + time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		a.Logger.ErrorContext(r.Context(), "extending write deadline", "err", err)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), kubernetesReconcileTimeout)
+	defer cancel()
+	key := r.PathValue("key")
+	result, err := a.KubernetesReconciler.Reconcile(ctx, key)
+	switch {
+	case err == nil:
+		a.writeJSON(w, r, http.StatusOK, KubernetesReconcileResultResponse{
+			Source: key, Services: result.Services, Matched: result.Matched, Unmatched: result.Unmatched,
+			Ambiguous: result.Ambiguous, NoUsableIP: result.NoUsableIP,
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		a.writeProblem(w, r, http.StatusNotFound, "kubernetes source not found", err)
+	case errors.Is(err, domain.ErrDiscoveryBusy):
+		a.writeProblem(w, r, http.StatusConflict, "a discovery cycle of the source is already running", err)
+	case errors.Is(err, domain.ErrDiscoveryUnanswered):
+		a.writeProblem(w, r, http.StatusServiceUnavailable, err.Error(), err)
+	default:
+		a.Logger.WarnContext(r.Context(), "kubernetes reconcile failed", "source", key, "err", err)
+		a.writeProblem(w, r, http.StatusBadGateway, "kubernetes discovery failed: "+err.Error(), err)
+	}
+}
+
+const defaultKubernetesHistoryLimit int32 = 100
+
+// @Summary List Kubernetes discovery runs of a source
+// @Description Newest first. Each complete publication of the source's Services is a run, as is each incremental publication that changed a Service and each failed attempt. Runs are kept for the source's stale retention.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Produce json
+// @Param key path string true "Source key"
+// @Param limit query int false "Maximum runs to return" default(100) minimum(1) maximum(500)
+// @Success 200 {array} KubernetesDiscoveryRunResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/kubernetes/sources/{key}/runs [get]
+func (a *API) handleGetKubernetesSourceRuns(w http.ResponseWriter, r *http.Request) {
+	if a.DiscoveryService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "kubernetes discovery unavailable", nil)
+		return
+	}
+	limit, done := a.parseKubernetesHistoryLimit(w, r)
+	if done {
+		return
+	}
+	runs, err := a.DiscoveryService.ListRuns(r.Context(), r.PathValue("key"), limit)
+	if err != nil {
+		a.writeKubernetesSourceError(w, r, "listing kubernetes discovery runs", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, kubernetesRunsToResponse(runs))
+}
+
+// @Summary List Service changes made by Kubernetes discovery
+// @Description Newest first. A Service appears when it becomes active, changes address when its set of addresses differs, goes stale when it is no longer observed and is purged after the stale retention. Addresses are recorded as they were, so the previous addresses of a Service remain after it changes.
+// @Tags kubernetes
+// @Security BearerAuth
+// @Produce json
+// @Param key path string true "Source key"
+// @Param run_id query int false "Only the changes of this run"
+// @Param limit query int false "Maximum changes to return" default(100) minimum(1) maximum(500)
+// @Success 200 {array} KubernetesDiscoveryEventResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/kubernetes/sources/{key}/events [get]
+func (a *API) handleGetKubernetesSourceEvents(w http.ResponseWriter, r *http.Request) {
+	if a.DiscoveryService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "kubernetes discovery unavailable", nil)
+		return
+	}
+	limit, done := a.parseKubernetesHistoryLimit(w, r)
+	if done {
+		return
+	}
+	var runID *int64
+	if raw := r.URL.Query().Get("run_id"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			a.writeProblem(w, r, http.StatusBadRequest, "run_id must be an integer", domain.InvalidField("run_id", "run_id must be an integer"))
+			return
+		}
+		runID = &parsed
+	}
+	events, err := a.DiscoveryService.ListEvents(r.Context(), r.PathValue("key"), runID, limit)
+	if err != nil {
+		a.writeKubernetesSourceError(w, r, "listing kubernetes discovery events", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, kubernetesEventsToResponse(events))
+}
+
+func (a *API) parseKubernetesHistoryLimit(w http.ResponseWriter, r *http.Request) (limit int32, done bool) {
+	limit = defaultKubernetesHistoryLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			a.writeProblem(w, r, http.StatusBadRequest, "limit must be an integer", domain.InvalidField("limit", "limit must be an integer"))
+			return 0, true
+		}
+		limit = int32(parsed)
+	}
+	return limit, false
+}
+
+func (a *API) requireKubernetesSourceService(w http.ResponseWriter, r *http.Request) bool {
+	if a.KubernetesSourceService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "kubernetes source service unavailable", nil)
+		return false
+	}
+	return true
+}
+
+func (a *API) writeKubernetesSourceError(w http.ResponseWriter, r *http.Request, operation string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, domain.ErrNotFound):
+		a.writeProblem(w, r, http.StatusNotFound, "kubernetes source not found", err)
+	case errors.Is(err, domain.ErrConflict):
+		a.writeProblem(w, r, http.StatusConflict, conflictDetail(err, "conflict"), err)
+	default:
+		a.writeSiteError(w, r, http.StatusInternalServerError, "internal server error", operation, err)
+	}
+}
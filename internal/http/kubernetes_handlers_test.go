@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/netip"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,26 +18,94 @@ import (
 type statusServiceStub struct {
 	statuses   []domain.KubernetesSourceStatus
 	services   []domain.KubernetesServiceObservation
+	objects    []domain.KubernetesObjectObservation
+	runs       []domain.KubernetesDiscoveryRun
+	events     []domain.KubernetesDiscoveryEvent
 	err        error
 	serviceErr error
 }
 
-func (s statusServiceStub) Reconcile(context.Context, domain.KubernetesSourceConfig, []domain.KubernetesServiceSnapshot, time.Time) (domain.KubernetesReconcileResult, error) {
+func (s statusServiceStub) Reconcile(context.Context, domain.KubernetesSourceConfig, []domain.KubernetesServiceSnapshot, time.Time, time.Time) (domain.KubernetesReconcileResult, error) {
 	return domain.KubernetesReconcileResult{}, nil
 }
 
+func (s statusServiceStub) ApplyChanges(context.Context, domain.KubernetesSourceConfig, domain.KubernetesServiceChanges, time.Time) (domain.KubernetesReconcileResult, error) {
+	return domain.KubernetesReconcileResult{}, nil
+}
+
+func (s statusServiceStub) ReconcileObjects(context.Context, domain.KubernetesSourceConfig, []domain.KubernetesObjectSnapshot, time.Time) (domain.KubernetesObjectReconcileResult, error) {
+	return domain.KubernetesObjectReconcileResult{}, nil
+}
+
 func (s statusServiceStub) RecordFailure(context.Context, domain.KubernetesSourceConfig, time.Time, error) error {
 	return nil
 }
 
+func (s statusServiceStub) RecordObjectFailure(context.Context, domain.KubernetesSourceConfig, error) error {
+	return nil
+}
+
+func (s statusServiceStub) RequestReconcile(context.Context, string, string, time.Time) error {
+	return nil
+}
+
 func (s statusServiceStub) ListSourceStatuses(context.Context) ([]domain.KubernetesSourceStatus, error) {
 	return s.statuses, s.err
 }
 
+func (s statusServiceStub) ListRuns(context.Context, string, int32) ([]domain.KubernetesDiscoveryRun, error) {
+	return s.runs, s.err
+}
+
+func (s statusServiceStub) ListEvents(_ context.Context, _ string, runID *int64, _ int32) ([]domain.KubernetesDiscoveryEvent, error) {
+	events := make([]domain.KubernetesDiscoveryEvent, 0, len(s.events))
+	for _, event := range s.events {
+		if runID == nil || event.RunID == *runID {
+			events = append(events, event)
+		}
+	}
+	return events, s.err
+}
+
 func (s statusServiceStub) ListServicesBySubnetID(context.Context, int64) ([]domain.KubernetesServiceObservation, error) {
 	return s.services, s.serviceErr
 }
 
+func (s statusServiceStub) ListObjectsBySubnetID(context.Context, int64) ([]domain.KubernetesObjectObservation, error) {
+	return s.objects, s.serviceErr
+}
+
+type kubernetesSourceServiceStub struct {
+	created domain.CreateKubernetesSourceInput
+	updated domain.UpdateKubernetesSourceInput
+	err     error
+	deleted bool
+}
+
+func (s *kubernetesSourceServiceStub) CreateSource(_ context.Context, input domain.CreateKubernetesSourceInput) (domain.KubernetesSourceStatus, error) {
+	s.created = input
+	if s.err != nil {
+		return domain.KubernetesSourceStatus{}, s.err
+	}
+	return domain.KubernetesSourceStatus{
+		Source: domain.KubernetesSource{Key: input.Key, Name: input.Name}, SiteID: input.SiteID, State: "pending", Managed: true,
+		Settings: &domain.KubernetesSourceSettings{AuthMode: input.AuthMode, ReconcileInterval: *input.ReconcileInterval, RequestTimeout: 15 * time.Second, StaleRetention: time.Hour},
+	}, nil
+}
+
+func (s *kubernetesSourceServiceStub) UpdateSource(_ context.Context, input domain.UpdateKubernetesSourceInput) (domain.KubernetesSourceStatus, error) {
+	s.updated = input
+	return domain.KubernetesSourceStatus{Source: domain.KubernetesSource{Key: input.Key}, Managed: true}, s.err
+}
+
+func (s *kubernetesSourceServiceStub) DeleteSource(context.Context, string) (bool, error) {
+	return s.deleted, s.err
+}
+
+func (s *kubernetesSourceServiceStub) ListManagedSources(context.Context) ([]domain.ManagedKubernetesSource, error) {
+	return nil, s.err
+}
+
 func TestKubernetesServicesBySubnetIncludesEveryMatchState(t *testing.T) {
 	now := time.Unix(1700000000, 0).UTC()
 	matchedID := domain.IPAddressID("550e8400-e29b-41d4-a716-446655440000")
@@ -98,6 +167,65 @@ func TestKubernetesServicesBySubnetIncludesEveryMatchState(t *testing.T) {
 	}
 }
 
+func TestKubernetesObjectsBySubnetIncludesUnmatchedAddresses(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	matchedID := domain.IPAddressID("550e8400-e29b-41d4-a716-446655440000")
+	api := newHandlerTestAPI(stubService{getSubnetFn: func(context.Context, int64) (domain.Subnet, error) {
+		return domain.Subnet{ID: 42}, nil
+	}}, nil)
+	api.DiscoveryService = statusServiceStub{objects: []domain.KubernetesObjectObservation{
+		{
+			Source: domain.KubernetesSource{Key: "prod", Name: "Production"}, Kind: domain.KubernetesObjectNode,
+			UID: "uid-node", Name: "worker-1", ObservedAt: now,
+			Addresses: []domain.KubernetesObjectAddressObservation{{IP: netip.MustParseAddr("10.0.0.5"), Kind: "internal_ip", NodeName: "worker-1", MatchStatus: domain.KubernetesMatchMatched, MatchCount: 1, MatchedIPAddressID: &matchedID}},
+		},
+		{
+			Source: domain.KubernetesSource{Key: "prod", Name: "Production"}, Kind: domain.KubernetesObjectPod,
+			UID: "uid-pod", Namespace: "kube-system", Name: "node-exporter", HostNetwork: true, ObservedAt: now,
+			Addresses: []domain.KubernetesObjectAddressObservation{{IP: netip.MustParseAddr("10.0.0.6"), Kind: "pod_ip", NodeName: "worker-2", MatchStatus: domain.KubernetesMatchUnmatched}},
+		},
+		{
+			Source: domain.KubernetesSource{Key: "prod", Name: "Production"}, Kind: domain.KubernetesObjectIngress,
+			UID: "uid-ingress", Namespace: "apps", Name: "ipam", ObservedAt: now,
+			Addresses: []domain.KubernetesObjectAddressObservation{{IP: netip.MustParseAddr("10.0.0.7"), Kind: "load_balancer", MatchStatus: domain.KubernetesMatchUnmatched, LoadBalancerServices: []string{"ingress-nginx/controller"}}},
+			Hostnames: []domain.KubernetesObjectHostname{{Kind: "host", Hostname: "ipam.example.test"}},
+		},
+	}}
+
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/subnets/42/kubernetes-objects", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response []KubernetesObjectObservationResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(response) != 3 || response[0].Addresses[0].MatchedIPAddressID == nil || response[0].Addresses[0].NodeName != "worker-1" {
+		t.Fatalf("matched node lost its link: %+v", response)
+	}
+	if !response[1].HostNetwork || response[1].Addresses[0].MatchStatus != string(domain.KubernetesMatchUnmatched) || response[1].Addresses[0].NodeName != "worker-2" {
+		t.Fatalf("hostNetwork pod was not represented: %+v", response[1])
+	}
+	if response[0].Hostnames == nil || response[0].Addresses[0].LoadBalancerServices == nil {
+		t.Fatalf("expected empty lists rather than null: %+v", response[0])
+	}
+	ingress := response[2]
+	if len(ingress.Hostnames) != 1 || ingress.Hostnames[0].Hostname != "ipam.example.test" || len(ingress.Addresses[0].LoadBalancerServices) != 1 {
+		t.Fatalf("ingress lost its hostnames or Service link: %+v", ingress)
+	}
+}
+
+func TestKubernetesObjectsBySubnetNotFound(t *testing.T) {
+	api := newHandlerTestAPI(stubService{getSubnetFn: func(context.Context, int64) (domain.Subnet, error) {
+		return domain.Subnet{}, domain.ErrNotFound
+	}}, nil)
+	api.DiscoveryService = statusServiceStub{}
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/subnets/42/kubernetes-objects", nil))
+	assertProblem(t, recorder, http.StatusNotFound, "subnet not found")
+}
+
 func TestKubernetesSourcesStatus(t *testing.T) {
 	api := newHandlerTestAPI(stubService{}, nil)
 	api.DiscoveryService = statusServiceStub{statuses: []domain.KubernetesSourceStatus{{
@@ -124,5 +252,213 @@ func TestKubernetesSourcesStatusFailure(t *testing.T) {
 	api.DiscoveryService = statusServiceStub{err: errors.New("boom")}
 	recorder := httptest.NewRecorder()
 	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/kubernetes/sources", nil))
-	assertJSONError(t, recorder, http.StatusInternalServerError, "internal server error")
+	assertProblem(t, recorder, http.StatusInternalServerError, "internal server error")
+}
+
+func TestCreateKubernetesSource(t *testing.T) {
+	api := newHandlerTestAPI(stubService{}, nil)
+	sources := &kubernetesSourceServiceStub{}
+	api.KubernetesSourceService = sources
+	body := `{"key":"prod","name":"Production","site_id":"550e8400-e29b-41d4-a716-446655440000","namespaces":["apps"],"auth_mode":"kubeconfig","kubeconfig":"apiVersion: v1","interval_seconds":60}`
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/kubernetes/sources", strings.NewReader(body)))
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if sources.created.Kubeconfig != "apiVersion: v1" || *sources.created.ReconcileInterval != time.Minute || sources.created.RequestTimeout != nil {
+		t.Fatalf("unexpected input: %+v", sources.created)
+	}
+	if strings.Contains(recorder.Body.String(), "apiVersion") {
+		t.Fatalf("response leaked the kubeconfig: %s", recorder.Body.String())
+	}
+	var response KubernetesDiscoveryStatusResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !response.Managed || response.Settings == nil || response.Settings.IntervalSeconds != 60 || response.Settings.StaleRetentionSeconds != 3600 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestUpdateKubernetesSourcePassesOnlyPresentFields(t *testing.T) {
+	api := newHandlerTestAPI(stubService{}, nil)
+	sources := &kubernetesSourceServiceStub{}
+	api.KubernetesSourceService = sources
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPatch, "/api/v1/kubernetes/sources/prod", strings.NewReader(`{"namespaces":["*"]}`)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if sources.updated.Key != "prod" || len(sources.updated.Namespaces) != 1 || sources.updated.Name != nil || sources.updated.AuthMode != nil || sources.updated.ReconcileInterval != nil {
+		t.Fatalf("unexpected input: %+v", sources.updated)
+	}
+
+	recorder = httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPatch, "/api/v1/kubernetes/sources/prod", strings.NewReader(`{"object_kinds":[],"pod_selector":"","auto_register":"register_lb_only"}`)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if sources.updated.ObjectKinds == nil || len(sources.updated.ObjectKinds) != 0 || sources.updated.PodSelector == nil || sources.updated.Namespaces != nil {
+		t.Fatalf("an empty object_kinds list should clear the kinds: %+v", sources.updated)
+	}
+	if sources.updated.AutoRegister == nil || *sources.updated.AutoRegister != "register_lb_only" {
+		t.Fatalf("auto_register was not passed on: %+v", sources.updated)
+	}
+}
+
+func TestKubernetesSourceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		stub   *kubernetesSourceServiceStub
+		status int
+		detail string
+	}{
+		{"invalid", http.MethodPost, "/api/v1/kubernetes/sources", `{}`, &kubernetesSourceServiceStub{err: domain.InvalidField("key", "key is required")}, http.StatusBadRequest, "invalid input: key is required"},
+		{"out of range", http.MethodPost, "/api/v1/kubernetes/sources", `{"interval_seconds":9999999999}`, &kubernetesSourceServiceStub{}, http.StatusBadRequest, "bad request"},
+		{"configured", http.MethodPatch, "/api/v1/kubernetes/sources/prod", `{}`, &kubernetesSourceServiceStub{err: errors.Join(domain.ErrConflict, errors.New("configured"))}, http.StatusConflict, "conflict"},
+		{"missing", http.MethodDelete, "/api/v1/kubernetes/sources/prod", ``, &kubernetesSourceServiceStub{}, http.StatusNotFound, "kubernetes source not found"},
+		{"failure", http.MethodDelete, "/api/v1/kubernetes/sources/prod", ``, &kubernetesSourceServiceStub{err: errors.New("boom")}, http.StatusInternalServerError, "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newHandlerTestAPI(stubService{}, nil)
+			api.KubernetesSourceService = tt.stub
+			recorder := httptest.NewRecorder()
+			api.Router().ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			assertProblem(t, recorder, tt.status, tt.detail)
+		})
+	}
+}
+
+func TestDeleteKubernetesSource(t *testing.T) {
+	api := newHandlerTestAPI(stubService{}, nil)
+	api.KubernetesSourceService = &kubernetesSourceServiceStub{deleted: true}
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/api/v1/kubernetes/sources/prod", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+type kubernetesReconcilerStub struct {
+	key    string
+	result domain.KubernetesReconcileResult
+	err    error
+}
+
+func (s *kubernetesReconcilerStub) Reconcile(_ context.Context, key string) (domain.KubernetesReconcileResult, error) {
+	s.key = key
+	return s.result, s.err
+}
+
+func TestReconcileKubernetesSource(t *testing.T) {
+	api := newHandlerTestAPI(stubService{}, nil)
+	reconciler := &kubernetesReconcilerStub{result: domain.KubernetesReconcileResult{Services: 5, Matched: 3, Unmatched: 1, NoUsableIP: 1}}
+	api.KubernetesReconciler = reconciler
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/kubernetes/sources/prod/reconcile", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response KubernetesReconcileResultResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := KubernetesReconcileResultResponse{Source: "prod", Services: 5, Matched: 3, Unmatched: 1, NoUsableIP: 1}
+	if reconciler.key != "prod" || response != want {
+		t.Fatalf("unexpected reconcile of %q: %+v", reconciler.key, response)
+	}
+}
+
+func TestReconcileKubernetesSourceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+	}{
+		{"missing", domain.ErrNotFound, http.StatusNotFound, "kubernetes source not found"},
+		{"busy", domain.ErrDiscoveryBusy, http.StatusConflict, "a discovery cycle of the source is already running"},
+		{"unanswered", domain.ErrDiscoveryUnanswered, http.StatusServiceUnavailable, "no kubernetes discovery runner answered"},
+		{"failed", errors.New("services is forbidden"), http.StatusBadGateway, "kubernetes discovery failed: services is forbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newHandlerTestAPI(stubService{}, nil)
+			api.KubernetesReconciler = &kubernetesReconcilerStub{err: tt.err}
+			recorder := httptest.NewRecorder()
+			api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/kubernetes/sources/prod/reconcile", nil))
+			assertProblem(t, recorder, tt.status, tt.detail)
+		})
+	}
+}
+
+func TestKubernetesSourceRuns(t *testing.T) {
+	api := newHandlerTestAPI(stubService{}, nil)
+	startedAt := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+	api.DiscoveryService = statusServiceStub{runs: []domain.KubernetesDiscoveryRun{
+		{ID: 2, StartedAt: startedAt.Add(time.Minute), FinishedAt: startedAt.Add(time.Minute), Error: "services is forbidden"},
+		{ID: 1, StartedAt: startedAt, FinishedAt: startedAt.Add(time.Second), Result: domain.KubernetesReconcileResult{Services: 2, Matched: 1, Unmatched: 1}, Changes: 2},
+	}}
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/kubernetes/sources/prod/runs?limit=2", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response []KubernetesDiscoveryRunResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(response) != 2 || response[0].Error != "services is forbidden" || response[1].Services != 2 || response[1].Unmatched != 1 || response[1].Changes != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestKubernetesSourceEvents(t *testing.T) {
+	api := newHandlerTestAPI(stubService{}, nil)
+	api.DiscoveryService = statusServiceStub{events: []domain.KubernetesDiscoveryEvent{
+		{ID: 2, RunID: 2, ServiceUID: "uid-1", Namespace: "commerce", Name: "orders", Change: domain.KubernetesServiceAddressChanged,
+			PreviousAddresses: []netip.Addr{netip.MustParseAddr("10.96.12.4")}, Addresses: []netip.Addr{netip.MustParseAddr("10.96.12.9")}},
+		{ID: 1, RunID: 1, ServiceUID: "uid-1", Namespace: "commerce", Name: "orders", Change: domain.KubernetesServiceAppeared,
+			PreviousAddresses: []netip.Addr{}, Addresses: []netip.Addr{netip.MustParseAddr("10.96.12.4")}},
+	}}
+	recorder := httptest.NewRecorder()
+	api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/kubernetes/sources/prod/events?run_id=2", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response []KubernetesDiscoveryEventResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(response) != 1 || response[0].Change != "address_changed" || len(response[0].PreviousAddresses) != 1 || response[0].PreviousAddresses[0] != "10.96.12.4" || response[0].Addresses[0] != "10.96.12.9" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestKubernetesSourceHistoryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		err    error
+		status int
+		detail string
+	}{
+		{"runs limit", "/api/v1/kubernetes/sources/prod/runs?limit=many", nil, http.StatusBadRequest, "limit must be an integer"},
+		{"events run", "/api/v1/kubernetes/sources/prod/events?run_id=latest", nil, http.StatusBadRequest, "run_id must be an integer"},
+		{"missing", "/api/v1/kubernetes/sources/missing/events", domain.ErrNotFound, http.StatusNotFound, "kubernetes source not found"},
+		{"limit range", "/api/v1/kubernetes/sources/prod/runs?limit=501", domain.InvalidField("limit", "limit must be between 1 and 500"), http.StatusBadRequest, "invalid input: limit must be between 1 and 500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newHandlerTestAPI(stubService{}, nil)
+			api.DiscoveryService = statusServiceStub{err: tt.err}
+			recorder := httptest.NewRecorder()
+			api.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assertProblem(t, recorder, tt.status, tt.detail)
+		})
+	}
 }
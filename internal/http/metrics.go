@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+)
+
+// RequestObserver records one finished request. route is the ServeMux
+// pattern without its method, or "unmatched".
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// metricsMiddleware resolves the route before CORS and auth run, so rejected
+// requests are counted under their route too. httpsnoop keeps Flush and
+// Unwrap working for the event stream.
+func (a *API) metricsMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	if a.RequestObserver == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if _, pattern := mux.Handler(r); pattern != "" {
+			route = routeFromPattern(pattern)
+		}
+		captured := httpsnoop.CaptureMetrics(next, w, r)
+		a.RequestObserver.ObserveRequest(r.Method, route, captured.Code, captured.Duration)
+	})
+}
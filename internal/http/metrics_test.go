@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+)
+
+type observedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type recordingObserver struct {
+	requests []observedRequest
+}
+
+func (o *recordingObserver) ObserveRequest(method, route string, status int, _ time.Duration) {
+	o.requests = append(o.requests, observedRequest{method: method, route: route, status: status})
+}
+
+func TestRouterObservesRequestsByRoutePattern(t *testing.T) {
+	observer := &recordingObserver{}
+	api := newTestAPI()
+	api.Authenticator = stubAuthenticator{principal: apiauth.Principal{Roles: []apiauth.Role{apiauth.RoleReadOnly}}}
+	api.NetService = stubService{}
+	api.RequestObserver = observer
+	router := api.Router()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/subnets/7/ips", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/subnets/7", nil),
+		httptest.NewRequest(http.MethodGet, "/nope", nil),
+	} {
+		req.Header.Set("Authorization", "Bearer token")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []observedRequest{
+		{method: http.MethodGet, route: "/api/v1/subnets/{id}/ips", status: http.StatusOK},
+		{method: http.MethodDelete, route: "/api/v1/subnets/{id}", status: http.StatusForbidden},
+		{method: http.MethodGet, route: "unmatched", status: http.StatusNotFound},
+	}
+	if len(observer.requests) != len(want) {
+		t.Fatalf("expected %d observations, got %+v", len(want), observer.requests)
+	}
+	for i := range want {
+		if observer.requests[i] != want[i] {
+			t.Fatalf("observation %d: expected %+v, got %+v", i, want[i], observer.requests[i])
+		}
+	}
+}
+
+func TestMetricsEndpointBypassesAuthentication(t *testing.T) {
+	api := newTestAPI()
+	api.Authenticator = stubAuthenticator{err: apiauth.ErrInvalidToken}
+	api.MetricsHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestMetricsEndpointRequiresConfiguredToken(t *testing.T) {
+	api := newTestAPI()
+	api.MetricsToken = "scrape-secret"
+	api.MetricsHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router := api.Router()
+
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		problem := assertProblem(t, rec, http.StatusUnauthorized, "invalid metrics token")
+		if !strings.HasSuffix(problem.Type, "#unauthorized") || rec.Header().Get("WWW-Authenticate") != `Bearer realm="metrics"` {
+			t.Fatalf("unexpected rejection for token %q: %+v, %q", token, problem, rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected scrape with token, got %d", rec.Code)
+	}
+}
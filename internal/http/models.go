@@ -1,6 +1,7 @@
 package http
 
 import (
+	"net/netip"
 	"time"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
@@ -15,10 +16,27 @@ type SubnetResponse struct {
 	UsedIPs     int64      `json:"used_ips"`
 	TotalIPs    int64      `json:"total_ips"`
 	Description string     `json:"description" example:"Office network"`
+	Gateway     string     `json:"gateway,omitempty" example:"10.0.0.1"`
+	DNSServers  []string   `json:"dns_servers" example:"10.0.0.53"`
+	DHCPPools   []DHCPPool `json:"dhcp_pools"`
 	CreatedAt   time.Time  `json:"created_at" example:"2024-05-10T15:04:05Z"`
 	UpdatedAt   time.Time  `json:"updated_at" example:"2024-05-10T15:04:05Z"`
 }
 
+// DHCPPool is an inclusive range of addresses handed out by DHCP.
+type DHCPPool struct {
+	Start string `json:"start" example:"10.0.0.100"`
+	End   string `json:"end" example:"10.0.0.199"`
+}
+
+// SubnetDHCPRequest replaces a subnet's DHCP settings. An empty gateway
+// removes it.
+type SubnetDHCPRequest struct {
+	Gateway    string     `json:"gateway" example:"10.0.0.1"`
+	DNSServers []string   `json:"dns_servers" example:"10.0.0.53"`
+	Pools      []DHCPPool `json:"pools"`
+}
+
 // CreateSubnetRequest is the payload accepted when creating a subnet.
 type CreateSubnetRequest struct {
 	CIDR        string     `json:"cidr" example:"10.0.0.0/24" validate:"required"`
@@ -55,11 +73,6 @@ type SiteStatisticsResponse struct {
 	FreeIPCount  int64 `json:"free_ip_count"`
 }
 
-// ErrorResponse is a simple envelope for error messages.
-type ErrorResponse struct {
-	Error string `json:"error" example:"subnet not found"`
-}
-
 type ReportingSettingsRequest struct {
 	Cadence       string `json:"cadence" example:"hourly" enums:"hourly,daily,weekly"`
 	RetentionDays int32  `json:"retention_days" example:"30" minimum:"1" maximum:"180"`
@@ -104,10 +117,35 @@ type IPResponse struct {
 	ID                 string                      `json:"id" example:"50e8400-e29b-41d4-a716-446655440000"`
 	IP                 string                      `json:"ip" example:"10.0.0.1"`
 	Hostname           string                      `json:"hostname" example:"printer-1"`
+	MACAddress         string                      `json:"mac_address,omitempty" example:"52:54:00:12:34:56"`
+	LastSeenAt         *time.Time                  `json:"last_seen_at,omitempty" example:"2026-10-14T10:00:00Z"`
 	SubnetID           int64                       `json:"subnet_id" example:"4"`
 	CreatedAt          time.Time                   `json:"created_at" example:"2024-05-10T15:04:05Z"`
 	UpdatedAt          time.Time                   `json:"updated_at" example:"2024-05-10T15:04:05Z"`
 	KubernetesServices []KubernetesServiceResponse `json:"kubernetes_services"`
+	KubernetesObjects  []KubernetesObjectResponse  `json:"kubernetes_objects"`
+	// KubernetesAllocation names the LoadBalancer Service the allocator gave
+	// the address to.
+	KubernetesAllocation *KubernetesAllocationResponse `json:"kubernetes_allocation,omitempty"`
+	// KubernetesRegistration names the Service discovery created the record
+	// for. The record is deleted once the Service is gone.
+	KubernetesRegistration *KubernetesRegistrationResponse `json:"kubernetes_registration,omitempty"`
+}
+
+type KubernetesAllocationResponse struct {
+	Controller  string    `json:"controller" example:"prod-cluster"`
+	ServiceUID  string    `json:"service_uid" example:"6f4d9f2a-5c1e-4d7b-9a43-1c0f3e2b8a10"`
+	Namespace   string    `json:"namespace" example:"ingress-nginx"`
+	Name        string    `json:"name" example:"ingress-nginx-controller"`
+	AllocatedAt time.Time `json:"allocated_at" example:"2026-10-18T10:00:00Z"`
+}
+
+type KubernetesRegistrationResponse struct {
+	Source       KubernetesSourceResponse `json:"source"`
+	ServiceUID   string                   `json:"service_uid" example:"6f4d9f2a-5c1e-4d7b-9a43-1c0f3e2b8a10"`
+	Namespace    string                   `json:"namespace" example:"metallb-system"`
+	Name         string                   `json:"name" example:"edge-gateway"`
+	RegisteredAt time.Time                `json:"registered_at" example:"2026-10-18T10:00:00Z"`
 }
 
 type KubernetesSourceResponse struct {
@@ -171,11 +209,57 @@ type KubernetesServiceObservationResponse struct {
 	ObservedAt   time.Time                               `json:"observed_at" example:"2026-08-01T10:00:00Z"`
 }
 
+// KubernetesObjectResponse is a discovered object whose address matched the
+// IP. node_name is the Node that owns or runs the address; hostnames are
+// the names an Ingress, Gateway or HTTPRoute serves on it.
+type KubernetesObjectResponse struct {
+	Source      KubernetesSourceResponse                `json:"source"`
+	Kind        string                                  `json:"kind" example:"pod" enums:"node,pod,endpoint_slice,ingress,gateway,http_route"`
+	UID         string                                  `json:"uid" example:"9d4c1a2b-1234-5678-90ab-abcdefabcdef"`
+	Namespace   string                                  `json:"namespace,omitempty" example:"kube-system"`
+	Name        string                                  `json:"name" example:"node-exporter-x7k2p"`
+	HostNetwork bool                                    `json:"host_network" example:"true"`
+	AddressKind string                                  `json:"address_kind" example:"pod_ip" enums:"internal_ip,external_ip,pod_ip,endpoint,load_balancer,gateway"`
+	NodeName    string                                  `json:"node_name,omitempty" example:"worker-3"`
+	Hostnames   []KubernetesHostnameObservationResponse `json:"hostnames"`
+	ObservedAt  time.Time                               `json:"observed_at" example:"2026-08-01T10:00:00Z"`
+}
+
+type KubernetesObjectAddressObservationResponse struct {
+	IP                 string  `json:"ip" example:"10.0.0.23"`
+	Kind               string  `json:"kind" example:"pod_ip" enums:"internal_ip,external_ip,pod_ip,endpoint,load_balancer,gateway"`
+	NodeName           string  `json:"node_name,omitempty" example:"worker-3"`
+	MatchStatus        string  `json:"match_status" example:"unmatched" enums:"matched,unmatched,ambiguous"`
+	MatchCount         int     `json:"match_count" example:"0"`
+	MatchedIPAddressID *string `json:"matched_ip_address_id,omitempty" example:"50e8400-e29b-41d4-a716-446655440000"`
+	MatchedSubnetID    *int64  `json:"matched_subnet_id,omitempty" example:"4"`
+	// LoadBalancerServices are the Services, as namespace/name, that have
+	// this address as a load-balancer IP.
+	LoadBalancerServices []string `json:"load_balancer_services" example:"ingress-nginx/ingress-nginx-controller"`
+}
+
+// KubernetesObjectObservationResponse lists only the object's addresses
+// inside the requested subnet.
+type KubernetesObjectObservationResponse struct {
+	Source      KubernetesSourceResponse                     `json:"source"`
+	Kind        string                                       `json:"kind" example:"ingress" enums:"node,pod,endpoint_slice,ingress,gateway,http_route"`
+	UID         string                                       `json:"uid" example:"9d4c1a2b-1234-5678-90ab-abcdefabcdef"`
+	Namespace   string                                       `json:"namespace,omitempty" example:"ipam"`
+	Name        string                                       `json:"name" example:"ipam"`
+	HostNetwork bool                                         `json:"host_network" example:"false"`
+	Addresses   []KubernetesObjectAddressObservationResponse `json:"addresses"`
+	Hostnames   []KubernetesHostnameObservationResponse      `json:"hostnames"`
+	ObservedAt  time.Time                                    `json:"observed_at" example:"2026-08-01T10:00:00Z"`
+}
+
 type KubernetesDiscoveryStatusResponse struct {
 	Source        KubernetesSourceResponse `json:"source"`
 	SiteID        uuid.UUID                `json:"site_id"`
 	ClusterDomain string                   `json:"cluster_domain"`
 	Namespaces    []string                 `json:"namespaces"`
+	ObjectKinds   []string                 `json:"object_kinds" example:"node,pod"`
+	PodSelector   string                   `json:"pod_selector,omitempty" example:"app.kubernetes.io/part-of=edge"`
+	AutoRegister  string                   `json:"auto_register" example:"register_lb_only" enums:"off,register,register_lb_only"`
 	State         string                   `json:"state" example:"healthy"`
 	LastAttemptAt *time.Time               `json:"last_attempt_at"`
 	LastSuccessAt *time.Time               `json:"last_success_at"`
@@ -185,17 +269,201 @@ type KubernetesDiscoveryStatusResponse struct {
 	Unmatched     int                      `json:"unmatched"`
 	Ambiguous     int                      `json:"ambiguous"`
 	NoUsableIP    int                      `json:"no_usable_ip"`
+	Objects       int                      `json:"objects"`
+	// ObjectError is why the latest listing of the source's object kinds
+	// failed; its Services are still discovered.
+	ObjectError string `json:"object_error,omitempty" example:"list pods in namespace \"apps\": pods is forbidden"`
+	// Managed sources were created through the API and can be changed or
+	// deleted there; the others come from the deployment's configuration.
+	Managed  bool                              `json:"managed"`
+	Settings *KubernetesSourceSettingsResponse `json:"settings,omitempty"`
+}
+
+// KubernetesReconcileResultResponse counts the Services of a source after an
+// on-demand discovery cycle.
+type KubernetesReconcileResultResponse struct {
+	Source     string `json:"source" example:"prod-a"`
+	Services   int    `json:"services" example:"42"`
+	Matched    int    `json:"matched" example:"38"`
+	Unmatched  int    `json:"unmatched" example:"3"`
+	Ambiguous  int    `json:"ambiguous" example:"0"`
+	NoUsableIP int    `json:"no_usable_ip" example:"1"`
+}
+
+// KubernetesDiscoveryRunResponse is one publication of a source's Services
+// or one failed attempt, whose counts are zero.
+type KubernetesDiscoveryRunResponse struct {
+	ID         int64     `json:"id" example:"812"`
+	StartedAt  time.Time `json:"started_at" example:"2026-08-01T10:00:00Z"`
+	FinishedAt time.Time `json:"finished_at" example:"2026-08-01T10:00:02Z"`
+	Services   int       `json:"services" example:"42"`
+	Matched    int       `json:"matched" example:"38"`
+	Unmatched  int       `json:"unmatched" example:"3"`
+	Ambiguous  int       `json:"ambiguous" example:"0"`
+	NoUsableIP int       `json:"no_usable_ip" example:"1"`
+	Error      string    `json:"error"`
+	Changes    int       `json:"changes" example:"2"`
+}
+
+type KubernetesDiscoveryEventResponse struct {
+	ID                int64     `json:"id" example:"5120"`
+	RunID             int64     `json:"run_id" example:"812"`
+	ServiceUID        string    `json:"service_uid" example:"1f6b2c58-5c2d-4b8f-9e79-0d8f7b6c1a10"`
+	Namespace         string    `json:"namespace" example:"commerce"`
+	Name              string    `json:"name" example:"orders"`
+	Change            string    `json:"change" example:"address_changed" enums:"appeared,address_changed,stale,purged"`
+	PreviousAddresses []string  `json:"previous_addresses" example:"10.96.12.4"`
+	Addresses         []string  `json:"addresses" example:"10.96.12.9"`
+	OccurredAt        time.Time `json:"occurred_at" example:"2026-08-01T10:00:02Z"`
+}
+
+// KubernetesSourceSettingsResponse never includes the kubeconfig.
+type KubernetesSourceSettingsResponse struct {
+	AuthMode              string `json:"auth_mode" example:"kubeconfig" enums:"in_cluster,kubeconfig"`
+	KubeconfigContext     string `json:"kubeconfig_context" example:"prod-a"`
+	IntervalSeconds       int64  `json:"interval_seconds" example:"300"`
+	RequestTimeoutSeconds int64  `json:"request_timeout_seconds" example:"15"`
+	StaleRetentionSeconds int64  `json:"stale_retention_seconds" example:"604800"`
+}
+
+// KubernetesSourceRequest is the payload accepted when creating a discovery
+// source. Omitted durations take the discovery defaults. A kubeconfig must
+// embed its certificates and token, since the server cannot read files or
+// run credential plugins on behalf of API clients.
+type KubernetesSourceRequest struct {
+	Key                   string    `json:"key" example:"prod-a"`
+	Name                  string    `json:"name" example:"Production A"`
+	SiteID                uuid.UUID `json:"site_id"`
+	ClusterDomain         string    `json:"cluster_domain" example:"cluster.local"`
+	Namespaces            []string  `json:"namespaces" example:"default,apps"`
+	ObjectKinds           []string  `json:"object_kinds,omitempty" example:"node,pod"`
+	PodSelector           string    `json:"pod_selector,omitempty" example:"app.kubernetes.io/part-of=edge"`
+	AutoRegister          string    `json:"auto_register,omitempty" example:"register_lb_only" enums:"off,register,register_lb_only"`
+	AuthMode              string    `json:"auth_mode" example:"kubeconfig" enums:"in_cluster,kubeconfig"`
+	Kubeconfig            string    `json:"kubeconfig,omitempty"`
+	KubeconfigContext     string    `json:"kubeconfig_context,omitempty" example:"prod-a"`
+	IntervalSeconds       *int32    `json:"interval_seconds,omitempty" example:"300"`
+	RequestTimeoutSeconds *int32    `json:"request_timeout_seconds,omitempty" example:"15"`
+	StaleRetentionSeconds *int32    `json:"stale_retention_seconds,omitempty" example:"604800"`
+}
+
+// UpdateKubernetesSourceRequest changes only the fields that are present.
+// Switching auth_mode to in_cluster discards the stored kubeconfig, and an
+// empty object_kinds list stops discovering objects other than Services.
+type UpdateKubernetesSourceRequest struct {
+	Name                  *string    `json:"name,omitempty" example:"Production A"`
+	SiteID                *uuid.UUID `json:"site_id,omitempty"`
+	ClusterDomain         *string    `json:"cluster_domain,omitempty" example:"cluster.local"`
+	Namespaces            []string   `json:"namespaces,omitempty" example:"*"`
+	ObjectKinds           *[]string  `json:"object_kinds,omitempty" example:"node"`
+	PodSelector           *string    `json:"pod_selector,omitempty" example:"app.kubernetes.io/part-of=edge"`
+	AutoRegister          *string    `json:"auto_register,omitempty" example:"register" enums:"off,register,register_lb_only"`
+	AuthMode              *string    `json:"auth_mode,omitempty" example:"kubeconfig" enums:"in_cluster,kubeconfig"`
+	Kubeconfig            *string    `json:"kubeconfig,omitempty"`
+	KubeconfigContext     *string    `json:"kubeconfig_context,omitempty" example:"prod-a"`
+	IntervalSeconds       *int32     `json:"interval_seconds,omitempty" example:"300"`
+	RequestTimeoutSeconds *int32     `json:"request_timeout_seconds,omitempty" example:"15"`
+	StaleRetentionSeconds *int32     `json:"stale_retention_seconds,omitempty" example:"604800"`
 }
 
 // CreateIPRequest is the payload accepted when creating a ip.
 type CreateIPRequest struct {
-	IP       string `json:"ip" example:"10.0.0.1"`
-	Hostname string `json:"hostname" example:"printer-1"`
+	IP         string `json:"ip" example:"10.0.0.1"`
+	Hostname   string `json:"hostname" example:"printer-1"`
+	MACAddress string `json:"mac_address" example:"52:54:00:12:34:56"`
 }
 
-// UpdateIPRequest is the payload accepted when updating an ip.
+// UpdateIPRequest is the payload accepted when updating an ip. Omitting
+// mac_address keeps the current one; an empty string removes it.
 type UpdateIPRequest struct {
-	Hostname string `json:"hostname" example:"pc-1"`
+	Hostname   string  `json:"hostname" example:"pc-1"`
+	MACAddress *string `json:"mac_address" example:"52:54:00:12:34:56"`
+}
+
+// WebhookSubscriptionRequest is the payload accepted when creating a webhook.
+// An empty secret asks the server to generate one.
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url" example:"https://cmdb.example.com/hooks/ipam"`
+	Description string   `json:"description" example:"CMDB sync"`
+	EventTypes  []string `json:"event_types" example:"subnet.*,ip.created"`
+	Secret      string   `json:"secret,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// UpdateWebhookSubscriptionRequest changes only the fields that are present.
+type UpdateWebhookSubscriptionRequest struct {
+	URL         *string  `json:"url,omitempty" example:"https://cmdb.example.com/hooks/ipam"`
+	Description *string  `json:"description,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty" example:"*"`
+	Secret      *string  `json:"secret,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+type WebhookSubscriptionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url" example:"https://cmdb.example.com/hooks/ipam"`
+	Description string    `json:"description" example:"CMDB sync"`
+	EventTypes  []string  `json:"event_types" example:"subnet.*,ip.created"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreatedWebhookSubscriptionResponse is the only response that includes the
+// signing secret.
+type CreatedWebhookSubscriptionResponse struct {
+	WebhookSubscriptionResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeadLetterResponse struct {
+	ID             int64      `json:"id" example:"12"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	URL            string     `json:"url"`
+	EventID        int64      `json:"event_id" example:"340"`
+	EventType      string     `json:"event_type" example:"ip.created"`
+	ObjectType     string     `json:"object_type" example:"ip"`
+	ObjectID       string     `json:"object_id"`
+	Attempts       int32      `json:"attempts" example:"10"`
+	LastStatusCode *int32     `json:"last_status_code,omitempty" example:"503"`
+	LastError      string     `json:"last_error" example:"unexpected response status 503"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// DNSZoneRequest attaches a forward zone to exactly one of a site or a
+// subnet.
+type DNSZoneRequest struct {
+	Name     string     `json:"name" example:"lab.example.com" validate:"required"`
+	SiteID   *uuid.UUID `json:"site_id,omitempty" example:"50e8400-e29b-41d4-a716-446655440000"`
+	SubnetID *int64     `json:"subnet_id,omitempty" example:"7"`
+	TTL      *int32     `json:"ttl,omitempty" example:"3600"`
+}
+
+// DNSZoneResponse describes a stored forward zone or a computed reverse
+// zone. Reverse zones have a network instead of an id and attachment.
+type DNSZoneResponse struct {
+	ID        *uuid.UUID `json:"id,omitempty"`
+	Name      string     `json:"name" example:"lab.example.com"`
+	Kind      string     `json:"kind" example:"forward" enums:"forward,reverse"`
+	SiteID    *uuid.UUID `json:"site_id,omitempty"`
+	SubnetID  *int64     `json:"subnet_id,omitempty" example:"7"`
+	Network   string     `json:"network,omitempty" example:"10.0.0.0/24"`
+	TTL       int32      `json:"ttl" example:"3600"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ChangeEventResponse is the data line of a live event stream frame. It
+// identifies the changed object; clients refetch the object to display it.
+type ChangeEventResponse struct {
+	ID         int64      `json:"id,omitempty" example:"340"`
+	Type       string     `json:"type" example:"ip.created"`
+	ObjectType string     `json:"object_type" example:"ip"`
+	ObjectID   string     `json:"object_id"`
+	SiteID     *uuid.UUID `json:"site_id,omitempty"`
+	SubnetID   *int64     `json:"subnet_id,omitempty" example:"7"`
+	OccurredAt time.Time  `json:"occurred_at"`
 }
 
 func subnetToResponse(s domain.Subnet) SubnetResponse {
@@ -203,16 +471,28 @@ func subnetToResponse(s domain.Subnet) SubnetResponse {
 	if s.SiteID != uuid.Nil {
 		siteID = &s.SiteID
 	}
-	return SubnetResponse{
+	response := SubnetResponse{
 		ID:          s.ID,
 		CIDR:        s.CIDR.String(),
 		SiteID:      siteID,
 		UsedIPs:     s.UsedIPCount,
 		TotalIPs:    s.TotalIPCount,
 		Description: s.Description,
+		DNSServers:  make([]string, 0, len(s.DNSServers)),
+		DHCPPools:   make([]DHCPPool, 0, len(s.DHCPPools)),
 		CreatedAt:   s.CreatedAt,
 		UpdatedAt:   s.UpdatedAt,
 	}
+	if s.Gateway.IsValid() {
+		response.Gateway = s.Gateway.String()
+	}
+	for _, server := range s.DNSServers {
+		response.DNSServers = append(response.DNSServers, server.String())
+	}
+	for _, pool := range s.DHCPPools {
+		response.DHCPPools = append(response.DHCPPools, DHCPPool{Start: pool.Start.String(), End: pool.End.String()})
+	}
+	return response
 }
 
 func reportingSettingsToResponse(settings domain.ReportingSettings) ReportingSettingsResponse {
@@ -248,14 +528,38 @@ func ipToResponse(i domain.IPAddress) IPResponse {
 		ID:                 string(i.ID),
 		IP:                 i.IP.String(),
 		Hostname:           i.Hostname,
+		MACAddress:         i.MACAddress.String(),
+		LastSeenAt:         i.LastSeenAt,
 		SubnetID:           i.SubnetID,
 		CreatedAt:          i.CreatedAt,
 		UpdatedAt:          i.UpdatedAt,
 		KubernetesServices: make([]KubernetesServiceResponse, 0, len(i.KubernetesServices)),
+		KubernetesObjects:  make([]KubernetesObjectResponse, 0, len(i.KubernetesObjects)),
 	}
 	for _, service := range i.KubernetesServices {
 		response.KubernetesServices = append(response.KubernetesServices, kubernetesServiceToResponse(service))
 	}
+	for _, object := range i.KubernetesObjects {
+		response.KubernetesObjects = append(response.KubernetesObjects, KubernetesObjectResponse{
+			Source: KubernetesSourceResponse{Key: object.Source.Key, Name: object.Source.Name},
+			Kind:   object.Kind, UID: object.UID, Namespace: object.Namespace, Name: object.Name,
+			HostNetwork: object.HostNetwork, AddressKind: object.AddressKind, NodeName: object.NodeName,
+			Hostnames: kubernetesObjectHostnamesToResponse(object.Hostnames), ObservedAt: object.ObservedAt,
+		})
+	}
+	if allocation := i.KubernetesAllocation; allocation != nil {
+		response.KubernetesAllocation = &KubernetesAllocationResponse{
+			Controller: allocation.Controller, ServiceUID: allocation.ServiceUID,
+			Namespace: allocation.Namespace, Name: allocation.Name, AllocatedAt: allocation.AllocatedAt,
+		}
+	}
+	if registration := i.KubernetesRegistration; registration != nil {
+		response.KubernetesRegistration = &KubernetesRegistrationResponse{
+			Source:     KubernetesSourceResponse{Key: registration.Source.Key, Name: registration.Source.Name},
+			ServiceUID: registration.ServiceUID, Namespace: registration.Namespace, Name: registration.Name,
+			RegisteredAt: registration.RegisteredAt,
+		}
+	}
 	return response
 }
 
@@ -332,6 +636,46 @@ func kubernetesServiceObservationsToResponse(services []domain.KubernetesService
 	return responses
 }
 
+func kubernetesObjectObservationsToResponse(objects []domain.KubernetesObjectObservation) []KubernetesObjectObservationResponse {
+	responses := make([]KubernetesObjectObservationResponse, 0, len(objects))
+	for _, object := range objects {
+		response := KubernetesObjectObservationResponse{
+			Source:      KubernetesSourceResponse{Key: object.Source.Key, Name: object.Source.Name},
+			Kind:        object.Kind,
+			UID:         object.UID,
+			Namespace:   object.Namespace,
+			Name:        object.Name,
+			HostNetwork: object.HostNetwork,
+			Addresses:   make([]KubernetesObjectAddressObservationResponse, 0, len(object.Addresses)),
+			Hostnames:   kubernetesObjectHostnamesToResponse(object.Hostnames),
+			ObservedAt:  object.ObservedAt,
+		}
+		for _, address := range object.Addresses {
+			var matchedIPAddressID *string
+			if address.MatchedIPAddressID != nil {
+				id := string(*address.MatchedIPAddressID)
+				matchedIPAddressID = &id
+			}
+			response.Addresses = append(response.Addresses, KubernetesObjectAddressObservationResponse{
+				IP: address.IP.String(), Kind: address.Kind, NodeName: address.NodeName,
+				MatchStatus: string(address.MatchStatus), MatchCount: address.MatchCount,
+				MatchedIPAddressID: matchedIPAddressID, MatchedSubnetID: address.MatchedSubnetID,
+				LoadBalancerServices: append(make([]string, 0, len(address.LoadBalancerServices)), address.LoadBalancerServices...),
+			})
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func kubernetesObjectHostnamesToResponse(hostnames []domain.KubernetesObjectHostname) []KubernetesHostnameObservationResponse {
+	responses := make([]KubernetesHostnameObservationResponse, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		responses = append(responses, KubernetesHostnameObservationResponse{Kind: hostname.Kind, Hostname: hostname.Hostname})
+	}
+	return responses
+}
+
 func kubernetesStatusesToResponse(statuses []domain.KubernetesSourceStatus) []KubernetesDiscoveryStatusResponse {
 	responses := make([]KubernetesDiscoveryStatusResponse, 0, len(statuses))
 	for _, status := range statuses {
@@ -339,14 +683,110 @@ func kubernetesStatusesToResponse(statuses []domain.KubernetesSourceStatus) []Ku
 			Source: KubernetesSourceResponse{Key: status.Source.Key, Name: status.Source.Name},
 			SiteID: status.SiteID, ClusterDomain: status.ClusterDomain,
 			Namespaces: append([]string(nil), status.Namespaces...), State: status.State,
-			LastAttemptAt: status.LastAttemptAt, LastSuccessAt: status.LastSuccessAt, LastError: status.LastError,
+			ObjectKinds: append(make([]string, 0, len(status.ObjectKinds)), status.ObjectKinds...), PodSelector: status.PodSelector,
+			AutoRegister: status.AutoRegister, LastAttemptAt: status.LastAttemptAt, LastSuccessAt: status.LastSuccessAt, LastError: status.LastError,
 			Services: status.Services, Matched: status.Matched, Unmatched: status.Unmatched, Ambiguous: status.Ambiguous,
-			NoUsableIP: status.NoUsableIP,
+			NoUsableIP: status.NoUsableIP, Objects: status.Objects, ObjectError: status.ObjectError, Managed: status.Managed, Settings: kubernetesSettingsToResponse(status.Settings),
+		})
+	}
+	return responses
+}
+
+func kubernetesRunsToResponse(runs []domain.KubernetesDiscoveryRun) []KubernetesDiscoveryRunResponse {
+	responses := make([]KubernetesDiscoveryRunResponse, 0, len(runs))
+	for _, run := range runs {
+		responses = append(responses, KubernetesDiscoveryRunResponse{
+			ID: run.ID, StartedAt: run.StartedAt, FinishedAt: run.FinishedAt,
+			Services: run.Result.Services, Matched: run.Result.Matched, Unmatched: run.Result.Unmatched,
+			Ambiguous: run.Result.Ambiguous, NoUsableIP: run.Result.NoUsableIP, Error: run.Error, Changes: run.Changes,
+		})
+	}
+	return responses
+}
+
+func kubernetesEventsToResponse(events []domain.KubernetesDiscoveryEvent) []KubernetesDiscoveryEventResponse {
+	responses := make([]KubernetesDiscoveryEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, KubernetesDiscoveryEventResponse{
+			ID: event.ID, RunID: event.RunID, ServiceUID: event.ServiceUID, Namespace: event.Namespace,
+			Name: event.Name, Change: event.Change, PreviousAddresses: addressStrings(event.PreviousAddresses),
+			Addresses: addressStrings(event.Addresses), OccurredAt: event.OccurredAt,
 		})
 	}
 	return responses
 }
 
+func addressStrings(addresses []netip.Addr) []string {
+	result := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		result = append(result, address.String())
+	}
+	return result
+}
+
+func kubernetesSettingsToResponse(settings *domain.KubernetesSourceSettings) *KubernetesSourceSettingsResponse {
+	if settings == nil {
+		return nil
+	}
+	return &KubernetesSourceSettingsResponse{
+		AuthMode:              settings.AuthMode,
+		KubeconfigContext:     settings.KubeconfigContext,
+		IntervalSeconds:       int64(settings.ReconcileInterval / time.Second),
+		RequestTimeoutSeconds: int64(settings.RequestTimeout / time.Second),
+		StaleRetentionSeconds: int64(settings.StaleRetention / time.Second),
+	}
+}
+
+func (r KubernetesSourceRequest) toInput() domain.CreateKubernetesSourceInput {
+	return domain.CreateKubernetesSourceInput{
+		Key:               r.Key,
+		Name:              r.Name,
+		SiteID:            r.SiteID,
+		ClusterDomain:     r.ClusterDomain,
+		Namespaces:        r.Namespaces,
+		ObjectKinds:       r.ObjectKinds,
+		PodSelector:       r.PodSelector,
+		AutoRegister:      r.AutoRegister,
+		AuthMode:          r.AuthMode,
+		Kubeconfig:        r.Kubeconfig,
+		KubeconfigContext: r.KubeconfigContext,
+		ReconcileInterval: secondsToDuration(r.IntervalSeconds),
+		RequestTimeout:    secondsToDuration(r.RequestTimeoutSeconds),
+		StaleRetention:    secondsToDuration(r.StaleRetentionSeconds),
+	}
+}
+
+func (r UpdateKubernetesSourceRequest) toInput(key string) domain.UpdateKubernetesSourceInput {
+	var objectKinds []string
+	if r.ObjectKinds != nil {
+		objectKinds = append(make([]string, 0, len(*r.ObjectKinds)), *r.ObjectKinds...)
+	}
+	return domain.UpdateKubernetesSourceInput{
+		Key:               key,
+		Name:              r.Name,
+		SiteID:            r.SiteID,
+		ClusterDomain:     r.ClusterDomain,
+		Namespaces:        r.Namespaces,
+		ObjectKinds:       objectKinds,
+		PodSelector:       r.PodSelector,
+		AutoRegister:      r.AutoRegister,
+		AuthMode:          r.AuthMode,
+		Kubeconfig:        r.Kubeconfig,
+		KubeconfigContext: r.KubeconfigContext,
+		ReconcileInterval: secondsToDuration(r.IntervalSeconds),
+		RequestTimeout:    secondsToDuration(r.RequestTimeoutSeconds),
+		StaleRetention:    secondsToDuration(r.StaleRetentionSeconds),
+	}
+}
+
+func secondsToDuration(seconds *int32) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	duration := time.Duration(*seconds) * time.Second
+	return &duration
+}
+
 func (r CreateSubnetRequest) toInput() domain.CreateSubnetInput {
 	return domain.CreateSubnetInput{
 		CIDR:        r.CIDR,
@@ -409,17 +849,27 @@ func siteStatisticsToResponse(statistics []domain.SiteStatistics) []SiteStatisti
 
 func (i CreateIPRequest) toInput() domain.CreateIPInput {
 	return domain.CreateIPInput{
-		IP:       i.IP,
-		Hostname: i.Hostname,
+		IP:         i.IP,
+		Hostname:   i.Hostname,
+		MACAddress: i.MACAddress,
 	}
 }
 
 func (r UpdateIPRequest) toInput() domain.UpdateIPInput {
 	return domain.UpdateIPInput{
-		Hostname: r.Hostname,
+		Hostname:   r.Hostname,
+		MACAddress: r.MACAddress,
 	}
 }
 
+func (r SubnetDHCPRequest) toInput(id int64) domain.UpdateSubnetDHCPInput {
+	input := domain.UpdateSubnetDHCPInput{ID: id, Gateway: r.Gateway, DNSServers: r.DNSServers}
+	for _, pool := range r.Pools {
+		input.Pools = append(input.Pools, domain.DHCPPoolInput{Start: pool.Start, End: pool.End})
+	}
+	return input
+}
+
 func importResultToResponse(result domain.ImportResult) ImportResponse {
 	errors := make([]RowError, 0, len(result.Errors))
 	for _, rowError := range result.Errors {
@@ -427,3 +877,106 @@ func importResultToResponse(result domain.ImportResult) ImportResponse {
 	}
 	return ImportResponse{Processed: result.Processed, Created: result.Created, Updated: result.Updated, Failed: result.Failed, Errors: errors}
 }
+
+func (r WebhookSubscriptionRequest) toInput() domain.CreateWebhookSubscriptionInput {
+	return domain.CreateWebhookSubscriptionInput{
+		URL:         r.URL,
+		Description: r.Description,
+		EventTypes:  r.EventTypes,
+		Secret:      r.Secret,
+		Active:      r.Active,
+	}
+}
+
+func (r UpdateWebhookSubscriptionRequest) toInput(id uuid.UUID) domain.UpdateWebhookSubscriptionInput {
+	return domain.UpdateWebhookSubscriptionInput{
+		ID:          id,
+		URL:         r.URL,
+		Description: r.Description,
+		EventTypes:  r.EventTypes,
+		Secret:      r.Secret,
+		Active:      r.Active,
+	}
+}
+
+func webhookSubscriptionToResponse(subscription domain.WebhookSubscription) WebhookSubscriptionResponse {
+	return WebhookSubscriptionResponse{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		Description: subscription.Description,
+		EventTypes:  subscription.EventTypes,
+		Active:      subscription.Active,
+		CreatedAt:   subscription.CreatedAt,
+		UpdatedAt:   subscription.UpdatedAt,
+	}
+}
+
+func webhookSubscriptionsToResponse(subscriptions []domain.WebhookSubscription) []WebhookSubscriptionResponse {
+	responses := make([]WebhookSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		responses = append(responses, webhookSubscriptionToResponse(subscription))
+	}
+	return responses
+}
+
+func webhookDeadLettersToResponse(deadLetters []domain.WebhookDeadLetter) []WebhookDeadLetterResponse {
+	responses := make([]WebhookDeadLetterResponse, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		responses = append(responses, WebhookDeadLetterResponse{
+			ID:             deadLetter.ID,
+			SubscriptionID: deadLetter.SubscriptionID,
+			URL:            deadLetter.URL,
+			EventID:        deadLetter.EventID,
+			EventType:      deadLetter.EventType,
+			ObjectType:     deadLetter.ObjectType,
+			ObjectID:       deadLetter.ObjectID,
+			Attempts:       deadLetter.Attempts,
+			LastStatusCode: deadLetter.LastStatusCode,
+			LastError:      deadLetter.LastError,
+			LastAttemptAt:  deadLetter.LastAttemptAt,
+			CreatedAt:      deadLetter.CreatedAt,
+		})
+	}
+	return responses
+}
+
+func changeEventToResponse(event domain.ChangeEvent) ChangeEventResponse {
+	return ChangeEventResponse{
+		ID:         event.ID,
+		Type:       event.Type,
+		ObjectType: event.ObjectType,
+		ObjectID:   event.ObjectID,
+		SiteID:     event.SiteID,
+		SubnetID:   event.SubnetID,
+		OccurredAt: event.OccurredAt,
+	}
+}
+
+func (r DNSZoneRequest) toInput() domain.CreateDNSZoneInput {
+	return domain.CreateDNSZoneInput{Name: r.Name, SiteID: r.SiteID, SubnetID: r.SubnetID, TTL: r.TTL}
+}
+
+func dnsZoneToResponse(zone domain.DNSZone) DNSZoneResponse {
+	response := DNSZoneResponse{
+		Name:     zone.Name,
+		Kind:     string(zone.Kind),
+		SiteID:   zone.SiteID,
+		SubnetID: zone.SubnetID,
+		TTL:      zone.TTL,
+	}
+	if zone.Kind == domain.DNSZoneReverse {
+		response.Network = zone.Network.String()
+		return response
+	}
+	id, createdAt, updatedAt := zone.ID, zone.CreatedAt, zone.UpdatedAt
+	response.ID, response.CreatedAt, response.UpdatedAt = &id, &createdAt, &updatedAt
+	return response
+}
+
+func dnsZonesToResponse(zones []domain.DNSZone) []DNSZoneResponse {
+	responses := make([]DNSZoneResponse, 0, len(zones))
+	for _, zone := range zones {
+		responses = append(responses, dnsZoneToResponse(zone))
+	}
+	return responses
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+const problemTypeBase = "https://github.com/Flarenzy/simple-k8s-app/blob/main/docs/problems.md#"
+
+// Problem is an RFC 9457 problem details body. Type is stable and documented
+// in docs/problems.md; clients should branch on it rather than on Detail.
+type Problem struct {
+	Type      string              `json:"type" example:"https://github.com/Flarenzy/simple-k8s-app/blob/main/docs/problems.md#not-found"`
+	Title     string              `json:"title" example:"Resource not found"`
+	Status    int                 `json:"status" example:"404"`
+	Detail    string              `json:"detail,omitempty" example:"subnet not found"`
+	Instance  string              `json:"instance,omitempty" example:"/api/v1/subnets/42"`
+	RequestID string              `json:"request_id,omitempty" example:"5f0c6c1e-4c1d-4df3-9d59-2a4a8f0f3b8e"`
+	Errors    []ProblemFieldError `json:"errors,omitempty"`
+}
+
+// ProblemFieldError names one invalid request field.
+type ProblemFieldError struct {
+	Field   string `json:"field" example:"cidr"`
+	Message string `json:"message" example:"invalid cidr"`
+}
+
+type problemType struct {
+	slug  string
+	title string
+}
+
+var (
+	problemBadRequest          = problemType{"bad-request", "Bad request"}
+	problemValidationFailed    = problemType{"validation-failed", "Validation failed"}
+	problemUnauthorized        = problemType{"unauthorized", "Authentication required"}
+	problemForbidden           = problemType{"forbidden", "Permission denied"}
+	problemNotFound            = problemType{"not-found", "Resource not found"}
+	problemConflict            = problemType{"conflict", "Conflict"}
+	problemIPv6Unsupported     = problemType{"ipv6-unsupported", "IPv6 not supported"}
+	problemDiscoveryBusy       = problemType{"discovery-busy", "Discovery already running"}
+	problemIdempotencyKeyReuse = problemType{"idempotency-key-reused", "Idempotency key reused"}
+	problemRateLimited         = problemType{"rate-limited", "Rate limit exceeded"}
+	problemServiceUnavailable  = problemType{"service-unavailable", "Service unavailable"}
+	problemInternalError       = problemType{"internal-error", "Internal server error"}
+)
+
+// problemForError maps a domain error to its documented problem type. The
+// more specific sentinels are checked before the generic ones they wrap.
+func problemForError(err error) (problemType, bool) {
+	switch {
+	case err == nil:
+		return problemType{}, false
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return problemIdempotencyKeyReuse, true
+	case errors.Is(err, domain.ErrIPv6Unsupported):
+		return problemIPv6Unsupported, true
+	case errors.Is(err, domain.ErrDiscoveryBusy):
+		return problemDiscoveryBusy, true
+	case errors.Is(err, domain.ErrConflict):
+		return problemConflict, true
+	case errors.Is(err, domain.ErrInvalidInput):
+		return problemValidationFailed, true
+	case errors.Is(err, domain.ErrNotFound):
+		return problemNotFound, true
+	case errors.Is(err, domain.ErrUnauthorized):
+		return problemUnauthorized, true
+	default:
+		return problemType{}, false
+	}
+}
+
+func problemForStatus(status int) (problemType, bool) {
+	switch status {
+	case http.StatusBadRequest:
+		return problemBadRequest, true
+	case http.StatusUnauthorized:
+		return problemUnauthorized, true
+	case http.StatusForbidden:
+		return problemForbidden, true
+	case http.StatusNotFound:
+		return problemNotFound, true
+	case http.StatusConflict:
+		return problemConflict, true
+	case http.StatusTooManyRequests:
+		return problemRateLimited, true
+	case http.StatusServiceUnavailable:
+		return problemServiceUnavailable, true
+	case http.StatusInternalServerError:
+		return problemInternalError, true
+	default:
+		return problemType{}, false
+	}
+}
+
+// newProblem builds the body for an error response. A client error caused by
+// a recognised domain error takes that error's type; anything else is typed by
+// status, falling back to about:blank as RFC 9457 recommends.
+func newProblem(r *http.Request, status int, detail string, cause error) Problem {
+	kind, ok := problemType{}, false
+	if status < http.StatusInternalServerError {
+		kind, ok = problemForError(cause)
+	}
+	if !ok {
+		kind, ok = problemForStatus(status)
+	}
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: requestIDFromContext(r.Context()),
+	}
+	if ok {
+		problem.Type = problemTypeBase + kind.slug
+		problem.Title = kind.title
+	}
+	var validation *domain.ValidationError
+	if kind == problemValidationFailed && errors.As(cause, &validation) {
+		for _, field := range validation.Fields {
+			problem.Errors = append(problem.Errors, ProblemFieldError{Field: field.Field, Message: field.Message})
+		}
+	}
+	return problem
+}
+
+// writeProblem responds with a problem details body. cause may be nil; when
+// set it selects a more specific problem type and any field errors.
+func (a *API) writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string, cause error) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newProblem(r, status, detail, cause)); err != nil {
+		a.Logger.ErrorContext(r.Context(), "responding to client", "err", err)
+	}
+}
+
+// conflictDetail returns the message a conflict error carries after the
+// ErrConflict prefix, or fallback when it carries none.
+func conflictDetail(err error, fallback string) string {
+	detail := strings.TrimPrefix(err.Error(), domain.ErrConflict.Error()+": ")
+	if detail == err.Error() {
+		return fallback
+	}
+	return detail
+}
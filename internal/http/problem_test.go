@@ -0,0 +1,86 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+func TestNewProblemMapsDomainErrorsToDocumentedTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		cause  error
+		want   string
+	}{
+		{name: "conflict", status: http.StatusConflict, cause: fmt.Errorf("%w: in progress", domain.ErrConflict), want: "conflict"},
+		{name: "ipv6", status: http.StatusBadRequest, cause: domain.ErrIPv6Unsupported, want: "ipv6-unsupported"},
+		{name: "discovery busy", status: http.StatusConflict, cause: domain.ErrDiscoveryBusy, want: "discovery-busy"},
+		{name: "idempotency", status: http.StatusUnprocessableEntity, cause: domain.ErrIdempotencyKeyReused, want: "idempotency-key-reused"},
+		{name: "not found", status: http.StatusNotFound, cause: domain.ErrSubnetNotFound, want: "not-found"},
+		{name: "status only", status: http.StatusTooManyRequests, want: "rate-limited"},
+		{name: "server error ignores cause", status: http.StatusInternalServerError, cause: domain.ErrConflict, want: "internal-error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subnets/1", nil)
+			problem := newProblem(req, tt.status, "detail", tt.cause)
+			if problem.Type != problemTypeBase+tt.want {
+				t.Fatalf("expected type %q, got %q", problemTypeBase+tt.want, problem.Type)
+			}
+			if problem.Status != tt.status || problem.Instance != "/api/v1/subnets/1" {
+				t.Fatalf("unexpected problem: %+v", problem)
+			}
+		})
+	}
+}
+
+func TestNewProblemFallsBackToAboutBlank(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/subnets", nil)
+	problem := newProblem(req, http.StatusRequestEntityTooLarge, "", nil)
+	if problem.Type != "about:blank" || problem.Title != http.StatusText(http.StatusRequestEntityTooLarge) {
+		t.Fatalf("unexpected fallback problem: %+v", problem)
+	}
+}
+
+func TestWriteProblemIncludesRequestIDAndFieldErrors(t *testing.T) {
+	api := newTestAPI()
+	handler := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.writeProblem(w, r, http.StatusBadRequest, "invalid site", domain.InvalidField("site_id", "site is required"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/subnets", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	problem := assertProblem(t, rec, http.StatusBadRequest, "invalid site")
+	if problem.RequestID != "req-123" || rec.Header().Get(RequestIDHeader) != "req-123" {
+		t.Fatalf("expected request id to be echoed, got body %q header %q", problem.RequestID, rec.Header().Get(RequestIDHeader))
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "site_id" {
+		t.Fatalf("unexpected field errors: %+v", problem.Errors)
+	}
+}
+
+func TestRequestIDMiddlewareReplacesMalformedIDs(t *testing.T) {
+	handler := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, incoming := range []string{"", "has space", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		req.Header.Set(RequestIDHeader, incoming)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(RequestIDHeader)
+		if got == "" || got == incoming {
+			t.Fatalf("expected generated request id for %q, got %q", incoming, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// RateLimitClass names a separately budgeted kind of request. The csv class
+// covers every bulk import under /api/v1/import/.
+type RateLimitClass string
+
+const (
+	RateLimitRead      RateLimitClass = "read"
+	RateLimitWrite     RateLimitClass = "write"
+	RateLimitCSVImport RateLimitClass = "csv"
+)
+
+// RateLimits holds the budget of each request class per role. A role or
+// class without an entry is not limited.
+type RateLimits map[apiauth.Role]map[RateLimitClass]domain.RateLimit
+
+// DefaultRateLimits is applied to every role unless overridden.
+func DefaultRateLimits() RateLimits {
+	budgets := map[RateLimitClass]domain.RateLimit{
+		RateLimitRead:      {Requests: 600, Window: time.Minute},
+		RateLimitWrite:     {Requests: 120, Window: time.Minute},
+		RateLimitCSVImport: {Requests: 10, Window: time.Minute},
+	}
+	limits := RateLimits{}
+	for _, role := range []apiauth.Role{apiauth.RoleAdmin, apiauth.RoleEditor, apiauth.RoleReadOnly} {
+		limits[role] = make(map[RateLimitClass]domain.RateLimit, len(budgets))
+		for class, limit := range budgets {
+			limits[role][class] = limit
+		}
+	}
+	return limits
+}
+
+// For returns the most generous budget among the principal's roles.
+func (l RateLimits) For(principal apiauth.Principal, class RateLimitClass) domain.RateLimit {
+	var best domain.RateLimit
+	for i, role := range principal.Roles {
+		limit, ok := l[role][class]
+		if !ok || limit.Requests <= 0 || limit.Window <= 0 {
+			return domain.RateLimit{}
+		}
+		if i == 0 || rate(limit) > rate(best) {
+			best = limit
+		}
+	}
+	return best
+}
+
+func rate(limit domain.RateLimit) float64 {
+	return float64(limit.Requests) / limit.Window.Seconds()
+}
+
+// rateLimitMiddleware runs after authentication so budgets follow the
+// principal. Without an Authenticator every caller is the default admin,
+// so requests are keyed by client address instead. Limiter failures let
+// the request through rather than taking the API down with the store.
+func (a *API) rateLimitMiddleware(next http.Handler) http.Handler {
+	if a.RateLimiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r.URL.Path) || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal, ok := apiauth.PrincipalFromContext(r.Context())
+		if !ok {
+			principal = apiauth.DefaultAdminPrincipal()
+		}
+		class := rateLimitClass(r)
+		limit := a.RateLimits.For(principal, class)
+		decision, err := a.RateLimiter.Allow(r.Context(), a.rateLimitKey(r, principal, class), limit)
+		if err != nil {
+			a.Logger.ErrorContext(r.Context(), "checking rate limit", "err", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if decision.Limit > 0 {
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(decision.Limit, 10))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(decision.Remaining, 10))
+		}
+		if !decision.Allowed {
+			seconds := int64(math.Ceil(decision.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+			a.writeProblem(w, r, http.StatusTooManyRequests, "rate limit exceeded", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitClass(r *http.Request) RateLimitClass {
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return RateLimitRead
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v1/import/"):
+		return RateLimitCSVImport
+	default:
+		return RateLimitWrite
+	}
+}
+
+func (a *API) rateLimitKey(r *http.Request, principal apiauth.Principal, class RateLimitClass) string {
+	if a.Authenticator != nil {
+		return "sub:" + principal.Subject + ":" + string(class)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host + ":" + string(class)
+}
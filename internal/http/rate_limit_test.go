@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+type stubRateLimiter struct {
+	keys   []string
+	limits []domain.RateLimit
+	allow  func(hits int) domain.RateLimitDecision
+	err    error
+}
+
+func (s *stubRateLimiter) Allow(_ context.Context, key string, limit domain.RateLimit) (domain.RateLimitDecision, error) {
+	s.keys = append(s.keys, key)
+	s.limits = append(s.limits, limit)
+	if s.err != nil {
+		return domain.RateLimitDecision{}, s.err
+	}
+	return s.allow(len(s.keys)), nil
+}
+
+func (s *stubRateLimiter) PruneExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestRateLimitMiddlewareRejectsWithRetryAfter(t *testing.T) {
+	api := newTestAPI()
+	api.Authenticator = stubAuthenticator{principal: apiauth.Principal{Subject: "ci-bot", Roles: []apiauth.Role{apiauth.RoleEditor}}}
+	api.RateLimits = DefaultRateLimits()
+	api.RateLimiter = domain.NewRateLimiter(domain.NewMemoryRateLimitCounters())
+	api.RateLimits[apiauth.RoleEditor][RateLimitWrite] = domain.RateLimit{Requests: 1, Window: time.Minute}
+	handler := api.authMiddleware(api.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/api/v1/sites"); rec.Code != http.StatusNoContent || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("expected first write to pass with no budget left, got %d %v", rec.Code, rec.Header())
+	}
+	rec := serve(http.MethodPatch, "/api/v1/sites/1")
+	assertProblem(t, rec, http.StatusTooManyRequests, "rate limit exceeded")
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header")
+	}
+	if rec := serve(http.MethodGet, "/api/v1/sites"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected reads to keep their own budget, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "/api/v1/import/csv"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected CSV imports to keep their own budget, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitMiddlewareKeysByClientAddressWithoutAuthentication(t *testing.T) {
+	limiter := &stubRateLimiter{allow: func(int) domain.RateLimitDecision { return domain.RateLimitDecision{Allowed: true} }}
+	api := &API{Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), RateLimiter: limiter, RateLimits: DefaultRateLimits()}
+	handler := api.authMiddleware(api.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	for _, path := range []string{"/api/v1/import/csv", "/healthz"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = "192.0.2.10:51234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(limiter.keys) != 1 || limiter.keys[0] != "ip:192.0.2.10:csv" {
+		t.Fatalf("expected one CSV bucket keyed by address, got %v", limiter.keys)
+	}
+	if limiter.limits[0].Requests != 10 {
+		t.Fatalf("expected default CSV budget, got %+v", limiter.limits[0])
+	}
+}
+
+func TestRateLimitMiddlewareFailsOpen(t *testing.T) {
+	api := newTestAPI()
+	api.RateLimiter = &stubRateLimiter{err: errors.New("db down")}
+	handler := api.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subnets", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected request to pass when the limiter fails, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitsPickMostGenerousRole(t *testing.T) {
+	limits := RateLimits{
+		apiauth.RoleReadOnly: {RateLimitRead: {Requests: 60, Window: time.Minute}},
+		apiauth.RoleEditor:   {RateLimitRead: {Requests: 10, Window: time.Second}},
+	}
+	principal := apiauth.Principal{Roles: []apiauth.Role{apiauth.RoleReadOnly, apiauth.RoleEditor}}
+	if got := limits.For(principal, RateLimitRead); got.Requests != 10 || got.Window != time.Second {
+		t.Fatalf("expected editor budget, got %+v", got)
+	}
+	principal.Roles = append(principal.Roles, apiauth.RoleAdmin)
+	if got := limits.For(principal, RateLimitRead); got.Requests != 0 {
+		t.Fatalf("expected unlisted admin role to be unlimited, got %+v", got)
+	}
+}
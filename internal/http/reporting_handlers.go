@@ -12,13 +12,13 @@ import (
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} ReportingSettingsResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} Problem
 // @Router /api/v1/reporting/settings [get]
 func (a *API) handleGetReportingSettings(w http.ResponseWriter, r *http.Request) {
 	settings, err := a.ReportingService.GetSettings(r.Context())
 	if err != nil {
 		a.Logger.ErrorContext(r.Context(), "reading reporting settings", "err", err)
-		_ = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		return
 	}
 	_ = encode(w, r, http.StatusOK, reportingSettingsToResponse(settings))
@@ -31,14 +31,14 @@ func (a *API) handleGetReportingSettings(w http.ResponseWriter, r *http.Request)
 // @Produce json
 // @Param settings body ReportingSettingsRequest true "Reporting settings"
 // @Success 200 {object} ReportingSettingsResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/reporting/settings [patch]
 func (a *API) handleUpdateReportingSettings(w http.ResponseWriter, r *http.Request) {
 	request, err := decode[ReportingSettingsRequest](r)
 	defer r.Body.Close()
 	if err != nil {
-		_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: "bad request"})
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
 		return
 	}
 	settings, err := a.ReportingService.UpdateSettings(r.Context(), domain.UpdateReportingSettingsInput{
@@ -46,11 +46,11 @@ func (a *API) handleUpdateReportingSettings(w http.ResponseWriter, r *http.Reque
 	})
 	if err != nil {
 		if errors.Is(err, domain.ErrInvalidInput) {
-			_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
 			return
 		}
 		a.Logger.ErrorContext(r.Context(), "updating reporting settings", "err", err)
-		_ = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+		a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		return
 	}
 	_ = encode(w, r, http.StatusOK, reportingSettingsToResponse(settings))
@@ -63,9 +63,9 @@ func (a *API) handleUpdateReportingSettings(w http.ResponseWriter, r *http.Reque
 // @Param id path int true "Subnet ID"
 // @Param range query string false "Bounded history range" default(7d) Enums(24h,7d,30d,90d,180d)
 // @Success 200 {object} SubnetUsageHistoryResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/subnets/{id}/usage-history [get]
 func (a *API) handleGetSubnetUsageHistory(w http.ResponseWriter, r *http.Request) {
 	ctx, id, _, done := parseID(w, r, a)
@@ -80,12 +80,12 @@ func (a *API) handleGetSubnetUsageHistory(w http.ResponseWriter, r *http.Request
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrIPv6Unsupported):
-			_ = encode(w, r, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
 		case errors.Is(err, domain.ErrNotFound):
-			_ = encode(w, r, http.StatusNotFound, ErrorResponse{Error: "subnet not found"})
+			a.writeProblem(w, r, http.StatusNotFound, "subnet not found", err)
 		default:
 			a.Logger.ErrorContext(ctx, "reading subnet usage history", "subnet_id", id, "err", err)
-			_ = encode(w, r, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+			a.writeProblem(w, r, http.StatusInternalServerError, "internal server error", nil)
 		}
 		return
 	}
@@ -15,7 +15,7 @@ import (
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} SiteResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} Problem
 // @Router /api/v1/sites [get]
 func (a *API) handleGetAllSites(w http.ResponseWriter, r *http.Request) {
 	statistics, err := a.SitesService.Statistics(r.Context())
@@ -32,9 +32,12 @@ func (a *API) handleGetAllSites(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param site body SiteRequest true "Site payload"
+// @Param Idempotency-Key header string false "Replays the stored response for a repeated key"
 // @Success 201 {object} SiteResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/sites [post]
 func (a *API) handleCreateSite(w http.ResponseWriter, r *http.Request) {
 	request, err := decodeSiteRequest(r)
@@ -55,7 +58,7 @@ func (a *API) handleCreateSite(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} SiteStatisticsResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} Problem
 // @Router /api/v1/sites/statistics [get]
 func (a *API) handleGetSiteStatistics(w http.ResponseWriter, r *http.Request) {
 	statistics, err := a.SitesService.Statistics(r.Context())
@@ -72,9 +75,9 @@ func (a *API) handleGetSiteStatistics(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "Site ID"
 // @Success 200 {object} SiteResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/sites/{id} [get]
 func (a *API) handleGetSiteByID(w http.ResponseWriter, r *http.Request) {
 	id, err := parseSiteID(r)
@@ -102,9 +105,9 @@ func (a *API) handleGetSiteByID(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Site ID"
 // @Param site body SiteRequest true "Site payload"
 // @Success 200 {object} SiteResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/sites/{id} [patch]
 func (a *API) handleUpdateSite(w http.ResponseWriter, r *http.Request) {
 	id, err := parseSiteID(r)
@@ -134,9 +137,9 @@ func (a *API) handleUpdateSite(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param id path string true "Site ID"
 // @Success 204
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/sites/{id} [delete]
 func (a *API) handleDeleteSiteByID(w http.ResponseWriter, r *http.Request) {
 	id, err := parseSiteID(r)
@@ -181,5 +184,5 @@ func (a *API) writeJSON(w http.ResponseWriter, r *http.Request, status int, valu
 
 func (a *API) writeSiteError(w http.ResponseWriter, r *http.Request, status int, message, operation string, cause error) {
 	a.Logger.ErrorContext(r.Context(), operation, "err", cause)
-	a.writeJSON(w, r, status, ErrorResponse{Error: message})
+	a.writeProblem(w, r, status, message, cause)
 }
@@ -149,7 +149,7 @@ func TestSiteRoutesRejectBlankNames(t *testing.T) {
 			req := httptest.NewRequest(test.method, test.path, strings.NewReader(`{"name":" \t ","description":"HQ"}`))
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
-			assertJSONError(t, rec, http.StatusBadRequest, "bad request")
+			assertProblem(t, rec, http.StatusBadRequest, "bad request")
 		})
 	}
 
@@ -185,7 +185,7 @@ func TestSiteRoutesMapServiceErrorsToAPIContract(t *testing.T) {
 			rec := httptest.NewRecorder()
 			api.Router().ServeHTTP(rec, req)
 
-			assertJSONError(t, rec, test.wantStatus, test.wantError)
+			assertProblem(t, rec, test.wantStatus, test.wantError)
 		})
 	}
 }
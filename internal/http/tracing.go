@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Flarenzy/simple-k8s-app/internal/http"
+
+// tracingMiddleware is the outermost handler: it extracts W3C trace context
+// from the request and starts the server span that CORS, auth, rate
+// limiting and the handler all run under. The span is named after the
+// method until routeSpanName learns the matched pattern.
+func (a *API) tracingMiddleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+		otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !isPublicPath(r.URL.Path)
+		}),
+	)
+}
+
+// routeSpanName renames the server span to the ServeMux pattern once the
+// mux has matched it, e.g. "GET /api/v1/subnets/{id}/ips", keeping span
+// names low-cardinality.
+func routeSpanName(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if r.Pattern == "" {
+			return
+		}
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Pattern)
+		span.SetAttributes(attribute.String("http.route", routeFromPattern(r.Pattern)))
+	})
+}
+
+// routeFromPattern drops the method from patterns like "GET /api/v1/sites".
+func routeFromPattern(pattern string) string {
+	if _, route, ok := strings.Cut(pattern, " "); ok {
+		return route
+	}
+	return pattern
+}
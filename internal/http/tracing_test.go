@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRouterTracesRequestsUnderIncomingTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var handlerSpan trace.SpanContext
+	api := newTestAPI()
+	api.NetService = domain.NewTracingNetworkService(stubService{
+		getSubnetFn: func(ctx context.Context, id int64) (domain.Subnet, error) {
+			handlerSpan = trace.SpanContextFromContext(ctx)
+			return domain.Subnet{ID: id}, nil
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/subnets/7", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+	api.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	spans := exporter.GetSpans()
+	names := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		names[span.Name] = span
+	}
+	server, ok := names["GET /api/v1/subnets/{id}"]
+	if !ok || len(spans) != 3 {
+		t.Fatalf("expected server, auth and service spans, got %v", names)
+	}
+	if server.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("expected server span to continue the incoming trace, got %s parent %s", server.SpanContext.TraceID(), server.Parent.SpanID())
+	}
+	service := names["NetworkService.GetSubnet"]
+	if service.Parent.SpanID() != server.SpanContext.SpanID() || service.SpanContext.SpanID() != handlerSpan.SpanID() {
+		t.Fatal("expected the service span to be a child of the server span")
+	}
+	if _, ok := names["auth.Authenticate"]; !ok {
+		t.Fatal("expected an authentication span")
+	}
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+const defaultDeadLetterLimit int32 = 100
+
+// @Summary List webhook subscriptions
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} WebhookSubscriptionResponse
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks [get]
+func (a *API) handleGetWebhookSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	subscriptions, err := a.WebhookService.ListSubscriptions(r.Context())
+	if err != nil {
+		a.writeWebhookError(w, r, "listing webhook subscriptions", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, webhookSubscriptionsToResponse(subscriptions))
+}
+
+// @Summary Create webhook subscription
+// @Description Event types are concrete types such as ip.created, an object wildcard such as subnet.*, or *.
+// @Description The signing secret is generated when omitted and is only returned by this endpoint.
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param subscription body WebhookSubscriptionRequest true "Webhook subscription"
+// @Success 201 {object} CreatedWebhookSubscriptionResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks [post]
+func (a *API) handleCreateWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	request, err := decode[WebhookSubscriptionRequest](r)
+	defer r.Body.Close()
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	subscription, err := a.WebhookService.CreateSubscription(r.Context(), request.toInput())
+	if err != nil {
+		a.writeWebhookError(w, r, "creating webhook subscription", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusCreated, CreatedWebhookSubscriptionResponse{
+		WebhookSubscriptionResponse: webhookSubscriptionToResponse(subscription),
+		Secret:                      subscription.Secret,
+	})
+}
+
+// @Summary Get webhook subscription
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Success 200 {object} WebhookSubscriptionResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id} [get]
+func (a *API) handleGetWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	subscription, err := a.WebhookService.GetSubscription(r.Context(), id)
+	if err != nil {
+		a.writeWebhookError(w, r, "reading webhook subscription", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, webhookSubscriptionToResponse(subscription))
+}
+
+// @Summary Update webhook subscription
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Param subscription body UpdateWebhookSubscriptionRequest true "Fields to change"
+// @Success 200 {object} WebhookSubscriptionResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id} [patch]
+func (a *API) handleUpdateWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	request, err := decode[UpdateWebhookSubscriptionRequest](r)
+	defer r.Body.Close()
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	subscription, err := a.WebhookService.UpdateSubscription(r.Context(), request.toInput(id))
+	if err != nil {
+		a.writeWebhookError(w, r, "updating webhook subscription", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, webhookSubscriptionToResponse(subscription))
+}
+
+// @Summary Delete webhook subscription
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path string true "Webhook subscription ID"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id} [delete]
+func (a *API) handleDeleteWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	deleted, err := a.WebhookService.DeleteSubscription(r.Context(), id)
+	if err != nil {
+		a.writeWebhookError(w, r, "deleting webhook subscription", err)
+		return
+	}
+	if !deleted {
+		a.writeWebhookError(w, r, "deleting webhook subscription", domain.ErrNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List dead-lettered webhook deliveries
+// @Description Deliveries that exhausted their retries, newest first.
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of deliveries" default(100) minimum(1) maximum(500)
+// @Success 200 {array} WebhookDeadLetterResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/dead-letters [get]
+func (a *API) handleGetWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	limit := defaultDeadLetterLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			a.writeProblem(w, r, http.StatusBadRequest, "limit must be an integer", domain.InvalidField("limit", "limit must be an integer"))
+			return
+		}
+		limit = int32(parsed)
+	}
+	deadLetters, err := a.WebhookService.ListDeadLetters(r.Context(), limit)
+	if err != nil {
+		a.writeWebhookError(w, r, "listing webhook dead letters", err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, webhookDeadLettersToResponse(deadLetters))
+}
+
+// @Summary Retry a dead-lettered webhook delivery
+// @Description Resets the attempt counter and queues the delivery for immediate redelivery.
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path int true "Delivery ID"
+// @Success 202
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/deliveries/{id}/retry [post]
+func (a *API) handleRetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWebhookService(w, r) {
+		return
+	}
+	id, err := parsePathInt64(r, "id")
+	if err != nil {
+		a.writeProblem(w, r, http.StatusBadRequest, "bad request", nil)
+		return
+	}
+	if err := a.WebhookService.RetryDeadLetter(r.Context(), id); err != nil {
+		a.writeWebhookError(w, r, "retrying webhook delivery", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (a *API) requireWebhookService(w http.ResponseWriter, r *http.Request) bool {
+	if a.WebhookService == nil {
+		a.writeProblem(w, r, http.StatusInternalServerError, "webhook service unavailable", nil)
+		return false
+	}
+	return true
+}
+
+func (a *API) writeWebhookError(w http.ResponseWriter, r *http.Request, operation string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		a.writeProblem(w, r, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, domain.ErrNotFound):
+		a.writeProblem(w, r, http.StatusNotFound, "webhook not found", err)
+	default:
+		a.writeSiteError(w, r, http.StatusInternalServerError, "internal server error", operation, err)
+	}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type webhookServiceStub struct {
+	domain.WebhookService
+	subscription  domain.WebhookSubscription
+	deadLetters   []domain.WebhookDeadLetter
+	createInput   domain.CreateWebhookSubscriptionInput
+	updateInput   domain.UpdateWebhookSubscriptionInput
+	deadLimit     int32
+	retriedID     int64
+	deleted       bool
+	err           error
+	retryErr      error
+	deleteCalls   int
+	listDeadCalls int
+}
+
+func (s *webhookServiceStub) ListSubscriptions(context.Context) ([]domain.WebhookSubscription, error) {
+	return []domain.WebhookSubscription{s.subscription}, s.err
+}
+
+func (s *webhookServiceStub) GetSubscription(context.Context, uuid.UUID) (domain.WebhookSubscription, error) {
+	return s.subscription, s.err
+}
+
+func (s *webhookServiceStub) CreateSubscription(_ context.Context, input domain.CreateWebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	s.createInput = input
+	return s.subscription, s.err
+}
+
+func (s *webhookServiceStub) UpdateSubscription(_ context.Context, input domain.UpdateWebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	s.updateInput = input
+	return s.subscription, s.err
+}
+
+func (s *webhookServiceStub) DeleteSubscription(context.Context, uuid.UUID) (bool, error) {
+	s.deleteCalls++
+	return s.deleted, s.err
+}
+
+func (s *webhookServiceStub) ListDeadLetters(_ context.Context, limit int32) ([]domain.WebhookDeadLetter, error) {
+	s.listDeadCalls++
+	s.deadLimit = limit
+	return s.deadLetters, s.err
+}
+
+func (s *webhookServiceStub) RetryDeadLetter(_ context.Context, id int64) error {
+	s.retriedID = id
+	return s.retryErr
+}
+
+func newWebhookTestAPI(service domain.WebhookService) *API {
+	api := NewAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), stubHealthChecker{}, nil, nil, nil)
+	api.WebhookService = service
+	return api
+}
+
+func TestWebhookRoutesSupportCRUD(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	id := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	service := &webhookServiceStub{
+		subscription: domain.WebhookSubscription{
+			ID: id, URL: "https://cmdb.example.com/hooks", EventTypes: []string{"subnet.*"}, Secret: "generated-secret-value", Active: true, CreatedAt: now, UpdatedAt: now,
+		},
+		deleted: true,
+	}
+	api := newWebhookTestAPI(service)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/v1/webhooks", status: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/api/v1/webhooks", body: `{"url":"https://cmdb.example.com/hooks","event_types":["subnet.*"]}`, status: http.StatusCreated},
+		{name: "get", method: http.MethodGet, path: "/api/v1/webhooks/" + id.String(), status: http.StatusOK},
+		{name: "update", method: http.MethodPatch, path: "/api/v1/webhooks/" + id.String(), body: `{"active":false}`, status: http.StatusOK},
+		{name: "delete", method: http.MethodDelete, path: "/api/v1/webhooks/" + id.String(), status: http.StatusNoContent},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+			if rec.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, rec.Code, rec.Body.String())
+			}
+			exposesSecret := strings.Contains(rec.Body.String(), "generated-secret-value")
+			if exposesSecret != (test.name == "create") {
+				t.Fatalf("secret exposure mismatch for %s: %s", test.name, rec.Body.String())
+			}
+		})
+	}
+
+	if service.createInput.URL != "https://cmdb.example.com/hooks" || len(service.createInput.EventTypes) != 1 || service.createInput.Active != nil {
+		t.Fatalf("unexpected create input: %+v", service.createInput)
+	}
+	if service.updateInput.ID != id || service.updateInput.Active == nil || *service.updateInput.Active || service.updateInput.URL != nil || service.updateInput.EventTypes != nil {
+		t.Fatalf("unexpected update input: %+v", service.updateInput)
+	}
+	if service.deleteCalls != 1 {
+		t.Fatalf("expected one delete, got %d", service.deleteCalls)
+	}
+}
+
+func TestWebhookRoutesMapErrors(t *testing.T) {
+	id := uuid.New().String()
+	tests := []struct {
+		name    string
+		service *webhookServiceStub
+		method  string
+		path    string
+		body    string
+		status  int
+		message string
+	}{
+		{name: "invalid id", service: &webhookServiceStub{}, method: http.MethodGet, path: "/api/v1/webhooks/nope", status: http.StatusBadRequest, message: "bad request"},
+		{name: "malformed body", service: &webhookServiceStub{}, method: http.MethodPost, path: "/api/v1/webhooks", body: `{`, status: http.StatusBadRequest, message: "bad request"},
+		{name: "validation", service: &webhookServiceStub{err: fmt.Errorf("%w: unsupported event type \"vlan.created\"", domain.ErrInvalidInput)}, method: http.MethodPost, path: "/api/v1/webhooks", body: `{"url":"https://x.test","event_types":["vlan.created"]}`, status: http.StatusBadRequest, message: "invalid input: unsupported event type \"vlan.created\""},
+		{name: "missing", service: &webhookServiceStub{err: domain.ErrNotFound}, method: http.MethodGet, path: "/api/v1/webhooks/" + id, status: http.StatusNotFound, message: "webhook not found"},
+		{name: "delete missing", service: &webhookServiceStub{}, method: http.MethodDelete, path: "/api/v1/webhooks/" + id, status: http.StatusNotFound, message: "webhook not found"},
+		{name: "unexpected", service: &webhookServiceStub{err: fmt.Errorf("db down")}, method: http.MethodGet, path: "/api/v1/webhooks", status: http.StatusInternalServerError, message: "internal server error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newWebhookTestAPI(test.service).Router().ServeHTTP(rec, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+			assertProblem(t, rec, test.status, test.message)
+		})
+	}
+}
+
+func TestWebhookDeadLetterRoutes(t *testing.T) {
+	statusCode := int32(503)
+	service := &webhookServiceStub{deadLetters: []domain.WebhookDeadLetter{{
+		ID: 12, EventID: 340, EventType: domain.EventIPCreated, ObjectType: "ip", Attempts: 10, LastStatusCode: &statusCode, LastError: "unexpected response status 503",
+	}}}
+	api := newWebhookTestAPI(service)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/dead-letters?limit=5", nil))
+	if rec.Code != http.StatusOK || service.deadLimit != 5 {
+		t.Fatalf("expected dead letters with limit 5, got %d limit=%d", rec.Code, service.deadLimit)
+	}
+	var response []WebhookDeadLetterResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response) != 1 || response[0].ID != 12 || response[0].LastStatusCode == nil || *response[0].LastStatusCode != 503 {
+		t.Fatalf("unexpected dead letters: %+v", response)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/dead-letters?limit=abc", nil))
+	assertProblem(t, rec, http.StatusBadRequest, "limit must be an integer")
+
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/deliveries/12/retry", nil))
+	if rec.Code != http.StatusAccepted || service.retriedID != 12 {
+		t.Fatalf("expected retry of delivery 12, got %d id=%d", rec.Code, service.retriedID)
+	}
+
+	service.retryErr = domain.ErrNotFound
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/deliveries/13/retry", nil))
+	assertProblem(t, rec, http.StatusNotFound, "webhook not found")
+}
+
+func TestWebhookRoutesRequireService(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newWebhookTestAPI(nil).Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil))
+	assertProblem(t, rec, http.StatusInternalServerError, "webhook service unavailable")
+}
@@ -0,0 +1,49 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+const defaultPruneInterval = time.Hour
+
+// Pruner periodically deletes expired Idempotency-Key records. Expired keys
+// are also overwritten when reused, so pruning only bounds table growth.
+type Pruner struct {
+	service  domain.IdempotencyService
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+func NewPruner(service domain.IdempotencyService, logger *slog.Logger) *Pruner {
+	return &Pruner{service: service, logger: logger, interval: defaultPruneInterval}
+}
+
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune(ctx)
+		}
+	}
+}
+
+func (p *Pruner) prune(ctx context.Context) {
+	deleted, err := p.service.PruneExpired(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.ErrorContext(ctx, "pruning idempotency keys failed", "err", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		p.logger.InfoContext(ctx, "pruned expired idempotency keys", "deleted", deleted)
+	}
+}
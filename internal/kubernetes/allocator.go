@@ -0,0 +1,251 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Allocator gives LoadBalancer Services addresses from an IPAM subnet and
+// writes them to status.loadBalancer.ingress, in place of a load-balancer
+// controller's own pools. Each address is recorded in the subnet with the
+// Service as owner and released when the Service is deleted or no longer
+// asks for the allocator.
+type Allocator struct {
+	config AllocatorConfig
+	client kubernetes.Interface
+	// watchClient serves the informers; see restConfigs.
+	watchClient kubernetes.Interface
+	service     domain.KubernetesAllocationService
+	logger      *slog.Logger
+	// pending holds the watched Services to handle, by UID; nil marks a
+	// Service to release.
+	pending *pendingQueue[string, *corev1.Service]
+}
+
+func NewAllocator(config AllocatorConfig, service domain.KubernetesAllocationService, logger *slog.Logger) (*Allocator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	watchConfig, restConfig, err := restConfigs(config.clientConfig(), "simple-k8s-app-load-balancer-allocator")
+	if err != nil {
+		return nil, err
+	}
+	watchClient, err := kubernetes.NewForConfig(watchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client: %w", err)
+	}
+	allocator := NewAllocatorWithInterface(config, client, service, logger)
+	allocator.watchClient = watchClient
+	return allocator, nil
+}
+
+func NewAllocatorWithInterface(config AllocatorConfig, client kubernetes.Interface, service domain.KubernetesAllocationService, logger *slog.Logger) *Allocator {
+	return &Allocator{
+		config: config, client: client, watchClient: client, service: service, logger: logger,
+		pending: newPendingQueue[string, *corev1.Service](),
+	}
+}
+
+// Run syncs every Service each ResyncInterval and, in between, handles
+// watched Services as they change. Without a watch it only syncs.
+func (a *Allocator) Run(ctx context.Context) {
+	controllerLoop{
+		name: "kubernetes load balancer allocator", logger: a.logger, logAttrs: []any{"controller", a.config.Key},
+		interval: a.config.ResyncInterval, changed: a.pending.changed,
+		watch: a.watch, sync: a.Sync, handlePending: a.HandlePending,
+	}.run(ctx)
+}
+
+// Sync allocates for every Service the allocator handles and releases the
+// allocations of all other Services, including ones deleted while the
+// allocator was not running. A Service whose allocation fails keeps any
+// address it already has. Every replica runs the allocator, so allocations
+// made after the listing, possibly for Services it does not include, are
+// left to the next sync.
+func (a *Allocator) Sync(ctx context.Context) error {
+	listedAt := time.Now().UTC()
+	services, err := a.listServices(ctx)
+	if err != nil {
+		return err
+	}
+	handled := make([]string, 0, len(services))
+	var errs []error
+	for i := range services {
+		service := &services[i]
+		if !a.handles(service) {
+			continue
+		}
+		handled = append(handled, string(service.UID))
+		if err := a.allocate(ctx, service); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	released, err := a.service.ReleaseExcept(ctx, a.config.Key, handled, listedAt)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("release allocations: %w", err))
+	} else if released > 0 {
+		a.logger.InfoContext(ctx, "kubernetes load balancer addresses released", "controller", a.config.Key, "released", released)
+	}
+	return errors.Join(errs...)
+}
+
+// HandlePending allocates for, or releases, the watched Services queued
+// since the last call. A Service whose update fails is left to the next
+// sync.
+func (a *Allocator) HandlePending(ctx context.Context) error {
+	pending := a.pending.take()
+	var errs []error
+	for uid, service := range pending {
+		if service != nil && a.handles(service) {
+			if err := a.allocate(ctx, service); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		released, err := a.service.Release(ctx, a.config.Key, uid)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("release allocation of service %s: %w", uid, err))
+		} else if released {
+			a.logger.InfoContext(ctx, "kubernetes load balancer address released", "controller", a.config.Key, "uid", uid)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handles reports whether the Service asks for the allocator. Services
+// being deleted are released instead.
+func (a *Allocator) handles(service *corev1.Service) bool {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer || service.DeletionTimestamp != nil {
+		return false
+	}
+	if a.config.LoadBalancerClass != "" && service.Spec.LoadBalancerClass != nil && *service.Spec.LoadBalancerClass == a.config.LoadBalancerClass {
+		return true
+	}
+	return a.config.Annotation != "" && service.Annotations[a.config.Annotation] == "true"
+}
+
+// allocate records an address for the Service and writes it to the status.
+// The address in spec.loadBalancerIP, or else the one already in the status,
+// is kept when it is free in the subnet.
+func (a *Allocator) allocate(ctx context.Context, service *corev1.Service) error {
+	var preferred netip.Addr
+	if address, err := netip.ParseAddr(service.Spec.LoadBalancerIP); err == nil {
+		preferred = address
+	} else {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if address, err := netip.ParseAddr(ingress.IP); err == nil {
+				preferred = address
+				break
+			}
+		}
+	}
+	allocation, err := a.service.Allocate(ctx, domain.AllocateLoadBalancerIPInput{
+		Controller: a.config.Key,
+		SubnetID:   a.config.SubnetID,
+		ServiceUID: string(service.UID),
+		Namespace:  service.Namespace,
+		Name:       service.Name,
+		Preferred:  preferred,
+	})
+	if err != nil {
+		return fmt.Errorf("allocate address for service %s/%s: %w", service.Namespace, service.Name, err)
+	}
+	ingress := service.Status.LoadBalancer.Ingress
+	if len(ingress) == 1 && ingress[0].IP == allocation.IP.String() && ingress[0].Hostname == "" {
+		return nil
+	}
+	updated := service.DeepCopy()
+	updated.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: allocation.IP.String()}}
+	requestCtx, cancel := context.WithTimeout(ctx, a.config.RequestTimeout)
+	defer cancel()
+	if _, err := a.client.CoreV1().Services(service.Namespace).UpdateStatus(requestCtx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update status of service %s/%s: %w", service.Namespace, service.Name, err)
+	}
+	a.logger.InfoContext(ctx, "kubernetes load balancer address assigned",
+		"controller", a.config.Key, "service", service.Namespace+"/"+service.Name, "ip", allocation.IP.String())
+	return nil
+}
+
+func (a *Allocator) listServices(ctx context.Context) ([]corev1.Service, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, a.config.RequestTimeout)
+	defer cancel()
+	var services []corev1.Service
+	for _, namespace := range watchedNamespaces(a.config.Namespaces) {
+		list, err := a.client.CoreV1().Services(namespace).List(requestCtx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list services in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		services = append(services, list.Items...)
+	}
+	return services, nil
+}
+
+// watch starts one Service informer per namespace, or one cluster-wide for
+// "*", and returns once they have synced. Services present at the start are
+// left to the first sync.
+func (a *Allocator) watch(ctx context.Context) error {
+	handler := cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj any, isInInitialList bool) {
+			if service, ok := obj.(*corev1.Service); ok && !isInInitialList && a.handles(service) {
+				a.enqueue(string(service.UID), service)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			previous, okPrevious := oldObj.(*corev1.Service)
+			current, ok := newObj.(*corev1.Service)
+			if !ok || !okPrevious || previous.ResourceVersion == current.ResourceVersion {
+				return
+			}
+			switch {
+			case a.handles(current):
+				a.enqueue(string(current.UID), current)
+			case a.handles(previous):
+				a.enqueue(string(current.UID), nil)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if service, ok := obj.(*corev1.Service); ok && service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				a.enqueue(string(service.UID), nil)
+			}
+		},
+	}
+	factories := make([]informerFactory, 0, len(a.config.Namespaces))
+	synced := make([]cache.InformerSynced, 0, len(a.config.Namespaces))
+	for _, namespace := range watchedNamespaces(a.config.Namespaces) {
+		factory := informers.NewSharedInformerFactoryWithOptions(a.watchClient, 0, informers.WithNamespace(namespace))
+		informer := factory.Core().V1().Services().Informer()
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return fmt.Errorf("watch services in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		factories = append(factories, factory)
+		synced = append(synced, informer.HasSynced)
+	}
+	return runInformers(ctx, "service", factories, synced)
+}
+
+// enqueue queues the Service for allocation, or its UID for release when
+// service is nil.
+func (a *Allocator) enqueue(uid string, service *corev1.Service) {
+	if uid == "" {
+		return
+	}
+	a.pending.add(uid, service)
+}
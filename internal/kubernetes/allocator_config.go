@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// AllocatorConfig configures the LoadBalancer allocator. It handles the
+// Services of type LoadBalancer whose spec.loadBalancerClass is
+// LoadBalancerClass, or that carry Annotation set to "true", and gives each
+// an address from SubnetID. Key identifies the cluster in the ownership
+// records, so two clusters can share a subnet.
+type AllocatorConfig struct {
+	Enabled           bool
+	Key               string
+	SubnetID          int64
+	LoadBalancerClass string
+	Annotation        string
+	Namespaces        []string
+	AuthMode          string
+	KubeconfigPath    string
+	KubeconfigContext string
+	ResyncInterval    time.Duration
+	RequestTimeout    time.Duration
+}
+
+func AllocatorConfigFromEnv(getenv func(string) string) (AllocatorConfig, error) {
+	cfg := AllocatorConfig{
+		Key:               strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_KEY")),
+		LoadBalancerClass: strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_CLASS")),
+		Annotation:        strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_ANNOTATION")),
+		Namespaces:        parseList(valueOrDefault(getenv("KUBERNETES_LB_ALLOCATOR_NAMESPACES"), "*")),
+		AuthMode:          valueOrDefault(getenv("KUBERNETES_LB_ALLOCATOR_AUTH_MODE"), AuthModeInCluster),
+		KubeconfigPath:    strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_KUBECONFIG_PATH")),
+		KubeconfigContext: strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_KUBECONFIG_CONTEXT")),
+		ResyncInterval:    domain.DefaultKubernetesReconcileInterval,
+		RequestTimeout:    domain.DefaultKubernetesRequestTimeout,
+	}
+	if raw := strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_ENABLED")); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return AllocatorConfig{}, fmt.Errorf("KUBERNETES_LB_ALLOCATOR_ENABLED: %w", err)
+		}
+		cfg.Enabled = enabled
+	}
+	if raw := strings.TrimSpace(getenv("KUBERNETES_LB_ALLOCATOR_SUBNET_ID")); raw != "" {
+		subnetID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return AllocatorConfig{}, fmt.Errorf("KUBERNETES_LB_ALLOCATOR_SUBNET_ID: %w", err)
+		}
+		cfg.SubnetID = subnetID
+	}
+	var err error
+	if cfg.ResyncInterval, err = parseDuration(getenv("KUBERNETES_LB_ALLOCATOR_INTERVAL"), cfg.ResyncInterval); err != nil {
+		return AllocatorConfig{}, fmt.Errorf("KUBERNETES_LB_ALLOCATOR_INTERVAL: %w", err)
+	}
+	if cfg.RequestTimeout, err = parseDuration(getenv("KUBERNETES_LB_ALLOCATOR_REQUEST_TIMEOUT"), cfg.RequestTimeout); err != nil {
+		return AllocatorConfig{}, fmt.Errorf("KUBERNETES_LB_ALLOCATOR_REQUEST_TIMEOUT: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return AllocatorConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c AllocatorConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Key == "" {
+		return fmt.Errorf("KUBERNETES_LB_ALLOCATOR_KEY is required when the allocator is enabled")
+	}
+	if c.SubnetID <= 0 {
+		return fmt.Errorf("KUBERNETES_LB_ALLOCATOR_SUBNET_ID is required when the allocator is enabled")
+	}
+	if c.LoadBalancerClass == "" && c.Annotation == "" {
+		return fmt.Errorf("KUBERNETES_LB_ALLOCATOR_CLASS or KUBERNETES_LB_ALLOCATOR_ANNOTATION is required when the allocator is enabled")
+	}
+	if c.LoadBalancerClass != "" {
+		if problems := utilvalidation.IsQualifiedName(c.LoadBalancerClass); len(problems) > 0 {
+			return fmt.Errorf("invalid load balancer class %q: %s", c.LoadBalancerClass, strings.Join(problems, ", "))
+		}
+	}
+	if c.Annotation != "" {
+		if problems := utilvalidation.IsQualifiedName(c.Annotation); len(problems) > 0 {
+			return fmt.Errorf("invalid allocator annotation %q: %s", c.Annotation, strings.Join(problems, ", "))
+		}
+	}
+	if len(c.Namespaces) == 0 {
+		return fmt.Errorf("KUBERNETES_LB_ALLOCATOR_NAMESPACES is required when the allocator is enabled")
+	}
+	if err := validateNamespaces(c.Namespaces, allocatorSetting); err != nil {
+		return err
+	}
+	if err := c.clientConfig().validateAuth(allocatorSetting); err != nil {
+		return err
+	}
+	if c.ResyncInterval <= 0 {
+		return fmt.Errorf("kubernetes allocator interval must be positive")
+	}
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("kubernetes allocator request timeout must be positive")
+	}
+	return nil
+}
+
+// clientConfig holds the settings the allocator's client shares with
+// discovery.
+func (c AllocatorConfig) clientConfig() Config {
+	return Config{
+		AuthMode: c.AuthMode, KubeconfigPath: c.KubeconfigPath, KubeconfigContext: c.KubeconfigContext,
+		RequestTimeout: c.RequestTimeout,
+	}
+}
+
+// allocatorSetting names a setting after its allocator environment
+// variable.
+func allocatorSetting(name string) string {
+	return "KUBERNETES_LB_ALLOCATOR_" + strings.ToUpper(name)
+}
@@ -0,0 +1,292 @@
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// stubAllocationService hands out 192.0.2.10 upwards, keeping the preferred
+// address when it is unused.
+type stubAllocationService struct {
+	mu          sync.Mutex
+	allocations map[string]netip.Addr
+	inputs      []domain.AllocateLoadBalancerIPInput
+	released    []string
+	kept        [][]string
+	listedAt    []time.Time
+}
+
+func newStubAllocationService() *stubAllocationService {
+	return &stubAllocationService{allocations: make(map[string]netip.Addr)}
+}
+
+func (s *stubAllocationService) Allocate(_ context.Context, input domain.AllocateLoadBalancerIPInput) (domain.KubernetesLoadBalancerAllocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputs = append(s.inputs, input)
+	address, ok := s.allocations[input.ServiceUID]
+	if !ok {
+		address = input.Preferred
+		if !address.IsValid() {
+			address = netip.MustParseAddr("192.0.2.10")
+			for s.used(address) {
+				address = address.Next()
+			}
+		}
+		s.allocations[input.ServiceUID] = address
+	}
+	return domain.KubernetesLoadBalancerAllocation{
+		Controller: input.Controller, ServiceUID: input.ServiceUID, Namespace: input.Namespace, Name: input.Name,
+		IP: address, SubnetID: input.SubnetID,
+	}, nil
+}
+
+func (s *stubAllocationService) used(address netip.Addr) bool {
+	for _, allocated := range s.allocations {
+		if allocated == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stubAllocationService) Release(_ context.Context, _ string, serviceUID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, serviceUID)
+	_, ok := s.allocations[serviceUID]
+	delete(s.allocations, serviceUID)
+	return ok, nil
+}
+
+func (s *stubAllocationService) ReleaseExcept(_ context.Context, _ string, serviceUIDs []string, listedAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kept = append(s.kept, slices.Clone(serviceUIDs))
+	s.listedAt = append(s.listedAt, listedAt)
+	var released int64
+	for uid := range s.allocations {
+		if !slices.Contains(serviceUIDs, uid) {
+			delete(s.allocations, uid)
+			released++
+		}
+	}
+	return released, nil
+}
+
+func (s *stubAllocationService) allocated(uid string) (netip.Addr, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address, ok := s.allocations[uid]
+	return address, ok
+}
+
+func testAllocatorConfig() AllocatorConfig {
+	return AllocatorConfig{
+		Enabled: true, Key: "prod", SubnetID: 7, LoadBalancerClass: "ipam.example.com/allocator", Annotation: "ipam.example.com/allocate",
+		Namespaces: []string{"*"}, AuthMode: AuthModeInCluster, ResyncInterval: time.Hour, RequestTimeout: time.Second,
+	}
+}
+
+func loadBalancerService(name, uid string, mutate func(*corev1.Service)) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", UID: types.UID(uid)},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	if mutate != nil {
+		mutate(service)
+	}
+	return service
+}
+
+func withClass(class string) func(*corev1.Service) {
+	return func(service *corev1.Service) { service.Spec.LoadBalancerClass = &class }
+}
+
+func serviceStatusIP(t *testing.T, clientset *fake.Clientset, name string) string {
+	t.Helper()
+	service, err := clientset.CoreV1().Services("apps").Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(service.Status.LoadBalancer.Ingress) != 1 {
+		return ""
+	}
+	return service.Status.LoadBalancer.Ingress[0].IP
+}
+
+func TestAllocatorSyncAssignsAddressesToHandledServices(t *testing.T) {
+	clientset := fake.NewClientset(
+		loadBalancerService("by-class", "uid-class", withClass("ipam.example.com/allocator")),
+		loadBalancerService("by-annotation", "uid-annotation", func(service *corev1.Service) {
+			service.Annotations = map[string]string{"ipam.example.com/allocate": "true"}
+			service.Spec.LoadBalancerIP = "192.0.2.50"
+		}),
+		loadBalancerService("metallb", "uid-other", withClass("metallb.io/metallb")),
+		loadBalancerService("cluster-ip", "uid-cluster-ip", func(service *corev1.Service) {
+			service.Spec.Type = corev1.ServiceTypeClusterIP
+			service.Annotations = map[string]string{"ipam.example.com/allocate": "true"}
+		}),
+	)
+	service := newStubAllocationService()
+	service.allocations["uid-gone"] = netip.MustParseAddr("192.0.2.99")
+	allocator := NewAllocatorWithInterface(testAllocatorConfig(), clientset, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	before := time.Now()
+	if err := allocator.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if ip := serviceStatusIP(t, clientset, "by-class"); ip != "192.0.2.10" {
+		t.Fatalf("class-selected service got %q", ip)
+	}
+	if ip := serviceStatusIP(t, clientset, "by-annotation"); ip != "192.0.2.50" {
+		t.Fatalf("annotated service should keep its requested address, got %q", ip)
+	}
+	if ip := serviceStatusIP(t, clientset, "metallb"); ip != "" {
+		t.Fatalf("service of another class was assigned %q", ip)
+	}
+	if len(service.kept) != 1 || !slices.Equal(sortedStrings(service.kept[0]), []string{"uid-annotation", "uid-class"}) {
+		t.Fatalf("expected only handled services to keep allocations, got %v", service.kept)
+	}
+	// Allocations made after the listing, possibly by another replica, are
+	// not swept.
+	if len(service.listedAt) != 1 || service.listedAt[0].Before(before) || service.listedAt[0].After(time.Now()) {
+		t.Fatalf("expected the listing time of the sync, got %v", service.listedAt)
+	}
+	if _, ok := service.allocated("uid-gone"); ok {
+		t.Fatal("allocation of a deleted service was not released")
+	}
+	if service.inputs[0].Controller != "prod" || service.inputs[0].SubnetID != 7 || service.inputs[0].Namespace != "apps" {
+		t.Fatalf("unexpected allocation input: %+v", service.inputs[0])
+	}
+
+	// A second sync finds the statuses current and writes nothing.
+	actions := len(clientset.Actions())
+	if err := allocator.Sync(context.Background()); err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	for _, action := range clientset.Actions()[actions:] {
+		if action.GetVerb() == "update" {
+			t.Fatalf("unexpected status update on an unchanged service: %v", action)
+		}
+	}
+}
+
+func TestAllocatorKeepsTheStatusAddress(t *testing.T) {
+	clientset := fake.NewClientset(loadBalancerService("orders", "uid-orders", func(service *corev1.Service) {
+		withClass("ipam.example.com/allocator")(service)
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.77"}}
+	}))
+	service := newStubAllocationService()
+	allocator := NewAllocatorWithInterface(testAllocatorConfig(), clientset, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := allocator.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if service.inputs[0].Preferred.String() != "192.0.2.77" {
+		t.Fatalf("expected the status address to be preferred, got %+v", service.inputs[0])
+	}
+}
+
+func TestAllocatorRunFollowsWatchedServices(t *testing.T) {
+	clientset := fake.NewClientset()
+	service := newStubAllocationService()
+	allocator := NewAllocatorWithInterface(testAllocatorConfig(), clientset, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go allocator.Run(ctx)
+
+	services := clientset.CoreV1().Services("apps")
+	created := loadBalancerService("orders", "uid-orders", withClass("ipam.example.com/allocator"))
+	// The watch may start after the Service exists; the first sync covers it.
+	waitFor(t, func() bool {
+		if _, err := services.Get(ctx, "orders", metav1.GetOptions{}); err != nil {
+			_, _ = services.Create(ctx, created, metav1.CreateOptions{})
+		}
+		return serviceStatusIP(t, clientset, "orders") == "192.0.2.10"
+	})
+
+	if err := services.Delete(ctx, "orders", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, ok := service.allocated("uid-orders")
+		return !ok
+	})
+}
+
+func waitFor(t *testing.T, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the allocator")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func sortedStrings(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return values
+}
+
+func TestAllocatorConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"KUBERNETES_LB_ALLOCATOR_ENABLED":   "true",
+		"KUBERNETES_LB_ALLOCATOR_KEY":       "prod",
+		"KUBERNETES_LB_ALLOCATOR_SUBNET_ID": "7",
+		"KUBERNETES_LB_ALLOCATOR_CLASS":     "ipam.example.com/allocator",
+	}
+	cfg, err := AllocatorConfigFromEnv(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("AllocatorConfigFromEnv: %v", err)
+	}
+	if cfg.SubnetID != 7 || !slices.Equal(cfg.Namespaces, []string{"*"}) || cfg.AuthMode != AuthModeInCluster || cfg.ResyncInterval != domain.DefaultKubernetesReconcileInterval {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "missing subnet", env: map[string]string{"KUBERNETES_LB_ALLOCATOR_SUBNET_ID": ""}, want: "SUBNET_ID is required"},
+		{name: "no selection", env: map[string]string{"KUBERNETES_LB_ALLOCATOR_CLASS": ""}, want: "CLASS or KUBERNETES_LB_ALLOCATOR_ANNOTATION"},
+		{name: "bad annotation", env: map[string]string{"KUBERNETES_LB_ALLOCATOR_ANNOTATION": "not an annotation"}, want: "invalid allocator annotation"},
+		{name: "kubeconfig without path", env: map[string]string{"KUBERNETES_LB_ALLOCATOR_AUTH_MODE": "kubeconfig"}, want: "KUBERNETES_LB_ALLOCATOR_KUBECONFIG_PATH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := make(map[string]string, len(env))
+			for key, value := range env {
+				merged[key] = value
+			}
+			for key, value := range tt.env {
+				merged[key] = value
+			}
+			_, err := AllocatorConfigFromEnv(func(key string) string { return merged[key] })
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	disabled, err := AllocatorConfigFromEnv(func(string) string { return "" })
+	if err != nil || disabled.Enabled {
+		t.Fatalf("expected the allocator to be off by default, got %+v, %v", disabled, err)
+	}
+}
@@ -3,15 +3,20 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/netip"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -22,28 +27,69 @@ type ServiceLister interface {
 
 type Client struct {
 	client kubernetes.Interface
-	config Config
+	// dynamic reads the Gateway API resources, whose types client-go does
+	// not include. It is nil for clients built around a test clientset.
+	dynamic dynamic.Interface
+	// watchClient serves the informers; see restConfigs.
+	watchClient kubernetes.Interface
+	config      Config
+
+	mu       sync.Mutex
+	watching bool
+	listers  []corelisters.ServiceLister
 }
 
 func NewClient(config Config) (*Client, error) {
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
-	restConfig, err := buildRESTConfig(config)
+	watchConfig, restConfig, err := restConfigs(config, "simple-k8s-app-service-discovery")
 	if err != nil {
 		return nil, err
 	}
-	restConfig.Timeout = config.RequestTimeout
-	restConfig.UserAgent = "simple-k8s-app-service-discovery"
+	watchClient, err := kubernetes.NewForConfig(watchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client: %w", err)
+	}
 	client, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("create kubernetes client: %w", err)
 	}
-	return &Client{client: client, config: config}, nil
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes dynamic client: %w", err)
+	}
+	return &Client{client: client, dynamic: dynamicClient, watchClient: watchClient, config: config}, nil
 }
 
 func NewClientWithInterface(config Config, client kubernetes.Interface) *Client {
-	return &Client{client: client, config: config}
+	return &Client{client: client, watchClient: client, config: config}
+}
+
+// NewClientWithInterfaces also takes the dynamic client Gateway and
+// HTTPRoute discovery reads through.
+func NewClientWithInterfaces(config Config, client kubernetes.Interface, dynamicClient dynamic.Interface) *Client {
+	return &Client{client: client, dynamic: dynamicClient, watchClient: client, config: config}
+}
+
+// restConfigs returns two traced REST configurations for the cluster, both
+// sending userAgent: one for informers, without an overall request timeout,
+// which would cut every watch stream short, and one for other requests,
+// limited to config.RequestTimeout.
+func restConfigs(config Config, userAgent string) (watch, request *rest.Config, err error) {
+	watch, err = buildRESTConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	watch.UserAgent = userAgent
+	watch.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(rt, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "kubernetes " + r.Method
+		}))
+	})
+	request = rest.CopyConfig(watch)
+	request.Timeout = config.RequestTimeout
+	return watch, request, nil
 }
 
 func buildRESTConfig(config Config) (*rest.Config, error) {
@@ -55,6 +101,9 @@ func buildRESTConfig(config Config) (*rest.Config, error) {
 		}
 		return result, nil
 	case AuthModeKubeconfig:
+		if config.Kubeconfig != nil {
+			return inlineKubeconfigRESTConfig(config.Kubeconfig, config.KubeconfigContext)
+		}
 		rules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: config.KubeconfigPath}
 		overrides := &clientcmd.ConfigOverrides{CurrentContext: config.KubeconfigContext}
 		result, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
@@ -67,26 +116,26 @@ func buildRESTConfig(config Config) (*rest.Config, error) {
 	}
 }
 
+// ListServices returns every Service in the watched namespaces. Once
+// WatchServices has synced it reads the watch caches; until then it lists
+// from the API server.
 func (c *Client) ListServices(ctx context.Context) ([]domain.KubernetesServiceSnapshot, error) {
-	requestCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
-	defer cancel()
-
-	snapshots := make([]domain.KubernetesServiceSnapshot, 0)
-	for _, namespace := range c.config.Source.Namespaces {
-		if namespace == "*" {
-			namespace = metav1.NamespaceAll
+	services, cached, err := c.cachedServices()
+	if err != nil {
+		return nil, err
+	}
+	if !cached {
+		if services, err = c.listServices(ctx); err != nil {
+			return nil, err
 		}
-		list, err := c.client.CoreV1().Services(namespace).List(requestCtx, metav1.ListOptions{})
+	}
+	snapshots := make([]domain.KubernetesServiceSnapshot, 0, len(services))
+	for _, service := range services {
+		snapshot, err := serviceToSnapshot(service, c.config.Source.ClusterDomain)
 		if err != nil {
-			return nil, fmt.Errorf("list services in namespace %q: %w", displayNamespace(namespace), err)
-		}
-		for i := range list.Items {
-			snapshot, err := serviceToSnapshot(&list.Items[i], c.config.Source.ClusterDomain)
-			if err != nil {
-				return nil, err
-			}
-			snapshots = append(snapshots, snapshot)
+			return nil, err
 		}
+		snapshots = append(snapshots, snapshot)
 	}
 	sort.Slice(snapshots, func(i, j int) bool {
 		left, right := snapshots[i], snapshots[j]
@@ -101,6 +150,23 @@ func (c *Client) ListServices(ctx context.Context) ([]domain.KubernetesServiceSn
 	return snapshots, nil
 }
 
+func (c *Client) listServices(ctx context.Context) ([]*corev1.Service, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	defer cancel()
+
+	var services []*corev1.Service
+	for _, namespace := range watchedNamespaces(c.config.Source.Namespaces) {
+		list, err := c.client.CoreV1().Services(namespace).List(requestCtx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list services in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		for i := range list.Items {
+			services = append(services, &list.Items[i])
+		}
+	}
+	return services, nil
+}
+
 func serviceToSnapshot(service *corev1.Service, clusterDomain string) (domain.KubernetesServiceSnapshot, error) {
 	if service.UID == "" {
 		return domain.KubernetesServiceSnapshot{}, fmt.Errorf("service %s/%s has no UID", service.Namespace, service.Name)
@@ -2,12 +2,14 @@ package kubernetes
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
 	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/labels"
 	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
@@ -17,10 +19,13 @@ const (
 )
 
 type Config struct {
-	Enabled           bool
-	Source            domain.KubernetesSourceConfig
-	AuthMode          string
-	KubeconfigPath    string
+	Enabled        bool
+	Source         domain.KubernetesSourceConfig
+	AuthMode       string
+	KubeconfigPath string
+	// Kubeconfig holds the contents of a kubeconfig stored through the API,
+	// used instead of KubeconfigPath.
+	Kubeconfig        []byte
 	KubeconfigContext string
 	ReconcileInterval time.Duration
 	RequestTimeout    time.Duration
@@ -31,14 +36,17 @@ func ConfigFromEnv(getenv func(string) string) (Config, error) {
 		AuthMode:          valueOrDefault(getenv("KUBERNETES_DISCOVERY_AUTH_MODE"), AuthModeInCluster),
 		KubeconfigPath:    strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_KUBECONFIG_PATH")),
 		KubeconfigContext: strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_KUBECONFIG_CONTEXT")),
-		ReconcileInterval: 5 * time.Minute,
-		RequestTimeout:    15 * time.Second,
+		ReconcileInterval: domain.DefaultKubernetesReconcileInterval,
+		RequestTimeout:    domain.DefaultKubernetesRequestTimeout,
 		Source: domain.KubernetesSourceConfig{
 			Key:            strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_SOURCE_KEY")),
 			Name:           strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_SOURCE_NAME")),
-			ClusterDomain:  valueOrDefault(getenv("KUBERNETES_DISCOVERY_CLUSTER_DOMAIN"), "cluster.local"),
+			ClusterDomain:  valueOrDefault(getenv("KUBERNETES_DISCOVERY_CLUSTER_DOMAIN"), domain.DefaultKubernetesClusterDomain),
 			Namespaces:     parseList(getenv("KUBERNETES_DISCOVERY_NAMESPACES")),
-			StaleRetention: 7 * 24 * time.Hour,
+			StaleRetention: domain.DefaultKubernetesStaleRetention,
+			ObjectKinds:    parseList(getenv("KUBERNETES_DISCOVERY_OBJECT_KINDS")),
+			PodSelector:    strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_POD_SELECTOR")),
+			AutoRegister:   valueOrDefault(getenv("KUBERNETES_DISCOVERY_AUTO_REGISTER"), domain.KubernetesAutoRegisterOff),
 		},
 	}
 
@@ -79,27 +87,69 @@ func (c Config) Validate() error {
 	if !c.Enabled {
 		return nil
 	}
+	return c.validate(envSetting)
+}
+
+// validate checks one source. setting turns a setting name such as site_id
+// into the name the operator wrote it under.
+func (c Config) validate(setting func(string) string) error {
 	if c.Source.Key == "" {
-		return fmt.Errorf("KUBERNETES_DISCOVERY_SOURCE_KEY is required when discovery is enabled")
+		return fmt.Errorf("%s is required when discovery is enabled", setting("source_key"))
 	}
 	if c.Source.SiteID == uuid.Nil {
-		return fmt.Errorf("KUBERNETES_DISCOVERY_SITE_ID is required when discovery is enabled")
+		return fmt.Errorf("%s is required when discovery is enabled", setting("site_id"))
 	}
 	clusterDomain := strings.Trim(strings.TrimSpace(c.Source.ClusterDomain), ".")
 	if problems := utilvalidation.IsDNS1123Subdomain(clusterDomain); len(problems) > 0 {
 		return fmt.Errorf("invalid kubernetes cluster domain %q: %s", c.Source.ClusterDomain, strings.Join(problems, ", "))
 	}
 	if len(c.Source.Namespaces) == 0 {
-		return fmt.Errorf("KUBERNETES_DISCOVERY_NAMESPACES is required when discovery is enabled")
+		return fmt.Errorf("%s is required when discovery is enabled", setting("namespaces"))
+	}
+	if err := validateNamespaces(c.Source.Namespaces, setting); err != nil {
+		return err
+	}
+	for _, kind := range c.Source.ObjectKinds {
+		if !slices.Contains(domain.KubernetesObjectKinds, kind) {
+			return fmt.Errorf("%s: unknown object kind %q; use %s", setting("object_kinds"), kind, strings.Join(domain.KubernetesObjectKinds, ", "))
+		}
+	}
+	if c.Source.PodSelector != "" {
+		if !slices.Contains(c.Source.ObjectKinds, domain.KubernetesObjectPod) {
+			return fmt.Errorf("%s needs pod in %s", setting("pod_selector"), setting("object_kinds"))
+		}
+		if _, err := labels.Parse(c.Source.PodSelector); err != nil {
+			return fmt.Errorf("%s: %w", setting("pod_selector"), err)
+		}
+	}
+	if !slices.Contains(domain.KubernetesAutoRegisterPolicies, c.Source.AutoRegister) {
+		return fmt.Errorf("%s: unknown policy %q; use %s", setting("auto_register"), c.Source.AutoRegister, strings.Join(domain.KubernetesAutoRegisterPolicies, ", "))
+	}
+	if err := c.validateAuth(setting); err != nil {
+		return err
+	}
+	if c.ReconcileInterval <= 0 {
+		return fmt.Errorf("kubernetes discovery interval must be positive")
+	}
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("kubernetes discovery request timeout must be positive")
+	}
+	if c.Source.StaleRetention <= 0 {
+		return fmt.Errorf("kubernetes discovery stale retention must be positive")
 	}
-	if len(c.Source.Namespaces) > 1 {
-		for _, namespace := range c.Source.Namespaces {
+	return nil
+}
+
+// validateNamespaces checks namespace names, and that * stands alone.
+func validateNamespaces(namespaces []string, setting func(string) string) error {
+	if len(namespaces) > 1 {
+		for _, namespace := range namespaces {
 			if namespace == "*" {
-				return fmt.Errorf("KUBERNETES_DISCOVERY_NAMESPACES cannot mix * with named namespaces")
+				return fmt.Errorf("%s cannot mix * with named namespaces", setting("namespaces"))
 			}
 		}
 	}
-	for _, namespace := range c.Source.Namespaces {
+	for _, namespace := range namespaces {
 		if namespace == "*" {
 			continue
 		}
@@ -107,30 +157,30 @@ func (c Config) Validate() error {
 			return fmt.Errorf("invalid kubernetes namespace %q: %s", namespace, strings.Join(problems, ", "))
 		}
 	}
+	return nil
+}
+
+func (c Config) validateAuth(setting func(string) string) error {
 	switch c.AuthMode {
 	case AuthModeInCluster:
-		if c.KubeconfigPath != "" || c.KubeconfigContext != "" {
+		if c.KubeconfigPath != "" || c.Kubeconfig != nil || c.KubeconfigContext != "" {
 			return fmt.Errorf("kubeconfig path/context cannot be set with in_cluster auth")
 		}
 	case AuthModeKubeconfig:
-		if c.KubeconfigPath == "" {
-			return fmt.Errorf("KUBERNETES_DISCOVERY_KUBECONFIG_PATH is required with kubeconfig auth")
+		if (c.KubeconfigPath == "") == (c.Kubeconfig == nil) {
+			return fmt.Errorf("%s is required with kubeconfig auth", setting("kubeconfig_path"))
 		}
 	default:
-		return fmt.Errorf("unsupported kubernetes discovery auth mode %q", c.AuthMode)
-	}
-	if c.ReconcileInterval <= 0 {
-		return fmt.Errorf("kubernetes discovery interval must be positive")
-	}
-	if c.RequestTimeout <= 0 {
-		return fmt.Errorf("kubernetes discovery request timeout must be positive")
-	}
-	if c.Source.StaleRetention <= 0 {
-		return fmt.Errorf("kubernetes discovery stale retention must be positive")
+		return fmt.Errorf("unsupported kubernetes auth mode %q", c.AuthMode)
 	}
 	return nil
 }
 
+// envSetting names a setting after its environment variable.
+func envSetting(name string) string {
+	return "KUBERNETES_DISCOVERY_" + strings.ToUpper(name)
+}
+
 func parseList(value string) []string {
 	seen := make(map[string]struct{})
 	items := make([]string, 0)
@@ -11,7 +11,7 @@ func TestConfigFromEnvDefaultsToDisabledInCluster(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ConfigFromEnv: %v", err)
 	}
-	if cfg.Enabled || cfg.AuthMode != AuthModeInCluster || cfg.Source.ClusterDomain != "cluster.local" {
+	if cfg.Enabled || cfg.AuthMode != AuthModeInCluster || cfg.Source.ClusterDomain != "cluster.local" || cfg.Source.AutoRegister != "off" {
 		t.Fatalf("unexpected defaults: %+v", cfg)
 	}
 	if cfg.ReconcileInterval != 5*time.Minute || cfg.RequestTimeout != 15*time.Second || cfg.Source.StaleRetention != 7*24*time.Hour {
@@ -32,12 +32,13 @@ func TestConfigFromEnvLoadsExplicitKubeconfig(t *testing.T) {
 		"KUBERNETES_DISCOVERY_CLUSTER_DOMAIN":     "corp.local",
 		"KUBERNETES_DISCOVERY_INTERVAL":           "2m",
 		"KUBERNETES_DISCOVERY_REQUEST_TIMEOUT":    "7s",
+		"KUBERNETES_DISCOVERY_AUTO_REGISTER":      "register_lb_only",
 	}
 	cfg, err := ConfigFromEnv(func(key string) string { return env[key] })
 	if err != nil {
 		t.Fatalf("ConfigFromEnv: %v", err)
 	}
-	if !cfg.Enabled || cfg.KubeconfigContext != "kiac" || len(cfg.Source.Namespaces) != 2 {
+	if !cfg.Enabled || cfg.KubeconfigContext != "kiac" || len(cfg.Source.Namespaces) != 2 || cfg.Source.AutoRegister != "register_lb_only" {
 		t.Fatalf("unexpected config: %+v", cfg)
 	}
 }
@@ -51,6 +52,10 @@ func TestConfigRejectsPartialOrContradictoryScope(t *testing.T) {
 		{name: "missing site", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_NAMESPACES": "default"}, want: "SITE_ID"},
 		{name: "mixed all namespaces", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_SITE_ID": "550e8400-e29b-41d4-a716-446655440000", "KUBERNETES_DISCOVERY_NAMESPACES": "*,default"}, want: "cannot mix"},
 		{name: "implicit kubeconfig", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_SITE_ID": "550e8400-e29b-41d4-a716-446655440000", "KUBERNETES_DISCOVERY_NAMESPACES": "default", "KUBERNETES_DISCOVERY_AUTH_MODE": "kubeconfig"}, want: "KUBECONFIG_PATH"},
+		{name: "unknown object kind", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_SITE_ID": "550e8400-e29b-41d4-a716-446655440000", "KUBERNETES_DISCOVERY_NAMESPACES": "default", "KUBERNETES_DISCOVERY_OBJECT_KINDS": "node,deployment"}, want: "unknown object kind"},
+		{name: "selector without pods", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_SITE_ID": "550e8400-e29b-41d4-a716-446655440000", "KUBERNETES_DISCOVERY_NAMESPACES": "default", "KUBERNETES_DISCOVERY_POD_SELECTOR": "app=edge"}, want: "needs pod"},
+		{name: "bad selector", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_SITE_ID": "550e8400-e29b-41d4-a716-446655440000", "KUBERNETES_DISCOVERY_NAMESPACES": "default", "KUBERNETES_DISCOVERY_OBJECT_KINDS": "pod", "KUBERNETES_DISCOVERY_POD_SELECTOR": "app in edge"}, want: "POD_SELECTOR"},
+		{name: "unknown auto-register policy", env: map[string]string{"KUBERNETES_DISCOVERY_ENABLED": "true", "KUBERNETES_DISCOVERY_SOURCE_KEY": "x", "KUBERNETES_DISCOVERY_SITE_ID": "550e8400-e29b-41d4-a716-446655440000", "KUBERNETES_DISCOVERY_NAMESPACES": "default", "KUBERNETES_DISCOVERY_AUTO_REGISTER": "always"}, want: "AUTO_REGISTER"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
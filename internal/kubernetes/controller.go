@@ -0,0 +1,116 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// controllerLoop is the Run loop the allocator and the IPAM provider share:
+// it syncs every object each interval and, in between, handles the watched
+// objects its queue reports. Without a watch it only syncs.
+type controllerLoop struct {
+	// name starts the log messages, such as "kubernetes ipam provider".
+	name          string
+	logger        *slog.Logger
+	logAttrs      []any
+	interval      time.Duration
+	changed       <-chan struct{}
+	watch         func(context.Context) error
+	sync          func(context.Context) error
+	handlePending func(context.Context) error
+}
+
+func (l controllerLoop) run(ctx context.Context) {
+	if err := l.watch(ctx); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		l.warn(ctx, "watch unavailable; using periodic syncs", err)
+	}
+	for {
+		if err := l.sync(ctx); err != nil && ctx.Err() == nil {
+			l.warn(ctx, "sync failed", err)
+		}
+		timer := time.NewTimer(l.interval)
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				break wait
+			case <-l.changed:
+				if err := l.handlePending(ctx); err != nil && ctx.Err() == nil {
+					l.warn(ctx, "sync failed", err)
+				}
+			}
+		}
+	}
+}
+
+func (l controllerLoop) warn(ctx context.Context, message string, err error) {
+	l.logger.WarnContext(ctx, l.name+" "+message, append(slices.Clip(l.logAttrs), "err", err)...)
+}
+
+// pendingQueue collects watched objects by key until the controller takes
+// them; a later event for the same key replaces the earlier one.
+type pendingQueue[K comparable, V any] struct {
+	mu      sync.Mutex
+	pending map[K]V
+	changed chan struct{}
+}
+
+func newPendingQueue[K comparable, V any]() *pendingQueue[K, V] {
+	return &pendingQueue[K, V]{pending: make(map[K]V), changed: make(chan struct{}, 1)}
+}
+
+func (q *pendingQueue[K, V]) add(key K, value V) {
+	q.mu.Lock()
+	q.pending[key] = value
+	q.mu.Unlock()
+	select {
+	case q.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (q *pendingQueue[K, V]) take() map[K]V {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := q.pending
+	q.pending = make(map[K]V)
+	return pending
+}
+
+// informerFactory is what runInformers needs of the typed and the dynamic
+// shared informer factories.
+type informerFactory interface {
+	Start(stopCh <-chan struct{})
+	Shutdown()
+}
+
+// runInformers starts the factories, shuts them down once ctx ends and
+// returns when every informer has synced. resource names what is watched in
+// the error.
+func runInformers(ctx context.Context, resource string, factories []informerFactory, synced []cache.InformerSynced) error {
+	for _, factory := range factories {
+		factory.Start(ctx.Done())
+	}
+	go func() {
+		<-ctx.Done()
+		for _, factory := range factories {
+			factory.Shutdown()
+		}
+	}()
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return fmt.Errorf("kubernetes %s cache did not sync: %w", resource, context.Cause(ctx))
+	}
+	return nil
+}
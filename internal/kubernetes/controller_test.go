@@ -0,0 +1,37 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRESTConfigsLimitOnlyRequests(t *testing.T) {
+	watch, request, err := restConfigs(Config{AuthMode: AuthModeKubeconfig, Kubeconfig: []byte(inlineKubeconfig), RequestTimeout: 5 * time.Second}, "test-agent")
+	if err != nil {
+		t.Fatalf("restConfigs: %v", err)
+	}
+	if watch.Timeout != 0 || request.Timeout != 5*time.Second {
+		t.Fatalf("expected only requests to time out, got watch %s and request %s", watch.Timeout, request.Timeout)
+	}
+	if watch.UserAgent != "test-agent" || request.UserAgent != "test-agent" || request.WrapTransport == nil {
+		t.Fatalf("expected both configurations to be named and traced: %+v", request)
+	}
+}
+
+func TestPendingQueueKeepsLatestPerKey(t *testing.T) {
+	queue := newPendingQueue[string, int]()
+	queue.add("a", 1)
+	queue.add("a", 2)
+	queue.add("b", 3)
+	select {
+	case <-queue.changed:
+	default:
+		t.Fatal("expected a change notification")
+	}
+	if pending := queue.take(); len(pending) != 2 || pending["a"] != 2 || pending["b"] != 3 {
+		t.Fatalf("unexpected pending objects: %v", pending)
+	}
+	if pending := queue.take(); len(pending) != 0 {
+		t.Fatalf("expected take to empty the queue, got %v", pending)
+	}
+}
@@ -0,0 +1,177 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+var (
+	gatewayResource   = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "gateways"}
+	httpRouteResource = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "httproutes"}
+)
+
+// gatewayObject holds the Gateway fields discovery reads.
+type gatewayObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Listeners []struct {
+			Hostname string `json:"hostname,omitempty"`
+		} `json:"listeners"`
+	} `json:"spec"`
+	Status struct {
+		Addresses []struct {
+			Type  string `json:"type,omitempty"`
+			Value string `json:"value"`
+		} `json:"addresses"`
+	} `json:"status"`
+}
+
+// httpRouteObject holds the HTTPRoute fields discovery reads.
+type httpRouteObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Hostnames  []string `json:"hostnames"`
+		ParentRefs []struct {
+			Group     *string `json:"group,omitempty"`
+			Kind      *string `json:"kind,omitempty"`
+			Namespace *string `json:"namespace,omitempty"`
+			Name      string  `json:"name"`
+		} `json:"parentRefs"`
+	} `json:"spec"`
+}
+
+// listGatewayObjects lists the Gateways and HTTPRoutes in the watched
+// namespaces. HTTPRoutes take the addresses of their parent Gateways, so
+// Gateways are listed for them even when only http_route is discovered; a
+// parent outside the watched namespaces adds no addresses. A cluster
+// without the Gateway API resources has no Gateways or HTTPRoutes.
+func (c *Client) listGatewayObjects(ctx context.Context, kinds []string, skipped *[]error) ([]domain.KubernetesObjectSnapshot, error) {
+	withGateways := slices.Contains(kinds, domain.KubernetesObjectGateway)
+	withRoutes := slices.Contains(kinds, domain.KubernetesObjectHTTPRoute)
+	if !withGateways && !withRoutes {
+		return nil, nil
+	}
+	if c.dynamic == nil {
+		return nil, errors.New("gateway and http_route discovery needs a dynamic client")
+	}
+
+	namespaces := watchedNamespaces(c.config.Source.Namespaces)
+	objects := make([]domain.KubernetesObjectSnapshot, 0)
+	gateways := make(map[types.NamespacedName]domain.KubernetesObjectSnapshot)
+	for _, namespace := range namespaces {
+		list, err := c.dynamic.Resource(gatewayResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list gateways in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		for i := range list.Items {
+			snapshot, err := gatewayToSnapshot(&list.Items[i])
+			if err != nil {
+				skipObject(skipped, domain.KubernetesObjectGateway, list.Items[i].GetNamespace(), list.Items[i].GetName(), err)
+				continue
+			}
+			gateways[types.NamespacedName{Namespace: snapshot.Namespace, Name: snapshot.Name}] = snapshot
+			if withGateways {
+				objects = append(objects, snapshot)
+			}
+		}
+	}
+	if !withRoutes {
+		return objects, nil
+	}
+	for _, namespace := range namespaces {
+		list, err := c.dynamic.Resource(httpRouteResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list httproutes in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		for i := range list.Items {
+			snapshot, err := httpRouteToSnapshot(&list.Items[i], gateways)
+			if err != nil {
+				skipObject(skipped, domain.KubernetesObjectHTTPRoute, list.Items[i].GetNamespace(), list.Items[i].GetName(), err)
+				continue
+			}
+			objects = append(objects, snapshot)
+		}
+	}
+	return objects, nil
+}
+
+// gatewayToSnapshot records the listener hostnames and the status
+// addresses: IPAddress ones as load_balancer addresses and Hostname ones as
+// load_balancer hostnames.
+func gatewayToSnapshot(object *unstructured.Unstructured) (domain.KubernetesObjectSnapshot, error) {
+	var gateway gatewayObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &gateway); err != nil {
+		return domain.KubernetesObjectSnapshot{}, fmt.Errorf("gateway %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+	}
+	snapshot := objectSnapshot(domain.KubernetesObjectGateway, gateway.ObjectMeta)
+	if snapshot.UID == "" {
+		return snapshot, fmt.Errorf("gateway %s/%s has no UID", gateway.Namespace, gateway.Name)
+	}
+	for _, listener := range gateway.Spec.Listeners {
+		appendObjectHostname(&snapshot, "host", listener.Hostname)
+	}
+	for _, address := range gateway.Status.Addresses {
+		switch address.Type {
+		case "", "IPAddress":
+			if err := appendObjectAddress(&snapshot, "load_balancer", address.Value, ""); err != nil {
+				return snapshot, fmt.Errorf("gateway %s/%s has invalid address %q: %w", gateway.Namespace, gateway.Name, address.Value, err)
+			}
+		case "Hostname":
+			appendObjectHostname(&snapshot, "load_balancer", address.Value)
+		}
+	}
+	return snapshot, nil
+}
+
+// httpRouteToSnapshot records the route's hostnames and, as gateway
+// addresses, the load-balancer addresses of the parent Gateways it found.
+func httpRouteToSnapshot(object *unstructured.Unstructured, gateways map[types.NamespacedName]domain.KubernetesObjectSnapshot) (domain.KubernetesObjectSnapshot, error) {
+	var route httpRouteObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &route); err != nil {
+		return domain.KubernetesObjectSnapshot{}, fmt.Errorf("httproute %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+	}
+	snapshot := objectSnapshot(domain.KubernetesObjectHTTPRoute, route.ObjectMeta)
+	if snapshot.UID == "" {
+		return snapshot, fmt.Errorf("httproute %s/%s has no UID", route.Namespace, route.Name)
+	}
+	for _, hostname := range route.Spec.Hostnames {
+		appendObjectHostname(&snapshot, "host", hostname)
+	}
+	for _, parent := range route.Spec.ParentRefs {
+		if parent.Group != nil && *parent.Group != gatewayAPIGroup || parent.Kind != nil && *parent.Kind != "Gateway" {
+			continue
+		}
+		namespace := route.Namespace
+		if parent.Namespace != nil {
+			namespace = *parent.Namespace
+		}
+		gateway, ok := gateways[types.NamespacedName{Namespace: namespace, Name: parent.Name}]
+		if !ok {
+			continue
+		}
+		for _, address := range gateway.Addresses {
+			if err := appendObjectAddress(&snapshot, "gateway", address.Address.String(), ""); err != nil {
+				return snapshot, err
+			}
+		}
+	}
+	return snapshot, nil
+}
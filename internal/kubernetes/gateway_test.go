@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func gatewayAPIObject(kind, namespace, name, uid string, spec, status map[string]any) *unstructured.Unstructured {
+	object := map[string]any{
+		"apiVersion": gatewayAPIGroup + "/v1",
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name, "namespace": namespace, "uid": uid},
+		"spec":       spec,
+	}
+	if status != nil {
+		object["status"] = status
+	}
+	return &unstructured.Unstructured{Object: object}
+}
+
+// newGatewayTestClient adds the objects through the tracker by resource,
+// since the fake would guess "gatewaies" from the Gateway kind.
+func newGatewayTestClient(t *testing.T, kinds []string, objects ...*unstructured.Unstructured) (*Client, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gatewayResource:   "GatewayList",
+		httpRouteResource: "HTTPRouteList",
+	})
+	for _, object := range objects {
+		resource := httpRouteResource
+		if object.GetKind() == "Gateway" {
+			resource = gatewayResource
+		}
+		if err := dynamicClient.Tracker().Create(resource, object, object.GetNamespace()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewClientWithInterfaces(objectTestConfig("", kinds...), fake.NewClientset(), dynamicClient), dynamicClient
+}
+
+func TestClientListsGatewaysAndHTTPRoutes(t *testing.T) {
+	client, _ := newGatewayTestClient(t, []string{domain.KubernetesObjectGateway, domain.KubernetesObjectHTTPRoute},
+		gatewayAPIObject("Gateway", "apps", "public", "uid-gateway",
+			map[string]any{"listeners": []any{map[string]any{"name": "https", "hostname": "*.example.test"}, map[string]any{"name": "http"}}},
+			map[string]any{"addresses": []any{map[string]any{"type": "IPAddress", "value": "192.0.2.20"}, map[string]any{"type": "Hostname", "value": "gw.example.test"}, map[string]any{"value": "2001:db8::20"}}},
+		),
+		gatewayAPIObject("HTTPRoute", "apps", "ipam", "uid-route", map[string]any{
+			"hostnames":  []any{"ipam.example.test"},
+			"parentRefs": []any{map[string]any{"name": "public"}, map[string]any{"name": "missing"}, map[string]any{"kind": "Service", "name": "public"}},
+		}, nil),
+	)
+
+	objects, err := client.ListObjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected a gateway and a route, got %+v", objects)
+	}
+	gateway, route := objects[0], objects[1]
+	if gateway.Kind != domain.KubernetesObjectGateway || len(gateway.Addresses) != 2 || len(gateway.Hostnames) != 2 || gateway.Hostnames[1].Kind != "load_balancer" {
+		t.Fatalf("unexpected gateway: %+v", gateway)
+	}
+	if route.Kind != domain.KubernetesObjectHTTPRoute || len(route.Addresses) != 2 || route.Addresses[0].Kind != "gateway" || route.Hostnames[0].Hostname != "ipam.example.test" {
+		t.Fatalf("unexpected route: %+v", route)
+	}
+}
+
+func TestClientResolvesRoutesWithoutPublishingGateways(t *testing.T) {
+	client, _ := newGatewayTestClient(t, []string{domain.KubernetesObjectHTTPRoute},
+		gatewayAPIObject("Gateway", "apps", "public", "uid-gateway", map[string]any{}, map[string]any{"addresses": []any{map[string]any{"value": "192.0.2.20"}}}),
+		gatewayAPIObject("HTTPRoute", "apps", "ipam", "uid-route", map[string]any{"parentRefs": []any{map[string]any{"name": "public", "namespace": "apps"}}}, nil),
+	)
+	objects, err := client.ListObjects(context.Background())
+	if err != nil || len(objects) != 1 || objects[0].UID != "uid-route" || len(objects[0].Addresses) != 1 {
+		t.Fatalf("expected only the route with its gateway address, got %+v, %v", objects, err)
+	}
+}
+
+func TestClientTreatsMissingGatewayAPIAsEmpty(t *testing.T) {
+	client, dynamicClient := newGatewayTestClient(t, []string{domain.KubernetesObjectGateway, domain.KubernetesObjectHTTPRoute})
+	dynamicClient.PrependReactor("list", "gateways", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(gatewayResource.GroupResource(), "")
+	})
+	objects, err := client.ListObjects(context.Background())
+	if err != nil || len(objects) != 0 {
+		t.Fatalf("expected no objects without the Gateway API, got %+v, %v", objects, err)
+	}
+
+	withoutDynamic := NewClientWithInterface(objectTestConfig("", domain.KubernetesObjectGateway), fake.NewClientset())
+	if _, err := withoutDynamic.ListObjects(context.Background()); err == nil {
+		t.Fatal("expected gateway discovery without a dynamic client to fail")
+	}
+}
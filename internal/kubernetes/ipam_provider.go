@@ -0,0 +1,475 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	capiIPAMGroup = "ipam.cluster.x-k8s.io"
+	// SubnetPoolGroup and SubnetPoolKind name the pool resource whose
+	// IPAddressClaims the IPAM provider serves. A SubnetPool's
+	// spec.subnetID is the IPAM subnet its addresses come from.
+	SubnetPoolGroup = "ipam.simple-k8s-app.io"
+	SubnetPoolKind  = "SubnetPool"
+
+	// releaseAddressFinalizer keeps a claim until its address is released.
+	releaseAddressFinalizer = "ipam.cluster.x-k8s.io/ReleaseAddress"
+	pausedAnnotation        = "cluster.x-k8s.io/paused"
+	// The IP record behind an IPAddress object is named in its annotations;
+	// the record itself is linked to the claim's UID.
+	ipAddressIDAnnotation = SubnetPoolGroup + "/ip-address-id"
+	subnetIDAnnotation    = SubnetPoolGroup + "/subnet-id"
+
+	// allocationAttempts bounds the retries when another writer takes the
+	// chosen address first.
+	allocationAttempts = 5
+)
+
+var (
+	ipAddressClaimResource = schema.GroupVersionResource{Group: capiIPAMGroup, Version: "v1beta1", Resource: "ipaddressclaims"}
+	ipAddressResource      = schema.GroupVersionResource{Group: capiIPAMGroup, Version: "v1beta1", Resource: "ipaddresses"}
+	subnetPoolResource     = schema.GroupVersionResource{Group: SubnetPoolGroup, Version: "v1alpha1", Resource: "subnetpools"}
+)
+
+type typedLocalReference struct {
+	APIGroup *string `json:"apiGroup,omitempty"`
+	Kind     string  `json:"kind"`
+	Name     string  `json:"name"`
+}
+
+// ipAddressClaimObject holds the IPAddressClaim fields the provider reads.
+type ipAddressClaimObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		PoolRef typedLocalReference `json:"poolRef"`
+	} `json:"spec"`
+}
+
+// subnetPoolObject is the SubnetPool resource.
+type subnetPoolObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		SubnetID int64 `json:"subnetID"`
+	} `json:"spec"`
+}
+
+// ipAddressObject is the Cluster API IPAddress the provider creates.
+type ipAddressObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ClaimRef struct {
+			Name string `json:"name"`
+		} `json:"claimRef"`
+		PoolRef typedLocalReference `json:"poolRef"`
+		Address string              `json:"address"`
+		Prefix  int                 `json:"prefix"`
+		Gateway string              `json:"gateway,omitempty"`
+	} `json:"spec"`
+}
+
+// IPAMProvider serves the Cluster API IPAM contract for SubnetPools. For
+// each IPAddressClaim whose poolRef names a SubnetPool it records a free
+// address of the pool's subnet, owned by the claim, creates the IPAddress
+// object and points the claim's status at it. A finalizer on the
+// claim holds its deletion until the address is released.
+type IPAMProvider struct {
+	config IPAMProviderConfig
+	client dynamic.Interface
+	// watchClient serves the informers; see restConfigs.
+	watchClient dynamic.Interface
+	network     domain.NetworkService
+	claims      domain.KubernetesAddressClaimService
+	logger      *slog.Logger
+	pending     *pendingQueue[types.NamespacedName, struct{}]
+}
+
+func NewIPAMProvider(config IPAMProviderConfig, network domain.NetworkService, claims domain.KubernetesAddressClaimService, logger *slog.Logger) (*IPAMProvider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	watchConfig, restConfig, err := restConfigs(config.clientConfig(), "simple-k8s-app-ipam-provider")
+	if err != nil {
+		return nil, err
+	}
+	watchClient, err := dynamic.NewForConfig(watchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes dynamic client: %w", err)
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes dynamic client: %w", err)
+	}
+	provider := NewIPAMProviderWithInterface(config, client, network, claims, logger)
+	provider.watchClient = watchClient
+	return provider, nil
+}
+
+func NewIPAMProviderWithInterface(config IPAMProviderConfig, client dynamic.Interface, network domain.NetworkService, claims domain.KubernetesAddressClaimService, logger *slog.Logger) *IPAMProvider {
+	return &IPAMProvider{
+		config: config, client: client, watchClient: client, network: network, claims: claims, logger: logger,
+		pending: newPendingQueue[types.NamespacedName, struct{}](),
+	}
+}
+
+// Run syncs every claim each ResyncInterval and, in between, handles
+// watched claims as they change. Without a watch it only syncs.
+func (p *IPAMProvider) Run(ctx context.Context) {
+	controllerLoop{
+		name: "kubernetes ipam provider", logger: p.logger,
+		interval: p.config.ResyncInterval, changed: p.pending.changed,
+		watch: p.watch, sync: p.Sync, handlePending: p.HandlePending,
+	}.run(ctx)
+}
+
+// Sync reconciles every claim in the watched namespaces. A cluster without
+// the Cluster API IPAM resources has no claims.
+func (p *IPAMProvider) Sync(ctx context.Context) error {
+	var errs []error
+	for _, namespace := range watchedNamespaces(p.config.Namespaces) {
+		requestCtx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
+		list, err := p.client.Resource(ipAddressClaimResource).Namespace(namespace).List(requestCtx, metav1.ListOptions{})
+		cancel()
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("list ipaddressclaims in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		for i := range list.Items {
+			if err := p.reconcileClaim(ctx, &list.Items[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandlePending reconciles the watched claims queued since the last call.
+// A claim that fails is left to the next sync.
+func (p *IPAMProvider) HandlePending(ctx context.Context) error {
+	pending := p.pending.take()
+	var errs []error
+	for key := range pending {
+		claim, err := p.get(ctx, ipAddressClaimResource, key.Namespace, key.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get ipaddressclaim %s: %w", key, err))
+			continue
+		}
+		if err := p.reconcileClaim(ctx, claim); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reconcileClaim allocates for a SubnetPool claim, or releases its address
+// once the claim is being deleted. Problems the claim's owner can fix, such
+// as a missing pool or a full subnet, are reported on the claim's Ready
+// condition instead of returned.
+func (p *IPAMProvider) reconcileClaim(ctx context.Context, object *unstructured.Unstructured) error {
+	var claim ipAddressClaimObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &claim); err != nil {
+		return fmt.Errorf("ipaddressclaim %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+	}
+	ref := claim.Spec.PoolRef
+	if ref.APIGroup == nil || *ref.APIGroup != SubnetPoolGroup || ref.Kind != SubnetPoolKind {
+		return nil
+	}
+	if claim.DeletionTimestamp != nil {
+		return p.release(ctx, object, claim)
+	}
+	if _, paused := claim.Annotations[pausedAnnotation]; paused {
+		return nil
+	}
+	if !slices.Contains(object.GetFinalizers(), releaseAddressFinalizer) {
+		object = object.DeepCopy()
+		object.SetFinalizers(append(object.GetFinalizers(), releaseAddressFinalizer))
+		updated, err := p.update(ctx, ipAddressClaimResource, object)
+		if err != nil {
+			return fmt.Errorf("add finalizer to ipaddressclaim %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+		object = updated
+	}
+
+	poolObject, err := p.get(ctx, subnetPoolResource, claim.Namespace, ref.Name)
+	if apierrors.IsNotFound(err) {
+		return p.setClaimStatus(ctx, object, "", "PoolNotFound", fmt.Sprintf("SubnetPool %q not found", ref.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("get subnetpool %s/%s: %w", claim.Namespace, ref.Name, err)
+	}
+	var pool subnetPoolObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(poolObject.Object, &pool); err != nil || pool.Spec.SubnetID <= 0 {
+		return p.setClaimStatus(ctx, object, "", "InvalidPool", fmt.Sprintf("SubnetPool %q has no valid spec.subnetID", ref.Name))
+	}
+
+	address, err := p.get(ctx, ipAddressResource, claim.Namespace, claim.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		address, err = p.allocate(ctx, claim, pool)
+		if errors.Is(err, domain.ErrSubnetFull) || errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrInvalidInput) {
+			return p.setClaimStatus(ctx, object, "", "AllocationFailed", err.Error())
+		}
+		if err != nil {
+			return fmt.Errorf("allocate address for ipaddressclaim %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+		ip, _, _ := unstructured.NestedString(address.Object, "spec", "address")
+		p.logger.InfoContext(ctx, "kubernetes ipam address allocated", "claim", claim.Namespace+"/"+claim.Name, "ip", ip)
+	case err != nil:
+		return fmt.Errorf("get ipaddress %s/%s: %w", claim.Namespace, claim.Name, err)
+	case !ownedBy(address, claim.UID):
+		return fmt.Errorf("ipaddress %s/%s belongs to another claim", claim.Namespace, claim.Name)
+	}
+	return p.setClaimStatus(ctx, object, address.GetName(), "", "")
+}
+
+// allocate records the claim's address in the pool's subnet and creates the
+// IPAddress object for it. An address taken by another writer in between is
+// retried. The record stays with the claim when the object cannot be
+// created, so the next attempt reuses it and release deletes it.
+func (p *IPAMProvider) allocate(ctx context.Context, claim ipAddressClaimObject, pool subnetPoolObject) (*unstructured.Unstructured, error) {
+	subnet, err := p.network.GetSubnet(ctx, pool.Spec.SubnetID)
+	if err != nil {
+		return nil, err
+	}
+	for range allocationAttempts {
+		record, err := p.claims.Claim(ctx, domain.ClaimAddressInput{SubnetID: subnet.ID, ClaimUID: string(claim.UID), Namespace: claim.Namespace, Name: claim.Name})
+		if errors.Is(err, domain.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		address, err := ipAddressFor(claim, pool, subnet, record)
+		if err != nil {
+			return nil, fmt.Errorf("create ipaddress %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+		created, err := p.create(ctx, ipAddressResource, address)
+		if apierrors.IsAlreadyExists(err) {
+			return p.get(ctx, ipAddressResource, claim.Namespace, claim.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("create ipaddress %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+		return created, nil
+	}
+	return nil, fmt.Errorf("%w: addresses in %s kept being taken", domain.ErrConflict, subnet.CIDR)
+}
+
+// release deletes the claim's IP record and IPAddress object, then lets the
+// claim go.
+func (p *IPAMProvider) release(ctx context.Context, object *unstructured.Unstructured, claim ipAddressClaimObject) error {
+	if !slices.Contains(object.GetFinalizers(), releaseAddressFinalizer) {
+		return nil
+	}
+	released, err := p.claims.Release(ctx, string(claim.UID))
+	if err != nil {
+		return fmt.Errorf("release address of ipaddressclaim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	address, err := p.get(ctx, ipAddressResource, claim.Namespace, claim.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("get ipaddress %s/%s: %w", claim.Namespace, claim.Name, err)
+	case ownedBy(address, claim.UID):
+		if !released {
+			if err := p.releaseRecord(ctx, address); err != nil {
+				return err
+			}
+		}
+		requestCtx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
+		err := p.client.Resource(ipAddressResource).Namespace(claim.Namespace).Delete(requestCtx, claim.Name, metav1.DeleteOptions{})
+		cancel()
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete ipaddress %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+	}
+	object = object.DeepCopy()
+	object.SetFinalizers(slices.DeleteFunc(object.GetFinalizers(), func(finalizer string) bool { return finalizer == releaseAddressFinalizer }))
+	if _, err := p.update(ctx, ipAddressClaimResource, object); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("remove finalizer from ipaddressclaim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	p.logger.InfoContext(ctx, "kubernetes ipam address released", "claim", claim.Namespace+"/"+claim.Name)
+	return nil
+}
+
+// releaseRecord deletes the IP record named in the IPAddress annotations,
+// for addresses recorded before records were linked to their claims. A
+// record already deleted by hand counts as released.
+func (p *IPAMProvider) releaseRecord(ctx context.Context, address *unstructured.Unstructured) error {
+	annotations := address.GetAnnotations()
+	id := annotations[ipAddressIDAnnotation]
+	subnetID, err := strconv.ParseInt(annotations[subnetIDAnnotation], 10, 64)
+	if id == "" || err != nil {
+		return nil
+	}
+	if err := p.network.DeleteIP(ctx, subnetID, domain.IPAddressID(id)); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("release address of ipaddress %s/%s: %w", address.GetNamespace(), address.GetName(), err)
+	}
+	return nil
+}
+
+// setClaimStatus points the claim at its IPAddress and sets its Ready
+// condition: true with an address, otherwise false with reason and message.
+// An unchanged status is not written.
+func (p *IPAMProvider) setClaimStatus(ctx context.Context, object *unstructured.Unstructured, addressName, reason, message string) error {
+	ready := map[string]any{"type": "Ready", "status": "True"}
+	if addressName == "" {
+		ready = map[string]any{"type": "Ready", "status": "False", "severity": "Error", "reason": reason, "message": message}
+	}
+	conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+	updated := make([]any, 0, len(conditions)+1)
+	for _, condition := range conditions {
+		current, ok := condition.(map[string]any)
+		if !ok || current["type"] != "Ready" {
+			updated = append(updated, condition)
+			continue
+		}
+		ready["lastTransitionTime"] = current["lastTransitionTime"]
+		if current["status"] != ready["status"] {
+			ready["lastTransitionTime"] = nil
+		}
+	}
+	if ready["lastTransitionTime"] == nil {
+		ready["lastTransitionTime"] = metav1.Now().UTC().Format(time.RFC3339)
+	}
+	updated = append(updated, ready)
+
+	currentName, _, _ := unstructured.NestedString(object.Object, "status", "addressRef", "name")
+	if currentName == addressName && reflect.DeepEqual(conditions, updated) {
+		return nil
+	}
+	object = object.DeepCopy()
+	if addressName == "" {
+		unstructured.RemoveNestedField(object.Object, "status", "addressRef")
+	} else if err := unstructured.SetNestedField(object.Object, addressName, "status", "addressRef", "name"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedSlice(object.Object, updated, "status", "conditions"); err != nil {
+		return err
+	}
+	requestCtx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
+	defer cancel()
+	if _, err := p.client.Resource(ipAddressClaimResource).Namespace(object.GetNamespace()).UpdateStatus(requestCtx, object, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update status of ipaddressclaim %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+	}
+	return nil
+}
+
+// ipAddressFor builds the IPAddress object for a recorded address. It is
+// owned by the claim, so it goes with it, and by the pool.
+func ipAddressFor(claim ipAddressClaimObject, pool subnetPoolObject, subnet domain.Subnet, record domain.KubernetesAddressClaim) (*unstructured.Unstructured, error) {
+	group := SubnetPoolGroup
+	var address ipAddressObject
+	address.APIVersion = ipAddressResource.GroupVersion().String()
+	address.Kind = "IPAddress"
+	address.Name = claim.Name
+	address.Namespace = claim.Namespace
+	address.Annotations = map[string]string{
+		ipAddressIDAnnotation: string(record.IPAddressID),
+		subnetIDAnnotation:    strconv.FormatInt(subnet.ID, 10),
+	}
+	controller := true
+	address.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: ipAddressClaimResource.GroupVersion().String(), Kind: "IPAddressClaim", Name: claim.Name, UID: claim.UID, Controller: &controller, BlockOwnerDeletion: &controller},
+		{APIVersion: subnetPoolResource.GroupVersion().String(), Kind: SubnetPoolKind, Name: pool.Name, UID: pool.UID},
+	}
+	address.Spec.ClaimRef.Name = claim.Name
+	address.Spec.PoolRef = typedLocalReference{APIGroup: &group, Kind: SubnetPoolKind, Name: pool.Name}
+	address.Spec.Address = record.IP.String()
+	address.Spec.Prefix = subnet.CIDR.Bits()
+	if subnet.Gateway.IsValid() {
+		address.Spec.Gateway = subnet.Gateway.String()
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&address)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: object}, nil
+}
+
+func ownedBy(object *unstructured.Unstructured, uid types.UID) bool {
+	for _, owner := range object.GetOwnerReferences() {
+		if owner.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *IPAMProvider) get(ctx context.Context, resource schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
+	defer cancel()
+	return p.client.Resource(resource).Namespace(namespace).Get(requestCtx, name, metav1.GetOptions{})
+}
+
+func (p *IPAMProvider) create(ctx context.Context, resource schema.GroupVersionResource, object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
+	defer cancel()
+	return p.client.Resource(resource).Namespace(object.GetNamespace()).Create(requestCtx, object, metav1.CreateOptions{})
+}
+
+func (p *IPAMProvider) update(ctx context.Context, resource schema.GroupVersionResource, object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
+	defer cancel()
+	return p.client.Resource(resource).Namespace(object.GetNamespace()).Update(requestCtx, object, metav1.UpdateOptions{})
+}
+
+// watch starts one claim informer per namespace, or one cluster-wide for
+// "*", and returns once they have synced. Claims present at the start are
+// left to the first sync.
+func (p *IPAMProvider) watch(ctx context.Context) error {
+	handler := cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj any, isInInitialList bool) {
+			if object, ok := obj.(*unstructured.Unstructured); ok && !isInInitialList {
+				p.enqueue(object)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			previous, okPrevious := oldObj.(*unstructured.Unstructured)
+			current, ok := newObj.(*unstructured.Unstructured)
+			if ok && okPrevious && previous.GetResourceVersion() != current.GetResourceVersion() {
+				p.enqueue(current)
+			}
+		},
+	}
+	factories := make([]informerFactory, 0, len(p.config.Namespaces))
+	synced := make([]cache.InformerSynced, 0, len(p.config.Namespaces))
+	for _, namespace := range watchedNamespaces(p.config.Namespaces) {
+		factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(p.watchClient, 0, namespace, nil)
+		informer := factory.ForResource(ipAddressClaimResource).Informer()
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return fmt.Errorf("watch ipaddressclaims in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		factories = append(factories, factory)
+		synced = append(synced, informer.HasSynced)
+	}
+	return runInformers(ctx, "ipaddressclaim", factories, synced)
+}
+
+func (p *IPAMProvider) enqueue(object *unstructured.Unstructured) {
+	p.pending.add(types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}, struct{}{})
+}
@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// IPAMProviderConfig configures the Cluster API IPAM provider. It serves the
+// IPAddressClaims in Namespaces whose pool is a SubnetPool.
+type IPAMProviderConfig struct {
+	Enabled           bool
+	Namespaces        []string
+	AuthMode          string
+	KubeconfigPath    string
+	KubeconfigContext string
+	ResyncInterval    time.Duration
+	RequestTimeout    time.Duration
+}
+
+func IPAMProviderConfigFromEnv(getenv func(string) string) (IPAMProviderConfig, error) {
+	cfg := IPAMProviderConfig{
+		Namespaces:        parseList(valueOrDefault(getenv("KUBERNETES_CAPI_IPAM_NAMESPACES"), "*")),
+		AuthMode:          valueOrDefault(getenv("KUBERNETES_CAPI_IPAM_AUTH_MODE"), AuthModeInCluster),
+		KubeconfigPath:    strings.TrimSpace(getenv("KUBERNETES_CAPI_IPAM_KUBECONFIG_PATH")),
+		KubeconfigContext: strings.TrimSpace(getenv("KUBERNETES_CAPI_IPAM_KUBECONFIG_CONTEXT")),
+		ResyncInterval:    domain.DefaultKubernetesReconcileInterval,
+		RequestTimeout:    domain.DefaultKubernetesRequestTimeout,
+	}
+	if raw := strings.TrimSpace(getenv("KUBERNETES_CAPI_IPAM_ENABLED")); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return IPAMProviderConfig{}, fmt.Errorf("KUBERNETES_CAPI_IPAM_ENABLED: %w", err)
+		}
+		cfg.Enabled = enabled
+	}
+	var err error
+	if cfg.ResyncInterval, err = parseDuration(getenv("KUBERNETES_CAPI_IPAM_INTERVAL"), cfg.ResyncInterval); err != nil {
+		return IPAMProviderConfig{}, fmt.Errorf("KUBERNETES_CAPI_IPAM_INTERVAL: %w", err)
+	}
+	if cfg.RequestTimeout, err = parseDuration(getenv("KUBERNETES_CAPI_IPAM_REQUEST_TIMEOUT"), cfg.RequestTimeout); err != nil {
+		return IPAMProviderConfig{}, fmt.Errorf("KUBERNETES_CAPI_IPAM_REQUEST_TIMEOUT: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return IPAMProviderConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c IPAMProviderConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.Namespaces) == 0 {
+		return fmt.Errorf("KUBERNETES_CAPI_IPAM_NAMESPACES is required when the IPAM provider is enabled")
+	}
+	if err := validateNamespaces(c.Namespaces, ipamProviderSetting); err != nil {
+		return err
+	}
+	if err := c.clientConfig().validateAuth(ipamProviderSetting); err != nil {
+		return err
+	}
+	if c.ResyncInterval <= 0 {
+		return fmt.Errorf("kubernetes IPAM provider interval must be positive")
+	}
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("kubernetes IPAM provider request timeout must be positive")
+	}
+	return nil
+}
+
+// clientConfig holds the settings the provider's client shares with
+// discovery.
+func (c IPAMProviderConfig) clientConfig() Config {
+	return Config{
+		AuthMode: c.AuthMode, KubeconfigPath: c.KubeconfigPath, KubeconfigContext: c.KubeconfigContext,
+		RequestTimeout: c.RequestTimeout,
+	}
+}
+
+// ipamProviderSetting names a setting after its IPAM provider environment
+// variable.
+func ipamProviderSetting(name string) string {
+	return "KUBERNETES_CAPI_IPAM_" + strings.ToUpper(name)
+}
@@ -0,0 +1,420 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// stubIPAMNetwork records addresses in one subnet, each linked to the claim
+// it was recorded for. The first conflicts calls to Claim fail as if another
+// writer took the address.
+type stubIPAMNetwork struct {
+	domain.NetworkService
+	mu        sync.Mutex
+	subnet    domain.Subnet
+	ips       []domain.IPAddress
+	owners    map[string]domain.IPAddressID
+	conflicts int
+	created   []domain.ClaimAddressInput
+	deleted   []domain.IPAddressID
+}
+
+func (s *stubIPAMNetwork) GetSubnet(_ context.Context, id int64) (domain.Subnet, error) {
+	if id != s.subnet.ID {
+		return domain.Subnet{}, fmt.Errorf("%w: %w", domain.ErrNotFound, domain.ErrSubnetNotFound)
+	}
+	return s.subnet, nil
+}
+
+func (s *stubIPAMNetwork) Claim(_ context.Context, input domain.ClaimAddressInput) (domain.KubernetesAddressClaim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ip := range s.ips {
+		if ip.ID == s.owners[input.ClaimUID] {
+			return domain.KubernetesAddressClaim{ClaimUID: input.ClaimUID, IPAddressID: ip.ID, IP: ip.IP, SubnetID: ip.SubnetID}, nil
+		}
+	}
+	used := make(map[netip.Addr]bool, len(s.ips))
+	for _, ip := range s.ips {
+		used[ip.IP] = true
+	}
+	address, ok := domain.FreeAddress(s.subnet, used, netip.Addr{})
+	if !ok {
+		return domain.KubernetesAddressClaim{}, domain.ErrSubnetFull
+	}
+	ip := domain.IPAddress{ID: domain.IPAddressID(fmt.Sprintf("ip-%d", len(s.created)+1)), IP: address, Hostname: input.Name, SubnetID: input.SubnetID}
+	s.created = append(s.created, input)
+	s.ips = append(s.ips, ip)
+	if s.conflicts > 0 {
+		s.conflicts--
+		return domain.KubernetesAddressClaim{}, domain.ErrConflict
+	}
+	s.owners[input.ClaimUID] = ip.ID
+	return domain.KubernetesAddressClaim{ClaimUID: input.ClaimUID, IPAddressID: ip.ID, IP: ip.IP, SubnetID: ip.SubnetID}, nil
+}
+
+func (s *stubIPAMNetwork) Release(_ context.Context, claimUID string) (bool, error) {
+	s.mu.Lock()
+	id, ok := s.owners[claimUID]
+	delete(s.owners, claimUID)
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, s.DeleteIP(context.Background(), s.subnet.ID, id)
+}
+
+func (s *stubIPAMNetwork) DeleteIP(_ context.Context, _ int64, id domain.IPAddressID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, id)
+	s.ips = slices.DeleteFunc(s.ips, func(ip domain.IPAddress) bool { return ip.ID == id })
+	return nil
+}
+
+func newStubIPAMNetwork(used ...string) *stubIPAMNetwork {
+	network := &stubIPAMNetwork{subnet: domain.Subnet{ID: 5, CIDR: netip.MustParsePrefix("192.0.2.0/29"), Gateway: netip.MustParseAddr("192.0.2.1")}, owners: make(map[string]domain.IPAddressID)}
+	for i, address := range used {
+		network.ips = append(network.ips, domain.IPAddress{ID: domain.IPAddressID(fmt.Sprintf("manual-%d", i)), IP: netip.MustParseAddr(address)})
+	}
+	return network
+}
+
+func subnetPool(name string, subnetID int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": SubnetPoolGroup + "/v1alpha1",
+		"kind":       SubnetPoolKind,
+		"metadata":   map[string]any{"name": name, "namespace": "capi", "uid": "uid-pool-" + name},
+		"spec":       map[string]any{"subnetID": subnetID},
+	}}
+}
+
+func ipAddressClaim(name, poolGroup, poolKind, poolName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": capiIPAMGroup + "/v1beta1",
+		"kind":       "IPAddressClaim",
+		"metadata":   map[string]any{"name": name, "namespace": "capi", "uid": "uid-claim-" + name},
+		"spec":       map[string]any{"poolRef": map[string]any{"apiGroup": poolGroup, "kind": poolKind, "name": poolName}},
+	}}
+}
+
+func newIPAMTestProvider(t *testing.T, network *stubIPAMNetwork, objects ...*unstructured.Unstructured) (*IPAMProvider, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ipAddressClaimResource: "IPAddressClaimList",
+		ipAddressResource:      "IPAddressList",
+		subnetPoolResource:     "SubnetPoolList",
+	})
+	for _, object := range objects {
+		resource := ipAddressClaimResource
+		switch object.GetKind() {
+		case SubnetPoolKind:
+			resource = subnetPoolResource
+		case "IPAddress":
+			resource = ipAddressResource
+		}
+		if err := client.Tracker().Create(resource, object, object.GetNamespace()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := IPAMProviderConfig{Enabled: true, Namespaces: []string{"capi"}, AuthMode: AuthModeInCluster, ResyncInterval: time.Hour, RequestTimeout: time.Second}
+	return NewIPAMProviderWithInterface(config, client, network, network, slog.New(slog.NewTextHandler(io.Discard, nil))), client
+}
+
+func getObject(t *testing.T, client *dynamicfake.FakeDynamicClient, resource schema.GroupVersionResource, name string) *unstructured.Unstructured {
+	t.Helper()
+	object, err := client.Resource(resource).Namespace("capi").Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return object
+}
+
+func readyCondition(t *testing.T, claim *unstructured.Unstructured) map[string]any {
+	t.Helper()
+	conditions, _, _ := unstructured.NestedSlice(claim.Object, "status", "conditions")
+	for _, condition := range conditions {
+		if condition := condition.(map[string]any); condition["type"] == "Ready" {
+			return condition
+		}
+	}
+	t.Fatalf("claim %s has no Ready condition: %v", claim.GetName(), claim.Object["status"])
+	return nil
+}
+
+func TestIPAMProviderAllocatesForSubnetPoolClaims(t *testing.T) {
+	network := newStubIPAMNetwork("192.0.2.2")
+	provider, client := newIPAMTestProvider(t, network,
+		subnetPool("nodes", 5),
+		ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"),
+		ipAddressClaim("other-0", "ipam.cluster.x-k8s.io", "InClusterIPPool", "nodes"),
+	)
+
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(network.created) != 1 || network.created[0].ClaimUID != "uid-claim-worker-0" || network.created[0].Name != "worker-0" {
+		t.Fatalf("expected one address recorded for the claim, got %+v", network.created)
+	}
+
+	address := getObject(t, client, ipAddressResource, "worker-0")
+	spec := address.Object["spec"].(map[string]any)
+	if spec["address"] != "192.0.2.3" || spec["prefix"] != int64(29) || spec["gateway"] != "192.0.2.1" {
+		t.Fatalf("unexpected ipaddress spec: %v", spec)
+	}
+	if claimRef := spec["claimRef"].(map[string]any); claimRef["name"] != "worker-0" {
+		t.Fatalf("unexpected claimRef: %v", claimRef)
+	}
+	if annotations := address.GetAnnotations(); annotations[ipAddressIDAnnotation] != "ip-1" || annotations[subnetIDAnnotation] != "5" {
+		t.Fatalf("unexpected ipaddress annotations: %v", annotations)
+	}
+	if !ownedBy(address, "uid-claim-worker-0") {
+		t.Fatalf("ipaddress is not owned by its claim: %v", address.GetOwnerReferences())
+	}
+
+	claim := getObject(t, client, ipAddressClaimResource, "worker-0")
+	if !slices.Contains(claim.GetFinalizers(), releaseAddressFinalizer) {
+		t.Fatalf("claim has no release finalizer: %v", claim.GetFinalizers())
+	}
+	if name, _, _ := unstructured.NestedString(claim.Object, "status", "addressRef", "name"); name != "worker-0" {
+		t.Fatalf("claim status does not reference the address: %v", claim.Object["status"])
+	}
+	if ready := readyCondition(t, claim); ready["status"] != "True" {
+		t.Fatalf("claim is not ready: %v", ready)
+	}
+	if other := getObject(t, client, ipAddressClaimResource, "other-0"); len(other.GetFinalizers()) != 0 || other.Object["status"] != nil {
+		t.Fatalf("claim of another provider was touched: %v", other.Object)
+	}
+
+	// A second sync finds everything in place and writes nothing.
+	actions := len(client.Actions())
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	for _, action := range client.Actions()[actions:] {
+		if action.GetVerb() != "get" && action.GetVerb() != "list" {
+			t.Fatalf("unexpected write on an unchanged claim: %v", action)
+		}
+	}
+	if len(network.created) != 1 {
+		t.Fatalf("second sync allocated again: %+v", network.created)
+	}
+}
+
+func TestIPAMProviderRetriesTakenAddresses(t *testing.T) {
+	network := newStubIPAMNetwork()
+	network.conflicts = 1
+	provider, client := newIPAMTestProvider(t, network, subnetPool("nodes", 5), ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"))
+
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	address := getObject(t, client, ipAddressResource, "worker-0")
+	if ip, _, _ := unstructured.NestedString(address.Object, "spec", "address"); ip != "192.0.2.3" {
+		t.Fatalf("expected the next address after a conflict, got %s", ip)
+	}
+}
+
+func TestIPAMProviderSkipsDHCPPools(t *testing.T) {
+	network := newStubIPAMNetwork()
+	network.subnet.DHCPPools = []domain.DHCPPool{{Start: netip.MustParseAddr("192.0.2.2"), End: netip.MustParseAddr("192.0.2.4")}}
+	provider, client := newIPAMTestProvider(t, network, subnetPool("nodes", 5), ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"))
+
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	address := getObject(t, client, ipAddressResource, "worker-0")
+	if ip, _, _ := unstructured.NestedString(address.Object, "spec", "address"); ip != "192.0.2.5" {
+		t.Fatalf("expected the first address after the DHCP pool, got %s", ip)
+	}
+}
+
+func TestIPAMProviderSyncContinuesPastMissingClaims(t *testing.T) {
+	provider, client := newIPAMTestProvider(t, newStubIPAMNetwork(), subnetPool("nodes", 5), ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"))
+	provider.config.Namespaces = []string{"legacy", "capi"}
+	client.PrependReactor("list", "ipaddressclaims", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "legacy" {
+			return true, nil, apierrors.NewNotFound(ipAddressClaimResource.GroupResource(), "")
+		}
+		return false, nil, nil
+	})
+
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if name, _, _ := unstructured.NestedString(getObject(t, client, ipAddressClaimResource, "worker-0").Object, "status", "addressRef", "name"); name != "worker-0" {
+		t.Fatal("claims after a namespace without the resource were not served")
+	}
+}
+
+func TestIPAMProviderReportsClaimProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		network *stubIPAMNetwork
+		pool    *unstructured.Unstructured
+		reason  string
+	}{
+		{name: "missing pool", network: newStubIPAMNetwork(), pool: subnetPool("elsewhere", 5), reason: "PoolNotFound"},
+		{name: "missing subnet", network: newStubIPAMNetwork(), pool: subnetPool("nodes", 9), reason: "AllocationFailed"},
+		{name: "full subnet", network: newStubIPAMNetwork("192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5", "192.0.2.6"), pool: subnetPool("nodes", 5), reason: "AllocationFailed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, client := newIPAMTestProvider(t, tt.network, tt.pool, ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"))
+			if err := provider.Sync(context.Background()); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			ready := readyCondition(t, getObject(t, client, ipAddressClaimResource, "worker-0"))
+			if ready["status"] != "False" || ready["reason"] != tt.reason {
+				t.Fatalf("expected reason %s, got %v", tt.reason, ready)
+			}
+			if _, err := client.Resource(ipAddressResource).Namespace("capi").Get(context.Background(), "worker-0", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+				t.Fatalf("expected no ipaddress, got %v", err)
+			}
+		})
+	}
+}
+
+func TestIPAMProviderReleasesDeletedClaims(t *testing.T) {
+	network := newStubIPAMNetwork()
+	provider, client := newIPAMTestProvider(t, network, subnetPool("nodes", 5), ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"))
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// The fake client deletes at once, so mark the claim as being deleted.
+	claim := getObject(t, client, ipAddressClaimResource, "worker-0")
+	now := metav1.Now()
+	claim.SetDeletionTimestamp(&now)
+	if _, err := client.Resource(ipAddressClaimResource).Namespace("capi").Update(context.Background(), claim, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	if !slices.Equal(network.deleted, []domain.IPAddressID{"ip-1"}) {
+		t.Fatalf("expected the address record to be released, got %v", network.deleted)
+	}
+	if _, err := client.Resource(ipAddressResource).Namespace("capi").Get(context.Background(), "worker-0", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the ipaddress to be deleted, got %v", err)
+	}
+	if finalizers := getObject(t, client, ipAddressClaimResource, "worker-0").GetFinalizers(); len(finalizers) != 0 {
+		t.Fatalf("finalizer was not removed: %v", finalizers)
+	}
+}
+
+func TestIPAMProviderReleasesRecordsWithoutIPAddress(t *testing.T) {
+	network := newStubIPAMNetwork()
+	provider, client := newIPAMTestProvider(t, network, subnetPool("nodes", 5), ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"))
+	client.PrependReactor("create", "ipaddresses", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(ipAddressResource.GroupResource(), "worker-0", fmt.Errorf("denied"))
+	})
+	if err := provider.Sync(context.Background()); err == nil {
+		t.Fatal("expected the ipaddress creation to fail")
+	}
+	if err := provider.Sync(context.Background()); err == nil {
+		t.Fatal("expected the ipaddress creation to fail again")
+	}
+	if len(network.ips) != 1 {
+		t.Fatalf("expected the retry to reuse the claim's record, got %+v", network.ips)
+	}
+
+	claim := getObject(t, client, ipAddressClaimResource, "worker-0")
+	now := metav1.Now()
+	claim.SetDeletionTimestamp(&now)
+	if _, err := client.Resource(ipAddressClaimResource).Namespace("capi").Update(context.Background(), claim, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(network.ips) != 0 || !slices.Equal(network.deleted, []domain.IPAddressID{"ip-1"}) {
+		t.Fatalf("expected the claim's record to be released, got %+v deleted %v", network.ips, network.deleted)
+	}
+}
+
+func TestIPAMProviderReleasesUnlinkedRecordsThroughAnnotations(t *testing.T) {
+	network := newStubIPAMNetwork("192.0.2.2")
+	claim := ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes")
+	now := metav1.Now()
+	claim.SetDeletionTimestamp(&now)
+	claim.SetFinalizers([]string{releaseAddressFinalizer})
+	address := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": capiIPAMGroup + "/v1beta1",
+		"kind":       "IPAddress",
+		"metadata": map[string]any{
+			"name": "worker-0", "namespace": "capi",
+			"annotations":     map[string]any{ipAddressIDAnnotation: "manual-0", subnetIDAnnotation: "5"},
+			"ownerReferences": []any{map[string]any{"apiVersion": capiIPAMGroup + "/v1beta1", "kind": "IPAddressClaim", "name": "worker-0", "uid": "uid-claim-worker-0"}},
+		},
+	}}
+	provider, client := newIPAMTestProvider(t, network, subnetPool("nodes", 5), claim, address)
+
+	if err := provider.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !slices.Equal(network.deleted, []domain.IPAddressID{"manual-0"}) {
+		t.Fatalf("expected the annotated record to be released, got %v", network.deleted)
+	}
+	if _, err := client.Resource(ipAddressResource).Namespace("capi").Get(context.Background(), "worker-0", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the ipaddress to be deleted, got %v", err)
+	}
+}
+
+func TestIPAMProviderRunServesWatchedClaims(t *testing.T) {
+	provider, client := newIPAMTestProvider(t, newStubIPAMNetwork(), subnetPool("nodes", 5))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go provider.Run(ctx)
+
+	claims := client.Resource(ipAddressClaimResource).Namespace("capi")
+	// The watch may start after the claim exists; the first sync covers it.
+	waitFor(t, func() bool {
+		if _, err := claims.Get(ctx, "worker-0", metav1.GetOptions{}); err != nil {
+			_, _ = claims.Create(ctx, ipAddressClaim("worker-0", SubnetPoolGroup, SubnetPoolKind, "nodes"), metav1.CreateOptions{})
+		}
+		claim, err := claims.Get(ctx, "worker-0", metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		name, _, _ := unstructured.NestedString(claim.Object, "status", "addressRef", "name")
+		return name == "worker-0"
+	})
+}
+
+func TestIPAMProviderConfigFromEnv(t *testing.T) {
+	cfg, err := IPAMProviderConfigFromEnv(func(key string) string {
+		return map[string]string{"KUBERNETES_CAPI_IPAM_ENABLED": "true", "KUBERNETES_CAPI_IPAM_INTERVAL": "1m"}[key]
+	})
+	if err != nil {
+		t.Fatalf("IPAMProviderConfigFromEnv: %v", err)
+	}
+	if !cfg.Enabled || !slices.Equal(cfg.Namespaces, []string{"*"}) || cfg.ResyncInterval != time.Minute || cfg.AuthMode != AuthModeInCluster {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	_, err = IPAMProviderConfigFromEnv(func(key string) string {
+		return map[string]string{"KUBERNETES_CAPI_IPAM_ENABLED": "true", "KUBERNETES_CAPI_IPAM_AUTH_MODE": "kubeconfig"}[key]
+	})
+	if err == nil || !strings.Contains(err.Error(), "KUBERNETES_CAPI_IPAM_KUBECONFIG_PATH") {
+		t.Fatalf("expected a missing kubeconfig path error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// CheckKubeconfig reports whether a kubeconfig stored through the API can
+// be used by a runner. It must embed its credentials: file references would
+// be read from the API server's disk, and exec and auth-provider plugins
+// would run there.
+func CheckKubeconfig(kubeconfig []byte, contextName string) error {
+	_, err := inlineKubeconfigRESTConfig(kubeconfig, contextName)
+	return err
+}
+
+func inlineKubeconfigRESTConfig(kubeconfig []byte, contextName string) (*rest.Config, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("parse kubeconfig: %w", err)
+	}
+	if contextName == "" {
+		if contextName = config.CurrentContext; contextName == "" {
+			return nil, errors.New("kubeconfig has no current-context; set a context")
+		}
+	}
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	if cluster, ok := config.Clusters[kubeContext.Cluster]; ok && cluster.CertificateAuthority != "" {
+		return nil, errors.New("certificate-authority files are not supported; embed certificate-authority-data")
+	}
+	if authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]; ok {
+		switch {
+		case authInfo.Exec != nil || authInfo.AuthProvider != nil:
+			return nil, errors.New("exec and auth-provider credentials are not supported; use a token or a client certificate")
+		case authInfo.ClientCertificate != "" || authInfo.ClientKey != "" || authInfo.TokenFile != "":
+			return nil, errors.New("credential files are not supported; embed client-certificate-data, client-key-data or token")
+		}
+	}
+	result, err := clientcmd.NewNonInteractiveClientConfig(*config, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubernetes kubeconfig: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+)
+
+const inlineKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+  - name: prod
+    cluster:
+      server: https://prod.example.test:6443
+contexts:
+  - name: prod
+    context: {cluster: prod, user: discovery}
+  - name: files
+    context: {cluster: prod, user: files}
+  - name: plugin
+    context: {cluster: prod, user: plugin}
+users:
+  - name: discovery
+    user: {token: secret-token}
+  - name: files
+    user: {tokenFile: /var/run/secrets/token}
+  - name: plugin
+    user:
+      exec: {apiVersion: client.authentication.k8s.io/v1, command: /bin/sh}
+`
+
+func TestCheckKubeconfig(t *testing.T) {
+	if err := CheckKubeconfig([]byte(inlineKubeconfig), ""); err != nil {
+		t.Fatalf("current context: %v", err)
+	}
+	config, err := inlineKubeconfigRESTConfig([]byte(inlineKubeconfig), "prod")
+	if err != nil || config.Host != "https://prod.example.test:6443" || config.BearerToken != "secret-token" {
+		t.Fatalf("unexpected rest config: %+v, %v", config, err)
+	}
+	tests := map[string]string{
+		"files":   "credential files are not supported",
+		"plugin":  "exec and auth-provider credentials are not supported",
+		"missing": `context "missing" not found`,
+	}
+	for contextName, want := range tests {
+		if err := CheckKubeconfig([]byte(inlineKubeconfig), contextName); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("context %s: expected %q, got %v", contextName, want, err)
+		}
+	}
+	if err := CheckKubeconfig([]byte("not: [yaml"), ""); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
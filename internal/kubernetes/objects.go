@@ -0,0 +1,285 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ObjectLister lists the Nodes, Pods, EndpointSlices, Ingresses, Gateways
+// and HTTPRoutes a source discovers besides its Services.
+type ObjectLister interface {
+	ListObjects(ctx context.Context) ([]domain.KubernetesObjectSnapshot, error)
+}
+
+// SkippedObjectsError is returned with an otherwise complete object snapshot
+// and lists the objects left out of it because they could not be converted,
+// such as a Pod with an unparsable IP.
+type SkippedObjectsError struct {
+	Errs []error
+}
+
+func (e *SkippedObjectsError) Error() string {
+	return fmt.Sprintf("skipped %d kubernetes objects: %v", len(e.Errs), errors.Join(e.Errs...))
+}
+
+func (e *SkippedObjectsError) Unwrap() []error {
+	return e.Errs
+}
+
+// skipObject records why an object was left out of the snapshot.
+func skipObject(skipped *[]error, kind, namespace, name string, err error) {
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+	*skipped = append(*skipped, fmt.Errorf("%s %s: %w", kind, name, err))
+}
+
+// ListObjects returns a complete snapshot of the source's object kinds:
+// every Node, and the Pods, EndpointSlices, Ingresses, Gateways and
+// HTTPRoutes in the watched namespaces. Pods are limited to the source's pod
+// selector, and Pods that have finished or have no IP yet are left out. A
+// source without object kinds gets an empty snapshot. Objects that cannot be
+// converted are left out too, and reported in a SkippedObjectsError returned
+// with the snapshot.
+func (c *Client) ListObjects(ctx context.Context) ([]domain.KubernetesObjectSnapshot, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	defer cancel()
+
+	kinds := c.config.Source.ObjectKinds
+	objects := make([]domain.KubernetesObjectSnapshot, 0)
+	var skipped []error
+	if slices.Contains(kinds, domain.KubernetesObjectNode) {
+		list, err := c.client.CoreV1().Nodes().List(requestCtx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list nodes: %w", err)
+		}
+		for i := range list.Items {
+			snapshot, err := nodeToSnapshot(&list.Items[i])
+			if err != nil {
+				skipObject(&skipped, domain.KubernetesObjectNode, "", list.Items[i].Name, err)
+				continue
+			}
+			objects = append(objects, snapshot)
+		}
+	}
+	for _, namespace := range watchedNamespaces(c.config.Source.Namespaces) {
+		if slices.Contains(kinds, domain.KubernetesObjectPod) {
+			list, err := c.client.CoreV1().Pods(namespace).List(requestCtx, metav1.ListOptions{LabelSelector: c.config.Source.PodSelector})
+			if err != nil {
+				return nil, fmt.Errorf("list pods in namespace %q: %w", displayNamespace(namespace), err)
+			}
+			for i := range list.Items {
+				pod := &list.Items[i]
+				if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+					continue
+				}
+				snapshot, err := podToSnapshot(pod)
+				if err != nil {
+					skipObject(&skipped, domain.KubernetesObjectPod, pod.Namespace, pod.Name, err)
+					continue
+				}
+				if len(snapshot.Addresses) > 0 {
+					objects = append(objects, snapshot)
+				}
+			}
+		}
+		if slices.Contains(kinds, domain.KubernetesObjectEndpointSlice) {
+			list, err := c.client.DiscoveryV1().EndpointSlices(namespace).List(requestCtx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("list endpointslices in namespace %q: %w", displayNamespace(namespace), err)
+			}
+			for i := range list.Items {
+				snapshot, err := endpointSliceToSnapshot(&list.Items[i])
+				if err != nil {
+					skipObject(&skipped, domain.KubernetesObjectEndpointSlice, list.Items[i].Namespace, list.Items[i].Name, err)
+					continue
+				}
+				objects = append(objects, snapshot)
+			}
+		}
+		if slices.Contains(kinds, domain.KubernetesObjectIngress) {
+			list, err := c.client.NetworkingV1().Ingresses(namespace).List(requestCtx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("list ingresses in namespace %q: %w", displayNamespace(namespace), err)
+			}
+			for i := range list.Items {
+				snapshot, err := ingressToSnapshot(&list.Items[i])
+				if err != nil {
+					skipObject(&skipped, domain.KubernetesObjectIngress, list.Items[i].Namespace, list.Items[i].Name, err)
+					continue
+				}
+				objects = append(objects, snapshot)
+			}
+		}
+	}
+	gatewayObjects, err := c.listGatewayObjects(requestCtx, kinds, &skipped)
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, gatewayObjects...)
+	sort.Slice(objects, func(i, j int) bool {
+		left, right := objects[i], objects[j]
+		if left.Kind != right.Kind {
+			return left.Kind < right.Kind
+		}
+		if left.Namespace != right.Namespace {
+			return left.Namespace < right.Namespace
+		}
+		if left.Name != right.Name {
+			return left.Name < right.Name
+		}
+		return left.UID < right.UID
+	})
+	if len(skipped) > 0 {
+		return objects, &SkippedObjectsError{Errs: skipped}
+	}
+	return objects, nil
+}
+
+func nodeToSnapshot(node *corev1.Node) (domain.KubernetesObjectSnapshot, error) {
+	snapshot := objectSnapshot(domain.KubernetesObjectNode, node.ObjectMeta)
+	if snapshot.UID == "" {
+		return snapshot, fmt.Errorf("node %s has no UID", node.Name)
+	}
+	for _, address := range node.Status.Addresses {
+		kind := ""
+		switch address.Type {
+		case corev1.NodeInternalIP:
+			kind = "internal_ip"
+		case corev1.NodeExternalIP:
+			kind = "external_ip"
+		default:
+			continue
+		}
+		if err := appendObjectAddress(&snapshot, kind, address.Address, node.Name); err != nil {
+			return snapshot, fmt.Errorf("node %s has invalid address %q: %w", node.Name, address.Address, err)
+		}
+	}
+	return snapshot, nil
+}
+
+func podToSnapshot(pod *corev1.Pod) (domain.KubernetesObjectSnapshot, error) {
+	snapshot := objectSnapshot(domain.KubernetesObjectPod, pod.ObjectMeta)
+	if snapshot.UID == "" {
+		return snapshot, fmt.Errorf("pod %s/%s has no UID", pod.Namespace, pod.Name)
+	}
+	snapshot.HostNetwork = pod.Spec.HostNetwork
+	podIPs := make([]string, 0, len(pod.Status.PodIPs))
+	for _, podIP := range pod.Status.PodIPs {
+		podIPs = append(podIPs, podIP.IP)
+	}
+	if len(podIPs) == 0 && pod.Status.PodIP != "" {
+		podIPs = append(podIPs, pod.Status.PodIP)
+	}
+	for _, raw := range podIPs {
+		if err := appendObjectAddress(&snapshot, "pod_ip", raw, pod.Spec.NodeName); err != nil {
+			return snapshot, fmt.Errorf("pod %s/%s has invalid pod IP %q: %w", pod.Namespace, pod.Name, raw, err)
+		}
+	}
+	return snapshot, nil
+}
+
+// endpointSliceToSnapshot keeps the addresses of IPv4 and IPv6 slices;
+// FQDN endpoints are names, not addresses.
+func endpointSliceToSnapshot(slice *discoveryv1.EndpointSlice) (domain.KubernetesObjectSnapshot, error) {
+	snapshot := objectSnapshot(domain.KubernetesObjectEndpointSlice, slice.ObjectMeta)
+	if snapshot.UID == "" {
+		return snapshot, fmt.Errorf("endpointslice %s/%s has no UID", slice.Namespace, slice.Name)
+	}
+	if slice.AddressType == discoveryv1.AddressTypeFQDN {
+		return snapshot, nil
+	}
+	for _, endpoint := range slice.Endpoints {
+		nodeName := ""
+		if endpoint.NodeName != nil {
+			nodeName = *endpoint.NodeName
+		}
+		for _, raw := range endpoint.Addresses {
+			if err := appendObjectAddress(&snapshot, "endpoint", raw, nodeName); err != nil {
+				return snapshot, fmt.Errorf("endpointslice %s/%s has invalid address %q: %w", slice.Namespace, slice.Name, raw, err)
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// ingressToSnapshot records the hosts of the rules and TLS entries and the
+// load-balancer addresses and hostnames in the status.
+func ingressToSnapshot(ingress *networkingv1.Ingress) (domain.KubernetesObjectSnapshot, error) {
+	snapshot := objectSnapshot(domain.KubernetesObjectIngress, ingress.ObjectMeta)
+	if snapshot.UID == "" {
+		return snapshot, fmt.Errorf("ingress %s/%s has no UID", ingress.Namespace, ingress.Name)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		appendObjectHostname(&snapshot, "host", rule.Host)
+	}
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			appendObjectHostname(&snapshot, "host", host)
+		}
+	}
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			if err := appendObjectAddress(&snapshot, "load_balancer", lb.IP, ""); err != nil {
+				return snapshot, fmt.Errorf("ingress %s/%s has invalid load balancer IP %q: %w", ingress.Namespace, ingress.Name, lb.IP, err)
+			}
+		}
+		appendObjectHostname(&snapshot, "load_balancer", lb.Hostname)
+	}
+	return snapshot, nil
+}
+
+func objectSnapshot(kind string, meta metav1.ObjectMeta) domain.KubernetesObjectSnapshot {
+	return domain.KubernetesObjectSnapshot{
+		UID:             string(meta.UID),
+		Kind:            kind,
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		ResourceVersion: meta.ResourceVersion,
+		Addresses:       make([]domain.KubernetesObjectAddress, 0),
+		Hostnames:       make([]domain.KubernetesObjectHostname, 0),
+	}
+}
+
+func appendObjectAddress(snapshot *domain.KubernetesObjectSnapshot, kind, raw, nodeName string) error {
+	address, err := netip.ParseAddr(raw)
+	if err != nil {
+		return err
+	}
+	address = address.Unmap()
+	for _, existing := range snapshot.Addresses {
+		if existing.Kind == kind && existing.Address == address {
+			return nil
+		}
+	}
+	snapshot.Addresses = append(snapshot.Addresses, domain.KubernetesObjectAddress{Kind: kind, Address: address, NodeName: nodeName})
+	return nil
+}
+
+// appendObjectHostname lower-cases the hostname and drops a trailing dot.
+// Empty hostnames and repeats are ignored.
+func appendObjectHostname(snapshot *domain.KubernetesObjectSnapshot, kind, hostname string) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+	if hostname == "" {
+		return
+	}
+	for _, existing := range snapshot.Hostnames {
+		if existing.Kind == kind && existing.Hostname == hostname {
+			return
+		}
+	}
+	snapshot.Hostnames = append(snapshot.Hostnames, domain.KubernetesObjectHostname{Kind: kind, Hostname: hostname})
+}
+
+var _ ObjectLister = (*Client)(nil)
@@ -0,0 +1,151 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func objectTestConfig(selector string, kinds ...string) Config {
+	cfg := validTestConfig("apps")
+	cfg.Source.ObjectKinds = kinds
+	cfg.Source.PodSelector = selector
+	return cfg
+}
+
+func TestClientListsNodesPodsAndEndpointSlices(t *testing.T) {
+	nodeName := "worker-1"
+	clientset := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1", UID: types.UID("uid-node")},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.5"},
+				{Type: corev1.NodeExternalIP, Address: "192.0.2.5"},
+				{Type: corev1.NodeHostName, Address: "worker-1"},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "apps", UID: types.UID("uid-exporter"), Labels: map[string]string{"app": "edge"}},
+			Spec:       corev1.PodSpec{NodeName: "worker-1", HostNetwork: true},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.5", PodIPs: []corev1.PodIP{{IP: "10.0.0.5"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "apps", UID: types.UID("uid-pending"), Labels: map[string]string{"app": "edge"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "apps", UID: types.UID("uid-done"), Labels: map[string]string{"app": "edge"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded, PodIP: "10.244.0.9"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "apps", UID: types.UID("uid-other"), Labels: map[string]string{"app": "billing"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.244.0.10"},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Name: "orders-abc", Namespace: "apps", UID: types.UID("uid-slice")},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.244.1.7", "10.244.1.7"}, NodeName: &nodeName}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Name: "external-abc", Namespace: "apps", UID: types.UID("uid-fqdn")},
+			AddressType: discoveryv1.AddressTypeFQDN,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"upstream.example"}}},
+		},
+	)
+	client := NewClientWithInterface(objectTestConfig("app=edge", domain.KubernetesObjectNode, domain.KubernetesObjectPod, domain.KubernetesObjectEndpointSlice), clientset)
+
+	objects, err := client.ListObjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	if len(objects) != 4 {
+		t.Fatalf("expected a node, a pod and two slices, got %+v", objects)
+	}
+	slice, node, pod := objects[1], objects[2], objects[3]
+	if objects[0].UID != "uid-fqdn" || len(objects[0].Addresses) != 0 {
+		t.Fatalf("FQDN slice should have no addresses: %+v", objects[0])
+	}
+	if slice.Kind != domain.KubernetesObjectEndpointSlice || len(slice.Addresses) != 1 || slice.Addresses[0].NodeName != "worker-1" {
+		t.Fatalf("unexpected endpoint slice: %+v", slice)
+	}
+	if node.Kind != domain.KubernetesObjectNode || len(node.Addresses) != 2 || node.Addresses[1].Kind != "external_ip" || node.Addresses[0].NodeName != "worker-1" {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+	if pod.UID != "uid-exporter" || !pod.HostNetwork || len(pod.Addresses) != 1 || pod.Addresses[0].NodeName != "worker-1" {
+		t.Fatalf("unexpected pod: %+v", pod)
+	}
+}
+
+func TestClientListsNoObjectsWithoutKinds(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", UID: types.UID("uid-node")}})
+	client := NewClientWithInterface(objectTestConfig(""), clientset)
+	objects, err := client.ListObjects(context.Background())
+	if err != nil || objects == nil || len(objects) != 0 {
+		t.Fatalf("expected an empty snapshot, got %+v, %v", objects, err)
+	}
+	if len(clientset.Actions()) != 0 {
+		t.Fatalf("expected no API calls, got %v", clientset.Actions())
+	}
+}
+
+func TestClientSkipsMalformedObjectAddress(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "bad", Namespace: "apps", UID: types.UID("uid-bad")},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "not-an-ip"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "good", Namespace: "apps", UID: types.UID("uid-good")},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.9"},
+		},
+	)
+	client := NewClientWithInterface(objectTestConfig("", domain.KubernetesObjectPod), clientset)
+	objects, err := client.ListObjects(context.Background())
+	var skipped *SkippedObjectsError
+	if !errors.As(err, &skipped) || len(skipped.Errs) != 1 || !strings.Contains(skipped.Errs[0].Error(), "pod apps/bad") {
+		t.Fatalf("expected the malformed pod to be reported as skipped, got %v", err)
+	}
+	if len(objects) != 1 || objects[0].UID != "uid-good" {
+		t.Fatalf("malformed pod dropped the rest of the snapshot: %+v", objects)
+	}
+}
+
+func TestClientListsIngressHostnamesAndStatusAddresses(t *testing.T) {
+	clientset := fake.NewClientset(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ipam", Namespace: "apps", UID: types.UID("uid-ingress")},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{Host: "IPAM.example.test."}, {}},
+			TLS:   []networkingv1.IngressTLS{{Hosts: []string{"ipam.example.test", "www.example.test"}}},
+		},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{Ingress: []networkingv1.IngressLoadBalancerIngress{
+			{IP: "192.0.2.10"}, {Hostname: "lb-123.elb.example"},
+		}}},
+	})
+	client := NewClientWithInterface(objectTestConfig("", domain.KubernetesObjectIngress), clientset)
+
+	objects, err := client.ListObjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("expected one ingress, got %+v", objects)
+	}
+	ingress := objects[0]
+	if len(ingress.Addresses) != 1 || ingress.Addresses[0].Kind != "load_balancer" || ingress.Addresses[0].Address.String() != "192.0.2.10" {
+		t.Fatalf("unexpected ingress addresses: %+v", ingress.Addresses)
+	}
+	want := []domain.KubernetesObjectHostname{{Kind: "host", Hostname: "ipam.example.test"}, {Kind: "host", Hostname: "www.example.test"}, {Kind: "load_balancer", Hostname: "lb-123.elb.example"}}
+	if !slices.Equal(ingress.Hostnames, want) {
+		t.Fatalf("unexpected ingress hostnames: %+v", ingress.Hostnames)
+	}
+}
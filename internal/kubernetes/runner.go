@@ -5,69 +5,311 @@ import (
 	"errors"
 	"log/slog"
 	"math/rand/v2"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+const tracerName = "github.com/Flarenzy/simple-k8s-app/internal/kubernetes"
+
+// changeBatchDelay collects the watched changes that arrive together, such
+// as a Deployment rollout, into one incremental publication.
+const changeBatchDelay = time.Second
+
+// Observer receives the duration and outcome of every discovery cycle.
+type Observer interface {
+	ObserveReconcile(source string, duration time.Duration, result domain.KubernetesReconcileResult, err error)
+}
+
 type Runner struct {
-	config  Config
-	lister  ServiceLister
-	service domain.KubernetesDiscoveryService
-	logger  *slog.Logger
-	now     func() time.Time
+	config   Config
+	lister   ServiceLister
+	service  domain.KubernetesDiscoveryService
+	logger   *slog.Logger
+	observer Observer
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]ServiceChange
+	// resync asks for a full cycle instead of an incremental one, after a
+	// watched Service could not be converted or the watch caches synced.
+	resync  bool
+	changed chan struct{}
 }
 
 func NewRunner(config Config, lister ServiceLister, service domain.KubernetesDiscoveryService, logger *slog.Logger) *Runner {
-	return &Runner{config: config, lister: lister, service: service, logger: logger, now: time.Now}
+	return &Runner{
+		config: config, lister: lister, service: service, logger: logger, now: time.Now,
+		pending: make(map[string]ServiceChange), changed: make(chan struct{}, 1),
+	}
 }
 
+// WithObserver reports each cycle to observer and returns the runner.
+func (r *Runner) WithObserver(observer Observer) *Runner {
+	r.observer = observer
+	return r
+}
+
+// Run publishes a complete snapshot every ReconcileInterval. When the lister
+// is a ServiceWatcher, changes in between are published incrementally, and
+// the full cycle remains as a safety net for anything the watch missed.
 func (r *Runner) Run(ctx context.Context) {
+	if watcher, ok := r.lister.(ServiceWatcher); ok {
+		go r.watch(ctx, watcher)
+	}
 	failureDelay := min(r.config.ReconcileInterval, 30*time.Second)
 	for {
-		err := r.ReconcileOnce(ctx)
+		_, err := r.ReconcileOnce(ctx)
+		failed := err != nil && !errors.Is(err, domain.ErrDiscoveryBusy)
 		delay := r.config.ReconcileInterval
-		if err != nil && !errors.Is(err, domain.ErrDiscoveryBusy) {
+		if failed {
 			delay = jitter(failureDelay)
 			failureDelay = min(failureDelay*2, r.config.ReconcileInterval)
 		} else {
 			failureDelay = min(r.config.ReconcileInterval, 30*time.Second)
 		}
+		if !r.waitForResync(ctx, delay, failed) {
+			return
+		}
+	}
+}
+
+func (r *Runner) watch(ctx context.Context, watcher ServiceWatcher) {
+	if err := watcher.WatchServices(ctx, r.enqueue); err != nil {
+		if ctx.Err() == nil {
+			r.logger.WarnContext(ctx, "kubernetes service watch unavailable; using periodic lists", "source", r.config.Source.Key, "err", err)
+		}
+		return
+	}
+	r.logger.InfoContext(ctx, "kubernetes service watch synced", "source", r.config.Source.Key)
+	// Changes made before the caches synced were not reported.
+	r.mu.Lock()
+	r.resync = true
+	r.mu.Unlock()
+	r.signal()
+}
 
-		timer := time.NewTimer(delay)
+func (r *Runner) enqueue(change ServiceChange) {
+	r.mu.Lock()
+	if change.Err != nil {
+		r.resync = true
+	} else {
+		r.pending[change.UID] = change
+	}
+	r.mu.Unlock()
+	r.signal()
+}
+
+func (r *Runner) signal() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// waitForResync publishes watched changes until the next full cycle is due
+// after delay, and returns false once ctx is cancelled. While the last full
+// cycle is failing, changes are left to the next one.
+func (r *Runner) waitForResync(ctx context.Context, delay time.Duration, failed bool) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var batch <-chan time.Time
+	for {
 		select {
 		case <-ctx.Done():
-			timer.Stop()
-			return
+			return false
 		case <-timer.C:
+			return true
+		case <-r.changed:
+			if batch == nil && !failed {
+				batch = time.After(changeBatchDelay)
+			}
+		case <-batch:
+			batch = nil
+			err := r.ApplyPendingChanges(ctx)
+			switch {
+			case errors.Is(err, errResyncRequested):
+				return true
+			case errors.Is(err, domain.ErrDiscoveryBusy):
+				batch = time.After(changeBatchDelay)
+			case err != nil:
+				// The full cycle republishes everything the batch held.
+				return true
+			}
+		}
+	}
+}
+
+var errResyncRequested = errors.New("full resync requested")
+
+// takePending removes and returns the queued changes. A full cycle discards
+// them, since the snapshot it lists already includes them.
+func (r *Runner) takePending() (domain.KubernetesServiceChanges, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resync := r.resync
+	r.resync = false
+	var changes domain.KubernetesServiceChanges
+	for uid, change := range r.pending {
+		if change.Snapshot == nil {
+			changes.DeletedUIDs = append(changes.DeletedUIDs, uid)
+		} else {
+			changes.Upserted = append(changes.Upserted, *change.Snapshot)
+		}
+	}
+	clear(r.pending)
+	sort.Strings(changes.DeletedUIDs)
+	sort.Slice(changes.Upserted, func(i, j int) bool { return changes.Upserted[i].UID < changes.Upserted[j].UID })
+	return changes, resync
+}
+
+func (r *Runner) requeue(changes domain.KubernetesServiceChanges) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Changes that arrived since take precedence.
+	for _, uid := range changes.DeletedUIDs {
+		if _, ok := r.pending[uid]; !ok {
+			r.pending[uid] = ServiceChange{UID: uid}
+		}
+	}
+	for i := range changes.Upserted {
+		snapshot := changes.Upserted[i]
+		if _, ok := r.pending[snapshot.UID]; !ok {
+			r.pending[snapshot.UID] = ServiceChange{UID: snapshot.UID, Snapshot: &snapshot}
 		}
 	}
 }
 
-func (r *Runner) ReconcileOnce(ctx context.Context) error {
+// ApplyPendingChanges publishes the watched changes queued since the last
+// publication as one incremental update. It returns errResyncRequested,
+// without publishing, when a full cycle is needed instead.
+func (r *Runner) ApplyPendingChanges(ctx context.Context) (err error) {
+	changes, resync := r.takePending()
+	if resync {
+		return errResyncRequested
+	}
+	if len(changes.Upserted) == 0 && len(changes.DeletedUIDs) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "kubernetes.ApplyPendingChanges")
+	span.SetAttributes(attribute.String("ipam.kubernetes.source", r.config.Source.Key))
 	startedAt := r.now().UTC()
+	var result domain.KubernetesReconcileResult
+	defer func() {
+		if r.observer != nil {
+			r.observer.ObserveReconcile(r.config.Source.Key, r.now().UTC().Sub(startedAt), result, err)
+		}
+		if err != nil && !errors.Is(err, domain.ErrDiscoveryBusy) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	result, err = r.service.ApplyChanges(ctx, r.config.Source, changes, startedAt)
+	if err != nil {
+		if errors.Is(err, domain.ErrDiscoveryBusy) {
+			r.requeue(changes)
+			return err
+		}
+		r.recordFailure(ctx, startedAt, err)
+		r.logger.WarnContext(ctx, "kubernetes service changes failed", "source", r.config.Source.Key, "err", err)
+		return err
+	}
+	r.logger.DebugContext(ctx, "kubernetes service changes published",
+		"source", r.config.Source.Key, "upserted", len(changes.Upserted), "deleted", len(changes.DeletedUIDs))
+	return nil
+}
+
+// ReconcileOnce runs one discovery cycle as a root span, so the Kubernetes
+// API calls and the reconcile transaction appear in one trace. It returns
+// ErrDiscoveryBusy when another cycle of the source holds its lock.
+func (r *Runner) ReconcileOnce(ctx context.Context) (result domain.KubernetesReconcileResult, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "kubernetes.ReconcileOnce")
+	span.SetAttributes(attribute.String("ipam.kubernetes.source", r.config.Source.Key))
+	startedAt := r.now().UTC()
+	defer func() {
+		if r.observer != nil {
+			r.observer.ObserveReconcile(r.config.Source.Key, r.now().UTC().Sub(startedAt), result, err)
+		}
+		if err != nil && !errors.Is(err, domain.ErrDiscoveryBusy) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	r.takePending()
 	services, err := r.lister.ListServices(ctx)
 	if err != nil {
 		r.recordFailure(ctx, startedAt, err)
 		r.logger.WarnContext(ctx, "kubernetes service discovery failed", "source", r.config.Source.Key, "err", err)
-		return err
+		return result, err
+	}
+	// Objects are optional: a failed listing keeps the last published
+	// objects and is recorded apart, and the Services are published anyway.
+	var objects []domain.KubernetesObjectSnapshot
+	var objectErr error
+	objectLister, listsObjects := r.lister.(ObjectLister)
+	if listsObjects {
+		objects, objectErr = objectLister.ListObjects(ctx)
+		var skipped *SkippedObjectsError
+		if errors.As(objectErr, &skipped) {
+			for _, skipErr := range skipped.Errs {
+				r.logger.WarnContext(ctx, "kubernetes object skipped", "source", r.config.Source.Key, "err", skipErr)
+			}
+			objectErr = nil
+		}
+		if objectErr != nil {
+			r.logger.WarnContext(ctx, "kubernetes object discovery failed", "source", r.config.Source.Key, "err", objectErr)
+		}
 	}
 	observedAt := r.now().UTC()
-	result, err := r.service.Reconcile(ctx, r.config.Source, services, observedAt)
-	if err != nil {
-		if errors.Is(err, domain.ErrDiscoveryBusy) {
-			r.logger.DebugContext(ctx, "kubernetes service discovery skipped; source lock held", "source", r.config.Source.Key)
-			return err
+	// Objects go first: the Services reconcile sends the change
+	// notification for both.
+	var objectResult domain.KubernetesObjectReconcileResult
+	if listsObjects && objectErr == nil {
+		if objectResult, err = r.service.ReconcileObjects(ctx, r.config.Source, objects, observedAt); err != nil {
+			return result, r.reconcileFailed(ctx, startedAt, err)
 		}
-		r.recordFailure(ctx, startedAt, err)
-		r.logger.WarnContext(ctx, "kubernetes service reconciliation failed", "source", r.config.Source.Key, "err", err)
-		return err
+	}
+	if result, err = r.service.Reconcile(ctx, r.config.Source, services, startedAt, observedAt); err != nil {
+		return result, r.reconcileFailed(ctx, startedAt, err)
+	}
+	if objectErr != nil {
+		r.recordObjectFailure(ctx, objectErr)
 	}
 	r.logger.InfoContext(ctx, "kubernetes service discovery reconciled",
 		"source", r.config.Source.Key, "services", result.Services, "matched", result.Matched,
 		"unmatched", result.Unmatched, "ambiguous", result.Ambiguous, "no_usable_ip", result.NoUsableIP,
+		"objects", objectResult.Objects, "objects_unmatched", objectResult.Unmatched,
 	)
-	return nil
+	return result, nil
+}
+
+func (r *Runner) reconcileFailed(ctx context.Context, startedAt time.Time, err error) error {
+	if errors.Is(err, domain.ErrDiscoveryBusy) {
+		r.logger.DebugContext(ctx, "kubernetes service discovery skipped; source lock held", "source", r.config.Source.Key)
+		return err
+	}
+	r.recordFailure(ctx, startedAt, err)
+	r.logger.WarnContext(ctx, "kubernetes service reconciliation failed", "source", r.config.Source.Key, "err", err)
+	return err
+}
+
+// recordObjectFailure follows the Services reconcile, which creates the row
+// of a configured source on its first cycle.
+func (r *Runner) recordObjectFailure(ctx context.Context, discoveryErr error) {
+	recordCtx, cancel := context.WithTimeout(ctx, r.config.RequestTimeout)
+	defer cancel()
+	if err := r.service.RecordObjectFailure(recordCtx, r.config.Source, discoveryErr); err != nil {
+		r.logger.ErrorContext(ctx, "recording kubernetes object discovery failure", "source", r.config.Source.Key, "err", err)
+	}
 }
 
 func (r *Runner) recordFailure(ctx context.Context, attemptedAt time.Time, discoveryErr error) {
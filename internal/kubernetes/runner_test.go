@@ -5,10 +5,16 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type stubLister struct {
@@ -20,22 +26,83 @@ func (s stubLister) ListServices(context.Context) ([]domain.KubernetesServiceSna
 	return s.services, s.err
 }
 
+type stubObjectLister struct {
+	stubLister
+	objects   []domain.KubernetesObjectSnapshot
+	objectErr error
+}
+
+func (s stubObjectLister) ListObjects(context.Context) ([]domain.KubernetesObjectSnapshot, error) {
+	return s.objects, s.objectErr
+}
+
 type stubDiscoveryService struct {
+	mu             sync.Mutex
 	reconcileCalls int
 	failureCalls   int
+	objectFailures []string
+	objectCalls    int
+	applied        []domain.KubernetesServiceChanges
+	applyErr       error
+	statuses       []domain.KubernetesSourceStatus
+	requested      []string
+	onRequest      func(key, requestID string)
+	cycleRequests  []string
 }
 
-func (s *stubDiscoveryService) Reconcile(context.Context, domain.KubernetesSourceConfig, []domain.KubernetesServiceSnapshot, time.Time) (domain.KubernetesReconcileResult, error) {
+func (s *stubDiscoveryService) Reconcile(ctx context.Context, _ domain.KubernetesSourceConfig, services []domain.KubernetesServiceSnapshot, _, _ time.Time) (domain.KubernetesReconcileResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.reconcileCalls++
+	if requestID := domain.KubernetesCycleRequestID(ctx); requestID != "" {
+		s.cycleRequests = append(s.cycleRequests, requestID)
+	}
+	return domain.KubernetesReconcileResult{Services: len(services)}, nil
+}
+
+func (s *stubDiscoveryService) ApplyChanges(_ context.Context, _ domain.KubernetesSourceConfig, changes domain.KubernetesServiceChanges, _ time.Time) (domain.KubernetesReconcileResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.applyErr != nil {
+		return domain.KubernetesReconcileResult{}, s.applyErr
+	}
+	s.applied = append(s.applied, changes)
 	return domain.KubernetesReconcileResult{}, nil
 }
 
 func (s *stubDiscoveryService) RecordFailure(context.Context, domain.KubernetesSourceConfig, time.Time, error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.failureCalls++
 	return nil
 }
 
+func (s *stubDiscoveryService) RecordObjectFailure(_ context.Context, _ domain.KubernetesSourceConfig, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objectFailures = append(s.objectFailures, err.Error())
+	return nil
+}
+
+func (s *stubDiscoveryService) RequestReconcile(_ context.Context, key, requestID string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requested = append(s.requested, key)
+	if s.onRequest != nil {
+		s.onRequest(key, requestID)
+	}
+	return nil
+}
+
 func (s *stubDiscoveryService) ListSourceStatuses(context.Context) ([]domain.KubernetesSourceStatus, error) {
+	return s.statuses, nil
+}
+
+func (s *stubDiscoveryService) ListRuns(context.Context, string, int32) ([]domain.KubernetesDiscoveryRun, error) {
+	return nil, nil
+}
+
+func (s *stubDiscoveryService) ListEvents(context.Context, string, *int64, int32) ([]domain.KubernetesDiscoveryEvent, error) {
 	return nil, nil
 }
 
@@ -43,10 +110,21 @@ func (s *stubDiscoveryService) ListServicesBySubnetID(context.Context, int64) ([
 	return nil, nil
 }
 
+func (s *stubDiscoveryService) ReconcileObjects(_ context.Context, _ domain.KubernetesSourceConfig, objects []domain.KubernetesObjectSnapshot, _ time.Time) (domain.KubernetesObjectReconcileResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objectCalls++
+	return domain.KubernetesObjectReconcileResult{Objects: len(objects)}, nil
+}
+
+func (s *stubDiscoveryService) ListObjectsBySubnetID(context.Context, int64) ([]domain.KubernetesObjectObservation, error) {
+	return nil, nil
+}
+
 func TestRunnerDoesNotPublishPartialList(t *testing.T) {
 	service := &stubDiscoveryService{}
 	runner := NewRunner(validTestConfig("one", "two"), stubLister{err: errors.New("namespace two failed")}, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
-	if err := runner.ReconcileOnce(context.Background()); err == nil {
+	if _, err := runner.ReconcileOnce(context.Background()); err == nil {
 		t.Fatal("expected list failure")
 	}
 	if service.reconcileCalls != 0 || service.failureCalls != 1 {
@@ -57,10 +135,140 @@ func TestRunnerDoesNotPublishPartialList(t *testing.T) {
 func TestRunnerPublishesCompleteEmptySnapshot(t *testing.T) {
 	service := &stubDiscoveryService{}
 	runner := NewRunner(validTestConfig("apps"), stubLister{services: []domain.KubernetesServiceSnapshot{}}, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
-	if err := runner.ReconcileOnce(context.Background()); err != nil {
+	if _, err := runner.ReconcileOnce(context.Background()); err != nil {
 		t.Fatalf("ReconcileOnce: %v", err)
 	}
 	if service.reconcileCalls != 1 || service.failureCalls != 0 {
 		t.Fatalf("unexpected calls: reconcile=%d failures=%d", service.reconcileCalls, service.failureCalls)
 	}
 }
+
+func TestRunnerTracesFailedCycle(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	runner := NewRunner(validTestConfig("apps"), stubLister{err: errors.New("forbidden")}, &stubDiscoveryService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, _ = runner.ReconcileOnce(context.Background())
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "kubernetes.ReconcileOnce" || spans[0].Status.Code != codes.Error {
+		t.Fatalf("expected one failed reconcile span, got %+v", spans)
+	}
+}
+
+type recordingObserver struct {
+	sources []string
+	errs    []error
+}
+
+func (o *recordingObserver) ObserveReconcile(source string, _ time.Duration, _ domain.KubernetesReconcileResult, err error) {
+	o.sources = append(o.sources, source)
+	o.errs = append(o.errs, err)
+}
+
+func TestRunnerReportsCyclesToObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config := validTestConfig("apps")
+	_, _ = NewRunner(config, stubLister{services: []domain.KubernetesServiceSnapshot{}}, &stubDiscoveryService{}, logger).WithObserver(observer).ReconcileOnce(context.Background())
+	_, _ = NewRunner(config, stubLister{err: errors.New("forbidden")}, &stubDiscoveryService{}, logger).WithObserver(observer).ReconcileOnce(context.Background())
+
+	if len(observer.sources) != 2 || observer.sources[0] != config.Source.Key || observer.errs[0] != nil || observer.errs[1] == nil {
+		t.Fatalf("unexpected observations: sources=%v errs=%v", observer.sources, observer.errs)
+	}
+}
+
+func TestRunnerAppliesPendingChangesIncrementally(t *testing.T) {
+	service := &stubDiscoveryService{}
+	runner := NewRunner(validTestConfig("apps"), stubLister{services: []domain.KubernetesServiceSnapshot{}}, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	runner.enqueue(ServiceChange{UID: "b", Snapshot: &domain.KubernetesServiceSnapshot{UID: "b", Name: "old"}})
+	runner.enqueue(ServiceChange{UID: "b", Snapshot: &domain.KubernetesServiceSnapshot{UID: "b", Name: "new"}})
+	runner.enqueue(ServiceChange{UID: "a", Snapshot: &domain.KubernetesServiceSnapshot{UID: "a"}})
+	runner.enqueue(ServiceChange{UID: "gone"})
+
+	if err := runner.ApplyPendingChanges(context.Background()); err != nil {
+		t.Fatalf("ApplyPendingChanges: %v", err)
+	}
+	if len(service.applied) != 1 {
+		t.Fatalf("expected one incremental publication, got %d", len(service.applied))
+	}
+	changes := service.applied[0]
+	if len(changes.Upserted) != 2 || changes.Upserted[0].UID != "a" || changes.Upserted[1].Name != "new" {
+		t.Fatalf("unexpected upserts: %+v", changes.Upserted)
+	}
+	if len(changes.DeletedUIDs) != 1 || changes.DeletedUIDs[0] != "gone" {
+		t.Fatalf("unexpected deletions: %v", changes.DeletedUIDs)
+	}
+	if err := runner.ApplyPendingChanges(context.Background()); err != nil || len(service.applied) != 1 {
+		t.Fatalf("empty batch was published: %d, %v", len(service.applied), err)
+	}
+}
+
+func TestRunnerRequeuesChangesWhileBusy(t *testing.T) {
+	service := &stubDiscoveryService{applyErr: domain.ErrDiscoveryBusy}
+	runner := NewRunner(validTestConfig("apps"), stubLister{services: []domain.KubernetesServiceSnapshot{}}, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	runner.enqueue(ServiceChange{UID: "a", Snapshot: &domain.KubernetesServiceSnapshot{UID: "a", Name: "old"}})
+	if err := runner.ApplyPendingChanges(context.Background()); !errors.Is(err, domain.ErrDiscoveryBusy) {
+		t.Fatalf("expected busy, got %v", err)
+	}
+	runner.enqueue(ServiceChange{UID: "a", Snapshot: &domain.KubernetesServiceSnapshot{UID: "a", Name: "new"}})
+	service.applyErr = nil
+	if err := runner.ApplyPendingChanges(context.Background()); err != nil {
+		t.Fatalf("ApplyPendingChanges: %v", err)
+	}
+	if len(service.applied) != 1 || service.applied[0].Upserted[0].Name != "new" || service.failureCalls != 0 {
+		t.Fatalf("requeued change overrode a newer one: %+v failures=%d", service.applied, service.failureCalls)
+	}
+}
+
+func TestRunnerFallsBackToFullResync(t *testing.T) {
+	service := &stubDiscoveryService{}
+	runner := NewRunner(validTestConfig("apps"), stubLister{services: []domain.KubernetesServiceSnapshot{}}, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	runner.enqueue(ServiceChange{UID: "a", Snapshot: &domain.KubernetesServiceSnapshot{UID: "a"}})
+	runner.enqueue(ServiceChange{UID: "b", Err: errors.New("bad port")})
+	if err := runner.ApplyPendingChanges(context.Background()); !errors.Is(err, errResyncRequested) || len(service.applied) != 0 {
+		t.Fatalf("expected a full resync request, got %v after %d publications", err, len(service.applied))
+	}
+
+	runner.enqueue(ServiceChange{UID: "c", Snapshot: &domain.KubernetesServiceSnapshot{UID: "c"}})
+	if _, err := runner.ReconcileOnce(context.Background()); err != nil {
+		t.Fatalf("ReconcileOnce: %v", err)
+	}
+	if err := runner.ApplyPendingChanges(context.Background()); err != nil || len(service.applied) != 0 {
+		t.Fatalf("full cycle left changes queued: %d, %v", len(service.applied), err)
+	}
+}
+
+func TestRunnerReconcilesObjectsBeforeServices(t *testing.T) {
+	service := &stubDiscoveryService{}
+	lister := stubObjectLister{stubLister: stubLister{services: []domain.KubernetesServiceSnapshot{}}, objects: []domain.KubernetesObjectSnapshot{{UID: "node", Kind: domain.KubernetesObjectNode}}}
+	runner := NewRunner(validTestConfig("apps"), lister, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := runner.ReconcileOnce(context.Background()); err != nil {
+		t.Fatalf("ReconcileOnce: %v", err)
+	}
+	if service.objectCalls != 1 || service.reconcileCalls != 1 {
+		t.Fatalf("unexpected calls: objects=%d reconcile=%d", service.objectCalls, service.reconcileCalls)
+	}
+
+	service = &stubDiscoveryService{}
+	lister.objectErr = errors.New("nodes is forbidden")
+	runner = NewRunner(validTestConfig("apps"), lister, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := runner.ReconcileOnce(context.Background()); err != nil {
+		t.Fatalf("object list failure stopped Service discovery: %v", err)
+	}
+	if service.objectCalls != 0 || service.reconcileCalls != 1 || service.failureCalls != 0 || !slices.Equal(service.objectFailures, []string{"nodes is forbidden"}) {
+		t.Fatalf("object failure was not kept apart: objects=%d reconcile=%d failures=%d object failures=%v", service.objectCalls, service.reconcileCalls, service.failureCalls, service.objectFailures)
+	}
+
+	service = &stubDiscoveryService{}
+	lister.objectErr = &SkippedObjectsError{Errs: []error{errors.New("pod apps/bad: invalid IP")}}
+	runner = NewRunner(validTestConfig("apps"), lister, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := runner.ReconcileOnce(context.Background()); err != nil {
+		t.Fatalf("ReconcileOnce with skipped objects: %v", err)
+	}
+	if service.objectCalls != 1 || service.reconcileCalls != 1 || len(service.objectFailures) != 0 {
+		t.Fatalf("skipped objects failed the snapshot: objects=%d reconcile=%d object failures=%v", service.objectCalls, service.reconcileCalls, service.objectFailures)
+	}
+}
@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"sigs.k8s.io/yaml"
+)
+
+// sourcesFile is the KUBERNETES_DISCOVERY_SOURCES_FILE document. Each source
+// takes the settings of the single-source environment variables, lower-cased
+// and without the KUBERNETES_DISCOVERY_ prefix.
+type sourcesFile struct {
+	Sources []sourceFileEntry `json:"sources"`
+}
+
+type sourceFileEntry struct {
+	SourceKey         string   `json:"source_key"`
+	SourceName        string   `json:"source_name"`
+	SiteID            string   `json:"site_id"`
+	AuthMode          string   `json:"auth_mode"`
+	KubeconfigPath    string   `json:"kubeconfig_path"`
+	KubeconfigContext string   `json:"kubeconfig_context"`
+	Namespaces        []string `json:"namespaces"`
+	ObjectKinds       []string `json:"object_kinds"`
+	PodSelector       string   `json:"pod_selector"`
+	AutoRegister      string   `json:"auto_register"`
+	ClusterDomain     string   `json:"cluster_domain"`
+	Interval          string   `json:"interval"`
+	RequestTimeout    string   `json:"request_timeout"`
+	StaleRetention    string   `json:"stale_retention"`
+}
+
+// SourcesFromEnv returns every enabled discovery source. Sources come from
+// the file named by KUBERNETES_DISCOVERY_SOURCES_FILE when it is set, and
+// otherwise from the single-source KUBERNETES_DISCOVERY_* variables.
+func SourcesFromEnv(getenv func(string) string) ([]Config, error) {
+	path := strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_SOURCES_FILE"))
+	if path == "" {
+		cfg, err := ConfigFromEnv(getenv)
+		if err != nil || !cfg.Enabled {
+			return nil, err
+		}
+		return []Config{cfg}, nil
+	}
+	if strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_ENABLED")) != "" || strings.TrimSpace(getenv("KUBERNETES_DISCOVERY_SOURCE_KEY")) != "" {
+		return nil, fmt.Errorf("KUBERNETES_DISCOVERY_SOURCES_FILE cannot be combined with KUBERNETES_DISCOVERY_ENABLED or KUBERNETES_DISCOVERY_SOURCE_KEY")
+	}
+	return LoadSourcesFile(path)
+}
+
+// LoadSourcesFile reads a YAML or JSON list of discovery sources. Source
+// keys must be unique, since each source owns its observations.
+func LoadSourcesFile(path string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read kubernetes discovery sources: %w", err)
+	}
+	var file sourcesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parse kubernetes discovery sources %s: %w", path, err)
+	}
+	configs := make([]Config, 0, len(file.Sources))
+	seen := make(map[string]struct{}, len(file.Sources))
+	for i, entry := range file.Sources {
+		cfg, err := entry.config()
+		if err != nil {
+			return nil, fmt.Errorf("%s: sources[%d]: %w", path, i, err)
+		}
+		if _, ok := seen[cfg.Source.Key]; ok {
+			return nil, fmt.Errorf("%s: sources[%d]: duplicate source_key %q", path, i, cfg.Source.Key)
+		}
+		seen[cfg.Source.Key] = struct{}{}
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+func (e sourceFileEntry) config() (Config, error) {
+	defaults, err := ConfigFromEnv(func(string) string { return "" })
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Enabled:           true,
+		AuthMode:          valueOrDefault(e.AuthMode, defaults.AuthMode),
+		KubeconfigPath:    strings.TrimSpace(e.KubeconfigPath),
+		KubeconfigContext: strings.TrimSpace(e.KubeconfigContext),
+		Source:            defaults.Source,
+	}
+	cfg.Source.Key = strings.TrimSpace(e.SourceKey)
+	cfg.Source.Name = valueOrDefault(e.SourceName, cfg.Source.Key)
+	cfg.Source.ClusterDomain = valueOrDefault(e.ClusterDomain, defaults.Source.ClusterDomain)
+	cfg.Source.Namespaces = parseList(strings.Join(e.Namespaces, ","))
+	cfg.Source.ObjectKinds = parseList(strings.Join(e.ObjectKinds, ","))
+	cfg.Source.PodSelector = strings.TrimSpace(e.PodSelector)
+	cfg.Source.AutoRegister = valueOrDefault(e.AutoRegister, defaults.Source.AutoRegister)
+	if raw := strings.TrimSpace(e.SiteID); raw != "" {
+		if cfg.Source.SiteID, err = uuid.Parse(raw); err != nil {
+			return Config{}, fmt.Errorf("site_id: %w", err)
+		}
+	}
+	durations := []struct {
+		name     string
+		raw      string
+		target   *time.Duration
+		fallback time.Duration
+	}{
+		{"interval", e.Interval, &cfg.ReconcileInterval, defaults.ReconcileInterval},
+		{"request_timeout", e.RequestTimeout, &cfg.RequestTimeout, defaults.RequestTimeout},
+		{"stale_retention", e.StaleRetention, &cfg.Source.StaleRetention, defaults.Source.StaleRetention},
+	}
+	for _, duration := range durations {
+		if *duration.target, err = parseDuration(duration.raw, duration.fallback); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", duration.name, err)
+		}
+	}
+	if err := cfg.validate(func(name string) string { return name }); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSourcesFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSourcesFromEnvLoadsEverySourceWithDefaults(t *testing.T) {
+	path := writeSourcesFile(t, `sources:
+  - source_key: prod-a
+    site_id: 550e8400-e29b-41d4-a716-446655440000
+    auth_mode: kubeconfig
+    kubeconfig_path: /etc/ipam/prod-a.kubeconfig
+    namespaces: ["*"]
+  - source_key: prod-b
+    source_name: Production B
+    site_id: 550e8400-e29b-41d4-a716-446655440001
+    auth_mode: kubeconfig
+    kubeconfig_path: /etc/ipam/kubeconfig
+    kubeconfig_context: prod-b
+    namespaces: [default, apps, default]
+    cluster_domain: corp.local
+    interval: 1m
+    request_timeout: 5s
+    stale_retention: 24h
+    object_kinds: [pod, node, pod]
+    pod_selector: app.kubernetes.io/part-of=edge
+    auto_register: register
+`)
+	sources, err := SourcesFromEnv(func(key string) string {
+		return map[string]string{"KUBERNETES_DISCOVERY_SOURCES_FILE": path}[key]
+	})
+	if err != nil {
+		t.Fatalf("SourcesFromEnv: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("expected two sources, got %+v", sources)
+	}
+	first, second := sources[0], sources[1]
+	if !first.Enabled || first.Source.Name != "prod-a" || first.Source.ClusterDomain != "cluster.local" || first.ReconcileInterval != 5*time.Minute || first.Source.StaleRetention != 7*24*time.Hour {
+		t.Fatalf("defaults not applied: %+v", first)
+	}
+	if second.KubeconfigContext != "prod-b" || len(second.Source.Namespaces) != 2 || second.ReconcileInterval != time.Minute || second.RequestTimeout != 5*time.Second || second.Source.StaleRetention != 24*time.Hour {
+		t.Fatalf("unexpected second source: %+v", second)
+	}
+	if len(first.Source.ObjectKinds) != 0 || len(second.Source.ObjectKinds) != 2 || second.Source.PodSelector != "app.kubernetes.io/part-of=edge" ||
+		first.Source.AutoRegister != "off" || second.Source.AutoRegister != "register" {
+		t.Fatalf("unexpected object settings: %+v, %+v", first.Source, second.Source)
+	}
+}
+
+func TestSourcesFromEnvFallsBackToSingleSource(t *testing.T) {
+	sources, err := SourcesFromEnv(func(string) string { return "" })
+	if err != nil || len(sources) != 0 {
+		t.Fatalf("expected no sources when disabled, got %+v, %v", sources, err)
+	}
+	env := map[string]string{
+		"KUBERNETES_DISCOVERY_ENABLED":    "true",
+		"KUBERNETES_DISCOVERY_SOURCE_KEY": "local",
+		"KUBERNETES_DISCOVERY_SITE_ID":    "550e8400-e29b-41d4-a716-446655440000",
+		"KUBERNETES_DISCOVERY_NAMESPACES": "default",
+	}
+	sources, err = SourcesFromEnv(func(key string) string { return env[key] })
+	if err != nil || len(sources) != 1 || sources[0].Source.Key != "local" {
+		t.Fatalf("unexpected sources: %+v, %v", sources, err)
+	}
+}
+
+func TestSourcesFileRejectsInvalidSources(t *testing.T) {
+	const site = "550e8400-e29b-41d4-a716-446655440000"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "duplicate key", content: "sources:\n  - {source_key: a, site_id: " + site + ", namespaces: [default]}\n  - {source_key: a, site_id: " + site + ", namespaces: [apps]}\n", want: `sources[1]: duplicate source_key "a"`},
+		{name: "missing site", content: "sources:\n  - {source_key: a, namespaces: [default]}\n", want: "sources[0]: site_id is required"},
+		{name: "implicit kubeconfig", content: "sources:\n  - {source_key: a, site_id: " + site + ", namespaces: [default], auth_mode: kubeconfig}\n", want: "kubeconfig_path is required"},
+		{name: "bad interval", content: "sources:\n  - {source_key: a, site_id: " + site + ", namespaces: [default], interval: often}\n", want: "interval:"},
+		{name: "unknown object kind", content: "sources:\n  - {source_key: a, site_id: " + site + ", namespaces: [default], object_kinds: [deployment]}\n", want: "sources[0]: object_kinds: unknown object kind"},
+		{name: "unknown setting", content: "sources:\n  - {source_key: a, site: " + site + "}\n", want: "unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSourcesFile(writeSourcesFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSourcesFileCannotBeCombinedWithSingleSource(t *testing.T) {
+	env := map[string]string{
+		"KUBERNETES_DISCOVERY_SOURCES_FILE": writeSourcesFile(t, "sources: []\n"),
+		"KUBERNETES_DISCOVERY_ENABLED":      "true",
+	}
+	_, err := SourcesFromEnv(func(key string) string { return env[key] })
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
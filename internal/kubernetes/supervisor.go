@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+// resyncInterval bounds how long a missed change notification can leave a
+// runner on old settings.
+const resyncInterval = time.Minute
+
+// SourceStore lists the discovery sources managed through the API.
+type SourceStore interface {
+	ListManagedSources(ctx context.Context) ([]domain.ManagedKubernetesSource, error)
+}
+
+// ChangeSource delivers live change notifications, such as events.Broker.
+type ChangeSource interface {
+	Subscribe(ctx context.Context, filter domain.EventFilter) <-chan domain.ChangeEvent
+}
+
+// Supervisor runs one Runner per source managed through the API and
+// replaces it when the source is changed or deleted, so sources are picked
+// up without a restart.
+type Supervisor struct {
+	store     SourceStore
+	service   domain.KubernetesDiscoveryService
+	logger    *slog.Logger
+	observer  Observer
+	triggers  *Triggers
+	newLister func(Config) (ServiceLister, error)
+	running   map[string]supervisedRunner
+}
+
+type supervisedRunner struct {
+	config Config
+	cancel context.CancelFunc
+	done   <-chan struct{}
+	remove func()
+}
+
+func NewSupervisor(store SourceStore, service domain.KubernetesDiscoveryService, logger *slog.Logger) *Supervisor {
+	return &Supervisor{
+		store:     store,
+		service:   service,
+		logger:    logger,
+		newLister: func(config Config) (ServiceLister, error) { return NewClient(config) },
+		running:   make(map[string]supervisedRunner),
+	}
+}
+
+// WithObserver reports each runner's cycles to observer and returns the
+// supervisor.
+func (s *Supervisor) WithObserver(observer Observer) *Supervisor {
+	s.observer = observer
+	return s
+}
+
+// WithTriggers makes each runner reachable by triggers while it runs, and
+// returns the supervisor.
+func (s *Supervisor) WithTriggers(triggers *Triggers) *Supervisor {
+	s.triggers = triggers
+	return s
+}
+
+// Run starts the stored sources and resyncs them on every source change
+// notification and every resyncInterval, until ctx is cancelled.
+func (s *Supervisor) Run(ctx context.Context, changes ChangeSource) {
+	events := changes.Subscribe(ctx, domain.EventFilter{ObjectTypes: []string{domain.ObjectTypeKubernetesSource}})
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+	defer s.stopAll()
+	for {
+		s.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync stops runners whose source was deleted or changed and starts the
+// missing ones. A source whose client cannot be built is retried on the
+// next sync.
+func (s *Supervisor) sync(ctx context.Context) {
+	sources, err := s.store.ListManagedSources(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "loading kubernetes discovery sources", "err", err)
+		}
+		return
+	}
+	wanted := make(map[string]Config, len(sources))
+	for _, source := range sources {
+		wanted[source.Config.Key] = managedSourceConfig(source)
+	}
+	for key, runner := range s.running {
+		if config, ok := wanted[key]; !ok || !reflect.DeepEqual(config, runner.config) {
+			runner.stop()
+			delete(s.running, key)
+			s.logger.InfoContext(ctx, "kubernetes discovery source stopped", "source", key)
+		}
+	}
+	for key, config := range wanted {
+		if _, ok := s.running[key]; !ok {
+			s.start(ctx, config)
+		}
+	}
+}
+
+func (s *Supervisor) start(ctx context.Context, config Config) {
+	lister, err := s.newLister(config)
+	if err != nil {
+		s.logger.WarnContext(ctx, "kubernetes discovery source cannot start", "source", config.Source.Key, "err", err)
+		recordCtx, cancel := context.WithTimeout(ctx, config.RequestTimeout)
+		defer cancel()
+		if recordErr := s.service.RecordFailure(recordCtx, config.Source, time.Now().UTC(), err); recordErr != nil && !errors.Is(recordErr, domain.ErrDiscoveryBusy) {
+			s.logger.ErrorContext(ctx, "recording kubernetes discovery failure", "source", config.Source.Key, "err", recordErr)
+		}
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	runner := NewRunner(config, lister, s.service, s.logger).WithObserver(s.observer)
+	remove := func() {}
+	if s.triggers != nil {
+		remove = s.triggers.Add(runner)
+	}
+	go func() {
+		defer close(done)
+		runner.Run(runCtx)
+	}()
+	s.running[config.Source.Key] = supervisedRunner{config: config, cancel: cancel, done: done, remove: remove}
+	s.logger.InfoContext(ctx, "kubernetes discovery source started", "source", config.Source.Key)
+}
+
+func (s *Supervisor) stopAll() {
+	for key, runner := range s.running {
+		runner.stop()
+		delete(s.running, key)
+	}
+}
+
+// stop waits for the runner so an old cycle never overlaps a new one.
+func (r supervisedRunner) stop() {
+	r.remove()
+	r.cancel()
+	<-r.done
+}
+
+func managedSourceConfig(source domain.ManagedKubernetesSource) Config {
+	return Config{
+		Enabled:           true,
+		Source:            source.Config,
+		AuthMode:          source.AuthMode,
+		Kubeconfig:        source.Kubeconfig,
+		KubeconfigContext: source.KubeconfigContext,
+		ReconcileInterval: source.ReconcileInterval,
+		RequestTimeout:    source.RequestTimeout,
+	}
+}
@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type stubSourceStore struct {
+	sources []domain.ManagedKubernetesSource
+}
+
+func (s *stubSourceStore) ListManagedSources(context.Context) ([]domain.ManagedKubernetesSource, error) {
+	return s.sources, nil
+}
+
+func managedTestSource(key string) domain.ManagedKubernetesSource {
+	return domain.ManagedKubernetesSource{
+		Config: domain.KubernetesSourceConfig{
+			Key:            key,
+			Name:           key,
+			SiteID:         uuid.New(),
+			ClusterDomain:  "cluster.local",
+			Namespaces:     []string{"default"},
+			StaleRetention: time.Hour,
+			Managed:        true,
+		},
+		AuthMode:          AuthModeInCluster,
+		ReconcileInterval: time.Hour,
+		RequestTimeout:    time.Second,
+	}
+}
+
+func TestSupervisorFollowsManagedSources(t *testing.T) {
+	store := &stubSourceStore{sources: []domain.ManagedKubernetesSource{managedTestSource("a"), managedTestSource("b")}}
+	supervisor := NewSupervisor(store, &stubDiscoveryService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	built := 0
+	supervisor.newLister = func(Config) (ServiceLister, error) {
+		built++
+		return stubLister{services: []domain.KubernetesServiceSnapshot{}}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer supervisor.stopAll()
+
+	supervisor.sync(ctx)
+	if len(supervisor.running) != 2 || built != 2 {
+		t.Fatalf("expected two runners, got %d after %d clients", len(supervisor.running), built)
+	}
+
+	supervisor.sync(ctx)
+	if built != 2 {
+		t.Fatalf("unchanged sources were restarted: %d clients", built)
+	}
+
+	changed := store.sources[0]
+	changed.Config.Namespaces = []string{"apps"}
+	store.sources = []domain.ManagedKubernetesSource{changed}
+	supervisor.sync(ctx)
+	if len(supervisor.running) != 1 || built != 3 {
+		t.Fatalf("expected only the changed source to restart, got %d runners after %d clients", len(supervisor.running), built)
+	}
+	if got := supervisor.running["a"].config.Source.Namespaces; len(got) != 1 || got[0] != "apps" {
+		t.Fatalf("runner kept old settings: %v", got)
+	}
+}
+
+func TestSupervisorRetriesSourcesThatCannotStart(t *testing.T) {
+	service := &stubDiscoveryService{}
+	supervisor := NewSupervisor(&stubSourceStore{sources: []domain.ManagedKubernetesSource{managedTestSource("a")}}, service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	fail := true
+	supervisor.newLister = func(Config) (ServiceLister, error) {
+		if fail {
+			return nil, errors.New("bad kubeconfig")
+		}
+		return stubLister{services: []domain.KubernetesServiceSnapshot{}}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer supervisor.stopAll()
+
+	supervisor.sync(ctx)
+	if len(supervisor.running) != 0 || service.failureCalls != 1 {
+		t.Fatalf("expected a recorded failure, got runners=%d failures=%d", len(supervisor.running), service.failureCalls)
+	}
+	fail = false
+	supervisor.sync(ctx)
+	if len(supervisor.running) != 1 {
+		t.Fatalf("expected the source to start on retry, got %d runners", len(supervisor.running))
+	}
+}
+
+func TestSupervisorRegistersRunnersWithTriggers(t *testing.T) {
+	store := &stubSourceStore{sources: []domain.ManagedKubernetesSource{managedTestSource("a")}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := &stubDiscoveryService{}
+	triggers := NewTriggers(service, stubChangeSource{}, logger)
+	supervisor := NewSupervisor(store, service, logger).WithTriggers(triggers)
+	supervisor.newLister = func(Config) (ServiceLister, error) {
+		return stubLister{services: []domain.KubernetesServiceSnapshot{}}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer supervisor.stopAll()
+
+	supervisor.sync(ctx)
+	if _, ok := triggers.runner("a"); !ok {
+		t.Fatal("started runner is not reachable by triggers")
+	}
+	store.sources = nil
+	supervisor.sync(ctx)
+	if _, ok := triggers.runner("a"); ok {
+		t.Fatal("stopped runner is still reachable by triggers")
+	}
+}
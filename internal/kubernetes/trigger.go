@@ -0,0 +1,130 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Triggers runs discovery cycles on demand. A source whose runner is on this
+// replica is reconciled directly. Otherwise the request is signalled to every
+// replica through the change notifications under a fresh request ID, and the
+// outcome is the reconcile notification that echoes that ID.
+type Triggers struct {
+	service domain.KubernetesDiscoveryService
+	changes ChangeSource
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	runners map[string]*Runner
+}
+
+func NewTriggers(service domain.KubernetesDiscoveryService, changes ChangeSource, logger *slog.Logger) *Triggers {
+	return &Triggers{service: service, changes: changes, logger: logger, now: time.Now, runners: make(map[string]*Runner)}
+}
+
+// Add makes runner reachable by its source key until the returned function
+// is called.
+func (t *Triggers) Add(runner *Runner) (remove func()) {
+	key := runner.config.Source.Key
+	t.mu.Lock()
+	t.runners[key] = runner
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.runners[key] == runner {
+			delete(t.runners, key)
+		}
+	}
+}
+
+func (t *Triggers) runner(key string) (*Runner, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	runner, ok := t.runners[key]
+	return runner, ok
+}
+
+// Reconcile runs a cycle of the source now and returns its result. It
+// returns ErrNotFound for an unknown source, ErrDiscoveryBusy when the local
+// runner finds another cycle holding the source lock, and
+// ErrDiscoveryUnanswered when ctx ends before another replica reports the
+// outcome of the requested cycle.
+func (t *Triggers) Reconcile(ctx context.Context, key string) (domain.KubernetesReconcileResult, error) {
+	if runner, ok := t.runner(key); ok {
+		return runner.ReconcileOnce(ctx)
+	}
+	// Subscribing first means the outcome cannot arrive unobserved.
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := t.changes.Subscribe(waitCtx, domain.EventFilter{ObjectTypes: []string{domain.ObjectTypeKubernetes}})
+	requestID := uuid.NewString()
+	if err := t.service.RequestReconcile(ctx, key, requestID, t.now().UTC()); err != nil {
+		return domain.KubernetesReconcileResult{}, err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return domain.KubernetesReconcileResult{}, domain.ErrDiscoveryUnanswered
+		case event, ok := <-events:
+			if !ok {
+				return domain.KubernetesReconcileResult{}, domain.ErrDiscoveryUnanswered
+			}
+			if event.ObjectID != key || (event.Type != domain.EventKubernetesReconciled && event.Type != domain.EventKubernetesReconcileFailed) {
+				continue
+			}
+			outcome, ok := cycleOutcome(event, requestID)
+			if !ok {
+				continue
+			}
+			if outcome.Error != "" || event.Type == domain.EventKubernetesReconcileFailed {
+				return domain.KubernetesReconcileResult{}, errors.New(outcome.Error)
+			}
+			return outcome.Result, nil
+		}
+	}
+}
+
+// cycleOutcome decodes the outcome a reconcile notification carries and
+// reports whether it answers requestID. Notifications of periodic cycles and
+// of other requests carry no outcome or another request's.
+func cycleOutcome(event domain.ChangeEvent, requestID string) (domain.KubernetesCycleOutcome, bool) {
+	var outcome domain.KubernetesCycleOutcome
+	if len(event.Payload) == 0 || json.Unmarshal(event.Payload, &outcome) != nil {
+		return domain.KubernetesCycleOutcome{}, false
+	}
+	return outcome, outcome.RequestID == requestID
+}
+
+// Run starts a cycle of the sources with a runner on this replica whenever
+// another replica signals a request for them, until ctx is cancelled. The
+// cycle carries the request's ID so that its notification answers it. The
+// replicas that find the source lock held skip the request.
+func (t *Triggers) Run(ctx context.Context) {
+	events := t.changes.Subscribe(ctx, domain.EventFilter{ObjectTypes: []string{domain.ObjectTypeKubernetes}})
+	for event := range events {
+		if event.Type != domain.EventKubernetesReconcileRequested {
+			continue
+		}
+		runner, ok := t.runner(event.ObjectID)
+		if !ok {
+			continue
+		}
+		var request domain.KubernetesCycleRequest
+		if err := json.Unmarshal(event.Payload, &request); err != nil || request.RequestID == "" {
+			t.logger.WarnContext(ctx, "kubernetes discovery request without an id skipped", "source", event.ObjectID)
+			continue
+		}
+		t.logger.DebugContext(ctx, "kubernetes discovery cycle requested", "source", event.ObjectID, "request_id", request.RequestID)
+		cycleCtx := domain.WithKubernetesCycleRequest(ctx, request.RequestID)
+		go func() { _, _ = runner.ReconcileOnce(cycleCtx) }()
+	}
+}
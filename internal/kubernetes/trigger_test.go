@@ -0,0 +1,151 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+type stubChangeSource struct {
+	events chan domain.ChangeEvent
+}
+
+func (s stubChangeSource) Subscribe(context.Context, domain.EventFilter) <-chan domain.ChangeEvent {
+	return s.events
+}
+
+func TestTriggersReconcileLocalRunner(t *testing.T) {
+	service := &stubDiscoveryService{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	triggers := NewTriggers(service, stubChangeSource{events: make(chan domain.ChangeEvent)}, logger)
+	services := []domain.KubernetesServiceSnapshot{{UID: "a"}, {UID: "b"}}
+	remove := triggers.Add(NewRunner(validTestConfig("apps"), stubLister{services: services}, service, logger))
+
+	result, err := triggers.Reconcile(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if result.Services != 2 || service.reconcileCalls != 1 || len(service.requested) != 0 {
+		t.Fatalf("expected a local cycle, got result=%+v reconcile=%d requested=%v", result, service.reconcileCalls, service.requested)
+	}
+
+	remove()
+	if _, ok := triggers.runner("test"); ok {
+		t.Fatal("removed runner is still reachable")
+	}
+}
+
+func cycleEvent(t *testing.T, eventType, key string, payload any) domain.ChangeEvent {
+	t.Helper()
+	event := domain.ChangeEvent{Type: eventType, ObjectType: domain.ObjectTypeKubernetes, ObjectID: key}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		event.Payload = raw
+	}
+	return event
+}
+
+func TestTriggersReconcileSignalsOtherReplicas(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		outcome   domain.KubernetesCycleOutcome
+		wantErr   bool
+	}{
+		{
+			name:      "reconciled",
+			eventType: domain.EventKubernetesReconciled,
+			outcome:   domain.KubernetesCycleOutcome{Result: domain.KubernetesReconcileResult{Services: 4, Matched: 3, Unmatched: 1}},
+		},
+		{
+			name:      "failed",
+			eventType: domain.EventKubernetesReconcileFailed,
+			outcome:   domain.KubernetesCycleOutcome{Error: "forbidden"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make(chan domain.ChangeEvent, 5)
+			service := &stubDiscoveryService{}
+			// Only the cycle that echoes the request answers it: not another
+			// source's, not a periodic cycle's and not another request's.
+			service.onRequest = func(key, requestID string) {
+				events <- cycleEvent(t, domain.EventKubernetesReconciled, "other", domain.KubernetesCycleOutcome{RequestID: requestID})
+				events <- cycleEvent(t, domain.EventKubernetesReconciled, key, nil)
+				events <- cycleEvent(t, domain.EventKubernetesReconcileFailed, key, domain.KubernetesCycleOutcome{RequestID: "earlier", Error: "stale"})
+				outcome := tt.outcome
+				outcome.RequestID = requestID
+				events <- cycleEvent(t, tt.eventType, key, outcome)
+			}
+			triggers := NewTriggers(service, stubChangeSource{events: events}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			result, err := triggers.Reconcile(context.Background(), "test")
+			if len(service.requested) != 1 || service.requested[0] != "test" {
+				t.Fatalf("expected one signalled request, got %v", service.requested)
+			}
+			if tt.wantErr {
+				if err == nil || err.Error() != "forbidden" {
+					t.Fatalf("expected the requested cycle's failure, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reconcile: %v", err)
+			}
+			if result != tt.outcome.Result {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+func TestTriggersReconcileWithoutAnswer(t *testing.T) {
+	triggers := NewTriggers(&stubDiscoveryService{}, stubChangeSource{events: make(chan domain.ChangeEvent)}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := triggers.Reconcile(ctx, "test"); !errors.Is(err, domain.ErrDiscoveryUnanswered) {
+		t.Fatalf("expected ErrDiscoveryUnanswered, got %v", err)
+	}
+}
+
+func TestTriggersRunServesRequestsForLocalRunners(t *testing.T) {
+	events := make(chan domain.ChangeEvent, 2)
+	service := &stubDiscoveryService{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	triggers := NewTriggers(service, stubChangeSource{events: events}, logger)
+	triggers.Add(NewRunner(validTestConfig("apps"), stubLister{services: []domain.KubernetesServiceSnapshot{}}, service, logger))
+
+	events <- cycleEvent(t, domain.EventKubernetesReconcileRequested, "other", domain.KubernetesCycleRequest{RequestID: "r1"})
+	events <- cycleEvent(t, domain.EventKubernetesReconcileRequested, "test", domain.KubernetesCycleRequest{RequestID: "r2"})
+	close(events)
+	triggers.Run(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		service.mu.Lock()
+		calls := service.reconcileCalls
+		requests := slices.Clone(service.cycleRequests)
+		service.mu.Unlock()
+		if calls == 1 {
+			if !slices.Equal(requests, []string{"r2"}) {
+				t.Fatalf("expected the cycle to carry request r2, got %v", requests)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected one requested cycle, got %d", calls)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,137 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ServiceChange is one watched Service event. Snapshot is nil when the
+// Service was deleted, and Err is set when it could not be converted.
+type ServiceChange struct {
+	UID      string
+	Snapshot *domain.KubernetesServiceSnapshot
+	Err      error
+}
+
+// ServiceWatcher is a ServiceLister that can also report changes as they
+// happen.
+type ServiceWatcher interface {
+	ServiceLister
+	// WatchServices returns once the watch caches have synced; onChange is
+	// called for every later add, update and delete until ctx is cancelled.
+	WatchServices(ctx context.Context, onChange func(ServiceChange)) error
+}
+
+// WatchServices starts one shared Service informer per watched namespace,
+// or a single cluster-wide one for "*". Once the caches have synced,
+// ListServices reads them instead of listing from the API server. Services
+// present when the watch starts are not reported as changes.
+func (c *Client) WatchServices(ctx context.Context, onChange func(ServiceChange)) error {
+	c.mu.Lock()
+	if c.watching {
+		c.mu.Unlock()
+		return errors.New("kubernetes services are already watched")
+	}
+	c.watching = true
+	c.mu.Unlock()
+
+	handler := cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj any, isInInitialList bool) {
+			if !isInInitialList {
+				c.reportChange(obj, false, onChange)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			if previous, ok := oldObj.(*corev1.Service); ok {
+				if current, ok := newObj.(*corev1.Service); ok && previous.ResourceVersion == current.ResourceVersion {
+					return
+				}
+			}
+			c.reportChange(newObj, false, onChange)
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			c.reportChange(obj, true, onChange)
+		},
+	}
+	listers := make([]corelisters.ServiceLister, 0, len(c.config.Source.Namespaces))
+	factories := make([]informerFactory, 0, len(c.config.Source.Namespaces))
+	synced := make([]cache.InformerSynced, 0, len(c.config.Source.Namespaces))
+	for _, namespace := range watchedNamespaces(c.config.Source.Namespaces) {
+		factory := informers.NewSharedInformerFactoryWithOptions(c.watchClient, 0, informers.WithNamespace(namespace))
+		informer := factory.Core().V1().Services()
+		if _, err := informer.Informer().AddEventHandler(handler); err != nil {
+			return fmt.Errorf("watch services in namespace %q: %w", displayNamespace(namespace), err)
+		}
+		listers = append(listers, informer.Lister())
+		factories = append(factories, factory)
+		synced = append(synced, informer.Informer().HasSynced)
+	}
+	if err := runInformers(ctx, "service", factories, synced); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.listers = listers
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Client) reportChange(obj any, deleted bool, onChange func(ServiceChange)) {
+	service, ok := obj.(*corev1.Service)
+	if !ok || service.UID == "" {
+		return
+	}
+	change := ServiceChange{UID: string(service.UID)}
+	if !deleted {
+		snapshot, err := serviceToSnapshot(service, c.config.Source.ClusterDomain)
+		if err != nil {
+			change.Err = err
+		} else {
+			change.Snapshot = &snapshot
+		}
+	}
+	onChange(change)
+}
+
+// cachedServices lists the synced watch caches, or returns false before
+// WatchServices has synced.
+func (c *Client) cachedServices() ([]*corev1.Service, bool, error) {
+	c.mu.Lock()
+	listers := c.listers
+	c.mu.Unlock()
+	if listers == nil {
+		return nil, false, nil
+	}
+	var services []*corev1.Service
+	for _, lister := range listers {
+		listed, err := lister.List(labels.Everything())
+		if err != nil {
+			return nil, true, fmt.Errorf("list cached services: %w", err)
+		}
+		services = append(services, listed...)
+	}
+	return services, true, nil
+}
+
+func watchedNamespaces(namespaces []string) []string {
+	watched := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if namespace == "*" {
+			return []string{corev1.NamespaceAll}
+		}
+		watched = append(watched, namespace)
+	}
+	return watched
+}
+
+var _ ServiceWatcher = (*Client)(nil)
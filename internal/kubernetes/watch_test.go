@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func watchTestService(name, uid, clusterIP string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", UID: types.UID(uid)},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: clusterIP, ClusterIPs: []string{clusterIP}},
+	}
+}
+
+func nextChange(t *testing.T, changes <-chan ServiceChange) ServiceChange {
+	t.Helper()
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a service change")
+		return ServiceChange{}
+	}
+}
+
+func TestClientWatchReportsServiceChanges(t *testing.T) {
+	clientset := fake.NewClientset(watchTestService("existing", "uid-existing", "10.96.0.1"))
+	client := NewClientWithInterface(validTestConfig("apps"), clientset)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan ServiceChange, 8)
+	if err := client.WatchServices(ctx, func(change ServiceChange) { changes <- change }); err != nil {
+		t.Fatalf("WatchServices: %v", err)
+	}
+	if err := client.WatchServices(ctx, func(ServiceChange) {}); err == nil {
+		t.Fatal("expected a second watch to be rejected")
+	}
+
+	services := clientset.CoreV1().Services("apps")
+	if _, err := services.Create(ctx, watchTestService("orders", "uid-orders", "10.96.0.2"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	change := nextChange(t, changes)
+	if change.UID != "uid-orders" || change.Snapshot == nil || change.Snapshot.Addresses[0].Address.String() != "10.96.0.2" {
+		t.Fatalf("unexpected add: %+v", change)
+	}
+
+	snapshots, err := client.ListServices(ctx)
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("expected the cache to hold both services, got %d, %v", len(snapshots), err)
+	}
+
+	if err := services.Delete(ctx, "existing", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if change = nextChange(t, changes); change.UID != "uid-existing" || change.Snapshot != nil || change.Err != nil {
+		t.Fatalf("unexpected delete: %+v", change)
+	}
+
+	if _, err := services.Create(ctx, watchTestService("broken", "uid-broken", "not-an-ip"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if change = nextChange(t, changes); change.UID != "uid-broken" || change.Err == nil {
+		t.Fatalf("expected a conversion error, got %+v", change)
+	}
+}
+
+func TestClientListsDirectlyUntilWatchSyncs(t *testing.T) {
+	clientset := fake.NewClientset(watchTestService("orders", "uid-orders", "10.96.0.2"))
+	client := NewClientWithInterface(validTestConfig("apps"), clientset)
+	if _, err := client.ListServices(context.Background()); err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	if len(clientset.Actions()) != 1 || clientset.Actions()[0].GetVerb() != "list" {
+		t.Fatalf("expected one direct list, got %v", clientset.Actions())
+	}
+}
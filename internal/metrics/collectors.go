@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStater is implemented by *pgxpool.Pool.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+type poolCollector struct {
+	pool PoolStater
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+}
+
+// NewPoolCollector reads pgxpool statistics at scrape time.
+func NewPoolCollector(pool PoolStater) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_connections", "Connections currently checked out of the pool."),
+		idleConns:            desc("idle_connections", "Idle connections in the pool."),
+		constructingConns:    desc("constructing_connections", "Connections being established."),
+		totalConns:           desc("total_connections", "Connections open in the pool."),
+		maxConns:             desc("max_connections", "Configured pool size."),
+		acquireCount:         desc("acquires_total", "Successful connection acquires."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Time spent waiting to acquire connections."),
+		emptyAcquireCount:    desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+		canceledAcquireCount: desc("canceled_acquires_total", "Acquires canceled by their context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+// SubnetLister is the part of domain.NetworkService the utilization
+// collector needs.
+type SubnetLister interface {
+	ListSubnets(ctx context.Context) ([]domain.Subnet, error)
+}
+
+const subnetScrapeTimeout = 10 * time.Second
+
+type subnetUtilizationCollector struct {
+	subnets SubnetLister
+	logger  *slog.Logger
+
+	used        *prometheus.Desc
+	total       *prometheus.Desc
+	utilization *prometheus.Desc
+}
+
+// NewSubnetUtilizationCollector lists subnets on every scrape, so the gauges
+// always match what the API reports. Total counts saturate at the int64
+// maximum for large IPv6 prefixes.
+func NewSubnetUtilizationCollector(subnets SubnetLister, logger *slog.Logger) prometheus.Collector {
+	labels := []string{"subnet_id", "cidr", "site_id"}
+	return &subnetUtilizationCollector{
+		subnets: subnets,
+		logger:  logger,
+		used: prometheus.NewDesc(prometheus.BuildFQName(namespace, "subnet", "used_addresses"),
+			"Addresses allocated in the subnet.", labels, nil),
+		total: prometheus.NewDesc(prometheus.BuildFQName(namespace, "subnet", "total_addresses"),
+			"Usable addresses in the subnet.", labels, nil),
+		utilization: prometheus.NewDesc(prometheus.BuildFQName(namespace, "subnet", "utilization_ratio"),
+			"Allocated share of the subnet's usable addresses, from 0 to 1.", labels, nil),
+	}
+}
+
+func (c *subnetUtilizationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.used
+	ch <- c.total
+	ch <- c.utilization
+}
+
+func (c *subnetUtilizationCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), subnetScrapeTimeout)
+	defer cancel()
+	subnets, err := c.subnets.ListSubnets(ctx)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "collecting subnet utilization", "err", err)
+		ch <- prometheus.NewInvalidMetric(c.utilization, err)
+		return
+	}
+	for _, subnet := range subnets {
+		labels := []string{strconv.FormatInt(subnet.ID, 10), subnet.CIDR.String(), subnet.SiteID.String()}
+		ch <- prometheus.MustNewConstMetric(c.used, prometheus.GaugeValue, float64(subnet.UsedIPCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(subnet.TotalIPCount), labels...)
+		ratio := 0.0
+		if subnet.TotalIPCount > 0 {
+			ratio = float64(subnet.UsedIPCount) / float64(subnet.TotalIPCount)
+		}
+		ch <- prometheus.MustNewConstMetric(c.utilization, prometheus.GaugeValue, ratio, labels...)
+	}
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ipam"
+
+// Metrics owns a private Prometheus registry, so tests can build as many as
+// they like. It implements the observer interfaces of internal/http,
+// internal/kubernetes and internal/reporting.
+type Metrics struct {
+	registry *prometheus.Registry
+	logger   *slog.Logger
+
+	httpRequests         *prometheus.CounterVec
+	httpDuration         *prometheus.HistogramVec
+	reconcileDuration    *prometheus.HistogramVec
+	reconcileServices    *prometheus.GaugeVec
+	reconcileLastSuccess *prometheus.GaugeVec
+	snapshotCycles       *prometheus.CounterVec
+	snapshotsCaptured    prometheus.Counter
+	snapshotsDeleted     prometheus.Counter
+}
+
+func New(logger *slog.Logger) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		logger:   logger,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		reconcileDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "kubernetes_reconcile_duration_seconds",
+			Help:      "Kubernetes discovery cycle duration by source and outcome (success, failure, busy).",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"source", "outcome"}),
+		reconcileServices: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "kubernetes_services",
+			Help:      "Services seen by the last successful discovery cycle, by source and match status.",
+		}, []string{"source", "status"}),
+		reconcileLastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "kubernetes_reconcile_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful discovery cycle by source.",
+		}, []string{"source"}),
+		snapshotCycles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reporting_snapshot_cycles_total",
+			Help:      "Usage snapshot cycles by outcome (success, failure).",
+		}, []string{"outcome"}),
+		snapshotsCaptured: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reporting_snapshots_captured_total",
+			Help:      "Subnet usage snapshots captured.",
+		}),
+		snapshotsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reporting_snapshots_deleted_total",
+			Help:      "Subnet usage snapshots deleted by retention.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.reconcileDuration,
+		m.reconcileServices,
+		m.reconcileLastSuccess,
+		m.snapshotCycles,
+		m.snapshotsCaptured,
+		m.snapshotsDeleted,
+	)
+	return m
+}
+
+// Register adds collectors such as NewPoolCollector and
+// NewSubnetUtilizationCollector.
+func (m *Metrics) Register(collectors ...prometheus.Collector) {
+	m.registry.MustRegister(collectors...)
+}
+
+// Handler serves the registry in the Prometheus text format. The API
+// checks the scrape token before it, so rejections are problem responses
+// like the rest of the API's. Collector errors are logged and the remaining
+// metrics are still served.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(m.logger.Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveReconcile(source string, duration time.Duration, result domain.KubernetesReconcileResult, err error) {
+	outcome := "success"
+	switch {
+	case errors.Is(err, domain.ErrDiscoveryBusy):
+		outcome = "busy"
+	case err != nil:
+		outcome = "failure"
+	}
+	m.reconcileDuration.WithLabelValues(source, outcome).Observe(duration.Seconds())
+	if err != nil {
+		return
+	}
+	m.reconcileServices.WithLabelValues(source, "matched").Set(float64(result.Matched))
+	m.reconcileServices.WithLabelValues(source, "unmatched").Set(float64(result.Unmatched))
+	m.reconcileServices.WithLabelValues(source, "ambiguous").Set(float64(result.Ambiguous))
+	m.reconcileServices.WithLabelValues(source, "no_usable_ip").Set(float64(result.NoUsableIP))
+	m.reconcileLastSuccess.WithLabelValues(source).SetToCurrentTime()
+}
+
+func (m *Metrics) ObserveSnapshotCycle(result domain.SnapshotCycleResult, err error) {
+	if err != nil {
+		m.snapshotCycles.WithLabelValues("failure").Inc()
+		return
+	}
+	m.snapshotCycles.WithLabelValues("success").Inc()
+	m.snapshotsCaptured.Add(float64(result.Captured))
+	m.snapshotsDeleted.Add(float64(result.Deleted))
+}
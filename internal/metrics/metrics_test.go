@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubSubnetLister struct {
+	subnets []domain.Subnet
+	err     error
+}
+
+func (s stubSubnetLister) ListSubnets(context.Context) ([]domain.Subnet, error) {
+	return s.subnets, s.err
+}
+
+func newTestMetrics() *Metrics {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func scrape(t *testing.T, handler http.Handler) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestMetricsRecordObservations(t *testing.T) {
+	m := newTestMetrics()
+	m.ObserveRequest(http.MethodGet, "/api/v1/subnets/{id}", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/api/v1/subnets/{id}", http.StatusOK, 30*time.Millisecond)
+	m.ObserveReconcile("prod", time.Second, domain.KubernetesReconcileResult{Services: 4, Matched: 2, Unmatched: 1, Ambiguous: 1}, nil)
+	m.ObserveReconcile("prod", time.Second, domain.KubernetesReconcileResult{}, domain.ErrDiscoveryBusy)
+	m.ObserveSnapshotCycle(domain.SnapshotCycleResult{Captured: 3, Deleted: 1}, nil)
+	m.ObserveSnapshotCycle(domain.SnapshotCycleResult{}, errors.New("db down"))
+
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/v1/subnets/{id}", "200")); got != 2 {
+		t.Fatalf("expected 2 requests, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.reconcileServices.WithLabelValues("prod", "matched")); got != 2 {
+		t.Fatalf("expected 2 matched services, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.reconcileDuration); got != 2 {
+		t.Fatalf("expected success and busy duration series, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.snapshotsCaptured); got != 3 {
+		t.Fatalf("expected 3 captured snapshots, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.snapshotCycles.WithLabelValues("failure")); got != 1 {
+		t.Fatalf("expected 1 failed cycle, got %v", got)
+	}
+}
+
+func TestSubnetUtilizationCollectorReportsEachSubnet(t *testing.T) {
+	m := newTestMetrics()
+	siteID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	m.Register(NewSubnetUtilizationCollector(stubSubnetLister{subnets: []domain.Subnet{
+		{ID: 7, CIDR: netip.MustParsePrefix("10.0.0.0/30"), SiteID: siteID, UsedIPCount: 1, TotalIPCount: 2},
+	}}, m.logger))
+
+	code, body := scrape(t, m.Handler())
+	if code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, code)
+	}
+	want := `ipam_subnet_utilization_ratio{cidr="10.0.0.0/30",site_id="11111111-1111-1111-1111-111111111111",subnet_id="7"} 0.5`
+	if !strings.Contains(body, want) {
+		t.Fatalf("expected %q in scrape:\n%s", want, body)
+	}
+}
+
+func TestHandlerKeepsServingWhenSubnetListingFails(t *testing.T) {
+	m := newTestMetrics()
+	m.Register(NewSubnetUtilizationCollector(stubSubnetLister{err: errors.New("db down")}, m.logger))
+	m.ObserveSnapshotCycle(domain.SnapshotCycleResult{Captured: 1}, nil)
+
+	code, body := scrape(t, m.Handler())
+	if code != http.StatusOK || !strings.Contains(body, "ipam_reporting_snapshots_captured_total 1") {
+		t.Fatalf("expected remaining metrics to be served, got %d:\n%s", code, body)
+	}
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+)
+
+const defaultPruneInterval = time.Minute
+
+// Pruner periodically deletes rate limit windows that have ended. Expired
+// windows are never read again, so pruning only bounds storage.
+type Pruner struct {
+	limiter  domain.RateLimiter
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+func NewPruner(limiter domain.RateLimiter, logger *slog.Logger) *Pruner {
+	return &Pruner{limiter: limiter, logger: logger, interval: defaultPruneInterval}
+}
+
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune(ctx)
+		}
+	}
+}
+
+func (p *Pruner) prune(ctx context.Context) {
+	deleted, err := p.limiter.PruneExpired(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.ErrorContext(ctx, "pruning rate limit counters failed", "err", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		p.logger.DebugContext(ctx, "pruned expired rate limit counters", "deleted", deleted)
+	}
+}
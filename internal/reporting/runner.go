@@ -10,9 +10,15 @@ import (
 
 const defaultCheckInterval = time.Minute
 
+// Observer receives the outcome of every snapshot cycle.
+type Observer interface {
+	ObserveSnapshotCycle(result domain.SnapshotCycleResult, err error)
+}
+
 type Runner struct {
 	service  domain.ReportingService
 	logger   *slog.Logger
+	observer Observer
 	interval time.Duration
 }
 
@@ -20,6 +26,12 @@ func NewRunner(service domain.ReportingService, logger *slog.Logger) *Runner {
 	return &Runner{service: service, logger: logger, interval: defaultCheckInterval}
 }
 
+// WithObserver reports each cycle to observer and returns the runner.
+func (r *Runner) WithObserver(observer Observer) *Runner {
+	r.observer = observer
+	return r
+}
+
 func (r *Runner) Run(ctx context.Context) {
 	r.runCycle(ctx)
 	ticker := time.NewTicker(r.interval)
@@ -36,6 +48,9 @@ func (r *Runner) Run(ctx context.Context) {
 
 func (r *Runner) runCycle(ctx context.Context) {
 	result, err := r.service.RunSnapshotCycle(ctx)
+	if r.observer != nil && ctx.Err() == nil {
+		r.observer.ObserveSnapshotCycle(result, err)
+	}
 	if err != nil {
 		if ctx.Err() == nil {
 			r.logger.ErrorContext(ctx, "subnet usage snapshot cycle failed", "err", err)
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestCipherRoundTripsAndDetectsTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	cipher, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := cipher.Encrypt([]byte("apiVersion: v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cipher.Encrypt([]byte("apiVersion: v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) || bytes.Contains(first, []byte("apiVersion")) {
+		t.Fatalf("ciphertexts must be randomized and opaque: %x %x", first, second)
+	}
+	plaintext, err := cipher.Decrypt(first)
+	if err != nil || string(plaintext) != "apiVersion: v1" {
+		t.Fatalf("unexpected round trip: %q, %v", plaintext, err)
+	}
+
+	first[len(first)-1] ^= 1
+	if _, err := cipher.Decrypt(first); err == nil {
+		t.Fatal("expected tampered ciphertext to be rejected")
+	}
+	other, err := NewCipher(bytes.Repeat([]byte{8}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Decrypt(second); err == nil {
+		t.Fatal("expected another key to be rejected")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))
+	if key, err := ParseKey(" " + encoded + "\n"); err != nil || len(key) != KeySize {
+		t.Fatalf("unexpected key: %v, %v", key, err)
+	}
+	for _, invalid := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseKey(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPollInterval  = 2 * time.Second
+	defaultPruneInterval = time.Hour
+	defaultBatchSize     = 50
+	defaultConcurrency   = 8
+	defaultTimeout       = 10 * time.Second
+	maxResponseBytes     = 64 << 10
+
+	HeaderEvent     = "X-IPAM-Event"
+	HeaderEventID   = "X-IPAM-Event-ID"
+	HeaderDelivery  = "X-IPAM-Delivery"
+	HeaderTimestamp = "X-IPAM-Timestamp"
+	HeaderSignature = "X-IPAM-Signature"
+)
+
+// Envelope is the JSON body posted to subscribers. Data is the changed row.
+type Envelope struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	ObjectType string          `json:"object_type"`
+	ObjectID   string          `json:"object_id"`
+	SiteID     *uuid.UUID      `json:"site_id,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type Dispatcher struct {
+	service       domain.WebhookService
+	client        *http.Client
+	logger        *slog.Logger
+	interval      time.Duration
+	pruneInterval time.Duration
+	batchSize     int32
+	concurrency   int
+	now           func() time.Time
+}
+
+func NewDispatcher(service domain.WebhookService, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		service:       service,
+		client:        &http.Client{Timeout: defaultTimeout},
+		logger:        logger,
+		interval:      defaultPollInterval,
+		pruneInterval: defaultPruneInterval,
+		batchSize:     defaultBatchSize,
+		concurrency:   defaultConcurrency,
+		now:           time.Now,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+			d.logger.ErrorContext(ctx, "webhook dispatch cycle failed", "err", err)
+		}
+		if d.now().Sub(lastPrune) >= d.pruneInterval {
+			lastPrune = d.now()
+			d.prune(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce claims due deliveries, posts them concurrently and records the
+// outcome of every attempt. It returns the number of deliveries attempted.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.service.ClaimDeliveries(ctx, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	semaphore := make(chan struct{}, d.concurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		semaphore <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-semaphore }()
+			statusCode, deliveryErr := d.deliver(ctx, delivery)
+			if deliveryErr != nil || statusCode < 200 || statusCode >= 300 {
+				d.logger.WarnContext(ctx, "webhook delivery failed",
+					"delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID,
+					"attempt", delivery.Attempts, "status", statusCode, "err", deliveryErr)
+			}
+			if err := d.service.RecordDeliveryAttempt(ctx, delivery, statusCode, deliveryErr); err != nil && ctx.Err() == nil {
+				d.logger.ErrorContext(ctx, "recording webhook delivery attempt", "delivery_id", delivery.ID, "err", err)
+			}
+		})
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(Envelope{
+		ID:         delivery.Event.ID,
+		Type:       delivery.Event.Type,
+		ObjectType: delivery.Event.ObjectType,
+		ObjectID:   delivery.Event.ObjectID,
+		SiteID:     delivery.Event.SiteID,
+		OccurredAt: delivery.Event.OccurredAt,
+		Data:       delivery.Event.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encoding webhook payload: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	sentAt := d.now()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "simple-k8s-app-webhooks")
+	request.Header.Set(HeaderEvent, delivery.Event.Type)
+	request.Header.Set(HeaderEventID, strconv.FormatInt(delivery.Event.ID, 10))
+	request.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(sentAt.Unix(), 10))
+	request.Header.Set(HeaderSignature, Sign(delivery.Secret, sentAt, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBytes))
+	return response.StatusCode, nil
+}
+
+func (d *Dispatcher) prune(ctx context.Context) {
+	deleted, err := d.service.PruneEvents(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.ErrorContext(ctx, "pruning webhook outbox", "err", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		d.logger.InfoContext(ctx, "pruned webhook outbox", "deleted", deleted)
+	}
+}
+
+// Sign returns the X-IPAM-Signature value for body: an HMAC-SHA256 over
+// "<unix timestamp>.<body>" keyed with the subscription secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign. Receivers should also reject
+// timestamps outside their replay window.
+func Verify(secret string, timestamp time.Time, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,136 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+type deliveryAttempt struct {
+	deliveryID int64
+	statusCode int
+	err        error
+}
+
+type stubWebhookService struct {
+	domain.WebhookService
+	deliveries []domain.WebhookDelivery
+	claimErr   error
+
+	mu       sync.Mutex
+	attempts []deliveryAttempt
+}
+
+func (s *stubWebhookService) ClaimDeliveries(context.Context, int32) ([]domain.WebhookDelivery, error) {
+	return s.deliveries, s.claimErr
+}
+
+func (s *stubWebhookService) RecordDeliveryAttempt(_ context.Context, delivery domain.WebhookDelivery, statusCode int, deliveryErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, deliveryAttempt{deliveryID: delivery.ID, statusCode: statusCode, err: deliveryErr})
+	return nil
+}
+
+func newTestDispatcher(service domain.WebhookService) *Dispatcher {
+	return NewDispatcher(service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestDispatchOnceDeliversSignedEnvelope(t *testing.T) {
+	siteID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	occurredAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	received := make(chan Envelope, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		unix, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil || !Verify("receiver-secret-1", time.Unix(unix, 0), body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("invalid signature %q for timestamp %q", r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp))
+		}
+		if r.Header.Get(HeaderEvent) != domain.EventIPCreated || r.Header.Get(HeaderDelivery) != "7" || r.Header.Get(HeaderEventID) != "99" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		var envelope Envelope
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			t.Errorf("decode envelope: %v", err)
+		}
+		received <- envelope
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	service := &stubWebhookService{deliveries: []domain.WebhookDelivery{{
+		ID: 7, URL: receiver.URL, Secret: "receiver-secret-1", Attempts: 1,
+		Event: domain.ChangeEvent{
+			ID: 99, Type: domain.EventIPCreated, ObjectType: "ip", ObjectID: "0b7c", SiteID: &siteID,
+			Payload: json.RawMessage(`{"ip":"10.0.0.10","hostname":"web-1"}`), OccurredAt: occurredAt,
+		},
+	}}}
+	attempted, err := newTestDispatcher(service).DispatchOnce(context.Background())
+	if err != nil || attempted != 1 {
+		t.Fatalf("dispatch: attempted=%d err=%v", attempted, err)
+	}
+
+	envelope := <-received
+	if envelope.ID != 99 || envelope.Type != domain.EventIPCreated || envelope.SiteID == nil || *envelope.SiteID != siteID || !envelope.OccurredAt.Equal(occurredAt) || string(envelope.Data) != `{"ip":"10.0.0.10","hostname":"web-1"}` {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+	if len(service.attempts) != 1 || service.attempts[0] != (deliveryAttempt{deliveryID: 7, statusCode: http.StatusNoContent}) {
+		t.Fatalf("unexpected attempts: %+v", service.attempts)
+	}
+}
+
+func TestDispatchOnceRecordsReceiverFailures(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	service := &stubWebhookService{deliveries: []domain.WebhookDelivery{
+		{ID: 1, URL: receiver.URL, Secret: "receiver-secret-1", Event: domain.ChangeEvent{ID: 1, Payload: json.RawMessage(`{}`)}},
+		{ID: 2, URL: unreachable.URL, Secret: "receiver-secret-1", Event: domain.ChangeEvent{ID: 2, Payload: json.RawMessage(`{}`)}},
+	}}
+	if _, err := newTestDispatcher(service).DispatchOnce(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	byID := map[int64]deliveryAttempt{}
+	for _, attempt := range service.attempts {
+		byID[attempt.deliveryID] = attempt
+	}
+	if byID[1].statusCode != http.StatusServiceUnavailable || byID[1].err != nil {
+		t.Fatalf("expected 503 to be recorded, got %+v", byID[1])
+	}
+	if byID[2].statusCode != 0 || byID[2].err == nil {
+		t.Fatalf("expected transport error to be recorded, got %+v", byID[2])
+	}
+}
+
+func TestDispatchOnceReturnsClaimError(t *testing.T) {
+	claimErr := errors.New("db unavailable")
+	if _, err := newTestDispatcher(&stubWebhookService{claimErr: claimErr}).DispatchOnce(context.Background()); !errors.Is(err, claimErr) {
+		t.Fatalf("expected claim error, got %v", err)
+	}
+}
+
+func TestVerifyRejectsTamperedBody(t *testing.T) {
+	sentAt := time.Unix(1700000000, 0)
+	signature := Sign("receiver-secret-1", sentAt, []byte(`{"id":1}`))
+	if !Verify("receiver-secret-1", sentAt, []byte(`{"id":1}`), signature) {
+		t.Fatal("expected signature to verify")
+	}
+	if Verify("receiver-secret-1", sentAt, []byte(`{"id":2}`), signature) || Verify("other-secret-123", sentAt, []byte(`{"id":1}`), signature) {
+		t.Fatal("expected tampered payload or wrong secret to fail verification")
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ClientCredentials obtains tokens with the OAuth 2.0 client credentials
+// grant, as used by service accounts in the API's OIDC provider.
+type ClientCredentials struct {
+	// IssuerURL is the OIDC issuer, such as
+	// https://keycloak.example.com/realms/ipam. The token endpoint is read
+	// from its discovery document unless TokenURL is set.
+	IssuerURL    string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient is used for discovery and token requests. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// TokenSource returns a source that caches tokens until shortly before they
+// expire. ctx is used for discovery and kept for later token requests, so it
+// should live as long as the client.
+func (c ClientCredentials) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	if c.ClientID == "" {
+		return nil, errors.New("client credentials: client id is required")
+	}
+	if c.HTTPClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.HTTPClient)
+	}
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		if c.IssuerURL == "" {
+			return nil, errors.New("client credentials: issuer url or token url is required")
+		}
+		endpoints, err := DiscoverOIDC(ctx, c.httpClient(), c.IssuerURL)
+		if err != nil {
+			return nil, err
+		}
+		tokenURL = endpoints.TokenURL
+	}
+	config := clientcredentials.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       c.Scopes,
+	}
+	return oauth2.ReuseTokenSource(nil, config.TokenSource(ctx)), nil
+}
+
+func (c ClientCredentials) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// OIDCEndpoints are the endpoints read from an issuer's discovery document.
+// DeviceAuthorizationURL is empty when the provider does not offer the
+// device authorization grant.
+type OIDCEndpoints struct {
+	TokenURL               string `json:"token_endpoint"`
+	DeviceAuthorizationURL string `json:"device_authorization_endpoint"`
+}
+
+// DiscoverOIDC reads issuer's /.well-known/openid-configuration.
+func DiscoverOIDC(ctx context.Context, httpClient *http.Client, issuer string) (OIDCEndpoints, error) {
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return OIDCEndpoints{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return OIDCEndpoints{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return OIDCEndpoints{}, fmt.Errorf("oidc discovery: unexpected status %d", resp.StatusCode)
+	}
+	var endpoints OIDCEndpoints
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return OIDCEndpoints{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if endpoints.TokenURL == "" {
+		return OIDCEndpoints{}, errors.New("oidc discovery: token_endpoint missing")
+	}
+	return endpoints, nil
+}
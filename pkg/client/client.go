@@ -0,0 +1,217 @@
+// Package client is a Go SDK for the IPAM API. It mirrors the JSON models
+// served under /api/v1, authenticates with a static bearer token or OIDC
+// client credentials, retries requests that are safe to repeat, and returns
+// API errors as *Error values that match the sentinels in this package.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	problemContentType   = "application/problem+json"
+	defaultUserAgent     = "ipam-go-client"
+	maxErrorBodyBytes    = 64 << 10
+)
+
+// Config configures a Client. Only BaseURL is required.
+type Config struct {
+	// BaseURL is the API root, such as https://ipam.example.com. Paths under
+	// /api/v1 are appended to it.
+	BaseURL string
+	// Token is a static bearer token. It is ignored when TokenSource is set.
+	Token string
+	// TokenSource supplies bearer tokens, for example from
+	// ClientCredentials.TokenSource.
+	TokenSource oauth2.TokenSource
+	HTTPClient  *http.Client
+	// Retry defaults to DefaultRetryPolicy.
+	Retry     *RetryPolicy
+	UserAgent string
+}
+
+// Client calls the IPAM API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	tokens     oauth2.TokenSource
+	retry      RetryPolicy
+	userAgent  string
+}
+
+func New(cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parse base url: %w", err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("base url %q must use http or https", cfg.BaseURL)
+	}
+	c := &Client{
+		baseURL:    baseURL,
+		httpClient: cfg.HTTPClient,
+		tokens:     cfg.TokenSource,
+		retry:      DefaultRetryPolicy(),
+		userAgent:  cfg.UserAgent,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.tokens == nil && cfg.Token != "" {
+		c.tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.Token, TokenType: "Bearer"})
+	}
+	if cfg.Retry != nil {
+		c.retry = *cfg.Retry
+	}
+	if c.userAgent == "" {
+		c.userAgent = defaultUserAgent
+	}
+	return c, nil
+}
+
+// Healthz reports whether the API process is up.
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodGet, path: "/healthz"}, nil)
+}
+
+// Readyz reports whether the API can reach its database.
+func (c *Client) Readyz(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodGet, path: "/readyz"}, nil)
+}
+
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	// idempotent sends a fresh Idempotency-Key, reused across retries.
+	idempotent bool
+}
+
+func jsonRequest(method, path string, body any) (request, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return request{}, fmt.Errorf("encode request: %w", err)
+	}
+	return request{method: method, path: path, body: encoded, contentType: "application/json"}, nil
+}
+
+// do sends req and decodes a successful JSON response into out, if set.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send returns the first successful response, retrying per the client's
+// policy. Error statuses are returned as *Error.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var idempotencyKey string
+	if req.idempotent {
+		idempotencyKey = uuid.NewString()
+	}
+	for attempt := 1; ; attempt++ {
+		httpReq, err := c.newHTTPRequest(ctx, req, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		retry := attempt < c.retry.MaxAttempts && retryableMethod(httpReq)
+		resp, err := c.httpClient.Do(httpReq)
+		switch {
+		case err != nil:
+			if !retry || ctx.Err() != nil {
+				return nil, fmt.Errorf("%s %s: %w", req.method, req.path, err)
+			}
+		case resp.StatusCode < http.StatusBadRequest:
+			return resp, nil
+		case !retry || !retryableStatus(resp.StatusCode):
+			return nil, decodeError(resp)
+		}
+
+		wait := c.retry.delay(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodyBytes))
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, req request, idempotencyKey string) (*http.Request, error) {
+	target := c.baseURL.JoinPath(req.path)
+	target.RawQuery = req.query.Encode()
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json, "+problemContentType)
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if idempotencyKey != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("obtain token: %w", err)
+		}
+		token.SetAuthHeader(httpReq)
+	}
+	return httpReq, nil
+}
+
+func decodeError(resp *http.Response) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	if err != nil {
+		return fmt.Errorf("read error response: %w", err)
+	}
+	apiErr := &Error{}
+	if json.Unmarshal(body, apiErr) != nil || apiErr.Type == "" {
+		apiErr = &Error{
+			Type:   "about:blank",
+			Title:  http.StatusText(resp.StatusCode),
+			Detail: strings.TrimSpace(string(body)),
+		}
+	}
+	apiErr.Status = resp.StatusCode
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return apiErr
+}
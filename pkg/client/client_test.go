@@ -0,0 +1,562 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	apihttp "github.com/Flarenzy/simple-k8s-app/internal/http"
+	"github.com/google/uuid"
+)
+
+const testToken = "sdk-token"
+
+type testServer struct {
+	*httptest.Server
+	network  *fakeNetworkService
+	sites    *fakeSitesService
+	imports  *fakeImportService
+	webhooks *fakeWebhookService
+	dns      *fakeDNSService
+	sources  *fakeKubernetesSourceService
+}
+
+// newTestServer serves the real API router over in-memory services. wrap, if
+// set, sits in front of the router to inject failures.
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *testServer {
+	t.Helper()
+	s := &testServer{
+		network:  newFakeNetworkService(),
+		sites:    newFakeSitesService(),
+		imports:  &fakeImportService{},
+		webhooks: newFakeWebhookService(),
+		dns:      newFakeDNSService(),
+		sources:  &fakeKubernetesSourceService{sources: map[string]domain.KubernetesSourceStatus{}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	api := apihttp.NewAPI(logger, fakeHealth{}, s.network, s.sites, tokenAuthenticator(testToken))
+	api.ImportService = s.imports
+	api.LeaseImportService = domain.NewLeaseImportService(s.network)
+	api.ZoneImportService = domain.NewZoneImportService(s.network)
+	api.DiscoveryService = fakeDiscoveryService{}
+	api.KubernetesSourceService = s.sources
+	api.KubernetesReconciler = fakeKubernetesReconciler{}
+	api.ReportingService = &fakeReportingService{settings: domain.ReportingSettings{Cadence: domain.ReportingCadenceDaily, RetentionDays: 30}}
+	api.WebhookService = s.webhooks
+	api.DNSService = s.dns
+	api.DHCPService = fakeDHCPService{network: s.network}
+	siteID := uuid.New()
+	api.EventStream = fakeEventStream{events: []domain.ChangeEvent{
+		{ID: 1, Type: domain.EventSubnetCreated, ObjectType: domain.ObjectTypeSubnet, ObjectID: "1", SiteID: &siteID, OccurredAt: fakeNow},
+		{ID: 2, Type: domain.EventIPCreated, ObjectType: domain.ObjectTypeIP, ObjectID: "abc", SiteID: &siteID, OccurredAt: fakeNow},
+	}}
+	handler := api.Router()
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	s.Server = httptest.NewServer(handler)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) client(t *testing.T, cfg Config) *Client {
+	t.Helper()
+	cfg.BaseURL = s.URL
+	if cfg.Token == "" && cfg.TokenSource == nil {
+		cfg.Token = testToken
+	}
+	if cfg.Retry == nil {
+		cfg.Retry = &RetryPolicy{MaxAttempts: 3, MinDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return c
+}
+
+func TestClientManagesSitesSubnetsAndIPs(t *testing.T) {
+	ctx := context.Background()
+	c := newTestServer(t, nil).client(t, Config{})
+
+	site, err := c.CreateSite(ctx, SiteRequest{Name: "Belgrade", Description: "office"})
+	if err != nil {
+		t.Fatalf("create site: %v", err)
+	}
+	if site, err = c.UpdateSite(ctx, site.ID, SiteRequest{Name: "Belgrade", Description: "hq"}); err != nil || site.Description != "hq" {
+		t.Fatalf("update site: %+v, %v", site, err)
+	}
+	subnet, err := c.CreateSubnet(ctx, SubnetRequest{CIDR: "10.0.0.0/24", SiteID: &site.ID, Description: "office"})
+	if err != nil {
+		t.Fatalf("create subnet: %v", err)
+	}
+	if subnet.CIDR != "10.0.0.0/24" || subnet.TotalIPs != 256 || *subnet.SiteID != site.ID {
+		t.Fatalf("unexpected subnet: %+v", subnet)
+	}
+	ip, err := c.CreateIP(ctx, subnet.ID, CreateIPRequest{IP: "10.0.0.10", Hostname: "printer"})
+	if err != nil {
+		t.Fatalf("create ip: %v", err)
+	}
+	if ip, err = c.UpdateIP(ctx, subnet.ID, ip.ID, UpdateIPRequest{Hostname: "printer-2"}); err != nil || ip.Hostname != "printer-2" {
+		t.Fatalf("update ip: %+v, %v", ip, err)
+	}
+	ips, err := c.ListIPs(ctx, subnet.ID)
+	if err != nil || len(ips) != 1 || ips[0].IP != "10.0.0.10" {
+		t.Fatalf("list ips: %+v, %v", ips, err)
+	}
+	if subnet, err = c.GetSubnet(ctx, subnet.ID); err != nil || subnet.UsedIPs != 1 {
+		t.Fatalf("get subnet: %+v, %v", subnet, err)
+	}
+	otherSite, err := c.CreateSite(ctx, SiteRequest{Name: "Novi Sad"})
+	if err != nil {
+		t.Fatalf("create second site: %v", err)
+	}
+	if subnet, err = c.AssignSubnetSite(ctx, subnet.ID, otherSite.ID); err != nil || *subnet.SiteID != otherSite.ID {
+		t.Fatalf("assign subnet site: %+v, %v", subnet, err)
+	}
+	if subnet, err = c.UpdateSubnet(ctx, subnet.ID, SubnetRequest{CIDR: "10.0.0.0/24", Description: "moved"}); err != nil || subnet.Description != "moved" {
+		t.Fatalf("update subnet: %+v, %v", subnet, err)
+	}
+	statistics, err := c.SiteStatistics(ctx)
+	if err != nil || len(statistics) != 2 || statistics[0].TotalIPCount != 256 {
+		t.Fatalf("site statistics: %+v, %v", statistics, err)
+	}
+
+	var listed []Subnet
+	for subnet, err := range All(ctx, c.ListSubnets) {
+		if err != nil {
+			t.Fatalf("list subnets: %v", err)
+		}
+		listed = append(listed, subnet)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("expected one subnet, got %+v", listed)
+	}
+
+	if err := c.DeleteIP(ctx, subnet.ID, ip.ID); err != nil {
+		t.Fatalf("delete ip: %v", err)
+	}
+	if err := c.DeleteSubnet(ctx, subnet.ID); err != nil {
+		t.Fatalf("delete subnet: %v", err)
+	}
+	if err := c.DeleteSite(ctx, site.ID); err != nil {
+		t.Fatalf("delete site: %v", err)
+	}
+	if _, err := c.GetSite(ctx, site.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected deleted site to be not found, got %v", err)
+	}
+}
+
+func TestClientReturnsProblemDetailsAsErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newTestServer(t, nil).client(t, Config{})
+
+	_, err := c.GetSubnet(ctx, 404)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found problem, got %v", err)
+	}
+	if apiErr.Status != http.StatusNotFound || apiErr.RequestID == "" || apiErr.Instance != "/api/v1/subnets/404" {
+		t.Fatalf("unexpected problem: %+v", apiErr)
+	}
+
+	siteID := uuid.New()
+	_, err = c.CreateSubnet(ctx, SubnetRequest{CIDR: "not-a-cidr", SiteID: &siteID})
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation problem, got %v", err)
+	}
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0].Field != "cidr" {
+		t.Fatalf("unexpected field errors: %+v", apiErr.Errors)
+	}
+
+	subnet, err := c.CreateSubnet(ctx, SubnetRequest{CIDR: "10.1.0.0/24", SiteID: &siteID})
+	if err != nil {
+		t.Fatalf("create subnet: %v", err)
+	}
+	if _, err := c.CreateIP(ctx, subnet.ID, CreateIPRequest{IP: "10.1.0.1"}); err != nil {
+		t.Fatalf("create ip: %v", err)
+	}
+	if _, err := c.CreateIP(ctx, subnet.ID, CreateIPRequest{IP: "10.1.0.1"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected conflict for duplicate ip, got %v", err)
+	}
+}
+
+func TestClientReportingKubernetesAndImport(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+	c := server.client(t, Config{})
+
+	settings, err := c.UpdateReportingSettings(ctx, ReportingSettingsRequest{Cadence: "hourly", RetentionDays: 7})
+	if err != nil || settings.Cadence != "hourly" || settings.RetentionDays != 7 {
+		t.Fatalf("update reporting settings: %+v, %v", settings, err)
+	}
+	if settings, err = c.GetReportingSettings(ctx); err != nil || settings.Cadence != "hourly" {
+		t.Fatalf("get reporting settings: %+v, %v", settings, err)
+	}
+	if _, err := c.UpdateReportingSettings(ctx, ReportingSettingsRequest{Cadence: "hourly"}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	history, err := c.GetSubnetUsageHistory(ctx, 4, "30d")
+	if err != nil || history.Range != "30d" || len(history.Points) != 1 {
+		t.Fatalf("usage history: %+v, %v", history, err)
+	}
+
+	sources, err := c.ListKubernetesSources(ctx)
+	if err != nil || len(sources) != 1 || sources[0].Source.Key != "prod" {
+		t.Fatalf("kubernetes sources: %+v, %v", sources, err)
+	}
+	siteID := uuid.New()
+	subnet, err := c.CreateSubnet(ctx, SubnetRequest{CIDR: "10.2.0.0/24", SiteID: &siteID})
+	if err != nil {
+		t.Fatalf("create subnet: %v", err)
+	}
+	services, err := c.ListSubnetKubernetesServices(ctx, subnet.ID)
+	if err != nil || len(services) != 1 || services[0].MatchStatus != "matched" {
+		t.Fatalf("subnet kubernetes services: %+v, %v", services, err)
+	}
+	objects, err := c.ListSubnetKubernetesObjects(ctx, subnet.ID)
+	if err != nil || len(objects) != 1 || !objects[0].HostNetwork || objects[0].Addresses[0].NodeName != "worker-1" {
+		t.Fatalf("subnet kubernetes objects: %+v, %v", objects, err)
+	}
+
+	csv := "site,cidr,ip,description\nedge,10.3.0.0/24,,\n"
+	result, err := c.ImportCSV(ctx, "/tmp/import.csv", strings.NewReader(csv))
+	if err != nil || result.Created != 1 {
+		t.Fatalf("import csv: %+v, %v", result, err)
+	}
+	if server.imports.received != csv {
+		t.Fatalf("expected uploaded csv to reach the service, got %q", server.imports.received)
+	}
+}
+
+func TestClientImportsLeases(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+	c := server.client(t, Config{})
+
+	siteID := uuid.New()
+	subnet, err := c.CreateSubnet(ctx, SubnetRequest{CIDR: "10.4.0.0/24", SiteID: &siteID})
+	if err != nil {
+		t.Fatalf("create subnet: %v", err)
+	}
+	leases := "0 52:54:00:12:34:56 10.4.0.20 nas *\n"
+	preview, err := c.ImportLeases(ctx, LeaseFormatDnsmasq, "dnsmasq.leases", strings.NewReader(leases), true)
+	if err != nil || preview.Created != 1 {
+		t.Fatalf("preview leases: %+v, %v", preview, err)
+	}
+	if ips, err := c.ListIPs(ctx, subnet.ID); err != nil || len(ips) != 0 {
+		t.Fatalf("expected the dry run to write nothing, got %+v, %v", ips, err)
+	}
+
+	result, err := c.ImportLeases(ctx, LeaseFormatDnsmasq, "dnsmasq.leases", strings.NewReader(leases), false)
+	if err != nil || result.Created != 1 {
+		t.Fatalf("import leases: %+v, %v", result, err)
+	}
+	ips, err := c.ListIPs(ctx, subnet.ID)
+	if err != nil || len(ips) != 1 || ips[0].Hostname != "nas" || ips[0].MACAddress != "52:54:00:12:34:56" || ips[0].LastSeenAt == nil {
+		t.Fatalf("unexpected addresses: %+v, %v", ips, err)
+	}
+
+	_, err = c.ImportLeases(ctx, "leases.txt", "dnsmasq.leases", strings.NewReader(leases), false)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected a validation error for an unknown format, got %v", err)
+	}
+}
+
+func TestClientImportsZone(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+	c := server.client(t, Config{})
+
+	siteID := uuid.New()
+	subnet, err := c.CreateSubnet(ctx, SubnetRequest{CIDR: "10.5.0.0/24", SiteID: &siteID})
+	if err != nil {
+		t.Fatalf("create subnet: %v", err)
+	}
+	zone := "www IN A 10.5.0.80\nfar IN A 192.0.2.1\n"
+	result, err := c.ImportZone(ctx, "example.com", "db.example.com", strings.NewReader(zone), false)
+	if err != nil || result.Created != 1 || len(result.Errors) != 1 || result.Errors[0].Row != 2 {
+		t.Fatalf("import zone: %+v, %v", result, err)
+	}
+	ips, err := c.ListIPs(ctx, subnet.ID)
+	if err != nil || len(ips) != 1 || ips[0].Hostname != "www.example.com" {
+		t.Fatalf("unexpected addresses: %+v, %v", ips, err)
+	}
+}
+
+func TestClientManagesWebhooks(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+	c := server.client(t, Config{})
+
+	created, err := c.CreateWebhook(ctx, WebhookSubscriptionRequest{URL: "https://cmdb.example.com/hook", EventTypes: []string{"ip.*"}})
+	if err != nil || created.Secret == "" || !created.Active {
+		t.Fatalf("create webhook: %+v, %v", created, err)
+	}
+	inactive := false
+	updated, err := c.UpdateWebhook(ctx, created.ID, UpdateWebhookSubscriptionRequest{Active: &inactive})
+	if err != nil || updated.Active {
+		t.Fatalf("update webhook: %+v, %v", updated, err)
+	}
+	if got, err := c.GetWebhook(ctx, created.ID); err != nil || got.URL != created.URL {
+		t.Fatalf("get webhook: %+v, %v", got, err)
+	}
+	if list, err := c.ListWebhooks(ctx); err != nil || len(list) != 1 {
+		t.Fatalf("list webhooks: %+v, %v", list, err)
+	}
+	deadLetters, err := c.ListWebhookDeadLetters(ctx, 25)
+	if err != nil || len(deadLetters) != 1 || deadLetters[0].ID != 25 {
+		t.Fatalf("dead letters: %+v, %v", deadLetters, err)
+	}
+	if err := c.RetryWebhookDelivery(ctx, 12); err != nil || len(server.webhooks.retried) != 1 || server.webhooks.retried[0] != 12 {
+		t.Fatalf("retry delivery: %v, %v", server.webhooks.retried, err)
+	}
+	if err := c.DeleteWebhook(ctx, created.ID); err != nil {
+		t.Fatalf("delete webhook: %v", err)
+	}
+}
+
+func TestClientManagesKubernetesSources(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+	c := server.client(t, Config{})
+
+	interval := int32(60)
+	created, err := c.CreateKubernetesSource(ctx, KubernetesSourceRequest{Key: "prod-a", SiteID: uuid.New(), Namespaces: []string{"apps"}, IntervalSeconds: &interval})
+	if err != nil || !created.Managed || created.Settings == nil || created.Settings.IntervalSeconds != 60 {
+		t.Fatalf("create source: %+v, %v", created, err)
+	}
+	namespaces := []string{"*"}
+	updated, err := c.UpdateKubernetesSource(ctx, "prod-a", UpdateKubernetesSourceRequest{Namespaces: namespaces})
+	if err != nil || len(updated.Namespaces) != 1 || updated.Namespaces[0] != "*" {
+		t.Fatalf("update source: %+v, %v", updated, err)
+	}
+	if err := c.DeleteKubernetesSource(ctx, "prod-a"); err != nil {
+		t.Fatalf("delete source: %v", err)
+	}
+	if err := c.DeleteKubernetesSource(ctx, "prod-a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestClientReconcilesKubernetesSource(t *testing.T) {
+	ctx := context.Background()
+	c := newTestServer(t, nil).client(t, Config{})
+
+	result, err := c.ReconcileKubernetesSource(ctx, "prod")
+	if err != nil || result != (KubernetesReconcileResult{Source: "prod", Services: 3, Matched: 2, Unmatched: 1}) {
+		t.Fatalf("reconcile source: %+v, %v", result, err)
+	}
+	if _, err := c.ReconcileKubernetesSource(ctx, "busy"); !errors.Is(err, ErrDiscoveryBusy) {
+		t.Fatalf("expected discovery busy, got %v", err)
+	}
+	if _, err := c.ReconcileKubernetesSource(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestClientListsKubernetesSourceHistory(t *testing.T) {
+	ctx := context.Background()
+	c := newTestServer(t, nil).client(t, Config{})
+
+	runs, err := c.ListKubernetesSourceRuns(ctx, "prod", 1)
+	if err != nil || len(runs) != 1 || runs[0].ID != 2 || runs[0].Services != 3 || runs[0].Changes != 1 {
+		t.Fatalf("list runs: %+v, %v", runs, err)
+	}
+	events, err := c.ListKubernetesSourceEvents(ctx, "prod", 2, 0)
+	if err != nil || len(events) != 1 || events[0].Change != "address_changed" || events[0].PreviousAddresses[0] != "10.96.0.4" || events[0].Addresses[0] != "10.96.0.9" {
+		t.Fatalf("list events: %+v, %v", events, err)
+	}
+	if _, err := c.ListKubernetesSourceRuns(ctx, "missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestClientManagesDNSZones(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+	c := server.client(t, Config{})
+
+	siteID := uuid.New()
+	created, err := c.CreateDNSZone(ctx, DNSZoneRequest{Name: "lab.example.com", SiteID: &siteID})
+	if err != nil || created.ID == nil || created.Kind != "forward" || *created.SiteID != siteID {
+		t.Fatalf("create zone: %+v, %v", created, err)
+	}
+	if got, err := c.GetDNSZone(ctx, "lab.example.com"); err != nil || got.Name != created.Name {
+		t.Fatalf("get zone: %+v, %v", got, err)
+	}
+	if list, err := c.ListDNSZones(ctx); err != nil || len(list) != 1 {
+		t.Fatalf("list zones: %+v, %v", list, err)
+	}
+	zoneFile, err := c.ExportDNSZone(ctx, "lab.example.com")
+	if err != nil || !strings.Contains(string(zoneFile), "$ORIGIN lab.example.com.\n") || !strings.Contains(string(zoneFile), "web-1\tIN\tA\t10.0.0.5\n") {
+		t.Fatalf("export zone: %q, %v", zoneFile, err)
+	}
+	if err := c.DeleteDNSZone(ctx, "lab.example.com"); err != nil {
+		t.Fatalf("delete zone: %v", err)
+	}
+	if _, err := c.ExportDNSZone(ctx, "lab.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestClientExportsDHCPConfig(t *testing.T) {
+	ctx := context.Background()
+	c := newTestServer(t, nil).client(t, Config{})
+
+	siteID := uuid.New()
+	subnet, err := c.CreateSubnet(ctx, SubnetRequest{CIDR: "10.0.0.0/24", SiteID: &siteID})
+	if err != nil {
+		t.Fatalf("create subnet: %v", err)
+	}
+	updated, err := c.UpdateSubnetDHCP(ctx, subnet.ID, SubnetDHCPRequest{
+		Gateway:    "10.0.0.1",
+		DNSServers: []string{"10.0.0.53"},
+		Pools:      []DHCPPool{{Start: "10.0.0.100", End: "10.0.0.199"}},
+	})
+	if err != nil || updated.Gateway != "10.0.0.1" || len(updated.DHCPPools) != 1 {
+		t.Fatalf("update dhcp: %+v, %v", updated, err)
+	}
+	ip, err := c.CreateIP(ctx, subnet.ID, CreateIPRequest{IP: "10.0.0.10", Hostname: "printer", MACAddress: "52:54:00:12:34:56"})
+	if err != nil || ip.MACAddress != "52:54:00:12:34:56" {
+		t.Fatalf("create ip: %+v, %v", ip, err)
+	}
+
+	kea, err := c.SubnetDHCPConfig(ctx, subnet.ID, "")
+	if err != nil || !strings.Contains(string(kea), `"pool": "10.0.0.100 - 10.0.0.199"`) || !strings.Contains(string(kea), `"hw-address": "52:54:00:12:34:56"`) {
+		t.Fatalf("kea config: %s, %v", kea, err)
+	}
+	dnsmasq, err := c.SiteDHCPConfig(ctx, siteID, DHCPFormatDnsmasq)
+	if err != nil || !strings.Contains(string(dnsmasq), "dhcp-host=52:54:00:12:34:56,10.0.0.10,printer\n") {
+		t.Fatalf("dnsmasq config: %s, %v", dnsmasq, err)
+	}
+	if _, err := c.SubnetDHCPConfig(ctx, subnet.ID, "isc"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for unknown format, got %v", err)
+	}
+}
+
+func TestClientStreamsEvents(t *testing.T) {
+	c := newTestServer(t, nil).client(t, Config{})
+
+	var received []ChangeEvent
+	err := c.StreamEvents(context.Background(), EventStreamOptions{Types: []string{"ip"}}, func(event ChangeEvent) error {
+		received = append(received, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream events: %v", err)
+	}
+	if len(received) != 1 || received[0].Type != domain.EventIPCreated || received[0].ID != 2 {
+		t.Fatalf("unexpected events: %+v", received)
+	}
+}
+
+func TestClientHealthAndAuthentication(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t, nil)
+
+	if err := server.client(t, Config{}).Readyz(ctx); err != nil {
+		t.Fatalf("readyz: %v", err)
+	}
+	anonymous := server.client(t, Config{Token: "wrong"})
+	if err := anonymous.Healthz(ctx); err != nil {
+		t.Fatalf("healthz should not need a token: %v", err)
+	}
+	if _, err := anonymous.ListSites(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestClientCredentialsDiscoverTokenEndpoint(t *testing.T) {
+	var tokenRequests int
+	var mu sync.Mutex
+	var provider *httptest.Server
+	provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/realms/ipam/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{"token_endpoint": provider.URL + "/realms/ipam/token"})
+		case "/realms/ipam/token":
+			id, secret, _ := r.BasicAuth()
+			if id != "ipam-sync" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" {
+				http.Error(w, "bad client", http.StatusUnauthorized)
+				return
+			}
+			mu.Lock()
+			tokenRequests++
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"` + testToken + `","token_type":"Bearer","expires_in":300}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer provider.Close()
+
+	tokens, err := ClientCredentials{
+		IssuerURL: provider.URL + "/realms/ipam", ClientID: "ipam-sync", ClientSecret: "s3cret",
+	}.TokenSource(context.Background())
+	if err != nil {
+		t.Fatalf("token source: %v", err)
+	}
+	c := newTestServer(t, nil).client(t, Config{TokenSource: tokens})
+	for range 2 {
+		if _, err := c.ListSites(context.Background()); err != nil {
+			t.Fatalf("list sites with client credentials: %v", err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Fatalf("expected the token to be cached, got %d token requests", tokenRequests)
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var mu sync.Mutex
+	failures := map[string]int{}
+	keys := map[string][]string{}
+	flaky := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.Method + " " + r.URL.Path
+			mu.Lock()
+			keys[route] = append(keys[route], r.Header.Get("Idempotency-Key"))
+			fail := failures[route] < 1
+			failures[route]++
+			mu.Unlock()
+			if fail {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	c := newTestServer(t, flaky).client(t, Config{})
+	ctx := context.Background()
+
+	if _, err := c.ListSites(ctx); err != nil {
+		t.Fatalf("expected GET to be retried: %v", err)
+	}
+	if _, err := c.CreateSite(ctx, SiteRequest{Name: "edge"}); err != nil {
+		t.Fatalf("expected keyed POST to be retried: %v", err)
+	}
+	siteKeys := keys["POST /api/v1/sites"]
+	if len(siteKeys) != 2 || siteKeys[0] == "" || siteKeys[0] != siteKeys[1] {
+		t.Fatalf("expected retries to reuse one idempotency key, got %q", siteKeys)
+	}
+	_, err := c.CreateWebhook(ctx, WebhookSubscriptionRequest{URL: "https://example.com/hook", EventTypes: []string{"*"}})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Fatalf("expected webhook create not to be retried, got %v", err)
+	}
+	if failures["POST /api/v1/webhooks"] != 1 {
+		t.Fatalf("expected one webhook create attempt, got %d", failures["POST /api/v1/webhooks"])
+	}
+}
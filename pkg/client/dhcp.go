@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// DHCP configuration formats accepted by SubnetDHCPConfig and SiteDHCPConfig.
+const (
+	DHCPFormatKea     = "kea"
+	DHCPFormatDnsmasq = "dnsmasq"
+)
+
+func (c *Client) UpdateSubnetDHCP(ctx context.Context, id int64, input SubnetDHCPRequest) (Subnet, error) {
+	req, err := jsonRequest(http.MethodPatch, subnetPath(id)+"/dhcp", input)
+	if err != nil {
+		return Subnet{}, err
+	}
+	var subnet Subnet
+	err = c.do(ctx, req, &subnet)
+	return subnet, err
+}
+
+// SubnetDHCPConfig renders the subnet for a DHCP server. An empty format
+// selects Kea.
+func (c *Client) SubnetDHCPConfig(ctx context.Context, id int64, format string) ([]byte, error) {
+	return c.dhcpConfig(ctx, subnetPath(id)+"/dhcp-config", format)
+}
+
+// SiteDHCPConfig renders every subnet of the site for a DHCP server. An
+// empty format selects Kea.
+func (c *Client) SiteDHCPConfig(ctx context.Context, siteID uuid.UUID, format string) ([]byte, error) {
+	return c.dhcpConfig(ctx, sitePath(siteID)+"/dhcp-config", format)
+}
+
+func (c *Client) dhcpConfig(ctx context.Context, path, format string) ([]byte, error) {
+	req := request{method: http.MethodGet, path: path}
+	if format != "" {
+		req.query = url.Values{"format": {format}}
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	config, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s %s response: %w", req.method, req.path, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+func dnsZonePath(name string) string {
+	return "/api/v1/dns/zones/" + url.PathEscape(name)
+}
+
+func (c *Client) ListDNSZones(ctx context.Context) ([]DNSZone, error) {
+	var zones []DNSZone
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/dns/zones"}, &zones)
+	return zones, err
+}
+
+func (c *Client) GetDNSZone(ctx context.Context, name string) (DNSZone, error) {
+	var zone DNSZone
+	err := c.do(ctx, request{method: http.MethodGet, path: dnsZonePath(name)}, &zone)
+	return zone, err
+}
+
+func (c *Client) CreateDNSZone(ctx context.Context, input DNSZoneRequest) (DNSZone, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/v1/dns/zones", input)
+	if err != nil {
+		return DNSZone{}, err
+	}
+	var zone DNSZone
+	err = c.do(ctx, req, &zone)
+	return zone, err
+}
+
+func (c *Client) DeleteDNSZone(ctx context.Context, name string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: dnsZonePath(name)}, nil)
+}
+
+// ExportDNSZone returns the zone as a BIND master file.
+func (c *Client) ExportDNSZone(ctx context.Context, name string) ([]byte, error) {
+	req := request{method: http.MethodGet, path: dnsZonePath(name) + "/export"}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	zoneFile, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s %s response: %w", req.method, req.path, err)
+	}
+	return zoneFile, nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors matched by *Error through errors.Is, one per documented
+// problem type in docs/problems.md.
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrValidation           = errors.New("validation failed")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrIPv6Unsupported      = errors.New("ipv6 unsupported")
+	ErrDiscoveryBusy        = errors.New("discovery busy")
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+	ErrRateLimited          = errors.New("rate limited")
+	ErrServiceUnavailable   = errors.New("service unavailable")
+	ErrInternal             = errors.New("internal server error")
+)
+
+var problemErrors = map[string]error{
+	"bad-request":            ErrBadRequest,
+	"validation-failed":      ErrValidation,
+	"unauthorized":           ErrUnauthorized,
+	"forbidden":              ErrForbidden,
+	"not-found":              ErrNotFound,
+	"conflict":               ErrConflict,
+	"ipv6-unsupported":       ErrIPv6Unsupported,
+	"discovery-busy":         ErrDiscoveryBusy,
+	"idempotency-key-reused": ErrIdempotencyKeyReused,
+	"rate-limited":           ErrRateLimited,
+	"service-unavailable":    ErrServiceUnavailable,
+	"internal-error":         ErrInternal,
+}
+
+// Error is an RFC 9457 problem details response. Responses that are not
+// problem documents, such as a proxy's error page, are reported with Type
+// "about:blank" and the body as Detail.
+type Error struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError names one rejected request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	message := e.Detail
+	if message == "" {
+		message = e.Title
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("ipam api: %d %s (request %s)", e.Status, message, e.RequestID)
+	}
+	return fmt.Sprintf("ipam api: %d %s", e.Status, message)
+}
+
+// Is reports whether target is the sentinel for the problem type.
+func (e *Error) Is(target error) bool {
+	_, slug, ok := strings.Cut(e.Type, "#")
+	return ok && problemErrors[slug] == target
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// EventStreamOptions filters StreamEvents. Empty fields match everything.
+type EventStreamOptions struct {
+	// Types are object types: subnet, ip, site, kubernetes or reporting.
+	Types  []string
+	SiteID *uuid.UUID
+}
+
+// StreamEvents follows the live change stream and calls handle for each
+// event until ctx is cancelled, the server closes the stream, or handle
+// returns an error. It returns nil when ctx is cancelled. The connection is
+// not re-established; callers that need continuity should call it again and
+// refetch the objects they display.
+func (c *Client) StreamEvents(ctx context.Context, opts EventStreamOptions, handle func(ChangeEvent) error) error {
+	req := request{method: http.MethodGet, path: "/api/v1/events/stream", query: url.Values{}}
+	if len(opts.Types) > 0 {
+		req.query.Set("types", strings.Join(opts.Types, ","))
+	}
+	if opts.SiteID != nil {
+		req.query.Set("site_id", opts.SiteID.String())
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var event ChangeEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("decode event: %w", err)
+			}
+			data.Reset()
+			if err := handle(event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) && ctx.Err() == nil {
+		return fmt.Errorf("read event stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,613 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	apiauth "github.com/Flarenzy/simple-k8s-app/internal/auth"
+	"github.com/Flarenzy/simple-k8s-app/internal/domain"
+	"github.com/google/uuid"
+)
+
+var fakeNow = time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+
+type fakeNetworkService struct {
+	mu      sync.Mutex
+	nextID  int64
+	subnets map[int64]domain.Subnet
+	ips     map[int64][]domain.IPAddress
+}
+
+func newFakeNetworkService() *fakeNetworkService {
+	return &fakeNetworkService{subnets: map[int64]domain.Subnet{}, ips: map[int64][]domain.IPAddress{}}
+}
+
+func (s *fakeNetworkService) ListSubnets(context.Context) ([]domain.Subnet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subnets := make([]domain.Subnet, 0, len(s.subnets))
+	for id := int64(1); id <= s.nextID; id++ {
+		if subnet, ok := s.subnets[id]; ok {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets, nil
+}
+
+func (s *fakeNetworkService) CreateSubnet(_ context.Context, input domain.CreateSubnetInput) (domain.Subnet, error) {
+	prefix, err := netip.ParsePrefix(input.CIDR)
+	if err != nil {
+		return domain.Subnet{}, domain.InvalidField("cidr", "invalid cidr")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	subnet := domain.Subnet{
+		ID: s.nextID, CIDR: prefix, SiteID: *input.SiteID, Description: input.Description,
+		TotalIPCount: 1 << (prefix.Addr().BitLen() - prefix.Bits()), CreatedAt: fakeNow, UpdatedAt: fakeNow,
+	}
+	s.subnets[subnet.ID] = subnet
+	return subnet, nil
+}
+
+func (s *fakeNetworkService) UpdateSubnet(_ context.Context, input domain.UpdateSubnetInput) (domain.Subnet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subnet, ok := s.subnets[input.ID]
+	if !ok {
+		return domain.Subnet{}, domain.ErrNotFound
+	}
+	subnet.Description = input.Description
+	s.subnets[input.ID] = subnet
+	return subnet, nil
+}
+
+func (s *fakeNetworkService) AssignSubnetSite(_ context.Context, input domain.AssignSubnetSiteInput) (domain.Subnet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subnet, ok := s.subnets[input.ID]
+	if !ok {
+		return domain.Subnet{}, domain.ErrNotFound
+	}
+	subnet.SiteID = input.SiteID
+	s.subnets[input.ID] = subnet
+	return subnet, nil
+}
+
+func (s *fakeNetworkService) GetSubnet(_ context.Context, id int64) (domain.Subnet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subnet, ok := s.subnets[id]
+	if !ok {
+		return domain.Subnet{}, domain.ErrNotFound
+	}
+	subnet.UsedIPCount = int64(len(s.ips[id]))
+	return subnet, nil
+}
+
+func (s *fakeNetworkService) DeleteSubnet(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subnets[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(s.subnets, id)
+	delete(s.ips, id)
+	return nil
+}
+
+func (s *fakeNetworkService) ListIPs(_ context.Context, subnetID int64) ([]domain.IPAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subnets[subnetID]; !ok {
+		return nil, domain.ErrNotFound
+	}
+	return append([]domain.IPAddress(nil), s.ips[subnetID]...), nil
+}
+
+func (s *fakeNetworkService) CreateIP(_ context.Context, subnetID int64, input domain.CreateIPInput) (domain.IPAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subnet, ok := s.subnets[subnetID]
+	if !ok {
+		return domain.IPAddress{}, domain.ErrNotFound
+	}
+	addr, err := netip.ParseAddr(input.IP)
+	if err != nil || !subnet.CIDR.Contains(addr) {
+		return domain.IPAddress{}, domain.InvalidField("ip", "ip is outside the subnet")
+	}
+	for _, existing := range s.ips[subnetID] {
+		if existing.IP == addr {
+			return domain.IPAddress{}, domain.ErrConflict
+		}
+	}
+	ip := domain.IPAddress{
+		ID: domain.IPAddressID(uuid.NewString()), IP: addr, Hostname: input.Hostname, SubnetID: subnetID,
+		LastSeenAt: input.LastSeenAt, CreatedAt: fakeNow, UpdatedAt: fakeNow,
+	}
+	if input.MACAddress != "" {
+		if ip.MACAddress, err = net.ParseMAC(input.MACAddress); err != nil {
+			return domain.IPAddress{}, domain.InvalidField("mac_address", "invalid mac address")
+		}
+	}
+	s.ips[subnetID] = append(s.ips[subnetID], ip)
+	return ip, nil
+}
+
+func (s *fakeNetworkService) UpdateIPHostname(_ context.Context, subnetID int64, id domain.IPAddressID, input domain.UpdateIPInput) (domain.IPAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, ip := range s.ips[subnetID] {
+		if ip.ID == id {
+			s.ips[subnetID][i].Hostname = input.Hostname
+			if input.MACAddress != nil {
+				mac, _ := net.ParseMAC(*input.MACAddress)
+				s.ips[subnetID][i].MACAddress = mac
+			}
+			if input.LastSeenAt != nil {
+				s.ips[subnetID][i].LastSeenAt = input.LastSeenAt
+			}
+			return s.ips[subnetID][i], nil
+		}
+	}
+	return domain.IPAddress{}, domain.ErrIPNotFound
+}
+
+func (s *fakeNetworkService) DeleteIP(_ context.Context, subnetID int64, id domain.IPAddressID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, ip := range s.ips[subnetID] {
+		if ip.ID == id {
+			s.ips[subnetID] = append(s.ips[subnetID][:i], s.ips[subnetID][i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+type fakeSitesService struct {
+	mu    sync.Mutex
+	sites map[uuid.UUID]domain.Site
+}
+
+func newFakeSitesService() *fakeSitesService {
+	return &fakeSitesService{sites: map[uuid.UUID]domain.Site{}}
+}
+
+func (s *fakeSitesService) List(context.Context) ([]domain.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sites := make([]domain.Site, 0, len(s.sites))
+	for _, site := range s.sites {
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+func (s *fakeSitesService) FindByID(_ context.Context, id uuid.UUID) (domain.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	site, ok := s.sites[id]
+	if !ok {
+		return domain.Site{}, domain.ErrNotFound
+	}
+	return site, nil
+}
+
+func (s *fakeSitesService) Create(_ context.Context, input domain.CreateSiteInput) (domain.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	site := domain.Site{ID: uuid.New(), Name: input.Name, Description: input.Description, CreatedAt: fakeNow, UpdatedAt: fakeNow}
+	s.sites[site.ID] = site
+	return site, nil
+}
+
+func (s *fakeSitesService) Update(_ context.Context, input domain.UpdateSiteInput) (domain.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	site, ok := s.sites[input.ID]
+	if !ok {
+		return domain.Site{}, domain.ErrNotFound
+	}
+	site.Name, site.Description = input.Name, input.Description
+	s.sites[input.ID] = site
+	return site, nil
+}
+
+func (s *fakeSitesService) Delete(_ context.Context, id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.sites[id]
+	delete(s.sites, id)
+	return ok, nil
+}
+
+func (s *fakeSitesService) Statistics(context.Context) ([]domain.SiteStatistics, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statistics := make([]domain.SiteStatistics, 0, len(s.sites))
+	for _, site := range s.sites {
+		statistics = append(statistics, domain.SiteStatistics{ID: site.ID, Name: site.Name, SubnetCount: 1, TotalIPCount: 256, FreeIPCount: 256})
+	}
+	return statistics, nil
+}
+
+type fakeImportService struct {
+	received string
+}
+
+func (s *fakeImportService) ImportCSV(_ context.Context, input io.Reader) (domain.ImportResult, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	s.received = string(data)
+	rows := strings.Count(strings.TrimSpace(s.received), "\n")
+	return domain.ImportResult{Processed: rows, Created: rows}, nil
+}
+
+type fakeKubernetesReconciler struct{}
+
+func (fakeKubernetesReconciler) Reconcile(_ context.Context, key string) (domain.KubernetesReconcileResult, error) {
+	switch key {
+	case "prod":
+		return domain.KubernetesReconcileResult{Services: 3, Matched: 2, Unmatched: 1}, nil
+	case "busy":
+		return domain.KubernetesReconcileResult{}, domain.ErrDiscoveryBusy
+	default:
+		return domain.KubernetesReconcileResult{}, domain.ErrNotFound
+	}
+}
+
+type fakeDiscoveryService struct {
+	domain.KubernetesDiscoveryService
+}
+
+func (fakeDiscoveryService) ListSourceStatuses(context.Context) ([]domain.KubernetesSourceStatus, error) {
+	return []domain.KubernetesSourceStatus{{
+		Source: domain.KubernetesSource{Key: "prod", Name: "Production"}, State: "healthy", Services: 3, Matched: 2,
+	}}, nil
+}
+
+func (fakeDiscoveryService) ListRuns(_ context.Context, key string, limit int32) ([]domain.KubernetesDiscoveryRun, error) {
+	if key != "prod" {
+		return nil, domain.ErrNotFound
+	}
+	runs := []domain.KubernetesDiscoveryRun{
+		{ID: 2, StartedAt: fakeNow, FinishedAt: fakeNow, Result: domain.KubernetesReconcileResult{Services: 3, Matched: 2, Unmatched: 1}, Changes: 1},
+		{ID: 1, StartedAt: fakeNow, FinishedAt: fakeNow, Error: "services is forbidden"},
+	}
+	return runs[:min(int(limit), len(runs))], nil
+}
+
+func (fakeDiscoveryService) ListEvents(_ context.Context, key string, runID *int64, _ int32) ([]domain.KubernetesDiscoveryEvent, error) {
+	if key != "prod" || runID == nil || *runID != 2 {
+		return nil, domain.ErrNotFound
+	}
+	return []domain.KubernetesDiscoveryEvent{{
+		ID: 7, RunID: 2, ServiceUID: "orders-uid", Namespace: "commerce", Name: "orders", Change: domain.KubernetesServiceAddressChanged,
+		PreviousAddresses: []netip.Addr{netip.MustParseAddr("10.96.0.4")}, Addresses: []netip.Addr{netip.MustParseAddr("10.96.0.9")}, OccurredAt: fakeNow,
+	}}, nil
+}
+
+func (fakeDiscoveryService) ListServicesBySubnetID(context.Context, int64) ([]domain.KubernetesServiceObservation, error) {
+	return []domain.KubernetesServiceObservation{{
+		Source: domain.KubernetesSource{Key: "prod", Name: "Production"}, Name: "orders", Namespace: "commerce",
+		MatchStatus: domain.KubernetesMatchStatus("matched"), ObservedAt: fakeNow,
+	}}, nil
+}
+
+func (fakeDiscoveryService) ListObjectsBySubnetID(context.Context, int64) ([]domain.KubernetesObjectObservation, error) {
+	return []domain.KubernetesObjectObservation{{
+		Source: domain.KubernetesSource{Key: "prod", Name: "Production"}, Kind: domain.KubernetesObjectPod,
+		Name: "node-exporter", Namespace: "monitoring", HostNetwork: true, ObservedAt: fakeNow,
+		Addresses: []domain.KubernetesObjectAddressObservation{{
+			IP: netip.MustParseAddr("10.2.0.7"), Kind: "pod_ip", NodeName: "worker-1", MatchStatus: domain.KubernetesMatchUnmatched,
+		}},
+	}}, nil
+}
+
+type fakeKubernetesSourceService struct {
+	mu      sync.Mutex
+	sources map[string]domain.KubernetesSourceStatus
+}
+
+func (s *fakeKubernetesSourceService) CreateSource(_ context.Context, input domain.CreateKubernetesSourceInput) (domain.KubernetesSourceStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	interval := domain.DefaultKubernetesReconcileInterval
+	if input.ReconcileInterval != nil {
+		interval = *input.ReconcileInterval
+	}
+	source := domain.KubernetesSourceStatus{
+		Source: domain.KubernetesSource{Key: input.Key, Name: input.Key}, SiteID: input.SiteID, Namespaces: input.Namespaces, State: "pending", Managed: true,
+		Settings: &domain.KubernetesSourceSettings{AuthMode: domain.KubernetesAuthInCluster, ReconcileInterval: interval},
+	}
+	s.sources[input.Key] = source
+	return source, nil
+}
+
+func (s *fakeKubernetesSourceService) UpdateSource(_ context.Context, input domain.UpdateKubernetesSourceInput) (domain.KubernetesSourceStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source, ok := s.sources[input.Key]
+	if !ok {
+		return domain.KubernetesSourceStatus{}, domain.ErrNotFound
+	}
+	if input.Namespaces != nil {
+		source.Namespaces = input.Namespaces
+	}
+	s.sources[input.Key] = source
+	return source, nil
+}
+
+func (s *fakeKubernetesSourceService) DeleteSource(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.sources[key]
+	delete(s.sources, key)
+	return ok, nil
+}
+
+func (s *fakeKubernetesSourceService) ListManagedSources(context.Context) ([]domain.ManagedKubernetesSource, error) {
+	return nil, nil
+}
+
+type fakeReportingService struct {
+	domain.ReportingService
+	settings domain.ReportingSettings
+}
+
+func (s *fakeReportingService) GetSettings(context.Context) (domain.ReportingSettings, error) {
+	return s.settings, nil
+}
+
+func (s *fakeReportingService) UpdateSettings(_ context.Context, input domain.UpdateReportingSettingsInput) (domain.ReportingSettings, error) {
+	if input.RetentionDays < 1 {
+		return domain.ReportingSettings{}, domain.InvalidField("retention_days", "retention_days must be between 1 and 180")
+	}
+	s.settings.Cadence, s.settings.RetentionDays = input.Cadence, input.RetentionDays
+	return s.settings, nil
+}
+
+func (s *fakeReportingService) GetSubnetUsageHistory(_ context.Context, subnetID int64, usageRange string) (domain.SubnetUsageHistory, error) {
+	if usageRange == "1y" {
+		return domain.SubnetUsageHistory{}, domain.InvalidField("range", "unsupported range")
+	}
+	return domain.SubnetUsageHistory{
+		SubnetID: subnetID, Range: usageRange, Cadence: s.settings.Cadence,
+		Points: []domain.SubnetUsageSnapshot{{SubnetID: subnetID, CapturedAt: fakeNow, UsedIPs: 4, TotalIPs: 256}},
+	}, nil
+}
+
+type fakeWebhookService struct {
+	domain.WebhookService
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]domain.WebhookSubscription
+	retried       []int64
+}
+
+func newFakeWebhookService() *fakeWebhookService {
+	return &fakeWebhookService{subscriptions: map[uuid.UUID]domain.WebhookSubscription{}}
+}
+
+func (s *fakeWebhookService) ListSubscriptions(context.Context) ([]domain.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := make([]domain.WebhookSubscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (s *fakeWebhookService) GetSubscription(_ context.Context, id uuid.UUID) (domain.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return domain.WebhookSubscription{}, domain.ErrNotFound
+	}
+	return subscription, nil
+}
+
+func (s *fakeWebhookService) CreateSubscription(_ context.Context, input domain.CreateWebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription := domain.WebhookSubscription{
+		ID: uuid.New(), URL: input.URL, Description: input.Description, EventTypes: input.EventTypes,
+		Secret: "generated-secret", Active: true, CreatedAt: fakeNow, UpdatedAt: fakeNow,
+	}
+	s.subscriptions[subscription.ID] = subscription
+	return subscription, nil
+}
+
+func (s *fakeWebhookService) UpdateSubscription(_ context.Context, input domain.UpdateWebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[input.ID]
+	if !ok {
+		return domain.WebhookSubscription{}, domain.ErrNotFound
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+	s.subscriptions[input.ID] = subscription
+	return subscription, nil
+}
+
+func (s *fakeWebhookService) DeleteSubscription(_ context.Context, id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subscriptions[id]
+	delete(s.subscriptions, id)
+	return ok, nil
+}
+
+func (s *fakeWebhookService) ListDeadLetters(_ context.Context, limit int32) ([]domain.WebhookDeadLetter, error) {
+	return []domain.WebhookDeadLetter{{ID: int64(limit), EventType: domain.EventIPCreated, Attempts: 10}}, nil
+}
+
+func (s *fakeWebhookService) RetryDeadLetter(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retried = append(s.retried, id)
+	return nil
+}
+
+type fakeEventStream struct {
+	events []domain.ChangeEvent
+}
+
+func (s fakeEventStream) Subscribe(ctx context.Context, filter domain.EventFilter) <-chan domain.ChangeEvent {
+	out := make(chan domain.ChangeEvent, len(s.events))
+	for _, event := range s.events {
+		if filter.Matches(event) {
+			out <- event
+		}
+	}
+	close(out)
+	return out
+}
+
+type fakeHealth struct {
+	err error
+}
+
+func (h fakeHealth) Ping(context.Context) error {
+	return h.err
+}
+
+// tokenAuthenticator accepts a single bearer token as an admin.
+type tokenAuthenticator string
+
+func (a tokenAuthenticator) Authenticate(_ context.Context, token string) (apiauth.Principal, error) {
+	if token != string(a) {
+		return apiauth.Principal{}, errors.New("unknown token")
+	}
+	return apiauth.Principal{Subject: "sdk-test", Roles: []apiauth.Role{apiauth.RoleAdmin}}, nil
+}
+
+type fakeDNSService struct {
+	domain.DNSService
+	mu    sync.Mutex
+	zones map[string]domain.DNSZone
+}
+
+func newFakeDNSService() *fakeDNSService {
+	return &fakeDNSService{zones: map[string]domain.DNSZone{}}
+}
+
+func (s *fakeDNSService) ListZones(context.Context) ([]domain.DNSZone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zones := make([]domain.DNSZone, 0, len(s.zones))
+	for _, zone := range s.zones {
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+func (s *fakeDNSService) GetZone(_ context.Context, name string) (domain.DNSZone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zone, ok := s.zones[name]
+	if !ok {
+		return domain.DNSZone{}, domain.ErrNotFound
+	}
+	return zone, nil
+}
+
+func (s *fakeDNSService) CreateZone(_ context.Context, input domain.CreateDNSZoneInput) (domain.DNSZone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zone := domain.DNSZone{
+		ID: uuid.New(), Name: input.Name, Kind: domain.DNSZoneForward, SiteID: input.SiteID, SubnetID: input.SubnetID,
+		TTL: domain.DefaultDNSTTL, CreatedAt: fakeNow, UpdatedAt: fakeNow,
+	}
+	s.zones[zone.Name] = zone
+	return zone, nil
+}
+
+func (s *fakeDNSService) DeleteZone(_ context.Context, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.zones[name]
+	delete(s.zones, name)
+	return ok, nil
+}
+
+func (s *fakeDNSService) ZoneData(ctx context.Context, name string) (domain.DNSZoneData, error) {
+	zone, err := s.GetZone(ctx, name)
+	if err != nil {
+		return domain.DNSZoneData{}, err
+	}
+	return domain.DNSZoneData{
+		Zone:    zone,
+		SOA:     domain.DNSSOA{PrimaryNS: "ns1.example.com", Hostmaster: "hostmaster.example.com", Serial: 2026080101},
+		Records: []domain.DNSRecord{{Name: "web-1." + zone.Name, Type: domain.DNSRecordA, Data: "10.0.0.5"}},
+	}, nil
+}
+
+// fakeDHCPService stores settings on the fake network's subnets and renders
+// them with the real renderer.
+type fakeDHCPService struct {
+	network *fakeNetworkService
+}
+
+func (s fakeDHCPService) UpdateSubnetSettings(_ context.Context, input domain.UpdateSubnetDHCPInput) (domain.Subnet, error) {
+	s.network.mu.Lock()
+	defer s.network.mu.Unlock()
+	subnet, ok := s.network.subnets[input.ID]
+	if !ok {
+		return domain.Subnet{}, domain.ErrNotFound
+	}
+	subnet.Gateway, _ = netip.ParseAddr(input.Gateway)
+	subnet.DNSServers, subnet.DHCPPools = nil, nil
+	for _, server := range input.DNSServers {
+		subnet.DNSServers = append(subnet.DNSServers, netip.MustParseAddr(server))
+	}
+	for _, pool := range input.Pools {
+		subnet.DHCPPools = append(subnet.DHCPPools, domain.DHCPPool{Start: netip.MustParseAddr(pool.Start), End: netip.MustParseAddr(pool.End)})
+	}
+	s.network.subnets[input.ID] = subnet
+	return subnet, nil
+}
+
+func (s fakeDHCPService) SubnetConfig(_ context.Context, subnetID int64, format domain.DHCPConfigFormat) ([]byte, error) {
+	s.network.mu.Lock()
+	defer s.network.mu.Unlock()
+	subnet, ok := s.network.subnets[subnetID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return domain.RenderDHCPConfig(format, []domain.DHCPSubnetConfig{s.config(subnet)})
+}
+
+func (s fakeDHCPService) SiteConfig(_ context.Context, siteID uuid.UUID, format domain.DHCPConfigFormat) ([]byte, error) {
+	s.network.mu.Lock()
+	defer s.network.mu.Unlock()
+	var configs []domain.DHCPSubnetConfig
+	for _, subnet := range s.network.subnets {
+		if subnet.SiteID == siteID {
+			configs = append(configs, s.config(subnet))
+		}
+	}
+	return domain.RenderDHCPConfig(format, configs)
+}
+
+func (s fakeDHCPService) config(subnet domain.Subnet) domain.DHCPSubnetConfig {
+	config := domain.DHCPSubnetConfig{Subnet: subnet}
+	for _, ip := range s.network.ips[subnet.ID] {
+		if ip.MACAddress != nil {
+			config.Reservations = append(config.Reservations, domain.DHCPReservation{MACAddress: ip.MACAddress, IP: ip.IP, Hostname: ip.Hostname})
+		}
+	}
+	return config
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+)
+
+// Lease file formats accepted by ImportLeases.
+const (
+	LeaseFormatISC     = "isc"
+	LeaseFormatKea     = "kea"
+	LeaseFormatDnsmasq = "dnsmasq"
+)
+
+// ImportCSV uploads a site,cidr,ip,description CSV. The file is buffered so
+// the upload can be retried; it sends an Idempotency-Key, so a retry replays
+// the first result instead of importing twice.
+func (c *Client) ImportCSV(ctx context.Context, filename string, csv io.Reader) (ImportResult, error) {
+	return c.importFile(ctx, "/api/v1/import/csv", nil, "csv", filename, csv)
+}
+
+// ImportLeases uploads a DHCP lease file written by the server named by
+// format. With dryRun set the result reports what would change and nothing
+// is written.
+func (c *Client) ImportLeases(ctx context.Context, format, filename string, leases io.Reader, dryRun bool) (ImportResult, error) {
+	query := url.Values{"format": {format}}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	return c.importFile(ctx, "/api/v1/import/leases", query, "lease file", filename, leases)
+}
+
+// ImportZone uploads a BIND zone file and names addresses after its A, AAAA
+// and PTR records. origin names the zone for relative names when the file
+// has no $ORIGIN and may be empty otherwise.
+func (c *Client) ImportZone(ctx context.Context, origin, filename string, zone io.Reader, dryRun bool) (ImportResult, error) {
+	query := url.Values{}
+	if origin != "" {
+		query.Set("origin", origin)
+	}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	return c.importFile(ctx, "/api/v1/import/zone", query, "zone file", filename, zone)
+}
+
+func (c *Client) importFile(ctx context.Context, path string, query url.Values, kind, filename string, file io.Reader) (ImportResult, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(filename))
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("build %s upload: %w", kind, err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return ImportResult{}, fmt.Errorf("read %s: %w", kind, err)
+	}
+	if err := writer.Close(); err != nil {
+		return ImportResult{}, fmt.Errorf("build %s upload: %w", kind, err)
+	}
+	req := request{
+		method:      http.MethodPost,
+		path:        path,
+		query:       query,
+		body:        body.Bytes(),
+		contentType: writer.FormDataContentType(),
+		idempotent:  true,
+	}
+	var result ImportResult
+	err = c.do(ctx, req, &result)
+	return result, err
+}
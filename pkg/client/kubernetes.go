@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListKubernetesSources returns the discovery status of each configured
+// cluster.
+func (c *Client) ListKubernetesSources(ctx context.Context) ([]KubernetesSourceStatus, error) {
+	var sources []KubernetesSourceStatus
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/kubernetes/sources"}, &sources)
+	return sources, err
+}
+
+// CreateKubernetesSource starts discovering a cluster. The server never
+// returns the kubeconfig.
+func (c *Client) CreateKubernetesSource(ctx context.Context, input KubernetesSourceRequest) (KubernetesSourceStatus, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/v1/kubernetes/sources", input)
+	if err != nil {
+		return KubernetesSourceStatus{}, err
+	}
+	var source KubernetesSourceStatus
+	err = c.do(ctx, req, &source)
+	return source, err
+}
+
+func (c *Client) UpdateKubernetesSource(ctx context.Context, key string, input UpdateKubernetesSourceRequest) (KubernetesSourceStatus, error) {
+	req, err := jsonRequest(http.MethodPatch, kubernetesSourcePath(key), input)
+	if err != nil {
+		return KubernetesSourceStatus{}, err
+	}
+	var source KubernetesSourceStatus
+	err = c.do(ctx, req, &source)
+	return source, err
+}
+
+func (c *Client) DeleteKubernetesSource(ctx context.Context, key string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: kubernetesSourcePath(key)}, nil)
+}
+
+// ReconcileKubernetesSource runs a discovery cycle of the source now instead
+// of at its next interval. It returns ErrDiscoveryBusy while another cycle of
+// the source is running.
+func (c *Client) ReconcileKubernetesSource(ctx context.Context, key string) (KubernetesReconcileResult, error) {
+	var result KubernetesReconcileResult
+	err := c.do(ctx, request{method: http.MethodPost, path: kubernetesSourcePath(key) + "/reconcile"}, &result)
+	return result, err
+}
+
+// ListKubernetesSourceRuns returns up to limit discovery runs of the source,
+// newest first. A limit of 0 uses the server default.
+func (c *Client) ListKubernetesSourceRuns(ctx context.Context, key string, limit int) ([]KubernetesDiscoveryRun, error) {
+	req := request{method: http.MethodGet, path: kubernetesSourcePath(key) + "/runs", query: url.Values{}}
+	if limit > 0 {
+		req.query.Set("limit", strconv.Itoa(limit))
+	}
+	var runs []KubernetesDiscoveryRun
+	err := c.do(ctx, req, &runs)
+	return runs, err
+}
+
+// ListKubernetesSourceEvents returns up to limit Service changes made by
+// discovery of the source, newest first. A runID of 0 lists the changes of
+// every run, and a limit of 0 uses the server default.
+func (c *Client) ListKubernetesSourceEvents(ctx context.Context, key string, runID int64, limit int) ([]KubernetesDiscoveryEvent, error) {
+	req := request{method: http.MethodGet, path: kubernetesSourcePath(key) + "/events", query: url.Values{}}
+	if runID > 0 {
+		req.query.Set("run_id", strconv.FormatInt(runID, 10))
+	}
+	if limit > 0 {
+		req.query.Set("limit", strconv.Itoa(limit))
+	}
+	var events []KubernetesDiscoveryEvent
+	err := c.do(ctx, req, &events)
+	return events, err
+}
+
+func kubernetesSourcePath(key string) string {
+	return "/api/v1/kubernetes/sources/" + url.PathEscape(key)
+}
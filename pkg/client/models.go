@@ -0,0 +1,461 @@
+package client
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subnet is a managed prefix and its address usage.
+type Subnet struct {
+	ID          int64      `json:"id"`
+	CIDR        string     `json:"cidr"`
+	SiteID      *uuid.UUID `json:"site_id,omitempty"`
+	UsedIPs     int64      `json:"used_ips"`
+	TotalIPs    int64      `json:"total_ips"`
+	Description string     `json:"description"`
+	Gateway     string     `json:"gateway,omitempty"`
+	DNSServers  []string   `json:"dns_servers"`
+	DHCPPools   []DHCPPool `json:"dhcp_pools"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// DHCPPool is an inclusive range of addresses handed out by DHCP.
+type DHCPPool struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// SubnetDHCPRequest replaces a subnet's gateway, DNS servers and pools. An
+// empty Gateway removes it.
+type SubnetDHCPRequest struct {
+	Gateway    string     `json:"gateway"`
+	DNSServers []string   `json:"dns_servers"`
+	Pools      []DHCPPool `json:"pools"`
+}
+
+// SubnetRequest creates a subnet or replaces its CIDR, site and description.
+type SubnetRequest struct {
+	CIDR        string     `json:"cidr"`
+	SiteID      *uuid.UUID `json:"site_id"`
+	Description string     `json:"description"`
+}
+
+type IPAddress struct {
+	ID                 string              `json:"id"`
+	IP                 string              `json:"ip"`
+	Hostname           string              `json:"hostname"`
+	MACAddress         string              `json:"mac_address,omitempty"`
+	LastSeenAt         *time.Time          `json:"last_seen_at,omitempty"`
+	SubnetID           int64               `json:"subnet_id"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	KubernetesServices []KubernetesService `json:"kubernetes_services"`
+	KubernetesObjects  []KubernetesObject  `json:"kubernetes_objects"`
+	// KubernetesAllocation is set when the LoadBalancer allocator gave the
+	// address to a Service.
+	KubernetesAllocation *KubernetesAllocation `json:"kubernetes_allocation,omitempty"`
+	// KubernetesRegistration is set when discovery created the record for
+	// an address a Service reported.
+	KubernetesRegistration *KubernetesRegistration `json:"kubernetes_registration,omitempty"`
+}
+
+type KubernetesAllocation struct {
+	Controller  string    `json:"controller"`
+	ServiceUID  string    `json:"service_uid"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	AllocatedAt time.Time `json:"allocated_at"`
+}
+
+type KubernetesRegistration struct {
+	Source       KubernetesSource `json:"source"`
+	ServiceUID   string           `json:"service_uid"`
+	Namespace    string           `json:"namespace"`
+	Name         string           `json:"name"`
+	RegisteredAt time.Time        `json:"registered_at"`
+}
+
+type CreateIPRequest struct {
+	IP         string `json:"ip"`
+	Hostname   string `json:"hostname"`
+	MACAddress string `json:"mac_address,omitempty"`
+}
+
+// UpdateIPRequest keeps the address's MAC address when MACAddress is nil and
+// removes it when it points to an empty string.
+type UpdateIPRequest struct {
+	Hostname   string  `json:"hostname"`
+	MACAddress *string `json:"mac_address,omitempty"`
+}
+
+type Site struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	SubnetCount int64     `json:"subnet_count"`
+	UsedIPs     int64     `json:"used_ips"`
+	TotalIPs    int64     `json:"total_ips"`
+	FreeIPs     int64     `json:"free_ips"`
+}
+
+type SiteRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type SiteStatistics struct {
+	Site
+	UsedIPCount  int64 `json:"used_ip_count"`
+	TotalIPCount int64 `json:"total_ip_count"`
+	FreeIPCount  int64 `json:"free_ip_count"`
+}
+
+// ImportResult summarises a CSV import. Rows that failed are listed in Errors
+// and do not fail the call.
+type ImportResult struct {
+	Processed int        `json:"processed"`
+	Created   int        `json:"created"`
+	Updated   int        `json:"updated"`
+	Failed    int        `json:"failed"`
+	Errors    []RowError `json:"errors"`
+}
+
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type KubernetesSource struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type KubernetesServicePort struct {
+	Name        string `json:"name"`
+	Protocol    string `json:"protocol"`
+	Port        int32  `json:"port"`
+	TargetPort  string `json:"target_port"`
+	AppProtocol string `json:"app_protocol,omitempty"`
+	NodePort    *int32 `json:"node_port,omitempty"`
+}
+
+type KubernetesMatchedAddress struct {
+	IP   string `json:"ip"`
+	Kind string `json:"kind"`
+}
+
+// KubernetesService is a Service that references an IP address record.
+type KubernetesService struct {
+	Source           KubernetesSource           `json:"source"`
+	UID              string                     `json:"uid"`
+	Name             string                     `json:"name"`
+	Namespace        string                     `json:"namespace"`
+	Type             string                     `json:"type"`
+	DNSName          string                     `json:"dns_name"`
+	MatchedAddresses []KubernetesMatchedAddress `json:"matched_addresses"`
+	Ports            []KubernetesServicePort    `json:"ports"`
+	ObservedAt       time.Time                  `json:"observed_at"`
+}
+
+// KubernetesObject is a Node, Pod, EndpointSlice, Ingress, Gateway or
+// HTTPRoute with an address that references an IP address record.
+type KubernetesObject struct {
+	Source      KubernetesSource                `json:"source"`
+	Kind        string                          `json:"kind"`
+	UID         string                          `json:"uid"`
+	Namespace   string                          `json:"namespace,omitempty"`
+	Name        string                          `json:"name"`
+	HostNetwork bool                            `json:"host_network"`
+	AddressKind string                          `json:"address_kind"`
+	NodeName    string                          `json:"node_name,omitempty"`
+	Hostnames   []KubernetesHostnameObservation `json:"hostnames"`
+	ObservedAt  time.Time                       `json:"observed_at"`
+}
+
+type KubernetesAddressObservation struct {
+	IP                 string  `json:"ip"`
+	Kind               string  `json:"kind"`
+	IPMode             string  `json:"ip_mode,omitempty"`
+	MatchStatus        string  `json:"match_status"`
+	MatchCount         int     `json:"match_count"`
+	MatchedIPAddressID *string `json:"matched_ip_address_id,omitempty"`
+	MatchedSubnetID    *int64  `json:"matched_subnet_id,omitempty"`
+}
+
+type KubernetesHostnameObservation struct {
+	Kind     string `json:"kind"`
+	Hostname string `json:"hostname"`
+}
+
+// KubernetesServiceObservation is a Service seen in a subnet's site, matched
+// or not.
+type KubernetesServiceObservation struct {
+	Source       KubernetesSource                `json:"source"`
+	UID          string                          `json:"uid"`
+	Name         string                          `json:"name"`
+	Namespace    string                          `json:"namespace"`
+	Type         string                          `json:"type"`
+	ExternalName string                          `json:"external_name,omitempty"`
+	DNSName      string                          `json:"dns_name"`
+	MatchStatus  string                          `json:"match_status"`
+	Addresses    []KubernetesAddressObservation  `json:"addresses"`
+	Hostnames    []KubernetesHostnameObservation `json:"hostnames"`
+	Ports        []KubernetesServicePort         `json:"ports"`
+	ObservedAt   time.Time                       `json:"observed_at"`
+}
+
+type KubernetesObjectAddressObservation struct {
+	IP                 string  `json:"ip"`
+	Kind               string  `json:"kind"`
+	NodeName           string  `json:"node_name,omitempty"`
+	MatchStatus        string  `json:"match_status"`
+	MatchCount         int     `json:"match_count"`
+	MatchedIPAddressID *string `json:"matched_ip_address_id,omitempty"`
+	MatchedSubnetID    *int64  `json:"matched_subnet_id,omitempty"`
+	// LoadBalancerServices are the Services, as namespace/name, that have
+	// the address as a load-balancer IP.
+	LoadBalancerServices []string `json:"load_balancer_services"`
+}
+
+// KubernetesObjectObservation is a discovered object with addresses in a
+// subnet, matched or not.
+type KubernetesObjectObservation struct {
+	Source      KubernetesSource                     `json:"source"`
+	Kind        string                               `json:"kind"`
+	UID         string                               `json:"uid"`
+	Namespace   string                               `json:"namespace,omitempty"`
+	Name        string                               `json:"name"`
+	HostNetwork bool                                 `json:"host_network"`
+	Addresses   []KubernetesObjectAddressObservation `json:"addresses"`
+	Hostnames   []KubernetesHostnameObservation      `json:"hostnames"`
+	ObservedAt  time.Time                            `json:"observed_at"`
+}
+
+type KubernetesSourceStatus struct {
+	Source        KubernetesSource `json:"source"`
+	SiteID        uuid.UUID        `json:"site_id"`
+	ClusterDomain string           `json:"cluster_domain"`
+	Namespaces    []string         `json:"namespaces"`
+	ObjectKinds   []string         `json:"object_kinds"`
+	PodSelector   string           `json:"pod_selector,omitempty"`
+	AutoRegister  string           `json:"auto_register"`
+	State         string           `json:"state"`
+	LastAttemptAt *time.Time       `json:"last_attempt_at"`
+	LastSuccessAt *time.Time       `json:"last_success_at"`
+	LastError     string           `json:"last_error"`
+	Services      int              `json:"services"`
+	Matched       int              `json:"matched"`
+	Unmatched     int              `json:"unmatched"`
+	Ambiguous     int              `json:"ambiguous"`
+	NoUsableIP    int              `json:"no_usable_ip"`
+	Objects       int              `json:"objects"`
+	// ObjectError is why the latest listing of the source's object kinds
+	// failed. Its Services are still discovered.
+	ObjectError string `json:"object_error,omitempty"`
+	// Managed sources were created through the API. Settings is only set
+	// for them.
+	Managed  bool                      `json:"managed"`
+	Settings *KubernetesSourceSettings `json:"settings,omitempty"`
+}
+
+// KubernetesReconcileResult counts the source's Services after an on-demand
+// discovery cycle.
+type KubernetesReconcileResult struct {
+	Source     string `json:"source"`
+	Services   int    `json:"services"`
+	Matched    int    `json:"matched"`
+	Unmatched  int    `json:"unmatched"`
+	Ambiguous  int    `json:"ambiguous"`
+	NoUsableIP int    `json:"no_usable_ip"`
+}
+
+// KubernetesDiscoveryRun is one publication of a source's Services or one
+// failed attempt, whose counts are zero.
+type KubernetesDiscoveryRun struct {
+	ID         int64     `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Services   int       `json:"services"`
+	Matched    int       `json:"matched"`
+	Unmatched  int       `json:"unmatched"`
+	Ambiguous  int       `json:"ambiguous"`
+	NoUsableIP int       `json:"no_usable_ip"`
+	Error      string    `json:"error"`
+	Changes    int       `json:"changes"`
+}
+
+// KubernetesDiscoveryEvent is a change a discovery run made to a Service:
+// appeared, address_changed, stale or purged.
+type KubernetesDiscoveryEvent struct {
+	ID                int64     `json:"id"`
+	RunID             int64     `json:"run_id"`
+	ServiceUID        string    `json:"service_uid"`
+	Namespace         string    `json:"namespace"`
+	Name              string    `json:"name"`
+	Change            string    `json:"change"`
+	PreviousAddresses []string  `json:"previous_addresses"`
+	Addresses         []string  `json:"addresses"`
+	OccurredAt        time.Time `json:"occurred_at"`
+}
+
+type KubernetesSourceSettings struct {
+	AuthMode              string `json:"auth_mode"`
+	KubeconfigContext     string `json:"kubeconfig_context"`
+	IntervalSeconds       int64  `json:"interval_seconds"`
+	RequestTimeoutSeconds int64  `json:"request_timeout_seconds"`
+	StaleRetentionSeconds int64  `json:"stale_retention_seconds"`
+}
+
+// KubernetesSourceRequest creates a discovery source. Nil durations take the
+// server defaults. A Kubeconfig must embed its credentials.
+type KubernetesSourceRequest struct {
+	Key                   string    `json:"key"`
+	Name                  string    `json:"name,omitempty"`
+	SiteID                uuid.UUID `json:"site_id"`
+	ClusterDomain         string    `json:"cluster_domain,omitempty"`
+	Namespaces            []string  `json:"namespaces"`
+	ObjectKinds           []string  `json:"object_kinds,omitempty"`
+	PodSelector           string    `json:"pod_selector,omitempty"`
+	AutoRegister          string    `json:"auto_register,omitempty"`
+	AuthMode              string    `json:"auth_mode,omitempty"`
+	Kubeconfig            string    `json:"kubeconfig,omitempty"`
+	KubeconfigContext     string    `json:"kubeconfig_context,omitempty"`
+	IntervalSeconds       *int32    `json:"interval_seconds,omitempty"`
+	RequestTimeoutSeconds *int32    `json:"request_timeout_seconds,omitempty"`
+	StaleRetentionSeconds *int32    `json:"stale_retention_seconds,omitempty"`
+}
+
+// UpdateKubernetesSourceRequest changes only the fields that are set. Set
+// ObjectKinds to an empty slice to stop discovering objects other than
+// Services.
+type UpdateKubernetesSourceRequest struct {
+	Name                  *string    `json:"name,omitempty"`
+	SiteID                *uuid.UUID `json:"site_id,omitempty"`
+	ClusterDomain         *string    `json:"cluster_domain,omitempty"`
+	Namespaces            []string   `json:"namespaces,omitempty"`
+	ObjectKinds           *[]string  `json:"object_kinds,omitempty"`
+	PodSelector           *string    `json:"pod_selector,omitempty"`
+	AutoRegister          *string    `json:"auto_register,omitempty"`
+	AuthMode              *string    `json:"auth_mode,omitempty"`
+	Kubeconfig            *string    `json:"kubeconfig,omitempty"`
+	KubeconfigContext     *string    `json:"kubeconfig_context,omitempty"`
+	IntervalSeconds       *int32     `json:"interval_seconds,omitempty"`
+	RequestTimeoutSeconds *int32     `json:"request_timeout_seconds,omitempty"`
+	StaleRetentionSeconds *int32     `json:"stale_retention_seconds,omitempty"`
+}
+
+type ReportingSettings struct {
+	Cadence        string     `json:"cadence"`
+	RetentionDays  int32      `json:"retention_days"`
+	LastSnapshotAt *time.Time `json:"last_snapshot_at,omitempty"`
+}
+
+type ReportingSettingsRequest struct {
+	Cadence       string `json:"cadence"`
+	RetentionDays int32  `json:"retention_days"`
+}
+
+type SubnetUsageSnapshot struct {
+	CapturedAt time.Time `json:"captured_at"`
+	UsedIPs    int64     `json:"used_ips"`
+	TotalIPs   int64     `json:"total_ips"`
+}
+
+type SubnetUsageHistory struct {
+	SubnetID int64                 `json:"subnet_id"`
+	Range    string                `json:"range"`
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	Cadence  string                `json:"cadence"`
+	Points   []SubnetUsageSnapshot `json:"points"`
+}
+
+type WebhookSubscription struct {
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreatedWebhookSubscription is the only response that carries the signing
+// secret.
+type CreatedWebhookSubscription struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookSubscriptionRequest creates a webhook. An empty Secret asks the
+// server to generate one.
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// UpdateWebhookSubscriptionRequest changes only the fields that are set.
+type UpdateWebhookSubscriptionRequest struct {
+	URL         *string  `json:"url,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Secret      *string  `json:"secret,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+type WebhookDeadLetter struct {
+	ID             int64      `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	URL            string     `json:"url"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	ObjectType     string     `json:"object_type"`
+	ObjectID       string     `json:"object_id"`
+	Attempts       int32      `json:"attempts"`
+	LastStatusCode *int32     `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ChangeEvent identifies an object that changed. Fetch the object to see its
+// new state.
+type ChangeEvent struct {
+	ID         int64      `json:"id,omitempty"`
+	Type       string     `json:"type"`
+	ObjectType string     `json:"object_type"`
+	ObjectID   string     `json:"object_id"`
+	SiteID     *uuid.UUID `json:"site_id,omitempty"`
+	SubnetID   *int64     `json:"subnet_id,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// DNSZone is a stored forward zone or a reverse zone computed from the
+// subnets. Reverse zones have a Network instead of an ID and attachment.
+type DNSZone struct {
+	ID        *uuid.UUID `json:"id,omitempty"`
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"`
+	SiteID    *uuid.UUID `json:"site_id,omitempty"`
+	SubnetID  *int64     `json:"subnet_id,omitempty"`
+	Network   string     `json:"network,omitempty"`
+	TTL       int32      `json:"ttl"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// DNSZoneRequest attaches a forward zone to exactly one of a site or a
+// subnet.
+type DNSZoneRequest struct {
+	Name     string     `json:"name"`
+	SiteID   *uuid.UUID `json:"site_id,omitempty"`
+	SubnetID *int64     `json:"subnet_id,omitempty"`
+	TTL      *int32     `json:"ttl,omitempty"`
+}
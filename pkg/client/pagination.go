@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// All ranges over the results of a list call, yielding the call's error
+// once if it fails. List endpoints return their whole collection, so All
+// makes a single request:
+//
+//	for subnet, err := range client.All(ctx, c.ListSubnets) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func All[T any](ctx context.Context, list func(context.Context) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		items, err := list(ctx)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
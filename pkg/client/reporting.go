@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+const reportingSettingsPath = "/api/v1/reporting/settings"
+
+func (c *Client) GetReportingSettings(ctx context.Context) (ReportingSettings, error) {
+	var settings ReportingSettings
+	err := c.do(ctx, request{method: http.MethodGet, path: reportingSettingsPath}, &settings)
+	return settings, err
+}
+
+func (c *Client) UpdateReportingSettings(ctx context.Context, input ReportingSettingsRequest) (ReportingSettings, error) {
+	req, err := jsonRequest(http.MethodPatch, reportingSettingsPath, input)
+	if err != nil {
+		return ReportingSettings{}, err
+	}
+	var settings ReportingSettings
+	err = c.do(ctx, req, &settings)
+	return settings, err
+}
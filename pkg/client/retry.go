@@ -0,0 +1,59 @@
+package client
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are repeated. Only requests that
+// are safe to repeat are retried: GET, HEAD, PUT and DELETE, and creates that
+// carry an Idempotency-Key. Network errors, 429 and 502-504 responses are
+// retried; a Retry-After header overrides the backoff up to MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt. Values below 2 disable retries.
+	MaxAttempts int
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, MinDelay: 250 * time.Millisecond, MaxDelay: 5 * time.Second}
+}
+
+func retryableMethod(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(idempotencyKeyHeader) != ""
+	}
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// delay returns the wait before the given retry, counting from 1. Backoff
+// doubles from MinDelay with full jitter.
+func (p RetryPolicy) delay(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, p.MaxDelay)
+		}
+	}
+	backoff := p.MinDelay << min(retry-1, 16)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff) + 1
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func sitePath(id uuid.UUID) string {
+	return "/api/v1/sites/" + id.String()
+}
+
+func (c *Client) ListSites(ctx context.Context) ([]Site, error) {
+	var sites []Site
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/sites"}, &sites)
+	return sites, err
+}
+
+func (c *Client) SiteStatistics(ctx context.Context) ([]SiteStatistics, error) {
+	var statistics []SiteStatistics
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/sites/statistics"}, &statistics)
+	return statistics, err
+}
+
+func (c *Client) GetSite(ctx context.Context, id uuid.UUID) (Site, error) {
+	var site Site
+	err := c.do(ctx, request{method: http.MethodGet, path: sitePath(id)}, &site)
+	return site, err
+}
+
+// CreateSite sends an Idempotency-Key, so a retried create cannot record the
+// site twice.
+func (c *Client) CreateSite(ctx context.Context, input SiteRequest) (Site, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/v1/sites", input)
+	if err != nil {
+		return Site{}, err
+	}
+	req.idempotent = true
+	var site Site
+	err = c.do(ctx, req, &site)
+	return site, err
+}
+
+func (c *Client) UpdateSite(ctx context.Context, id uuid.UUID, input SiteRequest) (Site, error) {
+	req, err := jsonRequest(http.MethodPatch, sitePath(id), input)
+	if err != nil {
+		return Site{}, err
+	}
+	var site Site
+	err = c.do(ctx, req, &site)
+	return site, err
+}
+
+func (c *Client) DeleteSite(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: sitePath(id)}, nil)
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+func subnetPath(id int64) string {
+	return "/api/v1/subnets/" + strconv.FormatInt(id, 10)
+}
+
+func (c *Client) ListSubnets(ctx context.Context) ([]Subnet, error) {
+	var subnets []Subnet
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/subnets"}, &subnets)
+	return subnets, err
+}
+
+func (c *Client) GetSubnet(ctx context.Context, id int64) (Subnet, error) {
+	var subnet Subnet
+	err := c.do(ctx, request{method: http.MethodGet, path: subnetPath(id)}, &subnet)
+	return subnet, err
+}
+
+// CreateSubnet sends an Idempotency-Key, so a retried create cannot record
+// the subnet twice.
+func (c *Client) CreateSubnet(ctx context.Context, input SubnetRequest) (Subnet, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/v1/subnets", input)
+	if err != nil {
+		return Subnet{}, err
+	}
+	req.idempotent = true
+	var subnet Subnet
+	err = c.do(ctx, req, &subnet)
+	return subnet, err
+}
+
+func (c *Client) UpdateSubnet(ctx context.Context, id int64, input SubnetRequest) (Subnet, error) {
+	req, err := jsonRequest(http.MethodPatch, subnetPath(id), input)
+	if err != nil {
+		return Subnet{}, err
+	}
+	var subnet Subnet
+	err = c.do(ctx, req, &subnet)
+	return subnet, err
+}
+
+func (c *Client) AssignSubnetSite(ctx context.Context, id int64, siteID uuid.UUID) (Subnet, error) {
+	req, err := jsonRequest(http.MethodPatch, subnetPath(id)+"/site", map[string]uuid.UUID{"site_id": siteID})
+	if err != nil {
+		return Subnet{}, err
+	}
+	var subnet Subnet
+	err = c.do(ctx, req, &subnet)
+	return subnet, err
+}
+
+func (c *Client) DeleteSubnet(ctx context.Context, id int64) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: subnetPath(id)}, nil)
+}
+
+func (c *Client) ListIPs(ctx context.Context, subnetID int64) ([]IPAddress, error) {
+	var ips []IPAddress
+	err := c.do(ctx, request{method: http.MethodGet, path: subnetPath(subnetID) + "/ips"}, &ips)
+	return ips, err
+}
+
+// CreateIP sends an Idempotency-Key, so a retried create does not fail with
+// a conflict when the first attempt succeeded.
+func (c *Client) CreateIP(ctx context.Context, subnetID int64, input CreateIPRequest) (IPAddress, error) {
+	req, err := jsonRequest(http.MethodPost, subnetPath(subnetID)+"/ips", input)
+	if err != nil {
+		return IPAddress{}, err
+	}
+	req.idempotent = true
+	var ip IPAddress
+	err = c.do(ctx, req, &ip)
+	return ip, err
+}
+
+func (c *Client) UpdateIP(ctx context.Context, subnetID int64, ipID string, input UpdateIPRequest) (IPAddress, error) {
+	req, err := jsonRequest(http.MethodPatch, ipPath(subnetID, ipID), input)
+	if err != nil {
+		return IPAddress{}, err
+	}
+	var ip IPAddress
+	err = c.do(ctx, req, &ip)
+	return ip, err
+}
+
+func (c *Client) DeleteIP(ctx context.Context, subnetID int64, ipID string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: ipPath(subnetID, ipID)}, nil)
+}
+
+func ipPath(subnetID int64, ipID string) string {
+	return fmt.Sprintf("%s/ips/%s", subnetPath(subnetID), url.PathEscape(ipID))
+}
+
+// ListSubnetKubernetesServices returns every Service observed in the site of
+// the subnet, matched to an address or not.
+func (c *Client) ListSubnetKubernetesServices(ctx context.Context, subnetID int64) ([]KubernetesServiceObservation, error) {
+	var services []KubernetesServiceObservation
+	err := c.do(ctx, request{method: http.MethodGet, path: subnetPath(subnetID) + "/kubernetes-services"}, &services)
+	return services, err
+}
+
+// ListSubnetKubernetesObjects returns every discovered Kubernetes object
+// with an address in the subnet, matched to a record or not.
+func (c *Client) ListSubnetKubernetesObjects(ctx context.Context, subnetID int64) ([]KubernetesObjectObservation, error) {
+	var objects []KubernetesObjectObservation
+	err := c.do(ctx, request{method: http.MethodGet, path: subnetPath(subnetID) + "/kubernetes-objects"}, &objects)
+	return objects, err
+}
+
+// GetSubnetUsageHistory returns usage snapshots for usageRange, one of 24h,
+// 7d, 30d, 90d or 180d. An empty range uses the server default of 7d.
+func (c *Client) GetSubnetUsageHistory(ctx context.Context, subnetID int64, usageRange string) (SubnetUsageHistory, error) {
+	req := request{method: http.MethodGet, path: subnetPath(subnetID) + "/usage-history"}
+	if usageRange != "" {
+		req.query = url.Values{"range": {usageRange}}
+	}
+	var history SubnetUsageHistory
+	err := c.do(ctx, req, &history)
+	return history, err
+}
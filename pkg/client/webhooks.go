@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+func webhookPath(id uuid.UUID) string {
+	return "/api/v1/webhooks/" + id.String()
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]WebhookSubscription, error) {
+	var subscriptions []WebhookSubscription
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/webhooks"}, &subscriptions)
+	return subscriptions, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id uuid.UUID) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	err := c.do(ctx, request{method: http.MethodGet, path: webhookPath(id)}, &subscription)
+	return subscription, err
+}
+
+// CreateWebhook is not retried: the endpoint takes no Idempotency-Key, and a
+// repeated create would add a second subscription.
+func (c *Client) CreateWebhook(ctx context.Context, input WebhookSubscriptionRequest) (CreatedWebhookSubscription, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/v1/webhooks", input)
+	if err != nil {
+		return CreatedWebhookSubscription{}, err
+	}
+	var subscription CreatedWebhookSubscription
+	err = c.do(ctx, req, &subscription)
+	return subscription, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id uuid.UUID, input UpdateWebhookSubscriptionRequest) (WebhookSubscription, error) {
+	req, err := jsonRequest(http.MethodPatch, webhookPath(id), input)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	var subscription WebhookSubscription
+	err = c.do(ctx, req, &subscription)
+	return subscription, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: webhookPath(id)}, nil)
+}
+
+// ListWebhookDeadLetters returns up to limit deliveries that exhausted their
+// attempts, most recently attempted first. A limit of 0 uses the server
+// default.
+func (c *Client) ListWebhookDeadLetters(ctx context.Context, limit int) ([]WebhookDeadLetter, error) {
+	req := request{method: http.MethodGet, path: "/api/v1/webhooks/dead-letters"}
+	if limit > 0 {
+		req.query = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+	var deadLetters []WebhookDeadLetter
+	err := c.do(ctx, req, &deadLetters)
+	return deadLetters, err
+}
+
+// RetryWebhookDelivery queues a dead-lettered delivery for redelivery.
+func (c *Client) RetryWebhookDelivery(ctx context.Context, deliveryID int64) error {
+	path := "/api/v1/webhooks/deliveries/" + strconv.FormatInt(deliveryID, 10) + "/retry"
+	return c.do(ctx, request{method: http.MethodPost, path: path}, nil)
+}